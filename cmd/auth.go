@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/auth"
+	"github.com/binary-install/binstaller/pkg/httpclient"
+	"github.com/spf13/cobra"
+)
+
+// gitHubAuthHost is the host binst auth logs in to
+const gitHubAuthHost = "github.com"
+
+// gitHubLoginBaseURL serves the device flow; tests replace it
+var gitHubLoginBaseURL = auth.DefaultBaseURL
+
+var (
+	// Flags for auth login command
+	authClientID  string
+	authScopes    []string
+	authWithToken bool
+)
+
+// AuthCommand represents the auth command
+var AuthCommand = &cobra.Command{
+	Use:   "auth",
+	Short: "Log in to GitHub for higher rate limits and private repositories",
+	Long: `Manage the GitHub token binst uses for API calls and downloads.
+
+binst uses the first token it finds:
+  1. the GITHUB_TOKEN or GH_TOKEN environment variable
+  2. the token stored by 'binst auth login'
+  3. the token gh stored by 'gh auth login'
+
+Set BINSTALLER_NO_KEYRING=1 to only use the environment.`,
+}
+
+// AuthLoginCommand represents the auth login command
+var AuthLoginCommand = &cobra.Command{
+	Use:   "login",
+	Short: "Log in to GitHub with the device flow and store the token",
+	Long: `Log in to GitHub in the browser with the OAuth device flow: binst shows a one-time
+code to enter at https://github.com/login/device and stores the token once the login
+is authorized.
+
+The token is stored in the OS keychain (macOS Keychain, Windows Credential Manager,
+Secret Service). Without a keychain it is stored in hosts.yml in
+$XDG_CONFIG_HOME/binstaller (~/.config/binstaller), readable by the owner only.
+
+The default "repo" scope gives access to private repositories; pass --scopes ""
+to only access public releases. With --with-token an existing token is read from
+stdin and stored instead, e.g. in headless environments.`,
+	Example: `  # Log in in the browser
+  binst auth login
+
+  # Store an existing token
+  binst auth login --with-token < token.txt`,
+	Args: cobra.NoArgs,
+	RunE: runAuthLogin,
+}
+
+// AuthStatusCommand represents the auth status command
+var AuthStatusCommand = &cobra.Command{
+	Use:   "status",
+	Short: "Show the GitHub account and rate limit of the token in use",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return printAuthStatus(cmd.Context(), os.Stdout)
+	},
+}
+
+// AuthLogoutCommand represents the auth logout command
+var AuthLogoutCommand = &cobra.Command{
+	Use:   "logout",
+	Short: "Remove the token stored by binst auth login",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		deleted, err := httpclient.DeleteLoginToken(gitHubAuthHost)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			log.Infof("No token stored for %s", gitHubAuthHost)
+			return nil
+		}
+		log.Infof("Removed the stored token for %s", gitHubAuthHost)
+		return nil
+	},
+}
+
+func init() {
+	AuthLoginCommand.Flags().StringVar(&authClientID, "client-id", "", "OAuth app client ID (default: binstaller's app, or $BINSTALLER_OAUTH_CLIENT_ID)")
+	AuthLoginCommand.Flags().StringSliceVar(&authScopes, "scopes", []string{"repo"}, "OAuth scopes to request")
+	AuthLoginCommand.Flags().BoolVar(&authWithToken, "with-token", false, "Read a token from stdin instead of logging in")
+	AuthCommand.AddCommand(AuthLoginCommand, AuthStatusCommand, AuthLogoutCommand)
+}
+
+func runAuthLogin(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	var token string
+	if authWithToken {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("failed to read the token from stdin: %w", err)
+		}
+		token = strings.TrimSpace(line)
+		if token == "" {
+			return errors.New("no token on stdin")
+		}
+	} else {
+		var err error
+		token, err = deviceLogin(ctx, os.Stderr)
+		if err != nil {
+			return err
+		}
+	}
+
+	where, err := httpclient.StoreLoginToken(gitHubAuthHost, token)
+	if err != nil {
+		return fmt.Errorf("failed to store the token: %w", err)
+	}
+	log.Infof("Stored the token for %s in %s", gitHubAuthHost, where)
+	if os.Getenv("GITHUB_TOKEN") != "" || os.Getenv("GH_TOKEN") != "" {
+		log.Warn("GITHUB_TOKEN or GH_TOKEN is set and takes precedence over the stored token")
+	}
+	return nil
+}
+
+// deviceLogin logs in with the device flow, showing the code to enter on w
+func deviceLogin(ctx context.Context, w io.Writer) (string, error) {
+	clientID := authClientID
+	if clientID == "" {
+		clientID = os.Getenv("BINSTALLER_OAUTH_CLIENT_ID")
+	}
+	if clientID == "" {
+		clientID = auth.DefaultClientID
+	}
+	if clientID == "" {
+		return "", errors.New("this build of binst has no OAuth client ID; pass --client-id, set BINSTALLER_OAUTH_CLIENT_ID, or use --with-token")
+	}
+
+	flow := &auth.DeviceFlow{ClientID: clientID, Scopes: authScopes, BaseURL: gitHubLoginBaseURL}
+	code, err := flow.RequestCode(ctx)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(w, "First copy your one-time code: %s\n", code.UserCode)
+	fmt.Fprintf(w, "Then open %s in your browser to authorize binstaller\n", code.VerificationURI)
+	log.Info("Waiting for authorization...")
+	token, err := flow.PollToken(ctx, code)
+	if err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+// printAuthStatus writes the GitHub account and rate limit of the token in use to w
+func printAuthStatus(ctx context.Context, w io.Writer) error {
+	source := httpclient.GitHubTokenSource()
+	if source == "" {
+		fmt.Fprintf(w, "Not logged in to %s; requests are unauthenticated\n", gitHubAuthHost)
+		return errors.New("not logged in; run 'binst auth login'")
+	}
+
+	req, err := httpclient.NewRequestWithGitHubAuth("GET", cmp.Or(gitHubAPIBaseURL, httpclient.DefaultGitHubAPIURL)+"/user")
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := httpclient.NewGitHubClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach the GitHub API: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized {
+		fmt.Fprintf(w, "The token from %s is invalid or expired\n", source)
+		return errors.New("invalid token; run 'binst auth login' again")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+	}
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return fmt.Errorf("failed to parse the user: %w", err)
+	}
+
+	fmt.Fprintf(w, "Logged in to %s as %s (token from %s)\n", gitHubAuthHost, user.Login, source)
+	if scopes := resp.Header.Get("X-OAuth-Scopes"); scopes != "" {
+		fmt.Fprintf(w, "Token scopes: %s\n", scopes)
+	}
+	if remaining := resp.Header.Get("X-RateLimit-Remaining"); remaining != "" {
+		fmt.Fprintf(w, "Rate limit: %s of %s requests remaining\n", remaining, resp.Header.Get("X-RateLimit-Limit"))
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDeviceLogin(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/login/device/code":
+			w.Write([]byte(`{"device_code":"dc","user_code":"ABCD-1234","verification_uri":"https://github.com/login/device","expires_in":60,"interval":0}`))
+		case "/login/oauth/access_token":
+			w.Write([]byte(`{"access_token":"gho_test","token_type":"bearer","scope":"repo"}`))
+		}
+	}))
+	defer server.Close()
+	oldURL, oldClientID := gitHubLoginBaseURL, authClientID
+	gitHubLoginBaseURL, authClientID = server.URL, "client"
+	defer func() { gitHubLoginBaseURL, authClientID = oldURL, oldClientID }()
+
+	var out bytes.Buffer
+	token, err := deviceLogin(context.Background(), &out)
+	if err != nil {
+		t.Fatalf("deviceLogin() error = %v", err)
+	}
+	if token != "gho_test" {
+		t.Errorf("deviceLogin() = %q, want gho_test", token)
+	}
+	if !strings.Contains(out.String(), "ABCD-1234") || !strings.Contains(out.String(), "https://github.com/login/device") {
+		t.Errorf("deviceLogin() output = %q, want the code and verification URL", out.String())
+	}
+
+	authClientID = ""
+	t.Setenv("BINSTALLER_OAUTH_CLIENT_ID", "")
+	if _, err := deviceLogin(context.Background(), &out); err == nil || !strings.Contains(err.Error(), "--with-token") {
+		t.Errorf("deviceLogin() without a client ID error = %v", err)
+	}
+}
+
+func TestPrintAuthStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/user" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("X-OAuth-Scopes", "repo")
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		w.Write([]byte(`{"login":"octocat"}`))
+	}))
+	defer server.Close()
+	oldURL := gitHubAPIBaseURL
+	gitHubAPIBaseURL = server.URL
+	defer func() { gitHubAPIBaseURL = oldURL }()
+
+	t.Setenv("GITHUB_TOKEN", "ghp_test")
+	var out bytes.Buffer
+	if err := printAuthStatus(context.Background(), &out); err != nil {
+		t.Fatalf("printAuthStatus() error = %v", err)
+	}
+	want := "Logged in to github.com as octocat (token from GITHUB_TOKEN environment variable)\nToken scopes: repo\nRate limit: 4999 of 5000 requests remaining\n"
+	if out.String() != want {
+		t.Errorf("printAuthStatus() output = %q, want %q", out.String(), want)
+	}
+
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("BINSTALLER_NO_KEYRING", "1")
+	out.Reset()
+	if err := printAuthStatus(context.Background(), &out); err == nil {
+		t.Error("printAuthStatus() without a token should fail")
+	}
+	if !strings.HasPrefix(out.String(), "Not logged in") {
+		t.Errorf("printAuthStatus() output = %q", out.String())
+	}
+}
@@ -17,13 +17,15 @@ var (
 
 func main() {
 	// Use fang to execute the command with enhanced features
-	if err := fang.Execute(
+	err := fang.Execute(
 		context.Background(),
 		cmd.RootCmd,
 		fang.WithVersion(version),
 		fang.WithCommit(commit),
 		fang.WithNotifySignal(syscall.SIGINT, syscall.SIGTERM),
-	); err != nil {
+	)
+	cmd.WriteMetrics(err)
+	if err != nil {
 		os.Exit(1)
 	}
 }
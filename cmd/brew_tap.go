@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/export/homebrew"
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Flags for brew-tap sync command
+	brewTapRepo        string
+	brewTapDir         string
+	brewTapSpecsDir    string
+	brewTapBranch      string
+	brewTapPush        bool
+	brewTapPullRequest bool
+	brewTapDryRun      bool
+)
+
+// BrewTapCommand represents the brew-tap command
+var BrewTapCommand = &cobra.Command{
+	Use:   "brew-tap",
+	Short: "Maintain a Homebrew tap derived from InstallSpecs",
+}
+
+// BrewTapSyncCommand represents the brew-tap sync command
+var BrewTapSyncCommand = &cobra.Command{
+	Use:   "sync",
+	Short: "Regenerate every formula of a Homebrew tap from a directory of InstallSpecs",
+	Long: `Regenerate Formula/NAME.rb in a Homebrew tap for every InstallSpec in a directory,
+so the tap is fully derived from binstaller configs.
+
+Each formula pins the spec's default_version (or the latest release) and takes its
+SHA-256 hashes from embedded checksums, falling back to the release checksum file.
+Generated formulas whose spec no longer exists are removed; hand-written formulas are
+never touched.
+
+With --push the changes are committed and pushed, on --branch if given. Add
+--pull-request to open a pull request for the branch with the gh CLI.`,
+	Example: `  # Update a local tap checkout
+  binst brew-tap sync --tap-dir ../homebrew-tap --specs .config/binstaller
+
+  # Clone the tap, update it, and push to its default branch
+  binst brew-tap sync --tap org/homebrew-tap --push
+
+  # Propose the update as a pull request
+  binst brew-tap sync --tap org/homebrew-tap --branch binstaller-sync --push --pull-request`,
+	Args: cobra.NoArgs,
+	RunE: runBrewTapSync,
+}
+
+func init() {
+	BrewTapSyncCommand.Flags().StringVar(&brewTapRepo, "tap", "", "Tap repository (owner/homebrew-name) to clone when --tap-dir is not given")
+	BrewTapSyncCommand.Flags().StringVar(&brewTapDir, "tap-dir", "", "Local checkout of the tap")
+	BrewTapSyncCommand.Flags().StringVar(&brewTapSpecsDir, "specs", ProjectToolsDir, "Directory of InstallSpec files (*.yml, *.yaml)")
+	BrewTapSyncCommand.Flags().StringVar(&brewTapBranch, "branch", "", "Commit the update on this new branch")
+	BrewTapSyncCommand.Flags().BoolVar(&brewTapPush, "push", false, "Commit and push the updated formulas")
+	BrewTapSyncCommand.Flags().BoolVar(&brewTapPullRequest, "pull-request", false, "Open a pull request for --branch with the gh CLI")
+	BrewTapSyncCommand.Flags().BoolVarP(&brewTapDryRun, "dry-run", "n", false, "Show changed formulas without writing them")
+	BrewTapCommand.AddCommand(BrewTapSyncCommand)
+}
+
+func runBrewTapSync(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if brewTapDir == "" && brewTapRepo == "" {
+		return fmt.Errorf("either --tap or --tap-dir is required")
+	}
+	if brewTapPullRequest && brewTapBranch == "" {
+		return fmt.Errorf("--pull-request requires --branch")
+	}
+
+	tapDir := brewTapDir
+	if tapDir == "" {
+		tmpDir, err := os.MkdirTemp("", "binst-tap-")
+		if err != nil {
+			return fmt.Errorf("failed to create temp directory: %w", err)
+		}
+		defer os.RemoveAll(tmpDir)
+		tapDir = tmpDir
+		log.Infof("Cloning %s", brewTapRepo)
+		if err := runGit(ctx, "", "clone", "--depth", "1", "https://github.com/"+brewTapRepo+".git", tapDir); err != nil {
+			return err
+		}
+	}
+
+	changed, err := syncBrewTap(ctx, brewTapSpecsDir, tapDir, brewTapDryRun)
+	if err != nil {
+		return err
+	}
+	if len(changed) == 0 {
+		log.Info("Tap is up to date")
+		return nil
+	}
+	for _, file := range changed {
+		log.Infof("Updated %s", file)
+	}
+	if brewTapDryRun || !brewTapPush {
+		return nil
+	}
+	return publishBrewTap(ctx, tapDir, changed)
+}
+
+// syncBrewTap regenerates the formulas of every spec in specsDir into tapDir/Formula
+// and returns the tap-relative paths of changed or removed formulas
+func syncBrewTap(ctx context.Context, specsDir, tapDir string, dryRun bool) ([]string, error) {
+	specFiles, err := listSpecFiles(specsDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(specFiles) == 0 {
+		return nil, fmt.Errorf("no InstallSpec files found in %s", specsDir)
+	}
+
+	formulaDir := filepath.Join(tapDir, "Formula")
+	generated := make(map[string]bool)
+	var changed []string
+	var failed []string
+	for _, specFile := range specFiles {
+		name, content, err := renderFormula(ctx, specFile)
+		if err != nil {
+			log.WithError(err).Errorf("Failed to generate formula from %s", specFile)
+			failed = append(failed, specFile)
+			continue
+		}
+		file := name + ".rb"
+		generated[file] = true
+
+		formulaPath := filepath.Join(formulaDir, file)
+		if existing, err := os.ReadFile(formulaPath); err == nil && bytes.Equal(existing, content) {
+			continue
+		}
+		changed = append(changed, filepath.ToSlash(filepath.Join("Formula", file)))
+		if dryRun {
+			continue
+		}
+		if err := os.MkdirAll(formulaDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create formula directory: %w", err)
+		}
+		if err := os.WriteFile(formulaPath, content, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", formulaPath, err)
+		}
+	}
+	if len(failed) > 0 {
+		return nil, fmt.Errorf("failed to generate formulas for %s", strings.Join(failed, ", "))
+	}
+
+	// Remove generated formulas whose spec is gone
+	entries, err := os.ReadDir(formulaDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read formula directory: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".rb") || generated[entry.Name()] {
+			continue
+		}
+		formulaPath := filepath.Join(formulaDir, entry.Name())
+		content, err := os.ReadFile(formulaPath)
+		if err != nil || !bytes.HasPrefix(content, []byte(homebrew.GeneratedHeader)) {
+			continue
+		}
+		changed = append(changed, filepath.ToSlash(filepath.Join("Formula", entry.Name())))
+		if dryRun {
+			continue
+		}
+		if err := os.Remove(formulaPath); err != nil {
+			return nil, fmt.Errorf("failed to remove %s: %w", formulaPath, err)
+		}
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// listSpecFiles returns the InstallSpec files in dir, sorted by name
+func listSpecFiles(dir string) ([]string, error) {
+	var files []string
+	for _, pattern := range []string{"*.yml", "*.yaml"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// renderFormula renders the Homebrew formula of a spec file and returns the formula name and content
+func renderFormula(ctx context.Context, specFile string) (string, []byte, error) {
+	installSpec, err := loadInstallSpec(specFile)
+	if err != nil {
+		return "", nil, err
+	}
+	installSpec.SetDefaults()
+	ctx = specContext(ctx, installSpec)
+
+	tag, err := resolveVersion(ctx, installSpec, spec.StringValue(installSpec.DefaultVersion))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to resolve version: %w", err)
+	}
+	formula, err := homebrew.New(installSpec, tag, releaseChecksumFunc(ctx, installSpec, tag))
+	if err != nil {
+		return "", nil, err
+	}
+	content, err := formula.Render()
+	if err != nil {
+		return "", nil, err
+	}
+	return formula.Name, content, nil
+}
+
+// publishBrewTap commits the changed formulas and pushes them, optionally opening a pull request
+func publishBrewTap(ctx context.Context, tapDir string, changed []string) error {
+	if brewTapBranch != "" {
+		if err := runGit(ctx, tapDir, "checkout", "-b", brewTapBranch); err != nil {
+			return err
+		}
+	}
+	if err := runGit(ctx, tapDir, append([]string{"add", "--all", "--"}, changed...)...); err != nil {
+		return err
+	}
+	message := fmt.Sprintf("Update %d formula(s) from binstaller specs", len(changed))
+	if err := runGit(ctx, tapDir, "commit", "-m", message); err != nil {
+		return err
+	}
+	pushArgs := []string{"push", "origin", "HEAD"}
+	if brewTapBranch != "" {
+		pushArgs = []string{"push", "--set-upstream", "origin", brewTapBranch}
+	}
+	if err := runGit(ctx, tapDir, pushArgs...); err != nil {
+		return err
+	}
+	log.Infof("Pushed %d formula update(s)", len(changed))
+
+	if !brewTapPullRequest {
+		return nil
+	}
+	c := exec.CommandContext(ctx, "gh", "pr", "create", "--fill", "--head", brewTapBranch)
+	c.Dir = tapDir
+	c.Stdout, c.Stderr = os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("failed to open pull request: %w", err)
+	}
+	return nil
+}
+
+// runGit runs a git command in dir
+func runGit(ctx context.Context, dir string, args ...string) error {
+	log.Debugf("git %s", strings.Join(args, " "))
+	c := exec.CommandContext(ctx, "git", args...)
+	c.Dir = dir
+	var stderr bytes.Buffer
+	c.Stderr = &stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/export/homebrew"
+)
+
+func TestSyncBrewTap(t *testing.T) {
+	root := t.TempDir()
+	specsDir := filepath.Join(root, "specs")
+	tapDir := filepath.Join(root, "tap")
+
+	writeTestFile(t, filepath.Join(specsDir, "tool.yml"), `repo: owner/tool
+default_version: v1.0.0
+asset:
+  template: ${NAME}_${OS}_${ARCH}.tar.gz
+checksums:
+  embedded_checksums:
+    v1.0.0:
+      - filename: tool_darwin_arm64.tar.gz
+        hash: aaaa
+      - filename: tool_linux_amd64.tar.gz
+        hash: bbbb
+supported_platforms:
+  - os: darwin
+    arch: arm64
+  - os: linux
+    arch: amd64
+`, 0644)
+	writeTestFile(t, filepath.Join(tapDir, "Formula", "stale.rb"), homebrew.GeneratedHeader+"\nclass Stale < Formula\nend\n", 0644)
+	writeTestFile(t, filepath.Join(tapDir, "Formula", "manual.rb"), "class Manual < Formula\nend\n", 0644)
+
+	ctx := context.Background()
+	changed, err := syncBrewTap(ctx, specsDir, tapDir, true)
+	if err != nil {
+		t.Fatalf("syncBrewTap() dry run error = %v", err)
+	}
+	want := []string{"Formula/stale.rb", "Formula/tool.rb"}
+	if !reflect.DeepEqual(changed, want) {
+		t.Errorf("syncBrewTap() dry run changed = %v, want %v", changed, want)
+	}
+	if _, err := os.Stat(filepath.Join(tapDir, "Formula", "tool.rb")); !os.IsNotExist(err) {
+		t.Error("syncBrewTap() dry run wrote a formula")
+	}
+
+	changed, err = syncBrewTap(ctx, specsDir, tapDir, false)
+	if err != nil {
+		t.Fatalf("syncBrewTap() error = %v", err)
+	}
+	if !reflect.DeepEqual(changed, want) {
+		t.Errorf("syncBrewTap() changed = %v, want %v", changed, want)
+	}
+	formula, err := os.ReadFile(filepath.Join(tapDir, "Formula", "tool.rb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{`sha256 "aaaa"`, `sha256 "bbbb"`, `version "1.0.0"`} {
+		if !strings.Contains(string(formula), s) {
+			t.Errorf("formula missing %q:\n%s", s, formula)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(tapDir, "Formula", "stale.rb")); !os.IsNotExist(err) {
+		t.Error("syncBrewTap() kept a stale generated formula")
+	}
+	if _, err := os.Stat(filepath.Join(tapDir, "Formula", "manual.rb")); err != nil {
+		t.Error("syncBrewTap() removed a hand-written formula")
+	}
+
+	// A second sync is a no-op
+	changed, err = syncBrewTap(ctx, specsDir, tapDir, false)
+	if err != nil {
+		t.Fatalf("syncBrewTap() error = %v", err)
+	}
+	if len(changed) != 0 {
+		t.Errorf("syncBrewTap() second run changed = %v", changed)
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"net/http"
 	"net/url"
 	"os"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"text/tabwriter"
@@ -16,6 +18,7 @@ import (
 	"github.com/apex/log"
 	"github.com/binary-install/binstaller/pkg/asset"
 	"github.com/binary-install/binstaller/pkg/httpclient"
+	"github.com/binary-install/binstaller/pkg/resolver"
 	"github.com/binary-install/binstaller/pkg/spec"
 	"github.com/buildkite/interpolate"
 	"github.com/spf13/cobra"
@@ -23,9 +26,15 @@ import (
 
 var (
 	// Flags for check command
-	checkVersion        string
-	checkCheckAssets    bool
-	checkIgnorePatterns []string
+	checkVersion         string
+	checkCheckAssets     bool
+	checkIgnorePatterns  []string
+	checkDeep            bool
+	checkDeepConcurrency int
+	checkDeepMaxSize     int64
+	checkVerifyEmbedded  bool
+	checkConcurrency     int
+	checkOutput          string
 )
 
 // CheckCommand represents the check command
@@ -37,11 +46,19 @@ var CheckCommand = &cobra.Command{
 - Generating asset filenames for all configured platforms
 - Verifying if assets exist in the GitHub release (default: enabled)
 - Validating checksums template configuration
+- Optionally downloading and hashing every asset (--deep)
+- Optionally comparing embedded checksums with the release checksum file (--verify-embedded)
 
 This helps validate your configuration before generating installer scripts.
 
+Asset filenames of the platforms are generated concurrently (--concurrency) and
+checked against the release asset list. When the release cannot be listed, e.g.
+because of API rate limits, each generated asset is checked with a HEAD request
+instead. A summary of the status counts follows the table.
+
 Asset Status Meanings:
   ✓ EXISTS       - Asset generated from config exists in GitHub release
+  ✓ FALLBACK     - Primary asset is missing but a fallback_templates candidate exists
   ✗ MISSING      - Asset generated from config not found in release
   ✗ NO MATCH     - Release asset exists but doesn't match any configured platform
   ⚠ NOT SUPPORTED - Feature not supported (e.g., per-asset checksums)
@@ -52,9 +69,35 @@ The unified table shows:
 2. Checksums file status (if configured)
 3. Unmatched release assets that might need configuration
 
+With --output json or --output yaml, the same results are written to stdout as
+a report instead of the table, for CI pipelines: the filename and status of
+every platform, the checksums file, the unmatched and ignored release assets,
+the status counts and whether the check passed. Statuses are lower case, e.g.
+exists, fallback, missing or no_match. Logs still go to stderr, and the exit
+code is the same as with the table.
+
+Deep Verification (--deep):
+  Downloads the asset for every configured platform, computes its checksum, and
+  compares it against the embedded checksums and the release checksum file.
+  ✓ VERIFIED      - Hash matches every available checksum source
+  ✗ MISMATCH      - Hash differs from an embedded or release checksum
+  ✗ FAILED        - Asset could not be downloaded or hashed
+  ⚠ NO CHECKSUM   - Neither embedded nor release checksum is available
+  ⚠ TOO LARGE     - Asset exceeds --deep-max-size and was not verified
+
+Embedded Checksum Verification (--verify-embedded):
+  Downloads the release checksum file of every version in embedded_checksums
+  (or only --version) and compares each embedded hash with the upstream one.
+  ✓ MATCH              - Embedded hash equals the checksum file entry
+  ✗ MISMATCH           - Embedded hash differs from the checksum file entry
+  ⚠ NOT IN UPSTREAM    - The checksum file has no entry for the asset
+  ⚠ NO CHECKSUM FILE   - The checksum file could not be downloaded
+
 Exit Codes:
   0 - All checks passed (no MISSING or NO MATCH statuses)
-  1 - Configuration issues detected (MISSING assets or NO MATCH files)`,
+  1 - Configuration issues detected (MISSING assets or NO MATCH files,
+      or MISMATCH/FAILED assets with --deep, or MISMATCH embedded
+      checksums with --verify-embedded)`,
 	Example: `  # Check the default config file
   binst check
 
@@ -67,9 +110,24 @@ Exit Codes:
   # Check with a specific version
   binst check --version v1.2.3
 
+  # Generate and check the assets of many platforms with more workers
+  binst check --concurrency 16
+
   # Ignore additional file patterns
-  binst check --ignore "\.AppImage$" --ignore ".*-musl.*"`,
+  binst check --ignore "\.AppImage$" --ignore ".*-musl.*"
+
+  # Download and verify every asset of a release before publishing installers
+  binst check --deep --version v1.2.3 --deep-concurrency 8
+
+  # Report embedded checksums that disagree with the upstream checksum files
+  binst check --verify-embedded --check-assets=false
+
+  # Fail a release pipeline on missing assets and list them
+  binst check --version v1.2.3 --output json | jq -r '.assets[] | select(.status == "missing") | .platform'`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateCheckOutput(checkOutput, checkDeep, checkVerifyEmbedded); err != nil {
+			return err
+		}
 		log.Info("Running check command...")
 
 		// Determine config file path using common logic
@@ -105,6 +163,8 @@ Exit Codes:
 		}
 
 		log.Info("✓ InstallSpec validation passed")
+		warnPlatformDetection(installSpec)
+		ctx := specContext(context.Background(), installSpec)
 
 		// Generate asset filenames for all supported platforms
 		log.Info("Generating asset filenames for all supported platforms...")
@@ -116,11 +176,10 @@ Exit Codes:
 
 		// If checking assets and version is not specified or is "latest",
 		// resolve the actual latest version from GitHub
-		if checkCheckAssets && (version == "" || version == "latest") {
-			ctx := context.Background()
+		if (checkCheckAssets || checkDeep) && (version == "" || version == "latest") {
 			repo := spec.StringValue(installSpec.Repo)
 			if repo != "" {
-				resolvedVersion, err := resolveLatestVersion(ctx, repo)
+				resolvedVersion, err := resolveLatestVersion(ctx, installSpec)
 				if err != nil {
 					log.WithError(err).Warn("Failed to resolve latest version, using default")
 					version = "1.0.0" // Fallback to example version
@@ -142,7 +201,6 @@ Exit Codes:
 		// Check if assets exist in GitHub release if requested
 		if checkCheckAssets {
 			log.Info("Checking if assets exist in GitHub release...")
-			ctx := context.Background()
 			// When check-assets is on and no platforms specified, use asset-based detection
 			if len(installSpec.SupportedPlatforms) == 0 {
 				err := checkAssetsExistWithDetection(ctx, installSpec, version)
@@ -158,12 +216,37 @@ Exit Codes:
 				}
 			}
 
+		} else if checkOutput != checkOutputTable {
+			report := newCheckReport(installSpec, version, false)
+			for _, platform := range slices.Sorted(maps.Keys(assetFilenames)) {
+				report.add(platform, assetFilenames[platform], "")
+			}
+			report.addChecksumStatuses(installSpec, nil)
+			if err := writeCheckReport(os.Stdout, checkOutput, report); err != nil {
+				return err
+			}
 		} else {
 			// Only display the generated filenames if not checking assets
 			// (checkAssetsExist displays its own table with status)
 			displayAssetFilenames(assetFilenames)
 		}
 
+		if checkDeep {
+			log.Info("Downloading and verifying every asset...")
+			if err := deepVerifyAssets(ctx, installSpec, version, assetFilenames, checkDeepConcurrency, checkDeepMaxSize*1024*1024); err != nil {
+				log.WithError(err).Error("Deep verification failed")
+				return fmt.Errorf("deep verification failed: %w", err)
+			}
+		}
+
+		if checkVerifyEmbedded {
+			log.Info("Comparing embedded checksums with the release checksum files...")
+			if err := verifyEmbeddedChecksums(ctx, os.Stdout, installSpec, checkVersion); err != nil {
+				log.WithError(err).Error("Embedded checksum verification failed")
+				return fmt.Errorf("embedded checksum verification failed: %w", err)
+			}
+		}
+
 		log.Info("✓ Check completed successfully")
 		return nil
 	},
@@ -175,8 +258,11 @@ func validateSpec(installSpec *spec.InstallSpec) error {
 		return fmt.Errorf("repo field is required")
 	}
 
-	// Validate repository format (owner/repo)
+	// Validate repository format (owner/repo, or a nested GitLab project path)
 	repoPattern := regexp.MustCompile(`^[a-zA-Z0-9._-]+/[a-zA-Z0-9._-]+$`)
+	if installSpec.GetSource() == spec.Gitlab {
+		repoPattern = regexp.MustCompile(`^[a-zA-Z0-9._-]+(/[a-zA-Z0-9._-]+)+$`)
+	}
 	if !repoPattern.MatchString(*installSpec.Repo) {
 		return fmt.Errorf("repo must be in format 'owner/repo', got: %s", *installSpec.Repo)
 	}
@@ -269,7 +355,7 @@ func displayAssetFilenames(assetFilenames map[string]string) {
 	w.Flush()
 }
 
-// checkAssetsExist checks if the generated asset filenames exist in the GitHub release
+// checkAssetsExist checks if the generated asset filenames exist in the release
 func checkAssetsExist(ctx context.Context, installSpec *spec.InstallSpec, version string, assetFilenames map[string]string) error {
 	repo := spec.StringValue(installSpec.Repo)
 	if repo == "" {
@@ -279,21 +365,6 @@ func checkAssetsExist(ctx context.Context, installSpec *spec.InstallSpec, versio
 	// Version should already be resolved at this point
 	log.Infof("Checking assets for version: %s", version)
 
-	// Fetch all release assets once
-	releaseAssets, err := fetchReleaseAssets(ctx, repo, version)
-	if err != nil {
-		return fmt.Errorf("failed to fetch release assets: %w", err)
-	}
-
-	// Create a map of existing assets for quick lookup
-	existingAssets := make(map[string]bool)
-	for _, asset := range releaseAssets {
-		existingAssets[asset] = true
-	}
-
-	// Track if we have any issues
-	hasIssues := false
-
 	// Check checksums filename if configured
 	checksumFilename := ""
 	checksumError := ""
@@ -308,6 +379,51 @@ func checkAssetsExist(ctx context.Context, installSpec *spec.InstallSpec, versio
 		}
 	}
 
+	// Generate the candidates of every platform concurrently: the primary
+	// asset first, then the fallback_templates
+	platforms := make([]string, 0, len(assetFilenames))
+	for platform := range assetFilenames {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+	generator := asset.NewFilenameGenerator(installSpec, version)
+	generator.Concurrency = checkConcurrency
+	all, err := generator.GenerateAll(ctx)
+	if err != nil {
+		return err
+	}
+	candidates := make([][]string, len(platforms))
+	for i, platform := range platforms {
+		osName, arch, _ := strings.Cut(platform, "/")
+		candidates[i] = all[asset.Platform{OS: strings.ToLower(osName), Arch: strings.ToLower(arch)}]
+		if len(candidates[i]) == 0 {
+			candidates[i] = []string{assetFilenames[platform]}
+		}
+	}
+
+	// Fetch all release assets once, or check the generated assets one by
+	// one when the release cannot be listed (e.g. API rate limits)
+	existingAssets := make(map[string]bool)
+	releaseAssets, err := fetchReleaseAssets(ctx, installSpec, version)
+	if err != nil {
+		log.WithError(err).Warn("Failed to list release assets; checking the generated assets with HEAD requests, unmatched release assets are not reported")
+		var filenames []string
+		for _, c := range candidates {
+			filenames = append(filenames, c...)
+		}
+		if checksumFilename != "" {
+			filenames = append(filenames, checksumFilename)
+		}
+		existingAssets = headReleaseAssets(ctx, installSpec, version, filenames, checkConcurrency)
+	}
+	for _, asset := range releaseAssets {
+		existingAssets[asset] = true
+	}
+	released := maps.Clone(existingAssets)
+
+	// Track if we have any issues
+	hasIssues := false
+
 	// Build a comprehensive list of all assets
 	type assetEntry struct {
 		platform string
@@ -317,11 +433,21 @@ func checkAssetsExist(ctx context.Context, installSpec *spec.InstallSpec, versio
 	}
 	var allAssets []assetEntry
 
-	// Add configured platform assets
-	for platform, filename := range assetFilenames {
-		status := "✓ EXISTS"
-		if !existingAssets[filename] {
-			status = "✗ MISSING"
+	// Add configured platform assets, trying the fallback candidates of a
+	// platform when its primary asset is missing
+	for p, platform := range platforms {
+		filename := assetFilenames[platform]
+		status := "✗ MISSING"
+		for i, candidate := range candidates[p] {
+			if existingAssets[candidate] {
+				status = "✓ EXISTS"
+				if i > 0 {
+					filename, status = candidate, "✓ FALLBACK"
+				}
+				break
+			}
+		}
+		if status == "✗ MISSING" {
 			hasIssues = true
 		}
 		allAssets = append(allAssets, assetEntry{
@@ -330,8 +456,10 @@ func checkAssetsExist(ctx context.Context, installSpec *spec.InstallSpec, versio
 			status:   status,
 			priority: 0,
 		})
-		// Mark as processed
-		delete(existingAssets, filename)
+		// Mark every candidate as processed
+		for _, candidate := range candidates[p] {
+			delete(existingAssets, candidate)
+		}
 	}
 
 	// Add checksums if configured
@@ -389,10 +517,11 @@ func checkAssetsExist(ctx context.Context, installSpec *spec.InstallSpec, versio
 			// Define status order
 			statusOrder := map[string]int{
 				"✓ EXISTS":        0,
-				"✗ MISSING":       1,
-				"✗ NO MATCH":      2,
-				"⚠ NOT SUPPORTED": 3,
-				"-":               4,
+				"✓ FALLBACK":      1,
+				"✗ MISSING":       2,
+				"✗ NO MATCH":      3,
+				"⚠ NOT SUPPORTED": 4,
+				"-":               5,
 			}
 			return statusOrder[allAssets[i].status] < statusOrder[allAssets[j].status]
 		}
@@ -400,17 +529,32 @@ func checkAssetsExist(ctx context.Context, installSpec *spec.InstallSpec, versio
 		return allAssets[i].filename < allAssets[j].filename
 	})
 
-	// Display unified table
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "PLATFORM\tASSET FILENAME\tSTATUS")
-	fmt.Fprintln(w, "--------\t--------------\t------")
+	if checkOutput != checkOutputTable {
+		report := newCheckReport(installSpec, version, true)
+		report.OK = !hasIssues
+		for _, asset := range allAssets {
+			report.add(asset.platform, asset.filename, asset.status)
+		}
+		report.addChecksumStatuses(installSpec, released)
+		if err := writeCheckReport(os.Stdout, checkOutput, report); err != nil {
+			return err
+		}
+	} else {
+		// Display unified table
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PLATFORM\tASSET FILENAME\tSTATUS")
+		fmt.Fprintln(w, "--------\t--------------\t------")
+
+		statuses := make([]string, len(allAssets))
+		for i, asset := range allAssets {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", asset.platform, asset.filename, asset.status)
+			statuses[i] = asset.status
+		}
 
-	for _, asset := range allAssets {
-		fmt.Fprintf(w, "%s\t%s\t%s\n", asset.platform, asset.filename, asset.status)
+		w.Flush()
+		printStatusSummary(os.Stdout, statuses)
 	}
 
-	w.Flush()
-
 	// Return error if there are any issues
 	if hasIssues {
 		return fmt.Errorf("configuration issues detected: missing assets or unmatched files")
@@ -419,55 +563,29 @@ func checkAssetsExist(ctx context.Context, installSpec *spec.InstallSpec, versio
 	return nil
 }
 
-// resolveLatestVersion resolves "latest" to the actual latest release tag
-func resolveLatestVersion(ctx context.Context, repo string) (string, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/releases/latest", repo)
-
-	req, err := httpclient.NewRequestWithGitHubAuth("GET", url)
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-	req = req.WithContext(ctx)
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to fetch latest release: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
-	}
-
-	var release struct {
-		TagName string `json:"tag_name"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		return "", fmt.Errorf("failed to parse release response: %w", err)
-	}
-
-	return release.TagName, nil
+// resolveLatestVersion resolves "latest" to the actual latest tag using the spec's version source
+func resolveLatestVersion(ctx context.Context, installSpec *spec.InstallSpec) (string, error) {
+	return resolver.New(installSpec).Latest(ctx)
 }
 
-// fetchReleaseAssets fetches all assets from a GitHub release
-func fetchReleaseAssets(ctx context.Context, repo, version string) ([]string, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/releases/tags/%s", repo, url.PathEscape(version))
+// fetchReleaseAssets fetches all assets from a GitHub release, or the asset
+// links of a GitLab release
+func fetchReleaseAssets(ctx context.Context, installSpec *spec.InstallSpec, version string) ([]string, error) {
+	gitLab := installSpec.GetSource() == spec.Gitlab
+	apiURL := fmt.Sprintf("%s/repos/%s/releases/tags/%s", gitHubAPIURL(installSpec), installSpec.GetRepo(), url.PathEscape(version))
+	if gitLab {
+		apiURL = fmt.Sprintf("%s/api/v4/projects/%s/releases/%s", releaseBaseURL(installSpec), url.PathEscape(installSpec.GetRepo()), url.PathEscape(version))
+	}
 
-	req, err := httpclient.NewRequestWithGitHubAuth("GET", url)
+	req, err := httpclient.NewRequestWithGitHubAuth("GET", apiURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 	req = req.WithContext(ctx)
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
+	client := httpclient.NewGitHubClient()
+	client.Timeout = 30 * time.Second
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch release: %w", err)
@@ -475,9 +593,30 @@ func fetchReleaseAssets(ctx context.Context, repo, version string) ([]string, er
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
+		if gitLab {
+			return nil, fmt.Errorf("GitLab API returned status %d", resp.StatusCode)
+		}
 		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
 	}
 
+	if gitLab {
+		var release struct {
+			Assets struct {
+				Links []struct {
+					Name string `json:"name"`
+				} `json:"links"`
+			} `json:"assets"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+			return nil, fmt.Errorf("failed to parse release response: %w", err)
+		}
+		assets := make([]string, len(release.Assets.Links))
+		for i, link := range release.Assets.Links {
+			assets[i] = link.Name
+		}
+		return assets, nil
+	}
+
 	var release struct {
 		Assets []struct {
 			Name string `json:"name"`
@@ -506,40 +645,31 @@ func checkAssetsExistWithDetection(ctx context.Context, installSpec *spec.Instal
 	log.Infof("Checking assets for version: %s", version)
 
 	// Fetch all release assets
-	releaseAssets, err := fetchReleaseAssets(ctx, repo, version)
+	releaseAssets, err := fetchReleaseAssets(ctx, installSpec, version)
 	if err != nil {
 		return fmt.Errorf("failed to fetch release assets: %w", err)
 	}
 
 	// Create filename generator
 	generator := asset.NewFilenameGenerator(installSpec, version)
+	generator.Concurrency = checkConcurrency
 
 	// Track if we have any issues
 	hasIssues := false
 
-	// Get all possible platforms using the same approach as embed-checksums
-	platforms := generator.GetAllPossiblePlatforms()
+	// Generate the filenames of all possible platforms concurrently, the
+	// same platforms embed-checksums uses
+	all, err := generator.GenerateAll(ctx)
+	if err != nil {
+		return err
+	}
 
-	// Generate all possible asset filenames
 	assetFilenames := make(map[string]string) // filename -> platform
-	for _, platform := range platforms {
-		os := spec.PlatformOSString(platform.OS)
-		arch := spec.PlatformArchString(platform.Arch)
-
-		if os == "" || arch == "" {
-			continue
-		}
-
-		filename, err := generator.GenerateFilename(os, arch)
-		if err != nil {
-			continue
-		}
-
-		if filename != "" {
-			platformKey := fmt.Sprintf("%s/%s", os, arch)
+	for _, platform := range generator.Platforms() {
+		for _, filename := range all[platform] {
 			// Store the first matching platform for each filename
-			if _, exists := assetFilenames[filename]; !exists {
-				assetFilenames[filename] = platformKey
+			if _, exists := assetFilenames[filename]; filename != "" && !exists {
+				assetFilenames[filename] = platform.String()
 			}
 		}
 	}
@@ -557,11 +687,6 @@ func checkAssetsExistWithDetection(ctx context.Context, installSpec *spec.Instal
 	}
 	sort.Strings(filenames)
 
-	// Display results based on release assets (not all possible combinations)
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "ASSET FILENAME\tDETECTED PLATFORM\tSTATUS")
-	fmt.Fprintln(w, "--------------\t-----------------\t------")
-
 	// Check if checksums file is configured
 	checksumFilename := ""
 	if installSpec.Checksums != nil && installSpec.Checksums.Template != nil {
@@ -639,30 +764,49 @@ func checkAssetsExistWithDetection(ctx context.Context, installSpec *spec.Instal
 		return assets[i].name < assets[j].name
 	})
 
-	// Display sorted assets
-	for _, asset := range assets {
-		fmt.Fprintf(w, "%s\t%s\t%s\n", asset.name, asset.platform, asset.status)
-	}
-
 	// Add checksums row if configured
 	if installSpec.Checksums != nil && installSpec.Checksums.Template != nil {
 		checksumFilename, err := generateChecksumFilename(installSpec, version)
 		if err != nil {
 			// Show error message for unsupported checksums configuration
 			if strings.Contains(err.Error(), "per-asset checksums") {
-				fmt.Fprintf(w, "(per-asset pattern)\tchecksums\t⚠ NOT SUPPORTED\n")
+				assets = append(assets, assetInfo{name: "(per-asset pattern)", platform: "checksums", status: "⚠ NOT SUPPORTED"})
 			}
 		} else {
 			if releaseAssetMap[checksumFilename] {
-				fmt.Fprintf(w, "%s\tchecksums\t✓ MATCHED\n", checksumFilename)
+				assets = append(assets, assetInfo{name: checksumFilename, platform: "checksums", status: "✓ MATCHED"})
 			} else {
-				fmt.Fprintf(w, "%s\tchecksums\t✗ MISSING\n", checksumFilename)
+				assets = append(assets, assetInfo{name: checksumFilename, platform: "checksums", status: "✗ MISSING"})
 				hasIssues = true
 			}
 		}
 	}
 
-	w.Flush()
+	if checkOutput != checkOutputTable {
+		report := newCheckReport(installSpec, version, true)
+		report.OK = !hasIssues
+		for _, asset := range assets {
+			report.add(asset.platform, asset.name, asset.status)
+		}
+		report.addChecksumStatuses(installSpec, releaseAssetMap)
+		if err := writeCheckReport(os.Stdout, checkOutput, report); err != nil {
+			return err
+		}
+	} else {
+		// Display results based on release assets (not all possible combinations)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ASSET FILENAME\tDETECTED PLATFORM\tSTATUS")
+		fmt.Fprintln(w, "--------------\t-----------------\t------")
+
+		statuses := make([]string, len(assets))
+		for i, asset := range assets {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", asset.name, asset.platform, asset.status)
+			statuses[i] = asset.status
+		}
+
+		w.Flush()
+		printStatusSummary(os.Stdout, statuses)
+	}
 
 	// Return error if there are any issues
 	if hasIssues {
@@ -777,4 +921,10 @@ func init() {
 	CheckCommand.Flags().StringVar(&checkVersion, "version", "", "Check with specific version (default: uses default_version from spec)")
 	CheckCommand.Flags().BoolVar(&checkCheckAssets, "check-assets", true, "Check if generated assets exist in GitHub release")
 	CheckCommand.Flags().StringSliceVar(&checkIgnorePatterns, "ignore", nil, "Additional regex patterns to ignore assets (can be specified multiple times)")
+	CheckCommand.Flags().IntVar(&checkConcurrency, "concurrency", 4, "Number of platforms whose asset filenames are generated, and assets HEAD-requested, at once")
+	CheckCommand.Flags().BoolVar(&checkDeep, "deep", false, "Download and hash every asset, comparing against embedded and release checksums")
+	CheckCommand.Flags().IntVar(&checkDeepConcurrency, "deep-concurrency", 4, "Number of concurrent downloads for --deep")
+	CheckCommand.Flags().Int64Var(&checkDeepMaxSize, "deep-max-size", 512, "Skip assets larger than this size in MiB for --deep (0 for no limit)")
+	CheckCommand.Flags().BoolVar(&checkVerifyEmbedded, "verify-embedded", false, "Compare embedded checksums with the release checksum file and fail on any mismatch")
+	CheckCommand.Flags().StringVar(&checkOutput, "output", checkOutputTable, "Output format (table, json, yaml)")
 }
@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/checksums"
+	"github.com/binary-install/binstaller/pkg/httpclient"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// deepResult is the outcome of downloading and hashing a single platform asset
+type deepResult struct {
+	platform string
+	filename string
+	size     int64
+	hash     string
+	embedded string
+	release  string
+	status   string
+	err      error
+}
+
+// Deep verification statuses
+const (
+	deepStatusVerified   = "✓ VERIFIED"
+	deepStatusMismatch   = "✗ MISMATCH"
+	deepStatusFailed     = "✗ FAILED"
+	deepStatusNoChecksum = "⚠ NO CHECKSUM"
+	deepStatusTooLarge   = "⚠ TOO LARGE"
+)
+
+// deepHashDisplayLength is the number of hash characters shown in the deep verification table
+const deepHashDisplayLength = 12
+
+// errAssetTooLarge is returned when an asset exceeds the --deep-max-size cap
+var errAssetTooLarge = errors.New("asset exceeds size cap")
+
+// deepVerifyAssets downloads every platform asset, hashes it, and compares the hash
+// against the embedded checksums and the release checksum file
+func deepVerifyAssets(ctx context.Context, installSpec *spec.InstallSpec, version string, assetFilenames map[string]string, concurrency int, maxSize int64) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	tempDir, err := os.MkdirTemp("", "binst-check-")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	platforms := make([]string, 0, len(assetFilenames))
+	for platform := range assetFilenames {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+
+	log.Infof("Downloading %d assets (concurrency %d)...", len(platforms), concurrency)
+
+	release := newReleaseChecksums(installSpec, version)
+	results := make([]deepResult, len(platforms))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, platform := range platforms {
+		wg.Add(1)
+		go func(i int, platform string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = deepVerifyAsset(ctx, installSpec, version, release, platform, assetFilenames[platform], filepath.Join(tempDir, fmt.Sprint(i)), maxSize)
+		}(i, platform)
+	}
+	wg.Wait()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PLATFORM\tASSET FILENAME\tSIZE\tHASH\tEMBEDDED\tRELEASE\tSTATUS")
+	fmt.Fprintln(w, "--------\t--------------\t----\t----\t--------\t-------\t------")
+	failed := 0
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", r.platform, r.filename, formatSize(r.size),
+			shortHash(r.hash), compareHash(r.hash, r.embedded), compareHash(r.hash, r.release), r.status)
+		if r.err != nil {
+			log.WithError(r.err).Debugf("%s: %s", r.platform, r.filename)
+		}
+		if r.status == deepStatusMismatch || r.status == deepStatusFailed {
+			failed++
+		}
+	}
+	w.Flush()
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d assets failed deep verification", failed, len(results))
+	}
+	return nil
+}
+
+// deepVerifyAsset downloads and verifies a single platform asset
+func deepVerifyAsset(ctx context.Context, installSpec *spec.InstallSpec, version string, release *releaseChecksums, platform, filename, destPath string, maxSize int64) deepResult {
+	result := deepResult{platform: platform, filename: filename}
+	size, err := downloadCapped(ctx, installSpec, destPath, releaseDownloadURL(installSpec, version, filename), maxSize)
+	result.size = size
+	if errors.Is(err, errAssetTooLarge) {
+		result.status, result.err = deepStatusTooLarge, err
+		return result
+	}
+	if err != nil {
+		result.status, result.err = deepStatusFailed, err
+		return result
+	}
+
+	result.hash, err = checksums.ComputeHash(destPath, string(installSpec.GetChecksums().GetAlgorithm()))
+	if err != nil {
+		result.status, result.err = deepStatusFailed, err
+		return result
+	}
+
+	result.embedded, _ = installSpec.GetChecksums().GetEmbeddedChecksum(version, filename)
+	result.release = release.lookup(ctx, platform, filename)
+
+	switch {
+	case result.embedded == "" && result.release == "":
+		result.status = deepStatusNoChecksum
+	case !hashMatches(result.hash, result.embedded) || !hashMatches(result.hash, result.release):
+		result.status = deepStatusMismatch
+	default:
+		result.status = deepStatusVerified
+	}
+	return result
+}
+
+// releaseChecksums looks up assets in the release checksum files, ignoring embedded
+// checksums. One instance serves a whole check so each checksum file is downloaded once.
+type releaseChecksums struct {
+	mu       sync.Mutex
+	verifier *checksums.Verifier
+}
+
+// newReleaseChecksums returns the release checksum lookup of version, or nil when the
+// spec has no checksums configuration
+func newReleaseChecksums(installSpec *spec.InstallSpec, version string) *releaseChecksums {
+	if installSpec.Checksums == nil {
+		return nil
+	}
+	return &releaseChecksums{verifier: releaseVerifier(installSpec, version)}
+}
+
+// lookup returns the release checksum of filename for platform, or "" when there is none
+func (r *releaseChecksums) lookup(ctx context.Context, platform, filename string) string {
+	if r == nil {
+		return ""
+	}
+	// The verifier's platform is set per lookup, so lookups are serialized
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	hash, err := releaseChecksumLookup(ctx, r.verifier, platform, filename)
+	if err != nil {
+		log.WithError(err).Debugf("No release checksum for %s", filename)
+		return ""
+	}
+	return hash
+}
+
+// releaseVerifier returns a verifier that only consults the release checksum files
+func releaseVerifier(installSpec *spec.InstallSpec, version string) *checksums.Verifier {
+	releaseOnly := *installSpec
+	checksumConfig := *installSpec.Checksums
+	checksumConfig.EmbeddedChecksums = nil
+	releaseOnly.Checksums = &checksumConfig
+
+	verifier := checksums.NewVerifier(&releaseOnly, version)
+	verifier.DownloadBaseURL = releaseBaseURL(installSpec)
+	return verifier
+}
+
+// releaseChecksumLookup looks up filename in the checksum file of platform ("os/arch", or
+// "" for the top-level checksums template)
+func releaseChecksumLookup(ctx context.Context, verifier *checksums.Verifier, platform, filename string) (string, error) {
+	verifier.OS, verifier.Arch, _ = strings.Cut(platform, "/")
+	return verifier.GetChecksum(ctx, filename)
+}
+
+// downloadCapped downloads url to destPath like download, failing with errAssetTooLarge once more
+// than maxSize bytes are announced or received. A maxSize of 0 disables the cap.
+func downloadCapped(ctx context.Context, installSpec *spec.InstallSpec, destPath, url string, maxSize int64) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := httpclient.NewGitHubClientWithPolicy(downloadRetryPolicy(installSpec)).Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to download file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("download failed with status %d", resp.StatusCode)
+	}
+	if maxSize > 0 && resp.ContentLength > maxSize {
+		return resp.ContentLength, errAssetTooLarge
+	}
+
+	out, err := os.Create(destPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create file: %w", err)
+	}
+	defer out.Close()
+
+	body := io.Reader(resp.Body)
+	if maxSize > 0 {
+		body = io.LimitReader(resp.Body, maxSize+1)
+	}
+	n, err := io.Copy(out, body)
+	if err != nil {
+		return n, fmt.Errorf("failed to write file: %w", err)
+	}
+	if maxSize > 0 && n > maxSize {
+		return n, errAssetTooLarge
+	}
+	return n, nil
+}
+
+// hashMatches reports whether actual matches expected; an unknown expected hash always matches
+func hashMatches(actual, expected string) bool {
+	return expected == "" || strings.EqualFold(actual, expected)
+}
+
+// compareHash formats the comparison of a computed hash with an expected one for display
+func compareHash(actual, expected string) string {
+	switch {
+	case expected == "":
+		return "-"
+	case actual == "":
+		return shortHash(expected)
+	case hashMatches(actual, expected):
+		return "match"
+	default:
+		return "≠ " + shortHash(expected)
+	}
+}
+
+// shortHash abbreviates a hash for table output
+func shortHash(hash string) string {
+	if hash == "" {
+		return "-"
+	}
+	if len(hash) > deepHashDisplayLength {
+		return hash[:deepHashDisplayLength]
+	}
+	return hash
+}
+
+// formatSize formats a byte count for table output
+func formatSize(size int64) string {
+	const unit = 1024
+	if size <= 0 {
+		return "-"
+	}
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+func TestDeepVerifyAsset(t *testing.T) {
+	assets := map[string]string{
+		"tool_linux_amd64":  "linux binary",
+		"tool_darwin_arm64": "darwin binary",
+		"tool_other":        "other binary",
+		"tool_big":          strings.Repeat("x", 2048),
+	}
+	var checksumDownloads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := filepath.Base(r.URL.Path)
+		if name == "checksums.txt" {
+			checksumDownloads.Add(1)
+		}
+		content, ok := assets[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	oldURL := gitHubDownloadBaseURL
+	gitHubDownloadBaseURL = server.URL
+	defer func() { gitHubDownloadBaseURL = oldURL }()
+
+	sha := func(s string) string {
+		sum := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+	assets["checksums.txt"] = sha("other binary") + "  tool_other\n"
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}")).
+		WithChecksums(spec.NewChecksums("checksums.txt").
+			WithEmbeddedChecksum("v1.0.0", "tool_linux_amd64", sha("linux binary")).
+			WithEmbeddedChecksum("v1.0.0", "tool_darwin_arm64", sha("tampered")))
+	installSpec.SetDefaults()
+
+	tests := []struct {
+		filename string
+		maxSize  int64
+		want     string
+	}{
+		{"tool_linux_amd64", 1024, deepStatusVerified},
+		{"tool_darwin_arm64", 1024, deepStatusMismatch},
+		{"tool_windows_amd64", 1024, deepStatusFailed},
+		{"tool_big", 1024, deepStatusTooLarge},
+		{"tool_big", 0, deepStatusNoChecksum},
+		{"tool_other", 1024, deepStatusVerified},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			dest := filepath.Join(t.TempDir(), "asset")
+			got := deepVerifyAsset(context.Background(), installSpec, "v1.0.0", newReleaseChecksums(installSpec, "v1.0.0"), "linux/amd64", tt.filename, dest, tt.maxSize)
+			if got.status != tt.want {
+				t.Errorf("deepVerifyAsset(%s).status = %q, want %q (err: %v)", tt.filename, got.status, tt.want, got.err)
+			}
+		})
+	}
+
+	// Mismatches fail the whole run
+	checksumDownloads.Store(0)
+	err := deepVerifyAssets(context.Background(), installSpec, "v1.0.0", map[string]string{
+		"linux/amd64":  "tool_linux_amd64",
+		"darwin/arm64": "tool_darwin_arm64",
+		"linux/arm64":  "tool_other",
+	}, 2, 1024)
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 assets") {
+		t.Errorf("deepVerifyAssets() error = %v, want 1 of 3 assets failed", err)
+	}
+	if n := checksumDownloads.Load(); n != 1 {
+		t.Errorf("checksum file downloaded %d times, want once per check", n)
+	}
+}
+
+func TestDeepVerifyAssetDownloadRetries(t *testing.T) {
+	t.Setenv("BINSTALLER_HTTP_RETRIES", "")
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	oldURL := gitHubDownloadBaseURL
+	gitHubDownloadBaseURL = server.URL
+	defer func() { gitHubDownloadBaseURL = oldURL }()
+
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}")).
+		WithDownload((&spec.Download{}).WithRetries(0))
+	installSpec.SetDefaults()
+
+	dest := filepath.Join(t.TempDir(), "asset")
+	got := deepVerifyAsset(context.Background(), installSpec, "v1.0.0", newReleaseChecksums(installSpec, "v1.0.0"), "linux/amd64", "tool_linux_amd64", dest, 1024)
+	if got.status != deepStatusFailed {
+		t.Errorf("deepVerifyAsset().status = %q, want %q", got.status, deepStatusFailed)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("asset requested %d times, want once with download.retries: 0", n)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := map[int64]string{0: "-", 512: "512B", 2048: "2.0KiB", 5 << 20: "5.0MiB"}
+	for size, want := range tests {
+		if got := formatSize(size); got != want {
+			t.Errorf("formatSize(%d) = %q, want %q", size, got, want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/asset"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// Embedded checksum verification statuses
+const (
+	embeddedStatusMatch      = "✓ MATCH"
+	embeddedStatusMismatch   = "✗ MISMATCH"
+	embeddedStatusNotInFile  = "⚠ NOT IN UPSTREAM"
+	embeddedStatusNoUpstream = "⚠ NO CHECKSUM FILE"
+)
+
+// embeddedResult is the comparison of one embedded checksum with the release checksum file
+type embeddedResult struct {
+	version  string
+	filename string
+	embedded string
+	upstream string
+	status   string
+}
+
+// verifyEmbeddedChecksums compares the embedded checksums of each version (or only
+// of version, when set) with the release checksum files and prints the result.
+// It fails when any embedded hash disagrees with upstream.
+func verifyEmbeddedChecksums(ctx context.Context, w io.Writer, installSpec *spec.InstallSpec, version string) error {
+	embedded := installSpec.GetChecksums().EmbeddedChecksums
+	versions := make([]string, 0, len(embedded))
+	for v := range embedded {
+		if version == "" || v == version {
+			versions = append(versions, v)
+		}
+	}
+	if len(versions) == 0 {
+		if version != "" {
+			log.Warnf("No embedded checksums for %s", version)
+		} else {
+			log.Warn("No embedded checksums to verify")
+		}
+		return nil
+	}
+	slices.SortFunc(versions, spec.CompareVersions)
+
+	var results []embeddedResult
+	for _, v := range versions {
+		if len(asset.NewFilenameGenerator(installSpec, v).PossibleChecksumTemplates()) == 0 {
+			log.Warn("No checksum file is configured (checksums.template); nothing to compare embedded checksums with")
+			return nil
+		}
+		results = append(results, compareEmbeddedChecksums(ctx, installSpec, v)...)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tFILENAME\tEMBEDDED\tUPSTREAM\tSTATUS")
+	fmt.Fprintln(tw, "-------\t--------\t--------\t--------\t------")
+	mismatches := 0
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.version, r.filename, shortHash(r.embedded), shortHash(r.upstream), r.status)
+		if r.status == embeddedStatusMismatch {
+			mismatches++
+		}
+	}
+	tw.Flush()
+
+	if mismatches > 0 {
+		return fmt.Errorf("%d of %d embedded checksums disagree with the release checksum file", mismatches, len(results))
+	}
+	return nil
+}
+
+// compareEmbeddedChecksums compares every embedded checksum of version with the release checksum file
+func compareEmbeddedChecksums(ctx context.Context, installSpec *spec.InstallSpec, version string) []embeddedResult {
+	// Map filenames back to platforms so per-platform checksum files are consulted
+	platforms := make(map[string]string)
+	if assetFilenames, err := generateAllAssetFilenames(installSpec, version); err == nil {
+		for platform, filename := range assetFilenames {
+			platforms[filename] = platform
+		}
+	}
+
+	verifier := releaseVerifier(installSpec, version)
+	var results []embeddedResult
+	for _, ec := range installSpec.Checksums.EmbeddedChecksums[version] {
+		r := embeddedResult{
+			version:  version,
+			filename: spec.StringValue(ec.Filename),
+			embedded: spec.StringValue(ec.Hash),
+		}
+		hash, err := releaseChecksumLookup(ctx, verifier, platforms[r.filename], r.filename)
+		switch {
+		case err != nil && strings.Contains(err.Error(), "failed to download checksum file"):
+			log.WithError(err).Debugf("No release checksum file for %s", r.filename)
+			r.status = embeddedStatusNoUpstream
+		case err != nil:
+			r.status = embeddedStatusNotInFile
+		case strings.EqualFold(hash, r.embedded):
+			r.upstream, r.status = hash, embeddedStatusMatch
+		default:
+			r.upstream, r.status = hash, embeddedStatusMismatch
+		}
+		results = append(results, r)
+	}
+	return results
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+func TestVerifyEmbeddedChecksums(t *testing.T) {
+	linux := strings.Repeat("a", 64)
+	darwin := strings.Repeat("b", 64)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/owner/tool/releases/download/v1.0.0/checksums.txt" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(linux + "  tool_linux_amd64\n" + darwin + "  tool_darwin_arm64\n"))
+	}))
+	defer server.Close()
+
+	oldURL := gitHubDownloadBaseURL
+	gitHubDownloadBaseURL = server.URL
+	defer func() { gitHubDownloadBaseURL = oldURL }()
+
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}")).
+		WithChecksums(spec.NewChecksums("checksums.txt").
+			WithEmbeddedChecksum("v1.0.0", "tool_linux_amd64", strings.ToUpper(linux)).
+			WithEmbeddedChecksum("v1.0.0", "tool_darwin_arm64", strings.Repeat("c", 64)).
+			WithEmbeddedChecksum("v1.0.0", "tool_windows_amd64.exe", strings.Repeat("d", 64)).
+			WithEmbeddedChecksum("v0.9.0", "tool_linux_amd64", strings.Repeat("e", 64)))
+	installSpec.SetDefaults()
+
+	got := compareEmbeddedChecksums(context.Background(), installSpec, "v1.0.0")
+	want := map[string]string{
+		"tool_linux_amd64":       embeddedStatusMatch,
+		"tool_darwin_arm64":      embeddedStatusMismatch,
+		"tool_windows_amd64.exe": embeddedStatusNotInFile,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("compareEmbeddedChecksums() returned %d results, want %d", len(got), len(want))
+	}
+	for _, r := range got {
+		if r.status != want[r.filename] {
+			t.Errorf("%s: status = %q, want %q", r.filename, r.status, want[r.filename])
+		}
+	}
+
+	var out bytes.Buffer
+	err := verifyEmbeddedChecksums(context.Background(), &out, installSpec, "")
+	if err == nil || !strings.Contains(err.Error(), "1 of 4 embedded checksums") {
+		t.Errorf("verifyEmbeddedChecksums() error = %v, want 1 of 4 mismatching", err)
+	}
+	if !strings.Contains(out.String(), "v0.9.0") || !strings.Contains(out.String(), embeddedStatusNoUpstream) {
+		t.Errorf("verifyEmbeddedChecksums() output lacks the missing v0.9.0 checksum file:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := verifyEmbeddedChecksums(context.Background(), &out, installSpec, "v0.9.0"); err != nil {
+		t.Errorf("verifyEmbeddedChecksums(v0.9.0) error = %v, want nil without a mismatch", err)
+	}
+}
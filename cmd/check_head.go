@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/httpclient"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// runConcurrently calls fn for 0..n-1 with at most concurrency calls running at once
+func runConcurrently(n, concurrency int, fn func(i int)) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// headReleaseAssets checks which of filenames exist in the release with one
+// HEAD request per asset, for when the release assets cannot be listed
+func headReleaseAssets(ctx context.Context, installSpec *spec.InstallSpec, version string, filenames []string, concurrency int) map[string]bool {
+	client := httpclient.NewGitHubClient()
+	client.Timeout = 30 * time.Second
+	found := make([]bool, len(filenames))
+	runConcurrently(len(filenames), concurrency, func(i int) {
+		exists, err := headReleaseAsset(ctx, client, releaseDownloadURL(installSpec, version, filenames[i]))
+		if err != nil {
+			log.WithError(err).Debugf("HEAD %s", filenames[i])
+		}
+		found[i] = exists
+	})
+
+	existing := make(map[string]bool)
+	for i, filename := range filenames {
+		if found[i] {
+			existing[filename] = true
+		}
+	}
+	return existing
+}
+
+// headReleaseAsset reports whether a HEAD request for url succeeds
+func headReleaseAsset(ctx context.Context, client *http.Client, url string) (bool, error) {
+	req, err := httpclient.NewRequestWithGitHubAuth(http.MethodHead, url)
+	if err != nil {
+		return false, err
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return true, nil
+	case resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+}
+
+// printStatusSummary prints how many rows of an asset table have each status,
+// in the order the statuses first appear. Ignored rows ("-") are not counted.
+func printStatusSummary(w io.Writer, statuses []string) {
+	counts := make(map[string]int)
+	var order []string
+	for _, status := range statuses {
+		if status == "-" {
+			continue
+		}
+		if counts[status] == 0 {
+			order = append(order, status)
+		}
+		counts[status]++
+	}
+	if len(order) == 0 {
+		return
+	}
+	parts := make([]string, len(order))
+	for i, status := range order {
+		// Drop the leading ✓/✗/⚠ mark
+		_, label, _ := strings.Cut(status, " ")
+		parts[i] = fmt.Sprintf("%d %s", counts[status], label)
+	}
+	fmt.Fprintf(w, "\nSummary: %s\n", strings.Join(parts, ", "))
+}
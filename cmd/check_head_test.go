@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+func TestCheckAssetsExistHeadFallback(t *testing.T) {
+	var heads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/repos/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.Method != http.MethodHead {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		heads.Add(1)
+		if strings.HasSuffix(r.URL.Path, "_windows_amd64") {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	oldAPI, oldDownload, oldConcurrency := gitHubAPIBaseURL, gitHubDownloadBaseURL, checkConcurrency
+	gitHubAPIBaseURL, gitHubDownloadBaseURL, checkConcurrency = server.URL, server.URL, 2
+	defer func() {
+		gitHubAPIBaseURL, gitHubDownloadBaseURL, checkConcurrency = oldAPI, oldDownload, oldConcurrency
+	}()
+
+	installSpec := spec.NewInstallSpec("owner/tool").WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}"))
+	installSpec.SetDefaults()
+	assetFilenames := map[string]string{
+		"linux/amd64":   "tool_linux_amd64",
+		"darwin/arm64":  "tool_darwin_arm64",
+		"windows/amd64": "tool_windows_amd64",
+	}
+	err := checkAssetsExist(context.Background(), installSpec, "v1.0.0", assetFilenames)
+	if err == nil {
+		t.Error("checkAssetsExist() succeeded with a missing asset, want error")
+	}
+	if heads.Load() != 3 {
+		t.Errorf("checkAssetsExist() sent %d HEAD requests, want 3", heads.Load())
+	}
+
+	delete(assetFilenames, "windows/amd64")
+	if err := checkAssetsExist(context.Background(), installSpec, "v1.0.0", assetFilenames); err != nil {
+		t.Errorf("checkAssetsExist() error = %v", err)
+	}
+}
+
+func TestPrintStatusSummary(t *testing.T) {
+	var buf bytes.Buffer
+	printStatusSummary(&buf, []string{"✓ EXISTS", "✓ EXISTS", "✗ MISSING", "-", "✓ EXISTS", "✗ NO MATCH"})
+	if got, want := buf.String(), "\nSummary: 3 EXISTS, 1 MISSING, 1 NO MATCH\n"; got != want {
+		t.Errorf("printStatusSummary() = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	printStatusSummary(&buf, []string{"-"})
+	if buf.Len() != 0 {
+		t.Errorf("printStatusSummary() of ignored rows = %q, want nothing", buf.String())
+	}
+}
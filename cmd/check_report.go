@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/binary-install/binstaller/pkg/checksums"
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/goccy/go-yaml"
+)
+
+// Output formats of the check command
+const (
+	checkOutputTable = "table"
+	checkOutputJSON  = "json"
+	checkOutputYAML  = "yaml"
+)
+
+// checkReport is the machine-readable result of the check command, written
+// instead of the table with --output json or yaml
+type checkReport struct {
+	Repo    string `json:"repo"`
+	Version string `json:"version"`
+	// OK is false when the command fails: an asset is missing or a release
+	// asset matches no platform
+	OK bool `json:"ok"`
+	// AssetsChecked is false with --check-assets=false, when only the
+	// filenames are generated
+	AssetsChecked bool               `json:"assets_checked"`
+	Assets        []checkAssetResult `json:"assets"`
+	Checksums     *checkAssetResult  `json:"checksums,omitempty"`
+	// Unmatched are the release assets matching no platform
+	Unmatched []string `json:"unmatched_assets"`
+	// Ignored are the release assets binstaller does not install, such as
+	// signatures and packages
+	Ignored []string `json:"ignored_assets"`
+	// Summary counts the assets by status, like the summary of the table
+	Summary map[string]int `json:"summary"`
+}
+
+// checkAssetResult is the status of the asset of a platform or of the
+// checksums file
+type checkAssetResult struct {
+	Platform string `json:"platform,omitempty"`
+	Filename string `json:"filename"`
+	// Status is the status of the table in lower case, e.g. exists, fallback,
+	// missing, matched or not_supported. It is empty when assets are not checked.
+	Status string `json:"status,omitempty"`
+	// Checksum is how the asset of a platform is verified on install:
+	// embedded, checksum_file or none
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// Checksum statuses of the asset of a platform
+const (
+	// checksumEmbedded is a checksum embedded in the spec for the version
+	checksumEmbedded = "embedded"
+	// checksumFile is the platform's checksum file, which the release has
+	checksumFile = "checksum_file"
+	// checksumNone leaves the asset unverified
+	checksumNone = "none"
+)
+
+// newCheckReport returns an empty report of the check of version
+func newCheckReport(installSpec *spec.InstallSpec, version string, assetsChecked bool) *checkReport {
+	return &checkReport{
+		Repo:          installSpec.GetRepo(),
+		Version:       version,
+		OK:            true,
+		AssetsChecked: assetsChecked,
+		Assets:        []checkAssetResult{},
+		Unmatched:     []string{},
+		Ignored:       []string{},
+		Summary:       map[string]int{},
+	}
+}
+
+// add records a row of the check table, with its status as displayed
+func (r *checkReport) add(platform, filename, status string) {
+	code := checkStatusCode(status)
+	switch {
+	case status == "-":
+		r.Ignored = append(r.Ignored, filename)
+		return
+	case platform == "checksums":
+		r.Checksums = &checkAssetResult{Filename: filename, Status: code}
+	case platform == "-":
+		r.Unmatched = append(r.Unmatched, filename)
+	default:
+		r.Assets = append(r.Assets, checkAssetResult{Platform: platform, Filename: filename, Status: code})
+	}
+	if code != "" {
+		r.Summary[code]++
+	}
+}
+
+// addChecksumStatuses records how the asset of every platform is verified.
+// released is the set of release assets, or nil when assets are not checked,
+// in which case a configured checksum file is assumed to be released.
+func (r *checkReport) addChecksumStatuses(installSpec *spec.InstallSpec, released map[string]bool) {
+	for i, a := range r.Assets {
+		r.Assets[i].Checksum = checksumStatus(installSpec, r.Version, a.Platform, a.Filename, released)
+	}
+}
+
+// checksumStatus returns how filename, the asset of platform at version, is
+// verified: by an embedded checksum, by its checksum file or not at all
+func checksumStatus(installSpec *spec.InstallSpec, version, platform, filename string, released map[string]bool) string {
+	embedded := installSpec.GetChecksums()
+	if _, ok := embedded.GetEmbeddedChecksum(version, filename); ok {
+		return checksumEmbedded
+	}
+	if _, ok := embedded.GetEmbeddedChecksum(strings.TrimPrefix(version, "v"), filename); ok {
+		return checksumEmbedded
+	}
+	verifier := checksums.NewVerifier(installSpec, version)
+	verifier.OS, verifier.Arch, _ = strings.Cut(platform, "/")
+	if name := verifier.ChecksumFilename(filename); name != "" && (released == nil || released[name]) {
+		return checksumFile
+	}
+	return checksumNone
+}
+
+// checkStatusCode turns a displayed status such as "✗ NO MATCH" into no_match
+func checkStatusCode(status string) string {
+	_, label, _ := strings.Cut(status, " ")
+	return strings.ReplaceAll(strings.ToLower(label), " ", "_")
+}
+
+// validateCheckOutput checks the --output format and that the checks it
+// cannot represent are not requested
+func validateCheckOutput(format string, deep, verifyEmbedded bool) error {
+	switch format {
+	case checkOutputTable:
+		return nil
+	case checkOutputJSON, checkOutputYAML:
+	default:
+		return fmt.Errorf("invalid output format %q: must be 'table', 'json' or 'yaml'", format)
+	}
+	if deep || verifyEmbedded {
+		return fmt.Errorf("%s output cannot be combined with --deep or --verify-embedded", strings.ToUpper(format))
+	}
+	return nil
+}
+
+// writeCheckReport writes the report to w as JSON or YAML
+func writeCheckReport(w io.Writer, format string, report *checkReport) error {
+	if format == checkOutputYAML {
+		data, err := yaml.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("failed to encode the check report: %w", err)
+		}
+		_, err = w.Write(data)
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("failed to encode the check report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/goccy/go-yaml"
+)
+
+func TestCheckAssetsExistReport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"assets": [
+			{"name": "tool_linux_amd64"},
+			{"name": "tool_freebsd_amd64"},
+			{"name": "tool_linux_amd64.sig"},
+			{"name": "checksums.txt"}
+		]}`))
+	}))
+	defer server.Close()
+	oldAPI, oldOutput := gitHubAPIBaseURL, checkOutput
+	gitHubAPIBaseURL, checkOutput = server.URL, checkOutputJSON
+	defer func() { gitHubAPIBaseURL, checkOutput = oldAPI, oldOutput }()
+
+	// Capture the report written to stdout
+	out, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = out
+	defer func() { os.Stdout = stdout }()
+
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}")).
+		WithChecksums(spec.NewChecksums("checksums.txt").WithEmbeddedChecksum("v1.0.0", "tool_linux_amd64", "aaaa"))
+	installSpec.SetDefaults()
+	assetFilenames := map[string]string{
+		"linux/amd64":  "tool_linux_amd64",
+		"darwin/arm64": "tool_darwin_arm64",
+	}
+	err = checkAssetsExist(context.Background(), installSpec, "v1.0.0", assetFilenames)
+	os.Stdout = stdout
+	if err == nil {
+		t.Error("checkAssetsExist() succeeded with a missing asset, want error")
+	}
+
+	data, err := os.ReadFile(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report checkReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("output is not a JSON report: %v\n%s", err, data)
+	}
+	want := checkReport{
+		Repo:          "owner/tool",
+		Version:       "v1.0.0",
+		OK:            false,
+		AssetsChecked: true,
+		Assets: []checkAssetResult{
+			{Platform: "linux/amd64", Filename: "tool_linux_amd64", Status: "exists", Checksum: "embedded"},
+			{Platform: "darwin/arm64", Filename: "tool_darwin_arm64", Status: "missing", Checksum: "checksum_file"},
+		},
+		Checksums: &checkAssetResult{Filename: "checksums.txt", Status: "exists"},
+		Unmatched: []string{"tool_freebsd_amd64"},
+		Ignored:   []string{"tool_linux_amd64.sig"},
+		Summary:   map[string]int{"exists": 2, "missing": 1, "no_match": 1},
+	}
+	got, _ := json.Marshal(report)
+	wantJSON, _ := json.Marshal(want)
+	if !bytes.Equal(got, wantJSON) {
+		t.Errorf("report = %s\nwant %s", got, wantJSON)
+	}
+}
+
+func TestWriteCheckReport(t *testing.T) {
+	report := newCheckReport(spec.NewInstallSpec("owner/tool"), "v1.0.0", false)
+	report.add("linux/amd64", "tool_linux_amd64", "")
+	report.addChecksumStatuses(spec.NewInstallSpec("owner/tool"), nil)
+
+	var buf bytes.Buffer
+	if err := writeCheckReport(&buf, checkOutputYAML, report); err != nil {
+		t.Fatalf("writeCheckReport() error = %v", err)
+	}
+	var decoded map[string]any
+	if err := yaml.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("output is not YAML: %v\n%s", err, buf.String())
+	}
+	for _, want := range []string{"assets_checked: false", "filename: tool_linux_amd64", "checksum: none", "unmatched_assets: []"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("YAML report lacks %q:\n%s", want, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "status:") {
+		t.Errorf("unchecked assets should have no status:\n%s", buf.String())
+	}
+}
+
+func TestChecksumStatus(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}")).
+		WithChecksums(spec.NewChecksums("${ASSET_FILENAME}.sha256").WithEmbeddedChecksum("1.0.0", "tool_linux_amd64", "aaaa"))
+	installSpec.SetDefaults()
+	released := map[string]bool{"tool_darwin_arm64.sha256": true}
+	for _, tt := range []struct {
+		platform, filename string
+		released           map[string]bool
+		want               string
+	}{
+		{"linux/amd64", "tool_linux_amd64", released, checksumEmbedded},
+		{"darwin/arm64", "tool_darwin_arm64", released, checksumFile},
+		{"windows/amd64", "tool_windows_amd64", released, checksumNone},
+		{"windows/amd64", "tool_windows_amd64", nil, checksumFile},
+	} {
+		if got := checksumStatus(installSpec, "v1.0.0", tt.platform, tt.filename, tt.released); got != tt.want {
+			t.Errorf("checksumStatus(%s, %v) = %q, want %q", tt.filename, tt.released != nil, got, tt.want)
+		}
+	}
+}
+
+func TestValidateCheckOutput(t *testing.T) {
+	for _, format := range []string{"table", "json", "yaml"} {
+		if err := validateCheckOutput(format, false, false); err != nil {
+			t.Errorf("validateCheckOutput(%q) error = %v", format, err)
+		}
+	}
+	if err := validateCheckOutput("xml", false, false); err == nil {
+		t.Error("validateCheckOutput() accepted an unknown format")
+	}
+	if err := validateCheckOutput("json", true, false); err == nil {
+		t.Error("validateCheckOutput() accepted --deep with JSON output")
+	}
+	if err := validateCheckOutput("table", true, true); err != nil {
+		t.Errorf("validateCheckOutput() rejected --deep with the table: %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/internal/conformance"
+	"github.com/binary-install/binstaller/pkg/resolver"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Flags for conformance command
+	conformanceDir      string
+	conformanceRecord   bool
+	conformanceReleases int
+)
+
+// ConformanceCommand represents the conformance command
+var ConformanceCommand = &cobra.Command{
+	Use:   "conformance [NAME...]",
+	Short: "Check filename generation against a corpus of real-world specs",
+	Long: `Runs this binst against a corpus of real-world specs and the asset lists of
+their releases, recorded once and vendored as fixtures. For every recorded
+release and supported platform, the asset filename binst generates (or one of
+its fallbacks) must be an asset of the release, and so must its checksum file
+and extra files. A failure means a change to binst broke the rule semantics
+some spec relies on.
+
+The corpus vendored with binst is used by default. --dir checks a corpus
+directory of NAME.binstaller.yml specs and NAME.releases.yml asset lists
+instead, and --record (re)records the asset lists of its specs from their
+latest releases, which needs network access.`,
+	Example: `  # Check the corpus vendored with binst
+  binst conformance
+
+  # Check one spec of a corpus directory
+  binst conformance --dir internal/conformance/corpus gum
+
+  # Record the assets of the 3 latest releases of every spec of a corpus
+  binst conformance --dir internal/conformance/corpus --record`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if conformanceRecord {
+			if conformanceDir == "" {
+				return fmt.Errorf("--record requires --dir")
+			}
+			if err := recordCorpus(cmd.Context(), conformanceDir, args, conformanceReleases); err != nil {
+				return err
+			}
+		}
+		fsys := conformance.Corpus()
+		if conformanceDir != "" {
+			fsys = os.DirFS(conformanceDir)
+		}
+		fixtures, err := conformance.LoadCorpus(fsys, args...)
+		if err != nil {
+			return err
+		}
+		return RunConformance(fixtures, os.Stdout)
+	},
+}
+
+// RunConformance verifies every fixture and writes a line per fixture and one
+// per failure
+func RunConformance(fixtures []conformance.Fixture, w io.Writer) error {
+	if len(fixtures) == 0 {
+		return fmt.Errorf("the corpus has no specs")
+	}
+	var b strings.Builder
+	failed := 0
+	for _, f := range fixtures {
+		report := conformance.Verify(f)
+		status := "ok  "
+		if len(report.Failures) > 0 {
+			status = "FAIL"
+			failed++
+		}
+		fmt.Fprintf(&b, "%s %s (%d releases, %d assets checked)\n", status, f.Name, len(f.Releases), report.Checked)
+		for _, failure := range report.Failures {
+			fmt.Fprintf(&b, "     %s\n", failure)
+		}
+	}
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d corpus specs failed", failed, len(fixtures))
+	}
+	return nil
+}
+
+// recordCorpus records the assets of the count latest stable releases of the
+// specs of the corpus directory dir, or of the specs in names
+func recordCorpus(ctx context.Context, dir string, names []string, count int) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.binstaller.yml"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".binstaller.yml")
+		if len(names) > 0 && !slices.Contains(names, name) {
+			continue
+		}
+		installSpec, err := loadInstallSpec(file)
+		if err != nil {
+			return err
+		}
+		installSpec.SetDefaults()
+		ctx := specContext(ctx, installSpec)
+
+		r := resolver.New(installSpec)
+		releases, err := r.Releases(ctx)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		var recorded []conformance.RecordedRelease
+		for _, release := range releases {
+			if len(recorded) >= count {
+				break
+			}
+			if release.Prerelease {
+				continue
+			}
+			assets, err := fetchReleaseAssets(ctx, installSpec, release.Tag)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			slices.Sort(assets)
+			rr := conformance.RecordedRelease{Tag: release.Tag, Assets: assets}
+			if installSpec.UsesReleaseVariables() {
+				metadata, err := r.ReleaseMetadata(ctx, release.Tag)
+				if err != nil {
+					return fmt.Errorf("%s: %w", name, err)
+				}
+				rr.Name, rr.PublishedAt = metadata.Name, metadata.PublishedAt
+			}
+			recorded = append(recorded, rr)
+		}
+		if len(recorded) == 0 {
+			return fmt.Errorf("%s: no stable releases to record", name)
+		}
+
+		data, err := conformance.MarshalReleases(recorded)
+		if err != nil {
+			return err
+		}
+		out := filepath.Join(dir, conformance.ReleasesFilename(name))
+		if err := os.WriteFile(out, data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", out, err)
+		}
+		log.Infof("Recorded %d releases of %s to %s", len(recorded), name, out)
+	}
+	return nil
+}
+
+func init() {
+	ConformanceCommand.Flags().StringVar(&conformanceDir, "dir", "", "Corpus directory of NAME.binstaller.yml specs and NAME.releases.yml asset lists (default: the corpus vendored with binst)")
+	ConformanceCommand.Flags().BoolVar(&conformanceRecord, "record", false, "Record the assets of the latest releases of the corpus specs before checking them")
+	ConformanceCommand.Flags().IntVar(&conformanceReleases, "releases", 3, "Number of latest stable releases to record per spec")
+}
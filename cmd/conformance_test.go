@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/binary-install/binstaller/internal/conformance"
+)
+
+func TestRunConformance(t *testing.T) {
+	const spec = `schema: v1
+name: tool
+repo: owner/tool
+asset:
+  template: ${NAME}_${VERSION}_${OS}_${ARCH}.tar.gz
+supported_platforms:
+- os: linux
+  arch: amd64
+- os: darwin
+  arch: arm64
+`
+	fixtures := []conformance.Fixture{
+		{Name: "good", Spec: []byte(spec), Releases: []conformance.RecordedRelease{
+			{Tag: "v1.0.0", Assets: []string{"tool_1.0.0_linux_amd64.tar.gz", "tool_1.0.0_darwin_arm64.tar.gz"}},
+		}},
+		{Name: "bad", Spec: []byte(spec), Releases: []conformance.RecordedRelease{
+			{Tag: "v1.0.0", Assets: []string{"tool_1.0.0_linux_amd64.tar.gz"}},
+		}},
+	}
+
+	var out bytes.Buffer
+	err := RunConformance(fixtures, &out)
+	if err == nil || err.Error() != "1 of 2 corpus specs failed" {
+		t.Errorf("RunConformance() error = %v, want 1 of 2 corpus specs failed", err)
+	}
+	want := `ok   good (1 releases, 2 assets checked)
+FAIL bad (1 releases, 1 assets checked)
+     bad@v1.0.0 darwin/arm64: no asset named tool_1.0.0_darwin_arm64.tar.gz
+`
+	if out.String() != want {
+		t.Errorf("RunConformance() output:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	if err := RunConformance(fixtures[:1], &out); err != nil {
+		t.Errorf("RunConformance() error = %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/goccy/go-yaml"
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Flags for convert command
+	convertTo     string
+	convertOutput string
+)
+
+// ConvertCommand represents the convert command
+var ConvertCommand = &cobra.Command{
+	Use:   "convert",
+	Short: "Convert an InstallSpec between YAML and JSON",
+	Long: `Converts an InstallSpec config file to JSON or YAML.
+
+The input may be YAML or JSON. Keys are written in the canonical order of the
+schema (the order of 'binst schema --example full'), and map keys such as the
+versions of embedded_checksums are sorted, so converting the same spec always
+produces the same output.
+
+Conversion is lossless: fields unknown to the schema are rejected instead of
+dropped, and the output is parsed back and compared with the input before it
+is written. Comments are not preserved, and specs encrypted with SOPS are
+converted to plaintext.`,
+	Example: `  # Convert the default config to JSON
+  binst convert --to json
+
+  # Convert a JSON spec back to YAML
+  binst convert -c mytool.binstaller.json --to yaml -o .config/binstaller.yml
+
+  # Canonicalize the key order of a YAML spec in place
+  binst convert -c .config/binstaller.yml --to yaml -o .config/binstaller.yml`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfgFile, err := resolveConfigFile(configFile)
+		if err != nil {
+			return err
+		}
+		data, err := readInstallSpecData(cfgFile)
+		if err != nil {
+			return err
+		}
+		out, err := convertSpec(data, convertTo)
+		if err != nil {
+			return fmt.Errorf("failed to convert %s: %w", cfgFile, err)
+		}
+
+		var w io.Writer = os.Stdout
+		if convertOutput != "" && convertOutput != "-" {
+			f, err := os.Create(convertOutput)
+			if err != nil {
+				return fmt.Errorf("failed to create output file: %w", err)
+			}
+			defer f.Close()
+			w = f
+		}
+		_, err = w.Write(out)
+		return err
+	},
+}
+
+// convertSpec converts InstallSpec YAML or JSON to the given format ("json" or "yaml")
+// in canonical key order, failing when the conversion would lose information
+func convertSpec(data []byte, to string) ([]byte, error) {
+	installSpec, err := decodeSpecStrict(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []byte
+	switch to {
+	case "json":
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(installSpec); err != nil {
+			return nil, fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		out = buf.Bytes()
+	case "yaml":
+		body, err := yaml.MarshalWithOptions(installSpec, yaml.IndentSequence(true))
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal YAML: %w", err)
+		}
+		out = append([]byte(schemaComment), body...)
+	default:
+		return nil, fmt.Errorf("unsupported format: %s (supported: json, yaml)", to)
+	}
+
+	// Parse the output back to guarantee a lossless round-trip
+	roundTrip, err := decodeSpecStrict(out)
+	if err != nil {
+		return nil, fmt.Errorf("converted spec does not parse: %w", err)
+	}
+	if diff := cmp.Diff(installSpec, roundTrip); diff != "" {
+		return nil, fmt.Errorf("conversion is not lossless (-input +output):\n%s", diff)
+	}
+	return out, nil
+}
+
+// decodeSpecStrict parses InstallSpec YAML or JSON, rejecting fields unknown to the schema
+func decodeSpecStrict(data []byte) (*spec.InstallSpec, error) {
+	var installSpec spec.InstallSpec
+	if err := yaml.UnmarshalWithOptions(data, &installSpec, yaml.Strict()); err != nil {
+		return nil, fmt.Errorf("failed to parse spec: %w", err)
+	}
+	return &installSpec, nil
+}
+
+func init() {
+	ConvertCommand.Flags().StringVar(&convertTo, "to", "yaml", "Output format (json, yaml)")
+	ConvertCommand.Flags().StringVarP(&convertOutput, "output", "o", "", "Write the converted spec to a file (default: stdout)")
+}
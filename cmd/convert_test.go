@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConvertSpec(t *testing.T) {
+	input := `asset:
+  binaries: [{path: bin/tool, name: tool}]
+  template: "${NAME}_${OS}_${ARCH}.tar.gz"
+repo: owner/tool
+checksums:
+  embedded_checksums:
+    v2.0.0: [{filename: b, hash: "22"}]
+    v1.0.0: [{filename: a, hash: "11"}]
+name: tool
+`
+	json, err := convertSpec([]byte(input), "json")
+	if err != nil {
+		t.Fatalf("convertSpec(json) error = %v", err)
+	}
+	// Keys follow the schema order and map keys are sorted
+	for _, order := range [][]string{
+		{`"name"`, `"repo"`, `"asset"`, `"checksums"`},
+		{`"template"`, `"binaries"`},
+		{`"name": "tool"`, `"path": "bin/tool"`},
+		{`"v1.0.0"`, `"v2.0.0"`},
+	} {
+		last := -1
+		for _, key := range order {
+			i := strings.Index(string(json), key)
+			if i <= last {
+				t.Errorf("convertSpec(json) has %s out of order in %v:\n%s", key, order, json)
+			}
+			last = i
+		}
+	}
+	if !strings.Contains(string(json), `"${NAME}_${OS}_${ARCH}.tar.gz"`) {
+		t.Errorf("convertSpec(json) escaped the template:\n%s", json)
+	}
+
+	yamlOut, err := convertSpec(json, "yaml")
+	if err != nil {
+		t.Fatalf("convertSpec(yaml) error = %v", err)
+	}
+	again, err := convertSpec(yamlOut, "yaml")
+	if err != nil || string(again) != string(yamlOut) {
+		t.Errorf("converting canonical YAML again = %q, %v; want it unchanged", again, err)
+	}
+	back, err := convertSpec(yamlOut, "json")
+	if err != nil || string(back) != string(json) {
+		t.Errorf("JSON -> YAML -> JSON = %q, %v; want the original JSON", back, err)
+	}
+
+	if _, err := convertSpec([]byte("repo: a/b\nasset:\n  template: x\n  bogus: 1\n"), "json"); err == nil || !strings.Contains(err.Error(), "bogus") {
+		t.Errorf("convertSpec() with an unknown field error = %v, want it rejected", err)
+	}
+	if _, err := convertSpec([]byte(input), "toml"); err == nil {
+		t.Error("convertSpec(toml) succeeded, want unsupported format")
+	}
+}
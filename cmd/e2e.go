@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/internal/shell"
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Flags for e2e command
+	e2ePlatforms   []string
+	e2eVersions    []string
+	e2eFormat      string
+	e2eConcurrency int
+	e2eExecArgs    string
+)
+
+// E2E result statuses
+const (
+	e2ePass = "PASS"
+	e2eFail = "FAIL"
+	e2eSkip = "SKIP"
+)
+
+// E2ECommand represents the e2e command
+var E2ECommand = &cobra.Command{
+	Use:   "e2e",
+	Short: "Run the generated installer across a matrix of platforms and versions",
+	Long: `Generate the installer for an InstallSpec and run it for every combination of
+--platforms and --versions, reporting the results as a table or JSON.
+
+Each case runs the installer with BINSTALLER_OS and BINSTALLER_ARCH set, so assets for
+other platforms are downloaded, verified, and extracted on this host as well. A case
+passes when the installer succeeds and every configured binary is installed.
+
+With --exec-args, binaries of the host's own platform are also executed with those
+arguments; binaries of other platforms are only installed. Platforms the spec does
+not support are skipped.
+
+Platforms default to the spec's supported_platforms (or the host platform), and
+versions default to latest.`,
+	Example: `  # Test every supported platform at the latest release
+  binst e2e --config .config/mytool.binstaller.yml
+
+  # Test a matrix and run the native binary
+  binst e2e --platforms linux/amd64,darwin/arm64 --versions latest,v1.2.3 --exec-args=--version
+
+  # Machine-readable results for CI
+  binst e2e --format json > e2e.json`,
+	Args: cobra.NoArgs,
+	RunE: runE2E,
+}
+
+func init() {
+	E2ECommand.Flags().StringSliceVar(&e2ePlatforms, "platforms", nil, "Platforms to test as os/arch (default: the spec's supported platforms)")
+	E2ECommand.Flags().StringSliceVar(&e2eVersions, "versions", []string{"latest"}, "Versions to test")
+	E2ECommand.Flags().StringVarP(&e2eFormat, "format", "f", "table", "Output format (table, json)")
+	E2ECommand.Flags().IntVar(&e2eConcurrency, "concurrency", 4, "Number of cases to run in parallel")
+	E2ECommand.Flags().StringVar(&e2eExecArgs, "exec-args", "", "Run native binaries with these arguments after installing")
+}
+
+// e2eCase is one platform and version of the matrix
+type e2eCase struct {
+	Version string `json:"version"`
+	OS      string `json:"os"`
+	Arch    string `json:"arch"`
+}
+
+// e2eResult is the outcome of one case
+type e2eResult struct {
+	e2eCase
+	Status   string   `json:"status"`
+	Executed bool     `json:"executed"`
+	Duration float64  `json:"duration_seconds"`
+	Detail   string   `json:"detail,omitempty"`
+	Binaries []string `json:"binaries,omitempty"`
+}
+
+func runE2E(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if e2eFormat != "table" && e2eFormat != "json" {
+		return fmt.Errorf("invalid format %q: must be 'table' or 'json'", e2eFormat)
+	}
+	if e2eConcurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		return fmt.Errorf("e2e needs a POSIX shell to run installers: %w", err)
+	}
+
+	cfgFile, err := resolveConfigFile(configFile)
+	if err != nil {
+		return err
+	}
+	installSpec, err := loadInstallSpec(cfgFile)
+	if err != nil {
+		return err
+	}
+	installSpec.SetDefaults()
+	ctx = specContext(ctx, installSpec)
+
+	script, err := shell.GenerateWithScriptType(installSpec, "", "installer")
+	if err != nil {
+		return fmt.Errorf("failed to generate installer: %w", err)
+	}
+	workDir, err := os.MkdirTemp("", "binst-e2e-")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+	scriptPath := filepath.Join(workDir, "install.sh")
+	if err := os.WriteFile(scriptPath, script, 0755); err != nil {
+		return fmt.Errorf("failed to write installer: %w", err)
+	}
+
+	cases, err := e2eMatrix(installSpec, e2ePlatforms, e2eVersions)
+	if err != nil {
+		return err
+	}
+	log.Infof("Running %d e2e case(s)", len(cases))
+	results := runE2ECases(ctx, installSpec, scriptPath, workDir, cases, e2eConcurrency, strings.Fields(e2eExecArgs))
+
+	if e2eFormat == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return fmt.Errorf("failed to encode results: %w", err)
+		}
+	} else {
+		printE2EResults(os.Stdout, results)
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Status == e2eFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d e2e case(s) failed", failed, len(results))
+	}
+	return nil
+}
+
+// e2eMatrix returns the cases for every version and platform. Platforms default to
+// the spec's supported platforms, or the host platform when none are declared.
+func e2eMatrix(installSpec *spec.InstallSpec, platforms, versions []string) ([]e2eCase, error) {
+	if len(platforms) == 0 {
+		for _, p := range installSpec.SupportedPlatforms {
+			platforms = append(platforms, spec.PlatformOSString(p.OS)+"/"+spec.PlatformArchString(p.Arch))
+		}
+	}
+	if len(platforms) == 0 {
+		platforms = []string{runtime.GOOS + "/" + runtime.GOARCH}
+	}
+	if len(versions) == 0 {
+		versions = []string{"latest"}
+	}
+
+	var cases []e2eCase
+	for _, version := range versions {
+		for _, platform := range platforms {
+			osName, arch, ok := strings.Cut(platform, "/")
+			if !ok || osName == "" || arch == "" {
+				return nil, fmt.Errorf("invalid platform %q: must be os/arch", platform)
+			}
+			cases = append(cases, e2eCase{Version: version, OS: osName, Arch: arch})
+		}
+	}
+	return cases, nil
+}
+
+// runE2ECases runs the cases with up to concurrency installers at a time and
+// returns the results in case order
+func runE2ECases(ctx context.Context, installSpec *spec.InstallSpec, scriptPath, workDir string, cases []e2eCase, concurrency int, execArgs []string) []e2eResult {
+	results := make([]e2eResult, len(cases))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, c := range cases {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			binDir := filepath.Join(workDir, fmt.Sprintf("case-%d", i))
+			results[i] = runE2ECase(ctx, installSpec, scriptPath, binDir, c, execArgs)
+			log.Debugf("%s %s/%s: %s", c.Version, c.OS, c.Arch, results[i].Status)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// runE2ECase runs the installer for one case into binDir and checks the installed binaries
+func runE2ECase(ctx context.Context, installSpec *spec.InstallSpec, scriptPath, binDir string, c e2eCase, execArgs []string) (result e2eResult) {
+	result = e2eResult{e2eCase: c}
+	start := time.Now()
+	defer func() { result.Duration = time.Since(start).Round(10 * time.Millisecond).Seconds() }()
+
+	if !e2ePlatformSupported(installSpec, c.OS, c.Arch) {
+		result.Status, result.Detail = e2eSkip, "platform not supported by spec"
+		return result
+	}
+
+	var output bytes.Buffer
+	installer := exec.CommandContext(ctx, "sh", scriptPath, "-b", binDir, c.Version)
+	installer.Env = append(os.Environ(), "BINSTALLER_OS="+c.OS, "BINSTALLER_ARCH="+c.Arch)
+	installer.Stdout, installer.Stderr = &output, &output
+	if err := installer.Run(); err != nil {
+		result.Status, result.Detail = e2eFail, e2eFailureDetail(err, output.Bytes())
+		return result
+	}
+
+	native := c.OS == runtime.GOOS && c.Arch == runtime.GOARCH
+	for _, binary := range getBinariesForPlatform(installSpec, c.OS, c.Arch) {
+		name := spec.StringValue(binary.Name)
+		if name == "" {
+			name = installSpec.GetName()
+		}
+		binaryPath := filepath.Join(binDir, name)
+		if _, err := os.Stat(binaryPath); err != nil && c.OS == "windows" {
+			binaryPath += ".exe"
+		}
+		if _, err := os.Stat(binaryPath); err != nil {
+			result.Status, result.Detail = e2eFail, fmt.Sprintf("binary %s not installed", name)
+			return result
+		}
+		result.Binaries = append(result.Binaries, filepath.Base(binaryPath))
+
+		if !native || len(execArgs) == 0 {
+			continue
+		}
+		out, err := exec.CommandContext(ctx, binaryPath, execArgs...).CombinedOutput()
+		if err != nil {
+			result.Status, result.Detail = e2eFail, fmt.Sprintf("%s %s: %s", name, strings.Join(execArgs, " "), e2eFailureDetail(err, out))
+			return result
+		}
+		result.Executed = true
+	}
+	result.Status = e2ePass
+	return result
+}
+
+// e2ePlatformSupported reports whether the spec supports a platform; specs without
+// supported_platforms support every platform
+func e2ePlatformSupported(installSpec *spec.InstallSpec, osName, arch string) bool {
+	if len(installSpec.SupportedPlatforms) == 0 {
+		return true
+	}
+	for _, p := range installSpec.SupportedPlatforms {
+		if spec.PlatformOSString(p.OS) == osName && spec.PlatformArchString(p.Arch) == arch {
+			return true
+		}
+	}
+	return false
+}
+
+// e2eFailureDetail summarizes a failed command by its error and last line of output
+func e2eFailureDetail(err error, output []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if last := strings.TrimSpace(lines[len(lines)-1]); last != "" {
+		return fmt.Sprintf("%v: %s", err, last)
+	}
+	return err.Error()
+}
+
+// printE2EResults prints the results as a table
+func printE2EResults(w io.Writer, results []e2eResult) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tPLATFORM\tSTATUS\tEXECUTED\tDURATION\tDETAIL")
+	for _, r := range results {
+		status := r.Status
+		switch r.Status {
+		case e2ePass:
+			status = "✓ " + status
+		case e2eFail:
+			status = "✗ " + status
+		default:
+			status = "- " + status
+		}
+		executed := "-"
+		if r.Executed {
+			executed = "yes"
+		}
+		fmt.Fprintf(tw, "%s\t%s/%s\t%s\t%s\t%.1fs\t%s\n", r.Version, r.OS, r.Arch, status, executed, r.Duration, r.Detail)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+func TestE2EMatrix(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").WithSupportedPlatforms("linux/amd64", "darwin/arm64")
+
+	cases, err := e2eMatrix(installSpec, nil, []string{"latest", "v1.2.3"})
+	if err != nil {
+		t.Fatalf("e2eMatrix() error = %v", err)
+	}
+	want := []e2eCase{
+		{Version: "latest", OS: "linux", Arch: "amd64"},
+		{Version: "latest", OS: "darwin", Arch: "arm64"},
+		{Version: "v1.2.3", OS: "linux", Arch: "amd64"},
+		{Version: "v1.2.3", OS: "darwin", Arch: "arm64"},
+	}
+	if len(cases) != len(want) {
+		t.Fatalf("e2eMatrix() = %v, want %v", cases, want)
+	}
+	for i := range want {
+		if cases[i] != want[i] {
+			t.Errorf("cases[%d] = %v, want %v", i, cases[i], want[i])
+		}
+	}
+
+	cases, err = e2eMatrix(spec.NewInstallSpec("owner/tool"), nil, nil)
+	if err != nil {
+		t.Fatalf("e2eMatrix() error = %v", err)
+	}
+	if len(cases) != 1 || cases[0] != (e2eCase{Version: "latest", OS: runtime.GOOS, Arch: runtime.GOARCH}) {
+		t.Errorf("e2eMatrix() without platforms = %v, want the host platform at latest", cases)
+	}
+
+	if _, err := e2eMatrix(installSpec, []string{"linux"}, nil); err == nil {
+		t.Error("e2eMatrix() accepted a platform without arch")
+	}
+}
+
+func TestRunE2ECases(t *testing.T) {
+	dir := t.TempDir()
+	// The fake installer installs an executable named tool, except for the broken version
+	installer := filepath.Join(dir, "install.sh")
+	writeTestFile(t, installer, `set -e
+[ "$3" = "v0.0.0" ] && { echo "release v0.0.0 not found"; exit 1; }
+mkdir -p "$2"
+printf '#!/bin/sh\nexit 0\n' > "$2/tool"
+chmod +x "$2/tool"
+echo "installed for $BINSTALLER_OS/$BINSTALLER_ARCH"
+`, 0755)
+
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}")).
+		WithSupportedPlatforms(runtime.GOOS+"/"+runtime.GOARCH, "plan9/amd64")
+	installSpec.SetDefaults()
+	cases := []e2eCase{
+		{Version: "v1.0.0", OS: runtime.GOOS, Arch: runtime.GOARCH},
+		{Version: "v1.0.0", OS: "plan9", Arch: "amd64"},
+		{Version: "v1.0.0", OS: "aix", Arch: "ppc64"},
+		{Version: "v0.0.0", OS: runtime.GOOS, Arch: runtime.GOARCH},
+	}
+
+	results := runE2ECases(context.Background(), installSpec, installer, dir, cases, 2, []string{"--version"})
+	want := []struct {
+		status   string
+		executed bool
+		detail   string
+	}{
+		{status: e2ePass, executed: runtime.GOOS != "windows"},
+		{status: e2ePass},
+		{status: e2eSkip, detail: "platform not supported by spec"},
+		{status: e2eFail, detail: "release v0.0.0 not found"},
+	}
+	for i, w := range want {
+		r := results[i]
+		if r.e2eCase != cases[i] || r.Status != w.status || r.Executed != w.executed || !strings.Contains(r.Detail, w.detail) {
+			t.Errorf("results[%d] = %+v, want status %s, executed %v, detail containing %q", i, r, w.status, w.executed, w.detail)
+		}
+	}
+
+	var buf bytes.Buffer
+	printE2EResults(&buf, results)
+	for _, w := range []string{"VERSION", "plan9/amd64", "✓ PASS", "- SKIP", "✗ FAIL"} {
+		if !strings.Contains(buf.String(), w) {
+			t.Errorf("table missing %q:\n%s", w, buf.String())
+		}
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/apex/log"
 	"github.com/binary-install/binstaller/pkg/checksums"
+	"github.com/binary-install/binstaller/pkg/sops"
 	"github.com/binary-install/binstaller/pkg/spec"
 	"github.com/goccy/go-yaml"
 	"github.com/goccy/go-yaml/parser"
@@ -15,10 +17,14 @@ import (
 
 var (
 	// Flags for embed-checksums command
-	embedVersion string
-	embedOutput  string
-	embedMode    string
-	embedFile    string
+	embedVersion  string
+	embedVersions string
+	embedLast     int
+	embedPrune    int
+	embedWorkers  int
+	embedOutput   string
+	embedMode     string
+	embedFile     string
 )
 
 // EmbedChecksumsCommand represents the embed-checksums command
@@ -26,23 +32,49 @@ var EmbedChecksumsCommand = &cobra.Command{
 	Use:   "embed-checksums",
 	Short: "Embed checksums for release assets into a binstaller configuration",
 	Long: `Reads an InstallSpec configuration file and embeds checksums for the assets.
-This command supports three modes of operation:
+This command supports four modes of operation:
 - download: Fetches the checksum file from GitHub releases
 - checksum-file: Uses a local checksum file
-- calculate: Downloads the assets and calculates checksums directly`,
+- calculate: Downloads the assets and calculates checksums directly
+- goreleaser-artifacts: Reads checksums from GoReleaser's dist/artifacts.json
+
+With --versions (a comma-separated list or a version range) or --last N (the N
+latest stable releases), the checksums of several versions are embedded in one
+run in download or calculate mode. A version that fails does not stop the
+others; the checksums embedded for the rest are still written.
+
+--prune-older-than N removes the embedded checksums of all but the N newest
+versions afterwards, so long-lived specs and the installers generated from
+them do not grow with every release.`,
 	Example: `  # Embed checksums by downloading checksum file from GitHub
   binst embed-checksums --version v1.0.0 --mode download
 
   # Embed checksums from a local checksum file
   binst embed-checksums --version v1.0.0 --mode checksum-file --file checksums.txt
 
-  # Calculate checksums by downloading assets (GITHUB_TOKEN recommended)
-  export GITHUB_TOKEN=$(gh auth token)
+  # Embed checksums right after a GoReleaser build, without downloading anything
+  # (version defaults to the tag in dist/metadata.json)
+  binst embed-checksums --mode goreleaser-artifacts --file dist/artifacts.json
+
+  # Calculate checksums by downloading assets (a GitHub token is recommended;
+  # GITHUB_TOKEN, GH_TOKEN or the token stored by gh auth login is used)
   binst embed-checksums --version v1.0.0 --mode calculate
 
+  # Download more assets at once in calculate mode
+  binst embed-checksums --version v1.0.0 --mode calculate --concurrency 8
+
   # Embed checksums for latest version
   binst embed-checksums --version latest --mode download
 
+  # Embed checksums for several versions at once
+  binst embed-checksums --versions v1.0.0,v1.1.0,v1.2.0 --mode download
+
+  # Embed checksums for the 5 latest stable releases
+  binst embed-checksums --last 5 --mode download
+
+  # Embed checksums for latest and keep those of the 10 newest versions only
+  binst embed-checksums --version latest --mode download --prune-older-than 10
+
   # Embed checksums with custom config and output
   binst embed-checksums --config myapp.yml --version v2.0.0 --mode download -o myapp-checksums.yml
 
@@ -77,6 +109,10 @@ This command supports three modes of operation:
 			log.WithError(err).Errorf("Failed to read install spec file: %s", cfgFile)
 			return fmt.Errorf("failed to read install spec file %s: %w", cfgFile, err)
 		}
+		if sops.IsEncrypted(yamlData) {
+			// Writing plaintext checksums into the file would invalidate its SOPS MAC
+			return fmt.Errorf("%s is encrypted with SOPS: decrypt it, embed checksums, and encrypt it again", cfgFile)
+		}
 
 		// Unmarshal YAML into InstallSpec struct
 		log.Debug("Unmarshalling InstallSpec YAML")
@@ -86,6 +122,7 @@ This command supports three modes of operation:
 			log.WithError(err).Errorf("Failed to unmarshal install spec YAML from: %s", cfgFile)
 			return fmt.Errorf("failed to unmarshal install spec YAML from %s: %w", cfgFile, err)
 		}
+		registerSpecHosts(&installSpec)
 
 		// Create the embedder
 		var mode checksums.EmbedMode
@@ -96,29 +133,68 @@ This command supports three modes of operation:
 			mode = checksums.EmbedModeChecksumFile
 		case "calculate":
 			mode = checksums.EmbedModeCalculate
+		case "goreleaser-artifacts":
+			mode = checksums.EmbedModeGoReleaserArtifacts
 		default:
-			return fmt.Errorf("invalid mode: %s. Must be one of: download, checksum-file, calculate", embedMode)
+			return fmt.Errorf("invalid mode: %s. Must be one of: download, checksum-file, calculate, goreleaser-artifacts", embedMode)
 		}
 
-		// Validate checksum-file mode has a file
-		if mode == checksums.EmbedModeChecksumFile && embedFile == "" {
-			log.Error("--file flag is required for checksum-file mode")
-			return fmt.Errorf("--file flag is required for checksum-file mode")
+		// Validate file-based modes have a file
+		if (mode == checksums.EmbedModeChecksumFile || mode == checksums.EmbedModeGoReleaserArtifacts) && embedFile == "" {
+			log.Errorf("--file flag is required for %s mode", mode)
+			return fmt.Errorf("--file flag is required for %s mode", mode)
+		}
+		if err := validateEmbedVersions(mode); err != nil {
+			return err
+		}
+		if embedWorkers < 1 {
+			return fmt.Errorf("--concurrency must be at least 1")
+		}
+		if embedPrune < 0 {
+			return fmt.Errorf("--prune-older-than must be a positive number of versions")
 		}
 
-		embedder := &checksums.Embedder{
-			Mode:         mode,
-			Version:      embedVersion,
-			Spec:         &installSpec,
-			SpecAST:      ast,
-			ChecksumFile: embedFile,
+		var versions []string
+		if embedVersions != "" || embedLast > 0 {
+			versions, err = embedVersionList(cmd.Context(), &installSpec, embedVersions, embedLast)
+			if err != nil {
+				return fmt.Errorf("failed to list versions: %w", err)
+			}
+			log.Infof("Embedding checksums using %s mode for %d version(s): %s", mode, len(versions), strings.Join(versions, ", "))
 		}
 
 		// Embed the checksums
-		log.Infof("Embedding checksums using %s mode for version: %s", mode, embedVersion)
-		if err := embedder.Embed(); err != nil {
-			log.WithError(err).Error("Failed to embed checksums")
-			return fmt.Errorf("failed to embed checksums: %w", err)
+		var embedErr error
+		if versions != nil {
+			var embedded int
+			embedded, embedErr = embedChecksumsForVersions(cmd.OutOrStdout(), &installSpec, ast, mode, versions, embedWorkers)
+			if embedded == 0 {
+				return embedErr
+			}
+		} else {
+			embedder := &checksums.Embedder{
+				Mode:         mode,
+				Version:      embedVersion,
+				Spec:         &installSpec,
+				SpecAST:      ast,
+				ChecksumFile: embedFile,
+				Concurrency:  embedWorkers,
+			}
+			log.Infof("Embedding checksums using %s mode for version: %s", mode, embedVersion)
+			if err := embedder.Embed(); err != nil {
+				log.WithError(err).Error("Failed to embed checksums")
+				return fmt.Errorf("failed to embed checksums: %w", err)
+			}
+		}
+
+		if embedPrune > 0 {
+			pruned, err := checksums.PruneEmbeddedChecksums(&installSpec, ast, embedPrune)
+			if err != nil {
+				return err
+			}
+			if len(pruned) > 0 {
+				log.Infof("Pruned the embedded checksums of %d older version(s): %s", len(pruned), strings.Join(pruned, ", "))
+			}
 		}
 
 		// Determine output file
@@ -145,16 +221,20 @@ This command supports three modes of operation:
 		}
 		log.Infof("InstallSpec successfully updated with embedded checksums")
 
-		return nil
+		return embedErr
 	},
 }
 
 func init() {
 	// Flags specific to embed-checksums command
 	EmbedChecksumsCommand.Flags().StringVarP(&embedVersion, "version", "v", "", "Version to embed checksums for (default: latest)")
+	EmbedChecksumsCommand.Flags().StringVar(&embedVersions, "versions", "", "Comma-separated list or range of versions to embed checksums for (e.g. v1.0.0,v1.1.0 or \">=1.2, <2\")")
+	EmbedChecksumsCommand.Flags().IntVar(&embedLast, "last", 0, "Embed checksums for the N latest stable releases")
+	EmbedChecksumsCommand.Flags().IntVar(&embedPrune, "prune-older-than", 0, "Keep the embedded checksums of only the N newest versions")
+	EmbedChecksumsCommand.Flags().IntVar(&embedWorkers, "concurrency", checksums.DefaultConcurrency, "Number of assets downloaded at once in calculate mode")
 	EmbedChecksumsCommand.Flags().StringVarP(&embedOutput, "output", "o", "", "Output path for the updated InstallSpec (default: overwrite input file)")
-	EmbedChecksumsCommand.Flags().StringVarP(&embedMode, "mode", "m", "download", "Checksums acquisition mode (download, checksum-file, calculate)")
-	EmbedChecksumsCommand.Flags().StringVarP(&embedFile, "file", "f", "", "Path to checksum file (required for checksum-file mode)")
+	EmbedChecksumsCommand.Flags().StringVarP(&embedMode, "mode", "m", "download", "Checksums acquisition mode (download, checksum-file, calculate, goreleaser-artifacts)")
+	EmbedChecksumsCommand.Flags().StringVarP(&embedFile, "file", "f", "", "Path to checksum file or GoReleaser artifacts.json (required for checksum-file and goreleaser-artifacts modes)")
 
 	// Mark required flags
 	EmbedChecksumsCommand.MarkFlagRequired("mode")
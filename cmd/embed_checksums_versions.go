@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/checksums"
+	"github.com/binary-install/binstaller/pkg/resolver"
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/goccy/go-yaml/ast"
+)
+
+// validateEmbedVersions checks that --versions and --last are not combined
+// with each other, with --version, or with modes reading a single local file
+func validateEmbedVersions(mode checksums.EmbedMode) error {
+	if embedVersions == "" && embedLast == 0 {
+		return nil
+	}
+	switch {
+	case embedLast < 0:
+		return fmt.Errorf("--last must be a positive number of releases")
+	case embedVersions != "" && embedLast > 0:
+		return fmt.Errorf("--versions and --last cannot be combined")
+	case embedVersion != "":
+		return fmt.Errorf("--version cannot be combined with --versions or --last")
+	case mode == checksums.EmbedModeChecksumFile || mode == checksums.EmbedModeGoReleaserArtifacts:
+		return fmt.Errorf("--versions and --last are not supported in %s mode, which reads the checksums of one version from --file", mode)
+	}
+	return nil
+}
+
+// embedVersionList returns the versions to embed checksums for, oldest first:
+// the versions of a --versions list or range, or the last stable releases
+func embedVersionList(ctx context.Context, installSpec *spec.InstallSpec, versions string, last int) ([]string, error) {
+	r := resolver.New(installSpec)
+	r.APIBaseURL = gitHubAPIURL(installSpec)
+	r.GitLabBaseURL = gitLabBaseURL
+	if versions != "" {
+		return r.Expand(ctx, versions)
+	}
+
+	releases, err := r.Releases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var tags []string
+	for _, release := range releases {
+		if len(tags) >= last {
+			break
+		}
+		if !release.Prerelease {
+			tags = append(tags, release.Tag)
+		}
+	}
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("no stable releases of %s found", installSpec.GetRepo())
+	}
+	if len(tags) < last {
+		log.Warnf("%s has only %d stable releases", installSpec.GetRepo(), len(tags))
+	}
+	slices.Reverse(tags)
+	return tags, nil
+}
+
+// versionResult is the outcome of embedding the checksums of one version
+type versionResult struct {
+	version string
+	count   int
+	err     error
+}
+
+// embedChecksumsForVersions embeds the checksums of every version into the spec
+// and its AST, one version after another. A failing version does not stop the
+// others: the checksums of the versions that succeeded are kept, a summary is
+// printed to w and an error reports the failures. It returns the number of
+// versions whose checksums were embedded. Calculate mode downloads concurrency
+// assets of a version at once.
+func embedChecksumsForVersions(w io.Writer, installSpec *spec.InstallSpec, specAST *ast.File, mode checksums.EmbedMode, versions []string, concurrency int) (int, error) {
+	results := make([]versionResult, 0, len(versions))
+	failed := 0
+	for i, version := range versions {
+		log.Infof("[%d/%d] Embedding checksums for %s", i+1, len(versions), version)
+		embedder := &checksums.Embedder{
+			Mode:        mode,
+			Version:     version,
+			Spec:        installSpec,
+			SpecAST:     specAST,
+			Concurrency: concurrency,
+		}
+		result := versionResult{version: version}
+		var previous []spec.EmbeddedChecksum
+		hadPrevious := false
+		if installSpec.Checksums != nil {
+			previous, hadPrevious = installSpec.Checksums.EmbeddedChecksums[version]
+		}
+		if err := embedder.Embed(); err != nil {
+			result.err = err
+			failed++
+			log.WithError(err).Errorf("Failed to embed checksums for %s", version)
+			// Embed clears the checksums of the version before fetching the new
+			// ones; restore them so a later version does not write the gap
+			if installSpec.Checksums != nil {
+				if hadPrevious {
+					installSpec.Checksums.EmbeddedChecksums[version] = previous
+				} else {
+					delete(installSpec.Checksums.EmbeddedChecksums, version)
+				}
+			}
+		} else {
+			result.count = len(installSpec.Checksums.EmbeddedChecksums[version])
+		}
+		results = append(results, result)
+	}
+
+	printVersionResults(w, results)
+	if failed > 0 {
+		return len(versions) - failed, fmt.Errorf("failed to embed checksums for %d of %d versions", failed, len(versions))
+	}
+	return len(versions), nil
+}
+
+// printVersionResults prints a table of the checksums embedded per version
+func printVersionResults(w io.Writer, results []versionResult) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tCHECKSUMS\tSTATUS")
+	for _, r := range results {
+		status := "embedded"
+		if r.err != nil {
+			status = "failed: " + strings.SplitN(r.err.Error(), "\n", 2)[0]
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", r.version, r.count, status)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/checksums"
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/parser"
+)
+
+func TestEmbedChecksumsForVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/tool/releases":
+			w.Write([]byte(`[
+				{"tag_name": "v1.3.0-rc.1", "prerelease": true, "published_at": "2024-04-01T00:00:00Z"},
+				{"tag_name": "v1.2.0", "published_at": "2024-03-01T00:00:00Z"},
+				{"tag_name": "v1.1.0", "published_at": "2024-02-01T00:00:00Z"},
+				{"tag_name": "v1.0.0", "published_at": "2024-01-01T00:00:00Z"}
+			]`))
+		case "/owner/tool/releases/download/v1.1.0/checksums.txt":
+			w.Write([]byte(strings.Repeat("b", 64) + "  tool_linux_amd64\n"))
+		case "/owner/tool/releases/download/v1.2.0/checksums.txt":
+			w.Write([]byte(strings.Repeat("c", 64) + "  tool_linux_amd64\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("GITHUB_SERVER_URL", server.URL)
+	oldAPIURL := gitHubAPIBaseURL
+	gitHubAPIBaseURL = server.URL
+	defer func() { gitHubAPIBaseURL = oldAPIURL }()
+
+	config := `name: tool
+repo: owner/tool
+asset:
+  template: ${NAME}_${OS}_${ARCH}
+checksums:
+  template: checksums.txt
+  embedded_checksums:
+    v1.0.0:
+      - filename: tool_linux_amd64
+        hash: ` + strings.Repeat("a", 64) + `
+`
+	specAST, err := parser.ParseBytes([]byte(config), parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var installSpec spec.InstallSpec
+	if err := yaml.UnmarshalWithOptions([]byte(config), &installSpec, yaml.UseOrderedMap()); err != nil {
+		t.Fatal(err)
+	}
+
+	versions, err := embedVersionList(context.Background(), &installSpec, "", 3)
+	if err != nil {
+		t.Fatalf("embedVersionList() error = %v", err)
+	}
+	if got := strings.Join(versions, ","); got != "v1.0.0,v1.1.0,v1.2.0" {
+		t.Errorf("embedVersionList(--last 3) = %s, want the stable releases oldest first", got)
+	}
+
+	// v1.0.0 has no checksum file any more: its embedded checksums are kept
+	var out bytes.Buffer
+	embedded, err := embedChecksumsForVersions(&out, &installSpec, specAST, checksums.EmbedModeDownload, versions, 1)
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 versions") {
+		t.Errorf("embedChecksumsForVersions() error = %v, want 1 of 3 versions failing", err)
+	}
+	if embedded != 2 {
+		t.Errorf("embedChecksumsForVersions() = %d, want 2 versions embedded", embedded)
+	}
+	for version, hash := range map[string]string{"v1.0.0": "a", "v1.1.0": "b", "v1.2.0": "c"} {
+		if !strings.Contains(specAST.String(), strings.Repeat(hash, 64)) {
+			t.Errorf("the checksum of %s is missing from the spec:\n%s", version, specAST.String())
+		}
+	}
+	if !strings.Contains(out.String(), "v1.0.0") || !strings.Contains(out.String(), "failed:") {
+		t.Errorf("summary lacks the failed version:\n%s", out.String())
+	}
+}
+
+func TestValidateEmbedVersions(t *testing.T) {
+	defer func() { embedVersion, embedVersions, embedLast = "", "", 0 }()
+	tests := []struct {
+		name     string
+		version  string
+		versions string
+		last     int
+		mode     checksums.EmbedMode
+		wantErr  string
+	}{
+		{name: "single version", version: "v1.0.0", mode: checksums.EmbedModeDownload},
+		{name: "list", versions: "v1.0.0,v1.1.0", mode: checksums.EmbedModeCalculate},
+		{name: "last", last: 5, mode: checksums.EmbedModeDownload},
+		{name: "negative last", last: -1, mode: checksums.EmbedModeDownload, wantErr: "positive"},
+		{name: "list and last", versions: "v1.0.0", last: 2, mode: checksums.EmbedModeDownload, wantErr: "cannot be combined"},
+		{name: "version and list", version: "v1.0.0", versions: "v1.1.0", mode: checksums.EmbedModeDownload, wantErr: "--version"},
+		{name: "checksum file", last: 2, mode: checksums.EmbedModeChecksumFile, wantErr: "checksum-file mode"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			embedVersion, embedVersions, embedLast = tt.version, tt.versions, tt.last
+			err := validateEmbedVersions(tt.mode)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("validateEmbedVersions() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("validateEmbedVersions() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/cache"
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/spf13/cobra"
+)
+
+// ProjectToolsDir is the directory, relative to the project root, that holds one InstallSpec per tool
+const ProjectToolsDir = ".config/binstaller"
+
+// ExecCommand represents the exec command
+var ExecCommand = &cobra.Command{
+	Use:   "exec TOOL[@VERSION] [-- ARGS...]",
+	Short: "Run a tool at the version pinned by the project config",
+	Long: `Run a release binary at the version pinned by the project, installing it into the
+binstaller cache on first use.
+
+The tool's InstallSpec is found by walking up from the current directory and checking:
+  1. .config/binstaller/TOOL.yml (or .yaml)
+  2. .config/binstaller.yml (or .yaml) when its name or one of its binaries is TOOL
+An explicit --config skips the lookup.
+
+The version is taken from TOOL@VERSION, then the spec's default_version, then the
+latest release. Installed binaries are kept under $BINSTALLER_CACHE_DIR/tools, so
+later runs of a pinned version need no network access. The tool runs with the
+runtime_env variables of its spec set.`,
+	Example: `  # Run the project's pinned golangci-lint
+  binst exec golangci-lint -- run ./...
+
+  # Run a specific version
+  binst exec gh@v2.40.0 -- --version
+
+  # Run a tool from an explicit config
+  binst exec --config ./tools/fzf.binstaller.yml fzf -- --version`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runExec,
+}
+
+func init() {
+	// Everything after TOOL belongs to the tool, even without --
+	ExecCommand.Flags().SetInterspersed(false)
+}
+
+func runExec(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	tool, version, _ := strings.Cut(args[0], "@")
+	toolArgs := args[1:]
+	if len(toolArgs) > 0 && toolArgs[0] == "--" {
+		toolArgs = toolArgs[1:]
+	}
+
+	cfgPath := configFile
+	if cfgPath == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+		cfgPath, err = findToolSpec(cwd, tool)
+		if err != nil {
+			return err
+		}
+	}
+	log.Debugf("Using config file for %s: %s", tool, cfgPath)
+
+	installSpec, err := loadInstallSpecWithOverlays(ctx, cfgPath, true)
+	if err != nil {
+		return err
+	}
+	installSpec.SetDefaults()
+	ctx = specContext(ctx, installSpec)
+
+	if version == "" {
+		version = spec.StringValue(installSpec.DefaultVersion)
+	}
+	tag, err := resolveVersion(ctx, installSpec, version)
+	if err != nil {
+		return fmt.Errorf("failed to resolve version: %w", err)
+	}
+
+	store, err := cache.New()
+	if err != nil {
+		return err
+	}
+	toolDir, err := store.ToolDir(installSpec.GetRepo(), tag)
+	if err != nil {
+		return err
+	}
+
+	osName, arch := detectPlatform(installSpec)
+	binaryPath := filepath.Join(toolDir, execBinaryName(installSpec, osName, arch, tool))
+	if _, err := os.Stat(binaryPath); err != nil {
+		log.Infof("Installing %s %s into %s", installSpec.GetName(), tag, toolDir)
+		if _, err := installRelease(ctx, installSpec, tag, toolDir, false, assetSource{}); err != nil {
+			return err
+		}
+	}
+
+	log.Debugf("Running %s %s", binaryPath, strings.Join(toolArgs, " "))
+	c := exec.CommandContext(ctx, binaryPath, toolArgs...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	c.Env = execEnv(os.Environ(), installSpec.ExpandRuntimeEnv(toolDir))
+	if err := c.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			// Propagate the tool's exit code as our own
+			os.Exit(exitErr.ExitCode())
+		}
+		return fmt.Errorf("failed to run %s: %w", binaryPath, err)
+	}
+	return nil
+}
+
+// findToolSpec walks up from dir looking for the InstallSpec of tool
+func findToolSpec(dir, tool string) (string, error) {
+	for {
+		for _, ext := range []string{".yml", ".yaml"} {
+			candidate := filepath.Join(dir, filepath.FromSlash(ProjectToolsDir), tool+ext)
+			if _, err := os.Stat(candidate); err == nil {
+				return candidate, nil
+			}
+		}
+		for _, name := range []string{DefaultConfigPathYML, DefaultConfigPathYAML} {
+			candidate := filepath.Join(dir, filepath.FromSlash(name))
+			if _, err := os.Stat(candidate); err != nil {
+				continue
+			}
+			installSpec, err := loadInstallSpec(candidate)
+			if err != nil {
+				return "", err
+			}
+			if specProvidesTool(installSpec, tool) {
+				return candidate, nil
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("no config found for %s: add %s/%s.yml to your project or pass --config", tool, ProjectToolsDir, tool)
+		}
+		dir = parent
+	}
+}
+
+// specProvidesTool reports whether the spec is named tool or installs a binary named tool
+func specProvidesTool(installSpec *spec.InstallSpec, tool string) bool {
+	installSpec.SetDefaults()
+	if installSpec.GetName() == tool {
+		return true
+	}
+	if installSpec.Asset == nil {
+		return false
+	}
+	for _, binary := range installSpec.Asset.Binaries {
+		if binary.GetName() == tool {
+			return true
+		}
+	}
+	return false
+}
+
+// execBinaryName returns the installed binary to run: the one named tool if
+// present, otherwise the first binary for the platform
+func execBinaryName(installSpec *spec.InstallSpec, osName, arch, tool string) string {
+	var names []string
+	for _, binary := range getBinariesForPlatform(installSpec, osName, arch) {
+		name := spec.StringValue(binary.Name)
+		if name == "" {
+			name = installSpec.GetName()
+		}
+		if name == tool {
+			return name
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return installSpec.GetName()
+	}
+	return names[0]
+}
+
+// execEnv returns environ with the runtime_env of the tool added, replacing
+// variables of the same name
+func execEnv(environ []string, runtimeEnv map[string]string) []string {
+	env := make([]string, 0, len(environ)+len(runtimeEnv))
+	for _, kv := range environ {
+		name, _, _ := strings.Cut(kv, "=")
+		if _, ok := runtimeEnv[name]; !ok {
+			env = append(env, kv)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(runtimeEnv)) {
+		env = append(env, name+"="+runtimeEnv[name])
+	}
+	return env
+}
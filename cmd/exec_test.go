@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/cache"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+func writeTestFile(t *testing.T, path, content string, perm os.FileMode) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), perm); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFindToolSpec(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(root, ".config", "binstaller", "gh.yml"), "repo: cli/cli\nname: gh\n", 0644)
+	writeTestFile(t, filepath.Join(root, ".config", "binstaller.yml"), `repo: owner/tools
+asset:
+  template: tools.tar.gz
+  binaries:
+    - name: tool-a
+      path: tool-a
+`, 0644)
+
+	tests := []struct {
+		tool    string
+		want    string
+		wantErr bool
+	}{
+		{"gh", filepath.Join(root, ".config", "binstaller", "gh.yml"), false},
+		{"tools", filepath.Join(root, ".config", "binstaller.yml"), false},
+		{"tool-a", filepath.Join(root, ".config", "binstaller.yml"), false},
+		{"missing", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			got, err := findToolSpec(nested, tt.tool)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("findToolSpec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("findToolSpec() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecBinaryName(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tools").
+		WithAsset(spec.NewAsset("tools.tar.gz").
+			WithBinary("tool-a", "tool-a").
+			WithBinary("tool-b", "bin/tool-b"))
+
+	if got := execBinaryName(installSpec, "linux", "amd64", "tool-b"); got != "tool-b" {
+		t.Errorf("execBinaryName(tool-b) = %q", got)
+	}
+	if got := execBinaryName(installSpec, "linux", "amd64", "tools"); got != "tool-a" {
+		t.Errorf("execBinaryName(tools) = %q, want first binary", got)
+	}
+}
+
+func TestRunExecCached(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the cached binary")
+	}
+
+	root := t.TempDir()
+	cacheDir := filepath.Join(root, "cache")
+	t.Setenv(cache.EnvDir, cacheDir)
+	t.Setenv("BINSTALLER_DEFAULTS_URL", "")
+	t.Setenv("BINSTALLER_OVERRIDES", filepath.Join(root, "missing.yml"))
+
+	cfg := filepath.Join(root, "tool.yml")
+	writeTestFile(t, cfg, "repo: owner/tool\ndefault_version: v1.0.0\nasset:\n  template: ${NAME}_${VERSION}\nruntime_env:\n  TOOL_HOME: ${INSTALL_DIR}/share\n", 0644)
+
+	out := filepath.Join(root, "out.txt")
+	store := &cache.Store{Root: cacheDir}
+	toolDir, err := store.ToolDir("owner/tool", "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(toolDir, "tool"), "#!/bin/sh\necho \"$TOOL_HOME\" \"$@\" > "+out+"\n", 0755)
+
+	oldConfig := configFile
+	configFile = cfg
+	defer func() { configFile = oldConfig }()
+
+	ExecCommand.SetContext(context.Background())
+	if err := runExec(ExecCommand, []string{"tool", "--", "--flag", "arg"}); err != nil {
+		t.Fatalf("runExec() error = %v", err)
+	}
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The tool runs with its runtime_env
+	want := filepath.Join(filepath.Dir(toolDir), "share") + " --flag arg"
+	if strings.TrimSpace(string(got)) != want {
+		t.Errorf("tool received %q, want %q", got, want)
+	}
+}
+
+func TestExecEnv(t *testing.T) {
+	got := execEnv([]string{"PATH=/bin", "TOOL_HOME=/old", "HOME=/home/me"}, map[string]string{"TOOL_HOME": "/opt/tool/share", "A_DIR": "/opt/tool/bin"})
+	want := []string{"PATH=/bin", "HOME=/home/me", "A_DIR=/opt/tool/bin", "TOOL_HOME=/opt/tool/share"}
+	if !slices.Equal(got, want) {
+		t.Errorf("execEnv() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/asset"
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/spf13/cobra"
+)
+
+// explainVersion is the --version flag of the explain-url command
+var explainVersion string
+
+// ExplainURLCommand represents the explain-url command
+var ExplainURLCommand = &cobra.Command{
+	Use:   "explain-url URL|FILENAME",
+	Short: "Explain which platform and rules produce a release asset",
+	Long: `Parses a release asset URL or filename against the spec and reports which
+platform it is the asset of, which asset rules produced it, and whether its
+checksum is embedded. This is the inverse of generating filenames, useful when
+triaging bug reports that only include the URL an installer downloaded.
+
+The version is taken from the URL's release tag, then --version, and is
+otherwise parsed from the filename.`,
+	Example: `  # Explain a download URL from a bug report
+  binst explain-url https://github.com/owner/tool/releases/download/v1.2.3/tool_1.2.3_linux_x86_64.tar.gz
+
+  # Explain a bare filename
+  binst explain-url tool_1.2.3_darwin_all.tar.gz`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfgFile, err := resolveConfigFile(configFile)
+		if err != nil {
+			return err
+		}
+		installSpec, err := loadInstallSpec(cfgFile)
+		if err != nil {
+			return err
+		}
+		installSpec.SetDefaults()
+		return RunExplainURL(installSpec, args[0], explainVersion, os.Stdout)
+	},
+}
+
+// releaseAsset is a release asset URL split into its parts
+type releaseAsset struct {
+	// repo is the repository of the URL, empty for bare filenames
+	repo string
+	// tag is the release tag, empty for latest releases and bare filenames
+	tag      string
+	filename string
+}
+
+// parseReleaseAsset splits a GitHub or GitLab release download URL, or returns a
+// bare filename as is
+func parseReleaseAsset(raw string) releaseAsset {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return releaseAsset{filename: path.Base(raw)}
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	result := releaseAsset{filename: segments[len(segments)-1]}
+	i := slices.Index(segments, "releases")
+	switch {
+	case i < 0:
+	case i > 1 && segments[i-1] == "-" && i+3 < len(segments) && segments[i+2] == "downloads":
+		// GitLab: GROUP/REPO/-/releases/TAG/downloads/FILENAME
+		result.repo = strings.Join(segments[:i-1], "/")
+		result.tag = segments[i+1]
+	case i+3 < len(segments) && segments[i+1] == "download":
+		// GitHub: OWNER/REPO/releases/download/TAG/FILENAME
+		result.repo = strings.Join(segments[:i], "/")
+		result.tag = segments[i+2]
+	case i+3 < len(segments) && segments[i+1] == "latest" && segments[i+2] == "download":
+		result.repo = strings.Join(segments[:i], "/")
+	}
+	return result
+}
+
+// RunExplainURL writes which platforms of installSpec resolve to the asset of
+// a URL or filename, and how
+func RunExplainURL(installSpec *spec.InstallSpec, arg, version string, w io.Writer) error {
+	if installSpec.Asset == nil {
+		return fmt.Errorf("asset configuration is required")
+	}
+	ra := parseReleaseAsset(arg)
+	if ra.repo != "" && !strings.EqualFold(ra.repo, installSpec.GetRepo()) {
+		log.Warnf("URL is a release of %s, not of %s", ra.repo, installSpec.GetRepo())
+	}
+	if ra.tag != "" {
+		version = ra.tag
+	}
+
+	matches, err := asset.NewFilenameGenerator(installSpec, version).Match(ra.filename)
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		if version != "" {
+			return fmt.Errorf("%s is not an asset of any supported platform of %s at %s", ra.filename, installSpec.GetName(), version)
+		}
+		return fmt.Errorf("%s is not an asset of any supported platform of %s", ra.filename, installSpec.GetName())
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Asset: %s\n", ra.filename)
+	for _, m := range matches {
+		fmt.Fprintf(&b, "\nPlatform: %s\n", m.Platform)
+		if m.OSVersion != "" {
+			fmt.Fprintf(&b, "  OS version: %s\n", m.OSVersion)
+		}
+		if m.Tag != "" {
+			fmt.Fprintf(&b, "  Version: %s\n", m.Tag)
+		}
+		if m.Candidate == 0 {
+			fmt.Fprintf(&b, "  Template: %s\n", m.Resolution.Template)
+		} else {
+			templates := asset.NewFilenameGenerator(installSpec, m.Tag).FallbackTemplates(m.Platform.OS, m.Platform.Arch)
+			fmt.Fprintf(&b, "  Template: %s (fallback #%d)\n", templates[m.Candidate-1], m.Candidate)
+		}
+		fmt.Fprintf(&b, "  Values: OS=%s ARCH=%s EXT=%s\n", m.Resolution.OS, m.Resolution.Arch, m.Resolution.EXT)
+		if len(m.Resolution.Rules) == 0 {
+			b.WriteString("  Rules: none\n")
+		} else {
+			b.WriteString("  Rules:\n")
+			for _, i := range m.Resolution.Rules {
+				fmt.Fprintf(&b, "    %s\n", strings.Join(ruleLabel(i, installSpec.Asset.Rules[i]), ", "))
+			}
+		}
+		b.WriteString("  Checksum: " + explainChecksum(installSpec, m.Tag, ra.filename) + "\n")
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// explainChecksum describes the embedded checksum of a filename. Without a tag
+// the versions embedding the filename are listed.
+func explainChecksum(installSpec *spec.InstallSpec, tag, filename string) string {
+	checksums := installSpec.GetChecksums()
+	if tag != "" {
+		for _, version := range []string{tag, strings.TrimPrefix(tag, "v")} {
+			if hash, ok := checksums.GetEmbeddedChecksum(version, filename); ok {
+				return fmt.Sprintf("embedded for %s (%s)", version, hash)
+			}
+		}
+		return "not embedded for " + tag
+	}
+	if checksums == nil {
+		return "not embedded"
+	}
+	var versions []string
+	for version := range checksums.EmbeddedChecksums {
+		if _, ok := checksums.GetEmbeddedChecksum(version, filename); ok {
+			versions = append(versions, version)
+		}
+	}
+	if len(versions) == 0 {
+		return "not embedded"
+	}
+	slices.SortFunc(versions, spec.CompareVersions)
+	return "embedded for " + strings.Join(versions, ", ")
+}
+
+func init() {
+	ExplainURLCommand.Flags().StringVar(&explainVersion, "version", "", "Version of the asset when the URL has no release tag (default: parsed from the filename)")
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+func TestParseReleaseAsset(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want releaseAsset
+	}{
+		{"https://github.com/owner/tool/releases/download/v1.2.3/tool_linux.tar.gz", releaseAsset{repo: "owner/tool", tag: "v1.2.3", filename: "tool_linux.tar.gz"}},
+		{"https://github.com/owner/tool/releases/latest/download/tool_linux.tar.gz", releaseAsset{repo: "owner/tool", filename: "tool_linux.tar.gz"}},
+		{"https://gitlab.com/group/sub/tool/-/releases/v1.0.0/downloads/tool.zip", releaseAsset{repo: "group/sub/tool", tag: "v1.0.0", filename: "tool.zip"}},
+		{"https://mirror.example.com/files/tool.zip", releaseAsset{filename: "tool.zip"}},
+		{"dist/tool_linux.tar.gz", releaseAsset{filename: "tool_linux.tar.gz"}},
+	}
+	for _, tt := range tests {
+		if got := parseReleaseAsset(tt.raw); got != tt.want {
+			t.Errorf("parseReleaseAsset(%q) = %+v, want %+v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestRunExplainURL(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${VERSION}_${OS}_${ARCH}${EXT}").
+			WithDefaultExtension(".tar.gz").
+			WithRules(
+				spec.NewRule("", "amd64").WithArch("x86_64"),
+				spec.NewRule("windows", "").WithExt(".zip"),
+			)).
+		WithChecksums(spec.NewChecksums("checksums.txt").WithEmbeddedChecksum("v1.2.3", "tool_1.2.3_windows_x86_64.zip", "abc123")).
+		WithSupportedPlatforms("linux/amd64", "windows/amd64")
+	installSpec.SetDefaults()
+
+	var buf bytes.Buffer
+	if err := RunExplainURL(installSpec, "https://github.com/owner/tool/releases/download/v1.2.3/tool_1.2.3_windows_x86_64.zip", "", &buf); err != nil {
+		t.Fatalf("RunExplainURL() error = %v", err)
+	}
+	for _, want := range []string{
+		"Platform: windows/amd64",
+		"Version: v1.2.3",
+		"Values: OS=windows ARCH=x86_64 EXT=.zip",
+		"rule #1, when arch=amd64, arch → x86_64",
+		"rule #2, when os=windows, ext → .zip",
+		"Checksum: embedded for v1.2.3 (abc123)",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("RunExplainURL() output missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := RunExplainURL(installSpec, "tool_2.0.0_linux_x86_64.tar.gz", "", &buf); err != nil {
+		t.Fatalf("RunExplainURL() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "Platform: linux/amd64") || !strings.Contains(buf.String(), "Checksum: not embedded for v2.0.0") {
+		t.Errorf("RunExplainURL() output for a filename:\n%s", buf.String())
+	}
+
+	if err := RunExplainURL(installSpec, "tool_1.2.3_linux_x86_64.tar.gz", "v2.0.0", &bytes.Buffer{}); err == nil {
+		t.Error("RunExplainURL() expected error for an asset of another version")
+	}
+}
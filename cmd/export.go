@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/binary-install/binstaller/pkg/export/aqua"
+	"github.com/binary-install/binstaller/pkg/export/homebrew"
+	"github.com/binary-install/binstaller/pkg/export/scoop"
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Flags for export command
+	exportFormat string
+	exportOutput string
+)
+
+// exporter renders the release tag of an InstallSpec as a package manifest
+type exporter func(ctx context.Context, installSpec *spec.InstallSpec, tag string) ([]byte, error)
+
+// exporters are the formats of binst export
+var exporters = map[string]exporter{
+	"aqua":          exportAquaRegistry,
+	"homebrew":      exportHomebrewFormula,
+	"homebrew-cask": exportHomebrewCask,
+	"scoop":         exportScoopManifest,
+}
+
+// releaseIndependentFormats describe every release alike, so export resolves no version for them
+var releaseIndependentFormats = map[string]bool{
+	"aqua": true,
+}
+
+// exportFormats returns the names of the export formats, sorted
+func exportFormats() []string {
+	formats := make([]string, 0, len(exporters))
+	for format := range exporters {
+		formats = append(formats, format)
+	}
+	slices.Sort(formats)
+	return formats
+}
+
+// ExportCommand represents the export command
+var ExportCommand = &cobra.Command{
+	Use:   "export [VERSION]",
+	Short: "Render a package manifest from an InstallSpec",
+	Long: `Render the manifest of another package manager from an InstallSpec, reusing the
+release layout the spec already describes: asset templates, rules, binaries, and
+supported platforms.
+
+The release is VERSION, or else the spec's default_version, or else the latest
+release. SHA-256 hashes come from embedded checksums, falling back to the
+release checksum file.
+
+Formats:
+  aqua           aqua registry entry (registry.yaml) with the asset rules as
+                 replacements, format_overrides and overrides; it describes
+                 every release, so VERSION is ignored
+  homebrew       Homebrew formula (Formula/NAME.rb) for the macOS and Linux
+                 amd64/arm64 platforms of the spec
+  homebrew-cask  Homebrew cask (Casks/NAME.rb) linking the binaries
+  scoop          Scoop manifest (bucket/NAME.json) for the windows amd64, 386
+                 and arm64 platforms, with checkver and autoupdate for GitHub
+
+Use 'binst brew-tap sync' to keep a whole tap of formulas up to date.`,
+	Example: `  # Print the Homebrew formula of the default config
+  binst export --format homebrew
+
+  # Write the formula of a specific release into a tap
+  binst export --format homebrew -c mytool.binstaller.yml -o ../homebrew-tap/Formula/mytool.rb v1.2.3
+
+  # Render a cask instead
+  binst export --format homebrew-cask -o Casks/mytool.rb
+
+  # Write the manifest of a Scoop bucket
+  binst export --format scoop -o bucket/mytool.json
+
+  # Write the package of an aqua registry
+  binst export --format aqua -o pkgs/owner/mytool/registry.yaml`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		export, ok := exporters[exportFormat]
+		if !ok {
+			return fmt.Errorf("unsupported export format %q: must be one of %s", exportFormat, strings.Join(exportFormats(), ", "))
+		}
+
+		cfgFile, err := resolveConfigFile(configFile)
+		if err != nil {
+			return err
+		}
+		installSpec, err := loadInstallSpec(cfgFile)
+		if err != nil {
+			return err
+		}
+		installSpec.SetDefaults()
+		ctx = specContext(ctx, installSpec)
+
+		var tag string
+		if !releaseIndependentFormats[exportFormat] {
+			version := spec.StringValue(installSpec.DefaultVersion)
+			if len(args) > 0 {
+				version = args[0]
+			}
+			tag, err = resolveVersion(ctx, installSpec, version)
+			if err != nil {
+				return fmt.Errorf("failed to resolve version: %w", err)
+			}
+		}
+
+		out, err := export(ctx, installSpec, tag)
+		if err != nil {
+			return fmt.Errorf("failed to export %s as %s: %w", cfgFile, exportFormat, err)
+		}
+
+		var w io.Writer = os.Stdout
+		if exportOutput != "" && exportOutput != "-" {
+			f, err := os.Create(exportOutput)
+			if err != nil {
+				return fmt.Errorf("failed to create output file: %w", err)
+			}
+			defer f.Close()
+			w = f
+		}
+		_, err = w.Write(out)
+		return err
+	},
+}
+
+func init() {
+	ExportCommand.Flags().StringVar(&exportFormat, "format", "", "Manifest format ("+strings.Join(exportFormats(), ", ")+")")
+	ExportCommand.Flags().StringVarP(&exportOutput, "output", "o", "", "Write the manifest to a file (default: stdout)")
+	_ = ExportCommand.MarkFlagRequired("format")
+}
+
+// exportHomebrewFormula renders the Homebrew formula of a release
+func exportHomebrewFormula(ctx context.Context, installSpec *spec.InstallSpec, tag string) ([]byte, error) {
+	formula, err := homebrew.New(installSpec, tag, releaseChecksumFunc(ctx, installSpec, tag))
+	if err != nil {
+		return nil, err
+	}
+	return formula.Render()
+}
+
+// exportHomebrewCask renders the Homebrew cask of a release
+func exportHomebrewCask(ctx context.Context, installSpec *spec.InstallSpec, tag string) ([]byte, error) {
+	formula, err := homebrew.New(installSpec, tag, releaseChecksumFunc(ctx, installSpec, tag))
+	if err != nil {
+		return nil, err
+	}
+	return formula.RenderCask()
+}
+
+// exportScoopManifest renders the Scoop manifest of a release
+func exportScoopManifest(ctx context.Context, installSpec *spec.InstallSpec, tag string) ([]byte, error) {
+	manifest, err := scoop.New(installSpec, tag, releaseChecksumFunc(ctx, installSpec, tag))
+	if err != nil {
+		return nil, err
+	}
+	return manifest.Render()
+}
+
+// exportAquaRegistry renders the aqua registry entry of a spec
+func exportAquaRegistry(ctx context.Context, installSpec *spec.InstallSpec, tag string) ([]byte, error) {
+	registry, err := aqua.New(installSpec)
+	if err != nil {
+		return nil, err
+	}
+	return registry.Render()
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportCommand(t *testing.T) {
+	dir := t.TempDir()
+	cfg := filepath.Join(dir, "tool.yml")
+	writeTestFile(t, cfg, `repo: owner/tool
+default_version: v1.0.0
+asset:
+  template: ${NAME}_${OS}_${ARCH}.tar.gz
+checksums:
+  embedded_checksums:
+    v1.0.0:
+      - filename: tool_darwin_arm64.tar.gz
+        hash: aaaa
+      - filename: tool_windows_amd64.tar.gz
+        hash: bbbb
+supported_platforms:
+  - os: darwin
+    arch: arm64
+  - os: windows
+    arch: amd64
+`, 0644)
+
+	oldConfig, oldFormat, oldOutput := configFile, exportFormat, exportOutput
+	defer func() { configFile, exportFormat, exportOutput = oldConfig, oldFormat, oldOutput }()
+	configFile = cfg
+	ExportCommand.SetContext(context.Background())
+
+	for format, want := range map[string][]string{
+		"aqua":          {"repo_owner: owner", "asset: tool_{{.OS}}_{{.Arch}}.tar.gz", "format: tar.gz", "- windows/amd64"},
+		"homebrew":      {"class Tool < Formula", `sha256 "aaaa"`},
+		"homebrew-cask": {`cask "tool" do`, `sha256 "aaaa"`},
+		"scoop":         {`"64bit": {`, `"hash": "bbbb"`, `"bin": "tool.exe"`},
+	} {
+		exportFormat, exportOutput = format, filepath.Join(dir, format+".out")
+		if err := ExportCommand.RunE(ExportCommand, nil); err != nil {
+			t.Fatalf("export --format %s error = %v", format, err)
+		}
+		got, err := os.ReadFile(exportOutput)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, w := range want {
+			if !strings.Contains(string(got), w) {
+				t.Errorf("export --format %s wrote:\n%s\nwant %s", format, got, w)
+			}
+		}
+	}
+
+	exportFormat = "rpm"
+	if err := ExportCommand.RunE(ExportCommand, nil); err == nil || !strings.Contains(err.Error(), "aqua, homebrew, homebrew-cask, scoop") {
+		t.Errorf("export --format rpm error = %v, want the supported formats", err)
+	}
+}
@@ -0,0 +1,300 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/cache"
+	"github.com/binary-install/binstaller/pkg/lockfile"
+	"github.com/binary-install/binstaller/pkg/receipt"
+	"github.com/spf13/cobra"
+)
+
+// gcResponseMaxAge is how long cached HTTP responses are kept without --older-than
+const gcResponseMaxAge = 30 * 24 * time.Hour
+
+var (
+	// Flags for gc command
+	gcDryRun    bool
+	gcOlderThan string
+	gcMaxSize   string
+	gcKeep      int
+	gcLockFile  string
+)
+
+// GCCommand represents the gc command
+var GCCommand = &cobra.Command{
+	Use:   "gc",
+	Short: "Remove cached assets, binst exec versions and receipts that are no longer used",
+	Long: `Reclaims disk space used by binstaller:
+
+- Cached release assets of versions that no receipt or lockfile references
+- Versions installed by 'binst exec' other than the newest ones (see --keep)
+  and those pinned by the lockfile
+- Cached HTTP responses older than 30 days (or --older-than)
+- Receipts of tools whose binaries were deleted
+
+The receipts of the user, of shared locations and of the project in the working
+directory are considered. --older-than only removes cache entries not written
+for the given time, and --max-size removes the oldest entries only until the
+cache fits in the given size. Use --dry-run to see what would be removed.`,
+	Example: `  # Show what would be removed
+  binst gc --dry-run
+
+  # Remove unused cache entries older than two weeks
+  binst gc --older-than 14d
+
+  # Shrink the cache to at most 500MB
+  binst gc --max-size 500MB`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		policy := gcPolicy{keep: gcKeep, now: time.Now()}
+		var err error
+		if gcOlderThan != "" {
+			if policy.olderThan, err = parseAge(gcOlderThan); err != nil {
+				return fmt.Errorf("invalid --older-than: %w", err)
+			}
+		}
+		if gcMaxSize != "" {
+			if policy.maxSize, err = parseSize(gcMaxSize); err != nil {
+				return fmt.Errorf("invalid --max-size: %w", err)
+			}
+		}
+		if gcKeep < 1 {
+			return fmt.Errorf("--keep must be at least 1")
+		}
+
+		store, err := cache.New()
+		if err != nil {
+			return err
+		}
+		lock, err := lockfile.ReadOptional(gcLockFile)
+		if err != nil {
+			return err
+		}
+		stores, err := gcReceiptStores()
+		if err != nil {
+			return err
+		}
+		return collectGarbage(cmd.OutOrStdout(), store, stores, lock, policy, gcDryRun)
+	},
+}
+
+// gcPolicy selects the cache entries gc removes
+type gcPolicy struct {
+	// olderThan keeps entries written more recently, when set
+	olderThan time.Duration
+	// maxSize stops removing entries once the cache fits, when set
+	maxSize int64
+	// keep is the number of binst exec versions kept per repository
+	keep int
+	now  time.Time
+}
+
+// gcItem is something gc removes
+type gcItem struct {
+	kind    string
+	name    string
+	size    int64
+	modTime time.Time
+	remove  func() error
+}
+
+// gcReceiptStores returns the receipt stores whose receipts gc considers: the
+// user's, the shared one and the one of the project in the working directory
+func gcReceiptStores() ([]*receipt.Store, error) {
+	user, err := receipt.New()
+	if err != nil {
+		return nil, err
+	}
+	stores := []*receipt.Store{user}
+	if dir := receipt.SystemDir(); dir != user.Dir {
+		stores = append(stores, &receipt.Store{Dir: dir})
+	}
+	binDir, err := projectBinDir()
+	if err != nil {
+		return nil, err
+	}
+	if project := receipt.ProjectStore(binDir); project != nil {
+		stores = append(stores, project)
+	}
+	return stores, nil
+}
+
+// collectGarbage removes the receipts of uninstalled tools and the cache
+// entries policy selects among those no receipt or lock references, and
+// prints what was removed with the reclaimed space to w. With dryRun nothing
+// is removed.
+func collectGarbage(w io.Writer, store *cache.Store, stores []*receipt.Store, lock *lockfile.Lock, policy gcPolicy, dryRun bool) error {
+	// referenced holds repo@tag of the releases in use
+	referenced := make(map[string]bool)
+	if lock != nil {
+		for _, tool := range lock.Tools {
+			referenced[tool.Repo+"@"+tool.Tag] = true
+		}
+	}
+
+	var receipts []gcItem
+	for _, s := range stores {
+		list, err := s.List()
+		if err != nil {
+			log.Warnf("Failed to read the receipts in %s: %v", s.Dir, err)
+			continue
+		}
+		for _, r := range list {
+			if r.Installed() {
+				referenced[r.Repo+"@"+r.Tag] = true
+				continue
+			}
+			name := r.Name
+			receipts = append(receipts, gcItem{
+				kind:    "receipt",
+				name:    fmt.Sprintf("%s (%s)", name, r.BinDir),
+				modTime: r.InstalledAt,
+				remove:  func() error { return s.Remove(name) },
+			})
+		}
+	}
+
+	entries, err := store.Entries()
+	if err != nil {
+		return err
+	}
+	// The newest binst exec versions of each repository are kept, with their assets
+	var tools []cache.Entry
+	for _, e := range entries {
+		if e.Kind == cache.KindTools {
+			tools = append(tools, e)
+		}
+	}
+	sort.SliceStable(tools, func(i, j int) bool { return tools[i].ModTime.After(tools[j].ModTime) })
+	kept := make(map[string]int)
+	for _, e := range tools {
+		if kept[e.Repo] < policy.keep {
+			kept[e.Repo]++
+			referenced[e.Repo+"@"+e.Tag] = true
+		}
+	}
+
+	var candidates []gcItem
+	for _, e := range entries {
+		maxAge := policy.olderThan
+		switch {
+		case e.Kind == cache.KindResponse:
+			if maxAge == 0 {
+				maxAge = gcResponseMaxAge
+			}
+		case referenced[e.Repo+"@"+e.Tag]:
+			continue
+		}
+		if maxAge > 0 && policy.now.Sub(e.ModTime) < maxAge {
+			continue
+		}
+		name := e.Repo + "@" + e.Tag
+		if e.Kind == cache.KindResponse {
+			name = filepath.Base(e.Path)
+		}
+		candidates = append(candidates, gcItem{
+			kind:    e.Kind,
+			name:    name,
+			size:    e.Size,
+			modTime: e.ModTime,
+			remove:  func() error { return store.Remove(e) },
+		})
+	}
+
+	if policy.maxSize > 0 {
+		total, err := store.Size()
+		if err != nil {
+			return fmt.Errorf("failed to measure the cache: %w", err)
+		}
+		sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].modTime.Before(candidates[j].modTime) })
+		n := 0
+		for ; n < len(candidates) && total > policy.maxSize; n++ {
+			total -= candidates[n].size
+		}
+		candidates = candidates[:n]
+	}
+
+	items := append(receipts, candidates...)
+	var removed []gcItem
+	var reclaimed int64
+	for _, item := range items {
+		if !dryRun {
+			if err := item.remove(); err != nil {
+				log.Warnf("Failed to remove %s %s: %v", item.kind, item.name, err)
+				continue
+			}
+		}
+		removed = append(removed, item)
+		reclaimed += item.size
+	}
+
+	if len(removed) == 0 {
+		fmt.Fprintln(w, "Nothing to remove")
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tENTRY\tSIZE")
+	for _, item := range removed {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", item.kind, item.name, formatSize(item.size))
+	}
+	tw.Flush()
+	verb := "Reclaimed"
+	if dryRun {
+		verb = "Would reclaim"
+	}
+	fmt.Fprintf(w, "\n%s %s from %d entries\n", verb, formatSize(reclaimed), len(removed))
+	return nil
+}
+
+// parseAge parses a duration such as 36h, or a number of days such as 30d
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.ParseFloat(days, 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid number of days %q", s)
+		}
+		return time.Duration(n * float64(24*time.Hour)), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d, nil
+}
+
+// parseSize parses a size such as 500MB or 2GiB; units are powers of 1024
+// like those formatSize prints
+func parseSize(s string) (int64, error) {
+	number := strings.TrimRight(strings.ToUpper(strings.TrimSpace(s)), "BI")
+	multiplier := int64(1)
+	if i := len(number) - 1; i >= 0 {
+		if exp := strings.IndexByte("KMGT", number[i]); exp >= 0 {
+			number = number[:i]
+			for ; exp >= 0; exp-- {
+				multiplier *= 1024
+			}
+		}
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+func init() {
+	GCCommand.Flags().BoolVar(&gcDryRun, "dry-run", false, "Print what would be removed without removing anything")
+	GCCommand.Flags().StringVar(&gcOlderThan, "older-than", "", "Only remove cache entries not written for this long (e.g. 14d or 36h)")
+	GCCommand.Flags().StringVar(&gcMaxSize, "max-size", "", "Remove the oldest unused cache entries until the cache fits in this size (e.g. 500MB)")
+	GCCommand.Flags().IntVar(&gcKeep, "keep", 1, "Number of binst exec versions to keep per tool")
+	GCCommand.Flags().StringVar(&gcLockFile, "lockfile", lockfile.DefaultPath, "Lockfile whose pinned versions are kept")
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/binary-install/binstaller/pkg/cache"
+	"github.com/binary-install/binstaller/pkg/lockfile"
+	"github.com/binary-install/binstaller/pkg/receipt"
+)
+
+// setupGCFixture fills a cache with the assets and binst exec versions of a
+// tool and receipts of an installed and an uninstalled tool
+func setupGCFixture(t *testing.T) (*cache.Store, *receipt.Store) {
+	t.Helper()
+	store := &cache.Store{Root: t.TempDir()}
+	old := time.Now().Add(-60 * 24 * time.Hour)
+	for i, tag := range []string{"v1.0.0", "v1.1.0", "v1.2.0", "v2.0.0"} {
+		path := store.Path("owner/tool", tag, "tool.tar.gz")
+		writeTestFile(t, path, strings.Repeat("x", 1024), 0644)
+		mtime := old.Add(time.Duration(i) * 24 * time.Hour)
+		os.Chtimes(path, mtime, mtime)
+		os.Chtimes(filepath.Dir(path), mtime, mtime)
+	}
+	for i, tag := range []string{"v1.0.0", "v1.1.0"} {
+		dir, err := store.ToolDir("owner/tool", tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeTestFile(t, filepath.Join(dir, "tool"), "binary", 0755)
+		mtime := old.Add(time.Duration(i) * time.Hour)
+		os.Chtimes(filepath.Join(dir, "tool"), mtime, mtime)
+		os.Chtimes(dir, mtime, mtime)
+	}
+	writeTestFile(t, filepath.Join(store.Root, cache.ResponsesDir, "old.json"), "{}", 0644)
+	os.Chtimes(filepath.Join(store.Root, cache.ResponsesDir, "old.json"), old, old)
+	writeTestFile(t, filepath.Join(store.Root, cache.ResponsesDir, "new.json"), "{}", 0644)
+
+	binDir := t.TempDir()
+	writeTestFile(t, filepath.Join(binDir, "tool"), "binary", 0755)
+	receipts := &receipt.Store{Dir: filepath.Join(t.TempDir(), "receipts")}
+	for _, r := range []*receipt.Receipt{
+		{Name: "tool", Repo: "owner/tool", Tag: "v1.2.0", BinDir: binDir, Binaries: []string{"tool"}},
+		{Name: "gone", Repo: "owner/gone", Tag: "v1.0.0", BinDir: binDir, Binaries: []string{"gone"}},
+	} {
+		if err := receipts.Write(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return store, receipts
+}
+
+func TestCollectGarbage(t *testing.T) {
+	store, receipts := setupGCFixture(t)
+	lock := &lockfile.Lock{Tools: []lockfile.Tool{{Name: "tool", Repo: "owner/tool", Tag: "v2.0.0"}}}
+	policy := gcPolicy{keep: 1, now: time.Now()}
+
+	var out bytes.Buffer
+	if err := collectGarbage(&out, store, []*receipt.Store{receipts}, lock, policy, true); err != nil {
+		t.Fatalf("collectGarbage() dry run error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Would reclaim") {
+		t.Errorf("dry run output lacks the space to reclaim:\n%s", out.String())
+	}
+	if before, _ := store.Entries(); len(before) != 8 {
+		t.Fatalf("dry run removed cache entries: %d left, want 8", len(before))
+	}
+
+	out.Reset()
+	if err := collectGarbage(&out, store, []*receipt.Store{receipts}, lock, policy, false); err != nil {
+		t.Fatalf("collectGarbage() error = %v", err)
+	}
+	entries, err := store.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kept []string
+	for _, e := range entries {
+		name := e.Repo + "@" + e.Tag
+		if e.Kind == cache.KindResponse {
+			name = filepath.Base(e.Path)
+		}
+		kept = append(kept, e.Kind+" "+name)
+	}
+	// The installed v1.2.0, the locked v2.0.0 and the newest binst exec version
+	// v1.1.0 with its assets are kept, like recent HTTP responses
+	want := []string{
+		"assets owner/tool@v1.1.0",
+		"assets owner/tool@v1.2.0",
+		"assets owner/tool@v2.0.0",
+		"tools owner/tool@v1.1.0",
+		"response new.json",
+	}
+	if strings.Join(kept, "\n") != strings.Join(want, "\n") {
+		t.Errorf("kept entries:\n%s\nwant:\n%s", strings.Join(kept, "\n"), strings.Join(want, "\n"))
+	}
+	if r, _ := receipts.Read("gone"); r != nil {
+		t.Error("the receipt of the uninstalled tool was kept")
+	}
+	if r, _ := receipts.Read("tool"); r == nil {
+		t.Error("the receipt of the installed tool was removed")
+	}
+	if !strings.Contains(out.String(), "Reclaimed") {
+		t.Errorf("output lacks the reclaimed space:\n%s", out.String())
+	}
+}
+
+func TestCollectGarbagePolicies(t *testing.T) {
+	t.Run("older than", func(t *testing.T) {
+		store, receipts := setupGCFixture(t)
+		// Only v1.0.0 of the unused assets and binaries is older than 59 days
+		policy := gcPolicy{keep: 1, olderThan: 59 * 24 * time.Hour, now: time.Now()}
+		if err := collectGarbage(&bytes.Buffer{}, store, []*receipt.Store{receipts}, nil, policy, false); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := store.Lookup("owner/tool", "v1.0.0", "tool.tar.gz"); ok {
+			t.Error("the old assets of v1.0.0 were kept")
+		}
+		if _, ok := store.Lookup("owner/tool", "v2.0.0", "tool.tar.gz"); !ok {
+			t.Error("the recent assets of v2.0.0 were removed")
+		}
+	})
+
+	t.Run("max size", func(t *testing.T) {
+		store, receipts := setupGCFixture(t)
+		total, err := store.Size()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Removing the oldest unused entry is enough
+		policy := gcPolicy{keep: 1, maxSize: total - 100, now: time.Now()}
+		if err := collectGarbage(&bytes.Buffer{}, store, []*receipt.Store{receipts}, nil, policy, false); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := store.Lookup("owner/tool", "v1.0.0", "tool.tar.gz"); ok {
+			t.Error("the oldest assets were kept")
+		}
+		if _, ok := store.Lookup("owner/tool", "v2.0.0", "tool.tar.gz"); !ok {
+			t.Error("more entries than needed were removed")
+		}
+	})
+}
+
+func TestParseAgeAndSize(t *testing.T) {
+	for in, want := range map[string]time.Duration{"14d": 14 * 24 * time.Hour, "36h": 36 * time.Hour, "1.5d": 36 * time.Hour} {
+		if got, err := parseAge(in); err != nil || got != want {
+			t.Errorf("parseAge(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+	if _, err := parseAge("soon"); err == nil {
+		t.Error("parseAge() accepted an invalid age")
+	}
+	for in, want := range map[string]int64{"100": 100, "2KB": 2048, "500MB": 500 << 20, "1.5GiB": 3 << 29, "1g": 1 << 30} {
+		if got, err := parseSize(in); err != nil || got != want {
+			t.Errorf("parseSize(%q) = %d, %v, want %d", in, got, err, want)
+		}
+	}
+	if _, err := parseSize("big"); err == nil {
+		t.Error("parseSize() accepted an invalid size")
+	}
+}
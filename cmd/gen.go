@@ -1,13 +1,21 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/apex/log"
 	"github.com/binary-install/binstaller/internal/shell" // Placeholder for script generator
+	"github.com/binary-install/binstaller/internal/testutil"
+	"github.com/binary-install/binstaller/pkg/metrics"
+	"github.com/binary-install/binstaller/pkg/resolver"
 	"github.com/binary-install/binstaller/pkg/spec"
 	"github.com/spf13/cobra"
 )
@@ -17,10 +25,11 @@ func validateScriptType(scriptType string) error {
 	if scriptType == "" || scriptType == "installer" {
 		return nil
 	}
-	if scriptType == "runner" {
+	switch scriptType {
+	case "runner", "chocolatey", "snapcraft", "flatpak", "azure-pipelines", "gitlab-ci":
 		return nil
 	}
-	return fmt.Errorf("invalid script type %q: must be 'installer' or 'runner'", scriptType)
+	return fmt.Errorf("invalid script type %q: must be 'installer', 'runner', 'chocolatey', 'snapcraft', 'flatpak', 'azure-pipelines', or 'gitlab-ci'", scriptType)
 }
 
 var (
@@ -29,6 +38,24 @@ var (
 	genTargetVersion string
 	genScriptType    string
 	genBinaryName    string
+	genToolName      string
+	genDisable       []string
+	genEnable        []string
+	genChannels      []string
+	// Flags for two-stage installers that can bootstrap binst at runtime
+	genBootstrapVersion string
+	genBootstrapConfig  string
+	// Flags for refreshing the golden installers of a spec corpus
+	genUpdateGolden bool
+	genGoldenDir    string
+	// Flags for hash-pinned one-liners
+	genOneLiner  bool
+	genScriptURL string
+	// Flags overriding the script_header of the spec
+	genHeaderCopyright  string
+	genHeaderLicense    string
+	genHeaderSupportURL string
+	genHeaderText       string
 	// Input config file is handled by the global --config flag
 )
 
@@ -140,7 +167,21 @@ var GenCommand = &cobra.Command{
 	Use:   "gen",
 	Short: "Generate an installer script from an InstallSpec config file",
 	Long: `Reads an InstallSpec configuration file (e.g., .binstaller.yml) and
-generates a POSIX-compatible shell installer script.`,
+generates a POSIX-compatible shell installer script.
+
+Scripts only contain the features the spec uses: checksum verification is left
+out for specs without checksums, and zstd extraction and OS version detection
+are only included when asset rules need them. Optional flags can be left out
+with --disable.
+
+Installers keep accepting the v0 long options (--bindir DIR, --debug, --dry-run,
+...) of older scripts, so existing install docs keep working; they print a
+deprecation warning. --disable compat removes them. Installers ignore the BINDIR
+variable of v0 scripts, a common Makefile variable, unless generated with
+--enable bindir-env.
+
+A config declaring several tools (tools:) generates the script of the tool
+selected with --name TOOL or --config FILE#TOOL.`,
 	Example: `  # Generate installer script using default config
   binst gen
 
@@ -153,9 +194,11 @@ generates a POSIX-compatible shell installer script.`,
   # Generate runner for specific binary (when multiple binaries exist)
   binst gen --type=runner --binary=mytool-helper -o run-helper.sh
 
-  # Run binary directly using runner script (all arguments pass to binary)
+  # Run binary directly using runner script (all arguments pass to binary,
+  # except leading --os/--arch options forcing the platform)
   ./run.sh --help
   ./run.sh --version
+  ./run.sh --arch amd64 --version
 
   # Control runner script with environment variables
   BINSTALLER_TARGET_TAG=v1.2.3 ./run.sh --help  # Use specific version
@@ -170,13 +213,41 @@ generates a POSIX-compatible shell installer script.`,
   # Generate installer for a specific version only
   binst gen --target-version v1.2.3 -o install-v1.2.3.sh
 
+  # Generate one pinned installer per release in a semver range (or comma list)
+  # into dist/install-TAG.sh, each embedding only its own checksums
+  binst gen --target-version '>=1.2, <2' -o dist/
+  binst gen --target-version v1.2.3,v1.3.0 -o dist/
+
+  # Generate channel alias scripts: dist/install-stable.sh pinned to the latest
+  # stable release and dist/install-beta.sh to the newest pre-release, with the
+  # tags and refresh times recorded in dist/channels.json (rerun to refresh)
+  binst gen --channels stable,beta -o dist/
+
+  # Generate the installer of one tool of a multi-tool config (tools:)
+  binst gen --name gh -o install-gh.sh
+
   # Generate runner for specific version
   binst gen --type=runner --target-version v1.2.3 -o run-v1.2.3.sh
 
+  # Generate a Chocolatey package (.nuspec and tools/chocolateyInstall.ps1)
+  binst gen --type=chocolatey --target-version v1.2.3 -o choco/
+
+  # Scaffold Linux store manifests pinned to the release assets' sha256
+  binst gen --type=snapcraft -o snap/snapcraft.yaml
+  binst gen --type=flatpak -o io.github.owner.mytool.yml
+
+  # Generate CI templates installing the pinned release on Linux agents
+  binst gen --type=azure-pipelines -o ci/mytool.azure-pipelines.yml
+  binst gen --type=gitlab-ci -o ci/mytool.gitlab-ci.yml
+
   # Typical workflow with init and gen
   binst init --source=github --repo=owner/repo
   binst gen -o install.sh
 
+  # Write install.sh and print a README one-liner that downloads it from where
+  # it is published and verifies its sha256 before running it
+  binst gen -o install.sh --one-liner --script-url https://example.com/install.sh
+
   # Generate and execute installer script directly
   binst gen | sh
 
@@ -193,7 +264,22 @@ generates a POSIX-compatible shell installer script.`,
   binst gen --type=runner | BINSTALLER_TARGET_TAG=v1.2.3 sh
 
   # Test installer with dry run mode
-  binst gen | sh -s -- -n`,
+  binst gen | sh -s -- -n
+
+  # Generate a smaller installer without the dry-run (-n) and quiet (-q) flags
+  binst gen --disable dry-run,quiet -o install.sh
+
+  # Generate a two-stage installer that can hand off to a pinned binst
+  # (hashes come from the embedded checksums of binst's own config)
+  binst gen --bootstrap-version v0.10.0 --bootstrap-config binst.binstaller.yml -o install.sh
+  BINSTALLER_BOOTSTRAP=1 ./install.sh
+
+  # Add a legal header to the generated script
+  binst gen --header-copyright "Copyright 2025 Example Corp." --header-license Apache-2.0 -o install.sh
+
+  # After changing templates, regenerate the golden installers of a spec corpus
+  # (NAME.binstaller.yml -> NAME.install.sh) and review the printed diffs
+  binst gen --update-golden --golden-dir testdata`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		log.Info("Running gen command...")
 
@@ -205,6 +291,12 @@ generates a POSIX-compatible shell installer script.`,
 		if genScriptType == "" {
 			genScriptType = "installer"
 		}
+		if genUpdateGolden {
+			if genScriptType != "installer" {
+				return fmt.Errorf("--update-golden only supports installer scripts")
+			}
+			return updateGoldenInstallers(genGoldenDir, os.Stdout)
+		}
 
 		// Resolve config file path
 		cfgFile, err := resolveConfigFile(configFile)
@@ -216,27 +308,91 @@ generates a POSIX-compatible shell installer script.`,
 			log.Infof("Using default config file: %s", cfgFile)
 		}
 		log.Debugf("Using config file: %s", cfgFile)
+		if genToolName != "" {
+			if _, tool := splitToolRef(cfgFile); tool != "" {
+				return fmt.Errorf("--name cannot be combined with --config FILE%sTOOL", toolRefSeparator)
+			}
+			cfgFile = toolRef(cfgFile, genToolName)
+		}
 
 		// Load and parse InstallSpec
 		installSpec, err := loadInstallSpec(cfgFile)
 		if err != nil {
 			return err
 		}
+		applyScriptHeaderFlags(installSpec)
+		warnWeakAlgorithm(installSpec)
+		warnPlatformDetection(installSpec)
+		ctx := specContext(cmd.Context(), installSpec)
+
+		pinnedSet := resolver.IsVersionSet(genTargetVersion)
+		if pinnedSet && genScriptType != "installer" && genScriptType != "runner" {
+			return fmt.Errorf("--target-version lists and ranges are only supported for installer and runner scripts")
+		}
+		if genOneLiner {
+			if genScriptType != "installer" && genScriptType != "runner" {
+				return fmt.Errorf("--one-liner is only supported for installer and runner scripts")
+			}
+			if pinnedSet || len(genChannels) > 0 {
+				return fmt.Errorf("--one-liner needs a single script and cannot be used with --channels or --target-version lists and ranges")
+			}
+			if err := validateScriptURL(genScriptURL); err != nil {
+				return err
+			}
+		}
+		if len(genChannels) > 0 {
+			if genTargetVersion != "" {
+				return fmt.Errorf("--channels and --target-version cannot be used together")
+			}
+			if genScriptType != "installer" && genScriptType != "runner" {
+				return fmt.Errorf("--channels is only supported for installer and runner scripts")
+			}
+		}
+		if genScriptType == "chocolatey" {
+			return genChocolatey(ctx, installSpec, genTargetVersion, genOutputFile)
+		}
+		switch genScriptType {
+		case "snapcraft", "flatpak", "azure-pipelines", "gitlab-ci":
+			return genScaffold(ctx, installSpec, genScriptType, genTargetVersion, genOutputFile)
+		}
 
 		// Handle binary selection for runner scripts
 		if err := handleRunnerBinarySelection(installSpec, genScriptType, genBinaryName); err != nil {
 			return err
 		}
 
+		bootstrap, err := loadBootstrap(genBootstrapVersion, genBootstrapConfig)
+		if err != nil {
+			return err
+		}
+		features, err := shell.DisableFeatures(genDisable)
+		if err != nil {
+			return err
+		}
+		if features, err = shell.EnableFeatures(features, genEnable); err != nil {
+			return err
+		}
+
+		if len(genChannels) > 0 {
+			return genChannelScripts(ctx, installSpec, genChannels, genScriptType, genOutputFile, shell.Options{Bootstrap: bootstrap, Features: &features}, time.Now())
+		}
+		if pinnedSet {
+			return genPinnedScripts(ctx, installSpec, genTargetVersion, genScriptType, genOutputFile, bootstrap, features)
+		}
+
 		// Generate the script
 		log.Infof("Generating %s script...", genScriptType)
-		scriptBytes, err := shell.GenerateWithScriptType(installSpec, genTargetVersion, genScriptType)
+		scriptBytes, err := generateScript(ctx, installSpec, genTargetVersion, genScriptType, shell.Options{Bootstrap: bootstrap, Features: &features})
 		if err != nil {
 			log.WithError(err).Errorf("Failed to generate %s script", genScriptType)
 			return fmt.Errorf("failed to generate %s script: %w", genScriptType, err)
 		}
 		log.Debugf("%s script generated successfully", genScriptType)
 
+		if genOneLiner {
+			return writeOneLiner(os.Stdout, scriptBytes, genScriptURL, genOutputFile, genScriptType)
+		}
+
 		// Write the output
 		return writeScript(scriptBytes, genOutputFile, genScriptType)
 	},
@@ -246,7 +402,232 @@ func init() {
 	// Flags specific to gen command
 	// Input config file is handled by the global --config flag
 	GenCommand.Flags().StringVarP(&genOutputFile, "output", "o", "-", "Output path for the generated script (use '-' for stdout)")
-	GenCommand.Flags().StringVar(&genTargetVersion, "target-version", "", "Generate script for specific version only (disables runtime version selection)")
-	GenCommand.Flags().StringVar(&genScriptType, "type", "installer", "Type of script to generate (installer, runner)")
+	GenCommand.Flags().StringVar(&genTargetVersion, "target-version", "", "Generate script for specific version only (disables runtime version selection); a comma list or semver range generates one script per version")
+	GenCommand.Flags().StringVar(&genScriptType, "type", "installer", "Type of script to generate (installer, runner, chocolatey, snapcraft, flatpak, azure-pipelines, gitlab-ci)")
 	GenCommand.Flags().StringVar(&genBinaryName, "binary", "", "For runner scripts with multiple binaries: specify which binary to run")
+	GenCommand.Flags().StringVar(&genToolName, "name", "", "Tool of a multi-tool config (tools:) to generate the script for")
+	GenCommand.Flags().StringSliceVar(&genChannels, "channels", nil, "Generate channel alias scripts pinned to the current release of each channel ("+strings.Join(resolver.Channels, ", ")+") into the --output directory")
+	GenCommand.Flags().StringSliceVar(&genDisable, "disable", nil, "Leave optional features out of the script ("+strings.Join(shell.FeatureNames, ", ")+")")
+	GenCommand.Flags().StringSliceVar(&genEnable, "enable", nil, "Add opt-in features to the script ("+strings.Join(shell.OptInFeatureNames, ", ")+")")
+	GenCommand.Flags().StringVar(&genBootstrapVersion, "bootstrap-version", "", "Pinned binst version the installer can bootstrap when BINSTALLER_BOOTSTRAP=1 is set")
+	GenCommand.Flags().StringVar(&genBootstrapConfig, "bootstrap-config", "", "InstallSpec for binst with embedded checksums for --bootstrap-version")
+	GenCommand.Flags().BoolVar(&genUpdateGolden, "update-golden", false, "Regenerate the golden installers of every spec in --golden-dir and print the diffs")
+	GenCommand.Flags().StringVar(&genGoldenDir, "golden-dir", "testdata", "Directory of NAME.binstaller.yml specs and NAME.install.sh golden installers")
+	GenCommand.Flags().BoolVar(&genOneLiner, "one-liner", false, "Print a command that downloads the script from --script-url, verifies its sha256 and only then runs it (the script is written to --output when it is a file)")
+	GenCommand.Flags().StringVar(&genHeaderCopyright, "header-copyright", "", "Copyright line of the script header (overrides script_header.copyright)")
+	GenCommand.Flags().StringVar(&genHeaderLicense, "header-license", "", "SPDX license identifier of the script header (overrides script_header.license)")
+	GenCommand.Flags().StringVar(&genHeaderSupportURL, "header-support-url", "", "Support URL of the script header (overrides script_header.support_url)")
+	GenCommand.Flags().StringVar(&genHeaderText, "header-text", "", "Free-form text of the script header (overrides script_header.text)")
+	GenCommand.Flags().StringVar(&genScriptURL, "script-url", "", "URL the generated script is published at, for --one-liner")
+}
+
+// updateGoldenInstallers regenerates NAME.install.sh for every NAME.binstaller.yml
+// in dir and writes the unified diff of each changed installer to w
+func updateGoldenInstallers(dir string, w io.Writer) error {
+	snapshots, err := testutil.Corpus(dir)
+	if err != nil {
+		return err
+	}
+	changed := 0
+	for _, s := range snapshots {
+		installSpec, err := loadInstallSpec(s.SpecPath)
+		if err != nil {
+			return err
+		}
+		script, err := generateScript(context.Background(), installSpec, "", "installer", shell.Options{})
+		if err != nil {
+			return fmt.Errorf("failed to generate installer for %s: %w", s.SpecPath, err)
+		}
+		diff, err := testutil.UpdateGolden(s.GoldenPath, script, 0755)
+		if err != nil {
+			return fmt.Errorf("failed to update %s: %w", s.GoldenPath, err)
+		}
+		if diff != "" {
+			changed++
+			fmt.Fprint(w, diff)
+		}
+	}
+	log.Infof("%d of %d golden installer(s) changed", changed, len(snapshots))
+	return nil
+}
+
+// applyScriptHeaderFlags overrides the script_header fields of installSpec
+// with the --header-* flags that are set
+func applyScriptHeaderFlags(installSpec *spec.InstallSpec) {
+	if genHeaderCopyright == "" && genHeaderLicense == "" && genHeaderSupportURL == "" && genHeaderText == "" {
+		return
+	}
+	if installSpec.ScriptHeader == nil {
+		installSpec.ScriptHeader = &spec.ScriptHeader{}
+	}
+	header := installSpec.ScriptHeader
+	if genHeaderCopyright != "" {
+		header.Copyright = spec.StringPtr(genHeaderCopyright)
+	}
+	if genHeaderLicense != "" {
+		header.License = spec.StringPtr(genHeaderLicense)
+	}
+	if genHeaderSupportURL != "" {
+		header.SupportURL = spec.StringPtr(genHeaderSupportURL)
+	}
+	if genHeaderText != "" {
+		header.Text = spec.StringPtr(genHeaderText)
+	}
+}
+
+// generateScript generates a script and records the generation in the metrics.
+// Scripts pinned to a target version get its release metadata variables.
+func generateScript(ctx context.Context, installSpec *spec.InstallSpec, targetVersion, scriptType string, opts shell.Options) ([]byte, error) {
+	if targetVersion != "" && opts.ReleaseVars == nil {
+		releaseVars, err := fetchReleaseVars(ctx, installSpec, targetVersion)
+		if err != nil {
+			return nil, err
+		}
+		opts.ReleaseVars = releaseVars
+	}
+	start := time.Now()
+	script, err := shell.GenerateWithOptions(installSpec, targetVersion, scriptType, opts)
+	metrics.RecordOperation("generate", time.Since(start), err)
+	return script, err
+}
+
+// genPinnedScripts writes one script pinned to each version of a --target-version
+// list or range into outputDir, named install-TAG.sh or run-TAG.sh
+func genPinnedScripts(ctx context.Context, installSpec *spec.InstallSpec, versionSet, scriptType, outputDir string, bootstrap *shell.Bootstrap, features shell.Features) error {
+	if outputDir == "" || outputDir == "-" {
+		return fmt.Errorf("--output must be a directory when --target-version is a list or range")
+	}
+	installSpec.SetDefaults()
+	r := resolver.New(installSpec)
+	r.APIBaseURL = gitHubAPIURL(installSpec)
+	r.GitLabBaseURL = gitLabBaseURL
+	versions, err := r.Expand(ctx, versionSet)
+	if err != nil {
+		return err
+	}
+	log.Infof("Generating %d pinned %s script(s) for %s", len(versions), scriptType, strings.Join(versions, ", "))
+
+	prefix := "install"
+	if scriptType == "runner" {
+		prefix = "run"
+	}
+	for _, version := range versions {
+		scriptBytes, err := generateScript(ctx, installSpec, version, scriptType, shell.Options{Bootstrap: bootstrap, Features: &features})
+		if err != nil {
+			return fmt.Errorf("failed to generate %s script for %s: %w", scriptType, version, err)
+		}
+		name := fmt.Sprintf("%s-%s.sh", prefix, strings.ReplaceAll(version, "/", "_"))
+		if err := writeScript(scriptBytes, filepath.Join(outputDir, name), scriptType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// channelsFile records the tag and refresh time of each channel alias script
+const channelsFile = "channels.json"
+
+// channelEntry is the metadata of one channel alias script
+type channelEntry struct {
+	Tag         string    `json:"tag"`
+	Script      string    `json:"script"`
+	RefreshedAt time.Time `json:"refreshed_at"`
+}
+
+// genChannelScripts writes a script pinned to the current release of each
+// channel into outputDir, named install-CHANNEL.sh or run-CHANNEL.sh, and
+// records them in outputDir/channels.json. Entries of other channels in an
+// existing channels.json are kept.
+func genChannelScripts(ctx context.Context, installSpec *spec.InstallSpec, channels []string, scriptType, outputDir string, opts shell.Options, now time.Time) error {
+	if outputDir == "" || outputDir == "-" {
+		return fmt.Errorf("--output must be a directory when generating channel scripts")
+	}
+	for _, channel := range channels {
+		if !slices.Contains(resolver.Channels, channel) {
+			return fmt.Errorf("unknown channel %q: must be one of %s", channel, strings.Join(resolver.Channels, ", "))
+		}
+	}
+	installSpec.SetDefaults()
+	r := resolver.New(installSpec)
+	r.APIBaseURL = gitHubAPIURL(installSpec)
+	r.GitLabBaseURL = gitLabBaseURL
+
+	metadataPath := filepath.Join(outputDir, channelsFile)
+	entries := map[string]channelEntry{}
+	if data, err := os.ReadFile(metadataPath); err == nil {
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", metadataPath, err)
+		}
+	}
+
+	prefix := "install"
+	if scriptType == "runner" {
+		prefix = "run"
+	}
+	for _, channel := range channels {
+		tag, err := r.Channel(ctx, channel)
+		if err != nil {
+			return err
+		}
+		log.Infof("Channel %s is at %s", channel, tag)
+		opts.Channel = &shell.Channel{Name: channel, RefreshedAt: now}
+		scriptBytes, err := generateScript(ctx, installSpec, tag, scriptType, opts)
+		if err != nil {
+			return fmt.Errorf("failed to generate %s script for channel %s: %w", scriptType, channel, err)
+		}
+		name := fmt.Sprintf("%s-%s.sh", prefix, channel)
+		if err := writeScript(scriptBytes, filepath.Join(outputDir, name), scriptType); err != nil {
+			return err
+		}
+		entries[channel] = channelEntry{Tag: tag, Script: name, RefreshedAt: now.UTC()}
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(metadataPath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", metadataPath, err)
+	}
+	log.Infof("Channel metadata written to %s", metadataPath)
+	return nil
+}
+
+// warnWeakAlgorithm warns when the spec verifies assets with a weak hash algorithm,
+// which installers treat as unverified unless BINSTALLER_ALLOW_WEAK_HASH=1 is set
+func warnWeakAlgorithm(installSpec *spec.InstallSpec) {
+	algo := installSpec.Checksums.GetAlgorithm()
+	if installSpec.Checksums == nil || !algo.IsWeak() {
+		return
+	}
+	log.Warnf("checksums.algorithm %s is weak: installers treat %s-verified assets as unverified unless BINSTALLER_ALLOW_WEAK_HASH=1 is set", algo, algo)
+	log.Warnf("Consider asking %s to publish sha256 or sha512 checksums", installSpec.GetRepo())
+}
+
+// warnPlatformDetection warns about declared platforms that the platform
+// detection of generated scripts cannot reliably identify
+func warnPlatformDetection(installSpec *spec.InstallSpec) {
+	for _, warning := range shell.DetectionWarnings(installSpec) {
+		log.Warn(warning)
+	}
+}
+
+// loadBootstrap resolves the pinned binst release for a two-stage installer, or nil when disabled
+func loadBootstrap(version, cfgFile string) (*shell.Bootstrap, error) {
+	if version == "" && cfgFile == "" {
+		return nil, nil
+	}
+	if version == "" || cfgFile == "" {
+		return nil, fmt.Errorf("--bootstrap-version and --bootstrap-config must be used together")
+	}
+	binstSpec, err := loadInstallSpec(cfgFile)
+	if err != nil {
+		return nil, err
+	}
+	bootstrap, err := shell.NewBootstrap(binstSpec, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure bootstrap: %w", err)
+	}
+	log.Infof("Installer can bootstrap %s %s on %d platform(s)", bootstrap.Repo, bootstrap.Tag, len(bootstrap.Assets))
+	return bootstrap, nil
 }
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/chocolatey"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// genChocolatey writes a Chocolatey package (.nuspec and tools/chocolateyInstall.ps1) into outputDir
+func genChocolatey(ctx context.Context, installSpec *spec.InstallSpec, targetVersion, outputDir string) error {
+	if outputDir == "" || outputDir == "-" {
+		return fmt.Errorf("--type=chocolatey writes several files: specify the package directory with -o")
+	}
+	installSpec.SetDefaults()
+
+	version := targetVersion
+	if version == "" {
+		version = spec.StringValue(installSpec.DefaultVersion)
+	}
+	tag, err := resolveVersion(ctx, installSpec, version)
+	if err != nil {
+		return fmt.Errorf("failed to resolve version: %w", err)
+	}
+
+	pkg, err := chocolatey.New(installSpec, tag, releaseChecksumFunc(ctx, installSpec, tag))
+	if err != nil {
+		return fmt.Errorf("failed to generate chocolatey package: %w", err)
+	}
+	files, err := pkg.Files()
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := filepath.Join(outputDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", path, err)
+		}
+		if err := os.WriteFile(path, files[name], 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		log.Infof("Wrote %s", path)
+	}
+	log.Infof("Chocolatey package %s %s written to %s (run 'choco pack' there)", pkg.ID, pkg.Version, outputDir)
+	return nil
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/url"
+
+	"github.com/apex/log"
+)
+
+// oneLinerScript downloads the script of $1 to a temp file, checks it against
+// the sha256 $2 and only then runs it with the remaining arguments
+const oneLinerScript = `u=$1 want=$2; shift 2; f=$(mktemp) || exit 1; ` +
+	`if curl -fsSL "$u" -o "$f" && got=$( (sha256sum "$f" 2>/dev/null || shasum -a 256 "$f") | cut -d " " -f 1) && [ "$got" = "$want" ]; ` +
+	`then sh "$f" "$@"; s=$?; ` +
+	`else echo "install script verification failed: sha256 ${got:-unknown}, want $want" >&2; s=1; fi; ` +
+	`rm -f "$f"; exit $s`
+
+// oneLiner returns a command that downloads the script published at scriptURL,
+// verifies it against the sha256 of script and only then executes it.
+// Installer options can be appended to the command.
+func oneLiner(scriptURL string, script []byte) string {
+	return fmt.Sprintf("sh -c %s sh %s %x", shellQuote(oneLinerScript), shellQuote(scriptURL), sha256.Sum256(script))
+}
+
+// validateScriptURL checks that scriptURL is an absolute http(s) URL
+func validateScriptURL(scriptURL string) error {
+	if scriptURL == "" {
+		return fmt.Errorf("--one-liner requires --script-url, the URL the generated script is published at")
+	}
+	u, err := url.Parse(scriptURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("--script-url must be an http(s) URL, got %q", scriptURL)
+	}
+	if u.Scheme == "http" {
+		log.Warnf("--script-url %s is not https; the sha256 check still protects the script, but not its confidentiality", scriptURL)
+	}
+	return nil
+}
+
+// writeOneLiner writes the script to outputFile unless it is stdout, then prints
+// the hash-pinned one-liner of scriptURL to w
+func writeOneLiner(w io.Writer, scriptBytes []byte, scriptURL, outputFile, scriptType string) error {
+	if outputFile != "" && outputFile != "-" {
+		if err := writeScript(scriptBytes, outputFile, scriptType); err != nil {
+			return err
+		}
+	} else {
+		log.Infof("Publish the output of 'binst gen' without --one-liner at %s", scriptURL)
+	}
+	if _, err := fmt.Fprintln(w, oneLiner(scriptURL, scriptBytes)); err != nil {
+		return err
+	}
+	log.Info("Append installer options to the command, e.g. -b /usr/local/bin")
+	return nil
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestOneLiner(t *testing.T) {
+	if _, err := exec.LookPath("curl"); err != nil {
+		t.Skip("curl not found")
+	}
+	script := []byte("#!/bin/sh\necho \"installed $*\"\n")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/tampered.sh" {
+			w.Write([]byte("#!/bin/sh\necho tampered\n"))
+			return
+		}
+		w.Write(script)
+	}))
+	defer server.Close()
+
+	command := oneLiner(server.URL+"/install.sh", script)
+	out, err := exec.Command("sh", "-c", command+" -b '/opt/my bin'").CombinedOutput()
+	if err != nil {
+		t.Fatalf("one-liner failed: %v\n%s", err, out)
+	}
+	if got := strings.TrimSpace(string(out)); got != "installed -b /opt/my bin" {
+		t.Errorf("one-liner output = %q, want the script run with the appended options", got)
+	}
+
+	out, err = exec.Command("sh", "-c", oneLiner(server.URL+"/tampered.sh", script)).CombinedOutput()
+	if err == nil || strings.Contains(string(out), "tampered") {
+		t.Fatalf("one-liner ran a tampered script: %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "verification failed") {
+		t.Errorf("one-liner output = %q, want a verification failure", out)
+	}
+}
+
+func TestValidateScriptURL(t *testing.T) {
+	for _, tt := range []struct {
+		url     string
+		wantErr bool
+	}{
+		{url: "https://example.com/install.sh"},
+		{url: "http://example.com/install.sh"},
+		{url: "", wantErr: true},
+		{url: "install.sh", wantErr: true},
+		{url: "ftp://example.com/install.sh", wantErr: true},
+	} {
+		if err := validateScriptURL(tt.url); (err != nil) != tt.wantErr {
+			t.Errorf("validateScriptURL(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/scaffold"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// genScaffold writes a snapcraft.yaml, flatpak-builder manifest, or CI template scaffold to outputFile ('-' for stdout)
+func genScaffold(ctx context.Context, installSpec *spec.InstallSpec, kind, targetVersion, outputFile string) error {
+	installSpec.SetDefaults()
+
+	version := targetVersion
+	if version == "" {
+		version = spec.StringValue(installSpec.DefaultVersion)
+	}
+	tag, err := resolveVersion(ctx, installSpec, version)
+	if err != nil {
+		return fmt.Errorf("failed to resolve version: %w", err)
+	}
+
+	release, err := scaffold.New(installSpec, tag, releaseChecksumFunc(ctx, installSpec, tag))
+	if err != nil {
+		return fmt.Errorf("failed to generate %s manifest: %w", kind, err)
+	}
+	content, err := release.Render(kind)
+	if err != nil {
+		return err
+	}
+
+	if outputFile == "" || outputFile == "-" {
+		fmt.Print(string(content))
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory for %s: %w", outputFile, err)
+	}
+	if err := os.WriteFile(outputFile, content, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputFile, err)
+	}
+	log.Infof("%s manifest for %s %s written to %s", kind, release.Name, release.Version, outputFile)
+	return nil
+}
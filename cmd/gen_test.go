@@ -1,9 +1,19 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/binary-install/binstaller/internal/shell"
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/google/go-cmp/cmp"
 	"github.com/spf13/cobra"
 )
 
@@ -161,6 +171,31 @@ func TestGenCommandValidateScriptType(t *testing.T) {
 			scriptType: "runner",
 			wantError:  false,
 		},
+		{
+			name:       "chocolatey type is valid",
+			scriptType: "chocolatey",
+			wantError:  false,
+		},
+		{
+			name:       "snapcraft type is valid",
+			scriptType: "snapcraft",
+			wantError:  false,
+		},
+		{
+			name:       "flatpak type is valid",
+			scriptType: "flatpak",
+			wantError:  false,
+		},
+		{
+			name:       "azure-pipelines type is valid",
+			scriptType: "azure-pipelines",
+			wantError:  false,
+		},
+		{
+			name:       "gitlab-ci type is valid",
+			scriptType: "gitlab-ci",
+			wantError:  false,
+		},
 		{
 			name:       "empty type defaults to installer",
 			scriptType: "",
@@ -208,3 +243,148 @@ func TestGenCommandUsageExamples(t *testing.T) {
 		}
 	}
 }
+
+func TestUpdateGoldenInstallers(t *testing.T) {
+	dir := t.TempDir()
+	specYAML := "schema: v1\nname: tool\nrepo: owner/tool\nasset:\n  template: ${NAME}_${OS}_${ARCH}.tar.gz\n"
+	if err := os.WriteFile(filepath.Join(dir, "tool.binstaller.yml"), []byte(specYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	golden := filepath.Join(dir, "tool.install.sh")
+	if err := os.WriteFile(golden, []byte("#!/bin/sh\necho stale\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := updateGoldenInstallers(dir, &out); err != nil {
+		t.Fatalf("updateGoldenInstallers() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "-echo stale") {
+		t.Errorf("updateGoldenInstallers() diff = %q, want the stale line removed", out.String())
+	}
+	script, _ := os.ReadFile(golden)
+	if !strings.Contains(string(script), "REPO='owner/tool'") {
+		t.Errorf("golden installer was not regenerated:\n%s", script)
+	}
+
+	out.Reset()
+	if err := updateGoldenInstallers(dir, &out); err != nil {
+		t.Fatalf("updateGoldenInstallers() error = %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("updateGoldenInstallers() of up-to-date corpus printed %q", out.String())
+	}
+}
+
+func TestGenPinnedScripts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"tag_name": "v2.0.0"}, {"tag_name": "v1.1.0"}, {"tag_name": "v1.0.0"}]`))
+	}))
+	defer server.Close()
+	oldURL := gitHubAPIBaseURL
+	gitHubAPIBaseURL = server.URL
+	defer func() { gitHubAPIBaseURL = oldURL }()
+
+	hashes := map[string]string{"v1.0.0": strings.Repeat("a", 64), "v1.1.0": strings.Repeat("b", 64)}
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}.tar.gz")).
+		WithChecksums(spec.NewChecksums("checksums.txt").
+			WithEmbeddedChecksum("v1.0.0", "tool_linux_amd64.tar.gz", hashes["v1.0.0"]).
+			WithEmbeddedChecksum("v1.1.0", "tool_linux_amd64.tar.gz", hashes["v1.1.0"]))
+
+	dir := t.TempDir()
+	if err := genPinnedScripts(t.Context(), installSpec, "<2", "installer", dir, nil, shell.DefaultFeatures()); err != nil {
+		t.Fatalf("genPinnedScripts() error = %v", err)
+	}
+	for version, hash := range hashes {
+		script, err := os.ReadFile(filepath.Join(dir, "install-"+version+".sh"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(script), `TAG="`+version+`"`) || !strings.Contains(string(script), hash) {
+			t.Errorf("install-%s.sh is not pinned to %s with its checksum", version, version)
+		}
+		for other, otherHash := range hashes {
+			if other != version && strings.Contains(string(script), otherHash) {
+				t.Errorf("install-%s.sh embeds the checksum of %s", version, other)
+			}
+		}
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("genPinnedScripts() wrote %d scripts, want 2", len(entries))
+	}
+
+	if err := genPinnedScripts(t.Context(), installSpec, "v1.0.0,v1.1.0", "installer", "-", nil, shell.DefaultFeatures()); err == nil {
+		t.Error("genPinnedScripts() to stdout succeeded, want error")
+	}
+}
+
+func TestGenChannelScripts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"tag_name": "v2.0.0-rc.1", "prerelease": true}, {"tag_name": "v1.3.0"}]`))
+	}))
+	defer server.Close()
+	oldURL := gitHubAPIBaseURL
+	gitHubAPIBaseURL = server.URL
+	defer func() { gitHubAPIBaseURL = oldURL }()
+
+	installSpec := spec.NewInstallSpec("owner/tool").WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}.tar.gz"))
+	dir := t.TempDir()
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	if err := genChannelScripts(t.Context(), installSpec, []string{"stable"}, "installer", dir, shell.Options{}, now); err != nil {
+		t.Fatalf("genChannelScripts() error = %v", err)
+	}
+	if err := genChannelScripts(t.Context(), installSpec, []string{"beta"}, "installer", dir, shell.Options{}, now.Add(time.Hour)); err != nil {
+		t.Fatalf("genChannelScripts() error = %v", err)
+	}
+
+	for channel, tag := range map[string]string{"stable": "v1.3.0", "beta": "v2.0.0-rc.1"} {
+		script, err := os.ReadFile(filepath.Join(dir, "install-"+channel+".sh"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(script), "# Channel: "+channel+" ("+tag+", refreshed ") || !strings.Contains(string(script), `TAG="`+tag+`"`) {
+			t.Errorf("install-%s.sh is not a channel script pinned to %s", channel, tag)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, channelsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries map[string]channelEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]channelEntry{
+		"stable": {Tag: "v1.3.0", Script: "install-stable.sh", RefreshedAt: now},
+		"beta":   {Tag: "v2.0.0-rc.1", Script: "install-beta.sh", RefreshedAt: now.Add(time.Hour)},
+	}
+	if diff := cmp.Diff(want, entries); diff != "" {
+		t.Errorf("channels.json mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := genChannelScripts(t.Context(), installSpec, []string{"nightly"}, "installer", dir, shell.Options{}, now); err == nil {
+		t.Error("genChannelScripts() with an unknown channel succeeded, want error")
+	}
+}
+
+func TestApplyScriptHeaderFlags(t *testing.T) {
+	defer func() { genHeaderCopyright, genHeaderLicense, genHeaderSupportURL, genHeaderText = "", "", "", "" }()
+
+	installSpec := spec.NewInstallSpec("owner/tool")
+	applyScriptHeaderFlags(installSpec)
+	if installSpec.ScriptHeader != nil {
+		t.Errorf("ScriptHeader = %+v without flags, want nil", installSpec.ScriptHeader)
+	}
+
+	installSpec.ScriptHeader = &spec.ScriptHeader{Copyright: spec.StringPtr("Copyright 2024 Example Corp."), License: spec.StringPtr("MIT")}
+	genHeaderLicense = "Apache-2.0"
+	genHeaderSupportURL = "https://example.com/support"
+	applyScriptHeaderFlags(installSpec)
+	want := []string{"Copyright 2024 Example Corp.", "SPDX-License-Identifier: Apache-2.0", "Support: https://example.com/support"}
+	if diff := cmp.Diff(want, installSpec.GetScriptHeader().Lines()); diff != "" {
+		t.Errorf("ScriptHeader lines mismatch (-want +got):\n%s", diff)
+	}
+}
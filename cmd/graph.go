@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/binary-install/binstaller/pkg/asset"
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Flags for graph command
+	graphFormat  string
+	graphVersion string
+	graphOutput  string
+)
+
+// GraphCommand represents the graph command
+var GraphCommand = &cobra.Command{
+	Use:   "graph",
+	Short: "Render how platforms resolve through asset rules",
+	Long: `Renders a diagram showing how each supported platform flows through the
+asset rules to its final template, extension, and asset filename.
+
+Edges from a platform to a rule are numbered in the order the rules are applied.
+Later rules override values set by earlier ones.
+
+Supported formats:
+  dot     - Graphviz DOT (render with 'dot -Tsvg')
+  mermaid - Mermaid flowchart (embed in Markdown docs)`,
+	Example: `  # Render a Mermaid diagram for the default config
+  binst graph
+
+  # Render an SVG with Graphviz
+  binst graph --format dot | dot -Tsvg > rules.svg
+
+  # Use a concrete version in asset filenames
+  binst graph --version v1.2.3 -o docs/rules.mmd`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfgFile, err := resolveConfigFile(configFile)
+		if err != nil {
+			return err
+		}
+		installSpec, err := loadInstallSpec(cfgFile)
+		if err != nil {
+			return err
+		}
+		installSpec.SetDefaults()
+
+		version := graphVersion
+		if version == "" {
+			version = spec.StringValue(installSpec.DefaultVersion)
+		}
+		if version == "" || version == "latest" {
+			version = "1.0.0"
+		}
+
+		var w io.Writer = os.Stdout
+		if graphOutput != "" && graphOutput != "-" {
+			f, err := os.Create(graphOutput)
+			if err != nil {
+				return fmt.Errorf("failed to create output file: %w", err)
+			}
+			defer f.Close()
+			w = f
+		}
+		return RunGraph(installSpec, version, graphFormat, w)
+	},
+}
+
+// graphPlatform is a platform node and its resolution
+type graphPlatform struct {
+	name       string
+	resolution *asset.Resolution
+	err        error
+}
+
+// RunGraph renders the rule resolution graph of installSpec in the given format
+func RunGraph(installSpec *spec.InstallSpec, version, format string, w io.Writer) error {
+	if installSpec.Asset == nil {
+		return fmt.Errorf("asset configuration is required")
+	}
+
+	generator := asset.NewFilenameGenerator(installSpec, version)
+	var platforms []graphPlatform
+	for _, platform := range getSupportedPlatforms(installSpec) {
+		osName := spec.PlatformOSString(platform.OS)
+		arch := spec.PlatformArchString(platform.Arch)
+		if osName == "" || arch == "" {
+			continue
+		}
+		resolution, err := generator.Resolve(osName, arch)
+		platforms = append(platforms, graphPlatform{name: osName + "/" + arch, resolution: resolution, err: err})
+	}
+
+	switch format {
+	case "dot":
+		return renderDOT(w, installSpec, platforms)
+	case "mermaid":
+		return renderMermaid(w, installSpec, platforms)
+	default:
+		return fmt.Errorf("unsupported format: %s (supported: dot, mermaid)", format)
+	}
+}
+
+// graphAssets returns the distinct asset nodes in platform order and each platform's asset node index
+func graphAssets(platforms []graphPlatform) ([][]string, []int) {
+	var assets [][]string
+	seen := make(map[string]int)
+	index := make([]int, len(platforms))
+	for i, p := range platforms {
+		lines := assetLabel(p)
+		key := strings.Join(lines, "\n")
+		n, ok := seen[key]
+		if !ok {
+			n = len(assets)
+			seen[key] = n
+			assets = append(assets, lines)
+		}
+		index[i] = n
+	}
+	return assets, index
+}
+
+// assetLabel returns the label lines of the asset node a platform resolves to
+func assetLabel(p graphPlatform) []string {
+	if p.err != nil {
+		return []string{"error: " + p.err.Error()}
+	}
+	lines := []string{p.resolution.Filename, "template: " + p.resolution.Template}
+	if p.resolution.EXT != "" {
+		lines = append(lines, "ext: "+p.resolution.EXT)
+	}
+	return lines
+}
+
+// ruleLabel returns the label lines of a rule node
+func ruleLabel(i int, rule spec.RuleElement) []string {
+	var when []string
+	if rule.When != nil {
+		if os := spec.StringValue(rule.When.OS); os != "" {
+			when = append(when, "os="+os)
+		}
+		if arch := spec.StringValue(rule.When.Arch); arch != "" {
+			when = append(when, "arch="+arch)
+		}
+		if osVersion := spec.StringValue(rule.When.OSVersion); osVersion != "" {
+			when = append(when, "os_version="+osVersion)
+		}
+	}
+	lines := []string{fmt.Sprintf("rule #%d", i+1), "when " + strings.Join(when, ", ")}
+	for _, field := range []struct{ name, value string }{
+		{"os", spec.StringValue(rule.OS)},
+		{"arch", spec.StringValue(rule.Arch)},
+		{"ext", spec.StringValue(rule.EXT)},
+		{"template", spec.StringValue(rule.Template)},
+		{"checksum_template", rule.GetChecksumTemplate()},
+	} {
+		if field.value != "" {
+			lines = append(lines, field.name+" → "+field.value)
+		}
+	}
+	if len(rule.Binaries) > 0 {
+		names := make([]string, len(rule.Binaries))
+		for i, b := range rule.Binaries {
+			names[i] = b.GetName()
+		}
+		lines = append(lines, "binaries → "+strings.Join(names, ", "))
+	}
+	return lines
+}
+
+// renderDOT renders the graph in Graphviz DOT format
+func renderDOT(w io.Writer, installSpec *spec.InstallSpec, platforms []graphPlatform) error {
+	quote := func(lines []string) string {
+		escaped := make([]string, len(lines))
+		for i, line := range lines {
+			escaped[i] = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(line)
+		}
+		return `"` + strings.Join(escaped, `\n`) + `"`
+	}
+	assets, assetIndex := graphAssets(platforms)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", quote([]string{installSpec.GetName()}))
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, fontname=\"monospace\"];\n")
+	for i, p := range platforms {
+		fmt.Fprintf(&b, "  p%d [label=%s, shape=ellipse];\n", i, quote([]string{p.name}))
+	}
+	for i, rule := range installSpec.Asset.Rules {
+		fmt.Fprintf(&b, "  r%d [label=%s, style=rounded];\n", i, quote(ruleLabel(i, rule)))
+	}
+	for i, lines := range assets {
+		fmt.Fprintf(&b, "  a%d [label=%s];\n", i, quote(lines))
+	}
+	for i, p := range platforms {
+		if p.resolution != nil {
+			for step, rule := range p.resolution.Rules {
+				fmt.Fprintf(&b, "  p%d -> r%d [label=\"%d\"];\n", i, rule, step+1)
+			}
+		}
+		fmt.Fprintf(&b, "  p%d -> a%d [style=bold];\n", i, assetIndex[i])
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// renderMermaid renders the graph as a Mermaid flowchart
+func renderMermaid(w io.Writer, installSpec *spec.InstallSpec, platforms []graphPlatform) error {
+	quote := func(lines []string) string {
+		escaped := make([]string, len(lines))
+		for i, line := range lines {
+			escaped[i] = strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;").Replace(line)
+		}
+		return `"` + strings.Join(escaped, "<br/>") + `"`
+	}
+	assets, assetIndex := graphAssets(platforms)
+
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for i, p := range platforms {
+		fmt.Fprintf(&b, "  p%d([%s])\n", i, quote([]string{p.name}))
+	}
+	for i, rule := range installSpec.Asset.Rules {
+		fmt.Fprintf(&b, "  r%d(%s)\n", i, quote(ruleLabel(i, rule)))
+	}
+	for i, lines := range assets {
+		fmt.Fprintf(&b, "  a%d[%s]\n", i, quote(lines))
+	}
+	for i, p := range platforms {
+		if p.resolution != nil {
+			for step, rule := range p.resolution.Rules {
+				fmt.Fprintf(&b, "  p%d -->|%d| r%d\n", i, step+1, rule)
+			}
+		}
+		fmt.Fprintf(&b, "  p%d ==> a%d\n", i, assetIndex[i])
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func init() {
+	GraphCommand.Flags().StringVarP(&graphFormat, "format", "f", "mermaid", "Output format (dot, mermaid)")
+	GraphCommand.Flags().StringVar(&graphVersion, "version", "", "Version used in asset filenames (default: default_version from spec, or 1.0.0)")
+	GraphCommand.Flags().StringVarP(&graphOutput, "output", "o", "", "Output file (default: stdout)")
+}
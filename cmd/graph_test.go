@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+func TestRunGraph(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${VERSION}_${OS}_${ARCH}${EXT}").
+			WithDefaultExtension(".tar.gz").
+			WithRules(
+				spec.NewRule("", "amd64").WithArch("x86_64"),
+				spec.NewRule("windows", "").WithExt(".zip"),
+			)).
+		WithSupportedPlatforms("linux/amd64", "windows/amd64")
+	installSpec.SetDefaults()
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{"mermaid", []string{
+			"flowchart LR",
+			`p1(["windows/amd64"])`,
+			`r1("rule #2<br/>when os=windows<br/>ext → .zip")`,
+			`a1["tool_1.0.0_windows_x86_64.zip<br/>template: ${NAME}_${VERSION}_${OS}_${ARCH}${EXT}<br/>ext: .zip"]`,
+			"p1 -->|1| r0",
+			"p1 -->|2| r1",
+			"p1 ==> a1",
+		}},
+		{"dot", []string{
+			`digraph "tool" {`,
+			`r0 [label="rule #1\nwhen arch=amd64\narch → x86_64", style=rounded];`,
+			`a0 [label="tool_1.0.0_linux_x86_64.tar.gz\ntemplate: ${NAME}_${VERSION}_${OS}_${ARCH}${EXT}\next: .tar.gz"];`,
+			`p0 -> r0 [label="1"];`,
+			"p0 -> a0 [style=bold];",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := RunGraph(installSpec, "1.0.0", tt.format, &buf); err != nil {
+				t.Fatalf("RunGraph() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("RunGraph() output missing %q:\n%s", want, buf.String())
+				}
+			}
+			if strings.Contains(buf.String(), "p0 -->|2|") || strings.Contains(buf.String(), `p0 -> r1`) {
+				t.Errorf("RunGraph() linked linux/amd64 to the windows rule:\n%s", buf.String())
+			}
+		})
+	}
+
+	if err := RunGraph(installSpec, "1.0.0", "svg", &bytes.Buffer{}); err == nil {
+		t.Error("RunGraph() expected error for unsupported format")
+	}
+}
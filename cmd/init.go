@@ -2,10 +2,12 @@ package cmd
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/apex/log"
@@ -17,14 +19,17 @@ import (
 
 var (
 	// Flags for init command
-	initSource     string
-	initSourceFile string
-	initRepo       string // Repo for GitHub source OR explicit override
-	initName       string // Explicit override for binary name
-	initTag        string
-	initCommitSHA  string
-	initOutputFile string
-	initForce      bool // Skip confirmation when overwriting existing files
+	initSources     []string
+	initSourceFiles []string
+	initRepo        string // Repo for GitHub source OR explicit override
+	initName        string // Explicit override for binary name
+	initTag         string
+	initCommitSHA   string
+	initOutputFile  string
+	initForce       bool // Skip confirmation when overwriting existing files
+	// Flags for reconciling several sources
+	initPrefer      map[string]string
+	initInteractive bool
 )
 
 // promptForConfirmation prompts the user for confirmation and returns true if they confirm
@@ -44,7 +49,36 @@ var InitCommand = &cobra.Command{
 	Use:   "init",
 	Short: "Generate an InstallSpec config file from various sources",
 	Long: `Initializes a binstaller configuration file (.config/binstaller.yml) by detecting
-settings from a source like a GoReleaser config file or a GitHub repository.`,
+settings from a source like a GoReleaser config file or a GitHub repository.
+
+With --source=github, when no checksum configuration is detected the latest release
+is probed for common checksum files (checksums.txt, SHA256SUMS,
+NAME_VERSION_checksums.txt, per-asset .sha256 files) and checksums.template and
+checksums.algorithm are filled in from the first match and its contents.
+
+With --source=cargo-dist, the cargo-dist configuration of a Rust project ([dist] in
+dist-workspace.toml, or [workspace.metadata.dist] in Cargo.toml) is mapped to asset
+rules for each of its target triples, along with the per-asset checksum files dist
+publishes. --file reads a local Cargo.toml or dist-workspace.toml and the other
+one next to it; --repo fetches both from GitHub.
+
+Several sources can be combined, e.g. --source=goreleaser,aqua to take the asset
+templates and platforms from GoReleaser and the Rosetta 2 emulation, overrides and
+files from the Aqua registry. The specs are merged field by field: mappings key by
+key, and fields only one source generates are kept. When sources disagree on a
+value, the first source listed wins unless --prefer FIELD=SOURCE names another
+source for the field (and the fields below it), or --interactive asks which value
+to keep. With several sources, --file takes SOURCE=PATH.
+
+--post-process runs a program on the generated spec, so conventions such as a
+default_bin_dir or required checksums can be enforced on every spec an
+organization generates. The program is run by the shell (cmd on Windows) with
+the spec YAML on stdin and must write the modified spec, YAML or JSON, to
+stdout; its stderr is shown and a non-zero exit aborts init. The environment
+has BINSTALLER_HOOK=post-process, BINSTALLER_HOOK_VERSION=` + postProcessHookVersion + ` (the version
+of this contract) and BINSTALLER_SOURCES (the --source list). The output must
+be a valid InstallSpec without unknown fields. Repeat the flag to chain
+programs, each receiving the output of the previous one.`,
 	Example: `  # Initialize from GitHub releases
   binst init --source=github --repo=junegunn/fzf
 
@@ -57,6 +91,12 @@ settings from a source like a GoReleaser config file or a GitHub repository.`,
   # Initialize from GoReleaser with specific commit SHA
   binst init --source=goreleaser --repo=owner/repo --sha=abc123
 
+  # Initialize from the cargo-dist config of a Rust project
+  binst init --source=cargo-dist --file=Cargo.toml
+
+  # Initialize from the cargo-dist config in a GitHub repo
+  binst init --source=cargo-dist --repo=owner/repo
+
   # Initialize from Aqua registry for a specific package
   binst init --source=aqua --repo=junegunn/fzf
 
@@ -69,58 +109,62 @@ settings from a source like a GoReleaser config file or a GitHub repository.`,
   # Initialize from Aqua registry via stdin
   cat registry.yaml | binst init --source=aqua --file=-
 
+  # Merge GoReleaser templates with the overrides of the Aqua registry,
+  # taking the asset rules from Aqua when the sources disagree
+  binst init --source=goreleaser --source=aqua --repo=owner/repo \
+    --file=goreleaser=.goreleaser.yml --prefer asset.rules=aqua
+
+  # Merge sources, choosing every disputed value interactively
+  binst init --source=goreleaser,aqua --repo=owner/repo --interactive
+
+  # Enforce organization conventions on the generated spec
+  binst init --source=github --repo=junegunn/fzf --post-process ./fixup.sh
+
   # Initialize and overwrite existing config without confirmation
   binst init --source=github --repo=junegunn/fzf --force`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		log.Infof("Running init command...")
 
-		var adapter datasource.SourceAdapter
-
-		switch initSource {
-		case "goreleaser":
-			adapter = datasource.NewGoReleaserAdapter(
-				initRepo,       // repo
-				initSourceFile, // filePath
-				initCommitSHA,  // commit
-				initName,       // nameOverride
-			)
-		case "github":
-			adapter = datasource.NewGitHubAdapter(initRepo)
-		case "aqua":
-			// Use --file for registry YAML, or stdin if not specified
-			switch initSourceFile {
-			case "":
-				// No file: use repo (and optionally commit SHA/ref)
-				if initRepo == "" {
-					return fmt.Errorf("--repo is required for aqua source when --file is not specified")
-				}
-				adapter = datasource.NewAquaRegistryAdapterFromRepo(initRepo, initCommitSHA)
-			case "-":
-				// --file=- means stdin
-				adapter = datasource.NewAquaRegistryAdapterFromReader(os.Stdin)
-			default:
-				// --file=path
-				f, err := os.Open(initSourceFile)
-				if err != nil {
-					return fmt.Errorf("failed to open aqua registry file: %w", err)
-				}
-				defer f.Close()
-				adapter = datasource.NewAquaRegistryAdapterFromReader(f)
-			}
-		default:
-			err := fmt.Errorf("unknown source specified: %s. Valid sources are: goreleaser, github, aqua", initSource)
-			log.WithError(err).Error("invalid source")
+		files, err := resolveInitSourceFiles(initSources, initSourceFiles)
+		if err != nil {
 			return err
 		}
+		if len(initSources) < 2 && (len(initPrefer) > 0 || initInteractive) {
+			return fmt.Errorf("--prefer and --interactive need more than one --source")
+		}
+		if initInteractive && files["aqua"] == "-" {
+			return fmt.Errorf("--interactive reads answers from stdin and cannot be combined with --file=aqua=-")
+		}
 
 		ctx := context.Background()
 
-		// Generate the InstallSpec
-		log.Infof("Generating InstallSpec using source: %s", initSource)
-		installSpec, err := adapter.GenerateInstallSpec(ctx)
-		if err != nil {
-			log.WithError(err).Error("Failed to detect install spec")
-			return fmt.Errorf("failed to detect install spec: %w", err)
+		// Generate an InstallSpec from every source
+		var generated []datasource.SourceSpec
+		for _, source := range initSources {
+			adapter, cleanup, err := newInitAdapter(source, files[source])
+			if err != nil {
+				return err
+			}
+			log.Infof("Generating InstallSpec using source: %s", source)
+			installSpec, err := adapter.GenerateInstallSpec(ctx)
+			cleanup()
+			if err != nil {
+				log.WithError(err).Error("Failed to detect install spec")
+				return fmt.Errorf("failed to detect install spec from %s: %w", source, err)
+			}
+			generated = append(generated, datasource.SourceSpec{Source: source, Spec: installSpec})
+		}
+
+		installSpec := generated[0].Spec
+		if len(generated) > 1 {
+			resolve := datasource.FirstSourceResolver
+			if initInteractive {
+				resolve = datasource.PromptResolver(os.Stdin, os.Stderr)
+			}
+			installSpec, err = datasource.MergeSourceSpecs(generated, datasource.PrecedenceResolver(initPrefer, resolve))
+			if err != nil {
+				return fmt.Errorf("failed to merge install specs: %w", err)
+			}
 		}
 		if spec.StringValue(installSpec.Schema) == "" {
 			installSpec.Schema = spec.StringPtr("v1")
@@ -135,9 +179,17 @@ settings from a source like a GoReleaser config file or a GitHub repository.`,
 			return fmt.Errorf("failed to marshal install spec to YAML: %w", err)
 		}
 
+		if len(initPostProcess) > 0 {
+			yamlData, err = postProcessSpec(ctx, yamlData, initPostProcess, initSources)
+			if err != nil {
+				return err
+			}
+		}
+
 		// Add schema reference comment for IDE support
-		schemaComment := "# yaml-language-server: $schema=https://raw.githubusercontent.com/binary-install/binstaller/main/schema/InstallSpec.json\n"
-		yamlData = append([]byte(schemaComment), yamlData...)
+		if !bytes.Contains(yamlData, []byte("yaml-language-server:")) {
+			yamlData = append([]byte(schemaComment), yamlData...)
+		}
 
 		// Write the output
 		if initOutputFile == "" || initOutputFile == "-" {
@@ -181,19 +233,101 @@ settings from a source like a GoReleaser config file or a GitHub repository.`,
 	},
 }
 
+// initSourceNames are the sources init can generate a spec from
+var initSourceNames = []string{"goreleaser", "aqua", "github", "cargo-dist"}
+
+// newInitAdapter creates the adapter of source reading file, with a cleanup
+// function to call once the spec is generated
+func newInitAdapter(source, file string) (datasource.SourceAdapter, func(), error) {
+	noop := func() {}
+	switch source {
+	case "goreleaser":
+		return datasource.NewGoReleaserAdapter(
+			initRepo,      // repo
+			file,          // filePath
+			initCommitSHA, // commit
+			initName,      // nameOverride
+		), noop, nil
+	case "github":
+		return datasource.NewGitHubAdapter(initRepo), noop, nil
+	case "cargo-dist":
+		return datasource.NewCargoDistAdapter(initRepo, file, initCommitSHA, initName), noop, nil
+	case "aqua":
+		// Use --file for registry YAML, or stdin if not specified
+		switch file {
+		case "":
+			// No file: use repo (and optionally commit SHA/ref)
+			if initRepo == "" {
+				return nil, nil, fmt.Errorf("--repo is required for aqua source when --file is not specified")
+			}
+			return datasource.NewAquaRegistryAdapterFromRepo(initRepo, initCommitSHA), noop, nil
+		case "-":
+			// --file=- means stdin
+			return datasource.NewAquaRegistryAdapterFromReader(os.Stdin), noop, nil
+		default:
+			// --file=path
+			f, err := os.Open(file)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to open aqua registry file: %w", err)
+			}
+			return datasource.NewAquaRegistryAdapterFromReader(f), func() { f.Close() }, nil
+		}
+	}
+	err := fmt.Errorf("unknown source specified: %s. Valid sources are: %s", source, strings.Join(initSourceNames, ", "))
+	log.WithError(err).Error("invalid source")
+	return nil, nil, err
+}
+
+// resolveInitSourceFiles maps each source to its --file. With several sources every
+// file is given as SOURCE=PATH; a single source also takes a plain PATH.
+func resolveInitSourceFiles(sources, files []string) (map[string]string, error) {
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("--source is required (%s)", strings.Join(initSourceNames, ", "))
+	}
+	seen := map[string]bool{}
+	for _, source := range sources {
+		if seen[source] {
+			return nil, fmt.Errorf("--source %s is given twice", source)
+		}
+		seen[source] = true
+	}
+
+	result := map[string]string{}
+	for _, file := range files {
+		source, path, ok := strings.Cut(file, "=")
+		if !ok || !slices.Contains(initSourceNames, source) {
+			if len(sources) > 1 {
+				return nil, fmt.Errorf("--file %s: use SOURCE=PATH with several sources", file)
+			}
+			source, path = sources[0], file
+		}
+		if !seen[source] {
+			return nil, fmt.Errorf("--file %s is for source %s, which is not selected", file, source)
+		}
+		if _, ok := result[source]; ok {
+			return nil, fmt.Errorf("--file is given twice for source %s", source)
+		}
+		result[source] = path
+	}
+	return result, nil
+}
+
 func init() {
 	// Required flags
-	InitCommand.Flags().StringVar(&initSource, "source", "", "Source type to detect spec from (required: goreleaser, aqua, github)")
+	InitCommand.Flags().StringSliceVar(&initSources, "source", nil, "Source types to detect spec from, merged when several are given (required: goreleaser, aqua, github, cargo-dist)")
 	_ = InitCommand.MarkFlagRequired("source")
 
 	// Optional flags (depending on source)
-	InitCommand.Flags().StringVar(&initSourceFile, "file", "", "Path to source file (e.g., .goreleaser.yml)")
-	InitCommand.Flags().StringVar(&initRepo, "repo", "", "GitHub repository (owner/repo) for source 'goreleaser'/'github', or explicit override")
+	InitCommand.Flags().StringArrayVar(&initSourceFiles, "file", nil, "Path to source file (e.g., .goreleaser.yml), SOURCE=PATH with several sources")
+	InitCommand.Flags().StringVar(&initRepo, "repo", "", "GitHub repository (owner/repo) for source 'goreleaser'/'github'/'cargo-dist', or explicit override")
 	InitCommand.Flags().StringVar(&initName, "name", "", "Explicit binary name override")
 	InitCommand.Flags().StringVar(&initTag, "tag", "", "Release tag/ref to inspect (for source 'github')")
-	InitCommand.Flags().StringVar(&initCommitSHA, "sha", "", "Commit SHA for source 'goreleaser'")
+	InitCommand.Flags().StringVar(&initCommitSHA, "sha", "", "Commit SHA for source 'goreleaser'/'cargo-dist'")
 	InitCommand.Flags().StringVarP(&initOutputFile, "output", "o", DefaultConfigPathYML, "Write spec to file instead of stdout (use '-' for stdout)")
 	InitCommand.Flags().BoolVar(&initForce, "force", false, "Skip confirmation when overwriting existing files")
+	InitCommand.Flags().StringToStringVar(&initPrefer, "prefer", nil, "With several sources, FIELD=SOURCE taking FIELD (e.g. asset.rules) and the fields below it from SOURCE when sources disagree")
+	InitCommand.Flags().StringArrayVar(&initPostProcess, "post-process", nil, "Program modifying the generated spec: it reads the spec on stdin and writes the modified spec to stdout (repeatable)")
+	InitCommand.Flags().BoolVar(&initInteractive, "interactive", false, "With several sources, ask which value to keep for each field they disagree on")
 
 	// TODO: Add dependencies between flags (e.g., --file required if --source goreleaser and no --repo)
 }
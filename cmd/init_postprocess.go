@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// postProcessHookVersion is the version of the contract between binst and
+// --post-process programs, passed as $BINSTALLER_HOOK_VERSION. It changes only
+// when the contract does.
+const postProcessHookVersion = "1"
+
+// postProcessTimeout bounds each --post-process program
+const postProcessTimeout = 2 * time.Minute
+
+// initPostProcess holds the programs that modify the generated spec
+var initPostProcess []string
+
+// postProcessSpec pipes the generated spec YAML through each program in turn.
+// A program reads the spec on stdin and writes the modified spec, YAML or
+// JSON, to stdout; its stderr is passed through and a non-zero exit aborts.
+// The output of every program must parse as an InstallSpec without unknown
+// fields and pass validation.
+func postProcessSpec(ctx context.Context, yamlData []byte, programs, sources []string) ([]byte, error) {
+	for _, program := range programs {
+		log.Infof("Post-processing spec with %s", program)
+		out, err := runPostProcessor(ctx, program, yamlData, sources)
+		if err != nil {
+			return nil, fmt.Errorf("post-process %s: %w", program, err)
+		}
+		installSpec, err := decodeSpecStrict(out)
+		if err != nil {
+			return nil, fmt.Errorf("post-process %s returned an invalid spec: %w", program, err)
+		}
+		if installSpec.GetRepo() == "" {
+			return nil, fmt.Errorf("post-process %s returned a spec without repo", program)
+		}
+		if err := spec.Validate(installSpec); err != nil {
+			return nil, fmt.Errorf("post-process %s returned an invalid spec: %w", program, err)
+		}
+		yamlData = out
+	}
+	return yamlData, nil
+}
+
+// runPostProcessor runs program through the shell with input on stdin and
+// returns its stdout
+func runPostProcessor(ctx context.Context, program string, input []byte, sources []string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, postProcessTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", program)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", program)
+	}
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"BINSTALLER_HOOK=post-process",
+		"BINSTALLER_HOOK_VERSION="+postProcessHookVersion,
+		"BINSTALLER_SOURCES="+strings.Join(sources, ","),
+	)
+	out, err := cmd.Output()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("timed out after %s", postProcessTimeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(out)) == 0 {
+		return nil, fmt.Errorf("no spec written to stdout")
+	}
+	return out, nil
+}
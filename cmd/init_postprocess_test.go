@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestPostProcessSpec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("post-process programs are shell scripts here")
+	}
+	dir := t.TempDir()
+	fixup := filepath.Join(dir, "fixup.sh")
+	writeTestFile(t, fixup, `#!/bin/sh
+cat
+echo "default_bin_dir: /opt/tools"
+echo "# $BINSTALLER_HOOK v$BINSTALLER_HOOK_VERSION from $BINSTALLER_SOURCES"
+`, 0755)
+	input := []byte("schema: v1\nrepo: owner/tool\n")
+
+	out, err := postProcessSpec(context.Background(), input, []string{fixup, "sed s/tools/bin/"}, []string{"github"})
+	if err != nil {
+		t.Fatalf("postProcessSpec() error = %v", err)
+	}
+	for _, want := range []string{"repo: owner/tool", "default_bin_dir: /opt/bin", "# post-process v1 from github"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("postProcessSpec() output lacks %q:\n%s", want, out)
+		}
+	}
+
+	tests := []struct {
+		name, program, wantErr string
+	}{
+		{"failing program", "exit 3", "exit status 3"},
+		{"no output", "cat >/dev/null", "no spec written"},
+		{"unknown field", "cat; echo 'unknown_field: 1'", "invalid spec"},
+		{"repo removed", "echo 'schema: v1'", "without repo"},
+		{"invalid value", "cat; echo 'breaking_changes_url: http://example.com'", "breaking_changes_url must be an https URL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := postProcessSpec(context.Background(), input, []string{tt.program}, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("postProcessSpec(%q) error = %v, want %q", tt.program, err, tt.wantErr)
+			}
+		})
+	}
+}
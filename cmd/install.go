@@ -2,7 +2,7 @@ package cmd
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,12 +11,18 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/apex/log"
 	"github.com/binary-install/binstaller/pkg/archive"
 	"github.com/binary-install/binstaller/pkg/asset"
+	"github.com/binary-install/binstaller/pkg/cache"
 	"github.com/binary-install/binstaller/pkg/checksums"
 	"github.com/binary-install/binstaller/pkg/httpclient"
+	"github.com/binary-install/binstaller/pkg/lockfile"
+	"github.com/binary-install/binstaller/pkg/metrics"
+	"github.com/binary-install/binstaller/pkg/pkgmgr"
+	"github.com/binary-install/binstaller/pkg/resolver"
 	"github.com/binary-install/binstaller/pkg/spec"
 	"github.com/buildkite/interpolate"
 	"github.com/spf13/cobra"
@@ -24,17 +30,153 @@ import (
 
 var (
 	// Flags for install command
-	installBinDir string
-	installDryRun bool
+	installBinDir     string
+	installDryRun     bool
+	installNoOverlays bool
+	installFromFile   string
+	installAllowWeak  bool
+	// Flags for deferring to the system package manager
+	installSuggestSystem bool
+	installPreferSystem  bool
+	installSystemPackage string
+	// Flags for installing every tool of the project
+	installAllTools  bool
+	installKeepGoing bool
+	installStateFile string
+	// Flag for printing shell environment setup
+	installPrintEnv bool
+	// Flag for opting out of the GitHub Actions tool cache
+	installNoToolCache bool
+	// Flag for listing the asset contents instead of installing
+	installListContents bool
+	// Flags for overriding the asset resolved from the templates
+	installAssetURL  string
+	installAssetName string
+	// Flags for installing the binaries of another platform
+	installOS     string
+	installArch   string
+	installOutput string
+	// Flag for installing into the project's tool directory
+	installProject bool
 )
 
+// errAssetNotFound is returned by download when the release has no such asset
+var errAssetNotFound = errors.New("asset not found")
+
 // InstallCommand represents the install command
 var InstallCommand = &cobra.Command{
 	Use:   "install [VERSION]",
 	Short: "Install a binary directly from GitHub releases",
 	Long: `Install a binary directly from GitHub releases, achieving script-parity with the generated shell installers.
 
-This command provides a native Go implementation of the installation process, supporting version resolution, checksum verification, and cross-platform binary installation.`,
+This command provides a native Go implementation of the installation process, supporting version resolution, checksum verification, and cross-platform binary installation.
+
+Downloads taking longer than a moment show their progress, speed and ETA when stdout
+is a terminal. --quiet or BINSTALLER_NO_PROGRESS=1 turns it off.
+
+The config is layered before use, later layers taking precedence:
+  1. Org defaults from $BINSTALLER_DEFAULTS_URL (URL or path)
+  2. The install spec
+  3. User overrides from $BINSTALLER_OVERRIDES (default: ~/.config/binstaller/overrides.yml)
+
+Mappings merge key by key; scalars and lists replace earlier values. Use --no-overlays to install from the spec alone.
+
+With --suggest-system, the local package managers (brew, apt, apk, dnf, pacman) are
+probed for the tool and a suggestion is printed when one has the same version.
+--prefer-system skips the installation in that case.
+
+With --from-file, an already downloaded asset is installed instead of downloading it,
+e.g. when a proxy only allows downloads from a browser. The asset is still verified
+against the checksums of the release and extracted as usual. Pass VERSION explicitly
+to avoid querying GitHub for the latest release.
+
+--asset-name installs another asset of the release than the one resolved from the
+asset templates, and --asset-url downloads the asset from anywhere else, e.g. a
+hotfix build or a mirror, for one-off debugging. The asset is verified against the
+checksums of the release under its filename (the last path element of the URL, or
+--asset-name), so checksums.required still refuses an asset the release does not
+list. Overridden assets are not cached.
+
+md5 and sha1 checksums are too weak to verify an asset: it is installed as unverified,
+or refused when checksums.required is set. Use --allow-weak-hash (or set
+BINSTALLER_ALLOW_WEAK_HASH=1, which generated installers honor too) to accept them.
+
+With --all, the tool of every InstallSpec in .config/binstaller (or every tool
+of a multi-tool config declaring tools:) is installed at its default_version,
+followed by a summary table of each tool's outcome. The
+first failure stops the run unless --keep-going is set. Progress is saved to a
+state file (--state), so re-running only retries the tools that failed; the file
+is removed once every tool is installed.
+
+With --print-env, shell lines adding the install directory to PATH are printed to
+stdout once the installation succeeds, so eval "$(binst install --print-env)" in a
+shell init file or CI step makes the tools available. Logs and the --all summary
+go to stderr. A directory already on PATH is not added again. The runtime_env
+variables of the spec are exported too.
+
+An install directory on a read-only file system, or in a system directory of an
+immutable distribution such as NixOS or Fedora Silverblue, is redirected to
+~/.local/bin with a warning. --no-fallback fails instead.
+
+Before installing, binst checks whether PATH finds another executable of the same
+name before the install directory, which would keep the old version running. A
+Homebrew or apt package providing it fails the installation with the package to
+uninstall; --force installs anyway. Other executables only cause a warning.
+
+Each installed tool is recorded in a receipt under $BINSTALLER_RECEIPTS_DIR
+(default: <user config dir>/binstaller/receipts) with its tag, binaries, and
+runtime_env expanded for the install directory. Tools installed outside the home
+directory, into shared locations such as /usr/local/bin, are recorded in the
+system receipts directory $BINSTALLER_SYSTEM_RECEIPTS_DIR (default:
+/var/lib/binstaller/receipts) when it is writable. Installs of the same tool lock
+its receipt, so concurrent runs, such as parallel CI jobs on one host, wait for
+each other instead of racing on the same binaries.
+
+Inside GitHub Actions ($GITHUB_ACTIONS=true with $RUNNER_TOOL_CACHE set), tools are
+installed into the hosted tool cache as $RUNNER_TOOL_CACHE/NAME/VERSION/ARCH, the
+layout of actions/tool-cache, and a version already there is not downloaded again.
+The directory is added to $GITHUB_PATH, and the path and key to cache it across
+jobs are logged and written to $GITHUB_OUTPUT as tool-cache-path and tool-cache-key.
+--bin-dir or --no-tool-cache installs as usual instead.
+
+With --list-contents, nothing is extracted or installed: the entries of the asset
+are listed with their sizes and the paths they are extracted to after
+strip_components and unpack filters, followed by the binaries that would be
+selected. The asset is reused from --from-file or the cache when possible, so
+strip_components and binary paths can be tuned without downloading it again.
+
+With --completions, the bash, zsh and fish completion scripts listed in the
+completions section of the spec are installed from the archive into the share
+directory next to the install directory, e.g. ~/.local/share for ~/.local/bin:
+share/bash-completion/completions, share/zsh/site-functions and
+share/fish/vendor_completions.d.
+
+When the spec declares breaking_changes (or breaking_changes_url), upgrading a
+binary already in the install directory across one of them logs a warning with
+its migration notes. The installed version is read from the binary's --version
+output; --upgrade-from sets it instead.
+
+When binstaller.lock (see --lockfile and 'binst lock') locks the tool for the
+requested version, or the spec's default_version without VERSION, the locked
+tag is installed and the asset must match the locked sha256 digest.
+
+With --output, the binaries are installed into DIR/OS_ARCH instead, for the host
+platform or the one set by --os and --arch, e.g. to bundle binaries for container
+images of other architectures. Such cross installs only download, verify and
+extract: no receipt, tool cache, PATH check or breaking change warning is
+involved, and Windows binaries are not marked executable. Run it once per
+platform to fill a dist directory.
+
+With --project, tools are installed into .binstaller/bin of the project instead of
+a global directory, so each project gets the toolchain its specs pin (with
+default_version and binstaller.lock) without touching the user's bin directory.
+The project root is the nearest directory up from the working directory with
+.binstaller, .config/binstaller, a binstaller config or .git. Receipts of project
+tools stay in .binstaller/receipts, and .binstaller/env is regenerated to add
+.binstaller/bin to PATH and export the runtime_env of the project's tools:
+'. .binstaller/env' activates them. /.binstaller/ is added to the .gitignore of
+the project root unless it is already ignored. Combine it with --all to install
+every tool of the project.`,
 	Example: `  # Install latest version
   binst install
 
@@ -45,7 +187,40 @@ This command provides a native Go implementation of the installation process, su
   binst install --bin-dir=/usr/local/bin
 
   # Dry run mode (verify URLs/versions without installing)
-  binst install --dry-run`,
+  binst install --dry-run
+
+  # Show how the asset is extracted and which binaries are selected
+  binst install v1.2.3 --list-contents
+
+  # Install an asset downloaded by other means
+  binst install v1.2.3 --from-file ~/Downloads/mytool_1.2.3_linux_amd64.tar.gz
+
+  # Install the musl build instead of the resolved asset
+  binst install v1.2.3 --asset-name mytool_1.2.3_linux_amd64_musl.tar.gz
+
+  # Install a hotfix build, verified against the release checksums
+  binst install v1.2.3 --asset-url https://example.com/mytool_1.2.3_linux_amd64.tar.gz
+
+  # Use the distribution package when it has the same version
+  binst install --prefer-system --system-package ripgrep
+
+  # Install every tool of the project, continuing past failures
+  binst install --all --keep-going
+
+  # Install one tool of a multi-tool config
+  binst install --config .config/binstaller.yml#gh
+
+  # Install the linux/arm64 binaries into dist/linux_arm64
+  binst install v1.2.3 --os linux --arch arm64 --output dist
+
+  # Install every tool of the project into .binstaller/bin and activate them
+  binst install --all --project && . .binstaller/env
+
+  # Install the shell completions shipped in the archive too
+  binst install --completions
+
+  # Install and add the install directory to PATH
+  eval "$(binst install --print-env)"`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runInstall,
 }
@@ -53,6 +228,29 @@ This command provides a native Go implementation of the installation process, su
 func init() {
 	InstallCommand.Flags().StringVarP(&installBinDir, "bin-dir", "b", "", "Installation directory")
 	InstallCommand.Flags().BoolVarP(&installDryRun, "dry-run", "n", false, "Dry run mode")
+	InstallCommand.Flags().BoolVar(&installNoOverlays, "no-overlays", false, "Ignore org defaults ($BINSTALLER_DEFAULTS_URL) and user overrides")
+	InstallCommand.Flags().StringVar(&installFromFile, "from-file", "", "Install from an already downloaded asset instead of downloading it")
+	InstallCommand.Flags().StringVar(&installAssetURL, "asset-url", "", "Download the asset from URL instead of the release, still verifying it against the release checksums")
+	InstallCommand.Flags().StringVar(&installAssetName, "asset-name", "", "Install the release asset NAME instead of the one resolved from the templates")
+	InstallCommand.Flags().BoolVar(&installAllowWeak, "allow-weak-hash", false, "Accept md5 and sha1 checksums as verification ($BINSTALLER_ALLOW_WEAK_HASH=1)")
+	InstallCommand.Flags().BoolVar(&installSuggestSystem, "suggest-system", false, "Suggest the system package manager when it has the same version")
+	InstallCommand.Flags().BoolVar(&installPreferSystem, "prefer-system", false, "Skip installing when the system package manager has the same version")
+	InstallCommand.Flags().StringVar(&installSystemPackage, "system-package", "", "Package name to probe in system package managers (default: the spec's name)")
+	InstallCommand.Flags().BoolVar(&installAllTools, "all", false, "Install the tool of every InstallSpec in "+ProjectToolsDir+", or every tool of the config")
+	InstallCommand.Flags().BoolVar(&installKeepGoing, "keep-going", false, "With --all, continue installing the remaining tools after a failure")
+	InstallCommand.Flags().StringVar(&installStateFile, "state", defaultInstallStateFile, "With --all, file recording progress so a re-run only retries failures")
+	InstallCommand.Flags().BoolVar(&installPrintEnv, "print-env", false, "Print shell lines adding the install directory to PATH, for eval")
+	InstallCommand.Flags().BoolVar(&installNoToolCache, "no-tool-cache", false, "Do not install into the GitHub Actions tool cache ($RUNNER_TOOL_CACHE)")
+	InstallCommand.Flags().BoolVar(&installListContents, "list-contents", false, "List the asset contents and the selected binaries instead of installing")
+	InstallCommand.Flags().StringVar(&installLockFile, "lockfile", lockfile.DefaultPath, "Lockfile pinning tags and asset digests, honored when it exists (see 'binst lock')")
+	InstallCommand.Flags().BoolVar(&installForce, "force", false, "Install even when a Homebrew or apt binary of the same name comes first on PATH")
+	InstallCommand.Flags().BoolVar(&installCompletions, "completions", false, "Install the shell completions of the spec into the share directory next to the install directory")
+	InstallCommand.Flags().BoolVar(&installNoFallback, "no-fallback", false, "Fail instead of installing into ~/.local/bin when the install directory is read-only")
+	InstallCommand.Flags().StringVar(&installOS, "os", "", "Install the binaries of OS instead of the host OS (requires --output)")
+	InstallCommand.Flags().StringVar(&installArch, "arch", "", "Install the binaries of ARCH instead of the host architecture (requires --output)")
+	InstallCommand.Flags().StringVar(&installOutput, "output", "", "Install the binaries into DIR/OS_ARCH for packaging, without receipts or tool cache")
+	InstallCommand.Flags().BoolVar(&installProject, "project", false, "Install into .binstaller/bin of the project and write .binstaller/env to activate it")
+	InstallCommand.Flags().StringVar(&installUpgradeFrom, "upgrade-from", "", "Version being upgraded, for breaking change warnings (default: the installed binary's --version)")
 }
 
 // GitHubRelease represents the GitHub API response for a release
@@ -61,86 +259,273 @@ type GitHubRelease struct {
 	Name    string `json:"name"`
 }
 
-// gitHubAPIBaseURL is the base URL for GitHub API calls (overridable for testing)
-var gitHubAPIBaseURL = "https://api.github.com"
+// gitHubAPIBaseURL overrides the base URL for GitHub API calls (for testing)
+var gitHubAPIBaseURL string
 
-// resolveVersion resolves a version string to an actual GitHub release tag
-func resolveVersion(ctx context.Context, repo, version string) (string, error) {
-	if version != "" && version != "latest" {
-		// User provided explicit version, use as-is
-		return version, nil
+// gitHubAPIURL returns the GitHub API base URL for the spec: the API of its
+// GitHub Enterprise Server host, GITHUB_API_URL, or https://api.github.com
+func gitHubAPIURL(installSpec *spec.InstallSpec) string {
+	if gitHubAPIBaseURL != "" {
+		return gitHubAPIBaseURL
 	}
+	return asset.GitHubAPIURL(installSpec)
+}
 
-	// Resolve "latest" to actual tag using GitHub API
-	log.Info("checking GitHub for latest tag")
+// gitHubDownloadBaseURL overrides the base URL for GitHub release asset
+// downloads (for testing)
+var gitHubDownloadBaseURL string
 
-	url := fmt.Sprintf("%s/repos/%s/releases/latest", gitHubAPIBaseURL, repo)
+// gitLabBaseURL replaces the web URL of the GitLab instance of source: gitlab specs (for testing)
+var gitLabBaseURL = ""
 
-	client := httpclient.NewGitHubClient()
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+// releaseBaseURL returns the web URL of the service hosting the releases of the spec
+func releaseBaseURL(installSpec *spec.InstallSpec) string {
+	override := gitHubDownloadBaseURL
+	if installSpec.GetSource() == spec.Gitlab {
+		override = gitLabBaseURL
+	}
+	if override != "" {
+		return override
 	}
+	return asset.BaseURL(installSpec)
+}
 
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
+// releaseDownloadURL returns the download URL of a release asset
+func releaseDownloadURL(installSpec *spec.InstallSpec, tag, filename string) string {
+	return asset.DownloadURL(installSpec, releaseBaseURL(installSpec), tag, filename)
+}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to fetch release: %w", err)
+// resolveVersion resolves a version string to an actual tag using the spec's version
+// source, or to the tag locked in the lockfile
+func resolveVersion(ctx context.Context, installSpec *spec.InstallSpec, version string) (string, error) {
+	if tag, ok := lockedTag(installSpec, version); ok {
+		return tag, nil
 	}
-	defer resp.Body.Close()
+	r := resolver.New(installSpec)
+	r.APIBaseURL = gitHubAPIURL(installSpec)
+	r.GitLabBaseURL = gitLabBaseURL
+	return r.Resolve(ctx, version)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("GitHub API returned status %d: %s", resp.StatusCode, string(body))
+// fetchReleaseVars returns the values of the release metadata variables of
+// tag, or nil when the spec's asset templates do not reference them
+func fetchReleaseVars(ctx context.Context, installSpec *spec.InstallSpec, tag string) (map[string]string, error) {
+	if !installSpec.UsesReleaseVariables() {
+		return nil, nil
 	}
-
-	var release GitHubRelease
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+	r := resolver.New(installSpec)
+	r.APIBaseURL = gitHubAPIURL(installSpec)
+	r.GitLabBaseURL = gitLabBaseURL
+	metadata, err := r.ReleaseMetadata(ctx, tag)
+	if err != nil {
+		return nil, err
 	}
+	return metadata.Vars(), nil
+}
 
-	if release.TagName == "" {
-		return "", fmt.Errorf("no tag_name found in GitHub response")
+// newReleaseFilenameGenerator returns the filename generator of the release of
+// tag, with its release metadata variables
+func newReleaseFilenameGenerator(ctx context.Context, installSpec *spec.InstallSpec, tag string) (*asset.FilenameGenerator, error) {
+	releaseVars, err := fetchReleaseVars(ctx, installSpec, tag)
+	if err != nil {
+		return nil, err
 	}
-
-	return release.TagName, nil
+	generator := asset.NewFilenameGenerator(installSpec, tag)
+	generator.ReleaseVars = releaseVars
+	return generator, nil
 }
 
 func runInstall(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 
+	if err := loadInstallLock(); err != nil {
+		return err
+	}
+	if err := validateProjectInstall(); err != nil {
+		return err
+	}
+
+	if installAllTools {
+		if installListContents {
+			return fmt.Errorf("--list-contents lists a single tool and cannot be combined with --all")
+		}
+		if len(args) > 0 || installAssetSource() != (assetSource{}) {
+			return fmt.Errorf("--all installs each tool at its default_version and cannot be combined with VERSION, --from-file, --asset-url or --asset-name")
+		}
+		if installOutput != "" {
+			return fmt.Errorf("--output installs a single tool and cannot be combined with --all")
+		}
+		files, err := listInputFiles(nil)
+		if err != nil {
+			return err
+		}
+		// Keep stdout for the environment when it is evaluated
+		var summary io.Writer = os.Stdout
+		if installPrintEnv {
+			summary = os.Stderr
+		}
+		results, err := installAll(ctx, summary, files, installStateFile, installKeepGoing, installDryRun)
+		if installProject && !installDryRun && len(installedBinDirs(results)) > 0 {
+			if envErr := writeProjectEnv(installedBinDirs(results)[0]); envErr != nil && err == nil {
+				err = envErr
+			}
+		}
+		if installPrintEnv {
+			if envErr := printEnv(os.Stdout, installedBinDirs(results)); envErr != nil && err == nil {
+				err = envErr
+			}
+			if envErr := printRuntimeEnv(os.Stdout, installedRuntimeEnv(results)); envErr != nil && err == nil {
+				err = envErr
+			}
+		}
+		return err
+	}
+	if installKeepGoing {
+		return fmt.Errorf("--keep-going requires --all")
+	}
+
+	src := installAssetSource()
+	if err := src.validate(); err != nil {
+		return err
+	}
+
 	// 1. Resolve config file path
 	cfgPath, err := resolveConfigFile(configFile)
 	if err != nil {
 		return err
 	}
 
-	// 2. Load config
-	spec, err := loadInstallSpec(cfgPath)
+	// 2. Load config with org defaults and user overrides layered on top
+	spec, err := loadInstallSpecWithOverlays(ctx, cfgPath, !installNoOverlays)
 	if err != nil {
 		return err
 	}
+	ctx = specContext(ctx, spec)
 
 	// Apply defaults (including setting Name from Repo if not specified)
 	spec.SetDefaults()
 
-	// Get repo from spec
-	if spec.Repo == nil || *spec.Repo == "" {
-		return fmt.Errorf("GitHub repo not specified in config")
-	}
-	repo := *spec.Repo
-
 	// 3. Get version from args (positional VERSION argument)
 	version := ""
 	if len(args) > 0 {
 		version = args[0]
 	}
 
+	if installListContents {
+		return listContents(ctx, os.Stdout, spec, version, src)
+	}
+	if err := validateCrossInstall(); err != nil {
+		return err
+	}
+	if installOutput != "" {
+		return installCross(ctx, spec, version, installOutput, installDryRun, src)
+	}
+
+	// Determine installation directory
+	binDir, err := resolveInstallBinDir(spec)
+	if err != nil {
+		return err
+	}
+
+	if installSuggestSystem || installPreferSystem {
+		if version, err = resolveVersion(ctx, spec, version); err != nil {
+			return fmt.Errorf("failed to resolve version: %w", err)
+		}
+		name := installSystemPackage
+		if name == "" {
+			name = spec.GetName()
+		}
+		if pkg := pkgmgr.NewProber().FindVersion(ctx, name, version); pkg != nil {
+			if suggestSystemPackage(pkg, installPreferSystem) {
+				return nil
+			}
+		}
+	}
+
+	root := installToolCacheRoot()
+	if root == "" {
+		if binDir, err = resolveWritableBinDir(binDir, installDryRun); err != nil {
+			return err
+		}
+		if err := checkShadowing(ctx, spec, binDir, installDryRun); err != nil {
+			return err
+		}
+	}
+	lock := &toolLock{}
+	if !installDryRun {
+		lockDir := binDir
+		if root != "" {
+			lockDir = root
+		}
+		if lock, err = lockToolInstall(ctx, spec, lockDir); err != nil {
+			return err
+		}
+		defer lock.release()
+	}
+
+	var tag string
+	start := time.Now()
+	if root != "" {
+		binDir, tag, err = installToolCached(ctx, spec, version, root, installDryRun, src)
+	} else {
+		tag, err = installRelease(ctx, spec, version, binDir, installDryRun, src)
+	}
+	if !installDryRun {
+		metrics.RecordOperation("install", time.Since(start), err)
+		if tag == "" {
+			tag = version
+		}
+		notifyInstall(ctx, spec, tag, err)
+	}
+	if err != nil {
+		return err
+	}
+	if !installDryRun {
+		lock.recordReceipt(spec, tag, binDir)
+		if installProject {
+			if err := writeProjectEnv(binDir); err != nil {
+				return err
+			}
+		}
+	}
+	if installPrintEnv {
+		if err := printEnv(os.Stdout, []string{binDir}); err != nil {
+			return err
+		}
+		return printRuntimeEnv(os.Stdout, expandRuntimeEnv(spec, binDir))
+	}
+	return nil
+}
+
+// suggestSystemPackage logs that a package manager has the requested version and
+// reports whether the installation should be skipped in favor of it
+func suggestSystemPackage(pkg *pkgmgr.Package, preferSystem bool) bool {
+	if pkg.Installed {
+		log.Infof("%s %s is already installed by %s", pkg.Name, pkg.Version, pkg.Manager)
+	} else {
+		log.Infof("%s %s is available from %s: %s", pkg.Name, pkg.Version, pkg.Manager, pkg.InstallCommand)
+	}
+	if preferSystem {
+		log.Infof("Skipping installation in favor of the %s package (--prefer-system)", pkg.Manager)
+	}
+	return preferSystem
+}
+
+// installRelease resolves version, then downloads, verifies, and installs the
+// binaries of spec into binDir. src overrides where the asset comes from, e.g.
+// a local file used instead of downloading the asset. It returns the resolved
+// tag.
+func installRelease(ctx context.Context, spec *spec.InstallSpec, version, binDir string, dryRun bool, src assetSource) (string, error) {
+	// Get repo from spec
+	if spec.Repo == nil || *spec.Repo == "" {
+		return "", fmt.Errorf("GitHub repo not specified in config")
+	}
+	repo := *spec.Repo
+
 	// 4. Resolve version (latest if not specified)
-	resolvedVersion, err := resolveVersion(ctx, repo, version)
+	resolvedVersion, err := resolveVersion(ctx, spec, version)
 	if err != nil {
-		return fmt.Errorf("failed to resolve version: %w", err)
+		return "", fmt.Errorf("failed to resolve version: %w", err)
 	}
 
 	// Strip leading 'v' if present for the version number
@@ -150,45 +535,121 @@ func runInstall(cmd *cobra.Command, args []string) error {
 
 	// Phase 2: Asset Resolution and Download
 	// 5. Detect OS/Arch
-	osName, arch := detectPlatform(spec)
+	osName, arch := targetPlatform(spec)
 	log.Infof("Detected Platform: %s/%s", osName, arch)
 
 	// 6. Generate asset filename
-	generator := asset.NewFilenameGenerator(spec, versionNumber)
-	assetFilename, err := generator.GenerateFilename(osName, arch)
+	generator, err := newReleaseFilenameGenerator(ctx, spec, resolvedVersion)
+	if err != nil {
+		return "", err
+	}
+	generator.OSVersion = hostOSVersion(osName)
+	if generator.OSVersion != "" {
+		log.Debugf("Detected OS version: %s", generator.OSVersion)
+	}
+	candidates, err := generator.Candidates(osName, arch)
 	if err != nil {
-		return fmt.Errorf("failed to generate asset filename: %w", err)
+		return "", fmt.Errorf("failed to generate asset filename: %w", err)
+	}
+	log.Infof("Resolved asset filename: %s", candidates[0])
+	if len(candidates) > 1 {
+		log.Infof("Fallback asset filenames: %s", strings.Join(candidates[1:], ", "))
+	}
+	candidates = src.candidates(candidates)
+	assetFilename := candidates[0]
+
+	extraFiles, err := resolveExtraFiles(spec, generator, osName, arch)
+	if err != nil {
+		return "", err
+	}
+
+	commandName := spec.GetName()
+	if binaries := getBinariesForPlatform(spec, osName, arch); len(binaries) > 0 {
+		if name := binaries[0].GetName(); name != "" {
+			commandName = name
+		}
+		if installOutput == "" {
+			warnBreakingChanges(ctx, spec, filepath.Join(binDir, commandName), resolvedVersion)
+		}
 	}
-	log.Infof("Resolved asset filename: %s", assetFilename)
+	completions := resolveCompletions(spec, binDir, osName, commandName)
 
 	// 7. Construct download URL
-	assetURL := fmt.Sprintf("https://github.com/%s/releases/download/%s/%s", repo, resolvedVersion, assetFilename)
+	assetURL := src.assetURL(spec, resolvedVersion, assetFilename)
 	log.Infof("Asset URL: %s", assetURL)
 
-	if installDryRun {
-		// In dry-run mode, just print what would be done
-		log.Info("Dry run mode - would download from: " + assetURL)
-		return nil
+	if dryRun {
+		if src.file != "" {
+			log.Info("Dry run mode - would install from: " + src.file)
+		} else {
+			// In dry-run mode, just print what would be done
+			log.Info("Dry run mode - would download from: " + assetURL)
+		}
+		if spec.GetAttestation() != nil {
+			log.Infof("Dry run mode - would verify the attestation of %s in %s", assetFilename, repo)
+		}
+		for _, f := range extraFiles {
+			log.Infof("Dry run mode - would install extra file %s into %s", releaseDownloadURL(spec, resolvedVersion, f.filename), filepath.Join(binDir, f.dest))
+		}
+		for _, w := range spec.Wrappers {
+			log.Infof("Dry run mode - would install wrapper %s", filepath.Join(binDir, w.GetName()))
+		}
+		for _, c := range completions {
+			log.Infof("Dry run mode - would install %s completion %s to %s", c.Shell, c.Path, c.dest)
+		}
+		return resolvedVersion, nil
 	}
 
 	// 8. Download asset to temporary file
 	tmpDir, err := os.MkdirTemp("", "binst-")
 	if err != nil {
-		return fmt.Errorf("failed to create temp directory: %w", err)
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
 	}
 	defer os.RemoveAll(tmpDir)
 
 	assetPath := filepath.Join(tmpDir, assetFilename)
-	log.Infof("Downloading %s", assetURL)
-	if err := download(ctx, assetPath, assetURL); err != nil {
-		return fmt.Errorf("failed to download asset: %w", err)
+	verifier := newAssetVerifier(spec, resolvedVersion, osName, arch, generator)
+
+	// Try a delta update against a cached previous version first
+	var store *cache.Store
+	downloaded := false
+	if src == (assetSource{}) && spec.GetAsset().GetDelta() != nil {
+		if store, err = cache.New(); err != nil {
+			log.Warnf("Delta updates disabled: %v", err)
+		} else if err := downloadDelta(ctx, store, spec, generator, verifier, osName, arch, resolvedVersion, assetFilename, assetPath); err != nil {
+			log.Infof("Delta update not used, falling back to full download: %v", err)
+		} else {
+			downloaded = true
+		}
+	}
+
+	if !downloaded {
+		if assetFilename, err = src.download(ctx, spec, resolvedVersion, tmpDir, candidates); err != nil {
+			return "", err
+		}
+		assetPath = filepath.Join(tmpDir, assetFilename)
 	}
 
 	// Phase 3: Checksum Verification
-	log.Infof("Verifying checksum for %s", assetFilename)
-	verifier := checksums.NewVerifier(spec, resolvedVersion)
-	if err := verifier.VerifyFile(ctx, assetPath, assetFilename); err != nil {
-		return fmt.Errorf("checksum verification failed: %w", err)
+	verification := assetVerification{installSpec: spec, verifier: verifier, tag: resolvedVersion, tmpDir: tmpDir}
+	if err := verification.verify(ctx, assetFilename, assetPath); err != nil {
+		return "", err
+	}
+	if err := verifyLocked(spec, resolvedVersion, assetFilename, assetPath); err != nil {
+		return "", err
+	}
+	if err := verifyAttestation(ctx, spec, assetFilename, assetPath); err != nil {
+		return "", err
+	}
+	if err := downloadExtraFiles(ctx, extraFiles, verification, tmpDir); err != nil {
+		return "", err
+	}
+
+	// Keep the verified asset as the base for future delta updates
+	if store != nil {
+		if err := store.Put(repo, resolvedVersion, assetFilename, assetPath); err != nil {
+			log.Warnf("Failed to cache %s: %v", assetFilename, err)
+		}
 	}
 
 	// Phase 3: Archive Extraction
@@ -197,41 +658,31 @@ func runInstall(cmd *cobra.Command, args []string) error {
 		stripComponents = int(*spec.Unpack.StripComponents)
 	}
 
+	filter, err := archive.NewFilter(spec.Unpack.GetInclude(), spec.Unpack.GetExclude())
+	if err != nil {
+		return "", fmt.Errorf("invalid unpack filter: %w", err)
+	}
+
 	extractDir := filepath.Join(tmpDir, "extracted")
-	extractor := archive.NewExtractor(stripComponents)
+	extractor := archive.NewExtractor(stripComponents).WithFilter(filter)
 	log.Infof("Extracting %s", assetFilename)
 	if err := extractor.Extract(assetPath, extractDir); err != nil {
-		return fmt.Errorf("failed to extract archive: %w", err)
+		return "", fmt.Errorf("failed to extract archive: %w", err)
 	}
 
 	// Phase 3: Binary Selection
 	binaries, err := selectBinaries(spec, osName, arch, extractDir, assetFilename)
 	if err != nil {
-		return fmt.Errorf("failed to select binaries: %w", err)
+		return "", fmt.Errorf("failed to select binaries: %w", err)
 	}
 	for _, binary := range binaries {
 		log.Infof("Selected binary: %s (from %s)", binary.Name, binary.Path)
 	}
 
 	// Phase 4: Installation
-	// Determine installation directory
-	binDir := installBinDir
-	if binDir == "" {
-		// Check $BINSTALLER_BIN environment variable first
-		binDir = os.Getenv("BINSTALLER_BIN")
-		if binDir == "" {
-			// Default to ~/.local/bin
-			homeDir, err := os.UserHomeDir()
-			if err != nil {
-				return fmt.Errorf("failed to get home directory: %w", err)
-			}
-			binDir = filepath.Join(homeDir, ".local", "bin")
-		}
-	}
-
 	// Create bin directory if it doesn't exist
 	if err := os.MkdirAll(binDir, 0755); err != nil {
-		return fmt.Errorf("failed to create bin directory: %w", err)
+		return "", fmt.Errorf("failed to create bin directory: %w", err)
 	}
 
 	// Install all binaries
@@ -240,15 +691,114 @@ func runInstall(cmd *cobra.Command, args []string) error {
 		srcPath := filepath.Join(extractDir, binary.Path)
 
 		log.Infof("Installing %s to %s", binary.Name, destPath)
-		if err := installBinary(srcPath, destPath); err != nil {
-			return fmt.Errorf("failed to install binary %s: %w", binary.Name, err)
+		if err := installBinaryMode(srcPath, destPath, binaryMode(osName)); err != nil {
+			return "", fmt.Errorf("failed to install binary %s: %w", binary.Name, err)
 		}
 	}
 
+	if err := installExtraFiles(extraFiles, binDir); err != nil {
+		return "", err
+	}
+	if err := installWrappers(spec, tmpDir, binDir, osName); err != nil {
+		return "", err
+	}
+	if err := installCompletionFiles(completions, extractDir); err != nil {
+		return "", err
+	}
+
 	log.Infof("Successfully installed %s %s to %s", *spec.Name, versionNumber, binDir)
+	return resolvedVersion, nil
+}
+
+// assetVerification verifies the downloaded release files of a tag
+type assetVerification struct {
+	installSpec *spec.InstallSpec
+	verifier    *checksums.Verifier
+	tag         string
+	tmpDir      string
+}
+
+// verify checks a downloaded release file against its checksum. Unverified
+// files are compared with a second download when checksums.double_fetch is
+// enabled.
+func (v assetVerification) verify(ctx context.Context, filename, path string) error {
+	log.Infof("Verifying checksum for %s", filename)
+	verified, err := v.verifier.Verify(ctx, path, filename)
+	if err != nil {
+		metrics.RecordVerification(metrics.VerificationFailed)
+		return fmt.Errorf("checksum verification failed: %w", err)
+	}
+	if verified {
+		metrics.RecordVerification(metrics.VerificationVerified)
+	} else {
+		metrics.RecordVerification(metrics.VerificationUnverified)
+	}
+	if cfg := v.installSpec.GetChecksums().GetDoubleFetch(); !verified && cfg.GetEnabled() {
+		if err := doubleFetch(ctx, cfg, v.installSpec, v.tag, filename, path, v.tmpDir); err != nil {
+			return fmt.Errorf("double-fetch comparison failed: %w", err)
+		}
+	}
 	return nil
 }
 
+// downloadAssetCandidates downloads the first candidate asset that exists in the
+// release into dir and returns its filename
+func downloadAssetCandidates(ctx context.Context, installSpec *spec.InstallSpec, tag, dir string, candidates []string) (string, error) {
+	for i, filename := range candidates {
+		url := releaseDownloadURL(installSpec, tag, filename)
+		log.Infof("Downloading %s", url)
+		err := download(ctx, installSpec, filepath.Join(dir, filename), url)
+		if err == nil {
+			return filename, nil
+		}
+		if !errors.Is(err, errAssetNotFound) || i == len(candidates)-1 {
+			return "", err
+		}
+		log.Infof("%s not found in release, trying %s", filename, candidates[i+1])
+	}
+	return "", fmt.Errorf("no asset candidates")
+}
+
+// allowWeakHash reports whether md5 and sha1 checksums are accepted, via
+// --allow-weak-hash or $BINSTALLER_ALLOW_WEAK_HASH
+func allowWeakHash() bool {
+	env := os.Getenv("BINSTALLER_ALLOW_WEAK_HASH")
+	return installAllowWeak || env == "1" || env == "true"
+}
+
+// localAssetFilename returns the release filename a local asset stands for: its
+// own name when it is one of the candidates, otherwise the primary candidate, as
+// browsers may rename downloads (e.g. "tool (1).tar.gz")
+func localAssetFilename(localPath string, candidates []string) string {
+	name := filepath.Base(localPath)
+	for _, candidate := range candidates {
+		if name == candidate {
+			return name
+		}
+	}
+	log.Warnf("%s does not match the release asset name; treating it as %s", name, candidates[0])
+	return candidates[0]
+}
+
+// copyFile copies src to dest
+func copyFile(src, dest string) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+
+	destFile, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(destFile, srcFile); err != nil {
+		destFile.Close()
+		return err
+	}
+	return destFile.Close()
+}
+
 // detectPlatform detects the current OS and architecture, matching shell script logic
 func detectPlatform(spec *spec.InstallSpec) (string, string) {
 	osName := detectOS()
@@ -298,9 +848,35 @@ func isRosetta2Available() bool {
 	return err == nil
 }
 
-// download downloads a file without progress reporting
-func download(ctx context.Context, destPath, url string) error {
-	client := httpclient.NewGitHubClient()
+// downloadRetryPolicy returns the retry policy of downloads for installSpec:
+// the default one with the retries and timeouts of its download settings,
+// and the retries of BINSTALLER_HTTP_RETRIES taking precedence
+func downloadRetryPolicy(installSpec *spec.InstallSpec) httpclient.RetryPolicy {
+	policy := httpclient.DefaultRetryPolicy
+	settings := installSpec.GetDownload()
+	if settings != nil && settings.Retries != nil {
+		policy.Attempts = int(settings.GetRetries()) + 1
+	}
+	if delay := time.Duration(settings.GetRetryDelay()) * time.Second; delay > 0 {
+		policy.MinBackoff = delay
+		policy.MaxBackoff = max(policy.MaxBackoff, delay)
+	}
+	policy.Timeout = time.Duration(settings.GetTimeout()) * time.Second
+	return policy.WithEnv()
+}
+
+// download downloads a file of installSpec, retrying as its download settings
+// configure
+func download(ctx context.Context, installSpec *spec.InstallSpec, destPath, url string) error {
+	return downloadWithClient(ctx, httpclient.NewGitHubClientWithPolicy(downloadRetryPolicy(installSpec)), destPath, url)
+}
+
+// downloadWithClient downloads url to destPath with client
+func downloadWithClient(ctx context.Context, client *http.Client, destPath, url string) (err error) {
+	var n int64
+	start := time.Now()
+	defer func() { metrics.RecordDownload(time.Since(start), n, err) }()
+
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
@@ -312,6 +888,9 @@ func download(ctx context.Context, destPath, url string) error {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("download failed with status %d: %w", resp.StatusCode, errAssetNotFound)
+	}
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		return fmt.Errorf("download failed with status %d: %s", resp.StatusCode, string(body))
@@ -324,8 +903,13 @@ func download(ctx context.Context, destPath, url string) error {
 	}
 	defer out.Close()
 
-	// Copy without progress
-	_, err = io.Copy(out, resp.Body)
+	// Show the progress of large downloads on terminals
+	var body io.Reader = resp.Body
+	if progress := newProgressWriter(filepath.Base(destPath), resp.ContentLength); progress != nil {
+		body = io.TeeReader(resp.Body, progress)
+		defer progress.finish()
+	}
+	n, err = io.Copy(out, body)
 	if err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
@@ -436,11 +1020,30 @@ func matchesRule(when *spec.When, osName, arch string) bool {
 		return false
 	}
 
+	// Check OS version match
+	if when.OSVersion != nil && !asset.MatchOSVersion(*when.OSVersion, hostOSVersion(osName)) {
+		return false
+	}
+
 	return true
 }
 
+// hostOSVersion returns the OS version matched by when.os_version. It is only
+// detected for the host OS; BINSTALLER_OS_VERSION overrides it for any OS.
+func hostOSVersion(osName string) string {
+	if osName != runtime.GOOS {
+		return os.Getenv("BINSTALLER_OS_VERSION")
+	}
+	return asset.DetectOSVersion(osName)
+}
+
 // installBinary copies the binary to its destination atomically and makes it executable
 func installBinary(src, dest string) error {
+	return installBinaryMode(src, dest, 0755)
+}
+
+// installBinaryMode copies the binary to its destination atomically with mode
+func installBinaryMode(src, dest string, mode os.FileMode) error {
 	// Open source file
 	srcFile, err := os.Open(src)
 	if err != nil {
@@ -476,7 +1079,7 @@ func installBinary(src, dest string) error {
 	tempFile = nil // Prevent double cleanup
 
 	// Set executable permissions on temp file
-	if err := os.Chmod(tempPath, 0755); err != nil {
+	if err := os.Chmod(tempPath, mode); err != nil {
 		return fmt.Errorf("failed to make file executable: %w", err)
 	}
 
@@ -519,3 +1122,42 @@ func copyAndRemove(src, dest string) error {
 	// Remove source
 	return os.Remove(src)
 }
+
+// resolveInstallBinDir returns the installation directory: --bin-dir, then the
+// project bin directory with --project, then a default_bin_dir customized by
+// the spec or its overlays, then $BINSTALLER_BIN, then ~/.local/bin
+func resolveInstallBinDir(installSpec *spec.InstallSpec) (string, error) {
+	if installBinDir != "" {
+		return installBinDir, nil
+	}
+	if installProject {
+		return projectBinDir()
+	}
+
+	if tmpl := spec.StringValue(installSpec.DefaultBinDir); tmpl != "" && tmpl != spec.DefaultBinDirValue {
+		env := os.Environ()
+		if os.Getenv("HOME") == "" {
+			if homeDir, err := os.UserHomeDir(); err == nil {
+				env = append(env, "HOME="+homeDir)
+			}
+		}
+		binDir, err := interpolate.Interpolate(interpolate.NewSliceEnv(env), tmpl)
+		if err != nil {
+			return "", fmt.Errorf("failed to interpolate default_bin_dir %q: %w", tmpl, err)
+		}
+		if binDir != "" {
+			return binDir, nil
+		}
+	}
+
+	// Check $BINSTALLER_BIN environment variable first
+	if binDir := os.Getenv("BINSTALLER_BIN"); binDir != "" {
+		return binDir, nil
+	}
+	// Default to ~/.local/bin
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".local", "bin"), nil
+}
@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/metrics"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// defaultInstallStateFile records the progress of 'binst install --all' so a
+// re-run only retries the tools that failed
+var defaultInstallStateFile = filepath.Join(ProjectToolsDir, ".install-state.json")
+
+// Tool install statuses
+const (
+	toolStatusInstalled = "✓ INSTALLED"
+	toolStatusSkipped   = "↷ DONE"
+	toolStatusFailed    = "✗ FAILED"
+	toolStatusPending   = "- NOT RUN"
+)
+
+// installState is the resumable state of an install of many tools
+type installState struct {
+	// Tools maps spec files to the outcome of their last install
+	Tools map[string]installStateEntry `json:"tools"`
+}
+
+// installStateEntry is the outcome of installing one tool
+type installStateEntry struct {
+	Version   string    `json:"version"`
+	BinDir    string    `json:"bin_dir"`
+	Tag       string    `json:"tag,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// toolResult is a row of the install summary
+type toolResult struct {
+	file    string
+	name    string
+	version string
+	binDir  string
+	// runtimeEnv is the runtime_env of the tool expanded for binDir
+	runtimeEnv map[string]string
+	status     string
+	err        error
+}
+
+// loadInstallState reads the state file, returning an empty state when it does not exist
+func loadInstallState(path string) (*installState, error) {
+	state := &installState{Tools: make(map[string]installStateEntry)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read install state: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse install state %s: %w", path, err)
+	}
+	if state.Tools == nil {
+		state.Tools = make(map[string]installStateEntry)
+	}
+	return state, nil
+}
+
+// save writes the state file
+func (s *installState) save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// installAll installs the tool of every spec file. Tools the state file records as
+// installed with the same version and bin dir are skipped, so re-running after a
+// failure only retries what failed. Without keepGoing the first failure stops the
+// run. The state file is removed once every tool is installed. It returns the
+// result of every tool.
+func installAll(ctx context.Context, w io.Writer, files []string, statePath string, keepGoing, dryRun bool) ([]toolResult, error) {
+	state, err := loadInstallState(statePath)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]toolResult, 0, len(files))
+	failed := 0
+	for _, file := range files {
+		if failed > 0 && !keepGoing {
+			results = append(results, toolResult{file: file, status: toolStatusPending})
+			continue
+		}
+		result := installTool(ctx, file, state, dryRun)
+		if result.status == toolStatusFailed {
+			failed++
+			log.WithError(result.err).Errorf("Failed to install %s", file)
+		}
+		results = append(results, result)
+		if !dryRun {
+			if err := state.save(statePath); err != nil {
+				return results, fmt.Errorf("failed to save install state: %w", err)
+			}
+		}
+	}
+
+	printToolResults(w, results)
+
+	if failed > 0 {
+		if !dryRun {
+			log.Infof("Progress saved to %s; re-run to retry the failed tools", statePath)
+		}
+		return results, fmt.Errorf("%d of %d tools failed to install", failed, len(files))
+	}
+	if !dryRun {
+		if err := os.Remove(statePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return results, fmt.Errorf("failed to remove install state: %w", err)
+		}
+	}
+	return results, nil
+}
+
+// installTool installs the tool of one spec file and records the outcome in state
+func installTool(ctx context.Context, file string, state *installState, dryRun bool) toolResult {
+	result := toolResult{file: file}
+	installSpec, err := loadInstallSpecWithOverlays(ctx, file, !installNoOverlays)
+	if err != nil {
+		result.status, result.err = toolStatusFailed, err
+		delete(state.Tools, file)
+		return result
+	}
+	installSpec.SetDefaults()
+	ctx = specContext(ctx, installSpec)
+	result.name = installSpec.GetName()
+	result.version = spec.StringValue(installSpec.DefaultVersion)
+
+	if root := installToolCacheRoot(); root != "" {
+		// The tool cache already skips installed versions
+		log.Infof("Installing %s (%s) into the tool cache...", result.name, file)
+		lock := &toolLock{}
+		if !dryRun {
+			if lock, err = lockToolInstall(ctx, installSpec, root); err != nil {
+				result.status, result.err = toolStatusFailed, err
+				return result
+			}
+			defer lock.release()
+		}
+		start := time.Now()
+		binDir, tag, err := installToolCached(ctx, installSpec, result.version, root, dryRun, assetSource{})
+		entry := installStateEntry{Version: result.version, BinDir: binDir, Tag: tag, UpdatedAt: time.Now().UTC()}
+		if err != nil {
+			result.status, result.err = toolStatusFailed, err
+			entry.Error = err.Error()
+		} else {
+			result.version, result.binDir, result.status = tag, binDir, toolStatusInstalled
+			result.runtimeEnv = expandRuntimeEnv(installSpec, binDir)
+			if !dryRun {
+				lock.recordReceipt(installSpec, tag, binDir)
+			}
+		}
+		state.Tools[file] = entry
+		if !dryRun {
+			metrics.RecordOperation("install", time.Since(start), err)
+			notifyInstall(ctx, installSpec, result.version, err)
+		}
+		return result
+	}
+
+	binDir, err := resolveInstallBinDir(installSpec)
+	if err == nil {
+		binDir, err = resolveWritableBinDir(binDir, dryRun)
+	}
+	if err == nil {
+		err = checkShadowing(ctx, installSpec, binDir, dryRun)
+	}
+	if err != nil {
+		result.status, result.err = toolStatusFailed, err
+		return result
+	}
+	result.binDir = binDir
+	result.runtimeEnv = expandRuntimeEnv(installSpec, binDir)
+
+	if prev, ok := state.Tools[file]; ok && prev.Error == "" && prev.Version == result.version && prev.BinDir == binDir {
+		log.Infof("Skipping %s: installed %s by a previous run", result.name, prev.Tag)
+		result.version, result.status = prev.Tag, toolStatusSkipped
+		return result
+	}
+
+	log.Infof("Installing %s (%s)...", result.name, file)
+	lock := &toolLock{}
+	if !dryRun {
+		if lock, err = lockToolInstall(ctx, installSpec, binDir); err != nil {
+			result.status, result.err = toolStatusFailed, err
+			return result
+		}
+		defer lock.release()
+	}
+	start := time.Now()
+	tag, err := installRelease(ctx, installSpec, result.version, binDir, dryRun, assetSource{})
+	entry := installStateEntry{Version: result.version, BinDir: binDir, Tag: tag, UpdatedAt: time.Now().UTC()}
+	if err != nil {
+		result.status, result.err = toolStatusFailed, err
+		entry.Error = err.Error()
+	} else {
+		result.version, result.status = tag, toolStatusInstalled
+		if !dryRun {
+			lock.recordReceipt(installSpec, tag, binDir)
+		}
+	}
+	state.Tools[file] = entry
+	if !dryRun {
+		metrics.RecordOperation("install", time.Since(start), err)
+		notifyInstall(ctx, installSpec, result.version, err)
+	}
+	return result
+}
+
+// printToolResults prints the per-tool summary table
+func printToolResults(w io.Writer, results []toolResult) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TOOL\tVERSION\tSTATUS\tSPEC\tERROR")
+	fmt.Fprintln(tw, "----\t-------\t------\t----\t-----")
+	for _, r := range results {
+		name, version, detail := r.name, r.version, ""
+		if name == "" {
+			name = "-"
+		}
+		if version == "" {
+			version = "-"
+		}
+		if r.err != nil {
+			detail = r.err.Error()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", name, version, r.status, r.file, detail)
+	}
+	tw.Flush()
+}
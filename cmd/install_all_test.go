@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/receipt"
+)
+
+func TestInstallAll(t *testing.T) {
+	t.Setenv("BINSTALLER_OS_VERSION", "")
+	receiptsDir := t.TempDir()
+	t.Setenv("BINSTALLER_RECEIPTS_DIR", receiptsDir)
+	content := "#!/bin/sh\necho tool\n"
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(content)))
+
+	var mu sync.Mutex
+	available := map[string]bool{"good": true}
+	downloads := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		name := strings.Split(strings.TrimPrefix(r.URL.Path, "/owner/"), "/")[0]
+		downloads[name]++
+		if !available[name] {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(content))
+	}))
+	defer server.Close()
+	oldURL := gitHubDownloadBaseURL
+	gitHubDownloadBaseURL = server.URL
+	defer func() { gitHubDownloadBaseURL = oldURL }()
+
+	oldBinDir, oldNoOverlays := installBinDir, installNoOverlays
+	installBinDir, installNoOverlays = t.TempDir(), true
+	defer func() { installBinDir, installNoOverlays = oldBinDir, oldNoOverlays }()
+
+	dir := t.TempDir()
+	var files []string
+	for _, name := range []string{"bad", "good"} {
+		filename := fmt.Sprintf("%s_1.0.0_%s_%s", name, runtime.GOOS, runtime.GOARCH)
+		file := filepath.Join(dir, name+".yml")
+		writeTestFile(t, file, fmt.Sprintf(`repo: owner/%s
+default_version: v1.0.0
+asset:
+  template: ${NAME}_${VERSION}_${OS}_${ARCH}
+checksums:
+  embedded_checksums:
+    v1.0.0:
+      - filename: %s
+        hash: %s
+runtime_env:
+  TOOL_HOME: ${INSTALL_DIR}/share/%s
+`, name, filename, hash, name), 0644)
+		files = append(files, file)
+	}
+	statePath := filepath.Join(dir, "state.json")
+
+	// Without --keep-going the first failure stops the run
+	var out bytes.Buffer
+	if _, err := installAll(context.Background(), &out, files, statePath, false, false); err == nil {
+		t.Fatal("installAll() succeeded, want the bad tool to fail")
+	}
+	if !strings.Contains(out.String(), toolStatusPending) || downloads["good"] != 0 {
+		t.Errorf("installAll() without keep-going ran past the failure:\n%s", out.String())
+	}
+
+	out.Reset()
+	_, err := installAll(context.Background(), &out, files, statePath, true, false)
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 tools") {
+		t.Fatalf("installAll(keep-going) error = %v, want 1 of 2 tools failed", err)
+	}
+	if !strings.Contains(out.String(), toolStatusFailed) || !strings.Contains(out.String(), toolStatusInstalled) {
+		t.Errorf("installAll() summary lacks the outcomes:\n%s", out.String())
+	}
+	state, err := loadInstallState(statePath)
+	if err != nil || state.Tools[files[1]].Tag != "v1.0.0" || state.Tools[files[0]].Error == "" {
+		t.Fatalf("install state = %+v, %v", state, err)
+	}
+	r, err := (&receipt.Store{Dir: receiptsDir}).Read("good")
+	if err != nil || r == nil {
+		t.Fatalf("receipt of good = %v, %v", r, err)
+	}
+	if r.Tag != "v1.0.0" || r.BinDir != installBinDir || r.RuntimeEnv["TOOL_HOME"] != filepath.Join(filepath.Dir(installBinDir), "share", "good") {
+		t.Errorf("receipt of good = %+v", r)
+	}
+
+	// A re-run only retries the failed tool and removes the state once all are installed
+	available["bad"] = true
+	out.Reset()
+	if _, err := installAll(context.Background(), &out, files, statePath, true, false); err != nil {
+		t.Fatalf("installAll() re-run error = %v", err)
+	}
+	if downloads["good"] != 1 || !strings.Contains(out.String(), toolStatusSkipped) {
+		t.Errorf("re-run downloaded good %d times, want it skipped:\n%s", downloads["good"], out.String())
+	}
+	if _, err := os.Stat(filepath.Join(installBinDir, "bad")); err != nil {
+		t.Errorf("bad tool not installed: %v", err)
+	}
+	if _, err := os.Stat(statePath); !os.IsNotExist(err) {
+		t.Errorf("state file still exists after every tool was installed: %v", err)
+	}
+}
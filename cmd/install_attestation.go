@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/attestation"
+	"github.com/binary-install/binstaller/pkg/checksums"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// verifyAttestation verifies the GitHub artifact attestation of the asset at
+// path when the spec configures attestation. A missing attestation is only
+// warned about when attestation.required is false.
+func verifyAttestation(ctx context.Context, installSpec *spec.InstallSpec, filename, path string) error {
+	cfg := installSpec.GetAttestation()
+	if cfg == nil {
+		return nil
+	}
+	if err := spec.ValidateAttestationHost(installSpec); err != nil {
+		return err
+	}
+	digest, err := checksums.ComputeHash(path, "sha256")
+	if err != nil {
+		return fmt.Errorf("failed to hash %s: %w", filename, err)
+	}
+
+	log.Infof("Verifying attestation for %s", filename)
+	verifier := &attestation.Verifier{
+		Repo:           installSpec.GetRepo(),
+		SignerWorkflow: cfg.GetSignerWorkflow(),
+		PredicateType:  cfg.GetPredicateType(),
+		APIBaseURL:     gitHubAPIURL(installSpec),
+	}
+	err = verifier.Verify(ctx, digest)
+	if errors.Is(err, attestation.ErrNoAttestation) && !cfg.GetRequired() {
+		log.Warnf("Installing %s without attestation: %v", filename, err)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("attestation verification failed for %s: %w", filename, err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+func TestInstallReleaseAttestation(t *testing.T) {
+	t.Setenv("BINSTALLER_OS_VERSION", "")
+	content := []byte("#!/bin/sh\necho tool\n")
+	filename := fmt.Sprintf("tool_1.0.0_%s_%s", runtime.GOOS, runtime.GOARCH)
+	digest := fmt.Sprintf("%x", sha256.Sum256(content))
+
+	var attestationRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/owner/tool/releases/download/v1.0.0/" + filename:
+			w.Write(content)
+		case "/repos/owner/tool/attestations/sha256:" + digest:
+			attestationRequests++
+			w.Write([]byte(`{"attestations":[]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	oldDownloadURL, oldAPIURL := gitHubDownloadBaseURL, gitHubAPIBaseURL
+	gitHubDownloadBaseURL, gitHubAPIBaseURL = server.URL, server.URL
+	defer func() { gitHubDownloadBaseURL, gitHubAPIBaseURL = oldDownloadURL, oldAPIURL }()
+
+	newSpec := func(attestation *spec.Attestation) *spec.InstallSpec {
+		s := spec.NewInstallSpec("owner/tool").
+			WithAsset(spec.NewAsset("${NAME}_${VERSION}_${OS}_${ARCH}")).
+			WithChecksums(spec.NewChecksums("").WithEmbeddedChecksum("v1.0.0", filename, digest)).
+			WithAttestation(attestation)
+		s.SetDefaults()
+		return s
+	}
+
+	t.Run("required", func(t *testing.T) {
+		binDir := t.TempDir()
+		_, err := installRelease(context.Background(), newSpec(spec.NewAttestation("")), "v1.0.0", binDir, false, assetSource{})
+		if err == nil || !strings.Contains(err.Error(), "no attestation found") {
+			t.Fatalf("installRelease() error = %v, want no attestation found", err)
+		}
+		if _, err := os.Stat(filepath.Join(binDir, "tool")); !os.IsNotExist(err) {
+			t.Errorf("binary should not be installed without an attestation")
+		}
+	})
+
+	t.Run("optional", func(t *testing.T) {
+		binDir := t.TempDir()
+		_, err := installRelease(context.Background(), newSpec(spec.NewAttestation("").WithRequired(false)), "v1.0.0", binDir, false, assetSource{})
+		if err != nil {
+			t.Fatalf("installRelease() error = %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(binDir, "tool")); err != nil {
+			t.Errorf("binary should be installed when attestations are optional: %v", err)
+		}
+	})
+
+	if attestationRequests != 2 {
+		t.Errorf("attestations were fetched %d times, want 2", attestationRequests)
+	}
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// installUpgradeFrom is the version being upgraded, overriding the version
+// reported by the installed binary
+var installUpgradeFrom string
+
+// versionProbeTimeout bounds running the installed binary with --version
+const versionProbeTimeout = 5 * time.Second
+
+// maxBreakingChangesSize bounds the list fetched from breaking_changes_url
+const maxBreakingChangesSize = 1 << 20
+
+// installedVersionPattern matches the first version number in --version output
+var installedVersionPattern = regexp.MustCompile(`\d+\.\d+(\.\d+)?`)
+
+// warnBreakingChanges logs a warning with the migration notes of every
+// breaking change between the version installed at binaryPath and tag. Nothing
+// is checked for specs without breaking changes or fresh installs, and
+// failures only skip the warnings.
+func warnBreakingChanges(ctx context.Context, installSpec *spec.InstallSpec, binaryPath, tag string) {
+	if len(installSpec.BreakingChanges) == 0 && installSpec.GetBreakingChangesURL() == "" {
+		return
+	}
+	from := installUpgradeFrom
+	if from == "" {
+		from = installedVersion(ctx, binaryPath)
+	}
+	if from == "" {
+		return
+	}
+
+	changes := append([]spec.BreakingChangeElement{}, installSpec.BreakingChanges...)
+	if target := installSpec.GetBreakingChangesURL(); target != "" {
+		fetched, err := fetchBreakingChanges(ctx, target)
+		if err != nil {
+			log.Warnf("Failed to fetch breaking changes from %s: %v", target, err)
+		}
+		changes = append(changes, fetched...)
+	}
+	crossed, err := spec.BreakingChangesBetween(changes, from, tag)
+	if err != nil {
+		log.Debugf("Not checking breaking changes: %v", err)
+		return
+	}
+	for _, change := range crossed {
+		log.Warnf("Upgrading %s from %s to %s crosses a breaking change in %s", installSpec.GetName(), from, tag, change.GetVersion())
+		if notes := change.GetNotes(); notes != "" {
+			log.Warnf("Migration notes: %s", notes)
+		}
+	}
+}
+
+// installedVersion runs the binary at path with --version and returns the
+// version it reports, or "" when it is not installed or reports none
+func installedVersion(ctx context.Context, path string) string {
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(ctx, versionProbeTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, "--version").Output()
+	if err != nil {
+		log.Debugf("Failed to get the installed version of %s: %v", filepath.Base(path), err)
+		return ""
+	}
+	return installedVersionPattern.FindString(string(out))
+}
+
+// fetchBreakingChanges downloads and parses the list of breaking changes at target
+func fetchBreakingChanges(ctx context.Context, target string) ([]spec.BreakingChangeElement, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBreakingChangesSize))
+	if err != nil {
+		return nil, err
+	}
+	return spec.ParseBreakingChanges(data)
+}
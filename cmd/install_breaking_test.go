@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/memory"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+func TestWarnBreakingChanges(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the installed binary")
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("- version: v3.0.0\n  notes: https://example.com/v3\n"))
+	}))
+	defer server.Close()
+
+	bin := filepath.Join(t.TempDir(), "tool")
+	if err := os.WriteFile(bin, []byte("#!/bin/sh\necho 'tool version 1.4.2'\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := installedVersion(context.Background(), bin); got != "1.4.2" {
+		t.Fatalf("installedVersion() = %q, want 1.4.2", got)
+	}
+
+	previous := log.Log.(*log.Logger).Handler
+	handler := memory.New()
+	log.SetHandler(handler)
+	defer log.SetHandler(previous)
+
+	installSpec := spec.NewInstallSpec("owner/tool").WithBreakingChange("v2.0.0", "https://example.com/v2")
+	installSpec.SetDefaults()
+	installSpec.BreakingChangesURL = spec.StringPtr(server.URL + "/breaking.yml")
+
+	warnings := func() []string {
+		var messages []string
+		for _, entry := range handler.Entries {
+			if entry.Level == log.WarnLevel {
+				messages = append(messages, entry.Message)
+			}
+		}
+		handler.Entries = nil
+		return messages
+	}
+
+	warnBreakingChanges(context.Background(), installSpec, bin, "v3.1.0")
+	want := []string{
+		"Upgrading tool from 1.4.2 to v3.1.0 crosses a breaking change in v2.0.0",
+		"Migration notes: https://example.com/v2",
+		"Upgrading tool from 1.4.2 to v3.1.0 crosses a breaking change in v3.0.0",
+		"Migration notes: https://example.com/v3",
+	}
+	if got := warnings(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("warnings = %q, want %q", got, want)
+	}
+
+	// --upgrade-from overrides the installed version
+	installUpgradeFrom = "v2.0.0"
+	defer func() { installUpgradeFrom = "" }()
+	warnBreakingChanges(context.Background(), installSpec, bin, "v2.9.0")
+	if got := warnings(); len(got) != 0 {
+		t.Errorf("upgrade within v2 warned: %q", got)
+	}
+
+	// Fresh installs are not checked
+	installUpgradeFrom = ""
+	warnBreakingChanges(context.Background(), installSpec, filepath.Join(t.TempDir(), "tool"), "v3.1.0")
+	if got := warnings(); len(got) != 0 {
+		t.Errorf("fresh install warned: %q", got)
+	}
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// installCompletions is the --completions flag of binst install
+var installCompletions bool
+
+// completionTarget is a completion script of the archive and where it is installed
+type completionTarget struct {
+	spec.CompletionFile
+	dest string
+}
+
+// resolveCompletions returns the completion scripts to install for the command
+// name, with their destinations in the share directory next to binDir. None are
+// installed without --completions or on Windows.
+func resolveCompletions(installSpec *spec.InstallSpec, binDir, osName, name string) []completionTarget {
+	files := installSpec.Completions.Files()
+	if !installCompletions || len(files) == 0 {
+		if installCompletions {
+			log.Infof("%s ships no shell completions", installSpec.GetName())
+		}
+		return nil
+	}
+	if osName == "windows" {
+		log.Infof("Skipping %d shell completion(s): completions are not installed on windows", len(files))
+		return nil
+	}
+	prefix := filepath.Dir(binDir)
+	targets := make([]completionTarget, 0, len(files))
+	for _, f := range files {
+		targets = append(targets, completionTarget{CompletionFile: f, dest: filepath.Join(prefix, filepath.FromSlash(spec.CompletionDest(f.Shell, name)))})
+	}
+	return targets
+}
+
+// installCompletionFiles copies the completion scripts from the extracted
+// archive to their destinations. Scripts missing from the archive are skipped
+// with a warning since the binaries are already installed.
+func installCompletionFiles(targets []completionTarget, extractDir string) error {
+	for _, t := range targets {
+		src := filepath.Join(extractDir, filepath.FromSlash(t.Path))
+		if info, err := os.Stat(src); err != nil || !info.Mode().IsRegular() {
+			log.Warnf("Skipping %s completion: %s not found in the asset", t.Shell, t.Path)
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(t.dest), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s completion: %w", t.Shell, err)
+		}
+		log.Infof("Installing %s completion to %s", t.Shell, t.dest)
+		if err := copyFile(src, t.dest); err != nil {
+			return fmt.Errorf("failed to install %s completion: %w", t.Shell, err)
+		}
+		if err := os.Chmod(t.dest, 0644); err != nil {
+			return fmt.Errorf("failed to install %s completion: %w", t.Shell, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+func TestResolveCompletions(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithCompletion("fish", "completions/tool.fish").
+		WithCompletion("bash", "completions/tool.bash")
+	installSpec.SetDefaults()
+	t.Cleanup(func() { installCompletions = false })
+
+	installCompletions = false
+	if got := resolveCompletions(installSpec, "/home/me/.local/bin", "linux", "tool"); got != nil {
+		t.Errorf("resolveCompletions() without --completions = %v, want none", got)
+	}
+
+	installCompletions = true
+	if got := resolveCompletions(installSpec, `C:\tools\bin`, "windows", "tool"); got != nil {
+		t.Errorf("resolveCompletions() on windows = %v, want none", got)
+	}
+
+	got := resolveCompletions(installSpec, "/home/me/.local/bin", "linux", "tool")
+	want := []completionTarget{
+		{CompletionFile: spec.CompletionFile{Shell: "bash", Path: "completions/tool.bash"}, dest: filepath.FromSlash("/home/me/.local/share/bash-completion/completions/tool")},
+		{CompletionFile: spec.CompletionFile{Shell: "fish", Path: "completions/tool.fish"}, dest: filepath.FromSlash("/home/me/.local/share/fish/vendor_completions.d/tool.fish")},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("resolveCompletions() = %v, want %v", got, want)
+	}
+}
+
+func TestInstallReleaseCompletions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("completions are not installed on windows")
+	}
+	t.Setenv("BINSTALLER_OS_VERSION", "")
+	t.Cleanup(func() { installCompletions = false })
+
+	var data bytes.Buffer
+	gz := gzip.NewWriter(&data)
+	tw := tar.NewWriter(gz)
+	for name, content := range map[string]string{
+		"tool":                 "#!/bin/sh\necho tool\n",
+		"completions/tool.zsh": "#compdef tool\n",
+	} {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+
+	assetName := fmt.Sprintf("tool_1.0.0_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if filepath.Base(r.URL.Path) != assetName {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data.Bytes())
+	}))
+	defer server.Close()
+	oldURL := gitHubDownloadBaseURL
+	gitHubDownloadBaseURL = server.URL
+	defer func() { gitHubDownloadBaseURL = oldURL }()
+
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${VERSION}_${OS}_${ARCH}${EXT}").WithDefaultExtension(".tar.gz")).
+		WithCompletion("zsh", "completions/tool.zsh").
+		WithCompletion("bash", "completions/tool.bash")
+	installSpec.SetDefaults()
+
+	prefix := t.TempDir()
+	binDir := filepath.Join(prefix, "bin")
+	installCompletions = true
+	if _, err := installRelease(context.Background(), installSpec, "v1.0.0", binDir, false, assetSource{}); err != nil {
+		t.Fatalf("installRelease() error = %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(prefix, "share", "zsh", "site-functions", "_tool"))
+	if err != nil || string(got) != "#compdef tool\n" {
+		t.Errorf("zsh completion = %q, %v, want the script of the archive", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(prefix, "share", "bash-completion", "completions", "tool")); !os.IsNotExist(err) {
+		t.Errorf("bash completion missing from the archive was installed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(binDir, "tool")); err != nil {
+		t.Errorf("binary not installed: %v", err)
+	}
+}
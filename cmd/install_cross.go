@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// targetPlatform returns the platform to install the binaries of: --os and
+// --arch, falling back to the detected host platform
+func targetPlatform(installSpec *spec.InstallSpec) (string, string) {
+	osName, arch := detectPlatform(installSpec)
+	if installOS != "" {
+		osName = installOS
+	}
+	if installArch != "" {
+		arch = installArch
+	}
+	return osName, arch
+}
+
+// validateCrossInstall checks the flags of a cross install (--output)
+func validateCrossInstall() error {
+	if installOutput == "" {
+		if installOS != "" || installArch != "" {
+			return fmt.Errorf("--os and --arch install the binaries of another platform and require --output (or --list-contents)")
+		}
+		return nil
+	}
+	if installBinDir != "" {
+		return fmt.Errorf("--output and --bin-dir cannot be combined")
+	}
+	if installPrintEnv {
+		return fmt.Errorf("--output installs binaries for packaging and cannot be combined with --print-env")
+	}
+	return nil
+}
+
+// crossInstallDir returns the subdirectory of output holding the binaries of a
+// platform
+func crossInstallDir(output, osName, arch string) string {
+	return filepath.Join(output, osName+"_"+arch)
+}
+
+// installCross installs the binaries of installSpec for the target platform
+// into a directory of output. Unlike a regular install, nothing on the host
+// refers to them afterwards: no receipt, tool cache entry or lock is involved.
+func installCross(ctx context.Context, installSpec *spec.InstallSpec, version, output string, dryRun bool, src assetSource) error {
+	osName, arch := targetPlatform(installSpec)
+	dir := crossInstallDir(output, osName, arch)
+	log.Infof("Installing the %s/%s binaries into %s", osName, arch, dir)
+	_, err := installRelease(ctx, installSpec, version, dir, dryRun, src)
+	return err
+}
+
+// binaryMode returns the file mode binaries of osName are installed with.
+// Windows has no executable bit, so binaries cross-installed for it are
+// regular files.
+func binaryMode(osName string) os.FileMode {
+	if installOutput != "" && osName == "windows" {
+		return 0644
+	}
+	return 0755
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestInstallCross(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not preserved on windows")
+	}
+	t.Setenv("BINSTALLER_OS_VERSION", "")
+
+	files := map[string][]byte{
+		"tool_1.0.0_linux_arm64":   []byte("linux/arm64"),
+		"tool_1.0.0_windows_amd64": []byte("windows/amd64"),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := filepath.Base(r.URL.Path)
+		if name == "checksums.txt" {
+			for name, content := range files {
+				fmt.Fprintf(w, "%x  %s\n", sha256.Sum256(content), name)
+			}
+			return
+		}
+		content, ok := files[name]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(content)
+	}))
+	defer server.Close()
+	oldURL := gitHubDownloadBaseURL
+	gitHubDownloadBaseURL = server.URL
+	defer func() { gitHubDownloadBaseURL = oldURL }()
+
+	file := filepath.Join(t.TempDir(), ".binstaller.yml")
+	writeTestFile(t, file, `repo: owner/tool
+asset:
+  template: ${NAME}_${VERSION}_${OS}_${ARCH}
+checksums:
+  template: checksums.txt
+`, 0644)
+	installSpec, err := loadInstallSpec(file)
+	if err != nil {
+		t.Fatalf("loadInstallSpec() error = %v", err)
+	}
+	installSpec.SetDefaults()
+
+	output := t.TempDir()
+	defer func() { installOS, installArch, installOutput = "", "", "" }()
+	installOutput = output
+	for _, tt := range []struct {
+		os, arch string
+		mode     os.FileMode
+	}{
+		{os: "linux", arch: "arm64", mode: 0755},
+		{os: "windows", arch: "amd64", mode: 0644},
+	} {
+		installOS, installArch = tt.os, tt.arch
+		if err := installCross(context.Background(), installSpec, "v1.0.0", output, false, assetSource{}); err != nil {
+			t.Fatalf("installCross(%s/%s) error = %v", tt.os, tt.arch, err)
+		}
+		path := filepath.Join(output, tt.os+"_"+tt.arch, "tool")
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("binary not installed: %v", err)
+		}
+		if string(got) != tt.os+"/"+tt.arch {
+			t.Errorf("%s = %q, want the %s/%s binary", path, got, tt.os, tt.arch)
+		}
+		if info, err := os.Stat(path); err != nil || info.Mode().Perm() != tt.mode {
+			t.Errorf("%s mode = %v, want %v", path, info.Mode().Perm(), tt.mode)
+		}
+	}
+}
+
+func TestValidateCrossInstall(t *testing.T) {
+	defer func() { installOS, installArch, installOutput, installBinDir, installPrintEnv = "", "", "", "", false }()
+
+	tests := []struct {
+		name    string
+		setup   func()
+		wantErr string
+	}{
+		{name: "host install", setup: func() {}},
+		{name: "platform without output", setup: func() { installArch = "arm64" }, wantErr: "require --output"},
+		{name: "output", setup: func() { installOS, installArch, installOutput = "linux", "arm64", "dist" }},
+		{name: "output with bin dir", setup: func() { installOutput, installBinDir = "dist", "/usr/local/bin" }, wantErr: "--bin-dir"},
+		{name: "output with print env", setup: func() { installOutput, installPrintEnv = "dist", true }, wantErr: "--print-env"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installOS, installArch, installOutput, installBinDir, installPrintEnv = "", "", "", "", false
+			tt.setup()
+			err := validateCrossInstall()
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("validateCrossInstall() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("validateCrossInstall() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/asset"
+	"github.com/binary-install/binstaller/pkg/cache"
+	"github.com/binary-install/binstaller/pkg/checksums"
+	"github.com/binary-install/binstaller/pkg/delta"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// downloadDelta reconstructs the asset at assetPath by applying a delta patch to the
+// most recently cached asset of a previous version for the same platform.
+// The result must match the release checksum; patched assets are never trusted unverified.
+func downloadDelta(ctx context.Context, store *cache.Store, installSpec *spec.InstallSpec, generator *asset.FilenameGenerator, verifier *checksums.Verifier, osName, arch, tag, assetFilename, assetPath string) error {
+	repo := spec.StringValue(installSpec.Repo)
+
+	expectedHash, err := verifier.GetChecksum(ctx, assetFilename)
+	if err != nil {
+		return fmt.Errorf("delta updates require a known checksum: %w", err)
+	}
+
+	tags, err := store.Tags(repo)
+	if err != nil {
+		return err
+	}
+	for _, fromTag := range tags {
+		if fromTag == tag {
+			continue
+		}
+		fromGenerator := asset.NewFilenameGenerator(installSpec, fromTag)
+		fromGenerator.OSVersion = generator.OSVersion
+		fromFilename, err := fromGenerator.GenerateFilename(osName, arch)
+		if err != nil {
+			continue
+		}
+		oldPath, ok := store.Lookup(repo, fromTag, fromFilename)
+		if !ok {
+			continue
+		}
+
+		patchFilename, err := generator.DeltaFilename(osName, arch, fromTag)
+		if err != nil {
+			return err
+		}
+		patchURL := releaseDownloadURL(installSpec, tag, patchFilename)
+		log.Infof("Downloading delta %s (from %s)", patchURL, fromTag)
+		if err := applyDelta(ctx, installSpec, patchURL, oldPath, assetPath); err != nil {
+			return err
+		}
+
+		actualHash, err := checksums.ComputeHash(assetPath, string(installSpec.GetChecksums().GetAlgorithm()))
+		if err != nil {
+			return fmt.Errorf("failed to compute hash: %w", err)
+		}
+		if actualHash != expectedHash {
+			os.Remove(assetPath)
+			return fmt.Errorf("patched asset checksum mismatch: expected %s, got %s", expectedHash, actualHash)
+		}
+		log.Infof("Reconstructed %s from cached %s", assetFilename, fromTag)
+		return nil
+	}
+	return errors.New("no cached asset of a previous version")
+}
+
+// applyDelta downloads the patch at patchURL next to assetPath and applies it to
+// oldPath, writing the result to assetPath. The patch is removed afterwards.
+func applyDelta(ctx context.Context, installSpec *spec.InstallSpec, patchURL, oldPath, assetPath string) error {
+	patchPath := assetPath + ".patch"
+	defer os.Remove(patchPath)
+
+	if err := download(ctx, installSpec, patchPath, patchURL); err != nil {
+		return fmt.Errorf("failed to download delta: %w", err)
+	}
+	format := delta.Format(installSpec.GetAsset().GetDelta().GetFormat())
+	return delta.ApplyFile(format, oldPath, patchPath, assetPath)
+}
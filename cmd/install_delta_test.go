@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/asset"
+	"github.com/binary-install/binstaller/pkg/cache"
+	"github.com/binary-install/binstaller/pkg/checksums"
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestDownloadDelta(t *testing.T) {
+	oldAsset := []byte("tool v1.0.0 binary contents, mostly unchanged between releases")
+	newAsset := []byte("tool v1.1.0 binary contents, mostly unchanged between releases!")
+
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderDictRaw(0, oldAsset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	patch := encoder.EncodeAll(newAsset, nil)
+	encoder.Close()
+
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		if r.URL.Path == "/owner/tool/releases/download/v1.1.0/tool_1.0.0_to_1.1.0_linux_amd64.zst" {
+			w.Write(patch)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	oldURL := gitHubDownloadBaseURL
+	gitHubDownloadBaseURL = server.URL
+	defer func() { gitHubDownloadBaseURL = oldURL }()
+
+	sum := sha256.Sum256(newAsset)
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${VERSION}_${OS}_${ARCH}").
+			WithDelta(spec.NewDelta("${NAME}_${FROM_VERSION}_to_${VERSION}_${OS}_${ARCH}.zst"))).
+		WithChecksums(spec.NewChecksums("").
+			WithEmbeddedChecksum("v1.1.0", "tool_1.1.0_linux_amd64", hex.EncodeToString(sum[:])))
+	installSpec.SetDefaults()
+
+	store := &cache.Store{Root: t.TempDir()}
+	generator := asset.NewFilenameGenerator(installSpec, "1.1.0")
+	verifier := checksums.NewVerifier(installSpec, "v1.1.0")
+	assetPath := filepath.Join(t.TempDir(), "tool_1.1.0_linux_amd64")
+
+	run := func() error {
+		return downloadDelta(context.Background(), store, installSpec, generator, verifier, "linux", "amd64", "v1.1.0", "tool_1.1.0_linux_amd64", assetPath)
+	}
+
+	// Nothing cached yet
+	if err := run(); err == nil {
+		t.Fatal("downloadDelta() expected error with empty cache")
+	}
+
+	oldPath := filepath.Join(t.TempDir(), "old")
+	if err := os.WriteFile(oldPath, oldAsset, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put("owner/tool", "v1.0.0", "tool_1.0.0_linux_amd64", oldPath); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := run(); err != nil {
+		t.Fatalf("downloadDelta() error = %v", err)
+	}
+	got, err := os.ReadFile(assetPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(newAsset) {
+		t.Errorf("patched asset = %q, want %q", got, newAsset)
+	}
+	if _, err := os.Stat(assetPath + ".patch"); !os.IsNotExist(err) {
+		t.Error("downloadDelta() left the patch behind")
+	}
+
+	// A patch producing the wrong contents must be rejected
+	installSpec.Checksums.EmbeddedChecksums["v1.1.0"][0].Hash = spec.StringPtr("0000")
+	if err := run(); err == nil {
+		t.Error("downloadDelta() expected checksum mismatch error")
+	}
+	if _, err := os.Stat(assetPath); !os.IsNotExist(err) {
+		t.Error("downloadDelta() left unverified asset behind")
+	}
+
+	// Without a known checksum delta updates are not attempted
+	installSpec.Checksums = nil
+	requested = nil
+	if err := run(); err == nil {
+		t.Error("downloadDelta() expected error without checksum")
+	}
+	if len(requested) != 0 {
+		t.Errorf("downloadDelta() made requests without checksum: %v", requested)
+	}
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/checksums"
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/buildkite/interpolate"
+)
+
+// doubleFetchURL returns the URL of the second download of an asset: the mirror
+// template of cfg when set, otherwise the release download URL
+func doubleFetchURL(cfg *spec.DoubleFetch, installSpec *spec.InstallSpec, tag, assetFilename string) (string, error) {
+	mirror := cfg.GetMirror()
+	if mirror == "" {
+		return releaseDownloadURL(installSpec, tag, assetFilename), nil
+	}
+	env := interpolate.NewMapEnv(map[string]string{
+		"REPO":           installSpec.GetRepo(),
+		"TAG":            tag,
+		"ASSET_FILENAME": assetFilename,
+	})
+	url, err := interpolate.Interpolate(env, mirror)
+	if err != nil {
+		return "", fmt.Errorf("failed to interpolate double_fetch.mirror %q: %w", mirror, err)
+	}
+	return url, nil
+}
+
+// doubleFetch downloads an unverified asset a second time into dir and fails
+// unless both copies have the same sha256 hash, as a last-resort tamper check
+func doubleFetch(ctx context.Context, cfg *spec.DoubleFetch, installSpec *spec.InstallSpec, tag, assetFilename, assetPath, dir string) error {
+	url, err := doubleFetchURL(cfg, installSpec, tag, assetFilename)
+	if err != nil {
+		return err
+	}
+	secondDir := filepath.Join(dir, "double-fetch")
+	if err := os.MkdirAll(secondDir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	secondPath := filepath.Join(secondDir, assetFilename)
+	log.Infof("Downloading %s again from %s to compare", assetFilename, url)
+	if err := download(ctx, installSpec, secondPath, url); err != nil {
+		return fmt.Errorf("failed to download second copy: %w", err)
+	}
+
+	first, err := checksums.ComputeHash(assetPath, "sha256")
+	if err != nil {
+		return fmt.Errorf("failed to compute hash: %w", err)
+	}
+	second, err := checksums.ComputeHash(secondPath, "sha256")
+	if err != nil {
+		return fmt.Errorf("failed to compute hash: %w", err)
+	}
+	if first != second {
+		return fmt.Errorf("%s differs between downloads (sha256 %s, then %s from %s): the asset may have been tampered with", assetFilename, first, second, url)
+	}
+	log.Infof("Both downloads of %s have sha256 %s", assetFilename, first)
+	return nil
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestDoubleFetch(t *testing.T) {
+	t.Setenv("BINSTALLER_OS_VERSION", "")
+	content := "#!/bin/sh\necho tool\n"
+
+	var mu sync.Mutex
+	requests := map[string]int{}
+	tampered := map[string]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests[r.URL.Path]++
+		if tampered[r.URL.Path] && requests[r.URL.Path] > 1 {
+			w.Write([]byte(content + "tampered\n"))
+			return
+		}
+		w.Write([]byte(content))
+	}))
+	defer server.Close()
+	oldURL := gitHubDownloadBaseURL
+	gitHubDownloadBaseURL = server.URL
+	defer func() { gitHubDownloadBaseURL = oldURL }()
+
+	filename := fmt.Sprintf("tool_1.0.0_%s_%s", runtime.GOOS, runtime.GOARCH)
+	writeSpec := func(t *testing.T, doubleFetch string) string {
+		t.Helper()
+		file := filepath.Join(t.TempDir(), ".binstaller.yml")
+		writeTestFile(t, file, `repo: owner/tool
+asset:
+  template: ${NAME}_${VERSION}_${OS}_${ARCH}
+checksums:
+  double_fetch:
+`+doubleFetch, 0644)
+		return file
+	}
+
+	tests := []struct {
+		name        string
+		doubleFetch string
+		tamper      string
+		wantErr     string
+		wantFetches map[string]int
+	}{
+		{
+			name:        "disabled",
+			doubleFetch: "    enabled: false\n",
+			wantFetches: map[string]int{"/owner/tool/releases/download/v1.0.0/" + filename: 1},
+		},
+		{
+			name:        "origin twice",
+			doubleFetch: "    enabled: true\n",
+			wantFetches: map[string]int{"/owner/tool/releases/download/v1.0.0/" + filename: 2},
+		},
+		{
+			name:        "mirror",
+			doubleFetch: "    enabled: true\n    mirror: ${REPO}-mirror/${TAG}/${ASSET_FILENAME}\n",
+			wantFetches: map[string]int{
+				"/owner/tool/releases/download/v1.0.0/" + filename: 1,
+				"/owner/tool-mirror/v1.0.0/" + filename:            1,
+			},
+		},
+		{
+			name:        "tampered",
+			doubleFetch: "    enabled: true\n",
+			tamper:      "/owner/tool/releases/download/v1.0.0/" + filename,
+			wantErr:     "may have been tampered with",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mu.Lock()
+			requests = map[string]int{}
+			tampered = map[string]bool{tt.tamper: true}
+			mu.Unlock()
+
+			doubleFetch := tt.doubleFetch
+			if strings.Contains(doubleFetch, "mirror:") {
+				doubleFetch = strings.Replace(doubleFetch, "mirror: ", "mirror: "+server.URL+"/", 1)
+			}
+			installSpec, err := loadInstallSpec(writeSpec(t, doubleFetch))
+			if err != nil {
+				t.Fatalf("loadInstallSpec() error = %v", err)
+			}
+			installSpec.SetDefaults()
+			_, err = installRelease(context.Background(), installSpec, "v1.0.0", t.TempDir(), false, assetSource{})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("installRelease() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("installRelease() error = %v", err)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for path, want := range tt.wantFetches {
+				if requests[path] != want {
+					t.Errorf("%s fetched %d times, want %d (requests: %v)", path, requests[path], want, requests)
+				}
+			}
+		})
+	}
+}
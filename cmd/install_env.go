@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"path/filepath"
+)
+
+// printEnv writes POSIX shell lines that add binDirs to PATH, for
+// eval "$(binst install --print-env)". Directories already on PATH when the
+// lines are evaluated are not added again, so shell init files can eval them
+// on every start.
+func printEnv(w io.Writer, binDirs []string) error {
+	seen := make(map[string]bool)
+	for _, dir := range binDirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", dir, err)
+		}
+		if seen[abs] {
+			continue
+		}
+		seen[abs] = true
+		quoted := shellQuote(abs)
+		if _, err := fmt.Fprintf(w, "case \":${PATH}:\" in *:%s:*) ;; *) export PATH=%s\"${PATH:+:${PATH}}\" ;; esac\n", quoted, quoted); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// installedBinDirs returns the bin dirs of the tools that are installed
+func installedBinDirs(results []toolResult) []string {
+	var dirs []string
+	for _, r := range results {
+		if r.status == toolStatusInstalled || r.status == toolStatusSkipped {
+			dirs = append(dirs, r.binDir)
+		}
+	}
+	return dirs
+}
+
+// installedRuntimeEnv returns the runtime_env of the tools that are installed.
+// When tools set the same variable, the last one wins.
+func installedRuntimeEnv(results []toolResult) map[string]string {
+	env := make(map[string]string)
+	for _, r := range results {
+		if r.status == toolStatusInstalled || r.status == toolStatusSkipped {
+			maps.Copy(env, r.runtimeEnv)
+		}
+	}
+	return env
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestPrintEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+	dir := filepath.Join(t.TempDir(), "it's bin")
+	other := t.TempDir()
+
+	var out bytes.Buffer
+	if err := printEnv(&out, []string{dir, other, dir}); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(out.String(), "\n"); lines != 2 {
+		t.Errorf("printEnv() printed %d lines, want one per unique dir:\n%s", lines, out.String())
+	}
+
+	// Evaluating the lines twice, as a shell init file would, adds each dir once
+	script := out.String() + out.String() + `printf %s "$PATH"`
+	c := exec.Command("sh", "-c", script)
+	c.Env = []string{"PATH=/usr/bin:/bin:" + other}
+	got, err := c.Output()
+	if err != nil {
+		t.Fatalf("sh failed: %v", err)
+	}
+	want := dir + ":/usr/bin:/bin:" + other
+	if string(got) != want {
+		t.Errorf("PATH = %q, want %q", got, want)
+	}
+}
+
+func TestInstalledBinDirs(t *testing.T) {
+	results := []toolResult{
+		{binDir: "/a", status: toolStatusInstalled},
+		{binDir: "/b", status: toolStatusFailed},
+		{binDir: "/c", status: toolStatusSkipped},
+		{status: toolStatusPending},
+	}
+	got := installedBinDirs(results)
+	if strings.Join(got, ",") != "/a,/c" {
+		t.Errorf("installedBinDirs() = %v, want [/a /c]", got)
+	}
+}
+
+func TestPrintRuntimeEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+	results := []toolResult{
+		{status: toolStatusInstalled, runtimeEnv: map[string]string{"TOOL_HOME": "/opt/it's/share"}},
+		{status: toolStatusFailed, runtimeEnv: map[string]string{"OTHER_HOME": "/opt/other"}},
+	}
+	var out bytes.Buffer
+	if err := printRuntimeEnv(&out, installedRuntimeEnv(results)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := exec.Command("sh", "-c", out.String()+`printf %s "${TOOL_HOME}|${OTHER_HOME}"`).Output()
+	if err != nil {
+		t.Fatalf("sh failed: %v", err)
+	}
+	if string(got) != "/opt/it's/share|" {
+		t.Errorf("environment = %q, want only the installed tool's TOOL_HOME", got)
+	}
+}
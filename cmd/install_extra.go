@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/archive"
+	"github.com/binary-install/binstaller/pkg/asset"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// extraFile is a downloaded file of asset.extra_files
+type extraFile struct {
+	filename string
+	// dest is the installation directory relative to the bin dir
+	dest string
+	path string
+}
+
+// resolveExtraFiles returns the extra files of a platform, not yet downloaded
+func resolveExtraFiles(installSpec *spec.InstallSpec, generator *asset.FilenameGenerator, osName, arch string) ([]extraFile, error) {
+	filenames, err := generator.ExtraFilenames(osName, arch)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]extraFile, 0, len(filenames))
+	for i, filename := range filenames {
+		dest := installSpec.Asset.ExtraFiles[i].GetDest()
+		if filepath.IsAbs(dest) {
+			return nil, fmt.Errorf("extra file %s: dest %q must be relative to the bin directory", filename, dest)
+		}
+		files = append(files, extraFile{filename: filename, dest: dest})
+	}
+	return files, nil
+}
+
+// downloadExtraFiles downloads the extra files into dir and verifies each of
+// them like the asset
+func downloadExtraFiles(ctx context.Context, files []extraFile, v assetVerification, dir string) error {
+	for i := range files {
+		f := &files[i]
+		url := releaseDownloadURL(v.installSpec, v.tag, f.filename)
+		f.path = filepath.Join(dir, f.filename)
+		log.Infof("Downloading extra file %s", url)
+		if err := download(ctx, v.installSpec, f.path, url); err != nil {
+			return fmt.Errorf("failed to download extra file %s: %w", f.filename, err)
+		}
+		if err := v.verify(ctx, f.filename, f.path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// installExtraFiles extracts or copies the extra files into their dest
+// directories under binDir
+func installExtraFiles(files []extraFile, binDir string) error {
+	for _, f := range files {
+		destDir := filepath.Join(binDir, f.dest)
+		if err := os.MkdirAll(destDir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory for extra file %s: %w", f.filename, err)
+		}
+		if archive.IsArchive(f.filename) {
+			// Keep modification times like the tar and unzip of installer scripts
+			log.Infof("Extracting extra file %s into %s", f.filename, destDir)
+			if err := archive.NewExtractor(0).WithModTimes(true).Extract(f.path, destDir); err != nil {
+				return fmt.Errorf("failed to extract extra file %s: %w", f.filename, err)
+			}
+			continue
+		}
+		log.Infof("Installing extra file %s into %s", f.filename, destDir)
+		if err := copyFile(f.path, filepath.Join(destDir, f.filename)); err != nil {
+			return fmt.Errorf("failed to install extra file %s: %w", f.filename, err)
+		}
+	}
+	return nil
+}
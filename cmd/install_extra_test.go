@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+func TestInstallReleaseExtraFiles(t *testing.T) {
+	t.Setenv("BINSTALLER_OS_VERSION", "")
+
+	var data bytes.Buffer
+	gz := gzip.NewWriter(&data)
+	tw := tar.NewWriter(gz)
+	dict := "hello\nworld\n"
+	if err := tw.WriteHeader(&tar.Header{Name: "dict.txt", Mode: 0644, Size: int64(len(dict))}); err != nil {
+		t.Fatal(err)
+	}
+	tw.Write([]byte(dict))
+	tw.Close()
+	gz.Close()
+
+	binary := fmt.Sprintf("tool_1.0.0_%s_%s", runtime.GOOS, runtime.GOARCH)
+	files := map[string][]byte{
+		binary:                []byte("#!/bin/sh\necho tool\n"),
+		"tool_1.0.0_data.tgz": data.Bytes(),
+		"tool.1":              []byte(".TH TOOL 1\n"),
+	}
+	checksums := func(tamper string) string {
+		var b strings.Builder
+		for name, content := range files {
+			if name == tamper {
+				content = append(content, '!')
+			}
+			fmt.Fprintf(&b, "%x  %s\n", sha256.Sum256(content), name)
+		}
+		return b.String()
+	}
+
+	var checksumFile string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := filepath.Base(r.URL.Path)
+		if name == "checksums.txt" {
+			w.Write([]byte(checksumFile))
+			return
+		}
+		content, ok := files[name]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(content)
+	}))
+	defer server.Close()
+	oldURL := gitHubDownloadBaseURL
+	gitHubDownloadBaseURL = server.URL
+	defer func() { gitHubDownloadBaseURL = oldURL }()
+
+	file := filepath.Join(t.TempDir(), ".binstaller.yml")
+	writeTestFile(t, file, `repo: owner/tool
+asset:
+  template: ${NAME}_${VERSION}_${OS}_${ARCH}
+  extra_files:
+    - template: ${NAME}_${VERSION}_data.tgz
+      dest: /usr/share/tool
+    - template: ${NAME}.1
+checksums:
+  template: checksums.txt
+`, 0644)
+	installSpec, err := loadInstallSpec(file)
+	if err != nil {
+		t.Fatalf("loadInstallSpec() error = %v", err)
+	}
+	installSpec.SetDefaults()
+
+	t.Run("absolute dest", func(t *testing.T) {
+		checksumFile = checksums("")
+		_, err := installRelease(context.Background(), installSpec, "v1.0.0", t.TempDir(), false, assetSource{})
+		if err == nil || !strings.Contains(err.Error(), "must be relative to the bin directory") {
+			t.Fatalf("installRelease() error = %v, want dest error", err)
+		}
+	})
+
+	installSpec.Asset.ExtraFiles[0].Dest = spec.StringPtr("../share/tool")
+
+	t.Run("installed", func(t *testing.T) {
+		checksumFile = checksums("")
+		binDir := filepath.Join(t.TempDir(), "bin")
+		if _, err := installRelease(context.Background(), installSpec, "v1.0.0", binDir, false, assetSource{}); err != nil {
+			t.Fatalf("installRelease() error = %v", err)
+		}
+		for path, want := range map[string]string{
+			"../share/tool/dict.txt": dict,
+			"tool.1":                 ".TH TOOL 1\n",
+		} {
+			got, err := os.ReadFile(filepath.Join(binDir, path))
+			if err != nil {
+				t.Fatalf("extra file not installed: %v", err)
+			}
+			if string(got) != want {
+				t.Errorf("%s = %q, want %q", path, got, want)
+			}
+		}
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		checksumFile = checksums("tool.1")
+		binDir := t.TempDir()
+		_, err := installRelease(context.Background(), installSpec, "v1.0.0", binDir, false, assetSource{})
+		if err == nil || !strings.Contains(err.Error(), "checksum verification failed") {
+			t.Fatalf("installRelease() error = %v, want checksum error", err)
+		}
+		if _, err := os.Stat(filepath.Join(binDir, "tool")); !os.IsNotExist(err) {
+			t.Errorf("binary should not be installed when an extra file fails verification")
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+
+	"github.com/apex/log"
+)
+
+// installNoFallback makes 'binst install' fail instead of redirecting a
+// read-only install directory to the user bin directory
+var installNoFallback bool
+
+// immutableSystem is a marker file of a distribution whose system directories
+// are read-only
+type immutableSystem struct {
+	marker string
+	name   string
+}
+
+// immutableSystems are the immutable distributions binst recognizes
+var immutableSystems = []immutableSystem{
+	{marker: "/etc/NIXOS", name: "NixOS"},
+	{marker: "/run/ostree-booted", name: "an ostree-based immutable system"},
+}
+
+// immutableDirs are the system directories immutable distributions keep read-only
+var immutableDirs = []string{"/usr", "/bin", "/sbin", "/lib", "/nix/store"}
+
+// writableDirs are the directories under immutableDirs that stay writable, such
+// as /usr/local, which ostree-based systems link to /var/usrlocal
+var writableDirs = []string{"/usr/local"}
+
+// readOnlyReason returns why binDir cannot be installed into, or "" when it can.
+// Permission errors are left to the installation to report. A dry run does not
+// probe the file system, so it only recognizes immutable system directories.
+func readOnlyReason(binDir string, dryRun bool) string {
+	abs, err := filepath.Abs(binDir)
+	if err != nil {
+		return ""
+	}
+	for _, dir := range immutableDirs {
+		if !isWithin(abs, dir) || slices.ContainsFunc(writableDirs, func(w string) bool { return isWithin(abs, w) }) {
+			continue
+		}
+		for _, system := range immutableSystems {
+			if _, err := os.Stat(system.marker); err == nil {
+				return fmt.Sprintf("%s is read-only on %s", dir, system.name)
+			}
+		}
+	}
+
+	if dryRun {
+		return ""
+	}
+	// Probe the nearest existing directory, where the install directory would be created
+	dir := abs
+	for {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+	probe, err := os.CreateTemp(dir, ".binst-probe-*")
+	if errors.Is(err, syscall.EROFS) {
+		return fmt.Sprintf("%s is on a read-only file system", dir)
+	}
+	if err == nil {
+		probe.Close()
+		os.Remove(probe.Name())
+	}
+	return ""
+}
+
+// isWithin reports whether path is dir or inside it
+func isWithin(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, dir+"/")
+}
+
+// resolveWritableBinDir returns binDir, or ~/.local/bin when binDir is on a
+// read-only file system or in a system directory of an immutable distribution.
+// With --no-fallback it fails instead.
+func resolveWritableBinDir(binDir string, dryRun bool) (string, error) {
+	reason := readOnlyReason(binDir, dryRun)
+	if reason == "" {
+		return binDir, nil
+	}
+	if installNoFallback {
+		return "", fmt.Errorf("cannot install into %s: %s (choose another directory with --bin-dir)", binDir, reason)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot install into %s: %s, and no home directory to fall back to: %w", binDir, reason, err)
+	}
+	fallback := filepath.Join(home, ".local", "bin")
+	if fallback == binDir || readOnlyReason(fallback, dryRun) != "" {
+		return "", fmt.Errorf("cannot install into %s: %s", binDir, reason)
+	}
+	log.Warnf("Cannot install into %s: %s", binDir, reason)
+	log.Warnf("Installing into %s instead; make sure it is on PATH, or pass --no-fallback to fail instead", fallback)
+	return fallback, nil
+}
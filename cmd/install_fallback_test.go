@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveWritableBinDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	systemDir := t.TempDir()
+	marker := filepath.Join(t.TempDir(), "NIXOS")
+
+	origSystems, origDirs, origWritable := immutableSystems, immutableDirs, writableDirs
+	t.Cleanup(func() { immutableSystems, immutableDirs, writableDirs = origSystems, origDirs, origWritable })
+	immutableSystems = []immutableSystem{{marker: marker, name: "NixOS"}}
+	immutableDirs = []string{systemDir}
+	writableDirs = []string{filepath.Join(systemDir, "local")}
+
+	binDir := filepath.Join(systemDir, "bin")
+
+	// Without the marker the system directory is installed into
+	got, err := resolveWritableBinDir(binDir, false)
+	if err != nil || got != binDir {
+		t.Fatalf("resolveWritableBinDir() = %q, %v, want %q", got, err, binDir)
+	}
+
+	writeTestFile(t, marker, "", 0o644)
+	got, err = resolveWritableBinDir(binDir, false)
+	if want := filepath.Join(home, ".local", "bin"); err != nil || got != want {
+		t.Fatalf("resolveWritableBinDir() on NixOS = %q, %v, want %q", got, err, want)
+	}
+
+	// Directories outside the system directories are unaffected
+	other := filepath.Join(t.TempDir(), "bin")
+	if got, err := resolveWritableBinDir(other, false); err != nil || got != other {
+		t.Errorf("resolveWritableBinDir(%q) = %q, %v", other, got, err)
+	}
+
+	// So are the writable directories within them, such as /usr/local
+	local := filepath.Join(systemDir, "local", "bin")
+	for _, dryRun := range []bool{false, true} {
+		if got, err := resolveWritableBinDir(local, dryRun); err != nil || got != local {
+			t.Errorf("resolveWritableBinDir(%q, %v) = %q, %v", local, dryRun, got, err)
+		}
+	}
+
+	// A dry run still recognizes the system directories
+	if got, _ := resolveWritableBinDir(binDir, true); got == binDir {
+		t.Errorf("resolveWritableBinDir() in a dry run = %q, want the fallback", got)
+	}
+
+	installNoFallback = true
+	t.Cleanup(func() { installNoFallback = false })
+	if _, err := resolveWritableBinDir(binDir, false); err == nil || !strings.Contains(err.Error(), "read-only on NixOS") {
+		t.Errorf("resolveWritableBinDir() with --no-fallback = %v, want a read-only error", err)
+	}
+}
@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/archive"
+	"github.com/binary-install/binstaller/pkg/asset"
+	"github.com/binary-install/binstaller/pkg/cache"
+	"github.com/binary-install/binstaller/pkg/checksums"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// newAssetVerifier returns the checksum verifier of a release for the platform
+// the assets are resolved for
+func newAssetVerifier(installSpec *spec.InstallSpec, tag, osName, arch string, generator *asset.FilenameGenerator) *checksums.Verifier {
+	verifier := checksums.NewVerifier(installSpec, tag)
+	verifier.OS, verifier.Arch, verifier.OSVersion = osName, arch, generator.OSVersion
+	verifier.AllowWeakAlgorithm = allowWeakHash()
+	verifier.DownloadBaseURL = releaseBaseURL(installSpec)
+	return verifier
+}
+
+// listContents writes the entries of the release asset of the target platform
+// with the paths they are extracted to, followed by the binaries install would
+// select, without extracting or installing anything. The asset is taken from
+// src or the cache when possible, and downloaded and verified otherwise.
+func listContents(ctx context.Context, w io.Writer, installSpec *spec.InstallSpec, version string, src assetSource) error {
+	repo := installSpec.GetRepo()
+	if repo == "" {
+		return fmt.Errorf("GitHub repo not specified in config")
+	}
+	tag, err := resolveVersion(ctx, installSpec, version)
+	if err != nil {
+		return fmt.Errorf("failed to resolve version: %w", err)
+	}
+	osName, arch := targetPlatform(installSpec)
+	generator, err := newReleaseFilenameGenerator(ctx, installSpec, tag)
+	if err != nil {
+		return err
+	}
+	generator.OSVersion = hostOSVersion(osName)
+	candidates, err := generator.Candidates(osName, arch)
+	if err != nil {
+		return fmt.Errorf("failed to generate asset filename: %w", err)
+	}
+	candidates = src.candidates(candidates)
+
+	tmpDir, err := os.MkdirTemp("", "binst-")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	var assetFilename, assetPath string
+	var store *cache.Store
+	switch {
+	case src.file != "":
+		assetFilename = candidates[0]
+		assetPath = src.file
+		if filepath.Base(src.file) != assetFilename {
+			// Copy under the release filename so that its extension decides the format
+			assetPath = filepath.Join(tmpDir, assetFilename)
+			if err := copyFile(src.file, assetPath); err != nil {
+				return fmt.Errorf("failed to copy local asset: %w", err)
+			}
+		}
+		log.Infof("Listing local asset %s as %s", src.file, assetFilename)
+	case src.overridesAsset():
+		// Neither looked up nor stored: the cache holds the assets of the templates
+	default:
+		if store, err = cache.New(); err != nil {
+			log.Warnf("Asset cache disabled: %v", err)
+			store = nil
+			break
+		}
+		for _, candidate := range candidates {
+			if path, ok := store.Lookup(repo, tag, candidate); ok {
+				assetFilename, assetPath = candidate, path
+				log.Infof("Listing cached asset %s", path)
+				break
+			}
+		}
+	}
+	if assetPath == "" {
+		if assetFilename, err = src.download(ctx, installSpec, tag, tmpDir, candidates); err != nil {
+			return err
+		}
+		assetPath = filepath.Join(tmpDir, assetFilename)
+		verification := assetVerification{
+			installSpec: installSpec,
+			verifier:    newAssetVerifier(installSpec, tag, osName, arch, generator),
+			tag:         tag,
+			tmpDir:      tmpDir,
+		}
+		if err := verification.verify(ctx, assetFilename, assetPath); err != nil {
+			return err
+		}
+		if store != nil {
+			if err := store.Put(repo, tag, assetFilename, assetPath); err != nil {
+				log.Warnf("Failed to cache %s: %v", assetFilename, err)
+			}
+		}
+	}
+
+	entries, err := archive.List(assetPath)
+	if err != nil {
+		return fmt.Errorf("failed to list %s: %w", assetFilename, err)
+	}
+
+	filter, err := archive.NewFilter(installSpec.Unpack.GetInclude(), installSpec.Unpack.GetExclude())
+	if err != nil {
+		return fmt.Errorf("invalid unpack filter: %w", err)
+	}
+	extractor := archive.NewExtractor(int(installSpec.Unpack.GetStripComponents())).WithFilter(filter)
+	isArchive := archive.IsArchive(assetFilename)
+	targets := make(map[string]archive.Entry)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "ASSET\t%s (%s %s/%s)\n\n", assetFilename, tag, osName, arch)
+	fmt.Fprintln(tw, "SIZE\tPATH\tEXTRACTED AS")
+	for _, entry := range entries {
+		target := entry.Name
+		if isArchive {
+			target = extractor.Target(entry.Name)
+		}
+		if target != "" && !entry.Mode.IsDir() {
+			targets[target] = entry
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", entrySize(entry), entryPath(entry), entryTarget(entry, target))
+	}
+
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "BINARY\tPATH\tSTATUS")
+	var missing []string
+	for _, binary := range getBinariesForPlatform(installSpec, osName, arch) {
+		name := spec.StringValue(binary.Name)
+		if name == "" {
+			name = installSpec.GetName()
+		}
+		path := spec.StringValue(binary.Path)
+		if path == "" {
+			path = name
+		}
+		path, err := interpolateBinaryPath(path, assetFilename, "")
+		if err != nil {
+			return fmt.Errorf("failed to interpolate binary path: %w", err)
+		}
+		status := "found"
+		if _, ok := targets[filepath.Clean(path)]; !ok {
+			status = "not found"
+			if similar := similarTargets(targets, path); len(similar) > 0 {
+				status += "; extracted as " + strings.Join(similar, ", ")
+			}
+			missing = append(missing, name)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", name, path, status)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("binaries not found in %s: %s", assetFilename, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// entrySize formats the size column of an archive entry
+func entrySize(entry archive.Entry) string {
+	if entry.Mode.IsDir() || entry.Size < 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d", entry.Size)
+}
+
+// entryPath formats the path column of an archive entry, with link targets
+func entryPath(entry archive.Entry) string {
+	switch {
+	case entry.HardLink:
+		return entry.Name + " => " + entry.Linkname
+	case entry.Mode&os.ModeSymlink != 0 && entry.Linkname != "":
+		return entry.Name + " -> " + entry.Linkname
+	}
+	return entry.Name
+}
+
+// entryTarget formats the extracted-as column of an archive entry
+func entryTarget(entry archive.Entry, target string) string {
+	if target == "" {
+		return "(skipped)"
+	}
+	if entry.Mode.IsDir() {
+		return target + "/"
+	}
+	return target
+}
+
+// similarTargets returns the extracted paths with the base name of a binary
+// path, which hint at a wrong strip_components or binary path
+func similarTargets(targets map[string]archive.Entry, path string) []string {
+	base := filepath.Base(path)
+	var similar []string
+	for target := range targets {
+		if name := filepath.Base(target); name == base || name == base+".exe" {
+			similar = append(similar, target)
+		}
+	}
+	sort.Strings(similar)
+	return similar
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/cache"
+)
+
+func TestListContents(t *testing.T) {
+	t.Setenv("BINSTALLER_OS_VERSION", "")
+	t.Setenv(cache.EnvDir, t.TempDir())
+
+	var data bytes.Buffer
+	gz := gzip.NewWriter(&data)
+	tw := tar.NewWriter(gz)
+	for _, header := range []*tar.Header{
+		{Name: "tool_1.0.0/", Typeflag: tar.TypeDir, Mode: 0755},
+		{Name: "tool_1.0.0/bin/tool", Typeflag: tar.TypeReg, Mode: 0755, Size: 4},
+		{Name: "tool_1.0.0/README.md", Typeflag: tar.TypeReg, Mode: 0644, Size: 4},
+	} {
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write(make([]byte, header.Size))
+	}
+	tw.Close()
+	gz.Close()
+
+	filename := fmt.Sprintf("tool_1.0.0_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+	var downloads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/owner/tool/releases/download/v1.0.0/"+filename {
+			http.NotFound(w, r)
+			return
+		}
+		downloads.Add(1)
+		w.Write(data.Bytes())
+	}))
+	defer server.Close()
+	oldURL := gitHubDownloadBaseURL
+	gitHubDownloadBaseURL = server.URL
+	defer func() { gitHubDownloadBaseURL = oldURL }()
+
+	writeSpec := func(t *testing.T, unpack string) string {
+		t.Helper()
+		file := filepath.Join(t.TempDir(), ".binstaller.yml")
+		writeTestFile(t, file, `repo: owner/tool
+asset:
+  template: ${NAME}_${VERSION}_${OS}_${ARCH}.tar.gz
+  binaries:
+    - name: tool
+      path: bin/tool
+`+unpack, 0644)
+		return file
+	}
+	list := func(t *testing.T, unpack string) (string, error) {
+		t.Helper()
+		installSpec, err := loadInstallSpec(writeSpec(t, unpack))
+		if err != nil {
+			t.Fatalf("loadInstallSpec() error = %v", err)
+		}
+		installSpec.SetDefaults()
+		var out bytes.Buffer
+		err = listContents(context.Background(), &out, installSpec, "v1.0.0", assetSource{})
+		return out.String(), err
+	}
+
+	out, err := list(t, "unpack:\n  strip_components: 1\n  exclude:\n    - \"*.md\"\n")
+	if err != nil {
+		t.Fatalf("listContents() error = %v", err)
+	}
+	for _, want := range []string{
+		"ASSET  " + filename,
+		"4     tool_1.0.0/bin/tool   bin/tool",
+		"4     tool_1.0.0/README.md  (skipped)",
+		"tool    bin/tool  found",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output should contain %q:\n%s", want, out)
+		}
+	}
+
+	// The second listing reuses the verified asset from the cache
+	out, err = list(t, "")
+	if err == nil || !strings.Contains(err.Error(), "binaries not found") {
+		t.Fatalf("listContents() error = %v, want binaries not found", err)
+	}
+	if !strings.Contains(out, "not found; extracted as tool_1.0.0/bin/tool") {
+		t.Errorf("output should hint at strip_components:\n%s", out)
+	}
+	if got := downloads.Load(); got != 1 {
+		t.Errorf("asset downloaded %d times, want 1", got)
+	}
+}
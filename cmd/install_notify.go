@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/buildkite/interpolate"
+)
+
+// Results of install events
+const (
+	installEventSuccess = "success"
+	installEventFailure = "failure"
+)
+
+// webhookClient posts install events; tests replace it
+var webhookClient = http.DefaultClient
+
+// installEvent is the JSON body posted to notify.webhook_url after an install
+type installEvent struct {
+	Event   string    `json:"event"`
+	Tool    string    `json:"tool"`
+	Repo    string    `json:"repo"`
+	Version string    `json:"version"`
+	Host    string    `json:"host"`
+	OS      string    `json:"os"`
+	Arch    string    `json:"arch"`
+	Result  string    `json:"result"`
+	Error   string    `json:"error,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// webhookURL expands ${BINSTALLER_WEBHOOK_URL} in notify.webhook_url and returns
+// the URL, or "" when notifications are off. No other environment variable is
+// expanded, and the URL must be https.
+func webhookURL(installSpec *spec.InstallSpec) (string, error) {
+	tmpl := installSpec.GetNotify().GetWebhookURL()
+	if tmpl == "" {
+		return "", nil
+	}
+	if err := spec.ValidateWebhookURL(tmpl); err != nil {
+		return "", err
+	}
+	env := interpolate.NewMapEnv(map[string]string{spec.WebhookURLEnv: os.Getenv(spec.WebhookURLEnv)})
+	u, err := interpolate.Interpolate(env, tmpl)
+	if err != nil {
+		return "", fmt.Errorf("failed to interpolate notify.webhook_url: %w", err)
+	}
+	if u == "" {
+		return "", nil
+	}
+	if parsed, err := url.Parse(u); err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return "", fmt.Errorf("notify.webhook_url must be an https URL, got %s", redactURL(u))
+	}
+	return u, nil
+}
+
+// redactURL returns the scheme and host of rawURL, leaving out paths and queries
+// that may carry tokens
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "(invalid URL)"
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// withoutURL strips the request URL from a *url.Error, which would print the
+// secret path and query of the webhook URL
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
+
+// notifyInstall posts the outcome of installing version of spec to the webhook
+// of notify.webhook_url. Notifications never fail the install: errors are
+// logged as warnings.
+func notifyInstall(ctx context.Context, installSpec *spec.InstallSpec, version string, installErr error) {
+	target, err := webhookURL(installSpec)
+	if err != nil {
+		log.Warnf("Not sending install event: %v", err)
+		return
+	}
+	if target == "" {
+		return
+	}
+
+	event := installEvent{
+		Event:   "install",
+		Tool:    installSpec.GetName(),
+		Repo:    installSpec.GetRepo(),
+		Version: version,
+		OS:      runtime.GOOS,
+		Arch:    runtime.GOARCH,
+		Result:  installEventSuccess,
+		Time:    time.Now().UTC(),
+	}
+	event.Host, _ = os.Hostname()
+	if installErr != nil {
+		event.Result, event.Error = installEventFailure, installErr.Error()
+	}
+
+	log.Infof("Sending install event for %s (%s) to webhook %s (notify.webhook_url)", event.Tool, event.Result, redactURL(target))
+	if err := postInstallEvent(ctx, target, event); err != nil {
+		log.Warnf("Failed to send install event: %v", err)
+	}
+}
+
+// postInstallEvent posts event as JSON to target
+func postInstallEvent(ctx context.Context, target string, event installEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode install event: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", withoutURL(err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post to %s: %w", redactURL(target), withoutURL(err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s returned HTTP %d", redactURL(target), resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+func TestNotifyInstall(t *testing.T) {
+	var mu sync.Mutex
+	var events []installEvent
+	var paths []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		var event installEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("failed to decode install event: %v", err)
+		}
+		events = append(events, event)
+		paths = append(paths, r.URL.Path)
+	}))
+	defer server.Close()
+	oldClient := webhookClient
+	webhookClient = server.Client()
+	defer func() { webhookClient = oldClient }()
+
+	installSpec := spec.NewInstallSpec("owner/tool")
+	installSpec.SetDefaults()
+
+	// Off by default
+	notifyInstall(context.Background(), installSpec, "v1.0.0", nil)
+	if len(events) != 0 {
+		t.Fatalf("notifyInstall() without notify.webhook_url sent %d events", len(events))
+	}
+
+	t.Setenv("BINSTALLER_WEBHOOK_URL", server.URL+"/hooks/secret")
+	installSpec.Notify = &spec.Notify{WebhookURL: spec.StringPtr("${BINSTALLER_WEBHOOK_URL}")}
+	notifyInstall(context.Background(), installSpec, "v1.0.0", nil)
+	notifyInstall(context.Background(), installSpec, "v1.1.0", errors.New("checksum mismatch"))
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 2 {
+		t.Fatalf("notifyInstall() sent %d events, want 2", len(events))
+	}
+	if paths[0] != "/hooks/secret" {
+		t.Errorf("event posted to %s, want the expanded webhook URL", paths[0])
+	}
+	if got := events[0]; got.Tool != "tool" || got.Repo != "owner/tool" || got.Version != "v1.0.0" || got.Result != installEventSuccess || got.Host == "" {
+		t.Errorf("success event = %+v", got)
+	}
+	if got := events[1]; got.Version != "v1.1.0" || got.Result != installEventFailure || got.Error != "checksum mismatch" {
+		t.Errorf("failure event = %+v", got)
+	}
+}
+
+func TestWebhookURL(t *testing.T) {
+	t.Setenv("BINSTALLER_WEBHOOK_URL", "https://hooks.example.com/services/T000")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	tests := []struct {
+		tmpl    string
+		want    string
+		wantErr bool
+	}{
+		{tmpl: "${BINSTALLER_WEBHOOK_URL}", want: "https://hooks.example.com/services/T000"},
+		{tmpl: "$BINSTALLER_WEBHOOK_URL?tool=binst", want: "https://hooks.example.com/services/T000?tool=binst"},
+		{tmpl: "https://hooks.example.com/plain", want: "https://hooks.example.com/plain"},
+		// Other environment variables are never expanded into the URL
+		{tmpl: "https://hooks.example.com/?key=${AWS_SECRET_ACCESS_KEY}", wantErr: true},
+		{tmpl: "${BINSTALLER_WEBHOOK_URL}?key=$AWS_SECRET_ACCESS_KEY", wantErr: true},
+		{tmpl: "http://hooks.example.com/plain", wantErr: true},
+	}
+	for _, tt := range tests {
+		installSpec := spec.NewInstallSpec("owner/tool")
+		installSpec.Notify = &spec.Notify{WebhookURL: spec.StringPtr(tt.tmpl)}
+		got, err := webhookURL(installSpec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("webhookURL(%q) error = %v, wantErr %v", tt.tmpl, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("webhookURL(%q) = %q, want %q", tt.tmpl, got, tt.want)
+		}
+		if strings.Contains(got, "secret") || (err != nil && strings.Contains(err.Error(), "secret")) {
+			t.Errorf("webhookURL(%q) leaked AWS_SECRET_ACCESS_KEY: %q, %v", tt.tmpl, got, err)
+		}
+	}
+
+	// A plain http URL from the environment is refused too
+	t.Setenv("BINSTALLER_WEBHOOK_URL", "http://hooks.example.com/services/T000")
+	installSpec := spec.NewInstallSpec("owner/tool")
+	installSpec.Notify = &spec.Notify{WebhookURL: spec.StringPtr("${BINSTALLER_WEBHOOK_URL}")}
+	if _, err := webhookURL(installSpec); err == nil {
+		t.Error("webhookURL() accepted an http URL from BINSTALLER_WEBHOOK_URL")
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	if got := redactURL("https://hooks.example.com/services/T000/B000/XXXX?token=abc"); got != "https://hooks.example.com" {
+		t.Errorf("redactURL() = %q, want scheme and host only", got)
+	}
+	if got := redactURL("not a url"); got != "(invalid URL)" {
+		t.Errorf("redactURL() = %q, want (invalid URL)", got)
+	}
+
+	// A failed POST does not print the secret path either
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	target := server.URL + "/services/T000/SECRET?token=abc"
+	server.Close()
+	err := postInstallEvent(context.Background(), target, installEvent{})
+	if err == nil {
+		t.Fatal("postInstallEvent() to a closed server succeeded")
+	}
+	if strings.Contains(err.Error(), "SECRET") || strings.Contains(err.Error(), "token=") {
+		t.Errorf("postInstallEvent() error = %v, want the webhook path redacted", err)
+	}
+}
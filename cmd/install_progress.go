@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+const (
+	// progressDelay is how long a download runs before its progress is shown,
+	// so that small files do not flash a progress line
+	progressDelay = 500 * time.Millisecond
+	// progressInterval is the minimum time between two progress updates
+	progressInterval = 200 * time.Millisecond
+)
+
+// progressOutput is where download progress is drawn, nil when progress is
+// disabled: with --quiet or BINSTALLER_NO_PROGRESS=1, and when stdout is not a
+// terminal
+var progressOutput = func() io.Writer {
+	if quiet || os.Getenv("BINSTALLER_NO_PROGRESS") == "1" || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return nil
+	}
+	return os.Stderr
+}
+
+// progressWriter counts the bytes of a download written through it and draws
+// a progress line with the percentage, speed and ETA
+type progressWriter struct {
+	out   io.Writer
+	name  string
+	total int64 // -1 when the server sent no Content-Length
+	done  int64
+	start time.Time
+	last  time.Time
+	shown bool
+	now   func() time.Time
+}
+
+// newProgressWriter returns a progress writer for a download of total bytes,
+// or nil when progress is disabled
+func newProgressWriter(name string, total int64) *progressWriter {
+	out := progressOutput()
+	if out == nil {
+		return nil
+	}
+	return &progressWriter{out: out, name: name, total: total, start: time.Now(), now: time.Now}
+}
+
+// Write implements io.Writer
+func (p *progressWriter) Write(b []byte) (int, error) {
+	p.done += int64(len(b))
+	now := p.now()
+	if now.Sub(p.start) >= progressDelay && now.Sub(p.last) >= progressInterval {
+		p.last = now
+		p.draw(now)
+	}
+	return len(b), nil
+}
+
+// finish completes the progress line of a download that showed progress
+func (p *progressWriter) finish() {
+	if !p.shown {
+		return
+	}
+	p.draw(p.now())
+	fmt.Fprintln(p.out)
+}
+
+// draw redraws the progress line
+func (p *progressWriter) draw(now time.Time) {
+	p.shown = true
+	elapsed := now.Sub(p.start).Seconds()
+	var speed float64
+	if elapsed > 0 {
+		speed = float64(p.done) / elapsed
+	}
+
+	var line strings.Builder
+	fmt.Fprintf(&line, "  %s", p.name)
+	if p.total > 0 {
+		fmt.Fprintf(&line, " %3d%% %s/%s", p.done*100/p.total, formatSize(p.done), formatSize(p.total))
+	} else {
+		fmt.Fprintf(&line, " %s", formatSize(p.done))
+	}
+	fmt.Fprintf(&line, " %s/s", formatSize(int64(speed)))
+	if p.total > p.done && speed > 0 {
+		eta := time.Duration(float64(p.total-p.done) / speed * float64(time.Second))
+		fmt.Fprintf(&line, " ETA %s", eta.Round(time.Second))
+	}
+	// \r returns to the start of the line and \033[K clears the rest of it
+	fmt.Fprintf(p.out, "\r%s\033[K", line.String())
+}
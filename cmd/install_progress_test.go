@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressWriter(t *testing.T) {
+	var out bytes.Buffer
+	start := time.Unix(0, 0)
+	now := start
+	p := &progressWriter{out: &out, name: "tool.tar.gz", total: 4 << 20, start: start, now: func() time.Time { return now }}
+
+	// Nothing is drawn before progressDelay
+	p.Write(make([]byte, 1<<20))
+	if out.Len() != 0 {
+		t.Fatalf("progress drawn before the delay: %q", out.String())
+	}
+
+	now = start.Add(time.Second)
+	p.Write(make([]byte, 1<<20))
+	if got := out.String(); !strings.Contains(got, "tool.tar.gz  50% 2.0MiB/4.0MiB 2.0MiB/s ETA 1s") {
+		t.Errorf("progress line = %q", got)
+	}
+
+	// Updates are throttled to progressInterval
+	out.Reset()
+	p.Write(make([]byte, 1<<20))
+	if out.Len() != 0 {
+		t.Errorf("progress redrawn within the interval: %q", out.String())
+	}
+
+	now = start.Add(2 * time.Second)
+	p.Write(make([]byte, 1<<20))
+	p.finish()
+	if got := out.String(); !strings.Contains(got, "100% 4.0MiB/4.0MiB 2.0MiB/s\x1b[K") || !strings.HasSuffix(got, "\n") {
+		t.Errorf("final progress line = %q", got)
+	}
+}
+
+func TestProgressWriterDisabled(t *testing.T) {
+	old := progressOutput
+	defer func() { progressOutput = old }()
+	progressOutput = func() io.Writer { return nil }
+	if p := newProgressWriter("tool", 10); p != nil {
+		t.Error("newProgressWriter() returned a writer with progress disabled")
+	}
+
+	// Small downloads never show progress
+	var out bytes.Buffer
+	progressOutput = func() io.Writer { return &out }
+	p := newProgressWriter("tool", 10)
+	p.Write(make([]byte, 10))
+	p.finish()
+	if out.Len() != 0 {
+		t.Errorf("fast download drew %q", out.String())
+	}
+}
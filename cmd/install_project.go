@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/receipt"
+)
+
+// projectEnvFile is the file in the project directory that adds the project's
+// tools to the environment when sourced
+const projectEnvFile = "env"
+
+// projectMarkers are the files and directories, relative to a directory, that
+// make it the root of a project
+var projectMarkers = []string{receipt.ProjectDir, ProjectToolsDir, DefaultConfigPathYML, DefaultConfigPathYAML, ".git"}
+
+// validateProjectInstall checks that --project is not combined with flags
+// choosing another install directory
+func validateProjectInstall() error {
+	if !installProject {
+		return nil
+	}
+	if installBinDir != "" {
+		return fmt.Errorf("--project and --bin-dir cannot be combined")
+	}
+	if installOutput != "" {
+		return fmt.Errorf("--project and --output cannot be combined")
+	}
+	return nil
+}
+
+// projectRoot returns the root of the project dir is in: the nearest directory
+// up from dir holding one of projectMarkers, or dir itself when there is none
+func projectRoot(dir string) string {
+	for d := dir; ; {
+		for _, marker := range projectMarkers {
+			if _, err := os.Stat(filepath.Join(d, filepath.FromSlash(marker))); err == nil {
+				return d
+			}
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return dir
+		}
+		d = parent
+	}
+}
+
+// projectBinDir returns the directory --project installs into,
+// PROJECT/.binstaller/bin for the project of the working directory
+func projectBinDir() (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get working directory: %w", err)
+	}
+	return filepath.Join(projectRoot(wd), receipt.ProjectDir, "bin"), nil
+}
+
+// writeProjectEnv writes the env file of the project whose bin directory is
+// binDir, which adds binDir to PATH and exports the runtime_env of every tool
+// installed into it, and keeps the project directory out of git
+func writeProjectEnv(binDir string) error {
+	store := receipt.ProjectStore(binDir)
+	if store == nil {
+		return fmt.Errorf("%s is not a project bin directory", binDir)
+	}
+	receipts, err := store.List()
+	if err != nil {
+		return fmt.Errorf("failed to read the receipts of the project: %w", err)
+	}
+	env := make(map[string]string)
+	for _, r := range receipts {
+		maps.Copy(env, r.RuntimeEnv)
+	}
+
+	projectDir := filepath.Dir(binDir)
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# Tools of this project installed by 'binst install --project'.\n# Source this file to use them: . %s/%s\n", receipt.ProjectDir, projectEnvFile)
+	if err := printEnv(&buf, []string{binDir}); err != nil {
+		return err
+	}
+	if err := printRuntimeEnv(&buf, env); err != nil {
+		return err
+	}
+	path := filepath.Join(projectDir, projectEnvFile)
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	log.Infof("Run '. %s' to use the tools of the project", path)
+	return ignoreProjectDir(filepath.Dir(projectDir))
+}
+
+// ignoreProjectDir adds the project directory to the .gitignore of the project
+// root, unless an entry already ignores it
+func ignoreProjectDir(root string) error {
+	path := filepath.Join(root, ".gitignore")
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.Trim(strings.TrimSpace(line), "/") == receipt.ProjectDir {
+			return nil
+		}
+	}
+
+	entry := "/" + receipt.ProjectDir + "/\n"
+	if len(data) > 0 && !bytes.HasSuffix(data, []byte("\n")) {
+		entry = "\n" + entry
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", path, err)
+	}
+	if _, err := f.WriteString(entry); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to update %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to update %s: %w", path, err)
+	}
+	log.Infof("Added /%s/ to %s", receipt.ProjectDir, path)
+	return nil
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/receipt"
+)
+
+func TestProjectRoot(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "src", "pkg")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := projectRoot(sub); got != sub {
+		t.Errorf("projectRoot() without markers = %s, want the directory itself", got)
+	}
+	if err := os.MkdirAll(filepath.Join(root, ".config", "binstaller"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := projectRoot(sub); got != root {
+		t.Errorf("projectRoot() = %s, want %s", got, root)
+	}
+}
+
+func TestValidateProjectInstall(t *testing.T) {
+	defer func() { installProject, installBinDir, installOutput = false, "", "" }()
+	installProject = true
+	if err := validateProjectInstall(); err != nil {
+		t.Errorf("validateProjectInstall() error = %v", err)
+	}
+	installBinDir = "/usr/local/bin"
+	if err := validateProjectInstall(); err == nil || !strings.Contains(err.Error(), "--bin-dir") {
+		t.Errorf("validateProjectInstall() with --bin-dir error = %v", err)
+	}
+	installBinDir, installOutput = "", "dist"
+	if err := validateProjectInstall(); err == nil || !strings.Contains(err.Error(), "--output") {
+		t.Errorf("validateProjectInstall() with --output error = %v", err)
+	}
+}
+
+func TestWriteProjectEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, ".gitignore"), "node_modules", 0644)
+	binDir := filepath.Join(root, receipt.ProjectDir, "bin")
+	store := receipt.ProjectStore(binDir)
+	for name, env := range map[string]map[string]string{
+		"go":   {"GOROOT": filepath.Join(root, receipt.ProjectDir, "go")},
+		"tool": nil,
+	} {
+		if err := store.Write(&receipt.Receipt{Name: name, BinDir: binDir, RuntimeEnv: env}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Installing more tools regenerates the env file without duplicate entries
+	for i := 0; i < 2; i++ {
+		if err := writeProjectEnv(binDir); err != nil {
+			t.Fatalf("writeProjectEnv() error = %v", err)
+		}
+	}
+
+	c := exec.Command("sh", "-c", `. ./.binstaller/env && printf '%s\n%s' "$PATH" "$GOROOT"`)
+	c.Dir = root
+	c.Env = []string{"PATH=/usr/bin:/bin"}
+	out, err := c.Output()
+	if err != nil {
+		t.Fatalf("sourcing the env file failed: %v", err)
+	}
+	if want := binDir + ":/usr/bin:/bin\n" + filepath.Join(root, receipt.ProjectDir, "go"); string(out) != want {
+		t.Errorf("environment = %q, want %q", out, want)
+	}
+
+	gitignore, err := os.ReadFile(filepath.Join(root, ".gitignore"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(gitignore) != "node_modules\n/.binstaller/\n" {
+		t.Errorf(".gitignore = %q, want the project directory added once", gitignore)
+	}
+
+	if err := writeProjectEnv(filepath.Join(root, "bin")); err == nil {
+		t.Error("writeProjectEnv() accepted a directory outside a project")
+	}
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/filelock"
+	"github.com/binary-install/binstaller/pkg/receipt"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// toolLock is the install lock of a tool, held in the receipt store that
+// records the tool
+type toolLock struct {
+	store *receipt.Store
+	lock  *filelock.Lock
+}
+
+// lockToolInstall takes the install lock of a tool installed into binDir, so that
+// concurrent installs of the tool wait for each other instead of racing on its
+// binaries and receipt. Tools in shared locations are locked and recorded in
+// the system receipts directory, or in the user's when that is not writable.
+// Failing to lock does not fail the installation unless ctx is done.
+func lockToolInstall(ctx context.Context, installSpec *spec.InstallSpec, binDir string) (*toolLock, error) {
+	name := installSpec.GetName()
+	store, err := receipt.ForBinDir(binDir)
+	if err != nil {
+		log.Warnf("Failed to find the receipts directory of %s: %v", name, err)
+		return &toolLock{}, nil
+	}
+	lock, err := store.TryLock(name)
+	if err != nil && !errors.Is(err, filelock.ErrLocked) && store.Dir == receipt.SystemDir() {
+		log.Debugf("Using the user receipts directory, the system one is not usable: %v", err)
+		if store, err = receipt.New(); err != nil {
+			log.Warnf("Failed to find the receipts directory of %s: %v", name, err)
+			return &toolLock{}, nil
+		}
+		lock, err = store.TryLock(name)
+	}
+	if errors.Is(err, filelock.ErrLocked) {
+		log.Infof("Waiting for another binst to finish installing %s", name)
+		lock, err = store.Lock(ctx, name)
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		log.Warnf("Installing %s without a lock: %v", name, err)
+	}
+	return &toolLock{store: store, lock: lock}, nil
+}
+
+// release releases the install lock
+func (l *toolLock) release() {
+	if l.lock == nil {
+		return
+	}
+	if err := l.lock.Release(); err != nil {
+		log.Warnf("Failed to release the install lock: %v", err)
+	}
+}
+
+// recordReceipt writes the receipt of a tool installed into binDir. Failing to
+// record it does not fail the installation.
+func (l *toolLock) recordReceipt(installSpec *spec.InstallSpec, tag, binDir string) {
+	if l.store == nil {
+		return
+	}
+	r, err := newReceipt(installSpec, tag, binDir)
+	if err == nil {
+		err = l.store.Write(r)
+	}
+	if err != nil {
+		log.Warnf("Failed to record the install receipt of %s: %v", installSpec.GetName(), err)
+	}
+}
+
+// newReceipt returns the receipt of a tool installed into binDir
+func newReceipt(installSpec *spec.InstallSpec, tag, binDir string) (*receipt.Receipt, error) {
+	abs, err := filepath.Abs(binDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", binDir, err)
+	}
+	osName, arch := detectPlatform(installSpec)
+	var binaries []string
+	for _, binary := range getBinariesForPlatform(installSpec, osName, arch) {
+		name := spec.StringValue(binary.Name)
+		if name == "" {
+			name = installSpec.GetName()
+		}
+		binaries = append(binaries, name)
+	}
+	return &receipt.Receipt{
+		Name:        installSpec.GetName(),
+		Repo:        installSpec.GetRepo(),
+		Tag:         tag,
+		BinDir:      abs,
+		Binaries:    binaries,
+		RuntimeEnv:  installSpec.ExpandRuntimeEnv(abs),
+		InstalledAt: time.Now().UTC(),
+	}, nil
+}
+
+// expandRuntimeEnv returns the runtime_env of a tool installed into binDir
+func expandRuntimeEnv(installSpec *spec.InstallSpec, binDir string) map[string]string {
+	if abs, err := filepath.Abs(binDir); err == nil {
+		binDir = abs
+	}
+	return installSpec.ExpandRuntimeEnv(binDir)
+}
+
+// printRuntimeEnv writes POSIX shell lines exporting env, sorted by name, for
+// --print-env
+func printRuntimeEnv(w io.Writer, env map[string]string) error {
+	for _, name := range slices.Sorted(maps.Keys(env)) {
+		if _, err := fmt.Fprintf(w, "export %s=%s\n", name, shellQuote(env[name])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/binary-install/binstaller/pkg/receipt"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+func TestMain(m *testing.M) {
+	// Keep receipts of test installs out of the user and system directories
+	dir, err := os.MkdirTemp("", "binst-receipts-")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv(receipt.EnvDir, dir)
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestLockToolInstall(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(receipt.EnvDir, "")
+	systemDir := filepath.Join(t.TempDir(), "system")
+	t.Setenv(receipt.EnvSystemDir, systemDir)
+	installSpec := &spec.InstallSpec{Name: spec.StringPtr("tool"), Repo: spec.StringPtr("owner/tool")}
+	binDir := filepath.Join(t.TempDir(), "bin")
+
+	lock, err := lockToolInstall(context.Background(), installSpec, binDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lock.store == nil || lock.store.Dir != systemDir {
+		t.Fatalf("lockToolInstall() of a shared bin dir uses store %+v, want %s", lock.store, systemDir)
+	}
+
+	// A second install waits for the first
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if _, err := lockToolInstall(ctx, installSpec, binDir); err == nil {
+		t.Fatal("lockToolInstall() of a locked tool did not wait")
+	}
+
+	lock.recordReceipt(installSpec, "v1.0.0", binDir)
+	lock.release()
+	r, err := lock.store.Read("tool")
+	if err != nil || r == nil || r.Tag != "v1.0.0" {
+		t.Fatalf("receipt after install = %+v, %v, want tag v1.0.0", r, err)
+	}
+
+	lock, err = lockToolInstall(context.Background(), installSpec, binDir)
+	if err != nil {
+		t.Fatalf("lockToolInstall() after release: %v", err)
+	}
+	lock.release()
+}
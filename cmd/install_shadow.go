@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/pkgmgr"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// installForce installs even when a package manager's binary shadows the installed one
+var installForce bool
+
+// shadowOwner finds the package that installed a file (overridable for tests)
+var shadowOwner = func(ctx context.Context, path string) *pkgmgr.Package {
+	return pkgmgr.NewProber().FindOwner(ctx, path)
+}
+
+// findShadowing returns the executable named name that PATH finds before
+// binDir, or "" when PATH finds the one in binDir first
+func findShadowing(name, binDir string, pathDirs []string) string {
+	absBinDir, err := filepath.Abs(binDir)
+	if err != nil {
+		return ""
+	}
+	installed, _ := os.Stat(filepath.Join(absBinDir, name))
+	for _, dir := range pathDirs {
+		if dir == "" {
+			continue
+		}
+		if abs, err := filepath.Abs(dir); err == nil && abs == absBinDir {
+			return ""
+		}
+		candidate := filepath.Join(dir, name)
+		info, err := os.Stat(candidate)
+		if err != nil || info.IsDir() || (runtime.GOOS != "windows" && info.Mode()&0o111 == 0) {
+			continue
+		}
+		// A PATH entry linking to binDir runs the installed binary too
+		if installed != nil && os.SameFile(info, installed) {
+			return ""
+		}
+		return candidate
+	}
+	return ""
+}
+
+// checkShadowing warns when PATH finds other executables before the binaries
+// installed into binDir, so the old version would keep running. Executables
+// installed by a package manager such as Homebrew or apt fail the installation
+// unless --force or dryRun.
+func checkShadowing(ctx context.Context, installSpec *spec.InstallSpec, binDir string, dryRun bool) error {
+	osName, arch := detectPlatform(installSpec)
+	pathDirs := filepath.SplitList(os.Getenv("PATH"))
+	for _, binary := range getBinariesForPlatform(installSpec, osName, arch) {
+		name := binary.GetName()
+		if name == "" {
+			name = installSpec.GetName()
+		}
+		if osName == "windows" {
+			name += ".exe"
+		}
+		found := findShadowing(name, binDir, pathDirs)
+		if found == "" {
+			continue
+		}
+		pkg := shadowOwner(ctx, found)
+		if pkg == nil {
+			log.Warnf("%s comes first on PATH and shadows %s", found, filepath.Join(binDir, name))
+			continue
+		}
+		msg := fmt.Sprintf("%s is installed by %s package %s and comes first on PATH, so it shadows %s; uninstall the package or put %s earlier on PATH",
+			found, pkg.Manager, pkg.Name, filepath.Join(binDir, name), binDir)
+		if installForce || dryRun {
+			log.Warn(msg)
+			continue
+		}
+		return fmt.Errorf("%s (--force installs anyway)", msg)
+	}
+	return nil
+}
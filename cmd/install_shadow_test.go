@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/pkgmgr"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+func TestCheckShadowing(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses POSIX executable bits")
+	}
+	brewBin := t.TempDir()
+	binDir := t.TempDir()
+	writeTestFile(t, filepath.Join(brewBin, "tool"), "#!/bin/sh\n", 0o755)
+	installSpec := spec.NewInstallSpec("owner/tool").WithAsset(spec.NewAsset("${NAME}${EXT}").WithBinary("tool", "tool"))
+
+	origOwner := shadowOwner
+	t.Cleanup(func() { shadowOwner = origOwner; installForce = false })
+	var owner *pkgmgr.Package
+	shadowOwner = func(ctx context.Context, path string) *pkgmgr.Package { return owner }
+
+	// binDir first on PATH: nothing is shadowed
+	t.Setenv("PATH", strings.Join([]string{binDir, brewBin}, string(os.PathListSeparator)))
+	owner = &pkgmgr.Package{Manager: "brew", Name: "tool", Installed: true}
+	if err := checkShadowing(context.Background(), installSpec, binDir, false); err != nil {
+		t.Fatalf("checkShadowing() with binDir first = %v", err)
+	}
+
+	t.Setenv("PATH", strings.Join([]string{brewBin, binDir}, string(os.PathListSeparator)))
+	err := checkShadowing(context.Background(), installSpec, binDir, false)
+	if err == nil || !strings.Contains(err.Error(), "brew package tool") || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("checkShadowing() of a Homebrew binary = %v, want an error naming the package", err)
+	}
+	if err := checkShadowing(context.Background(), installSpec, binDir, true); err != nil {
+		t.Errorf("checkShadowing() in a dry run = %v, want a warning only", err)
+	}
+	installForce = true
+	if err := checkShadowing(context.Background(), installSpec, binDir, false); err != nil {
+		t.Errorf("checkShadowing() with --force = %v", err)
+	}
+	installForce = false
+
+	// Executables no package manager installed only cause a warning
+	owner = nil
+	if err := checkShadowing(context.Background(), installSpec, binDir, false); err != nil {
+		t.Errorf("checkShadowing() of an unmanaged binary = %v", err)
+	}
+}
+
+func TestFindShadowing(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses POSIX executable bits and symlinks")
+	}
+	other := t.TempDir()
+	binDir := t.TempDir()
+	writeTestFile(t, filepath.Join(other, "data"), "", 0o644)
+	writeTestFile(t, filepath.Join(binDir, "tool"), "", 0o755)
+	link := t.TempDir()
+	if err := os.Symlink(filepath.Join(binDir, "tool"), filepath.Join(link, "tool")); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := findShadowing("data", binDir, []string{other, binDir}); got != "" {
+		t.Errorf("findShadowing() of a non-executable file = %q", got)
+	}
+	if got := findShadowing("tool", binDir, []string{link, binDir}); got != "" {
+		t.Errorf("findShadowing() of a link to the installed binary = %q", got)
+	}
+	writeTestFile(t, filepath.Join(other, "tool"), "", 0o755)
+	if got, want := findShadowing("tool", binDir, []string{"", other}), filepath.Join(other, "tool"); got != want {
+		t.Errorf("findShadowing() with binDir off PATH = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/httpclient"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// assetSource overrides where the asset of a release comes from. The asset is
+// verified against the checksums of the release under its filename either way.
+type assetSource struct {
+	// file is an already downloaded asset (--from-file)
+	file string
+	// url downloads the asset from outside the release (--asset-url)
+	url string
+	// name replaces the filename resolved from the asset templates (--asset-name)
+	name string
+}
+
+// installAssetSource returns the asset source of the install flags
+func installAssetSource() assetSource {
+	return assetSource{file: installFromFile, url: installAssetURL, name: installAssetName}
+}
+
+// overridesAsset reports whether the asset is not resolved from the templates,
+// so that it must not be cached as the release asset of the platform
+func (s assetSource) overridesAsset() bool {
+	return s.url != "" || s.name != ""
+}
+
+// validate checks the combination and values of the overrides
+func (s assetSource) validate() error {
+	if s.file != "" && s.url != "" {
+		return fmt.Errorf("--from-file and --asset-url cannot be combined")
+	}
+	if s.file != "" {
+		if _, err := os.Stat(s.file); err != nil {
+			return fmt.Errorf("failed to read local asset: %w", err)
+		}
+	}
+	if s.name != "" && (strings.ContainsAny(s.name, `/\`) || s.name == "." || s.name == "..") {
+		return fmt.Errorf("--asset-name must be a filename: %s", s.name)
+	}
+	if s.url != "" {
+		u, err := url.Parse(s.url)
+		if err != nil {
+			return fmt.Errorf("invalid --asset-url: %w", err)
+		}
+		if u.Scheme != "https" && u.Scheme != "http" {
+			return fmt.Errorf("--asset-url must be an http or https URL: %s", s.url)
+		}
+		if s.name == "" && urlFilename(u) == "" {
+			return fmt.Errorf("--asset-url has no filename, set it with --asset-name: %s", s.url)
+		}
+	}
+	return nil
+}
+
+// urlFilename returns the last path element of u, or "" when there is none
+func urlFilename(u *url.URL) string {
+	name := path.Base(u.Path)
+	if name == "/" || name == "." {
+		return ""
+	}
+	return name
+}
+
+// candidates returns the asset filenames to try, replacing the ones resolved
+// from the templates when the filename is overridden or a local file is used
+func (s assetSource) candidates(resolved []string) []string {
+	candidates := resolved
+	switch {
+	case s.name != "":
+		log.Infof("Using asset name %s instead of %s (--asset-name)", s.name, resolved[0])
+		candidates = []string{s.name}
+	case s.url != "":
+		// validate ensures the URL parses and has a filename
+		u, _ := url.Parse(s.url)
+		candidates = []string{urlFilename(u)}
+	}
+	if s.file != "" {
+		filename := localAssetFilename(s.file, candidates)
+		log.Infof("Using local asset %s as %s", s.file, filename)
+		candidates = []string{filename}
+	}
+	return candidates
+}
+
+// assetURL returns the URL the asset is downloaded from
+func (s assetSource) assetURL(installSpec *spec.InstallSpec, tag, filename string) string {
+	if s.url != "" {
+		return s.url
+	}
+	return releaseDownloadURL(installSpec, tag, filename)
+}
+
+// download puts the asset into dir and returns its filename, copying the local
+// file, downloading the URL override, or trying the candidates of the release.
+// candidates are the ones returned by s.candidates.
+func (s assetSource) download(ctx context.Context, installSpec *spec.InstallSpec, tag, dir string, candidates []string) (string, error) {
+	switch {
+	case s.file != "":
+		// Copy under the release filename so extraction sees the asset's real extension
+		if err := copyFile(s.file, filepath.Join(dir, candidates[0])); err != nil {
+			return "", fmt.Errorf("failed to copy local asset: %w", err)
+		}
+		return candidates[0], nil
+	case s.url != "":
+		log.Infof("Downloading %s (--asset-url)", s.url)
+		// The URL comes from the command line, so it never gets a GitHub or GitLab token
+		client := httpclient.NewClientWithPolicy(downloadRetryPolicy(installSpec))
+		if err := downloadWithClient(ctx, client, filepath.Join(dir, candidates[0]), s.url); err != nil {
+			return "", fmt.Errorf("failed to download asset: %w", err)
+		}
+		return candidates[0], nil
+	}
+	filename, err := downloadAssetCandidates(ctx, installSpec, tag, dir, candidates)
+	if err != nil {
+		return "", fmt.Errorf("failed to download asset: %w", err)
+	}
+	return filename, nil
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/httpclient"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+func TestAssetSourceValidate(t *testing.T) {
+	localAsset := filepath.Join(t.TempDir(), "tool.tar.gz")
+	writeTestFile(t, localAsset, "tool", 0644)
+
+	tests := []struct {
+		name    string
+		src     assetSource
+		wantErr string
+	}{
+		{"none", assetSource{}, ""},
+		{"file", assetSource{file: localAsset}, ""},
+		{"missing file", assetSource{file: localAsset + ".missing"}, "failed to read local asset"},
+		{"file and name", assetSource{file: localAsset, name: "tool_musl.tar.gz"}, ""},
+		{"file and url", assetSource{file: localAsset, url: "https://example.com/tool.tar.gz"}, "cannot be combined"},
+		{"url", assetSource{url: "https://example.com/hotfix/tool.tar.gz?token=x"}, ""},
+		{"url without filename", assetSource{url: "https://example.com/"}, "has no filename"},
+		{"url without filename and name", assetSource{url: "https://example.com/", name: "tool.tar.gz"}, ""},
+		{"url scheme", assetSource{url: "file:///tmp/tool.tar.gz"}, "http or https"},
+		{"name with directory", assetSource{name: "../tool.tar.gz"}, "must be a filename"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.src.validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInstallReleaseAssetOverrides(t *testing.T) {
+	t.Setenv("BINSTALLER_OS_VERSION", "")
+	t.Setenv("BINSTALLER_CACHE_DIR", t.TempDir())
+
+	filename := fmt.Sprintf("tool_1.0.0_%s_%s", runtime.GOOS, runtime.GOARCH)
+	files := map[string][]byte{
+		"/owner/tool/releases/download/v1.0.0/" + filename:           []byte("#!/bin/sh\necho release\n"),
+		"/owner/tool/releases/download/v1.0.0/" + filename + "_musl": []byte("#!/bin/sh\necho musl\n"),
+		"/hotfix/" + filename: []byte("#!/bin/sh\necho hotfix\n"),
+		"/hotfix/unlisted":    []byte("#!/bin/sh\necho unlisted\n"),
+	}
+	var leaked atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/hotfix/") && r.Header.Get("Authorization") != "" {
+			leaked.Store(true)
+		}
+		content, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(content)
+	}))
+	defer server.Close()
+	oldURL := gitHubDownloadBaseURL
+	gitHubDownloadBaseURL = server.URL
+	defer func() { gitHubDownloadBaseURL = oldURL }()
+
+	hash := func(path string) string { return fmt.Sprintf("%x", sha256.Sum256(files[path])) }
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${VERSION}_${OS}_${ARCH}")).
+		WithChecksums(spec.NewChecksums("").
+			WithRequired(true).
+			WithEmbeddedChecksum("v1.0.0", filename, hash("/hotfix/"+filename)).
+			WithEmbeddedChecksum("v1.0.0", filename+"_musl", hash("/owner/tool/releases/download/v1.0.0/"+filename+"_musl")))
+	installSpec.SetDefaults()
+
+	// --asset-url never gets a token, even on a known GitHub host
+	t.Setenv("GH_ENTERPRISE_TOKEN", "ghe_secret")
+	ctx := httpclient.WithGitHubHost(context.Background(), strings.TrimPrefix(server.URL, "http://"))
+
+	tests := []struct {
+		name    string
+		src     assetSource
+		want    string
+		wantErr string
+	}{
+		{name: "asset name", src: assetSource{name: filename + "_musl"}, want: "musl"},
+		{name: "asset url", src: assetSource{url: server.URL + "/hotfix/" + filename}, want: "hotfix"},
+		{name: "asset url not in checksums", src: assetSource{url: server.URL + "/hotfix/unlisted"}, wantErr: "checksum"},
+		{name: "asset url renamed", src: assetSource{url: server.URL + "/hotfix/unlisted", name: filename}, wantErr: "checksum verification failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			binDir := t.TempDir()
+			_, err := installRelease(ctx, installSpec, "v1.0.0", binDir, false, tt.src)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("installRelease() error = %v, want %q", err, tt.wantErr)
+				}
+				if _, err := os.Stat(filepath.Join(binDir, "tool")); !os.IsNotExist(err) {
+					t.Errorf("binary should not be installed when verification fails")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("installRelease() error = %v", err)
+			}
+			installed, err := os.ReadFile(filepath.Join(binDir, "tool"))
+			if err != nil || !strings.Contains(string(installed), tt.want) {
+				t.Errorf("installed binary = %q, %v, want the %s build", installed, err, tt.want)
+			}
+		})
+	}
+	if leaked.Load() {
+		t.Error("--asset-url download sent an Authorization header")
+	}
+}
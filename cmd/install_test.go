@@ -2,15 +2,20 @@ package cmd
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/binary-install/binstaller/pkg/httpclient"
 	"github.com/binary-install/binstaller/pkg/spec"
 )
 
@@ -119,7 +124,9 @@ func TestResolveVersion(t *testing.T) {
 				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 					// Verify request path
 					expectedPath := "/repos/" + tt.repo + "/releases/latest"
-					if r.URL.Path != expectedPath {
+					// A missing latest release falls back to the tags
+					fallback := tt.serverStatus == http.StatusNotFound && r.URL.Path == "/repos/"+tt.repo+"/tags"
+					if r.URL.Path != expectedPath && !fallback {
 						t.Errorf("unexpected path: got %s, want %s", r.URL.Path, expectedPath)
 					}
 
@@ -142,7 +149,7 @@ func TestResolveVersion(t *testing.T) {
 			}
 
 			ctx := context.Background()
-			version, err := resolveVersion(ctx, tt.repo, tt.inputVersion)
+			version, err := resolveVersion(ctx, spec.NewInstallSpec(tt.repo), tt.inputVersion)
 
 			if tt.expectedError {
 				if err == nil {
@@ -182,6 +189,44 @@ func TestInstallCommandFlags(t *testing.T) {
 	}
 }
 
+func TestResolveInstallBinDir(t *testing.T) {
+	t.Setenv("HOME", "/home/me")
+	t.Setenv("BINSTALLER_BIN", "")
+
+	tests := []struct {
+		name          string
+		flag          string
+		defaultBinDir string
+		env           string
+		want          string
+	}{
+		{"default", "", "", "", filepath.Join("/home/me", ".local", "bin")},
+		{"builtin default template", "", spec.DefaultBinDirValue, "", filepath.Join("/home/me", ".local", "bin")},
+		{"env", "", spec.DefaultBinDirValue, "/env/bin", "/env/bin"},
+		{"overlay", "", "${HOME}/bin", "/env/bin", "/home/me/bin"},
+		{"overlay with env fallback", "", "${BINSTALLER_BIN:-/opt/org/bin}", "", "/opt/org/bin"},
+		{"flag wins", "/flag/bin", "/opt/org/bin", "/env/bin", "/flag/bin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("BINSTALLER_BIN", tt.env)
+			oldFlag := installBinDir
+			installBinDir = tt.flag
+			defer func() { installBinDir = oldFlag }()
+
+			installSpec := spec.NewInstallSpec("owner/tool")
+			installSpec.DefaultBinDir = spec.StringPtrOrNil(tt.defaultBinDir)
+			got, err := resolveInstallBinDir(installSpec)
+			if err != nil {
+				t.Fatalf("resolveInstallBinDir() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("resolveInstallBinDir() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestInstallCommandArgs(t *testing.T) {
 	cmd := InstallCommand
 
@@ -309,7 +354,7 @@ func TestDownload(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := download(context.Background(), tt.destPath, tt.url)
+			err := download(context.Background(), nil, tt.destPath, tt.url)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("download() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -317,6 +362,32 @@ func TestDownload(t *testing.T) {
 	}
 }
 
+func TestDownloadRetryPolicy(t *testing.T) {
+	t.Setenv("BINSTALLER_HTTP_RETRIES", "")
+	if got := downloadRetryPolicy(nil); got != httpclient.DefaultRetryPolicy {
+		t.Errorf("downloadRetryPolicy(nil) = %+v, want the default policy", got)
+	}
+
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithDownload((&spec.Download{}).WithRetries(0).WithTimeout(120))
+	got := downloadRetryPolicy(installSpec)
+	if got.Attempts != 1 || got.Timeout != 2*time.Minute {
+		t.Errorf("downloadRetryPolicy() = %+v, want 1 attempt of at most 2m", got)
+	}
+
+	installSpec.Download.WithRetries(5).WithRetryDelay(45)
+	got = downloadRetryPolicy(installSpec)
+	if got.Attempts != 6 || got.MinBackoff != 45*time.Second || got.MaxBackoff < got.MinBackoff {
+		t.Errorf("downloadRetryPolicy() = %+v, want 6 attempts 45s apart", got)
+	}
+
+	// BINSTALLER_HTTP_RETRIES overrides the retries of the spec
+	t.Setenv("BINSTALLER_HTTP_RETRIES", "1")
+	if got := downloadRetryPolicy(installSpec); got.Attempts != 2 {
+		t.Errorf("downloadRetryPolicy() with BINSTALLER_HTTP_RETRIES=1 attempts = %d, want 2", got.Attempts)
+	}
+}
+
 // Helper function to map Go arch to shell script conventions
 func mapGoArchToShellArch(goArch string) string {
 	switch goArch {
@@ -664,8 +735,28 @@ func TestMatchesRule(t *testing.T) {
 			arch:    "amd64",
 			matches: false,
 		},
+		{
+			name: "Match OS version",
+			when: &spec.When{
+				OS:        stringPtr("linux"),
+				OSVersion: stringPtr("alpine"),
+			},
+			osName:  "linux",
+			arch:    "amd64",
+			matches: true,
+		},
+		{
+			name: "OS version mismatch",
+			when: &spec.When{
+				OSVersion: stringPtr("ubuntu-*"),
+			},
+			osName:  "linux",
+			arch:    "amd64",
+			matches: false,
+		},
 	}
 
+	t.Setenv("BINSTALLER_OS_VERSION", "alpine-3.20.3")
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			if got := matchesRule(tt.when, tt.osName, tt.arch); got != tt.matches {
@@ -747,3 +838,110 @@ func TestInstallBinary(t *testing.T) {
 func stringPtr(s string) *string {
 	return &s
 }
+
+func TestDownloadAssetCandidates(t *testing.T) {
+	// The server error is persistent, do not wait for retries
+	t.Setenv("BINSTALLER_HTTP_RETRIES", "0")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/owner/tool/releases/download/v1.0.0/tool-gnu.tar.gz":
+			w.Write([]byte("gnu"))
+		case "/owner/tool/releases/download/v1.0.0/tool-broken.tar.gz":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	oldURL := gitHubDownloadBaseURL
+	gitHubDownloadBaseURL = server.URL
+	defer func() { gitHubDownloadBaseURL = oldURL }()
+
+	dir := t.TempDir()
+	got, err := downloadAssetCandidates(context.Background(), spec.NewInstallSpec("owner/tool"), "v1.0.0", dir, []string{"tool-musl.tar.gz", "tool-gnu.tar.gz"})
+	if err != nil {
+		t.Fatalf("downloadAssetCandidates() error = %v", err)
+	}
+	if got != "tool-gnu.tar.gz" {
+		t.Errorf("downloadAssetCandidates() = %q, want tool-gnu.tar.gz", got)
+	}
+	if content, err := os.ReadFile(filepath.Join(dir, got)); err != nil || string(content) != "gnu" {
+		t.Errorf("downloaded content = %q, %v", content, err)
+	}
+
+	// Only a missing asset moves on to the next candidate
+	if _, err := downloadAssetCandidates(context.Background(), spec.NewInstallSpec("owner/tool"), "v1.0.0", dir, []string{"tool-broken.tar.gz", "tool-gnu.tar.gz"}); err == nil {
+		t.Error("downloadAssetCandidates() should fail on a server error")
+	}
+	_, err = downloadAssetCandidates(context.Background(), spec.NewInstallSpec("owner/tool"), "v1.0.0", dir, []string{"tool-musl.tar.gz", "tool-none.tar.gz"})
+	if !errors.Is(err, errAssetNotFound) {
+		t.Errorf("downloadAssetCandidates() error = %v, want errAssetNotFound", err)
+	}
+}
+
+func TestDownloadAssetCandidatesGitLab(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/group/sub/tool/-/releases/v1.0.0/downloads/tool.tar.gz" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("gitlab"))
+	}))
+	defer server.Close()
+	oldURL := gitLabBaseURL
+	gitLabBaseURL = server.URL
+	defer func() { gitLabBaseURL = oldURL }()
+
+	dir := t.TempDir()
+	installSpec := spec.NewInstallSpec("group/sub/tool").WithSource(spec.Gitlab, "")
+	got, err := downloadAssetCandidates(context.Background(), installSpec, "v1.0.0", dir, []string{"tool.tar.gz"})
+	if err != nil {
+		t.Fatalf("downloadAssetCandidates() error = %v", err)
+	}
+	if content, err := os.ReadFile(filepath.Join(dir, got)); err != nil || string(content) != "gitlab" {
+		t.Errorf("downloaded content = %q, %v", content, err)
+	}
+}
+
+func TestLocalAssetFilename(t *testing.T) {
+	candidates := []string{"tool-musl.tar.gz", "tool-gnu.tar.gz"}
+	if got := localAssetFilename("/downloads/tool-gnu.tar.gz", candidates); got != "tool-gnu.tar.gz" {
+		t.Errorf("localAssetFilename() = %q, want tool-gnu.tar.gz", got)
+	}
+	if got := localAssetFilename("/downloads/tool (1).tar.gz", candidates); got != "tool-musl.tar.gz" {
+		t.Errorf("localAssetFilename() = %q, want the primary candidate", got)
+	}
+}
+
+func TestInstallReleaseFromFile(t *testing.T) {
+	t.Setenv("BINSTALLER_OS_VERSION", "")
+	content := []byte("#!/bin/sh\necho tool\n")
+	hash := fmt.Sprintf("%x", sha256.Sum256(content))
+	osName, arch := runtime.GOOS, runtime.GOARCH
+	filename := fmt.Sprintf("tool_1.0.0_%s_%s", osName, arch)
+
+	localAsset := filepath.Join(t.TempDir(), "downloaded-tool")
+	writeTestFile(t, localAsset, string(content), 0644)
+
+	newSpec := func(hash string) *spec.InstallSpec {
+		s := spec.NewInstallSpec("owner/tool").
+			WithAsset(spec.NewAsset("${NAME}_${VERSION}_${OS}_${ARCH}")).
+			WithChecksums(spec.NewChecksums("").WithEmbeddedChecksum("v1.0.0", filename, hash))
+		s.SetDefaults()
+		return s
+	}
+
+	binDir := t.TempDir()
+	if _, err := installRelease(context.Background(), newSpec(hash), "v1.0.0", binDir, false, assetSource{file: localAsset}); err != nil {
+		t.Fatalf("installRelease() error = %v", err)
+	}
+	installed, err := os.ReadFile(filepath.Join(binDir, "tool"))
+	if err != nil || string(installed) != string(content) {
+		t.Errorf("installed binary = %q, %v", installed, err)
+	}
+
+	// The local asset is still verified
+	if _, err := installRelease(context.Background(), newSpec(strings.Repeat("0", 64)), "v1.0.0", t.TempDir(), false, assetSource{file: localAsset}); err == nil {
+		t.Error("installRelease() should reject a local asset with a mismatching checksum")
+	}
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// gitHubToolCacheRoot returns the hosted tool cache directory when running inside
+// GitHub Actions, or "" elsewhere
+func gitHubToolCacheRoot() string {
+	if os.Getenv("GITHUB_ACTIONS") != "true" {
+		return ""
+	}
+	return os.Getenv("RUNNER_TOOL_CACHE")
+}
+
+// installToolCacheRoot returns the tool cache directory binst install uses, or ""
+// when --bin-dir, --project or --no-tool-cache is set or not running in GitHub Actions.
+// An overridden asset is not installed there either, since a later job would
+// take it for the release.
+func installToolCacheRoot() string {
+	if installBinDir != "" || installProject || installNoToolCache || installAssetSource().overridesAsset() {
+		return ""
+	}
+	return gitHubToolCacheRoot()
+}
+
+// toolCacheArch maps a Go architecture to the directory name of the
+// actions/tool-cache layout, which follows Node's process.arch
+func toolCacheArch(goarch string) string {
+	switch goarch {
+	case "amd64":
+		return "x64"
+	case "386":
+		return "x86"
+	}
+	return goarch
+}
+
+// toolCacheEntry is a tool version in the hosted tool cache, laid out as
+// actions/tool-cache does: ROOT/NAME/VERSION/ARCH holds the binaries and
+// ROOT/NAME/VERSION/ARCH.complete marks a finished install
+type toolCacheEntry struct {
+	// VersionDir is ROOT/NAME/VERSION, the directory to cache across jobs
+	VersionDir string
+	// BinDir is ROOT/NAME/VERSION/ARCH
+	BinDir string
+	// Key is a cache key identifying the tool version and runner platform
+	Key string
+}
+
+// newToolCacheEntry returns the tool cache entry of a tool version
+func newToolCacheEntry(root, name, version string) toolCacheEntry {
+	version = strings.TrimPrefix(version, "v")
+	arch := toolCacheArch(runtime.GOARCH)
+	versionDir := filepath.Join(root, name, version)
+	return toolCacheEntry{
+		VersionDir: versionDir,
+		BinDir:     filepath.Join(versionDir, arch),
+		Key:        fmt.Sprintf("binst-%s-%s-%s-%s", name, version, runtime.GOOS, arch),
+	}
+}
+
+// markerPath returns the path of the file marking the entry complete
+func (e toolCacheEntry) markerPath() string {
+	return e.BinDir + ".complete"
+}
+
+// installToolCached installs a release of spec into the GitHub Actions tool cache
+// under root, skipping the download when a previous job already installed the
+// same version. The tool cache directory is added to $GITHUB_PATH, and cache
+// hints are logged and written to $GITHUB_OUTPUT. It returns the bin dir and
+// the resolved tag.
+func installToolCached(ctx context.Context, installSpec *spec.InstallSpec, version, root string, dryRun bool, src assetSource) (string, string, error) {
+	tag, err := resolveVersion(ctx, installSpec, version)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve version: %w", err)
+	}
+	entry := newToolCacheEntry(root, installSpec.GetName(), tag)
+
+	if _, err := os.Stat(entry.markerPath()); err == nil {
+		log.Infof("Found %s %s in the tool cache: %s", installSpec.GetName(), tag, entry.BinDir)
+	} else {
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", "", fmt.Errorf("failed to check the tool cache: %w", err)
+		}
+		if _, err := installRelease(ctx, installSpec, tag, entry.BinDir, dryRun, src); err != nil {
+			return "", "", err
+		}
+		if !dryRun {
+			if err := os.WriteFile(entry.markerPath(), nil, 0644); err != nil {
+				return "", "", fmt.Errorf("failed to mark the tool cache entry complete: %w", err)
+			}
+		}
+	}
+	if dryRun {
+		return entry.BinDir, tag, nil
+	}
+
+	if err := appendGitHubFile("GITHUB_PATH", entry.BinDir); err != nil {
+		return "", "", err
+	}
+	if err := appendGitHubFile("GITHUB_OUTPUT", "tool-cache-path="+entry.VersionDir, "tool-cache-key="+entry.Key); err != nil {
+		return "", "", err
+	}
+	log.Infof("Restore %s in later jobs by caching the tool cache entry, e.g. with actions/cache:", installSpec.GetName())
+	log.Infof("  path: %s", entry.VersionDir)
+	log.Infof("  key: %s", entry.Key)
+	return entry.BinDir, tag, nil
+}
+
+// appendGitHubFile appends lines to the GitHub Actions file named by an
+// environment variable such as GITHUB_PATH, doing nothing when it is unset
+func appendGitHubFile(env string, lines ...string) error {
+	path := os.Getenv(env)
+	if path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open $%s: %w", env, err)
+	}
+	defer f.Close()
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(f, line); err != nil {
+			return fmt.Errorf("failed to write $%s: %w", env, err)
+		}
+	}
+	return nil
+}
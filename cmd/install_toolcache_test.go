@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+func TestInstallToolCached(t *testing.T) {
+	t.Setenv("BINSTALLER_OS_VERSION", "")
+	root := t.TempDir()
+	ghDir := t.TempDir()
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("RUNNER_TOOL_CACHE", root)
+	t.Setenv("GITHUB_PATH", filepath.Join(ghDir, "path"))
+	t.Setenv("GITHUB_OUTPUT", filepath.Join(ghDir, "output"))
+
+	content := "#!/bin/sh\necho tool\n"
+	filename := fmt.Sprintf("tool_1.0.0_%s_%s", runtime.GOOS, runtime.GOARCH)
+	localAsset := filepath.Join(t.TempDir(), "downloaded-tool")
+	writeTestFile(t, localAsset, content, 0644)
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${VERSION}_${OS}_${ARCH}")).
+		WithChecksums(spec.NewChecksums("").WithEmbeddedChecksum("v1.0.0", filename, fmt.Sprintf("%x", sha256.Sum256([]byte(content)))))
+	installSpec.SetDefaults()
+
+	if got := installToolCacheRoot(); got != root {
+		t.Fatalf("installToolCacheRoot() = %q, want %q", got, root)
+	}
+
+	binDir, tag, err := installToolCached(context.Background(), installSpec, "v1.0.0", root, false, assetSource{file: localAsset})
+	if err != nil {
+		t.Fatalf("installToolCached() error = %v", err)
+	}
+	wantDir := filepath.Join(root, "tool", "1.0.0", toolCacheArch(runtime.GOARCH))
+	if binDir != wantDir || tag != "v1.0.0" {
+		t.Errorf("installToolCached() = %q, %q, want %q, v1.0.0", binDir, tag, wantDir)
+	}
+	if _, err := os.Stat(filepath.Join(binDir, "tool")); err != nil {
+		t.Errorf("binary not installed into the tool cache: %v", err)
+	}
+	if _, err := os.Stat(binDir + ".complete"); err != nil {
+		t.Errorf("tool cache entry not marked complete: %v", err)
+	}
+
+	// A later job finds the entry without installing it again
+	if _, _, err := installToolCached(context.Background(), installSpec, "v1.0.0", root, false, assetSource{file: filepath.Join(t.TempDir(), "missing")}); err != nil {
+		t.Fatalf("installToolCached() with a cached entry error = %v", err)
+	}
+
+	path, _ := os.ReadFile(filepath.Join(ghDir, "path"))
+	if strings.TrimSpace(strings.Split(string(path), "\n")[0]) != wantDir {
+		t.Errorf("$GITHUB_PATH = %q, want %s", path, wantDir)
+	}
+	output, _ := os.ReadFile(filepath.Join(ghDir, "output"))
+	wantKey := fmt.Sprintf("tool-cache-key=binst-tool-1.0.0-%s-%s", runtime.GOOS, toolCacheArch(runtime.GOARCH))
+	if !strings.Contains(string(output), "tool-cache-path="+filepath.Join(root, "tool", "1.0.0")) || !strings.Contains(string(output), wantKey) {
+		t.Errorf("$GITHUB_OUTPUT = %q, want the cache path and %s", output, wantKey)
+	}
+}
+
+func TestInstallToolCacheRootOptOut(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("RUNNER_TOOL_CACHE", t.TempDir())
+	old := installNoToolCache
+	installNoToolCache = true
+	defer func() { installNoToolCache = old }()
+	if got := installToolCacheRoot(); got != "" {
+		t.Errorf("installToolCacheRoot() with --no-tool-cache = %q, want empty", got)
+	}
+
+	installNoToolCache = false
+	t.Setenv("GITHUB_ACTIONS", "")
+	if got := installToolCacheRoot(); got != "" {
+		t.Errorf("installToolCacheRoot() outside GitHub Actions = %q, want empty", got)
+	}
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/internal/shell"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// installWrappers installs the wrapper scripts of the spec into binDir, the
+// same scripts generated installers install, writing them to tmpDir first.
+// Wrappers are POSIX shell scripts, so they are skipped on Windows.
+func installWrappers(installSpec *spec.InstallSpec, tmpDir, binDir, osName string) error {
+	if len(installSpec.Wrappers) == 0 {
+		return nil
+	}
+	if osName == "windows" {
+		log.Infof("Skipping %d wrapper(s): wrappers are not supported on windows", len(installSpec.Wrappers))
+		return nil
+	}
+	for i := range installSpec.Wrappers {
+		w := &installSpec.Wrappers[i]
+		name := w.GetName()
+		if name == "" || name == "." || name == ".." || filepath.Base(name) != name {
+			return fmt.Errorf("invalid wrapper name %q", name)
+		}
+		srcPath := filepath.Join(tmpDir, "wrapper-"+name)
+		if err := os.WriteFile(srcPath, []byte(shell.Wrapper(installSpec, w)), 0644); err != nil {
+			return fmt.Errorf("failed to write wrapper %s: %w", name, err)
+		}
+		destPath := filepath.Join(binDir, name)
+		log.Infof("Installing wrapper to %s", destPath)
+		if err := installBinary(srcPath, destPath); err != nil {
+			return fmt.Errorf("failed to install wrapper %s: %w", name, err)
+		}
+	}
+	return nil
+}
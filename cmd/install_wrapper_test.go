@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+func TestInstallWrappers(t *testing.T) {
+	installSpec := &spec.InstallSpec{
+		Name:     spec.StringPtr("tool"),
+		Repo:     spec.StringPtr("owner/tool"),
+		Wrappers: []spec.WrapperElement{{Name: spec.StringPtr("tool-local"), Args: []string{"--quiet"}}},
+	}
+	binDir := t.TempDir()
+	if err := installWrappers(installSpec, t.TempDir(), binDir, "linux"); err != nil {
+		t.Fatalf("installWrappers() error = %v", err)
+	}
+	path := filepath.Join(binDir, "tool-local")
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm()&0111 == 0 {
+		t.Errorf("wrapper mode = %v, want executable", info.Mode())
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(got), `exec "${WRAPPER_BINDIR}"/'tool' '--quiet' "$@"`) {
+		t.Errorf("wrapper does not run tool:\n%s", got)
+	}
+
+	// Wrappers are shell scripts, so Windows gets none
+	windowsDir := t.TempDir()
+	if err := installWrappers(installSpec, t.TempDir(), windowsDir, "windows"); err != nil {
+		t.Fatalf("installWrappers(windows) error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(windowsDir, "tool-local")); !os.IsNotExist(err) {
+		t.Errorf("wrapper installed on windows: %v", err)
+	}
+
+	installSpec.Wrappers[0].Name = spec.StringPtr("../tool-local")
+	if err := installWrappers(installSpec, t.TempDir(), binDir, "linux"); err == nil {
+		t.Error("installWrappers() accepted a name outside the bin dir")
+	}
+}
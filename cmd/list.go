@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+)
+
+// listFormat is the output format of the list command
+var listFormat string
+
+// ListCommand represents the list command
+var ListCommand = &cobra.Command{
+	Use:   "list [DIR|FILE...]",
+	Short: "List InstallSpecs with their version and project metadata",
+	Long: `List the InstallSpecs of a project with their repository, default version, and
+metadata (license, homepage, security contact), e.g. for compliance reviews of the
+tools a project installs.
+
+Arguments may be InstallSpec files or directories of them. Without arguments the
+specs in .config/binstaller are listed, or the default config file when that
+directory does not exist.`,
+	Example: `  # List the project's tools
+  binst list
+
+  # List specs in a directory as JSON
+  binst list tools/ --format json`,
+	RunE: runList,
+}
+
+func init() {
+	ListCommand.Flags().StringVarP(&listFormat, "format", "f", "table", "Output format (table, json)")
+}
+
+// listEntry is one InstallSpec in the list output
+type listEntry struct {
+	File            string `json:"file"`
+	Name            string `json:"name"`
+	Repo            string `json:"repo"`
+	Version         string `json:"default_version"`
+	License         string `json:"license,omitempty"`
+	Homepage        string `json:"homepage,omitempty"`
+	SecurityContact string `json:"security_contact,omitempty"`
+}
+
+func runList(cmd *cobra.Command, args []string) error {
+	if listFormat != "table" && listFormat != "json" {
+		return fmt.Errorf("invalid format %q: must be 'table' or 'json'", listFormat)
+	}
+	files, err := listInputFiles(args)
+	if err != nil {
+		return err
+	}
+
+	entries := make([]listEntry, 0, len(files))
+	for _, file := range files {
+		installSpec, err := loadInstallSpec(file)
+		if err != nil {
+			log.WithError(err).Warnf("Skipping %s", file)
+			continue
+		}
+		installSpec.SetDefaults()
+		metadata := installSpec.GetMetadata()
+		entries = append(entries, listEntry{
+			File:            file,
+			Name:            installSpec.GetName(),
+			Repo:            installSpec.GetRepo(),
+			Version:         installSpec.GetDefaultVersion(),
+			License:         metadata.GetLicense(),
+			Homepage:        metadata.GetHomepage(),
+			SecurityContact: metadata.GetSecurityContact(),
+		})
+	}
+
+	if listFormat == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	}
+	printListEntries(os.Stdout, entries)
+	return nil
+}
+
+// listInputFiles expands the list arguments into InstallSpec files, with a
+// FILE#TOOL reference for each tool of a multi-tool spec
+func listInputFiles(args []string) ([]string, error) {
+	if len(args) == 0 {
+		if info, err := os.Stat(ProjectToolsDir); err == nil && info.IsDir() {
+			args = []string{ProjectToolsDir}
+		} else {
+			cfgFile, err := resolveConfigFile(configFile)
+			if err != nil {
+				return nil, err
+			}
+			return expandToolRefs([]string{cfgFile}), nil
+		}
+	}
+
+	var files []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+		specFiles, err := listSpecFiles(arg)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, specFiles...)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no InstallSpec files found in %v", args)
+	}
+	return expandToolRefs(files), nil
+}
+
+// printListEntries prints the specs as a table, with "-" for missing metadata
+func printListEntries(w io.Writer, entries []listEntry) {
+	orDash := func(s string) string {
+		if s == "" {
+			return "-"
+		}
+		return s
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tREPO\tVERSION\tLICENSE\tHOMEPAGE\tSECURITY CONTACT")
+	for _, e := range entries {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", e.Name, e.Repo, e.Version, orDash(e.License), orDash(e.Homepage), orDash(e.SecurityContact))
+	}
+	tw.Flush()
+}
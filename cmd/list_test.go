@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestListInputFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "b.yml"), "repo: owner/b\n", 0644)
+	writeTestFile(t, filepath.Join(dir, "a.yaml"), "repo: owner/a\n", 0644)
+	writeTestFile(t, filepath.Join(dir, "README.md"), "not a spec\n", 0644)
+	single := filepath.Join(t.TempDir(), "tool.binstaller.yml")
+	writeTestFile(t, single, "repo: owner/tool\n", 0644)
+
+	got, err := listInputFiles([]string{dir, single})
+	if err != nil {
+		t.Fatalf("listInputFiles() error = %v", err)
+	}
+	want := []string{filepath.Join(dir, "a.yaml"), filepath.Join(dir, "b.yml"), single}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("listInputFiles() = %v, want %v", got, want)
+	}
+
+	if _, err := listInputFiles([]string{t.TempDir()}); err == nil {
+		t.Error("listInputFiles() should fail for a directory without specs")
+	}
+}
+
+func TestPrintListEntries(t *testing.T) {
+	var buf bytes.Buffer
+	printListEntries(&buf, []listEntry{
+		{Name: "tool", Repo: "owner/tool", Version: "v1.2.3", License: "MIT", SecurityContact: "security@example.com"},
+	})
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected header and one row, got %q", buf.String())
+	}
+	if fields := strings.Fields(lines[1]); strings.Join(fields, " ") != "tool owner/tool v1.2.3 MIT - security@example.com" {
+		t.Errorf("row = %q", lines[1])
+	}
+}
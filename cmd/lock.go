@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/checksums"
+	"github.com/binary-install/binstaller/pkg/lockfile"
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Flags for lock command
+	lockFile      string
+	lockPlatforms []string
+
+	// installLockFile is the lockfile 'binst install' honors
+	installLockFile string
+	// installLock is the lock loaded from installLockFile, nil without one
+	installLock *lockfile.Lock
+)
+
+// LockCommand represents the lock command
+var LockCommand = &cobra.Command{
+	Use:   "lock [FILE|DIR]...",
+	Short: "Pin the release tag and asset digests of every tool in a lockfile",
+	Long: `Resolves the default_version (e.g. latest) of every InstallSpec to an exact
+release tag and records it in a lockfile together with the sha256 digest of the
+asset of each platform. 'binst install' then installs the locked tag and refuses
+an asset whose digest differs, so every machine installs the same bytes until
+the lock is refreshed by running 'binst lock' again.
+
+Without arguments, the specs in .config/binstaller are locked, or the config
+file (see --config). Multi-tool configs lock each of their tools. Locking
+specific files or directories updates their tools and keeps the other tools of
+an existing lockfile.
+
+The assets of supported_platforms are locked, or those of the current platform
+for specs without supported_platforms; --platform selects other platforms.
+Digests come from the embedded checksums or checksum files of sha256 specs;
+other assets are downloaded to compute them.`,
+	Example: `  # Lock every tool of the project into binstaller.lock
+  binst lock
+
+  # Refresh the lock of one tool only
+  binst lock .config/binstaller/gh.yml
+
+  # Lock the assets of specific platforms
+  binst lock --platform linux/amd64 --platform darwin/arm64
+
+  # Install the locked versions
+  binst install --all`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		files, err := listInputFiles(args)
+		if err != nil {
+			return err
+		}
+		lock := &lockfile.Lock{}
+		if len(args) > 0 {
+			existing, err := lockfile.ReadOptional(lockFile)
+			if err != nil {
+				return err
+			}
+			if existing != nil {
+				lock = existing
+			}
+		}
+		for _, file := range files {
+			tool, err := lockTool(cmd.Context(), file, lockPlatforms)
+			if err != nil {
+				return fmt.Errorf("failed to lock %s: %w", file, err)
+			}
+			lock.Set(*tool)
+			log.Infof("Locked %s at %s (%d assets)", tool.Name, tool.Tag, len(tool.Assets))
+		}
+		if err := lock.Write(lockFile); err != nil {
+			return fmt.Errorf("failed to write lockfile: %w", err)
+		}
+		log.Infof("Lockfile written to %s", lockFile)
+		return nil
+	},
+}
+
+// lockTool resolves the default_version of the spec in file and the digests
+// of its assets for platforms (supported_platforms when empty)
+func lockTool(ctx context.Context, file string, platforms []string) (*lockfile.Tool, error) {
+	installSpec, err := loadInstallSpec(file)
+	if err != nil {
+		return nil, err
+	}
+	installSpec.SetDefaults()
+	ctx = specContext(ctx, installSpec)
+	if installSpec.GetRepo() == "" {
+		return nil, fmt.Errorf("repo is not set")
+	}
+	requested := installSpec.GetDefaultVersion()
+	tag, err := resolveVersion(ctx, installSpec, requested)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", requested, err)
+	}
+	tool := &lockfile.Tool{
+		Name:      installSpec.GetName(),
+		Repo:      installSpec.GetRepo(),
+		Requested: requested,
+		Tag:       tag,
+	}
+
+	if len(platforms) == 0 {
+		for _, p := range installSpec.SupportedPlatforms {
+			platforms = append(platforms, spec.PlatformOSString(p.OS)+"/"+spec.PlatformArchString(p.Arch))
+		}
+	}
+	if len(platforms) == 0 {
+		platforms = []string{detectOS() + "/" + detectArch()}
+	}
+	generator, err := newReleaseFilenameGenerator(ctx, installSpec, tag)
+	if err != nil {
+		return nil, err
+	}
+	lookup := releaseChecksumFunc(ctx, installSpec, tag)
+	for _, platform := range platforms {
+		osName, arch, ok := strings.Cut(platform, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid platform %q: must be os/arch", platform)
+		}
+		filename, err := generator.GenerateFilename(osName, arch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate asset filename for %s: %w", platform, err)
+		}
+		digest, err := assetSHA256(ctx, installSpec, tag, osName, arch, filename, lookup)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the sha256 of %s: %w", filename, err)
+		}
+		tool.Assets = append(tool.Assets, lockfile.Asset{Platform: platform, Filename: filename, SHA256: digest})
+	}
+	return tool, nil
+}
+
+// assetSHA256 returns the sha256 of a release asset, from the spec's checksums
+// when they are sha256 and by downloading the asset otherwise
+func assetSHA256(ctx context.Context, installSpec *spec.InstallSpec, tag, osName, arch, filename string, lookup func(osName, arch, filename string) (string, error)) (string, error) {
+	if installSpec.GetChecksums() != nil && installSpec.GetChecksums().GetAlgorithm() == spec.Sha256 {
+		digest, err := lookup(osName, arch, filename)
+		if err == nil {
+			return strings.ToLower(digest), nil
+		}
+		log.Debugf("No sha256 checksum of %s, downloading it: %v", filename, err)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "binst-lock-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpDir)
+	path := filepath.Join(tmpDir, filename)
+	if err := download(ctx, installSpec, path, releaseDownloadURL(installSpec, tag, filename)); err != nil {
+		return "", err
+	}
+	return checksums.ComputeHash(path, string(spec.Sha256))
+}
+
+// loadInstallLock loads the lockfile 'binst install' honors, if there is one
+func loadInstallLock() error {
+	lock, err := lockfile.ReadOptional(installLockFile)
+	if err != nil {
+		return err
+	}
+	if lock != nil {
+		log.Debugf("Using lockfile %s", installLockFile)
+	}
+	installLock = lock
+	return nil
+}
+
+// lockedTag returns the locked tag of the tool when version, or the spec's
+// default_version when empty, is the version the lock was resolved from
+func lockedTag(installSpec *spec.InstallSpec, version string) (string, bool) {
+	tool := installLock.Tool(installSpec.GetName(), installSpec.GetRepo())
+	if tool == nil {
+		return "", false
+	}
+	requested := cmp.Or(version, installSpec.GetDefaultVersion())
+	if requested != tool.Requested {
+		if version == "" {
+			log.Warnf("%s is locked at %s for %s but default_version is now %s; run 'binst lock' to update %s", tool.Name, tool.Tag, tool.Requested, requested, installLockFile)
+		}
+		return "", false
+	}
+	log.Infof("Using %s %s from %s", tool.Name, tool.Tag, installLockFile)
+	return tool.Tag, true
+}
+
+// verifyLocked checks the sha256 of the asset at path against the lock when
+// the lock pins the tool at tag
+func verifyLocked(installSpec *spec.InstallSpec, tag, filename, path string) error {
+	tool := installLock.Tool(installSpec.GetName(), installSpec.GetRepo())
+	if tool == nil || tool.Tag != tag {
+		return nil
+	}
+	locked := tool.Asset(filename)
+	if locked == nil {
+		log.Warnf("%s is not in %s; run 'binst lock --platform' to lock it", filename, installLockFile)
+		return nil
+	}
+	digest, err := checksums.ComputeHash(path, string(spec.Sha256))
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(digest, locked.SHA256) {
+		return fmt.Errorf("sha256 of %s does not match %s: got %s, locked %s", filename, installLockFile, digest, locked.SHA256)
+	}
+	log.Infof("Verified %s against %s", filename, installLockFile)
+	return nil
+}
+
+func init() {
+	LockCommand.Flags().StringVar(&lockFile, "lockfile", lockfile.DefaultPath, "Lockfile to write")
+	LockCommand.Flags().StringArrayVar(&lockPlatforms, "platform", nil, "Platform (os/arch) to lock the asset of (default: supported_platforms, or the current platform)")
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/lockfile"
+)
+
+func TestLockAndInstall(t *testing.T) {
+	t.Setenv("BINSTALLER_OS_VERSION", "")
+	content := "#!/bin/sh\necho tool\n"
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(content)))
+	latest := "v1.0.0"
+
+	var downloads []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/releases/latest") {
+			fmt.Fprintf(w, `{"tag_name": %q}`, latest)
+			return
+		}
+		downloads = append(downloads, r.URL.Path)
+		w.Write([]byte(content))
+	}))
+	defer server.Close()
+	oldAPI, oldDownload := gitHubAPIBaseURL, gitHubDownloadBaseURL
+	gitHubAPIBaseURL, gitHubDownloadBaseURL = server.URL, server.URL
+	defer func() { gitHubAPIBaseURL, gitHubDownloadBaseURL = oldAPI, oldDownload }()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "tool.yml")
+	writeTestFile(t, file, `repo: owner/tool
+asset:
+  template: ${NAME}_${VERSION}_${OS}_${ARCH}
+supported_platforms:
+  - os: linux
+    arch: amd64
+  - os: darwin
+    arch: arm64
+`, 0644)
+
+	// Without sha256 checksums the assets are downloaded to compute digests
+	tool, err := lockTool(context.Background(), file, nil)
+	if err != nil {
+		t.Fatalf("lockTool() error = %v", err)
+	}
+	if tool.Name != "tool" || tool.Requested != "latest" || tool.Tag != "v1.0.0" || len(tool.Assets) != 2 {
+		t.Fatalf("lockTool() = %+v, want tool latest locked at v1.0.0 for 2 platforms", tool)
+	}
+	if tool.Assets[1].Platform != "darwin/arm64" || tool.Assets[1].Filename != "tool_1.0.0_darwin_arm64" || tool.Assets[1].SHA256 != hash {
+		t.Errorf("lockTool() darwin asset = %+v", tool.Assets[1])
+	}
+	if len(downloads) != 2 {
+		t.Errorf("lockTool() downloaded %v, want both assets", downloads)
+	}
+
+	lockPath := filepath.Join(dir, lockfile.DefaultPath)
+	lock := &lockfile.Lock{}
+	lock.Set(*tool)
+	if err := lock.Write(lockPath); err != nil {
+		t.Fatal(err)
+	}
+
+	oldLockFile, oldLock := installLockFile, installLock
+	installLockFile = lockPath
+	defer func() { installLockFile, installLock = oldLockFile, oldLock }()
+	if err := loadInstallLock(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A newer latest release is ignored while the lock pins v1.0.0
+	latest = "v2.0.0"
+	installSpec, err := loadInstallSpec(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	installSpec.SetDefaults()
+	filename := fmt.Sprintf("tool_1.0.0_%s_%s", runtime.GOOS, runtime.GOARCH)
+	installLock.Tools[0].Assets = append(installLock.Tools[0].Assets, lockfile.Asset{Platform: runtime.GOOS + "/" + runtime.GOARCH, Filename: filename, SHA256: hash})
+	tag, err := installRelease(context.Background(), installSpec, "", t.TempDir(), false, assetSource{})
+	if err != nil || tag != "v1.0.0" {
+		t.Fatalf("installRelease() = %s, %v, want the locked v1.0.0", tag, err)
+	}
+
+	// An explicit version is not locked
+	if tag, err := resolveVersion(context.Background(), installSpec, "v1.5.0"); err != nil || tag != "v1.5.0" {
+		t.Errorf("resolveVersion(v1.5.0) = %s, %v", tag, err)
+	}
+
+	// An asset that differs from the lock is refused
+	for i := range installLock.Tools[0].Assets {
+		installLock.Tools[0].Assets[i].SHA256 = strings.Repeat("0", 64)
+	}
+	_, err = installRelease(context.Background(), installSpec, "", t.TempDir(), false, assetSource{})
+	if err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("installRelease() with a tampered lock error = %v, want a digest mismatch", err)
+	}
+}
@@ -3,9 +3,12 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/apex/log"
 	"github.com/apex/log/handlers/cli"
+	"github.com/binary-install/binstaller/pkg/httpclient"
+	"github.com/binary-install/binstaller/pkg/metrics"
 	"github.com/spf13/cobra"
 )
 
@@ -36,9 +39,15 @@ func resolveConfigFile(configFile string) (string, error) {
 
 var (
 	// Global flags
-	configFile string
-	verbose    bool
-	quiet      bool
+	configFile  string
+	verbose     bool
+	quiet       bool
+	metricsFile string
+	noCache     bool
+
+	// The running command, for the metrics
+	commandName  string
+	commandStart time.Time
 )
 
 // RootCmd represents the base command when called without any subcommands
@@ -61,13 +70,33 @@ released on GitHub, binstaller can generate installation scripts for them.`,
 			log.SetLevel(log.InfoLevel)
 		}
 		log.Debugf("Config file: %s", configFile)
+		if noCache {
+			httpclient.DisableCache()
+		}
+		commandName, commandStart = cmd.Name(), time.Now()
 	},
 }
 
+// WriteMetrics writes the metrics of the command that ran to --metrics-file when
+// it is set, recording err as the result of the command. It is called once the
+// command returns.
+func WriteMetrics(err error) {
+	if metricsFile == "" {
+		return
+	}
+	if commandName != "" {
+		metrics.RecordCommand(commandName, time.Since(commandStart), err)
+	}
+	if err := metrics.WriteFile(metricsFile); err != nil {
+		log.WithError(err).Error("failed to write metrics")
+	}
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the RootCmd.
 func Execute() {
 	err := RootCmd.Execute()
+	WriteMetrics(err)
 	if err != nil {
 		log.WithError(err).Fatal("command execution failed")
 		// os.Exit(1) // log.Fatal exits automatically
@@ -82,6 +111,8 @@ func init() {
 	RootCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "", "Path to InstallSpec config file (default: "+DefaultConfigPathYML+")")
 	RootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "Increase log verbosity")
 	RootCmd.PersistentFlags().BoolVar(&quiet, "quiet", false, "Suppress progress output")
+	RootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Do not use or store cached GitHub and GitLab API responses (also "+httpclient.EnvNoCache+"=1)")
+	RootCmd.PersistentFlags().StringVar(&metricsFile, "metrics-file", "", "Write download, verification, failure and GitHub rate limit metrics in Prometheus text format to this file when the command exits")
 
 	// Mark 'config' flag for auto-detection? Cobra doesn't directly support this.
 	// We'll handle default detection logic within commands if the flag is empty.
@@ -106,14 +137,37 @@ func init() {
 	EmbedChecksumsCommand.GroupID = "workflow"
 	GenCommand.GroupID = "workflow"
 	InstallCommand.GroupID = "workflow"
+	ExecCommand.GroupID = "workflow"
+	SandboxCommand.GroupID = "workflow"
+	E2ECommand.GroupID = "workflow"
+	ConformanceCommand.GroupID = "utility"
+	GraphCommand.GroupID = "utility"
+	ExplainURLCommand.GroupID = "utility"
+	ListCommand.GroupID = "utility"
+	BrewTapCommand.GroupID = "utility"
 	HelpfulCommand.GroupID = "utility"
 	SchemaCommand.GroupID = "utility"
+	ConvertCommand.GroupID = "utility"
+	AuthCommand.GroupID = "utility"
 
 	RootCmd.AddCommand(InitCommand)           // Step 1: Initialize config
 	RootCmd.AddCommand(CheckCommand)          // Step 2: Validate config
 	RootCmd.AddCommand(EmbedChecksumsCommand) // Step 3: Embed checksums (optional)
 	RootCmd.AddCommand(GenCommand)            // Step 4: Generate installer
+	RootCmd.AddCommand(SandboxCommand)        // Optional: Test installer in containers
+	RootCmd.AddCommand(E2ECommand)            // Optional: Test installer across platforms and versions
 	RootCmd.AddCommand(InstallCommand)        // Alternative: Install binary directly
+	RootCmd.AddCommand(LockCommand)           // Alternative: Pin tool versions for install
+	RootCmd.AddCommand(ExecCommand)           // Alternative: Run a pinned tool from the cache
+	RootCmd.AddCommand(GraphCommand)          // Utility: Visualize rule resolution
+	RootCmd.AddCommand(ExplainURLCommand)     // Utility: Trace an asset back to its platform
+	RootCmd.AddCommand(ConformanceCommand)    // Utility: Check rules against real-world releases
+	RootCmd.AddCommand(ListCommand)           // Utility: List specs and their metadata
+	RootCmd.AddCommand(ExportCommand)         // Utility: Render package manifests
+	RootCmd.AddCommand(BrewTapCommand)        // Utility: Maintain a Homebrew tap
 	RootCmd.AddCommand(HelpfulCommand)        // Utility: Comprehensive help for LLMs
 	RootCmd.AddCommand(SchemaCommand)         // Utility: Display configuration schema
+	RootCmd.AddCommand(ConvertCommand)        // Utility: Convert specs between YAML and JSON
+	RootCmd.AddCommand(AuthCommand)           // Utility: Log in to GitHub
+	RootCmd.AddCommand(GCCommand)             // Utility: Reclaim cache and receipt space
 }
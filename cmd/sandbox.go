@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/internal/shell"
+	"github.com/spf13/cobra"
+)
+
+// sandboxScriptDir is where the generated installer is mounted inside the container
+const sandboxScriptDir = "/binstaller"
+
+// sandboxBinDir is where the installer installs binaries inside the container
+const sandboxBinDir = "/tmp/binstaller-bin"
+
+var (
+	// Flags for sandbox command
+	sandboxImages        []string
+	sandboxEngine        string
+	sandboxTargetVersion string
+	sandboxSetup         string
+	sandboxLogDir        string
+	sandboxVerbose       bool
+)
+
+// SandboxCommand represents the sandbox command
+var SandboxCommand = &cobra.Command{
+	Use:   "sandbox [-- INSTALLER_ARGS...]",
+	Short: "Run the generated installer inside disposable containers",
+	Long: `Generate the installer for the current InstallSpec and run it inside a disposable
+container for each --image, to validate the installer across distributions before a
+release.
+
+The installer is mounted read-only at /binstaller/install.sh and run with
+"-b /tmp/binstaller-bin" plus any arguments after --. Use --setup to prepare images
+that lack curl/wget or CA certificates. GITHUB_TOKEN is passed through when set.
+
+Each image's output is captured; with --log-dir it is written to IMAGE.log. A summary
+with the exit status of every image is printed, and the command fails if any image
+fails.`,
+	Example: `  # Run the installer on Alpine
+  binst sandbox --image alpine:3.20
+
+  # Test a matrix of distributions and keep the logs
+  binst sandbox --image alpine:3.20 --image debian:12 --image ubuntu:24.04 \
+    --setup 'command -v apt-get >/dev/null && apt-get update -qq && apt-get install -qq -y curl ca-certificates || true' \
+    --log-dir sandbox-logs
+
+  # Install a specific version in dry-run mode
+  binst sandbox --image alpine:3.20 -- -n v1.2.3`,
+	RunE: runSandbox,
+}
+
+func init() {
+	SandboxCommand.Flags().StringSliceVar(&sandboxImages, "image", []string{"alpine:3.20"}, "Container image to run the installer in (repeatable)")
+	SandboxCommand.Flags().StringVar(&sandboxEngine, "engine", "docker", "Container engine command (docker or podman)")
+	SandboxCommand.Flags().StringVar(&sandboxTargetVersion, "target-version", "", "Generate the installer for a specific version only")
+	SandboxCommand.Flags().StringVar(&sandboxSetup, "setup", "", "Shell commands to run in the container before the installer")
+	SandboxCommand.Flags().StringVar(&sandboxLogDir, "log-dir", "", "Directory to write one log file per image")
+	SandboxCommand.Flags().BoolVarP(&sandboxVerbose, "verbose", "v", false, "Stream container output while it runs")
+}
+
+// sandboxResult is the outcome of running the installer in one image
+type sandboxResult struct {
+	Image    string
+	ExitCode int
+	Duration time.Duration
+	Output   []byte
+}
+
+func runSandbox(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if len(sandboxImages) == 0 {
+		return fmt.Errorf("at least one --image is required")
+	}
+	if _, err := exec.LookPath(sandboxEngine); err != nil {
+		return fmt.Errorf("container engine %q not found: %w", sandboxEngine, err)
+	}
+
+	cfgFile, err := resolveConfigFile(configFile)
+	if err != nil {
+		return err
+	}
+	installSpec, err := loadInstallSpec(cfgFile)
+	if err != nil {
+		return err
+	}
+	script, err := shell.GenerateWithScriptType(installSpec, sandboxTargetVersion, "installer")
+	if err != nil {
+		return fmt.Errorf("failed to generate installer: %w", err)
+	}
+
+	scriptDir, err := os.MkdirTemp("", "binst-sandbox-")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(scriptDir)
+	// The container user may differ from ours
+	if err := os.Chmod(scriptDir, 0755); err != nil {
+		return fmt.Errorf("failed to set permissions on %s: %w", scriptDir, err)
+	}
+	if err := os.WriteFile(filepath.Join(scriptDir, "install.sh"), script, 0755); err != nil {
+		return fmt.Errorf("failed to write installer: %w", err)
+	}
+	if sandboxLogDir != "" {
+		if err := os.MkdirAll(sandboxLogDir, 0755); err != nil {
+			return fmt.Errorf("failed to create log directory: %w", err)
+		}
+	}
+
+	var results []sandboxResult
+	for _, image := range sandboxImages {
+		log.Infof("Running installer in %s", image)
+		result, err := runSandboxImage(ctx, sandboxEngine, image, scriptDir, sandboxSetup, args)
+		if err != nil {
+			return err
+		}
+		results = append(results, result)
+
+		if sandboxLogDir != "" {
+			logFile := filepath.Join(sandboxLogDir, sandboxLogName(image))
+			if err := os.WriteFile(logFile, result.Output, 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", logFile, err)
+			}
+			log.Debugf("Wrote %s", logFile)
+		}
+		if result.ExitCode != 0 && !sandboxVerbose {
+			log.Errorf("Installer failed in %s (exit status %d):", image, result.ExitCode)
+			os.Stderr.Write(result.Output)
+		}
+	}
+
+	printSandboxSummary(os.Stdout, results)
+	var failed []string
+	for _, r := range results {
+		if r.ExitCode != 0 {
+			failed = append(failed, r.Image)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("installer failed in %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// runSandboxImage runs the installer in scriptDir inside a disposable container of image
+func runSandboxImage(ctx context.Context, engine, image, scriptDir, setup string, installerArgs []string) (sandboxResult, error) {
+	var output bytes.Buffer
+	var w io.Writer = &output
+	if sandboxVerbose {
+		w = io.MultiWriter(&output, os.Stderr)
+	}
+
+	start := time.Now()
+	c := exec.CommandContext(ctx, engine, sandboxRunArgs(image, scriptDir, setup, installerArgs)...)
+	c.Stdout, c.Stderr = w, w
+	err := c.Run()
+	result := sandboxResult{Image: image, Duration: time.Since(start), Output: output.Bytes()}
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return result, fmt.Errorf("failed to run %s: %w", engine, err)
+		}
+		result.ExitCode = exitErr.ExitCode()
+	}
+	return result, nil
+}
+
+// sandboxRunArgs returns the container engine arguments that run the installer in image
+func sandboxRunArgs(image, scriptDir, setup string, installerArgs []string) []string {
+	var script strings.Builder
+	script.WriteString("set -e\n")
+	if setup != "" {
+		script.WriteString(setup + "\n")
+	}
+	fmt.Fprintf(&script, "sh %s/install.sh -b %s", sandboxScriptDir, sandboxBinDir)
+	for _, arg := range installerArgs {
+		script.WriteString(" " + shellQuote(arg))
+	}
+	fmt.Fprintf(&script, "\nls -l %s 2>/dev/null || true\n", sandboxBinDir)
+
+	args := []string{"run", "--rm", "-v", scriptDir + ":" + sandboxScriptDir + ":ro"}
+	if os.Getenv("GITHUB_TOKEN") != "" {
+		// Passing only the name keeps the token out of the process list
+		args = append(args, "-e", "GITHUB_TOKEN")
+	}
+	return append(args, image, "sh", "-c", script.String())
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+var unsafeLogNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// sandboxLogName returns the log file name of an image, e.g. alpine_3.20.log
+func sandboxLogName(image string) string {
+	return unsafeLogNameChars.ReplaceAllString(image, "_") + ".log"
+}
+
+// printSandboxSummary prints the exit status of every image
+func printSandboxSummary(w io.Writer, results []sandboxResult) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "IMAGE\tSTATUS\tEXIT\tDURATION")
+	for _, r := range results {
+		status := "✓ PASS"
+		if r.ExitCode != 0 {
+			status = "✗ FAIL"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", r.Image, status, r.ExitCode, r.Duration.Round(100*time.Millisecond))
+	}
+	tw.Flush()
+}
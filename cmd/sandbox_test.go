@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSandboxRunArgs(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "secret")
+	args := sandboxRunArgs("alpine:3.20", "/tmp/scripts", "apk add curl", []string{"-n", "v1.0.0", "it's"})
+
+	want := []string{"run", "--rm", "-v", "/tmp/scripts:/binstaller:ro", "-e", "GITHUB_TOKEN", "alpine:3.20", "sh", "-c"}
+	if len(args) != len(want)+1 {
+		t.Fatalf("sandboxRunArgs() = %q", args)
+	}
+	for i, w := range want {
+		if args[i] != w {
+			t.Errorf("args[%d] = %q, want %q", i, args[i], w)
+		}
+	}
+	script := args[len(args)-1]
+	for _, w := range []string{"set -e\napk add curl\n", `sh /binstaller/install.sh -b /tmp/binstaller-bin '-n' 'v1.0.0' 'it'\''s'`} {
+		if !strings.Contains(script, w) {
+			t.Errorf("script missing %q:\n%s", w, script)
+		}
+	}
+	if strings.Contains(strings.Join(args, " "), "secret") {
+		t.Error("GITHUB_TOKEN value leaked into the engine arguments")
+	}
+}
+
+func TestRunSandboxImage(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	engine := filepath.Join(t.TempDir(), "fake-engine")
+	writeTestFile(t, engine, "#!/bin/sh\necho \"engine $1 $5\"\ncase \"$5\" in *broken*) exit 3 ;; esac\n", 0755)
+
+	tests := []struct {
+		image        string
+		wantExitCode int
+	}{
+		{image: "alpine:3.20", wantExitCode: 0},
+		{image: "broken:latest", wantExitCode: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			result, err := runSandboxImage(context.Background(), engine, tt.image, "/tmp/scripts", "", nil)
+			if err != nil {
+				t.Fatalf("runSandboxImage() error = %v", err)
+			}
+			if result.ExitCode != tt.wantExitCode {
+				t.Errorf("ExitCode = %d, want %d", result.ExitCode, tt.wantExitCode)
+			}
+			if got, want := string(result.Output), "engine run "+tt.image+"\n"; got != want {
+				t.Errorf("Output = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestSandboxSummary(t *testing.T) {
+	if got, want := sandboxLogName("ghcr.io/org/img:1.0"), "ghcr.io_org_img_1.0.log"; got != want {
+		t.Errorf("sandboxLogName() = %q, want %q", got, want)
+	}
+
+	var buf bytes.Buffer
+	printSandboxSummary(&buf, []sandboxResult{
+		{Image: "alpine:3.20", Duration: time.Second},
+		{Image: "debian:12", ExitCode: 1, Duration: 2 * time.Second},
+	})
+	for _, w := range []string{"alpine:3.20  ✓ PASS  0", "debian:12    ✗ FAIL  1"} {
+		if !strings.Contains(buf.String(), w) {
+			t.Errorf("summary missing %q:\n%s", w, buf.String())
+		}
+	}
+}
@@ -5,10 +5,14 @@ import (
 	"io"
 	"os"
 
+	"github.com/binary-install/binstaller/pkg/schemadoc"
 	"github.com/binary-install/binstaller/schema"
 	"github.com/spf13/cobra"
 )
 
+// schemaComment references the InstallSpec schema for IDE support in YAML configs
+const schemaComment = "# yaml-language-server: $schema=https://raw.githubusercontent.com/binary-install/binstaller/main/schema/InstallSpec.json\n"
+
 // SchemaCommand represents the schema command
 var SchemaCommand = &cobra.Command{
 	Use:   "schema",
@@ -16,7 +20,16 @@ var SchemaCommand = &cobra.Command{
 	Long: `Display binstaller configuration schema directly from the CLI.
 
 This command shows the binstaller configuration schema in various formats.
-For filtering and processing, use yq or jq tools on the output.`,
+For filtering and processing, use yq or jq tools on the output.
+
+The markdown and html formats render reference documentation with a field
+table per type and the examples from the schema descriptions, for embedding
+in project documentation sites.
+
+--example prints an example config synthesized from the schema defaults,
+enums and description examples: "minimal" with only the required fields,
+"full" with every field, or the name of a type (e.g. AssetRule) for a
+snippet of that type. Every field is annotated with its description.`,
 	Example: `  # Display schema in YAML format (default)
   binst schema
 
@@ -26,6 +39,15 @@ For filtering and processing, use yq or jq tools on the output.`,
   # Display original TypeSpec source
   binst schema --format typespec
 
+  # Generate reference documentation
+  binst schema --format markdown > docs/config-reference.md
+  binst schema --format html > site/config-reference.html
+
+  # Start a config from an example
+  binst schema --example minimal > .config/binstaller.yml
+  binst schema --example full
+  binst schema --example AssetRule
+
   # List all available schema types
   binst schema | yq '."$defs" | keys'
 
@@ -42,6 +64,9 @@ For filtering and processing, use yq or jq tools on the output.`,
   binst schema --format json | jq '.["$defs"].Platform.properties.os.anyOf[].const'
   binst schema --format json | jq '.["$defs"].Platform.properties.arch.anyOf[].const'`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if example, _ := cmd.Flags().GetString("example"); example != "" {
+			return RunSchemaExample(example, os.Stdout)
+		}
 		format, _ := cmd.Flags().GetString("format")
 		return RunSchema(format, os.Stdout)
 	},
@@ -63,8 +88,18 @@ func RunSchema(format string, output interface{}) error {
 		outputBytes = schema.GetInstallSpecSchemaJSON()
 	case "typespec":
 		outputBytes = schema.GetTypeSpecSource()
+	case "markdown", "html":
+		doc, err := schemadoc.Parse(schema.GetInstallSpecSchemaJSON())
+		if err != nil {
+			return err
+		}
+		if format == "markdown" {
+			outputBytes = doc.Markdown()
+		} else {
+			outputBytes = doc.HTML()
+		}
 	default:
-		return fmt.Errorf("unsupported format: %s (supported: yaml, json, typespec)", format)
+		return fmt.Errorf("unsupported format: %s (supported: yaml, json, typespec, markdown, html)", format)
 	}
 
 	// Write output
@@ -72,6 +107,20 @@ func RunSchema(format string, output interface{}) error {
 	return err
 }
 
+// RunSchemaExample writes an example config synthesized from the schema
+func RunSchemaExample(kind string, w io.Writer) error {
+	example, err := schemadoc.ExampleConfig(schema.GetInstallSpecSchemaJSON(), kind)
+	if err != nil {
+		return err
+	}
+	if kind == schemadoc.ExampleMinimal || kind == schemadoc.ExampleFull {
+		example = append([]byte(schemaComment), example...)
+	}
+	_, err = w.Write(example)
+	return err
+}
+
 func init() {
-	SchemaCommand.Flags().StringP("format", "f", "yaml", "Output format (yaml, json, typespec)")
+	SchemaCommand.Flags().StringP("format", "f", "yaml", "Output format (yaml, json, typespec, markdown, html)")
+	SchemaCommand.Flags().String("example", "", "Print an example config instead: minimal, full, or a type name")
 }
@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/goccy/go-yaml"
 )
 
 // TestRunSchema_BasicYAMLOutput tests basic YAML output
@@ -102,6 +105,22 @@ func TestRunSchema_TypeSpecFormatOutput(t *testing.T) {
 	}
 }
 
+// TestRunSchema_MarkdownFormatOutput tests markdown reference documentation output
+func TestRunSchema_MarkdownFormatOutput(t *testing.T) {
+	var output bytes.Buffer
+
+	if err := RunSchema("markdown", &output); err != nil {
+		t.Fatalf("RunSchema() returned error: %v", err)
+	}
+
+	result := output.String()
+	for _, want := range []string{"# InstallSpec", "### AssetConfig", "| `repo` |", "```yaml"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("Expected markdown output to contain %q", want)
+		}
+	}
+}
+
 // TestRunSchema_CanBeeParsedCorrectly tests that embedded schema can be parsed
 func TestRunSchema_CanBeParsedCorrectly(t *testing.T) {
 	var output bytes.Buffer
@@ -168,6 +187,18 @@ func TestRunSchema_ErrorCases(t *testing.T) {
 			expectError: false,
 			errorMsg:    "",
 		},
+		{
+			name:        "valid markdown format",
+			format:      "markdown",
+			expectError: false,
+			errorMsg:    "",
+		},
+		{
+			name:        "valid html format",
+			format:      "html",
+			expectError: false,
+			errorMsg:    "",
+		},
 	}
 
 	for _, tt := range tests {
@@ -192,3 +223,37 @@ func TestRunSchema_ErrorCases(t *testing.T) {
 		})
 	}
 }
+
+// TestRunSchemaExample tests that the synthesized examples are valid configs
+func TestRunSchemaExample(t *testing.T) {
+	for _, kind := range []string{"minimal", "full"} {
+		t.Run(kind, func(t *testing.T) {
+			var output bytes.Buffer
+			if err := RunSchemaExample(kind, &output); err != nil {
+				t.Fatalf("RunSchemaExample() returned error: %v", err)
+			}
+			if !strings.HasPrefix(output.String(), schemaComment) {
+				t.Error("Expected example to start with the schema comment")
+			}
+
+			var installSpec spec.InstallSpec
+			if err := yaml.Unmarshal(output.Bytes(), &installSpec); err != nil {
+				t.Fatalf("Example is not valid YAML: %v\n%s", err, output.String())
+			}
+			if err := validateSpec(&installSpec); err != nil {
+				t.Errorf("Example failed validation: %v", err)
+			}
+			if err := spec.Validate(&installSpec); err != nil {
+				t.Errorf("Example failed security validation: %v", err)
+			}
+		})
+	}
+
+	var output bytes.Buffer
+	if err := RunSchemaExample("AssetRule", &output); err != nil {
+		t.Fatalf("RunSchemaExample(AssetRule) returned error: %v", err)
+	}
+	if strings.HasPrefix(output.String(), schemaComment) || !strings.Contains(output.String(), "when:") {
+		t.Errorf("Expected a bare AssetRule snippet, got:\n%s", output.String())
+	}
+}
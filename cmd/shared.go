@@ -1,17 +1,59 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
 
 	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/checksums"
+	"github.com/binary-install/binstaller/pkg/httpclient"
+	"github.com/binary-install/binstaller/pkg/overlay"
+	"github.com/binary-install/binstaller/pkg/sops"
 	"github.com/binary-install/binstaller/pkg/spec"
 	"github.com/goccy/go-yaml"
 )
 
-// loadInstallSpec loads and parses the InstallSpec from the config file
+// loadInstallSpec loads and parses the InstallSpec from the config file. cfgFile
+// may be FILE#TOOL to select a tool of a multi-tool spec.
 func loadInstallSpec(cfgFile string) (*spec.InstallSpec, error) {
+	yamlData, err := readToolSpecData(cfgFile)
+	if err != nil {
+		return nil, err
+	}
+	return parseInstallSpec(yamlData, cfgFile)
+}
+
+// loadInstallSpecWithOverlays loads the InstallSpec from the config file and,
+// when enabled, layers org defaults and user overrides onto it
+func loadInstallSpecWithOverlays(ctx context.Context, cfgFile string, enabled bool) (*spec.InstallSpec, error) {
+	yamlData, err := readToolSpecData(cfgFile)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		yamlData, err = overlay.DefaultLayers().Apply(ctx, yamlData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply config overlays: %w", err)
+		}
+	}
+	return parseInstallSpec(yamlData, cfgFile)
+}
+
+// readToolSpecData reads the InstallSpec YAML of FILE#TOOL, or of FILE alone
+func readToolSpecData(ref string) ([]byte, error) {
+	file, tool := splitToolRef(ref)
+	yamlData, err := readInstallSpecData(file)
+	if err != nil {
+		return nil, err
+	}
+	return selectTool(yamlData, file, tool)
+}
+
+// readInstallSpecData reads the raw InstallSpec YAML from the config file or stdin,
+// decrypting it first when it is encrypted with SOPS
+func readInstallSpecData(cfgFile string) ([]byte, error) {
 	// Read the InstallSpec YAML file
 	log.Debugf("Reading InstallSpec from: %s", cfgFile)
 	var yamlData []byte
@@ -32,14 +74,54 @@ func loadInstallSpec(cfgFile string) (*spec.InstallSpec, error) {
 		}
 	}
 
+	if sops.IsEncrypted(yamlData) {
+		log.Debugf("Install spec %s is encrypted with SOPS", cfgFile)
+		yamlData, err = sops.Decrypt(context.Background(), yamlData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt install spec %s: %w", cfgFile, err)
+		}
+	}
+	return yamlData, nil
+}
+
+// parseInstallSpec unmarshals InstallSpec YAML read from cfgFile
+func parseInstallSpec(yamlData []byte, cfgFile string) (*spec.InstallSpec, error) {
 	// Unmarshal YAML into InstallSpec struct
 	log.Debug("Unmarshalling InstallSpec YAML")
 	var installSpec spec.InstallSpec
-	err = yaml.Unmarshal(yamlData, &installSpec)
+	err := yaml.Unmarshal(yamlData, &installSpec)
 	if err != nil {
 		log.WithError(err).Errorf("Failed to unmarshal install spec YAML from: %s", cfgFile)
 		return nil, fmt.Errorf("failed to unmarshal install spec YAML from %s: %w", cfgFile, err)
 	}
+	registerSpecHosts(&installSpec)
 
 	return &installSpec, nil
 }
+
+// registerSpecHosts makes HTTP clients send GITLAB_TOKEN to the GitLab instance
+// of installSpec. Specs register their host once, when they are loaded.
+func registerSpecHosts(installSpec *spec.InstallSpec) {
+	if installSpec.GetSource() == spec.Gitlab {
+		httpclient.AddGitLabHost(installSpec.GetHost())
+	}
+}
+
+// specContext returns a copy of ctx whose requests to the GitHub Enterprise
+// Server host of installSpec carry the token of that instance
+func specContext(ctx context.Context, installSpec *spec.InstallSpec) context.Context {
+	if installSpec.GetSource() == spec.Github && spec.StringValue(installSpec.Host) != "" {
+		return httpclient.WithGitHubHost(ctx, installSpec.GetHost())
+	}
+	return ctx
+}
+
+// releaseChecksumFunc returns a lookup of release asset checksums for tag, using
+// embedded checksums first and the platform's checksum file otherwise
+func releaseChecksumFunc(ctx context.Context, installSpec *spec.InstallSpec, tag string) func(osName, arch, filename string) (string, error) {
+	return func(osName, arch, filename string) (string, error) {
+		verifier := checksums.NewVerifier(installSpec, tag)
+		verifier.OS, verifier.Arch = osName, arch
+		return verifier.GetChecksum(ctx, filename)
+	}
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/overlay"
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/goccy/go-yaml"
+)
+
+// toolRefSeparator separates a spec file from the name of one of its tools, as
+// in .config/binstaller.yml#gh
+const toolRefSeparator = "#"
+
+// toolSpec is one tool of a multi-tool spec file
+type toolSpec struct {
+	name string
+	// data is the YAML of the tool with the shared fields merged in
+	data []byte
+}
+
+// toolRef returns the reference to the tool named tool of the spec file
+func toolRef(file, tool string) string {
+	if tool == "" {
+		return file
+	}
+	return file + toolRefSeparator + tool
+}
+
+// splitToolRef splits FILE#TOOL into the spec file and the tool name. A path
+// that exists is a file even when it contains the separator.
+func splitToolRef(ref string) (file, tool string) {
+	if ref == "-" {
+		return ref, ""
+	}
+	if _, err := os.Stat(ref); err == nil {
+		return ref, ""
+	}
+	i := strings.LastIndex(ref, toolRefSeparator)
+	if i < 0 {
+		return ref, ""
+	}
+	return ref[:i], ref[i+len(toolRefSeparator):]
+}
+
+// splitTools returns the tools of multi-tool InstallSpec YAML, or nil when it
+// declares none. The top-level fields other than tools are merged into every
+// tool the way overlays are: mappings key by key, while scalars and lists of
+// the tool replace the shared ones.
+func splitTools(yamlData []byte) ([]toolSpec, error) {
+	var doc map[string]any
+	if err := yaml.Unmarshal(yamlData, &doc); err != nil || doc["tools"] == nil {
+		// Parse errors are reported when the spec itself is parsed
+		return nil, nil
+	}
+	list, ok := doc["tools"].([]any)
+	if !ok {
+		return nil, fmt.Errorf("tools must be a list of install specs")
+	}
+	delete(doc, "tools")
+
+	tools := make([]toolSpec, 0, len(list))
+	seen := make(map[string]bool)
+	for i, item := range list {
+		fields, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("tools[%d] must be an install spec", i)
+		}
+		if fields["tools"] != nil {
+			return nil, fmt.Errorf("tools[%d] cannot declare tools of its own", i)
+		}
+		merged := map[string]any{}
+		overlay.Merge(merged, doc)
+		overlay.Merge(merged, fields)
+		data, err := yaml.Marshal(merged)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal tools[%d]: %w", i, err)
+		}
+
+		var installSpec spec.InstallSpec
+		if err := yaml.Unmarshal(data, &installSpec); err != nil {
+			return nil, fmt.Errorf("failed to parse tools[%d]: %w", i, err)
+		}
+		installSpec.SetDefaults()
+		name := installSpec.GetName()
+		switch {
+		case name == "":
+			return nil, fmt.Errorf("tools[%d] needs a name or repo", i)
+		case strings.Contains(name, toolRefSeparator):
+			return nil, fmt.Errorf("tools[%d]: name %q cannot contain %q", i, name, toolRefSeparator)
+		case seen[name]:
+			return nil, fmt.Errorf("tools[%d]: duplicate tool name %q", i, name)
+		}
+		seen[name] = true
+		tools = append(tools, toolSpec{name: name, data: data})
+	}
+	return tools, nil
+}
+
+// selectTool returns the YAML of the tool named tool of a multi-tool spec read
+// from file, or yamlData itself for a single-tool spec. The tool may be omitted
+// when the spec declares only one.
+func selectTool(yamlData []byte, file, tool string) ([]byte, error) {
+	tools, err := splitTools(yamlData)
+	if err != nil {
+		return nil, fmt.Errorf("invalid install spec %s: %w", file, err)
+	}
+	if len(tools) == 0 {
+		if tool != "" {
+			return nil, fmt.Errorf("cannot select tool %q: %s declares no tools", tool, file)
+		}
+		return yamlData, nil
+	}
+	if tool == "" && len(tools) == 1 {
+		return tools[0].data, nil
+	}
+
+	names := make([]string, 0, len(tools))
+	for _, t := range tools {
+		if t.name == tool {
+			log.Debugf("Selected tool %s of %s", tool, file)
+			return t.data, nil
+		}
+		names = append(names, t.name)
+	}
+	if tool == "" {
+		return nil, fmt.Errorf("%s declares several tools (%s); select one as %s", file, strings.Join(names, ", "), toolRef(file, "TOOL"))
+	}
+	return nil, fmt.Errorf("%s has no tool %q (tools: %s)", file, tool, strings.Join(names, ", "))
+}
+
+// expandToolRefs replaces every multi-tool spec file with references to its
+// tools. Files that cannot be read are kept, so callers report them, and
+// so is stdin.
+func expandToolRefs(files []string) []string {
+	refs := make([]string, 0, len(files))
+	for _, file := range files {
+		if file == "-" {
+			// stdin can only be read once
+			refs = append(refs, file)
+			continue
+		}
+		yamlData, err := readInstallSpecData(file)
+		if err != nil {
+			refs = append(refs, file)
+			continue
+		}
+		tools, err := splitTools(yamlData)
+		if err != nil || len(tools) == 0 {
+			refs = append(refs, file)
+			continue
+		}
+		for _, t := range tools {
+			refs = append(refs, toolRef(file, t.name))
+		}
+	}
+	return refs
+}
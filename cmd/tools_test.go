@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const multiToolSpec = `default_bin_dir: ./bin
+asset:
+  template: ${NAME}_${VERSION}_${OS}_${ARCH}${EXT}
+  default_extension: .tar.gz
+tools:
+  - repo: cli/cli
+    name: gh
+    asset:
+      default_extension: .zip
+  - repo: junegunn/fzf
+    default_bin_dir: ./tools
+`
+
+func TestSplitTools(t *testing.T) {
+	tools, err := splitTools([]byte(multiToolSpec))
+	if err != nil {
+		t.Fatalf("splitTools() error = %v", err)
+	}
+	if len(tools) != 2 || tools[0].name != "gh" || tools[1].name != "fzf" {
+		t.Fatalf("splitTools() = %v, want gh and fzf", tools)
+	}
+
+	gh, err := parseInstallSpec(tools[0].data, "gh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gh.GetRepo() != "cli/cli" || gh.GetDefaultBinDir() != "./bin" || gh.Asset.GetTemplate() != "${NAME}_${VERSION}_${OS}_${ARCH}${EXT}" || gh.Asset.GetDefaultExtension() != ".zip" {
+		t.Errorf("gh spec = %s, want the shared fields merged with its own", tools[0].data)
+	}
+	fzf, err := parseInstallSpec(tools[1].data, "fzf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fzf.GetDefaultBinDir() != "./tools" || fzf.Asset.GetDefaultExtension() != ".tar.gz" || len(fzf.Tools) != 0 {
+		t.Errorf("fzf spec = %s, want its default_bin_dir over the shared one", tools[1].data)
+	}
+
+	single, err := splitTools([]byte("repo: owner/repo\n"))
+	if err != nil || single != nil {
+		t.Errorf("splitTools(single) = %v, %v, want no tools", single, err)
+	}
+
+	for _, tc := range []struct {
+		name, yaml, wantErr string
+	}{
+		{"not a list", "tools: gh\n", "tools must be a list"},
+		{"no name", "tools:\n  - default_version: v1.0.0\n", "tools[0] needs a name or repo"},
+		{"duplicate", "tools:\n  - repo: cli/cli\n  - repo: other/cli\n", `duplicate tool name "cli"`},
+		{"nested", "tools:\n  - repo: cli/cli\n    tools:\n      - repo: a/b\n", "tools[0] cannot declare tools"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := splitTools([]byte(tc.yaml)); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("splitTools() error = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadInstallSpecTool(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "binstaller.yml")
+	writeTestFile(t, file, multiToolSpec, 0644)
+
+	installSpec, err := loadInstallSpec(toolRef(file, "fzf"))
+	if err != nil {
+		t.Fatalf("loadInstallSpec(fzf) error = %v", err)
+	}
+	if installSpec.GetRepo() != "junegunn/fzf" {
+		t.Errorf("loadInstallSpec(fzf) repo = %s, want junegunn/fzf", installSpec.GetRepo())
+	}
+
+	if _, err := loadInstallSpec(file); err == nil || !strings.Contains(err.Error(), "declares several tools (gh, fzf)") {
+		t.Errorf("loadInstallSpec() without a tool error = %v, want the tools listed", err)
+	}
+	if _, err := loadInstallSpec(toolRef(file, "jq")); err == nil || !strings.Contains(err.Error(), `no tool "jq"`) {
+		t.Errorf("loadInstallSpec(jq) error = %v, want an unknown tool", err)
+	}
+
+	single := filepath.Join(dir, "single.yml")
+	writeTestFile(t, single, "repo: owner/repo\n", 0644)
+	if _, err := loadInstallSpec(toolRef(single, "repo")); err == nil || !strings.Contains(err.Error(), "declares no tools") {
+		t.Errorf("loadInstallSpec(single#repo) error = %v, want no tools", err)
+	}
+
+	// A path containing the separator is still a file
+	hashed := filepath.Join(dir, "a#b.yml")
+	writeTestFile(t, hashed, "repo: owner/hashed\n", 0644)
+	if installSpec, err := loadInstallSpec(hashed); err != nil || installSpec.GetRepo() != "owner/hashed" {
+		t.Errorf("loadInstallSpec(%s) = %v, %v", hashed, installSpec, err)
+	}
+
+	refs := expandToolRefs([]string{file, single, filepath.Join(dir, "missing.yml")})
+	want := []string{file + "#gh", file + "#fzf", single, filepath.Join(dir, "missing.yml")}
+	if !slices.Equal(refs, want) {
+		t.Errorf("expandToolRefs() = %v, want %v", refs, want)
+	}
+}
+
+func TestInstallAllMultiTool(t *testing.T) {
+	t.Setenv("BINSTALLER_OS_VERSION", "")
+	t.Setenv("BINSTALLER_RECEIPTS_DIR", t.TempDir())
+	content := "#!/bin/sh\necho tool\n"
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(content)))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(content))
+	}))
+	defer server.Close()
+	oldURL := gitHubDownloadBaseURL
+	gitHubDownloadBaseURL = server.URL
+	defer func() { gitHubDownloadBaseURL = oldURL }()
+
+	oldBinDir, oldNoOverlays := installBinDir, installNoOverlays
+	installBinDir, installNoOverlays = t.TempDir(), true
+	defer func() { installBinDir, installNoOverlays = oldBinDir, oldNoOverlays }()
+
+	var specYAML strings.Builder
+	specYAML.WriteString("default_version: v1.0.0\nasset:\n  template: ${NAME}_${VERSION}_${OS}_${ARCH}\ntools:\n")
+	for _, name := range []string{"one", "two"} {
+		filename := fmt.Sprintf("%s_1.0.0_%s_%s", name, runtime.GOOS, runtime.GOARCH)
+		fmt.Fprintf(&specYAML, "  - repo: owner/%s\n    checksums:\n      embedded_checksums:\n        v1.0.0:\n          - filename: %s\n            hash: %s\n", name, filename, hash)
+	}
+	dir := t.TempDir()
+	file := filepath.Join(dir, "binstaller.yml")
+	writeTestFile(t, file, specYAML.String(), 0644)
+
+	var out bytes.Buffer
+	results, err := installAll(context.Background(), &out, expandToolRefs([]string{file}), filepath.Join(dir, "state.json"), false, false)
+	if err != nil {
+		t.Fatalf("installAll() error = %v\n%s", err, out.String())
+	}
+	if len(results) != 2 || results[0].name != "one" || results[1].name != "two" {
+		t.Fatalf("installAll() results = %+v, want one and two", results)
+	}
+	for _, name := range []string{"one", "two"} {
+		if _, err := os.Stat(filepath.Join(installBinDir, name)); err != nil {
+			t.Errorf("%s not installed: %v", name, err)
+		}
+		if !strings.Contains(out.String(), file+"#"+name) {
+			t.Errorf("summary lacks %s#%s:\n%s", file, name, out.String())
+		}
+	}
+}
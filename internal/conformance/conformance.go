@@ -0,0 +1,292 @@
+// Package conformance checks that the installer backends agree on what they
+// install: for a spec, release tag, and platform, each backend must resolve the
+// same asset URL, filename, and expected checksum as the reference resolution of
+// pkg/asset. New backends (such as a native PowerShell installer) implement
+// Backend and are run against the same fixtures.
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/binary-install/binstaller/internal/shell"
+	"github.com/binary-install/binstaller/pkg/asset"
+	"github.com/binary-install/binstaller/pkg/chocolatey"
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/goccy/go-yaml"
+)
+
+// Case is one spec, release tag, and platform of the matrix
+type Case struct {
+	Name string // fixture name
+	Spec []byte // InstallSpec YAML; every backend parses its own copy
+	Tag  string
+	OS   string
+	Arch string
+}
+
+func (c Case) String() string {
+	return fmt.Sprintf("%s@%s/%s_%s", c.Name, c.Tag, c.OS, c.Arch)
+}
+
+// Resolution is what a backend would download and verify for a case
+type Resolution struct {
+	URL      string
+	Filename string
+	// Hash is the expected checksum of the asset, or empty when the backend
+	// has no embedded checksum for it
+	Hash string
+}
+
+// Backend resolves cases the way one installer implementation does
+type Backend interface {
+	Name() string
+	// Resolve returns the resolution of c, or nil when the backend does not
+	// handle the case's platform
+	Resolve(ctx context.Context, c Case) (*Resolution, error)
+}
+
+// Mismatch is a backend disagreeing with the reference resolution
+type Mismatch struct {
+	Case    Case
+	Backend string
+	Want    *Resolution
+	Got     *Resolution
+	Err     error
+}
+
+func (m Mismatch) String() string {
+	if m.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", m.Case, m.Backend, m.Err)
+	}
+	return fmt.Sprintf("%s: %s resolved %+v, want %+v", m.Case, m.Backend, *m.Got, *m.Want)
+}
+
+// Run resolves every case with reference and each backend and returns the
+// disagreements. Cases a backend does not handle are skipped.
+func Run(ctx context.Context, reference Backend, backends []Backend, cases []Case) []Mismatch {
+	var mismatches []Mismatch
+	for _, c := range cases {
+		want, err := reference.Resolve(ctx, c)
+		if err != nil || want == nil {
+			mismatches = append(mismatches, Mismatch{Case: c, Backend: reference.Name(), Err: fmt.Errorf("reference resolution failed: %v", err)})
+			continue
+		}
+		for _, b := range backends {
+			got, err := b.Resolve(ctx, c)
+			switch {
+			case err != nil:
+				mismatches = append(mismatches, Mismatch{Case: c, Backend: b.Name(), Err: err})
+			case got != nil && *got != *want:
+				mismatches = append(mismatches, Mismatch{Case: c, Backend: b.Name(), Want: want, Got: got})
+			}
+		}
+	}
+	return mismatches
+}
+
+// DefaultPlatforms are tested for specs without supported_platforms
+var DefaultPlatforms = []string{"linux/amd64", "linux/arm64", "darwin/amd64", "darwin/arm64", "windows/amd64", "windows/386"}
+
+// FixtureCases returns the cases of every *.binstaller.yml spec in dir: each
+// supported platform (or DefaultPlatforms) at every version with embedded
+// checksums, or at defaultTag when the spec embeds none
+func FixtureCases(dir, defaultTag string) ([]Case, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.binstaller.yml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var cases []Case
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		installSpec, err := parseSpec(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+
+		tags := []string{defaultTag}
+		if installSpec.Checksums != nil && len(installSpec.Checksums.EmbeddedChecksums) > 0 {
+			tags = tags[:0]
+			for tag := range installSpec.Checksums.EmbeddedChecksums {
+				tags = append(tags, tag)
+			}
+			sort.Strings(tags)
+		}
+		platforms := DefaultPlatforms
+		if len(installSpec.SupportedPlatforms) > 0 {
+			platforms = nil
+			for _, p := range installSpec.SupportedPlatforms {
+				platforms = append(platforms, spec.PlatformOSString(p.OS)+"/"+spec.PlatformArchString(p.Arch))
+			}
+		}
+
+		name := strings.TrimSuffix(filepath.Base(file), ".binstaller.yml")
+		for _, tag := range tags {
+			for _, platform := range platforms {
+				osName, arch, _ := strings.Cut(platform, "/")
+				cases = append(cases, Case{Name: name, Spec: data, Tag: tag, OS: osName, Arch: arch})
+			}
+		}
+	}
+	return cases, nil
+}
+
+// parseSpec parses an InstallSpec and applies its defaults
+func parseSpec(data []byte) (*spec.InstallSpec, error) {
+	var installSpec spec.InstallSpec
+	if err := yaml.Unmarshal(data, &installSpec); err != nil {
+		return nil, err
+	}
+	installSpec.SetDefaults()
+	return &installSpec, nil
+}
+
+// releaseURL returns the download URL of a release asset
+func releaseURL(repo, tag, filename string) string {
+	return fmt.Sprintf("https://github.com/%s/releases/download/%s/%s", repo, tag, filename)
+}
+
+// GoBackend is the reference resolution of pkg/asset used by binst install
+type GoBackend struct{}
+
+// Name implements Backend
+func (GoBackend) Name() string { return "go" }
+
+// Resolve implements Backend
+func (GoBackend) Resolve(ctx context.Context, c Case) (*Resolution, error) {
+	installSpec, err := parseSpec(c.Spec)
+	if err != nil {
+		return nil, err
+	}
+	filename, err := asset.NewFilenameGenerator(installSpec, c.Tag).GenerateFilename(c.OS, c.Arch)
+	if err != nil {
+		return nil, err
+	}
+	hash, _ := installSpec.Checksums.GetEmbeddedChecksum(c.Tag, filename)
+	return &Resolution{URL: releaseURL(installSpec.GetRepo(), c.Tag, filename), Filename: filename, Hash: hash}, nil
+}
+
+// ShellBackend runs the generated POSIX installer with a stub curl that records
+// the requested URLs and serves placeholder content. The expected checksum is
+// read from the installer's checksum mismatch report.
+type ShellBackend struct {
+	// Dir is a scratch directory for installers and the curl stub
+	Dir string
+}
+
+// Name implements Backend
+func (ShellBackend) Name() string { return "shell" }
+
+// curlStub records the URL of every request and writes placeholder content
+const curlStub = `#!/bin/sh
+out=""
+while [ $# -gt 1 ]; do
+  case "$1" in
+    -o) out=$2; shift ;;
+    -H) shift ;;
+  esac
+  shift
+done
+echo "$1" >>"$CONFORMANCE_URL_LOG"
+[ -z "$out" ] || printf 'conformance' >"$out"
+`
+
+var expectedHashPattern = regexp.MustCompile(`Expected: ([0-9a-fA-F]+)`)
+
+// Resolve implements Backend
+func (b ShellBackend) Resolve(ctx context.Context, c Case) (*Resolution, error) {
+	installSpec, err := parseSpec(c.Spec)
+	if err != nil {
+		return nil, err
+	}
+	script, err := shell.GenerateWithVersion(installSpec, c.Tag)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp(b.Dir, "case-")
+	if err != nil {
+		return nil, err
+	}
+	stubDir := filepath.Join(dir, "stub")
+	if err := os.MkdirAll(stubDir, 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(stubDir, "curl"), []byte(curlStub), 0755); err != nil {
+		return nil, err
+	}
+	scriptPath := filepath.Join(dir, "install.sh")
+	if err := os.WriteFile(scriptPath, script, 0755); err != nil {
+		return nil, err
+	}
+	urlLog := filepath.Join(dir, "urls")
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", scriptPath, "-n", "-b", filepath.Join(dir, "bin"))
+	cmd.Env = append(os.Environ(),
+		"PATH="+stubDir+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"CONFORMANCE_URL_LOG="+urlLog,
+		"BINSTALLER_OS="+c.OS,
+		"BINSTALLER_ARCH="+c.Arch,
+		"GITHUB_TOKEN=",
+	)
+	cmd.Stdout, cmd.Stderr = &output, &output
+	// The placeholder asset fails verification or extraction; only the requests matter
+	_ = cmd.Run()
+
+	urls, err := os.ReadFile(urlLog)
+	if err != nil || len(bytes.TrimSpace(urls)) == 0 {
+		return nil, fmt.Errorf("installer requested no URL:\n%s", output.String())
+	}
+	url, _, _ := strings.Cut(string(urls), "\n")
+	r := &Resolution{URL: url, Filename: url[strings.LastIndex(url, "/")+1:]}
+	if m := expectedHashPattern.FindStringSubmatch(output.String()); m != nil {
+		r.Hash = m[1]
+	}
+	return r, nil
+}
+
+// PowerShellBackend resolves the Windows assets the way the Chocolatey
+// PowerShell install script does
+type PowerShellBackend struct{}
+
+// Name implements Backend
+func (PowerShellBackend) Name() string { return "powershell" }
+
+// Resolve implements Backend
+func (PowerShellBackend) Resolve(ctx context.Context, c Case) (*Resolution, error) {
+	if c.OS != "windows" || (c.Arch != "amd64" && c.Arch != "386") {
+		return nil, nil
+	}
+	installSpec, err := parseSpec(c.Spec)
+	if err != nil {
+		return nil, err
+	}
+	pkg, err := chocolatey.New(installSpec, c.Tag, func(osName, arch, filename string) (string, error) {
+		hash, _ := installSpec.Checksums.GetEmbeddedChecksum(c.Tag, filename)
+		return hash, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	url, hash := pkg.URL64, pkg.Checksum64
+	if c.Arch == "386" {
+		url, hash = pkg.URL, pkg.Checksum
+	}
+	if url == "" {
+		return nil, nil
+	}
+	return &Resolution{URL: url, Filename: url[strings.LastIndex(url, "/")+1:], Hash: hash}, nil
+}
@@ -0,0 +1,55 @@
+package conformance
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+)
+
+func TestBackendsAgreeOnFixtures(t *testing.T) {
+	cases, err := FixtureCases("../../testdata", "v1.2.3")
+	if err != nil {
+		t.Fatalf("FixtureCases() error = %v", err)
+	}
+	if len(cases) == 0 {
+		t.Fatal("no fixture cases found")
+	}
+
+	backends := []Backend{PowerShellBackend{}}
+	if _, err := exec.LookPath("sh"); err == nil {
+		backends = append(backends, ShellBackend{Dir: t.TempDir()})
+	} else {
+		t.Log("sh not found; skipping the shell backend")
+	}
+
+	for _, m := range Run(t.Context(), GoBackend{}, backends, cases) {
+		t.Error(m)
+	}
+}
+
+// staticBackend resolves every case to the same resolution
+type staticBackend struct {
+	name       string
+	resolution *Resolution
+}
+
+func (b staticBackend) Name() string { return b.name }
+
+func (b staticBackend) Resolve(ctx context.Context, c Case) (*Resolution, error) {
+	return b.resolution, nil
+}
+
+func TestRunReportsMismatches(t *testing.T) {
+	want := &Resolution{URL: "https://example.com/tool.tar.gz", Filename: "tool.tar.gz", Hash: "abc"}
+	cases := []Case{{Name: "tool", Tag: "v1.0.0", OS: "linux", Arch: "amd64"}}
+	backends := []Backend{
+		staticBackend{name: "same", resolution: &Resolution{URL: want.URL, Filename: want.Filename, Hash: want.Hash}},
+		staticBackend{name: "unsupported"},
+		staticBackend{name: "different", resolution: &Resolution{URL: want.URL, Filename: want.Filename, Hash: "def"}},
+	}
+
+	mismatches := Run(context.Background(), staticBackend{name: "reference", resolution: want}, backends, cases)
+	if len(mismatches) != 1 || mismatches[0].Backend != "different" {
+		t.Fatalf("Run() = %v, want one mismatch from the different backend", mismatches)
+	}
+}
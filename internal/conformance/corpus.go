@@ -0,0 +1,218 @@
+package conformance
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/binary-install/binstaller/pkg/asset"
+	"github.com/binary-install/binstaller/pkg/checksums"
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/goccy/go-yaml"
+)
+
+// corpusFS is the corpus vendored with binst
+//
+//go:embed corpus
+var corpusFS embed.FS
+
+// Corpus returns the corpus vendored with binst: real-world specs and the
+// asset lists of their releases, recorded once with binst conformance --record
+func Corpus() fs.FS {
+	sub, err := fs.Sub(corpusFS, "corpus")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+// RecordedRelease is a release of a corpus spec as recorded from its repository
+type RecordedRelease struct {
+	Tag string `yaml:"tag"`
+	// Name and PublishedAt are only recorded for specs using release metadata
+	// variables in their asset templates
+	Name        string    `yaml:"name,omitempty"`
+	PublishedAt time.Time `yaml:"published_at,omitempty"`
+	Assets      []string  `yaml:"assets"`
+}
+
+// Fixture is a corpus spec and its recorded releases
+type Fixture struct {
+	Name     string
+	Spec     []byte
+	Releases []RecordedRelease
+}
+
+// ReleasesFilename returns the name of the file recording the releases of the
+// fixture name
+func ReleasesFilename(name string) string {
+	return name + ".releases.yml"
+}
+
+// LoadCorpus reads every NAME.binstaller.yml spec of fsys with the releases
+// recorded in NAME.releases.yml, sorted by name. Only the fixtures in names are
+// read when names is not empty.
+func LoadCorpus(fsys fs.FS, names ...string) ([]Fixture, error) {
+	files, err := fs.Glob(fsys, "*.binstaller.yml")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var fixtures []Fixture
+	for _, file := range files {
+		name := strings.TrimSuffix(path.Base(file), ".binstaller.yml")
+		if len(names) > 0 && !slices.Contains(names, name) {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		f := Fixture{Name: name, Spec: data}
+		recorded, err := fs.ReadFile(fsys, ReleasesFilename(name))
+		if err != nil {
+			return nil, fmt.Errorf("%s has no recorded releases: %w", name, err)
+		}
+		if err := yaml.Unmarshal(recorded, &f.Releases); err != nil {
+			return nil, fmt.Errorf("%s: %w", ReleasesFilename(name), err)
+		}
+		fixtures = append(fixtures, f)
+	}
+	for _, name := range names {
+		if !slices.ContainsFunc(fixtures, func(f Fixture) bool { return f.Name == name }) {
+			return nil, fmt.Errorf("no fixture named %s in the corpus", name)
+		}
+	}
+	return fixtures, nil
+}
+
+// MarshalReleases encodes recorded releases in the NAME.releases.yml format
+func MarshalReleases(releases []RecordedRelease) ([]byte, error) {
+	data, err := yaml.Marshal(releases)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte("# Release assets of the corpus spec. Refresh with binst conformance --record.\n"), data...), nil
+}
+
+// Failure is a recorded release, or a platform of it, that the spec does not
+// resolve to the release's assets
+type Failure struct {
+	Fixture  string
+	Tag      string
+	Platform string
+	Message  string
+}
+
+func (f Failure) String() string {
+	if f.Platform == "" {
+		return fmt.Sprintf("%s@%s: %s", f.Fixture, f.Tag, f.Message)
+	}
+	return fmt.Sprintf("%s@%s %s: %s", f.Fixture, f.Tag, f.Platform, f.Message)
+}
+
+// Report is the result of verifying a fixture
+type Report struct {
+	Fixture string
+	// Checked is the number of release and platform pairs whose asset was found
+	Checked  int
+	Failures []Failure
+}
+
+// Verify checks that the asset filename binst generates for every platform of
+// every recorded release of f, or one of its fallbacks, is an asset of the
+// release, and so is its checksum file. Platforms are the spec's
+// supported_platforms; specs without them must resolve at least one platform
+// to a recorded asset.
+func Verify(f Fixture) Report {
+	report := Report{Fixture: f.Name}
+	installSpec, err := parseSpec(f.Spec)
+	if err != nil {
+		report.Failures = append(report.Failures, Failure{Fixture: f.Name, Message: err.Error()})
+		return report
+	}
+	if installSpec.Asset == nil {
+		report.Failures = append(report.Failures, Failure{Fixture: f.Name, Message: "asset configuration is required"})
+		return report
+	}
+	declared := len(installSpec.SupportedPlatforms) > 0
+
+	osVersions := []string{""}
+	for _, rule := range installSpec.Asset.Rules {
+		if pattern := rule.GetWhen().GetOSVersion(); pattern != "" && !slices.Contains(osVersions, pattern) {
+			osVersions = append(osVersions, pattern)
+		}
+	}
+
+	for _, release := range f.Releases {
+		fail := func(platform, format string, args ...any) {
+			report.Failures = append(report.Failures, Failure{Fixture: f.Name, Tag: release.Tag, Platform: platform, Message: fmt.Sprintf(format, args...)})
+		}
+		if installSpec.UsesReleaseVariables() && release.PublishedAt.IsZero() {
+			fail("", "release metadata not recorded")
+			continue
+		}
+		generator := asset.NewFilenameGenerator(installSpec, release.Tag)
+		if installSpec.UsesReleaseVariables() {
+			generator.ReleaseVars = spec.ReleaseVars(release.Name, release.PublishedAt)
+		}
+
+		found := 0
+		for _, platform := range generator.Platforms() {
+			var generated [][]string
+			for _, osVersion := range osVersions {
+				g := *generator
+				g.OSVersion = osVersion
+				label := platform.String()
+				if osVersion != "" {
+					label += " (os_version " + osVersion + ")"
+				}
+				candidates, err := g.Candidates(platform.OS, platform.Arch)
+				if err != nil {
+					fail(label, "%v", err)
+					continue
+				}
+				if slices.ContainsFunc(generated, func(c []string) bool { return slices.Equal(c, candidates) }) {
+					// The OS version rules do not change the asset of this platform
+					continue
+				}
+				generated = append(generated, candidates)
+				i := slices.IndexFunc(candidates, func(c string) bool { return slices.Contains(release.Assets, c) })
+				if i < 0 {
+					if declared {
+						fail(label, "no asset named %s", strings.Join(candidates, " or "))
+					}
+					continue
+				}
+				found++
+				report.Checked++
+
+				verifier := checksums.NewVerifier(installSpec, release.Tag)
+				verifier.OS, verifier.Arch, verifier.OSVersion = platform.OS, platform.Arch, osVersion
+				if checksumFile := verifier.ChecksumFilename(candidates[i]); checksumFile != "" && !slices.Contains(release.Assets, checksumFile) {
+					fail(label, "no checksum file named %s", checksumFile)
+				}
+				extras, err := g.ExtraFilenames(platform.OS, platform.Arch)
+				if err != nil {
+					fail(label, "%v", err)
+					continue
+				}
+				for _, extra := range extras {
+					if !slices.Contains(release.Assets, extra) {
+						fail(label, "no extra file named %s", extra)
+					}
+				}
+			}
+		}
+		if !declared && found == 0 {
+			fail("", "no platform resolves to an asset of the release")
+		}
+	}
+	return report
+}
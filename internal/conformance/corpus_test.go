@@ -0,0 +1,148 @@
+package conformance
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+func TestCorpus(t *testing.T) {
+	fixtures, err := LoadCorpus(Corpus())
+	if err != nil {
+		t.Fatalf("LoadCorpus() error = %v", err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatal("the vendored corpus has no specs")
+	}
+	for _, f := range fixtures {
+		report := Verify(f)
+		for _, failure := range report.Failures {
+			t.Error(failure)
+		}
+		if report.Checked == 0 {
+			t.Errorf("%s: no asset checked", f.Name)
+		}
+	}
+}
+
+const corpusSpec = `schema: v1
+name: tool
+repo: owner/tool
+asset:
+  template: ${NAME}_${VERSION}_${OS}_${ARCH}${EXT}
+  default_extension: .tar.gz
+  rules:
+  - when:
+      arch: amd64
+    arch: x86_64
+  - when:
+      os: windows
+    ext: .zip
+checksums:
+  template: ${NAME}_${VERSION}_checksums.txt
+supported_platforms:
+- os: linux
+  arch: amd64
+- os: windows
+  arch: amd64
+`
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		assets  []string
+		checked int
+		want    []string
+	}{
+		{
+			name:    "all assets",
+			spec:    corpusSpec,
+			assets:  []string{"tool_1.0.0_linux_x86_64.tar.gz", "tool_1.0.0_windows_x86_64.zip", "tool_1.0.0_checksums.txt"},
+			checked: 2,
+		},
+		{
+			name:    "missing asset",
+			spec:    corpusSpec,
+			assets:  []string{"tool_1.0.0_linux_x86_64.tar.gz", "tool_1.0.0_windows_x86_64.tar.gz", "tool_1.0.0_checksums.txt"},
+			checked: 1,
+			want:    []string{"tool@v1.0.0 windows/amd64: no asset named tool_1.0.0_windows_x86_64.zip"},
+		},
+		{
+			name:    "missing checksum file",
+			spec:    corpusSpec,
+			assets:  []string{"tool_1.0.0_linux_x86_64.tar.gz", "tool_1.0.0_windows_x86_64.zip", "checksums.txt"},
+			checked: 2,
+			want: []string{
+				"tool@v1.0.0 linux/amd64: no checksum file named tool_1.0.0_checksums.txt",
+				"tool@v1.0.0 windows/amd64: no checksum file named tool_1.0.0_checksums.txt",
+			},
+		},
+		{
+			name:    "undeclared platforms",
+			spec:    strings.Split(corpusSpec, "supported_platforms:")[0],
+			assets:  []string{"tool_1.0.0_linux_x86_64.tar.gz", "tool_1.0.0_checksums.txt"},
+			checked: 1,
+		},
+		{
+			name:   "undeclared platforms without assets",
+			spec:   strings.Split(corpusSpec, "supported_platforms:")[0],
+			assets: []string{"tool-linux-amd64.tar.gz"},
+			want:   []string{"tool@v1.0.0: no platform resolves to an asset of the release"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Verify(Fixture{Name: "tool", Spec: []byte(tt.spec), Releases: []RecordedRelease{{Tag: "v1.0.0", Assets: tt.assets}}})
+			var got []string
+			for _, failure := range report.Failures {
+				got = append(got, failure.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Verify() failures = %q, want %q", got, tt.want)
+			}
+			if report.Checked != tt.checked {
+				t.Errorf("Verify() checked = %d, want %d", report.Checked, tt.checked)
+			}
+		})
+	}
+}
+
+func TestLoadCorpus(t *testing.T) {
+	releases := []RecordedRelease{
+		{Tag: "v1.0.0", Assets: []string{"tool_1.0.0_linux_x86_64.tar.gz"}},
+		{Tag: "v0.9.0", Name: "Spring release", PublishedAt: time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC), Assets: []string{"tool_0.9.0_linux_x86_64.tar.gz"}},
+	}
+	data, err := MarshalReleases(releases)
+	if err != nil {
+		t.Fatalf("MarshalReleases() error = %v", err)
+	}
+	fsys := fstest.MapFS{
+		"tool.binstaller.yml":  {Data: []byte(corpusSpec)},
+		"tool.releases.yml":    {Data: data},
+		"other.binstaller.yml": {Data: []byte(corpusSpec)},
+	}
+
+	fixtures, err := LoadCorpus(fsys, "tool")
+	if err != nil {
+		t.Fatalf("LoadCorpus() error = %v", err)
+	}
+	if len(fixtures) != 1 || fixtures[0].Name != "tool" || !reflect.DeepEqual(fixtures[0].Releases, releases) {
+		t.Errorf("LoadCorpus() = %+v, want the tool fixture with %+v", fixtures, releases)
+	}
+	var raw []map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil || len(raw[0]) != 2 {
+		t.Errorf("release without metadata encoded as %v, want only tag and assets", raw)
+	}
+
+	if _, err := LoadCorpus(fsys); err == nil || !strings.Contains(err.Error(), "other has no recorded releases") {
+		t.Errorf("LoadCorpus() error = %v, want missing releases of other", err)
+	}
+	if _, err := LoadCorpus(fsys, "missing"); err == nil {
+		t.Error("LoadCorpus() should fail for an unknown fixture name")
+	}
+}
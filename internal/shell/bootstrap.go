@@ -0,0 +1,132 @@
+package shell
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/binary-install/binstaller/pkg/asset"
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/buildkite/interpolate"
+	"github.com/goccy/go-yaml"
+)
+
+// bootstrapSpecDelimiter terminates the here-document embedding the InstallSpec
+const bootstrapSpecDelimiter = "BINSTALLER_SPEC_EOF"
+
+// Bootstrap pins the binst release that a two-stage installer downloads when
+// BINSTALLER_BOOTSTRAP=1 is set, delegating installation to `binst install`
+type Bootstrap struct {
+	Repo   string
+	Tag    string
+	Assets []BootstrapAsset
+}
+
+// BootstrapAsset is a binst release asset for one platform with its pinned SHA-256 hash
+type BootstrapAsset struct {
+	OS         string
+	Arch       string
+	Filename   string
+	Hash       string
+	BinaryPath string
+}
+
+// NewBootstrap resolves the binst assets of tag for every supported platform of
+// binstSpec. Hashes come from the spec's embedded checksums, so generation never
+// touches the network; platforms without an embedded checksum are skipped.
+func NewBootstrap(binstSpec *spec.InstallSpec, tag string) (*Bootstrap, error) {
+	if binstSpec == nil {
+		return nil, fmt.Errorf("bootstrap spec cannot be nil")
+	}
+	if tag == "" || tag == "latest" {
+		return nil, fmt.Errorf("bootstrap requires a pinned binst version, got %q", tag)
+	}
+	if err := spec.Validate(binstSpec); err != nil {
+		return nil, fmt.Errorf("invalid bootstrap spec: %w", err)
+	}
+	binstSpec.SetDefaults()
+	if algo := spec.AlgorithmString(binstSpec.GetChecksums().Algorithm); algo != "" && algo != "sha256" {
+		return nil, fmt.Errorf("bootstrap requires sha256 checksums, got %s", algo)
+	}
+
+	version := strings.TrimPrefix(tag, "v")
+	generator := asset.NewFilenameGenerator(binstSpec, tag)
+	b := &Bootstrap{Repo: spec.StringValue(binstSpec.Repo), Tag: tag}
+	for _, p := range binstSpec.SupportedPlatforms {
+		osName, arch := spec.PlatformOSString(p.OS), spec.PlatformArchString(p.Arch)
+		filename, err := generator.GenerateFilename(osName, arch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate bootstrap asset filename for %s/%s: %w", osName, arch, err)
+		}
+		hash, ok := binstSpec.Checksums.GetEmbeddedChecksum(tag, filename)
+		if !ok {
+			hash, ok = binstSpec.Checksums.GetEmbeddedChecksum(version, filename)
+		}
+		if !ok {
+			continue
+		}
+		binaryPath, err := bootstrapBinaryPath(binstSpec, generator, osName, arch, filename)
+		if err != nil {
+			return nil, err
+		}
+		if osName == "windows" && !strings.HasSuffix(binaryPath, ".exe") {
+			binaryPath += ".exe"
+		}
+		b.Assets = append(b.Assets, BootstrapAsset{
+			OS:         osName,
+			Arch:       arch,
+			Filename:   filename,
+			Hash:       hash,
+			BinaryPath: binaryPath,
+		})
+	}
+	if len(b.Assets) == 0 {
+		return nil, fmt.Errorf("no embedded checksums for %s %s; run 'binst embed-checksums --version %s' on the bootstrap spec first", b.Repo, tag, tag)
+	}
+	return b, nil
+}
+
+// bootstrapBinaryPath returns the path of binst inside its asset filename for
+// osName/arch, expanding ${NAME}, ${VERSION}, ${TAG} and ${ASSET_FILENAME}
+func bootstrapBinaryPath(binstSpec *spec.InstallSpec, generator *asset.FilenameGenerator, osName, arch, filename string) (string, error) {
+	binaries := generator.Binaries(osName, arch)
+	if len(binaries) == 0 {
+		return "", fmt.Errorf("bootstrap spec has no binaries for %s/%s", osName, arch)
+	}
+	binaryPath, err := interpolate.Interpolate(interpolate.NewMapEnv(map[string]string{
+		"NAME":           binstSpec.GetName(),
+		"VERSION":        strings.TrimPrefix(generator.Version, "v"),
+		"TAG":            generator.Version,
+		"ASSET_FILENAME": filename,
+	}), spec.StringValue(binaries[0].Path))
+	if err != nil {
+		return "", fmt.Errorf("failed to interpolate bootstrap binary path for %s/%s: %w", osName, arch, err)
+	}
+	if binaryPath == "" {
+		binaryPath = spec.StringValue(binaries[0].Name)
+	}
+	if binaryPath == "" {
+		binaryPath = binstSpec.GetName()
+	}
+	return path.Clean(binaryPath), nil
+}
+
+// bootstrapSpecYAML renders the InstallSpec passed to binst install by the second stage
+func bootstrapSpecYAML(installSpec *spec.InstallSpec) (string, error) {
+	data, err := yaml.Marshal(installSpec)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal install spec for bootstrap: %w", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == bootstrapSpecDelimiter {
+			return "", fmt.Errorf("install spec contains reserved line %q", bootstrapSpecDelimiter)
+		}
+	}
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
+
+// sha256Function returns the hash_sha256 shell function without hash_compute
+func sha256Function() string {
+	fn, _, _ := strings.Cut(hashSHA256, "\nhash_compute() {")
+	return strings.TrimRight(fn, "\n")
+}
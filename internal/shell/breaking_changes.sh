@@ -0,0 +1,31 @@
+# Print the first version number in the --version output of the binary at $1
+installed_version() {
+  [ -x "$1" ] || return 0
+  "$1" --version </dev/null 2>/dev/null | sed -n 's/^[^0-9]*\([0-9][0-9]*\.[0-9][0-9]*\(\.[0-9][0-9]*\)\{0,2\}\).*/\1/p' | head -n 1
+  return 0
+}
+
+# Succeed when version $1 is lower than version $2, comparing up to four
+# numbers separated by dots or dashes, such as major.minor.patch or a date
+# (2024.05.01). A prefix before the first digit (v, build-) is ignored.
+version_lt() {
+  awk -v a="$1" -v b="$2" 'BEGIN {
+    sub(/^[^0-9]*/, "", a); sub(/^[^0-9]*/, "", b)
+    split(a, x, /[.-]/); split(b, y, /[.-]/)
+    for (i = 1; i <= 4; i++) {
+      if (x[i] + 0 < y[i] + 0) exit 0
+      if (x[i] + 0 > y[i] + 0) exit 1
+    }
+    exit 1
+  }'
+}
+
+# Warn when upgrading from installed version $1 to VERSION crosses the breaking
+# change in version $2, printing the migration notes $3
+breaking_change() {
+  if version_lt "$1" "$2" && ! version_lt "${VERSION}" "$2"; then
+    log_warn "Upgrading ${NAME} from $1 to ${VERSION} crosses a breaking change in ${2#v}"
+    [ -z "$3" ] || log_warn "Migration notes: $3"
+  fi
+  return 0
+}
@@ -0,0 +1,35 @@
+public_key_download() {
+  log_debug "public_key_download $2"
+  if is_command curl; then
+    curl -fsSL -o "$1" "$2"
+  elif is_command wget; then
+    wget -q -O "$1" "$2"
+  else
+    log_crit "public_key_download unable to find wget or curl"
+    return 1
+  fi
+}
+gpg_verify() {
+  if ! is_command gpg; then
+    log_crit "gpg is required to verify the signature of ${1##*/}"
+    return 1
+  fi
+  gpg_home=$(mktemp -d)
+  status=0
+  gpg --homedir "${gpg_home}" --batch --quiet --import "$3" 2>/dev/null || status=$?
+  if [ "$status" -eq 0 ]; then
+    gpg --homedir "${gpg_home}" --batch --quiet --trust-model always --verify "$2" "$1" || status=$?
+  else
+    log_err "gpg failed to import the public key"
+  fi
+  rm -rf "${gpg_home}"
+  return "$status"
+}
+minisign_verify() {
+  if ! is_command minisign; then
+    log_crit "minisign is required to verify the signature of ${1##*/}"
+    return 1
+  fi
+  public_key=$(grep -v '^untrusted comment:' "$3" | sed -n '/./{p;q;}')
+  minisign -Vqm "$1" -x "$2" -P "${public_key}"
+}
@@ -43,5 +43,47 @@ var hashSHA1 string
 //go:embed hash_md5.sh
 var hashMD5 string
 
+// hashVerify checks an asset against a checksum file; it is only included in
+// scripts that verify checksums
+//
+//go:embed hash_verify.sh
+var hashVerify string
+
 //go:embed shell_functions.sh
 var shellFunctions string
+
+// untarZstd extracts .tar.zst archives and decompresses plain .zst files; it is
+// only included for specs that use them
+//
+//go:embed untar_zstd.sh
+var untarZstd string
+
+// untar7z extracts .7z archives with the 7z command; it is only included for
+// specs that use them
+//
+//go:embed untar_7z.sh
+var untar7z string
+
+// checksumSignature verifies the gpg or minisign signature of checksum files;
+// it is only included for specs with checksums.signature
+//
+//go:embed checksum_signature.sh
+var checksumSignature string
+
+// osVersion detects and matches the OS version; it is only included for specs
+// with when.os_version rules
+//
+//go:embed os_version.sh
+var osVersion string
+
+// gitLab downloads from GitLab releases; it is only included for specs with
+// source: gitlab
+//
+//go:embed gitlab.sh
+var gitLab string
+
+// breakingChanges compares versions for the breaking change warnings; it is
+// only included in installers for specs with breaking_changes
+//
+//go:embed breaking_changes.sh
+var breakingChanges string
@@ -0,0 +1,21 @@
+gitlab_http_download() {
+  # Authenticate with GITLAB_TOKEN; GITHUB_TOKEN is never sent to GitLab
+  (
+    GITHUB_TOKEN=${GITLAB_TOKEN:-}
+    github_http_download "$@"
+  )
+}
+gitlab_http_copy() {
+  (
+    GITHUB_TOKEN=${GITLAB_TOKEN:-}
+    github_http_copy "$@"
+  )
+}
+gitlab_release() {
+  project_api=$1
+  json=$(gitlab_http_copy "${project_api}/releases/permalink/latest" "Accept:application/json")
+  test -z "$json" && return 1
+  version=$(echo "$json" | tr -s '\n' ' ' | sed 's/.*"tag_name" *: *"//' | sed 's/".*//')
+  test -z "$version" && return 1
+  echo "$version"
+}
@@ -0,0 +1,37 @@
+package shell
+
+import (
+	"os"
+	"testing"
+
+	"github.com/binary-install/binstaller/internal/testutil"
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/goccy/go-yaml"
+)
+
+// TestGoldenInstallers compares the installers generated for the testdata corpus
+// with the committed scripts. Run with BINSTALLER_UPDATE_GOLDEN=1 (or
+// `binst gen --update-golden`) after an intended template change.
+func TestGoldenInstallers(t *testing.T) {
+	snapshots, err := testutil.Corpus("../../testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range snapshots {
+		t.Run(s.Name, func(t *testing.T) {
+			data, err := os.ReadFile(s.SpecPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var installSpec spec.InstallSpec
+			if err := yaml.Unmarshal(data, &installSpec); err != nil {
+				t.Fatalf("failed to parse %s: %v", s.SpecPath, err)
+			}
+			script, err := Generate(&installSpec)
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			testutil.AssertGolden(t, s.GoldenPath, script)
+		})
+	}
+}
@@ -0,0 +1,28 @@
+uname_os_version() {
+  if [ -n "${BINSTALLER_OS_VERSION:-}" ]; then
+    echo "${BINSTALLER_OS_VERSION}"
+    return 0
+  fi
+  case "$1" in
+    darwin)
+      version=$(sw_vers -productVersion 2>/dev/null) || version=""
+      [ -n "${version}" ] && echo "macos-${version%%.*}"
+      ;;
+    linux)
+      if [ -r /etc/os-release ]; then
+        (
+          . /etc/os-release
+          [ -n "${ID:-}" ] && echo "${ID}${VERSION_ID:+-${VERSION_ID}}"
+        ) || true
+      fi
+      ;;
+  esac
+  return 0
+}
+os_version_matches() {
+  [ -n "${OS_VERSION}" ] || return 1
+  # $1 is intentionally unquoted so it is matched as a glob pattern
+  case "${OS_VERSION}" in $1) return 0 ;; esac
+  case "${OS_VERSION%%-*}" in $1) return 0 ;; esac
+  return 1
+}
@@ -0,0 +1,75 @@
+package shell
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// byteOrderArches are the architectures uname -m reports without their byte
+// order: mips64 and mips64le machines both report mips64
+var byteOrderArches = map[string]bool{
+	"mips":     true,
+	"mipsle":   true,
+	"mips64":   true,
+	"mips64le": true,
+}
+
+// archDetectionNotes explain why the uname -m detection of generated scripts
+// may pick the wrong asset for an architecture
+var archDetectionNotes = map[string]string{
+	"mips":     "uname -m reports mips for both byte orders, so the script probes the byte order with od",
+	"mipsle":   "uname -m reports mips for both byte orders, so the script probes the byte order with od",
+	"mips64":   "uname -m reports mips64 for both byte orders, so the script probes the byte order with od",
+	"mips64le": "uname -m reports mips64 for both byte orders, so the script probes the byte order with od",
+	"ppc64":    "only Linux reports big-endian POWER as ppc64; AIX and other systems report a machine type",
+	"s390x":    "31-bit s390 userlands report s390 and are refused",
+}
+
+// declaredArches returns the sorted architectures the spec declares in
+// supported_platforms and asset rules
+func declaredArches(installSpec *spec.InstallSpec) []string {
+	seen := make(map[string]bool)
+	for _, p := range installSpec.SupportedPlatforms {
+		if arch := spec.PlatformArchString(p.Arch); arch != "" {
+			seen[arch] = true
+		}
+	}
+	if installSpec.Asset != nil {
+		for _, rule := range installSpec.Asset.Rules {
+			if arch := rule.GetWhen().GetArch(); arch != "" {
+				seen[arch] = true
+			}
+		}
+	}
+	arches := make([]string, 0, len(seen))
+	for arch := range seen {
+		arches = append(arches, arch)
+	}
+	sort.Strings(arches)
+	return arches
+}
+
+// probesByteOrder reports whether the spec declares an architecture whose byte
+// order the script has to probe
+func probesByteOrder(installSpec *spec.InstallSpec) bool {
+	for _, arch := range declaredArches(installSpec) {
+		if byteOrderArches[arch] {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectionWarnings returns a warning for every architecture the spec declares
+// that the platform detection of generated scripts cannot reliably identify
+func DetectionWarnings(installSpec *spec.InstallSpec) []string {
+	var warnings []string
+	for _, arch := range declaredArches(installSpec) {
+		if note, ok := archDetectionNotes[arch]; ok {
+			warnings = append(warnings, fmt.Sprintf("%s may be misdetected: %s; users can force it with -a %s or BINSTALLER_ARCH=%s", arch, note, arch, arch))
+		}
+	}
+	return warnings
+}
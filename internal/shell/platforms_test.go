@@ -0,0 +1,116 @@
+package shell
+
+import (
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+func TestDetectionWarnings(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}-${OS}-${ARCH}").
+			WithRules(spec.NewRule("", "s390x").WithArch("zarch"))).
+		WithSupportedPlatforms("linux/amd64", "linux/mips64le", "linux/ppc64")
+
+	warnings := DetectionWarnings(installSpec)
+	if len(warnings) != 3 {
+		t.Fatalf("DetectionWarnings() = %q, want mips64le, ppc64 and s390x", warnings)
+	}
+	for i, prefix := range []string{"mips64le may be misdetected", "ppc64 may be misdetected", "s390x may be misdetected"} {
+		if !strings.HasPrefix(warnings[i], prefix) {
+			t.Errorf("warning %d = %q, want prefix %q", i, warnings[i], prefix)
+		}
+	}
+	if !strings.Contains(warnings[0], "-a mips64le") {
+		t.Errorf("warning %q does not mention the -a override", warnings[0])
+	}
+
+	if warnings := DetectionWarnings(spec.NewInstallSpec("owner/tool").WithSupportedPlatforms("linux/amd64", "darwin/arm64")); len(warnings) != 0 {
+		t.Errorf("DetectionWarnings() for common platforms = %q, want none", warnings)
+	}
+}
+
+func TestGenerateByteOrderProbe(t *testing.T) {
+	probe := "# uname -m reports mips and mips64 for both byte orders"
+	got, err := Generate(spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}-${OS}-${ARCH}")).
+		WithSupportedPlatforms("linux/amd64", "linux/mipsle"))
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	script := string(got)
+	if !strings.Contains(script, probe) {
+		t.Error("script for mipsle does not probe the byte order")
+	}
+	if strings.Index(script, probe) > strings.Index(script, `log_info "Detected Platform: ${OS}/${ARCH}"`) {
+		t.Error("byte order is probed after the platform is logged")
+	}
+
+	got, err = Generate(spec.NewInstallSpec("owner/tool").WithAsset(spec.NewAsset("${NAME}-${OS}-${ARCH}")))
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if strings.Contains(string(got), probe) {
+		t.Error("script without mips platforms probes the byte order")
+	}
+}
+
+func TestByteOrderProbeCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+	out, err := exec.Command("sh", "-c", `printf '\001\000' | od -An -tu2 | tr -d ' '`).Output()
+	if err != nil {
+		t.Skipf("od not available: %v", err)
+	}
+	want := "256"
+	switch runtime.GOARCH {
+	case "amd64", "386", "arm64", "arm", "riscv64", "loong64", "ppc64le", "mipsle", "mips64le", "wasm":
+		want = "1"
+	}
+	if got := strings.TrimSpace(string(out)); got != want {
+		t.Errorf("byte order probe on %s = %q, want %q", runtime.GOARCH, got, want)
+	}
+}
+
+func TestRunnerPlatformOptions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+	got, err := GenerateRunner(spec.NewInstallSpec("owner/tool").WithAsset(spec.NewAsset("${NAME}-${OS}-${ARCH}")), "")
+	if err != nil {
+		t.Fatalf("GenerateRunner() error = %v", err)
+	}
+	script := string(got)
+	start := strings.Index(script, "# Leading --os and --arch options")
+	end := strings.Index(script[start:], "\ndone\n")
+	if start < 0 || end < 0 {
+		t.Fatalf("runner does not parse --os and --arch:\n%s", script)
+	}
+	loop := script[start : start+end+len("\ndone\n")]
+
+	tests := []struct {
+		args string
+		want string
+	}{
+		{args: "--os linux --arch=arm64 --version", want: "linux/arm64:--version"},
+		{args: "--arch 386 -- --os x", want: "/386:--os x"},
+		{args: "run --os linux", want: "/:run --os linux"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.args, func(t *testing.T) {
+			c := exec.Command("sh", "-c", "usage() { exit 2; }\nset -- "+tt.args+"\n"+loop+`printf '%s/%s:%s' "$BINSTALLER_OS" "$BINSTALLER_ARCH" "$*"`)
+			c.Env = []string{"PATH=/usr/bin:/bin"}
+			out, err := c.Output()
+			if err != nil {
+				t.Fatalf("sh failed: %v", err)
+			}
+			if string(out) != tt.want {
+				t.Errorf("runner options %q = %q, want %q", tt.args, out, tt.want)
+			}
+		})
+	}
+}
@@ -3,9 +3,14 @@ package shell
 import (
 	"bytes"
 	"fmt"
+	"net/url"
+	"regexp"
 	"strings"
 	"text/template"
+	"time"
+	"unicode"
 
+	"github.com/binary-install/binstaller/pkg/jsonpath"
 	"github.com/binary-install/binstaller/pkg/spec"
 	"github.com/pkg/errors"
 )
@@ -13,12 +18,137 @@ import (
 // templateData holds the data passed to the shell script template execution.
 // It only includes static data from the spec.
 type templateData struct {
-	*spec.InstallSpec        // Embed the original spec for access to fields like Name, Repo, Asset, Checksums, etc.
-	Shlib             string // The content of the shell function library
-	HashFunctions     string
-	ShellFunctions    string
-	TargetVersion     string // Fixed version when --target-version is specified
-	ScriptType        string // Type of script: "installer" or "runner"
+	*spec.InstallSpec         // Embed the original spec for access to fields like Name, Repo, Asset, Checksums, etc.
+	Shlib              string // The content of the shell function library
+	HashFunctions      string // hash_compute and hash_verify when VerifyChecksums is set
+	ShellFunctions     string
+	ZstdFunctions      string // untar_zstd and unzstd functions when the spec has .zst assets
+	SevenZipFunctions  string // untar_7z function when the spec has .7z assets
+	SignatureFunctions string // gpg_verify and minisign_verify when checksums.signature is set
+	OSVersionFunctions string // uname_os_version and os_version_matches when rules match on when.os_version
+	GitLabFunctions    string // gitlab_http_download and gitlab_release for source: gitlab
+	GitLabHost         string // Host of the GitLab instance for source: gitlab
+	GitHubHost         string // Host of github.com or the GitHub Enterprise Server instance for source: github
+	GitHubAPI          string // API URL of the GitHub instance for source: github
+	GitLabProjectAPI   string // GitLab API URL of the project for source: gitlab
+	DownloadFunc       string // Shell function downloading release files
+	BreakingFunctions  string // version_lt and breaking_change when an installer warns about breaking changes
+	TargetVersion      string // Fixed version when --target-version is specified
+	ScriptType         string // Type of script: "installer" or "runner"
+	Bootstrap          *Bootstrap
+	BootstrapSpec      string // InstallSpec YAML handed to binst install by the bootstrap stage
+	BootstrapHash      string // hash_sha256 function when HashFunctions does not define it
+	Features           Features
+	VerifyChecksums    bool            // Whether the spec has a checksum source to verify assets against
+	ProbeByteOrder     bool            // Whether ARCH needs the byte order to tell mips from mipsle
+	Channel            string          // Release channel of a channel alias script
+	UsagePingURL       string          // Endpoint installers ping after a successful install when usage_ping is enabled
+	Download           *downloadFlags  // curl and wget options of the download settings, nil without them
+	Wrappers           []wrapperScript // Wrapper scripts installers install next to the binaries
+	ChannelRefreshed   string          // When the channel was resolved to TargetVersion (RFC 3339)
+	ReleaseVars        []releaseVar    // Release metadata variables of TargetVersion set at generation time
+	Header             []string        // Lines of the script_header comment block after the shebang
+}
+
+// downloadFlags are the options scripts pass to curl and wget for the retries
+// and timeouts of the download settings
+type downloadFlags struct {
+	Curl        string
+	Wget        string
+	BusyBoxWget string // BusyBox wget has a timeout but no retry options
+}
+
+// newDownloadFlags returns the curl and wget options of d, or nil when it sets
+// nothing
+func newDownloadFlags(d *spec.Download) *downloadFlags {
+	if d == nil || (d.Retries == nil && d.RetryDelay == nil && d.Timeout == nil) {
+		return nil
+	}
+	var curl, wget []string
+	if d.Retries != nil {
+		curl = append(curl, fmt.Sprintf("--retry %d", d.GetRetries()))
+		wget = append(wget, fmt.Sprintf("--tries=%d", d.GetRetries()+1))
+	}
+	if d.RetryDelay != nil {
+		curl = append(curl, fmt.Sprintf("--retry-delay %d", d.GetRetryDelay()))
+		wget = append(wget, fmt.Sprintf("--waitretry=%d", d.GetRetryDelay()))
+	}
+	flags := &downloadFlags{}
+	if d.Timeout != nil {
+		curl = append(curl, fmt.Sprintf("--max-time %d", d.GetTimeout()))
+		wget = append(wget, fmt.Sprintf("--timeout=%d", d.GetTimeout()))
+		flags.BusyBoxWget = fmt.Sprintf("-T %d", d.GetTimeout())
+	}
+	flags.Curl = strings.Join(curl, " ")
+	flags.Wget = strings.Join(wget, " ")
+	return flags
+}
+
+// releaseVar is a release metadata variable and its value
+type releaseVar struct {
+	Name  string
+	Value string
+}
+
+// Features toggles optional parts of generated scripts. Disabled features are
+// left out of the script entirely.
+type Features struct {
+	DryRun bool // -n flag of installers
+	Quiet  bool // -q flag of installers and BINSTALLER_QUIET of runners
+	// Fallback redirects installers from a read-only bindir to ~/.local/bin,
+	// unless -s or BINSTALLER_NO_FALLBACK asks them to fail instead
+	Fallback bool
+	// Compat keeps the v0 long options (--bindir, --debug, ...) of installers
+	// working, with a deprecation warning
+	Compat bool
+	// BinDirEnv makes installers install into BINDIR from the environment, like
+	// v0 installers did. It is off by default because BINDIR is a common
+	// Makefile variable that callers do not mean for the installer.
+	BinDirEnv bool
+}
+
+// FeatureNames are the names of the features that can be disabled
+var FeatureNames = []string{"dry-run", "quiet", "compat", "fallback"}
+
+// OptInFeatureNames are the names of the features that can be enabled
+var OptInFeatureNames = []string{"bindir-env"}
+
+// DefaultFeatures returns the features enabled by default
+func DefaultFeatures() Features {
+	return Features{DryRun: true, Quiet: true, Compat: true, Fallback: true}
+}
+
+// DisableFeatures returns the default features without the named ones
+func DisableFeatures(names []string) (Features, error) {
+	features := DefaultFeatures()
+	for _, name := range names {
+		switch name {
+		case "dry-run":
+			features.DryRun = false
+		case "quiet":
+			features.Quiet = false
+		case "compat":
+			features.Compat = false
+		case "fallback":
+			features.Fallback = false
+		default:
+			return features, fmt.Errorf("unknown feature %q: must be one of %s", name, strings.Join(FeatureNames, ", "))
+		}
+	}
+	return features, nil
+}
+
+// EnableFeatures returns features with the named opt-in features enabled
+func EnableFeatures(features Features, names []string) (Features, error) {
+	for _, name := range names {
+		switch name {
+		case "bindir-env":
+			features.BinDirEnv = true
+		default:
+			return features, fmt.Errorf("unknown feature %q: must be one of %s", name, strings.Join(OptInFeatureNames, ", "))
+		}
+	}
+	return features, nil
 }
 
 // Generate creates the installer shell script content based on the InstallSpec.
@@ -40,6 +170,42 @@ func GenerateRunner(installSpec *spec.InstallSpec, targetVersion string) ([]byte
 
 // GenerateWithScriptType creates a shell script based on the specified script type
 func GenerateWithScriptType(installSpec *spec.InstallSpec, targetVersion, scriptType string) ([]byte, error) {
+	return GenerateWithBootstrap(installSpec, targetVersion, scriptType, nil)
+}
+
+// GenerateWithBootstrap creates a shell script that, when bootstrap is non-nil,
+// can hand installation off to a pinned binst release at runtime (two-stage mode)
+func GenerateWithBootstrap(installSpec *spec.InstallSpec, targetVersion, scriptType string, bootstrap *Bootstrap) ([]byte, error) {
+	return GenerateWithOptions(installSpec, targetVersion, scriptType, Options{Bootstrap: bootstrap})
+}
+
+// Options are the optional parts of a generated script
+type Options struct {
+	// Bootstrap enables the two-stage mode of installers when non-nil
+	Bootstrap *Bootstrap
+	// Features are the optional features to include (DefaultFeatures when nil)
+	Features *Features
+	// Channel marks the script as a release channel alias pinned to targetVersion
+	Channel *Channel
+	// ReleaseVars are the values of spec.ReleaseVariables of targetVersion's
+	// release, required when asset templates reference them
+	ReleaseVars map[string]string
+}
+
+// Channel describes a release channel alias script, such as install-stable.sh
+// pinned to the latest stable release when it was generated
+type Channel struct {
+	Name        string // e.g. stable or beta
+	RefreshedAt time.Time
+}
+
+// GenerateWithOptions creates a shell script with the given optional parts
+func GenerateWithOptions(installSpec *spec.InstallSpec, targetVersion, scriptType string, opts Options) ([]byte, error) {
+	bootstrap := opts.Bootstrap
+	features := DefaultFeatures()
+	if opts.Features != nil {
+		features = *opts.Features
+	}
 	if installSpec == nil {
 		return nil, errors.New("install spec cannot be nil")
 	}
@@ -58,6 +224,9 @@ func GenerateWithScriptType(installSpec *spec.InstallSpec, targetVersion, script
 	if scriptType == "" {
 		scriptType = "installer"
 	}
+	if bootstrap != nil && scriptType != "installer" {
+		return nil, fmt.Errorf("bootstrap is only supported for installer scripts")
+	}
 
 	// Apply spec defaults
 	installSpec.SetDefaults()
@@ -69,12 +238,88 @@ func GenerateWithScriptType(installSpec *spec.InstallSpec, targetVersion, script
 
 	// Prepare template data
 	data := templateData{
-		InstallSpec:    installSpec,
-		Shlib:          shlib,
-		HashFunctions:  hashFunc(installSpec),
-		ShellFunctions: shellFunctions,
-		TargetVersion:  targetVersion,
-		ScriptType:     scriptType,
+		InstallSpec:     installSpec,
+		Shlib:           shlib,
+		ShellFunctions:  shellFunctions,
+		TargetVersion:   targetVersion,
+		ScriptType:      scriptType,
+		Features:        features,
+		VerifyChecksums: verifiesChecksums(installSpec),
+		ProbeByteOrder:  probesByteOrder(installSpec),
+		DownloadFunc:    "github_http_download",
+		Header:          installSpec.GetScriptHeader().Lines(),
+	}
+	if opts.Channel != nil {
+		if targetVersion == "" {
+			return nil, fmt.Errorf("channel scripts must be pinned to a target version")
+		}
+		data.Channel = opts.Channel.Name
+		data.ChannelRefreshed = opts.Channel.RefreshedAt.UTC().Format(time.RFC3339)
+	}
+	if installSpec.UsesReleaseVariables() {
+		// Scripts cannot query the release metadata, so it is resolved when
+		// the script is generated
+		if targetVersion == "" {
+			return nil, fmt.Errorf("asset templates referencing release metadata (%s) need a script pinned to a target version", strings.Join(spec.ReleaseVariables, ", "))
+		}
+		if opts.ReleaseVars == nil {
+			return nil, fmt.Errorf("release metadata of %s is required by the asset templates", targetVersion)
+		}
+		for _, name := range spec.ReleaseVariables {
+			data.ReleaseVars = append(data.ReleaseVars, releaseVar{Name: name, Value: opts.ReleaseVars[name]})
+		}
+	}
+	if data.VerifyChecksums {
+		data.HashFunctions = hashFunc(installSpec) + "\n" + hashVerify
+	}
+	if data.VerifyChecksums && installSpec.GetChecksums().GetSignature() != nil {
+		data.SignatureFunctions = checksumSignature
+	}
+	if usesZstd(installSpec) {
+		data.ZstdFunctions = untarZstd
+	}
+	if usesExtension(installSpec, ".7z") {
+		data.SevenZipFunctions = untar7z
+	}
+	if usesOSVersion(installSpec) {
+		data.OSVersionFunctions = osVersion
+	}
+	if installSpec.GetSource() == spec.Github {
+		data.GitHubHost = installSpec.GetHost()
+		data.GitHubAPI = "https://api.github.com"
+		if data.GitHubHost != spec.DefaultGitHubHost {
+			data.GitHubAPI = "https://" + data.GitHubHost + "/api/v3"
+		}
+	}
+	if installSpec.GetSource() == spec.Gitlab {
+		data.GitLabFunctions = gitLab
+		data.GitLabHost = installSpec.GetHost()
+		data.GitLabProjectAPI = fmt.Sprintf("https://%s/api/v4/projects/%s", data.GitLabHost, url.PathEscape(installSpec.GetRepo()))
+		data.DownloadFunc = "gitlab_http_download"
+	}
+	if scriptType == "installer" && len(installSpec.BreakingChanges) > 0 {
+		data.BreakingFunctions = breakingChanges
+	}
+	if scriptType == "installer" {
+		for i := range installSpec.Wrappers {
+			w := &installSpec.Wrappers[i]
+			data.Wrappers = append(data.Wrappers, wrapperScript{Name: w.GetName(), Script: Wrapper(installSpec, w)})
+		}
+	}
+	data.Download = newDownloadFlags(installSpec.GetDownload())
+	if scriptType == "installer" && installSpec.GetUsagePing().GetEnabled() {
+		data.UsagePingURL = installSpec.GetUsagePing().GetURL()
+	}
+	if bootstrap != nil {
+		specYAML, err := bootstrapSpecYAML(installSpec)
+		if err != nil {
+			return nil, err
+		}
+		data.Bootstrap = bootstrap
+		data.BootstrapSpec = specYAML
+		if !data.VerifyChecksums || hashFunc(installSpec) != hashSHA256 {
+			data.BootstrapHash = sha256Function()
+		}
 	}
 
 	// Use unified template
@@ -93,25 +338,28 @@ func GenerateWithScriptType(installSpec *spec.InstallSpec, targetVersion, script
 	return buf.Bytes(), nil
 }
 
-// filterChecksumsForVersion filters embedded checksums to only include the specified version
-// This function modifies the original installSpec to filter checksums
+// filterChecksumsForVersion returns a copy of installSpec whose embedded
+// checksums only include the specified version, so that a spec can be reused to
+// generate scripts for several versions
 func filterChecksumsForVersion(installSpec *spec.InstallSpec, targetVersion string) *spec.InstallSpec {
 	if installSpec.Checksums == nil || installSpec.Checksums.EmbeddedChecksums == nil || len(installSpec.Checksums.EmbeddedChecksums) == 0 {
 		return installSpec
 	}
 
-	// Filter embedded checksums in place - only keep the target version
-	if checksums, exists := installSpec.Checksums.EmbeddedChecksums[targetVersion]; exists {
+	filtered := *installSpec
+	checksums := *installSpec.Checksums
+	filtered.Checksums = &checksums
+	if versionChecksums, exists := installSpec.Checksums.EmbeddedChecksums[targetVersion]; exists {
 		// Replace the entire map with only the target version
-		installSpec.Checksums.EmbeddedChecksums = map[string][]spec.EmbeddedChecksum{
-			targetVersion: checksums,
+		checksums.EmbeddedChecksums = map[string][]spec.EmbeddedChecksum{
+			targetVersion: versionChecksums,
 		}
 	} else {
 		// Target version not found, clear all embedded checksums
-		installSpec.Checksums.EmbeddedChecksums = make(map[string][]spec.EmbeddedChecksum)
+		checksums.EmbeddedChecksums = make(map[string][]spec.EmbeddedChecksum)
 	}
 
-	return installSpec
+	return &filtered
 }
 
 func hashFunc(installSpec *spec.InstallSpec) string {
@@ -132,6 +380,71 @@ func hashFunc(installSpec *spec.InstallSpec) string {
 	return hashSHA256
 }
 
+// verifiesChecksums reports whether the spec has a checksum file or embedded
+// checksums, or requires verification (so that a --target-version without
+// embedded checksums still refuses to install). Scripts for other specs cannot
+// verify anything and leave the verification code out.
+func verifiesChecksums(installSpec *spec.InstallSpec) bool {
+	if installSpec.Asset != nil {
+		for _, rule := range installSpec.Asset.Rules {
+			if spec.StringValue(rule.ChecksumTemplate) != "" {
+				return true
+			}
+		}
+	}
+	c := installSpec.Checksums
+	return c != nil && (c.GetTemplate() != "" || len(c.EmbeddedChecksums) > 0 || c.GetRequired())
+}
+
+// usesZstd reports whether any asset or extra file of the spec may be
+// zstd-compressed, as a tarball or a plain file
+func usesZstd(installSpec *spec.InstallSpec) bool {
+	return usesExtension(installSpec, ".zst", ".tzst")
+}
+
+// usesExtension reports whether the name of any asset or extra file of the
+// spec may contain one of exts
+func usesExtension(installSpec *spec.InstallSpec, exts ...string) bool {
+	if installSpec.Asset == nil {
+		return false
+	}
+	candidates := []*string{installSpec.Asset.Template, installSpec.Asset.DefaultExtension}
+	for _, rule := range installSpec.Asset.Rules {
+		candidates = append(candidates, rule.Template, rule.EXT)
+		for i := range rule.FallbackTemplates {
+			candidates = append(candidates, &rule.FallbackTemplates[i])
+		}
+	}
+	for _, extra := range installSpec.Asset.ExtraFiles {
+		candidates = append(candidates, extra.Template)
+	}
+	for _, c := range candidates {
+		v := spec.StringValue(c)
+		for _, ext := range exts {
+			if strings.Contains(v, ext) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// usesOSVersion reports whether any asset rule matches on when.os_version
+func usesOSVersion(installSpec *spec.InstallSpec) bool {
+	if installSpec.Asset == nil {
+		return false
+	}
+	for _, rule := range installSpec.Asset.Rules {
+		if rule.GetWhen().GetOSVersion() != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// simpleJSONKey matches JSON keys that can be matched literally in the shell fallback
+var simpleJSONKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
 // createFuncMap defines the functions available to the Go template.
 func createFuncMap() template.FuncMap {
 	return template.FuncMap{
@@ -150,9 +463,83 @@ func createFuncMap() template.FuncMap {
 			}
 			return false
 		},
+		"releaseFileURL": func(gitLabHost, filename string) string {
+			if gitLabHost != "" {
+				return "${GITLAB_DOWNLOAD}/${TAG}/downloads/" + filename
+			}
+			return "${GITHUB_DOWNLOAD}/${TAG}/" + filename
+		},
+		"versionSource": func(data templateData) string {
+			return string(data.GetVersion().GetSource())
+		},
+		"jsonPathJQ": func(path string) (string, error) {
+			p, err := jsonpath.Parse(path)
+			if err != nil {
+				return "", err
+			}
+			return p.JQ(), nil
+		},
+		"jsonPathKey": func(path string) (string, error) {
+			p, err := jsonpath.Parse(path)
+			if err != nil {
+				return "", err
+			}
+			// Only keys that are safe as an awk regular expression are used for the jq-less fallback
+			if !simpleJSONKey.MatchString(p.LastKey()) {
+				return "", nil
+			}
+			return p.LastKey(), nil
+		},
+		"hasFallbacks": func(rules []spec.RuleElement) bool {
+			for _, rule := range rules {
+				if len(rule.FallbackTemplates) > 0 {
+					return true
+				}
+			}
+			return false
+		},
+		"fallbacks": func(templates []string) string {
+			// Candidates are word-split by the shell, so each one is validated like deref
+			for _, t := range templates {
+				if err := spec.ValidateShellSafe(t, "fallback template"); err != nil {
+					panic(fmt.Sprintf("unsafe value in template: %v", err))
+				}
+			}
+			return strings.Join(templates, " ")
+		},
+		"unpackPatterns": func(patterns []string) string {
+			// Patterns are embedded unquoted in a case statement; spec.Validate
+			// limits them to characters without special meaning there
+			var alternatives []string
+			for _, p := range patterns {
+				p = strings.TrimSuffix(strings.TrimPrefix(p, "./"), "/")
+				alternatives = append(alternatives, p, p+"/*")
+			}
+			return strings.Join(alternatives, " | ")
+		},
+		"quote": func(s string) string {
+			// Single-quote free text; only the quote itself needs escaping
+			return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+		},
+		"completionDest": spec.CompletionDest,
+		"runtimeEnvValue": func(value string) string {
+			// ${INSTALL_DIR} and ${BINDIR} become the RUNTIME_ variables runners
+			// set from the binary path
+			return dirRefWord(value, "RUNTIME_")
+		},
+		"comment": func(s *string) string {
+			// Comment text only has to stay on one line; spec.Validate rejects control characters
+			return strings.Map(func(r rune) rune {
+				if unicode.IsControl(r) {
+					return ' '
+				}
+				return r
+			}, spec.StringValue(s))
+		},
 		"trimPrefix": func(s, prefix string) string {
 			return strings.TrimPrefix(s, prefix)
 		},
+		"trimSpace": strings.TrimSpace,
 		"deref": func(ptr interface{}) interface{} {
 			// Helper function to safely dereference pointers and validate shell safety
 			if ptr == nil {
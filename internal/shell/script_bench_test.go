@@ -0,0 +1,116 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// Performance budget for script generation. Registry-scale generation runs
+// GenerateWithVersion once per tool, so a large spec (1000 embedded checksums,
+// 50 rules) must stay well below the cost of the network calls around it.
+// The budget is enforced by TestGenerateWithVersionBudget when
+// BINSTALLER_PERF_GATE=1 (see `make bench-gate`).
+const (
+	budgetGenerateLargeSpec       = 50_000_000 // 50ms per generation, all versions embedded
+	budgetGenerateLargeSpecPinned = 20_000_000 // 20ms per generation, single target version
+)
+
+const (
+	benchVersions        = 50
+	benchFilesPerVersion = 20 // benchVersions * benchFilesPerVersion = 1000 checksums
+	benchRules           = 50
+	benchTargetVersion   = "v1.25.0"
+)
+
+// largeSpec returns a spec with 1000 embedded checksums and 50 rules.
+func largeSpec() *spec.InstallSpec {
+	oses := []string{"linux", "darwin", "windows", "freebsd", "netbsd"}
+	arches := []string{"amd64", "arm64", "386", "arm", "riscv64", "ppc64le", "s390x", "mips", "mipsle", "loong64"}
+
+	asset := spec.NewAsset("${NAME}_${VERSION}_${OS}_${ARCH}${EXT}").
+		WithDefaultExtension(".tar.gz").
+		WithBinary("bench-tool", "bench-tool")
+	for i := 0; i < benchRules; i++ {
+		rule := spec.NewRule(oses[i%len(oses)], arches[i/len(oses)%len(arches)]).
+			WithTemplate(fmt.Sprintf("${NAME}-${VERSION}-rule%d-${OS}-${ARCH}${EXT}", i))
+		if oses[i%len(oses)] == "windows" {
+			rule.WithExt(".zip")
+		}
+		asset.WithRules(rule)
+	}
+
+	checksums := spec.NewChecksums("${NAME}_${VERSION}_checksums.txt")
+	for v := 0; v < benchVersions; v++ {
+		version := fmt.Sprintf("v1.%d.0", v)
+		for f := 0; f < benchFilesPerVersion; f++ {
+			checksums.WithEmbeddedChecksum(version,
+				fmt.Sprintf("bench-tool_1.%d.0_%s_%s.tar.gz", v, oses[f%len(oses)], arches[f%len(arches)]),
+				fmt.Sprintf("%064x", v*benchFilesPerVersion+f))
+		}
+	}
+
+	return spec.NewInstallSpec("owner/bench-tool").
+		WithAsset(asset).
+		WithChecksums(checksums)
+}
+
+func BenchmarkGenerateWithVersion(b *testing.B) {
+	b.Run("all-versions", func(b *testing.B) {
+		s := largeSpec()
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := GenerateWithVersion(s, ""); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("target-version", func(b *testing.B) {
+		// GenerateWithVersion filters embedded checksums in place, so each
+		// iteration needs a fresh spec.
+		b.ReportAllocs()
+		for b.Loop() {
+			b.StopTimer()
+			s := largeSpec()
+			b.StartTimer()
+			if _, err := GenerateWithVersion(s, benchTargetVersion); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestGenerateWithVersionBudget(t *testing.T) {
+	if os.Getenv("BINSTALLER_PERF_GATE") != "1" {
+		t.Skip("set BINSTALLER_PERF_GATE=1 to enforce the generation performance budget")
+	}
+
+	tests := []struct {
+		name          string
+		targetVersion string
+		budget        int64
+	}{
+		{"all-versions", "", budgetGenerateLargeSpec},
+		{"target-version", benchTargetVersion, budgetGenerateLargeSpecPinned},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := testing.Benchmark(func(b *testing.B) {
+				for b.Loop() {
+					b.StopTimer()
+					s := largeSpec()
+					b.StartTimer()
+					if _, err := GenerateWithVersion(s, tt.targetVersion); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+			if got := result.NsPerOp(); got > tt.budget {
+				t.Errorf("GenerateWithVersion took %v per op, budget is %v", time.Duration(got), time.Duration(tt.budget))
+			}
+		})
+	}
+}
@@ -1,9 +1,20 @@
 package shell
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
 	"github.com/binary-install/binstaller/pkg/spec"
 )
 
@@ -304,7 +315,7 @@ func TestDryRunFlagParsing(t *testing.T) {
 				},
 			},
 			wantSubstrings: []string{
-				`while getopts "b:dqh?xn" arg`,
+				`while getopts "b:dqh?xnso:a:-:" arg`,
 				`n) DRY_RUN=1 ;;`,
 			},
 		},
@@ -529,6 +540,26 @@ func TestGenerateRunner(t *testing.T) {
 				`Installing binary to`,
 			},
 		},
+		{
+			name: "runner script exports runtime_env",
+			installSpec: &spec.InstallSpec{
+				Name: spec.StringPtr("test-tool"),
+				Repo: spec.StringPtr("owner/test-tool"),
+				Asset: &spec.AssetConfig{
+					Template:         spec.StringPtr("${NAME}-${VERSION}-${OS}_${ARCH}${EXT}"),
+					DefaultExtension: spec.StringPtr(".tar.gz"),
+				},
+				RuntimeEnv: map[string]string{
+					"TOOL_HOME":    "${INSTALL_DIR}/share/tool",
+					"TOOL_PLUGINS": "${BINDIR}/plugins:${INSTALL_DIR}/lib/it's",
+				},
+			},
+			wantSubstrings: []string{
+				`RUNTIME_BINDIR=$(dirname "${BINARY_PATH}")`,
+				`export TOOL_HOME="${RUNTIME_INSTALL_DIR}"'/share/tool'`,
+				`export TOOL_PLUGINS="${RUNTIME_BINDIR}"'/plugins:'"${RUNTIME_INSTALL_DIR}"'/lib/it'\''s'`,
+			},
+		},
 		{
 			name: "runner script with target version",
 			installSpec: &spec.InstallSpec{
@@ -563,9 +594,9 @@ func TestGenerateRunner(t *testing.T) {
 			},
 			targetVersion: "",
 			wantSubstrings: []string{
-				`Usage: $this [binary arguments]`,
+				`Usage: $this [--os os] [--arch arch] [binary arguments]`,
 				`This script downloads and runs test-tool directly`,
-				`ALL arguments are passed directly to the binary`,
+				`ALL other arguments are passed directly to the binary`,
 				`$this --help`,
 				`BINSTALLER_TARGET_TAG=...  Specify tag to run`,
 				`BINSTALLER_DEBUG=1         Enable debug logging`,
@@ -794,3 +825,1559 @@ func TestGenerateWithValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestGeneratePerRuleChecksumTemplate(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/test-tool").
+		WithAsset(spec.NewAsset("${NAME}_${VERSION}_${OS}_${ARCH}${EXT}").
+			WithDefaultExtension(".tar.gz").
+			WithRules(
+				spec.NewRule("linux", "").WithChecksumTemplate("checksums-linux.txt"),
+				spec.NewRule("darwin", "arm64").WithChecksumTemplate("checksums-darwin-${ARCH}.txt"),
+			)).
+		WithChecksums(spec.NewChecksums("checksums.txt"))
+
+	got, err := Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	gotStr := string(got)
+
+	for _, want := range []string{
+		`CHECKSUM_FILENAME="checksums.txt"`,
+		"if [ \"${UNAME_OS}\" = 'linux' ] && true\n  then\n    CHECKSUM_FILENAME=\"checksums-linux.txt\"\n  fi",
+		"if [ \"${UNAME_OS}\" = 'darwin' ] && [ \"${UNAME_ARCH}\" = 'arm64' ] && true\n  then\n    CHECKSUM_FILENAME=\"checksums-darwin-${ARCH}.txt\"\n  fi",
+	} {
+		if !strings.Contains(gotStr, want) {
+			t.Errorf("Generate() output missing %q", want)
+		}
+	}
+}
+
+func TestGenerateVersionSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		version *spec.Version
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "github releases",
+			version: nil,
+			want:    []string{`REALTAG=$(github_release "${REPO}" "${TAG}") && true`},
+			notWant: []string{"github_latest_tag", "http_json_version"},
+		},
+		{
+			name:    "github tags",
+			version: spec.NewVersion(spec.GithubTags),
+			want: []string{
+				"github_latest_tag() {",
+				`REALTAG=$(github_latest_tag "${REPO}") && true`,
+			},
+			notWant: []string{`REALTAG=$(github_release`, "http_json_version"},
+		},
+		{
+			name: "http json",
+			version: spec.NewVersion(spec.HTTPJSON).
+				WithURL("https://example.com/${NAME}/release.json").
+				WithJSONPath("$.channels['stable'].version"),
+			want: []string{
+				"http_json_version() {",
+				`VERSION_URL="https://example.com/${NAME}/release.json"`,
+				`REALTAG=$(http_json_version "${VERSION_URL}" '.channels.stable.version' 'version') && true`,
+			},
+			notWant: []string{"github_latest_tag", `REALTAG=$(github_release`},
+		},
+		{
+			name: "http json without simple key",
+			version: spec.NewVersion(spec.HTTPJSON).
+				WithURL("https://example.com/versions.json").
+				WithJSONPath("$.versions[0]"),
+			want: []string{`REALTAG=$(http_json_version "${VERSION_URL}" '.versions[0]' '') && true`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installSpec := spec.NewInstallSpec("owner/test-tool").
+				WithAsset(spec.NewAsset("${NAME}_${VERSION}_${OS}_${ARCH}.tar.gz")).
+				WithVersion(tt.version)
+
+			got, err := Generate(installSpec)
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(got), want) {
+					t.Errorf("Generate() output missing %q", want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(string(got), notWant) {
+					t.Errorf("Generate() output unexpectedly contains %q", notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestLatestTag(t *testing.T) {
+	got, err := Generate(spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}")).
+		WithVersion(spec.NewVersion(spec.GithubTags)))
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	script := string(got)
+	start := strings.Index(script, "latest_tag() {")
+	end := strings.Index(script[start:], "\n}\n")
+	if start < 0 || end < 0 {
+		t.Fatal("latest_tag function not found")
+	}
+	tests := []struct {
+		name  string
+		pages string
+		want  string
+	}{
+		{"minor above 9", `[{"name": "v0.9.0"}, {"name": "v0.10.0"}, {"name": "v0.1.0"}]`, "v0.10.0"},
+		{"pre-release", `[{"name": "v2.0.0-rc.1"}, {"name": "v2.0.0"}, {"name": "v1.9.9"}]`, "v2.0.0"},
+		{"pretty printed", "[\n  {\n    \"name\": \"1.2\"\n  },\n  {\n    \"name\": \"1.10\"\n  }\n]", "1.10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Serve the tags on page 1 only
+			copyTags := `tags_copy() { case "$1" in *"&page=1") printf '%s\n' "$TAGS_JSON" ;; esac; }`
+			c := exec.Command("sh", "-c", copyTags+"\n"+script[start:start+end+3]+`latest_tag tags_copy https://api.example.com/tags Accept:application/json`)
+			c.Env = append(os.Environ(), "TAGS_JSON="+tt.pages)
+			out, err := c.CombinedOutput()
+			if err != nil {
+				t.Fatalf("latest_tag failed: %v\n%s", err, out)
+			}
+			if got := strings.TrimSpace(string(out)); got != tt.want {
+				t.Errorf("latest_tag = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateWithBootstrap(t *testing.T) {
+	binstSpec := spec.NewInstallSpec("binary-install/binstaller").
+		WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}${EXT}").
+			WithDefaultExtension(".tar.gz").
+			WithOSNamingConvention(spec.Titlecase).
+			WithRules(
+				spec.NewRule("", "amd64").WithArch("x86_64"),
+				spec.NewRule("windows", "").WithExt(".zip"),
+			)).
+		WithChecksums(spec.NewChecksums("checksums.txt").
+			WithEmbeddedChecksum("v1.2.3", "binstaller_Linux_x86_64.tar.gz", "aaaa").
+			WithEmbeddedChecksum("v1.2.3", "binstaller_Windows_x86_64.zip", "bbbb")).
+		WithSupportedPlatforms("linux/amd64", "darwin/arm64", "windows/amd64")
+
+	bootstrap, err := NewBootstrap(binstSpec, "v1.2.3")
+	if err != nil {
+		t.Fatalf("NewBootstrap() error = %v", err)
+	}
+	if len(bootstrap.Assets) != 2 {
+		t.Fatalf("NewBootstrap() assets = %+v, want linux and windows only", bootstrap.Assets)
+	}
+
+	installSpec := spec.NewInstallSpec("owner/test-tool").
+		WithAsset(spec.NewAsset("${NAME}_${VERSION}_${OS}_${ARCH}.tar.gz")).
+		WithChecksums(spec.NewChecksums("checksums.txt").WithAlgorithm(spec.Sha512))
+	got, err := GenerateWithBootstrap(installSpec, "", "installer", bootstrap)
+	if err != nil {
+		t.Fatalf("GenerateWithBootstrap() error = %v", err)
+	}
+	script := string(got)
+	for _, want := range []string{
+		"BINSTALLER_BOOTSTRAP=1     Install via binst v1.2.3",
+		"hash_sha256() {",
+		"BINST_TAG='v1.2.3'",
+		"  linux/amd64)\n    BINST_ASSET='binstaller_Linux_x86_64.tar.gz'\n    BINST_HASH='aaaa'\n    BINST_PATH='binstaller'",
+		"  windows/amd64)\n    BINST_ASSET='binstaller_Windows_x86_64.zip'\n    BINST_HASH='bbbb'\n    BINST_PATH='binstaller.exe'",
+		"<<'BINSTALLER_SPEC_EOF'\n",
+		"repo: owner/test-tool\n",
+		`if [ "${BINSTALLER_BOOTSTRAP}" = "1" ]`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("GenerateWithBootstrap() output missing %q", want)
+		}
+	}
+
+	if _, err := GenerateWithBootstrap(installSpec, "", "runner", bootstrap); err == nil {
+		t.Error("GenerateWithBootstrap() expected error for runner scripts")
+	}
+	if _, err := NewBootstrap(binstSpec, "latest"); err == nil {
+		t.Error("NewBootstrap() expected error for unpinned version")
+	}
+	if _, err := NewBootstrap(binstSpec, "v9.9.9"); err == nil {
+		t.Error("NewBootstrap() expected error without embedded checksums")
+	}
+
+	// Binary paths follow the platform rules and expand their variables
+	binstSpec = spec.NewInstallSpec("binary-install/binstaller").
+		WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}${EXT}").
+			WithRules(spec.NewRule("darwin", "").WithExt(".tar.gz").WithBinary("binst", "${NAME}_${VERSION}/binst"))).
+		WithChecksums(spec.NewChecksums("checksums.txt").
+			WithEmbeddedChecksum("v1.2.3", "binstaller_linux_amd64", "aaaa").
+			WithEmbeddedChecksum("v1.2.3", "binstaller_darwin_arm64.tar.gz", "bbbb")).
+		WithSupportedPlatforms("linux/amd64", "darwin/arm64")
+	bootstrap, err = NewBootstrap(binstSpec, "v1.2.3")
+	if err != nil {
+		t.Fatalf("NewBootstrap() error = %v", err)
+	}
+	paths := map[string]string{}
+	for _, a := range bootstrap.Assets {
+		paths[a.OS+"/"+a.Arch] = a.BinaryPath
+	}
+	want := map[string]string{"linux/amd64": "binstaller_linux_amd64", "darwin/arm64": "binstaller_1.2.3/binst"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("NewBootstrap() binary paths = %v, want %v", paths, want)
+	}
+
+	// A spec without binaries is an error, not a panic
+	binstSpec = spec.NewInstallSpec("binary-install/binstaller").
+		WithAsset(spec.NewAsset("binst_${OS}_${ARCH}")).
+		WithChecksums(spec.NewChecksums("checksums.txt").WithEmbeddedChecksum("v1.2.3", "binst_linux_amd64", "aaaa")).
+		WithSupportedPlatforms("linux/amd64")
+	binstSpec.Name = spec.StringPtr("")
+	if _, err := NewBootstrap(binstSpec, "v1.2.3"); err == nil || !strings.Contains(err.Error(), "no binaries") {
+		t.Errorf("NewBootstrap() without binaries error = %v, want no binaries", err)
+	}
+}
+
+func TestGenerateZstd(t *testing.T) {
+	tests := []struct {
+		name        string
+		installSpec *spec.InstallSpec
+		wantZstd    bool
+	}{
+		{
+			name: "default extension",
+			installSpec: spec.NewInstallSpec("owner/tool").
+				WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}${EXT}").WithDefaultExtension(".tar.zst")),
+			wantZstd: true,
+		},
+		{
+			name: "rule extension",
+			installSpec: spec.NewInstallSpec("owner/tool").
+				WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}${EXT}").
+					WithDefaultExtension(".tar.gz").
+					WithRules(spec.NewRule("linux", "").WithExt(".tzst"))),
+			wantZstd: true,
+		},
+		{
+			name: "extra file",
+			installSpec: spec.NewInstallSpec("owner/tool").
+				WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}.tar.gz").
+					WithExtraFile("${NAME}-data.tar.zst", "")),
+			wantZstd: true,
+		},
+		{
+			name: "no zstd assets",
+			installSpec: spec.NewInstallSpec("owner/tool").
+				WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}${EXT}").WithDefaultExtension(".tar.gz")),
+			wantZstd: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Generate(tt.installSpec)
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			script := string(got)
+			for _, want := range []string{"untar_zstd() {", `*.tar.zst | *.tzst) (cd "${TMPDIR}" && untar_zstd`} {
+				if strings.Contains(script, want) != tt.wantZstd {
+					t.Errorf("script contains %q = %v, want %v", want, !tt.wantZstd, tt.wantZstd)
+				}
+			}
+		})
+	}
+}
+
+func TestGeneratePlainZstd(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}.zst"))
+	got, err := Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	script := string(got)
+	if !strings.Contains(script, `*.zst) (cd "${TMPDIR}" && unzstd "${ASSET_FILENAME}") ;;`) {
+		t.Error("script should decompress plain .zst assets with unzstd")
+	}
+
+	// Run unzstd on a compressed file
+	if _, err := exec.LookPath("zstd"); err != nil {
+		t.Skip("zstd not installed")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "tool"), []byte("binary"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command("zstd", "-q", "--rm", filepath.Join(dir, "tool")).CombinedOutput(); err != nil {
+		t.Fatalf("zstd failed: %v\n%s", err, out)
+	}
+	c := exec.Command("sh", "-c", shlib+"\n"+untarZstd+"\nunzstd tool.zst")
+	c.Dir = dir
+	if out, err := c.CombinedOutput(); err != nil {
+		t.Fatalf("unzstd failed: %v\n%s", err, out)
+	}
+	if content, err := os.ReadFile(filepath.Join(dir, "tool")); err != nil || string(content) != "binary" {
+		t.Errorf("decompressed content = %q, %v", content, err)
+	}
+}
+
+func TestUntarBzip2(t *testing.T) {
+	for _, tool := range []string{"tar", "bzip2"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s not installed", tool)
+		}
+	}
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "src", "tool_1.0.0"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"tool_1.0.0/tool", "plain"} {
+		if err := os.WriteFile(filepath.Join(dir, "src", name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	prepare := "tar -cjf tool.tbz2 -C src tool_1.0.0 && mv src/plain . && bzip2 plain"
+	if out, err := exec.Command("sh", "-c", "cd "+dir+" && "+prepare).CombinedOutput(); err != nil {
+		t.Fatalf("creating the bzip2 files failed: %v\n%s", err, out)
+	}
+
+	c := exec.Command("sh", "-c", shlib+"\n"+shellFunctions+"\nuntar tool.tbz2 1 && untar plain.bz2")
+	c.Dir = dir
+	if out, err := c.CombinedOutput(); err != nil {
+		t.Fatalf("untar failed: %v\n%s", err, out)
+	}
+	for name, want := range map[string]string{"tool": "tool_1.0.0/tool", "plain": "plain"} {
+		if content, err := os.ReadFile(filepath.Join(dir, name)); err != nil || string(content) != want {
+			t.Errorf("%s = %q, %v, want %q", name, content, err, want)
+		}
+	}
+}
+
+func TestGenerate7z(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}${EXT}").
+			WithDefaultExtension(".tar.gz").
+			WithRules(spec.NewRule("windows", "").WithExt(".7z")))
+	got, err := Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	script := string(got)
+	for _, want := range []string{"untar_7z() {", `*.7z) (cd "${TMPDIR}" && untar_7z "${ASSET_FILENAME}" "${STRIP_COMPONENTS}") ;;`} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script should contain %q", want)
+		}
+	}
+
+	got, err = Generate(spec.NewInstallSpec("owner/tool").WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}.tar.gz")))
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if strings.Contains(string(got), "untar_7z") {
+		t.Error("script without .7z assets should not contain untar_7z")
+	}
+}
+
+func TestUntar7z(t *testing.T) {
+	// A fake 7z extracting a fixed archive into the -o directory
+	bin := t.TempDir()
+	fake := `#!/bin/sh
+out=.
+for arg in "$@"; do
+  case "$arg" in -o*) out="${arg#-o}" ;; esac
+done
+mkdir -p "$out/tool_1.0.0/doc"
+printf binary >"$out/tool_1.0.0/tool"
+printf readme >"$out/tool_1.0.0/doc/README.md"
+`
+	if err := os.WriteFile(filepath.Join(bin, "7z"), []byte(fake), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		strip string
+		want  []string
+	}{
+		{strip: "0", want: []string{"tool_1.0.0/tool", "tool_1.0.0/doc/README.md"}},
+		{strip: "1", want: []string{"tool", "doc/README.md"}},
+	} {
+		t.Run("strip "+tt.strip, func(t *testing.T) {
+			dir := t.TempDir()
+			c := exec.Command("sh", "-c", shlib+"\n"+untar7z+"\nuntar_7z tool.7z "+tt.strip)
+			c.Dir = dir
+			c.Env = append(os.Environ(), "PATH="+bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+			if out, err := c.CombinedOutput(); err != nil {
+				t.Fatalf("untar_7z failed: %v\n%s", err, out)
+			}
+			for _, name := range tt.want {
+				if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+					t.Errorf("%s not extracted: %v", name, err)
+				}
+			}
+			if _, err := os.Stat(filepath.Join(dir, "tool_extracted")); !os.IsNotExist(err) {
+				t.Error("the temporary extract directory was not removed")
+			}
+		})
+	}
+
+	// Without a 7z command the installer explains how to get one
+	c := exec.Command("sh", "-c", shlib+"\n"+untar7z+"\nis_command() { return 1; }\nuntar_7z tool.7z 0")
+	c.Dir = t.TempDir()
+	out, err := c.CombinedOutput()
+	if err == nil || !strings.Contains(string(out), "Install 7-Zip") {
+		t.Errorf("untar_7z without 7z = %v\n%s, want an error with an install hint", err, out)
+	}
+}
+
+func TestGenerateChecksumSignature(t *testing.T) {
+	key := "RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3"
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}.tar.gz")).
+		WithChecksums(spec.NewChecksums("checksums.txt").WithSignature(spec.NewSignature(spec.Minisign, key)))
+	got, err := Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	script := string(got)
+	for _, want := range []string{
+		"minisign_verify() {",
+		`signature_filename="${CHECKSUM_FILENAME}.minisig"`,
+		key + "\nBINSTALLER_KEY_EOF",
+		`if ! minisign_verify "${TMPDIR}/${CHECKSUM_FILENAME}"`,
+		"\n    verify_checksum_signature\n    log_info \"Verifying checksum ...\"",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script should contain %q", want)
+		}
+	}
+
+	installSpec.Checksums.Signature = &spec.Signature{Keyring: spec.StringPtr("https://example.com/release.asc")}
+	got, err = Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	for _, want := range []string{
+		`signature_filename="${CHECKSUM_FILENAME}.sig"`,
+		`public_key_download "${TMPDIR}/checksum_signature.key" 'https://example.com/release.asc'`,
+		`if ! gpg_verify`,
+	} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("script should contain %q", want)
+		}
+	}
+
+	installSpec.Checksums.Signature = nil
+	got, err = Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if strings.Contains(string(got), "verify_checksum_signature") {
+		t.Error("script without checksums.signature should not verify signatures")
+	}
+}
+
+func TestGPGVerify(t *testing.T) {
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg not installed")
+	}
+	entity, err := openpgp.NewEntity("release", "", "release@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	var key bytes.Buffer
+	w, err := armor.Encode(&key, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := entity.Serialize(w); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	content := "abc123  tool_linux_amd64.tar.gz\n"
+	var sig bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&sig, entity, strings.NewReader(content), nil); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string][]byte{"key.asc": key.Bytes(), "checksums.txt": []byte(content), "checksums.txt.sig": sig.Bytes(), "evil.txt": []byte("evil  tool_linux_amd64.tar.gz\n")} {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run := func(file string) error {
+		c := exec.Command("sh", "-c", shlib+"\n"+checksumSignature+"\ngpg_verify "+file+" checksums.txt.sig key.asc")
+		c.Dir = dir
+		return c.Run()
+	}
+	if err := run("checksums.txt"); err != nil {
+		t.Errorf("gpg_verify of a signed file failed: %v", err)
+	}
+	if err := run("evil.txt"); err == nil {
+		t.Error("gpg_verify accepted a file the signature is not for")
+	}
+}
+
+func TestMinisignVerify(t *testing.T) {
+	// A fake minisign recording its arguments
+	bin := t.TempDir()
+	fake := "#!/bin/sh\necho \"$@\" >args\n"
+	if err := os.WriteFile(filepath.Join(bin, "minisign"), []byte(fake), 0755); err != nil {
+		t.Fatal(err)
+	}
+	key := "RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3"
+	for name, pub := range map[string]string{
+		"key line": key + "\n",
+		"pub file": "untrusted comment: minisign public key 37F0DFE1\n" + key + "\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "key.pub"), []byte(pub), 0644); err != nil {
+				t.Fatal(err)
+			}
+			c := exec.Command("sh", "-c", shlib+"\n"+checksumSignature+"\nminisign_verify checksums.txt checksums.txt.minisig key.pub")
+			c.Dir = dir
+			c.Env = append(os.Environ(), "PATH="+bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+			if out, err := c.CombinedOutput(); err != nil {
+				t.Fatalf("minisign_verify failed: %v\n%s", err, out)
+			}
+			args, _ := os.ReadFile(filepath.Join(dir, "args"))
+			if want := "-Vqm checksums.txt -x checksums.txt.minisig -P " + key + "\n"; string(args) != want {
+				t.Errorf("minisign arguments = %q, want %q", args, want)
+			}
+		})
+	}
+
+	// Without minisign the signature cannot be verified
+	c := exec.Command("sh", "-c", shlib+"\n"+checksumSignature+"\nis_command() { return 1; }\nminisign_verify checksums.txt checksums.txt.minisig key.pub")
+	c.Dir = t.TempDir()
+	if out, err := c.CombinedOutput(); err == nil || !strings.Contains(string(out), "minisign is required") {
+		t.Errorf("minisign_verify without minisign = %v\n%s, want an error", err, out)
+	}
+}
+
+func TestGenerateRequiredChecksums(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}.tar.gz")).
+		WithChecksums(spec.NewChecksums("checksums.txt"))
+	refusal := "refusing to install an unverified asset"
+
+	got, err := Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if strings.Contains(string(got), refusal) {
+		t.Error("script refuses unverified assets without checksums.required")
+	}
+
+	installSpec.Checksums.WithRequired(true)
+	got, err = Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !strings.Contains(string(got), refusal) || strings.Contains(string(got), "No checksum found, skipping verification.") {
+		t.Errorf("script with checksums.required still installs unverified assets:\n%s", got)
+	}
+}
+
+func TestGenerateFallbackTemplates(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}-${ARCH}-unknown-linux-musl${EXT}").
+			WithDefaultExtension(".tar.gz").
+			WithRules(spec.NewRule("linux", "").WithFallbackTemplates("${NAME}-${ARCH}-unknown-linux-gnu${EXT}")))
+	got, err := Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	script := string(got)
+	for _, want := range []string{
+		`ASSET_FALLBACKS="${NAME}-${ARCH}-unknown-linux-gnu${EXT}"`,
+		`for candidate in ${ASSET_FALLBACKS}; do`,
+		`log_crit "None of the asset candidates could be downloaded"`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script should contain %q", want)
+		}
+	}
+
+	// Specs without fallbacks keep the single download
+	got, err = Generate(spec.NewInstallSpec("owner/tool").WithAsset(spec.NewAsset("${NAME}${EXT}")))
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if strings.Contains(string(got), "ASSET_FALLBACKS") {
+		t.Error("script without fallback_templates should not contain ASSET_FALLBACKS")
+	}
+}
+
+func TestGenerateOSVersion(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}-${OS}-${ARCH}${EXT}").
+			WithRules(spec.NewRule("linux", "").WithOSVersion("alpine").WithTemplate("${NAME}-${ARCH}-musl${EXT}")))
+	got, err := Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	script := string(got)
+	for _, want := range []string{
+		"uname_os_version() {",
+		`OS_VERSION="$(uname_os_version "${OS}")"`,
+		`if [ "${UNAME_OS}" = 'linux' ] && os_version_matches 'alpine' && true`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script should contain %q", want)
+		}
+	}
+
+	got, err = Generate(spec.NewInstallSpec("owner/tool").WithAsset(spec.NewAsset("${NAME}${EXT}")))
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if strings.Contains(string(got), "OS_VERSION") {
+		t.Error("script without os_version rules should not detect the OS version")
+	}
+}
+
+func TestGenerateMetadataHeader(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").WithAsset(spec.NewAsset("${NAME}${EXT}"))
+	installSpec.Metadata = &spec.Metadata{
+		License:         spec.StringPtr("Apache-2.0 OR MIT"),
+		Homepage:        spec.StringPtr("https://example.com/tool?a=1&b=2"),
+		SecurityContact: spec.StringPtr("security@example.com"),
+	}
+	got, err := Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	want := `#!/bin/sh
+# Code generated by binstaller. DO NOT EDIT.
+# License: Apache-2.0 OR MIT
+# Homepage: https://example.com/tool?a=1&b=2
+# Security contact: security@example.com
+`
+	if !strings.HasPrefix(string(got), want) {
+		t.Errorf("script header = %q, want prefix %q", string(got)[:len(want)], want)
+	}
+
+	installSpec.Metadata.License = spec.StringPtr("MIT\nrm -rf /")
+	if _, err := Generate(installSpec); err == nil {
+		t.Error("Generate() should reject metadata with a newline")
+	}
+}
+
+func TestGenerateScriptHeader(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").WithAsset(spec.NewAsset("${NAME}${EXT}"))
+	installSpec.ScriptHeader = &spec.ScriptHeader{
+		Copyright:  spec.StringPtr("Copyright 2025 Example Corp."),
+		License:    spec.StringPtr("Apache-2.0"),
+		SupportURL: spec.StringPtr("https://example.com/support"),
+		Text:       spec.StringPtr("Internal distribution only.\n\nSee LEGAL.md.\n"),
+	}
+	want := `#!/bin/sh
+# Copyright 2025 Example Corp.
+# SPDX-License-Identifier: Apache-2.0
+# Support: https://example.com/support
+# Internal distribution only.
+#
+# See LEGAL.md.
+#
+# Code generated by binstaller. DO NOT EDIT.
+`
+	for _, scriptType := range []string{"installer", "runner"} {
+		got, err := GenerateWithScriptType(installSpec, "", scriptType)
+		if err != nil {
+			t.Fatalf("GenerateWithScriptType(%s) error = %v", scriptType, err)
+		}
+		if !strings.HasPrefix(string(got), want) {
+			t.Errorf("%s header = %q, want prefix %q", scriptType, string(got)[:len(want)], want)
+		}
+	}
+
+	installSpec.ScriptHeader.Text = spec.StringPtr("ok\r\nrm -rf /")
+	if _, err := Generate(installSpec); err == nil {
+		t.Error("Generate() should reject header text with a carriage return")
+	}
+}
+
+func TestGenerateWeakAlgorithm(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}${EXT}")).
+		WithChecksums(spec.NewChecksums("checksums.txt"))
+	got, err := Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if strings.Contains(string(got), "BINSTALLER_ALLOW_WEAK_HASH") {
+		t.Error("sha256 installer should not mention BINSTALLER_ALLOW_WEAK_HASH")
+	}
+
+	installSpec.Checksums.WithAlgorithm(spec.Md5)
+	got, err = Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	for _, want := range []string{
+		"BINSTALLER_ALLOW_WEAK_HASH=1  Accept md5 checksums as verification",
+		`log_err "md5 checksums are too weak to verify ${ASSET_FILENAME}; treating it as unverified`,
+	} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("md5 installer does not contain %q", want)
+		}
+	}
+
+	installSpec.Checksums.WithRequired(true)
+	got, err = Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !strings.Contains(string(got), `log_crit "md5 checksums are too weak to verify ${ASSET_FILENAME}; refusing to install an unverified asset (checksums.required)"`) {
+		t.Error("required md5 installer should refuse weakly verified assets")
+	}
+}
+
+func TestGenerateChecksumVerificationOnlyWhenConfigured(t *testing.T) {
+	verification := []string{"hash_verify() {", "hash_compute() {", "find_embedded_checksum() {", `EMBEDDED_HASH=$(find_embedded_checksum`}
+
+	installSpec := spec.NewInstallSpec("owner/tool").WithAsset(spec.NewAsset("${NAME}${EXT}"))
+	got, err := Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	for _, unwanted := range verification {
+		if strings.Contains(string(got), unwanted) {
+			t.Errorf("installer without checksums contains %q", unwanted)
+		}
+	}
+	if !strings.Contains(string(got), `log_warn "No checksum found, skipping verification."`) {
+		t.Error("installer without checksums should report skipping verification")
+	}
+
+	// Required verification must survive a target version without embedded checksums
+	installSpec.Checksums = (&spec.Checksums{}).
+		WithEmbeddedChecksum("v1.0.0", "tool", strings.Repeat("a", 64)).
+		WithRequired(true)
+	got, err = GenerateWithVersion(installSpec, "v2.0.0")
+	if err != nil {
+		t.Fatalf("GenerateWithVersion() error = %v", err)
+	}
+	if !strings.Contains(string(got), "refusing to install an unverified asset") {
+		t.Error("required installer for a version without checksums should refuse to install")
+	}
+
+	for name, checksums := range map[string]*spec.Checksums{
+		"checksum file":     spec.NewChecksums("checksums.txt"),
+		"embedded checksum": (&spec.Checksums{}).WithEmbeddedChecksum("v1.0.0", "tool", strings.Repeat("a", 64)),
+	} {
+		installSpec.Checksums = checksums
+		got, err := Generate(installSpec)
+		if err != nil {
+			t.Fatalf("%s: Generate() error = %v", name, err)
+		}
+		for _, want := range verification {
+			if !strings.Contains(string(got), want) {
+				t.Errorf("%s: installer does not contain %q", name, want)
+			}
+		}
+	}
+}
+
+func TestGenerateFeatures(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").WithAsset(spec.NewAsset("${NAME}${EXT}"))
+	installer, err := Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	features, err := DisableFeatures([]string{"dry-run", "quiet"})
+	if err != nil {
+		t.Fatalf("DisableFeatures() error = %v", err)
+	}
+
+	got, err := GenerateWithOptions(installSpec, "", "installer", Options{Features: &features})
+	if err != nil {
+		t.Fatalf("GenerateWithOptions() error = %v", err)
+	}
+	for _, unwanted := range []string{"DRY_RUN", "-n turns on dry run mode", "-q turns on quiet mode", "dry-run) arg", "quiet) arg"} {
+		if strings.Contains(string(got), unwanted) {
+			t.Errorf("installer without dry-run and quiet contains %q", unwanted)
+		}
+	}
+	for _, want := range []string{`getopts "b:dh?xso:a:-:" arg`, `install "${BINARY_PATH}" "${INSTALL_PATH}"`} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("installer does not contain %q", want)
+		}
+	}
+
+	got, err = GenerateWithOptions(installSpec, "", "runner", Options{Features: &features})
+	if err != nil {
+		t.Fatalf("GenerateWithOptions() error = %v", err)
+	}
+	if strings.Contains(string(got), "BINSTALLER_QUIET") {
+		t.Error("runner without quiet mentions BINSTALLER_QUIET")
+	}
+
+	for _, want := range []string{`getopts "b:dqh?xnso:a:-:" arg`, `dry-run) arg="n" ;;`} {
+		if !strings.Contains(string(installer), want) {
+			t.Errorf("default installer does not contain %q", want)
+		}
+	}
+	// -o and -a never had long forms in released scripts
+	if strings.Contains(string(installer), `os) arg="o"`) || strings.Contains(string(installer), `arch) arg="a"`) {
+		t.Error("default installer aliases --os or --arch")
+	}
+	// BINDIR from the environment is only honored on request
+	if strings.Contains(string(installer), "V0_BINDIR") {
+		t.Error("default installer reads BINDIR from the environment")
+	}
+	features, err = EnableFeatures(DefaultFeatures(), []string{"bindir-env"})
+	if err != nil {
+		t.Fatalf("EnableFeatures() error = %v", err)
+	}
+	got, err = GenerateWithOptions(installSpec, "", "installer", Options{Features: &features})
+	if err != nil {
+		t.Fatalf("GenerateWithOptions() error = %v", err)
+	}
+	if !strings.Contains(string(got), `log_warn "BINDIR is deprecated`) {
+		t.Error("installer with bindir-env does not read BINDIR")
+	}
+	if _, err := EnableFeatures(DefaultFeatures(), []string{"compat"}); err == nil {
+		t.Error("EnableFeatures() with a default feature succeeded, want error")
+	}
+	features, err = DisableFeatures([]string{"compat"})
+	if err != nil {
+		t.Fatalf("DisableFeatures() error = %v", err)
+	}
+	got, err = GenerateWithOptions(installSpec, "", "installer", Options{Features: &features})
+	if err != nil {
+		t.Fatalf("GenerateWithOptions() error = %v", err)
+	}
+	for _, unwanted := range []string{"V0_BINDIR", "--bindir", `bindir) arg="b"`, "is deprecated, use -$arg"} {
+		if strings.Contains(string(got), unwanted) {
+			t.Errorf("installer without compat contains %q", unwanted)
+		}
+	}
+
+	if !strings.Contains(string(installer), "\nresolve_bindir\n") {
+		t.Error("default installer does not resolve a read-only bindir")
+	}
+	features, err = DisableFeatures([]string{"fallback"})
+	if err != nil {
+		t.Fatalf("DisableFeatures() error = %v", err)
+	}
+	got, err = GenerateWithOptions(installSpec, "", "installer", Options{Features: &features})
+	if err != nil {
+		t.Fatalf("GenerateWithOptions() error = %v", err)
+	}
+	for _, unwanted := range []string{"resolve_bindir", "NO_FALLBACK", "-s fails"} {
+		if strings.Contains(string(got), unwanted) {
+			t.Errorf("installer without fallback contains %q", unwanted)
+		}
+	}
+
+	if _, err := DisableFeatures([]string{"completions"}); err == nil {
+		t.Error("DisableFeatures() with an unknown feature succeeded, want error")
+	}
+}
+
+func TestBindirReadOnly(t *testing.T) {
+	got, err := Generate(spec.NewInstallSpec("owner/tool").WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}")))
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	script := string(got)
+	start := strings.Index(script, "bindir_read_only() {")
+	end := strings.Index(script[start:], "\n}\n")
+	if start < 0 || end < 0 {
+		t.Fatal("bindir_read_only function not found")
+	}
+	// Pretend to run on an ostree-based system
+	marker := filepath.Join(t.TempDir(), "ostree-booted")
+	if err := os.WriteFile(marker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fn := strings.ReplaceAll(script[start:start+end+3], "/run/ostree-booted", marker)
+	for dir, want := range map[string]string{
+		"/usr/bin":       "/usr is read-only on an ostree-based immutable system",
+		"/usr/local/bin": "",
+	} {
+		out, err := exec.Command("sh", "-c", fn+`DRY_RUN=1; bindir_read_only "$1"`, "sh", dir).CombinedOutput()
+		if err != nil {
+			t.Fatalf("bindir_read_only %s failed: %v\n%s", dir, err, out)
+		}
+		if got := strings.TrimSpace(string(out)); got != want {
+			t.Errorf("bindir_read_only %s = %q, want %q", dir, got, want)
+		}
+	}
+	// A dry run does not probe the directory
+	dir := t.TempDir()
+	if out, err := exec.Command("sh", "-c", fn+`DRY_RUN=1; rm() { echo "probed $*"; }; bindir_read_only "$1"`, "sh", dir).CombinedOutput(); err != nil || len(out) > 0 {
+		t.Errorf("bindir_read_only in a dry run = %q, %v, want no probe", out, err)
+	}
+}
+
+func TestGenerateChannel(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").WithAsset(spec.NewAsset("${NAME}${EXT}"))
+	channel := &Channel{Name: "stable", RefreshedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+	got, err := GenerateWithOptions(installSpec, "v1.2.3", "installer", Options{Channel: channel})
+	if err != nil {
+		t.Fatalf("GenerateWithOptions() error = %v", err)
+	}
+	if !strings.Contains(string(got), "# Channel: stable (v1.2.3, refreshed 2026-01-02T03:04:05Z)\n") {
+		t.Error("channel script does not record its channel and refresh time")
+	}
+
+	if _, err := GenerateWithOptions(installSpec, "", "installer", Options{Channel: channel}); err == nil {
+		t.Error("GenerateWithOptions() of an unpinned channel script succeeded, want error")
+	}
+}
+
+func TestGenerateExtraFiles(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}${EXT}").
+			WithDefaultExtension(".tar.gz").
+			WithExtraFile("${NAME}-data.tar.gz", "/usr/share/tool").
+			WithExtraFile("${NAME}.1", "")).
+		WithChecksums(spec.NewChecksums("${NAME}_${VERSION}_checksums.txt"))
+	if _, err := Generate(installSpec); err == nil || !strings.Contains(err.Error(), "asset.extra_files[0].dest") {
+		t.Fatalf("Generate() error = %v, want dest validation error", err)
+	}
+
+	installSpec.Asset.ExtraFiles[0].Dest = spec.StringPtr("../share/tool")
+	got, err := Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	script := string(got)
+	for _, want := range []string{
+		`EXTRA_FILENAME_0="${NAME}-data.tar.gz"`,
+		`verify_extra_file "${EXTRA_FILENAME_1}"`,
+		`install_extra_file "${EXTRA_FILENAME_0}" "${BINDIR}/../share/tool"`,
+		`install_extra_file "${EXTRA_FILENAME_1}" "${BINDIR}/."`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script should contain %q", want)
+		}
+	}
+	if out, err := exec.Command("sh", "-n", "-c", script).CombinedOutput(); err != nil {
+		t.Errorf("sh -n failed: %v\n%s", err, out)
+	}
+
+	// Runners only run the binary and leave the extra files out
+	got, err = GenerateRunner(installSpec, "")
+	if err != nil {
+		t.Fatalf("GenerateRunner() error = %v", err)
+	}
+	if strings.Contains(string(got), "EXTRA_FILENAME") {
+		t.Error("runner script should not download extra files")
+	}
+}
+
+func TestGenerateCompletions(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}${EXT}").WithDefaultExtension(".tar.gz")).
+		WithCompletion("zsh", "completions/_tool").
+		WithCompletion("bash", "completions/tool.bash")
+	got, err := Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	script := string(got)
+	for _, want := range []string{
+		`while getopts "b:dqh?xnsco:a:-:" arg; do`,
+		`COMPLETIONS="${BINSTALLER_COMPLETIONS:-0}"`,
+		`COMPLETION_NAME="${BINARY_NAME}"`,
+		`install_completion bash "completions/tool.bash" "${install_prefix}/share/bash-completion/completions/${COMPLETION_NAME}"`,
+		`install_completion zsh "completions/_tool" "${install_prefix}/share/zsh/site-functions/_${COMPLETION_NAME}"`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script should contain %q", want)
+		}
+	}
+	if out, err := exec.Command("sh", "-n", "-c", script).CombinedOutput(); err != nil {
+		t.Errorf("sh -n failed: %v\n%s", err, out)
+	}
+
+	// Runners and specs without completions leave the option out
+	got, err = GenerateRunner(installSpec, "")
+	if err != nil {
+		t.Fatalf("GenerateRunner() error = %v", err)
+	}
+	if strings.Contains(string(got), "install_completion") {
+		t.Error("runner script should not install completions")
+	}
+	installSpec.Completions = nil
+	got, err = Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if strings.Contains(string(got), "COMPLETIONS") {
+		t.Error("script of a spec without completions should not have the -c option")
+	}
+}
+
+func TestGenerateReleaseVars(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${PUBLISHED_DATE}_${OS}_${ARCH}${EXT}").WithDefaultExtension(".tar.gz"))
+
+	if _, err := Generate(installSpec); err == nil || !strings.Contains(err.Error(), "pinned to a target version") {
+		t.Errorf("Generate() of an unpinned script error = %v, want pinned version error", err)
+	}
+	if _, err := GenerateWithVersion(installSpec, "nightly"); err == nil {
+		t.Error("GenerateWithVersion() without release metadata should fail")
+	}
+
+	got, err := GenerateWithOptions(installSpec, "nightly", "installer", Options{
+		ReleaseVars: spec.ReleaseVars("It's nightly", time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatalf("GenerateWithOptions() error = %v", err)
+	}
+	script := string(got)
+	for _, want := range []string{
+		`RELEASE_NAME='It'\''s nightly'`,
+		`PUBLISHED_AT='2024-05-01T12:00:00Z'`,
+		`PUBLISHED_DATE='2024-05-01'`,
+		`ASSET_FILENAME="${NAME}_${PUBLISHED_DATE}_${OS}_${ARCH}${EXT}"`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script should contain %q", want)
+		}
+	}
+	if out, err := exec.Command("sh", "-n", "-c", script).CombinedOutput(); err != nil {
+		t.Errorf("sh -n failed: %v\n%s", err, out)
+	}
+}
+
+func TestGenerateWrappers(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}${EXT}").WithDefaultExtension(".tar.gz"))
+	installSpec.Wrappers = []spec.WrapperElement{{
+		Name: spec.StringPtr("tool-local"),
+		Env:  map[string]string{"TOOL_HOME": "${INSTALL_DIR}/share/tool"},
+		Args: []string{"--quiet"},
+	}}
+	got, err := Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	script := string(got)
+	for _, want := range []string{
+		"install_wrapper 'tool-local' <<'BINSTALLER_WRAPPER'\n" + Wrapper(installSpec, &installSpec.Wrappers[0]) + "BINSTALLER_WRAPPER\n",
+		`export TOOL_HOME="${WRAPPER_INSTALL_DIR}"'/share/tool'`,
+		`exec "${WRAPPER_BINDIR}"/'tool' '--quiet' "$@"`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script should contain %q", want)
+		}
+	}
+	if out, err := exec.Command("sh", "-n", "-c", script).CombinedOutput(); err != nil {
+		t.Errorf("sh -n failed: %v\n%s", err, out)
+	}
+
+	// Runners run the binary itself
+	got, err = GenerateRunner(installSpec, "")
+	if err != nil {
+		t.Fatalf("GenerateRunner() error = %v", err)
+	}
+	if strings.Contains(string(got), "install_wrapper") {
+		t.Error("runner script should not install wrappers")
+	}
+}
+
+func TestGenerateUnpackFilters(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}${EXT}").WithDefaultExtension(".tar.gz")).
+		WithUnpack(spec.NewUnpack(1).WithInclude("bin/*", "share/").WithExclude("share/doc", "*.md"))
+	got, err := Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	script := string(got)
+	for _, want := range []string{
+		`case "${path}" in share/doc | share/doc/* | *.md | *.md/*) continue ;; esac`,
+		`case "${path}" in bin/* | bin/*/* | share | share/*) ;; *) continue ;; esac`,
+		`(cd "${TMPDIR}" && untar_filtered "${ASSET_FILENAME}" "${STRIP_COMPONENTS}")`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script should contain %q", want)
+		}
+	}
+
+	// Run the generated function against an archive
+	start := strings.Index(script, "untar_filtered() {")
+	end := strings.Index(script[start:], "\n}\n")
+	if start < 0 || end < 0 {
+		t.Fatal("untar_filtered function not found")
+	}
+	dir := t.TempDir()
+	f, err := os.Create(filepath.Join(dir, "tool.tar.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, name := range []string{"tool-1.0/bin/tool", "tool-1.0/README.md", "tool-1.0/share/man/tool.1", "tool-1.0/share/doc/guide.html", "tool-1.0/lib/libtool.so"} {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(name))}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(name))
+	}
+	tw.Close()
+	gz.Close()
+	f.Close()
+
+	c := exec.Command("sh", "-c", shlib+"\n"+shellFunctions+"\n"+script[start:start+end+3]+`untar_filtered tool.tar.gz 1`)
+	c.Dir = dir
+	if out, err := c.CombinedOutput(); err != nil {
+		t.Fatalf("untar_filtered failed: %v\n%s", err, out)
+	}
+	for path, want := range map[string]bool{
+		"bin/tool":             true,
+		"share/man/tool.1":     true,
+		"README.md":            false,
+		"share/doc/guide.html": false,
+		"lib/libtool.so":       false,
+	} {
+		if _, err := os.Stat(filepath.Join(dir, path)); (err == nil) != want {
+			t.Errorf("%s extracted = %v, want %v", path, err == nil, want)
+		}
+	}
+
+	// Specs without filters extract everything with untar
+	got, err = Generate(spec.NewInstallSpec("owner/tool").WithAsset(spec.NewAsset("${NAME}${EXT}")))
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if strings.Contains(string(got), "untar_filtered") {
+		t.Error("script without unpack filters should not contain untar_filtered")
+	}
+}
+
+func TestGenerateAttestation(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}")).
+		WithAttestation(spec.NewAttestation("owner/tool/.github/workflows/release.yml"))
+	got, err := Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	script := string(got)
+	if !strings.Contains(script, `verify_attestation "${ASSET_FILENAME}"`) {
+		t.Error("script should verify the attestation of the asset")
+	}
+	if out, err := exec.Command("sh", "-n", "-c", script).CombinedOutput(); err != nil {
+		t.Fatalf("generated script is not valid sh: %v\n%s", err, out)
+	}
+
+	// Run the generated function with a fake gh recording its arguments
+	start := strings.Index(script, "verify_attestation() {")
+	end := strings.Index(script[start:], "\n}\n")
+	if start < 0 || end < 0 {
+		t.Fatal("verify_attestation function not found")
+	}
+	dir := t.TempDir()
+	writeGh := func(status string) {
+		gh := "#!/bin/sh\necho \"$@\" >'" + filepath.Join(dir, "args") + "'\nexit " + status + "\n"
+		if err := os.WriteFile(filepath.Join(dir, "gh"), []byte(gh), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run := func(path string) error {
+		c := exec.Command("sh", "-c", shlib+"\n"+script[start:start+end+3]+`REPO=owner/tool; TMPDIR=/tmp; verify_attestation tool_linux_amd64`)
+		c.Env = append(os.Environ(), "PATH="+path)
+		return c.Run()
+	}
+
+	writeGh("0")
+	if err := run(dir); err != nil {
+		t.Fatalf("verify_attestation failed: %v", err)
+	}
+	args, _ := os.ReadFile(filepath.Join(dir, "args"))
+	want := "attestation verify /tmp/tool_linux_amd64 --repo owner/tool --predicate-type https://slsa.dev/provenance/v1 --signer-workflow owner/tool/.github/workflows/release.yml\n"
+	if string(args) != want {
+		t.Errorf("gh arguments = %q, want %q", args, want)
+	}
+
+	writeGh("1")
+	if err := run(dir); err == nil {
+		t.Error("verify_attestation should fail when gh attestation verify fails")
+	}
+
+	// Installs without gh skip the verification
+	if err := run(t.TempDir()); err != nil {
+		t.Errorf("verify_attestation without gh failed: %v", err)
+	}
+}
+
+func TestGenerateUsagePing(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}")).
+		WithUsagePing(spec.NewUsagePing("https://counter.example.com/tool"))
+	got, err := Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	script := string(got)
+	for _, want := range []string{
+		"# Usage ping: after a successful install",
+		"# without identifiers (no query parameters added, no token, User-Agent\n# \"binstaller\") to https://counter.example.com/tool\n",
+		"# BINSTALLER_NO_USAGE_PING=1 or DO_NOT_TRACK=1 to skip it.",
+		"\n  usage_ping\n}\n",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script should contain %q", want)
+		}
+	}
+	if out, err := exec.Command("sh", "-n", "-c", script).CombinedOutput(); err != nil {
+		t.Fatalf("generated script is not valid sh: %v\n%s", err, out)
+	}
+
+	// Run the generated function with a fake curl recording its arguments
+	start := strings.Index(script, "usage_ping() {")
+	end := strings.Index(script[start:], "\n}\n")
+	if start < 0 || end < 0 {
+		t.Fatal("usage_ping function not found")
+	}
+	dir := t.TempDir()
+	curl := "#!/bin/sh\necho \"$@\" >'" + filepath.Join(dir, "args") + "'\n"
+	if err := os.WriteFile(filepath.Join(dir, "curl"), []byte(curl), 0755); err != nil {
+		t.Fatal(err)
+	}
+	run := func(env ...string) string {
+		os.Remove(filepath.Join(dir, "args"))
+		c := exec.Command("sh", "-c", shlib+"\n"+script[start:start+end+3]+`DRY_RUN=0; usage_ping`)
+		c.Env = append([]string{"PATH=" + dir}, env...)
+		if out, err := c.CombinedOutput(); err != nil {
+			t.Fatalf("usage_ping failed: %v\n%s", err, out)
+		}
+		args, _ := os.ReadFile(filepath.Join(dir, "args"))
+		return string(args)
+	}
+	if got, want := run(), "-fsS -o /dev/null -A binstaller --max-time 5 https://counter.example.com/tool\n"; got != want {
+		t.Errorf("curl arguments = %q, want %q", got, want)
+	}
+	for _, env := range []string{"DO_NOT_TRACK=1", "BINSTALLER_NO_USAGE_PING=1"} {
+		if got := run(env); got != "" {
+			t.Errorf("usage ping sent with %s: %q", env, got)
+		}
+	}
+
+	// Runner scripts never ping
+	got, err = GenerateRunner(installSpec, "")
+	if err != nil {
+		t.Fatalf("GenerateRunner() error = %v", err)
+	}
+	if strings.Contains(string(got), "usage_ping") || strings.Contains(string(got), "counter.example.com") {
+		t.Error("runner script should not send the usage ping")
+	}
+}
+
+func TestGenerateDownloadSettings(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}"))
+	got, err := Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if strings.Contains(string(got), "# Retries and timeouts of the download settings") {
+		t.Error("script without download settings should not wrap curl")
+	}
+
+	installSpec.WithDownload((&spec.Download{}).WithRetries(5).WithRetryDelay(3).WithTimeout(120))
+	got, err = Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	script := string(got)
+	if out, err := exec.Command("sh", "-n", "-c", script).CombinedOutput(); err != nil {
+		t.Fatalf("generated script is not valid sh: %v\n%s", err, out)
+	}
+	start := strings.Index(script, "# Retries and timeouts of the download settings")
+	end := strings.Index(script[max(start, 0):], "\n  fi\nfi\n")
+	if start < 0 || end < 0 {
+		t.Fatal("curl and wget wrappers not found")
+	}
+	wrappers := script[start : start+end+9]
+
+	// Run the wrappers with fake tools recording their arguments
+	dir := t.TempDir()
+	fake := func(name, help string) {
+		tool := "#!/bin/sh\n[ \"$1\" = --help ] && { echo '" + help + "'; exit 0; }\necho \"$@\" >'" + filepath.Join(dir, "args") + "'\n"
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(tool), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run := func(call string) string {
+		os.Remove(filepath.Join(dir, "args"))
+		c := exec.Command("sh", "-c", shlib+"\n"+wrappers+call)
+		c.Env = []string{"PATH=" + dir + ":/usr/bin:/bin"}
+		if out, err := c.CombinedOutput(); err != nil {
+			t.Fatalf("%s failed: %v\n%s", call, err, out)
+		}
+		args, _ := os.ReadFile(filepath.Join(dir, "args"))
+		return string(args)
+	}
+	fake("curl", "")
+	if got, want := run("curl -fsSL -o out https://example.com/a"), "--retry 5 --retry-delay 3 --max-time 120 -fsSL -o out https://example.com/a\n"; got != want {
+		t.Errorf("curl arguments = %q, want %q", got, want)
+	}
+	fake("wget", "  --waitretry=SECONDS")
+	if got, want := run("wget -q -O out https://example.com/a"), "--tries=6 --waitretry=3 --timeout=120 -q -O out https://example.com/a\n"; got != want {
+		t.Errorf("wget arguments = %q, want %q", got, want)
+	}
+	fake("wget", "BusyBox")
+	if got, want := run("wget -q -O out https://example.com/a"), "-T 120 -q -O out https://example.com/a\n"; got != want {
+		t.Errorf("BusyBox wget arguments = %q, want %q", got, want)
+	}
+}
+
+func TestGenerateGitLab(t *testing.T) {
+	installSpec := spec.NewInstallSpec("group/sub/tool").
+		WithSource(spec.Gitlab, "gitlab.example.com").
+		WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}"))
+	got, err := Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	script := string(got)
+	for _, want := range []string{
+		`GITLAB_DOWNLOAD="https://gitlab.example.com/${REPO}/-/releases"`,
+		`ASSET_URL="${GITLAB_DOWNLOAD}/${TAG}/downloads/${ASSET_FILENAME}"`,
+		`gitlab_http_download "${TMPDIR}/${ASSET_FILENAME}" "${ASSET_URL}"`,
+		`REALTAG=$(gitlab_release "https://gitlab.example.com/api/v4/projects/group%2Fsub%2Ftool")`,
+		"https://gitlab.example.com/group/sub/tool/-/releases",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script should contain %q", want)
+		}
+	}
+	if strings.Contains(script, "GITHUB_DOWNLOAD") {
+		t.Error("script should not download from GitHub")
+	}
+	if out, err := exec.Command("sh", "-n", "-c", script).CombinedOutput(); err != nil {
+		t.Fatalf("generated script is not valid sh: %v\n%s", err, out)
+	}
+
+	got, err = Generate(installSpec.WithVersion(spec.NewVersion(spec.GithubTags)))
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !strings.Contains(string(got), `REALTAG=$(gitlab_latest_tag "https://gitlab.example.com/api/v4/projects/group%2Fsub%2Ftool")`) {
+		t.Error("script should look up the latest GitLab tag")
+	}
+}
+
+func TestGenerateGitHubEnterprise(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithSource(spec.Github, "github.example.com").
+		WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}"))
+	got, err := Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	script := string(got)
+	for _, want := range []string{
+		`GITHUB_DOWNLOAD="https://github.example.com/${REPO}/releases/download"`,
+		`REALTAG=$(github_release "${REPO}" "${TAG}" "https://github.example.com")`,
+		"https://github.example.com/owner/tool/releases",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script should contain %q", want)
+		}
+	}
+	if out, err := exec.Command("sh", "-n", "-c", script).CombinedOutput(); err != nil {
+		t.Fatalf("generated script is not valid sh: %v\n%s", err, out)
+	}
+
+	got, err = Generate(installSpec.WithVersion(spec.NewVersion(spec.GithubTags)))
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !strings.Contains(string(got), `"https://github.example.com/api/v3/repos/$1/tags"`) {
+		t.Error("script should look up the latest tag with the GitHub Enterprise Server API")
+	}
+}
+
+func TestGenerateBreakingChanges(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}")).
+		WithBreakingChange("v2.0.0", "See the tool's v2 migration guide").
+		WithBreakingChange("3.0.0", "")
+	got, err := Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	script := string(got)
+	for _, want := range []string{
+		`breaking_change "${installed}" 'v2.0.0' 'See the tool'\''s v2 migration guide'`,
+		`breaking_change "${installed}" '3.0.0' ''`,
+		`warn_breaking_changes "${BINDIR}/${BINARY_NAME}"`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script should contain %q", want)
+		}
+	}
+	if out, err := exec.Command("sh", "-n", "-c", script).CombinedOutput(); err != nil {
+		t.Fatalf("generated script is not valid sh: %v\n%s", err, out)
+	}
+
+	// Run the generated function against a fake installed binary
+	start := strings.Index(script, "warn_breaking_changes() {")
+	end := strings.Index(script[start:], "\n}\n")
+	if start < 0 || end < 0 {
+		t.Fatal("warn_breaking_changes function not found")
+	}
+	dir := t.TempDir()
+	run := func(installed, version string) string {
+		bin := filepath.Join(dir, "tool")
+		os.Remove(bin)
+		if installed != "" {
+			if err := os.WriteFile(bin, []byte("#!/bin/sh\necho \"tool version "+installed+" (abc123)\"\n"), 0755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		c := exec.Command("sh", "-c", shlib+"\n"+breakingChanges+"\n"+script[start:start+end+3]+
+			`REPO=owner/tool; NAME=tool; VERSION=`+version+`; log_prefix() { echo "${REPO}"; }; log_set_priority 6; warn_breaking_changes "$1"`, "sh", bin)
+		out, err := c.CombinedOutput()
+		if err != nil {
+			t.Fatalf("warn_breaking_changes failed: %v\n%s", err, out)
+		}
+		return string(out)
+	}
+	if got := run("", "2.0.0"); got != "" {
+		t.Errorf("fresh install warned: %q", got)
+	}
+	if got := run("2.0.0", "2.1.0"); got != "" {
+		t.Errorf("upgrade within v2 warned: %q", got)
+	}
+	if got := run("3.1.0", "1.0.0"); got != "" {
+		t.Errorf("downgrade warned: %q", got)
+	}
+	out := run("1.9", "3.0.0")
+	for _, want := range []string{
+		"owner/tool warning Upgrading tool from 1.9 to 3.0.0 crosses a breaking change in 2.0.0\n",
+		"owner/tool warning Migration notes: See the tool's v2 migration guide\n",
+		"owner/tool warning Upgrading tool from 1.9 to 3.0.0 crosses a breaking change in 3.0.0\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output should contain %q, got:\n%s", want, out)
+		}
+	}
+
+	// Runner scripts never install over a previous version
+	got, err = GenerateRunner(installSpec, "")
+	if err != nil {
+		t.Fatalf("GenerateRunner() error = %v", err)
+	}
+	if strings.Contains(string(got), "breaking_change") {
+		t.Error("runner script should not check breaking changes")
+	}
+}
+
+func TestVersionLt(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"1.9.0", "v2.0.0", true},
+		{"v2.0.0", "2.0.0", false},
+		{"2.10.0", "2.9.0", false},
+		{"2024.05.01", "2024.10.01", true},
+		{"2024.05.01.2", "2024.05.01.10", true},
+		{"2024-06-01", "2024.05.01", false},
+		{"build-999", "build-1000", true},
+		{"b5123", "b5123", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"<"+tt.b, func(t *testing.T) {
+			err := exec.Command("sh", "-c", breakingChanges+"\nversion_lt \"$1\" \"$2\"", "sh", tt.a, tt.b).Run()
+			if got := err == nil; got != tt.want {
+				t.Errorf("version_lt %s %s = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateLogging(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").WithAsset(spec.NewAsset("${NAME}-${OS}-${ARCH}"))
+	features, err := DisableFeatures([]string{"compat"})
+	if err != nil {
+		t.Fatalf("DisableFeatures() error = %v", err)
+	}
+	got, err := GenerateWithOptions(installSpec, "", "installer", Options{Features: &features})
+	if err != nil {
+		t.Fatalf("GenerateWithOptions() error = %v", err)
+	}
+	script := string(got)
+	function := func(name string) string {
+		start := strings.Index(script, name+"() {")
+		end := strings.Index(script[start:], "\n}\n")
+		if start < 0 || end < 0 {
+			t.Fatalf("script has no %s function", name)
+		}
+		return script[start : start+end+3]
+	}
+	run := func(body string, args ...string) string {
+		c := exec.Command("sh", append([]string{"-c", shlib + "\n" + function("parse_args") + function("log_resolved") +
+			`REPO=owner/tool; log_prefix() { echo "${REPO}"; }; usage() { exit 2; }` + "\n" + body, "sh"}, args...)...)
+		out, err := c.CombinedOutput()
+		if err != nil {
+			t.Fatalf("sh failed: %v\n%s", err, out)
+		}
+		return string(out)
+	}
+
+	// Text logs are unchanged, and the resolved variables are logged with -x only
+	resolve := `OS=linux ARCH=amd64 TAG=v1.0.0 VERSION=1.0.0 ASSET_URL=https://example.com/tool-linux-amd64; log_info 'Downloading'; log_resolved embedded`
+	if got, want := run(`parse_args "$@"; `+resolve, "-b", "/tmp/bin"), "owner/tool info Downloading\n"; got != want {
+		t.Errorf("logs = %q, want %q", got, want)
+	}
+	out := run(`parse_args "$@" 2>/dev/null; `+resolve, "-x")
+	if want := "owner/tool resolved os=linux arch=amd64 tag=v1.0.0 version=1.0.0 asset_url=https://example.com/tool-linux-amd64 checksum=embedded\n"; !strings.Contains(out, want) {
+		t.Errorf("logs with -x = %q, want a line %q", out, want)
+	}
+
+	// --log-json logs one JSON object per line
+	out = run(`parse_args "$@"; log_set_step download; log_warn 'say "hi" to \ you'; `+resolve, "--log-json")
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("--log-json logs = %q, want 3 lines", out)
+	}
+	var objects []map[string]string
+	for _, line := range lines {
+		var object map[string]string
+		if err := json.Unmarshal([]byte(line), &object); err != nil {
+			t.Fatalf("log line %q is not a JSON object: %v", line, err)
+		}
+		objects = append(objects, object)
+	}
+	if want := map[string]string{"level": "warning", "repo": "owner/tool", "step": "download", "msg": `say "hi" to \ you`}; !reflect.DeepEqual(objects[0], want) {
+		t.Errorf("log object = %v, want %v", objects[0], want)
+	}
+	if objects[2]["step"] != "resolve" || objects[2]["checksum"] != "embedded" || objects[2]["asset_url"] != "https://example.com/tool-linux-amd64" {
+		t.Errorf("resolved object = %v", objects[2])
+	}
+
+	// Other long options are rejected without compat
+	c := exec.Command("sh", "-c", shlib+"\n"+function("parse_args")+`log_prefix() { echo tool; }; usage() { exit 2; }; parse_args "$@"`, "sh", "--bindir=/tmp")
+	if err := c.Run(); err == nil {
+		t.Error("parse_args accepted --bindir without compat")
+	}
+
+	runner, err := GenerateRunner(installSpec, "")
+	if err != nil {
+		t.Fatalf("GenerateRunner() error = %v", err)
+	}
+	for _, want := range []string{`if [ "${BINSTALLER_LOG_JSON}" = "1" ]`, `if [ "${BINSTALLER_TRACE}" = "1" ]`} {
+		if !strings.Contains(string(runner), want) {
+			t.Errorf("runner does not contain %q", want)
+		}
+	}
+}
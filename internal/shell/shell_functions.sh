@@ -48,9 +48,10 @@ untar() {
   case "${tarball}" in
   *.tar.gz | *.tgz) tar --no-same-owner -xzf "${tarball}" --strip-components "${strip_components}" ;;
   *.tar.xz) tar --no-same-owner -xJf "${tarball}" --strip-components "${strip_components}" ;;
-  *.tar.bz2) tar --no-same-owner -xjf "${tarball}" --strip-components "${strip_components}" ;;
+  *.tar.bz2 | *.tbz2 | *.tbz) tar --no-same-owner -xjf "${tarball}" --strip-components "${strip_components}" ;;
   *.tar) tar --no-same-owner -xf "${tarball}" --strip-components "${strip_components}" ;;
   *.gz) gunzip "${tarball}" ;;
+  *.bz2) bunzip2 "${tarball}" ;;
   *.zip)
     # unzip doesn't have a standard --strip-components
     # Workaround: extract to a subdir and move contents up if stripping
@@ -81,113 +82,90 @@ untar() {
   esac
 }
 
-
-
-hash_verify() {
-  TARGET_PATH=$1
-  SUMFILE=$2
-  if [ -z "${SUMFILE}" ]; then
-    log_err "hash_verify checksum file not specified in arg2"
-    return 1
-  fi
-  got=$(hash_compute "$TARGET_PATH")
-  if [ -z "${got}" ]; then
-    log_err "failed to calculate hash: ${TARGET_PATH}"
-    return 1
-  fi
-
-  BASENAME=${TARGET_PATH##*/}
-
-  # Check for line matches in checksum file
-  # Format: "<hash>  <filename>" or "<hash> *<filename>"
-  # Filename may include path prefix (e.g., "deployment/m2/file.tar.gz")
-  while IFS= read -r line || [ -n "$line" ]; do
-    # Normalize tabs to spaces
-    line=$(echo "$line" | tr '\t' ' ')
-
-    # Remove trailing spaces for hash-only line check
-    line_trimmed=$(echo "$line" | sed 's/[[:space:]]*$//')
-
-    # Check for hash-only line (no filename) - early return
-    if [ "$line_trimmed" = "$got" ]; then
-      return 0
-    fi
-
-    # Extract hash and filename parts
-    # First field is the hash, rest is filename (which may contain spaces)
-    line_hash=$(echo "$line" | cut -d' ' -f1)
-
-    # Skip if hash doesn't match
-    if [ "$line_hash" != "$got" ]; then
-      continue
-    fi
-
-    # Hash matches, now check filename
-    # Remove the hash part from the beginning of the line
-    line_rest="${line#"$got"}"
-    # Remove leading spaces
-    while [ "${line_rest#[ ]}" != "$line_rest" ]; do
-      line_rest="${line_rest#[ ]}"
-    done
-
-    # Remove leading asterisk if present (binary mode indicator)
-    if [ "${line_rest#\*}" != "$line_rest" ]; then
-      line_rest="${line_rest#\*}"
-    fi
-
-    # Extract just the filename without any path
-    line_filename="${line_rest##*/}"
-
-    # Check if the filename matches
-    if [ "$line_filename" = "$BASENAME" ]; then
-      return 0
-    fi
-  done < "$SUMFILE"
-
-  log_err "hash_verify checksum for '$TARGET_PATH' did not verify"
-  log_err "  Expected hash: ${got}"
-  log_err "  Checksum file content:"
-  cat "$SUMFILE" >&2
-  return 1
-}
-
 # GitHub HTTP download functions with GITHUB_TOKEN support
+#
+# With a token, redirects are followed by hand so that the Authorization header
+# is only sent to the host of the URL. GitHub redirects release assets to signed
+# objects.githubusercontent.com URLs, which reject requests carrying another
+# Authorization header, and wget forwards --header to every redirect host.
+url_host() {
+  echo "$1" | sed -e 's#^[A-Za-z][A-Za-z0-9+.-]*://##' -e 's#[/?#].*##' -e 's#.*@##'
+}
+github_redirect_limit=10
 github_http_download_curl() {
   local_file=$1
   source_url=$2
   header=$3
   if [ -n "$GITHUB_TOKEN" ]; then
     log_debug "Using GITHUB_TOKEN for authentication"
-    if [ -z "$header" ]; then
-      curl -fsSL -H "Authorization: Bearer $GITHUB_TOKEN" -o "$local_file" "$source_url"
-    else
-      curl -fsSL -H "Authorization: Bearer $GITHUB_TOKEN" -H "$header" -o "$local_file" "$source_url"
-    fi
+    auth_host=$(url_host "$source_url")
+    redirects=0
+    while [ "$(url_host "$source_url")" = "$auth_host" ]; do
+      if [ -z "$header" ]; then
+        redirect_url=$(curl -fsS -H "Authorization: Bearer $GITHUB_TOKEN" -o "$local_file" -w '%{redirect_url}' "$source_url") || return 1
+      else
+        redirect_url=$(curl -fsS -H "Authorization: Bearer $GITHUB_TOKEN" -H "$header" -o "$local_file" -w '%{redirect_url}' "$source_url") || return 1
+      fi
+      [ -z "$redirect_url" ] && return 0
+      redirects=$((redirects + 1))
+      if [ "$redirects" -gt "$github_redirect_limit" ]; then
+        log_err "github_http_download stopped after ${github_redirect_limit} redirects"
+        return 1
+      fi
+      source_url=$redirect_url
+    done
+    log_debug "Following redirect to $(url_host "$source_url") without GITHUB_TOKEN"
+  fi
+  if [ -z "$header" ]; then
+    curl -fsSL -o "$local_file" "$source_url"
   else
-    if [ -z "$header" ]; then
-      curl -fsSL -o "$local_file" "$source_url"
-    else
-      curl -fsSL -H "$header" -o "$local_file" "$source_url"
-    fi
+    curl -fsSL -H "$header" -o "$local_file" "$source_url"
   fi
 }
 github_http_download_wget() {
   local_file=$1
   source_url=$2
   header=$3
-  if [ -n "$GITHUB_TOKEN" ]; then
+  if [ -n "$GITHUB_TOKEN" ] && ! wget --help 2>&1 | grep -q -- --max-redirect; then
+    # BusyBox wget follows every redirect with the same headers, which would
+    # send GITHUB_TOKEN to the host a release asset redirects to
+    log_warn "wget cannot stop at redirects; downloading without GITHUB_TOKEN (install curl to authenticate)"
+  elif [ -n "$GITHUB_TOKEN" ]; then
     log_debug "Using GITHUB_TOKEN for authentication"
-    if [ -z "$header" ]; then
-      wget -q --header "Authorization: Bearer $GITHUB_TOKEN" -O "$local_file" "$source_url"
-    else
-      wget -q --header "Authorization: Bearer $GITHUB_TOKEN" --header "$header" -O "$local_file" "$source_url"
-    fi
+    auth_host=$(url_host "$source_url")
+    redirects=0
+    headers_file=$(mktemp)
+    while [ "$(url_host "$source_url")" = "$auth_host" ]; do
+      # wget fails on a redirect it may not follow; the Location header tells
+      # a redirect apart from an error
+      if [ -z "$header" ]; then
+        wget -q -S --max-redirect=0 --header "Authorization: Bearer $GITHUB_TOKEN" -O "$local_file" "$source_url" 2>"$headers_file" && status=0 || status=$?
+      else
+        wget -q -S --max-redirect=0 --header "Authorization: Bearer $GITHUB_TOKEN" --header "$header" -O "$local_file" "$source_url" 2>"$headers_file" && status=0 || status=$?
+      fi
+      redirect_url=$(tr -d '\r' <"$headers_file" | sed -n 's/^ *[Ll][Oo][Cc][Aa][Tt][Ii][Oo][Nn]: *//p' | tail -n 1)
+      if [ -z "$redirect_url" ]; then
+        rm -f "$headers_file"
+        return "$status"
+      fi
+      case "$redirect_url" in
+        /*) redirect_url="${source_url%%://*}://$(url_host "$source_url")${redirect_url}" ;;
+      esac
+      redirects=$((redirects + 1))
+      if [ "$redirects" -gt "$github_redirect_limit" ]; then
+        rm -f "$headers_file"
+        log_err "github_http_download stopped after ${github_redirect_limit} redirects"
+        return 1
+      fi
+      source_url=$redirect_url
+    done
+    rm -f "$headers_file"
+    log_debug "Following redirect to $(url_host "$source_url") without GITHUB_TOKEN"
+  fi
+  if [ -z "$header" ]; then
+    wget -q -O "$local_file" "$source_url"
   else
-    if [ -z "$header" ]; then
-      wget -q -O "$local_file" "$source_url"
-    else
-      wget -q --header "$header" -O "$local_file" "$source_url"
-    fi
+    wget -q --header "$header" -O "$local_file" "$source_url"
   fi
 }
 github_http_download() {
@@ -212,8 +190,9 @@ github_http_copy() {
 github_release() {
   owner_repo=$1
   version=$2
+  server_url=${3:-https://github.com}
   test -z "$version" && version="latest"
-  giturl="https://github.com/${owner_repo}/releases/${version}"
+  giturl="${server_url}/${owner_repo}/releases/${version}"
   json=$(github_http_copy "$giturl" "Accept:application/json")
   test -z "$json" && return 1
   version=$(echo "$json" | tr -s '\n' ' ' | sed 's/.*"tag_name":"//' | sed 's/".*//')
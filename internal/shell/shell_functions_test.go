@@ -0,0 +1,119 @@
+package shell
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestGitHubHTTPDownloadRedirects checks that the download functions send
+// GITHUB_TOKEN to the host of the URL, including across same-host redirects,
+// but never to the host a release asset redirects to
+func TestGitHubHTTPDownloadRedirects(t *testing.T) {
+	const token = "ghp_secret"
+	assets := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			http.Error(w, "Only one auth mechanism allowed", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte("asset"))
+	}))
+	defer assets.Close()
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		switch r.URL.Path {
+		case "/old-owner/tool/releases/download/v1.0.0/tool.tar.gz":
+			// A renamed repository redirects within the host
+			http.Redirect(w, r, "/owner/tool/releases/download/v1.0.0/tool.tar.gz", http.StatusMovedPermanently)
+		case "/owner/tool/releases/download/v1.0.0/tool.tar.gz":
+			http.Redirect(w, r, assets.URL+"/signed?X-Amz-Signature=abc", http.StatusFound)
+		default:
+			w.Write([]byte("direct"))
+		}
+	}))
+	defer origin.Close()
+
+	for _, client := range []string{"curl", "wget"} {
+		if _, err := exec.LookPath(client); err != nil {
+			t.Logf("%s not found, skipping", client)
+			continue
+		}
+		for _, tt := range []struct {
+			path string
+			want string
+		}{
+			{path: "/old-owner/tool/releases/download/v1.0.0/tool.tar.gz", want: "asset"},
+			{path: "/owner/tool/releases/download/v1.0.0/tool.tar.gz", want: "asset"},
+			{path: "/owner/tool/releases/download/v1.0.0/checksums.txt", want: "direct"},
+		} {
+			t.Run(client+tt.path, func(t *testing.T) {
+				out := filepath.Join(t.TempDir(), "out")
+				script := shlib + "\n" + shellFunctions + "\ngithub_http_download_" + client + ` "$1" "$2"`
+				c := exec.Command("sh", "-c", script, "sh", out, origin.URL+tt.path)
+				c.Env = append(os.Environ(), "GITHUB_TOKEN="+token)
+				if output, err := c.CombinedOutput(); err != nil {
+					t.Fatalf("github_http_download_%s failed: %v\n%s", client, err, output)
+				}
+				got, err := os.ReadFile(out)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if strings.TrimSpace(string(got)) != tt.want {
+					t.Errorf("downloaded %q, want %q", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+// TestGitHubHTTPDownloadBusyBoxWget checks that a wget without --max-redirect,
+// such as BusyBox wget, still downloads but never sends GITHUB_TOKEN
+func TestGitHubHTTPDownloadBusyBoxWget(t *testing.T) {
+	dir := t.TempDir()
+	argsLog := filepath.Join(dir, "args")
+	stub := `#!/bin/sh
+for arg in "$@"; do
+  case "$arg" in
+  --help) echo "Usage: wget [-cqS] [--spider] [-O FILE] [--header STR] [-T SEC] URL..."; exit 1 ;;
+  --max-redirect*) echo "wget: unrecognized option '$arg'" >&2; exit 1 ;;
+  esac
+done
+echo "$*" >>"` + argsLog + `"
+while [ $# -gt 1 ]; do
+  if [ "$1" = "-O" ]; then echo asset >"$2"; fi
+  shift
+done
+`
+	if err := os.WriteFile(filepath.Join(dir, "wget"), []byte(stub), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(dir, "out")
+	script := shlib + "\n" + shellFunctions + "\ngithub_http_download_wget \"$1\" \"$2\""
+	c := exec.Command("sh", "-c", script, "sh", out, "https://github.com/owner/tool/releases/download/v1.0.0/tool.tar.gz")
+	c.Env = append(os.Environ(), "GITHUB_TOKEN=ghp_secret", "PATH="+dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	output, err := c.CombinedOutput()
+	if err != nil {
+		t.Fatalf("github_http_download_wget failed: %v\n%s", err, output)
+	}
+	if got, _ := os.ReadFile(out); strings.TrimSpace(string(got)) != "asset" {
+		t.Errorf("downloaded %q, want %q", got, "asset")
+	}
+	args, err := os.ReadFile(argsLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(args), "ghp_secret") {
+		t.Errorf("wget was given GITHUB_TOKEN: %s", args)
+	}
+	if !strings.Contains(string(output), "without GITHUB_TOKEN") {
+		t.Errorf("output lacks the warning:\n%s", output)
+	}
+}
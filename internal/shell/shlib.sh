@@ -36,21 +36,45 @@ log_tag() {
     *) echo "$1" ;;
   esac
 }
+_logjson=0
+_logstep="init"
+log_set_json() {
+  _logjson="$1"
+}
+log_set_step() {
+  _logstep="$1"
+}
+log_json_escape() {
+  printf '%s' "$1" | tr '\n\t' '  ' | tr -d '\000-\037' | sed -e 's/\\/\\\\/g' -e 's/"/\\"/g'
+}
+log_line() {
+  _loglevel="$(log_tag "$1")"
+  shift
+  if [ "$_logjson" = 1 ]; then
+    printf '{"level":"%s","repo":"%s","step":"%s","msg":"%s"}\n' "$_loglevel" "$(log_json_escape "$(log_prefix)")" "$_logstep" "$(log_json_escape "$*")" 1>&2
+  else
+    echoerr "$(log_prefix)" "$_loglevel" "$@"
+  fi
+}
 log_debug() {
   log_priority 7 || return 0
-  echoerr "$(log_prefix)" "$(log_tag 7)" "$@"
+  log_line 7 "$@"
 }
 log_info() {
   log_priority 6 || return 0
-  echoerr "$(log_prefix)" "$(log_tag 6)" "$@"
+  log_line 6 "$@"
+}
+log_warn() {
+  log_priority 4 || return 0
+  log_line 4 "$@"
 }
 log_err() {
   log_priority 3 || return 0
-  echoerr "$(log_prefix)" "$(log_tag 3)" "$@"
+  log_line 3 "$@"
 }
 log_crit() {
   log_priority 2 || return 0
-  echoerr "$(log_prefix)" "$(log_tag 2)" "$@"
+  log_line 2 "$@"
 }
 uname_os() {
   os=$(uname -s | tr '[:upper:]' '[:lower:]')
@@ -77,9 +101,12 @@ uname_arch() {
     i686) arch="386" ;;
     i386) arch="386" ;;
     aarch64) arch="arm64" ;;
+    aarch64_be) arch="arm64be" ;;
+    armv*b) arch="armbe" ;;
     armv5*) arch="armv5" ;;
     armv6*) arch="armv6" ;;
     armv7*) arch="armv7" ;;
+    loongarch64) arch="loong64" ;;
   esac
   echo "${arch}"
 }
@@ -119,7 +146,13 @@ uname_arch_check() {
     mips64) return 0 ;;
     mips64le) return 0 ;;
     s390x) return 0 ;;
+    riscv64) return 0 ;;
+    loong64) return 0 ;;
     amd64p32) return 0 ;;
+    armbe | arm64be)
+      log_crit "big-endian ARM ('$(uname -m)') has no release binaries: releases target little-endian ARM"
+      return 1
+      ;;
   esac
   log_crit "uname_arch_check '$(uname -m)' got converted to '$arch' which is not a GOARCH value.  Please file bug report at https://github.com/client9/shlib"
   return 1
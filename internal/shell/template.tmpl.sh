@@ -1,8 +1,37 @@
 #!/bin/sh
+{{- range .Header }}
+#{{ if . }} {{ . }}{{ end }}
+{{- end }}
+{{- if .Header }}
+#
+{{- end }}
 # Code generated by binstaller. DO NOT EDIT.
+{{- if .Channel }}
+# Channel: {{ .Channel }} ({{ .TargetVersion }}, refreshed {{ .ChannelRefreshed }})
+# Regenerate this script to follow newer {{ .Channel }} releases.
+{{- end }}
+{{- with .Metadata }}
+{{- if .License }}
+# License: {{ comment .License }}
+{{- end }}
+{{- if .Homepage }}
+# Homepage: {{ comment .Homepage }}
+{{- end }}
+{{- if .SecurityContact }}
+# Security contact: {{ comment .SecurityContact }}
+{{- end }}
+{{- end }}
 {{- if eq .ScriptType "runner" }}
 # This script runs {{ deref .Name }} directly without installing
 {{- end }}
+{{- if .UsagePingURL }}
+#
+# Usage ping: after a successful install, this script sends one GET request
+# without identifiers (no query parameters added, no token, User-Agent
+# "binstaller") to {{ .UsagePingURL }}
+# so the maintainers can count installs. Failures are ignored. Set
+# BINSTALLER_NO_USAGE_PING=1 or DO_NOT_TRACK=1 to skip it.
+{{- end }}
 #
 set -e
 
@@ -12,23 +41,64 @@ usage() {
   cat <<EOF
 $this: download ${NAME} from ${REPO}
 
-Usage: $this [-b bindir] [-d] [-q] [-n]{{- if not .TargetVersion }} [tag]{{- end }}
+Usage: $this [-b bindir] [-d]{{- if .Features.Quiet }} [-q]{{- end }}{{- if .Features.DryRun }} [-n]{{- end }}{{- if .Features.Fallback }} [-s]{{- end }}{{- if .Completions.Files }} [-c]{{- end }} [-o os] [-a arch]{{- if not .TargetVersion }} [tag]{{- end }}
   -b sets bindir or installation directory, Defaults to {{ deref .DefaultBinDir }}
   -d turns on debug logging
+  {{- if .Features.Quiet }}
   -q turns on quiet mode (errors only)
+  {{- end }}
+  {{- if .Features.DryRun }}
   -n turns on dry run mode
+  {{- end }}
+  {{- if .Features.Fallback }}
+  -s fails instead of installing into ~/.local/bin when bindir is read-only
+  {{- end }}
+  {{- if .Completions.Files }}
+  -c installs the shell completions into the share directory next to bindir
+  {{- end }}
+  -o sets the OS instead of detecting it (e.g. linux)
+  -a sets the architecture instead of detecting it (e.g. arm64)
+  -x traces the script and logs the resolved platform, tag, asset URL and
+     checksum source in one line
+  --log-json logs one JSON object per line, e.g. for CI log scraping
+  {{- if .Features.Compat }}
+  The deprecated --bindir, --debug,{{ if .Features.Quiet }} --quiet,{{ end }}{{ if .Features.DryRun }} --dry-run,{{ end }} and --help
+  options still work.
+  {{- end }}
+  {{- if .Features.BinDirEnv }}
+  The deprecated BINDIR variable still sets bindir.
+  {{- end }}
   {{- if .TargetVersion }}
    This installer is configured for {{ .TargetVersion }} only.
   {{- else }}
    [tag] is a tag from
-   https://github.com/{{ deref .Repo }}/releases
+   {{- if .GitLabHost }}
+   https://{{ .GitLabHost }}/{{ deref .Repo }}/-/releases
+   {{- else }}
+   https://{{ .GitHubHost }}/{{ deref .Repo }}/releases
+   {{- end }}
    If tag is missing, then {{ deref .DefaultVersion | default "the latest" }} will be used.
   {{- end }}
 
 Environment variables:
   BINSTALLER_NO_PROGRESS=1   Disable progress indicators
-  BINSTALLER_OS=...          Override OS detection
-  BINSTALLER_ARCH=...        Override architecture detection
+  BINSTALLER_OS=...          Override OS detection (-o takes precedence)
+  BINSTALLER_ARCH=...        Override architecture detection (-a takes precedence)
+  {{- if .Features.Fallback }}
+  BINSTALLER_NO_FALLBACK=1   Fail instead of falling back to ~/.local/bin (like -s)
+  {{- end }}
+  {{- if .Completions.Files }}
+  BINSTALLER_COMPLETIONS=1   Install the shell completions (like -c)
+  {{- end }}
+  {{- if .OSVersionFunctions }}
+  BINSTALLER_OS_VERSION=...  Override OS version detection (e.g. alpine-3.20, macos-15)
+  {{- end }}
+  {{- if .Checksums.GetAlgorithm.IsWeak }}
+  BINSTALLER_ALLOW_WEAK_HASH=1  Accept {{ .Checksums.GetAlgorithm }} checksums as verification
+  {{- end }}
+  {{- if .Bootstrap }}
+  BINSTALLER_BOOTSTRAP=1     Install via binst {{ .Bootstrap.Tag }} (signature verification, receipts)
+  {{- end }}
 
  Generated by binstaller
   https://github.com/binary-install/binstaller
@@ -43,17 +113,20 @@ usage() {
   cat <<EOF
 $this: download and run ${NAME} from ${REPO}
 
-Usage: $this [binary arguments]
+Usage: $this [--os os] [--arch arch] [binary arguments]
   {{- if .TargetVersion }}
    This script is configured for {{ .TargetVersion }} only.
   {{- end }}
 
    This script downloads and runs {{ deref .Name }} directly.
-   ALL arguments are passed directly to the binary.
+   Leading --os and --arch options set the platform instead of detecting it,
+   and a leading -- ends them. ALL other arguments are passed directly to the binary.
 
    Examples:
      $this --help
      $this --version
+     $this --arch amd64 --version
+     $this -- --os
      {{- if not .TargetVersion }}
      BINSTALLER_TARGET_TAG=v1.2.3 $this --help
      {{- end }}
@@ -64,10 +137,20 @@ Environment variables:
   {{- end }}
   BINSTALLER_SHOW_HELP=1     Show this help message
   BINSTALLER_DEBUG=1         Enable debug logging
+  {{- if .Features.Quiet }}
   BINSTALLER_QUIET=1         Enable quiet mode (errors only)
+  {{- end }}
+  BINSTALLER_TRACE=1         Trace the script and log the resolved variables
+  BINSTALLER_LOG_JSON=1      Log one JSON object per line
   BINSTALLER_NO_PROGRESS=1   Disable progress indicators
-  BINSTALLER_OS=...          Override OS detection
-  BINSTALLER_ARCH=...        Override architecture detection
+  BINSTALLER_OS=...          Override OS detection (--os takes precedence)
+  BINSTALLER_ARCH=...        Override architecture detection (--arch takes precedence)
+  {{- if .OSVersionFunctions }}
+  BINSTALLER_OS_VERSION=...  Override OS version detection (e.g. alpine-3.20, macos-15)
+  {{- end }}
+  {{- if .Checksums.GetAlgorithm.IsWeak }}
+  BINSTALLER_ALLOW_WEAK_HASH=1  Accept {{ .Checksums.GetAlgorithm }} checksums as verification
+  {{- end }}
 
  Generated by binstaller
   https://github.com/binary-install/binstaller
@@ -83,10 +166,230 @@ EOF
 {{- end }}
 
 {{ .Shlib }}
+{{- if .VerifyChecksums }}
 
 {{ .HashFunctions }}
+{{- end }}
 
 {{ .ShellFunctions }}
+{{- with .Download }}
+# Retries and timeouts of the download settings of the spec
+if is_command curl; then
+  curl() {
+    command curl {{ .Curl }} "$@"
+  }
+fi
+if is_command wget; then
+  if wget --help 2>&1 | grep -q -- --waitretry; then
+    wget() {
+      command wget {{ .Wget }} "$@"
+    }
+  {{- if .BusyBoxWget }}
+  else
+    wget() {
+      command wget {{ .BusyBoxWget }} "$@"
+    }
+  {{- end }}
+  fi
+fi
+{{- end }}
+{{- if .ZstdFunctions }}
+{{ .ZstdFunctions }}
+{{- end }}
+{{- if .SevenZipFunctions }}
+{{ .SevenZipFunctions }}
+{{- end }}
+{{- if .SignatureFunctions }}
+{{ .SignatureFunctions }}
+
+# Verify the signature of the checksum file downloaded into TMPDIR before any
+# checksum in it is trusted
+verify_checksum_signature() {
+  signature_filename="{{ .Checksums.Signature.GetTemplate }}"
+  log_info "Verifying {{ .Checksums.Signature.GetFormat }} signature of ${CHECKSUM_FILENAME}"
+  {{ .DownloadFunc }} "${TMPDIR}/${signature_filename}" "{{ releaseFileURL .GitLabHost "${signature_filename}" }}"
+  {{- if .Checksums.Signature.GetKeyring }}
+  public_key_download "${TMPDIR}/checksum_signature.key" '{{ .Checksums.Signature.GetKeyring }}'
+  {{- else }}
+  cat >"${TMPDIR}/checksum_signature.key" <<'BINSTALLER_KEY_EOF'
+{{ trimSpace .Checksums.Signature.GetKey }}
+BINSTALLER_KEY_EOF
+  {{- end }}
+  if ! {{ .Checksums.Signature.GetFormat }}_verify "${TMPDIR}/${CHECKSUM_FILENAME}" "${TMPDIR}/${signature_filename}" "${TMPDIR}/checksum_signature.key"; then
+    log_crit "Signature verification failed for ${CHECKSUM_FILENAME}"
+    return 1
+  fi
+}
+{{ end }}
+{{- if .OSVersionFunctions }}
+{{ .OSVersionFunctions }}
+{{- end }}
+{{- if .GitLabFunctions }}
+{{ .GitLabFunctions }}
+{{- end }}
+{{- if .BreakingFunctions }}
+{{ .BreakingFunctions }}
+
+# Warn when upgrading the binary at $1 crosses a breaking change of the spec
+warn_breaking_changes() {
+  installed=$(installed_version "$1")
+  [ -n "${installed}" ] || return 0
+  {{- range .BreakingChanges }}
+  breaking_change "${installed}" '{{ .GetVersion }}' {{ quote .GetNotes }}
+  {{- end }}
+}
+{{- end }}
+{{- template "version_source_functions" . }}
+{{- if .Bootstrap }}
+{{- template "bootstrap_functions" . }}
+{{- end }}
+
+{{- define "bootstrap_functions" }}
+{{- if .BootstrapHash }}
+{{ .BootstrapHash }}
+{{- end }}
+
+# Two-stage install: download a pinned binst and hand the install spec to it
+bootstrap_binst() {
+  BINST_REPO='{{ .Bootstrap.Repo }}'
+  BINST_TAG='{{ .Bootstrap.Tag }}'
+  case "${OS}/${ARCH}" in
+  {{- range .Bootstrap.Assets }}
+  {{ .OS }}/{{ .Arch }})
+    BINST_ASSET='{{ .Filename }}'
+    BINST_HASH='{{ .Hash }}'
+    BINST_PATH='{{ .BinaryPath }}'
+    ;;
+  {{- end }}
+  *)
+    log_crit "binst ${BINST_TAG} is not available for ${OS}/${ARCH}"
+    return 1
+    ;;
+  esac
+  BINST_URL="https://github.com/${BINST_REPO}/releases/download/${BINST_TAG}/${BINST_ASSET}"
+
+  TMPDIR=$(mktemp -d)
+  trap cleanup EXIT HUP INT TERM
+  log_info "Bootstrapping binst ${BINST_TAG} from ${BINST_URL}"
+  github_http_download "${TMPDIR}/${BINST_ASSET}" "${BINST_URL}"
+  got=$(hash_sha256 "${TMPDIR}/${BINST_ASSET}")
+  if [ "$got" != "$BINST_HASH" ]; then
+    log_crit "Checksum verification failed for ${BINST_ASSET}"
+    log_crit "Expected: ${BINST_HASH}"
+    log_crit "Got: ${got}"
+    return 1
+  fi
+  log_info "Checksum verification successful"
+
+  case "${BINST_ASSET}" in
+  *.tar.gz | *.tgz | *.tar.xz | *.tar.bz2 | *.tbz2 | *.tbz | *.tar | *.zip)
+    (cd "${TMPDIR}" && untar "${BINST_ASSET}")
+    BINST_BIN="${TMPDIR}/${BINST_PATH}"
+    ;;
+  *)
+    BINST_BIN="${TMPDIR}/${BINST_ASSET}"
+    ;;
+  esac
+  chmod +x "${BINST_BIN}"
+
+  cat >"${TMPDIR}/binstaller.yml" <<'BINSTALLER_SPEC_EOF'
+{{ .BootstrapSpec }}
+BINSTALLER_SPEC_EOF
+
+  set -- install --config "${TMPDIR}/binstaller.yml" --bin-dir "${BINDIR}"
+  {{- if .Features.DryRun }}
+  if [ "$DRY_RUN" = "1" ]; then
+    set -- "$@" --dry-run
+  fi
+  {{- end }}
+  progress_clear
+  "${BINST_BIN}" "$@" "${TAG}"
+}
+{{- end }}
+
+{{- define "version_source_functions" }}
+{{- $source := versionSource . }}
+{{- if eq $source "github-tags" }}
+# Print the highest version among the tags listed by the tags endpoint $2,
+# fetched page by page with the copy function $1 and the Accept header $3.
+# The endpoint sorts tags by name or update time, so v0.9.0 may come before
+# v0.10.0.
+latest_tag() {
+  tags_copy=$1
+  tags_url=$2
+  tags_accept=$3
+  tag_names=""
+  tags_page=1
+  while [ "$tags_page" -le 10 ]; do
+    json=$("$tags_copy" "${tags_url}?per_page=100&page=${tags_page}" "$tags_accept")
+    page_names=$(echo "$json" | awk '{ while (match($0, /"name" *: *"[^"]*"/)) { s = substr($0, RSTART, RLENGTH); $0 = substr($0, RSTART + RLENGTH); sub(/^"name" *: *"/, "", s); sub(/"$/, "", s); print s } }')
+    test -z "$page_names" && break
+    tag_names="${tag_names}${page_names}
+"
+    test "$(echo "$page_names" | wc -l)" -lt 100 && break
+    tags_page=$((tags_page + 1))
+  done
+  # Compare the dotted numbers after any prefix (v, release-), then rank a
+  # pre-release below its release
+  version=$(printf '%s' "$tag_names" | awk '
+    function lower(a, b,  x, y, pa, pb, n, i) {
+      sub(/^[^0-9]*/, "", a); sub(/^[^0-9]*/, "", b)
+      sub(/\+.*/, "", a); sub(/\+.*/, "", b)
+      pa = ""; pb = ""
+      if (match(a, /-/)) { pa = substr(a, RSTART + 1); a = substr(a, 1, RSTART - 1) }
+      if (match(b, /-/)) { pb = substr(b, RSTART + 1); b = substr(b, 1, RSTART - 1) }
+      n = split(a, x, "."); i = split(b, y, "."); if (i > n) n = i
+      for (i = 1; i <= n; i++) {
+        if (x[i] + 0 < y[i] + 0) return 1
+        if (x[i] + 0 > y[i] + 0) return 0
+      }
+      if (pa == pb) return 0
+      if (pa == "") return 0
+      if (pb == "") return 1
+      return pa < pb
+    }
+    NF { if (best == "" || lower(best, $0)) best = $0 }
+    END { if (best != "") print best }')
+  test -z "$version" && return 1
+  echo "$version"
+}
+{{- if .GitLabHost }}
+gitlab_latest_tag() {
+  latest_tag gitlab_http_copy "$1/repository/tags" "Accept:application/json"
+}
+{{- else }}
+github_latest_tag() {
+  latest_tag github_http_copy "{{ .GitHubAPI }}/repos/$1/tags" "Accept:application/vnd.github.v3+json"
+}
+{{- end }}
+{{- else if eq $source "http-json" }}
+http_json_version() {
+  url=$1
+  filter=$2
+  key=$3
+  # Fetch without GITHUB_TOKEN: the endpoint is not necessarily GitHub
+  if is_command curl; then
+    json=$(curl -fsSL -H "Accept:application/json" "$url")
+  elif is_command wget; then
+    json=$(wget -q --header "Accept:application/json" -O - "$url")
+  else
+    log_crit "http_json_version unable to find wget or curl"
+    return 1
+  fi
+  test -z "$json" && return 1
+  if is_command jq; then
+    version=$(echo "$json" | jq -r "${filter} // empty")
+  elif [ -n "$key" ]; then
+    version=$(echo "$json" | awk -v key="$key" '{ if (match($0, "\"" key "\" *: *\"[^\"]*\"")) { s = substr($0, RSTART, RLENGTH); sub(/^"[^"]*" *: *"/, "", s); sub(/"$/, "", s); print s; exit } }')
+  else
+    log_crit "jq is required to evaluate ${filter}"
+    return 1
+  fi
+  test -z "$version" && return 1
+  echo "$version"
+}
+{{- end }}
+{{- end }}
 
 {{- define "embedded_checksums" }}
 # --- Embedded Checksums (Format: VERSION:FILENAME:HASH) ---
@@ -107,20 +410,412 @@ find_embedded_checksum() {
 }
 {{- end }}
 
+{{- if .VerifyChecksums }}
 {{- template "embedded_checksums" . }}
+{{- end }}
+
+{{- define "unpack_filter_functions" }}
+
+# Extract the members of a tar archive that unpack.include and unpack.exclude
+# select. Other archives are extracted in full.
+untar_filtered() {
+  tarball=$1
+  strip_components=${2:-0}
+  case "${tarball}" in
+  *.tar.gz | *.tgz) list_flags=-tzf ;;
+  *.tar.xz) list_flags=-tJf ;;
+  *.tar.bz2 | *.tbz2 | *.tbz) list_flags=-tjf ;;
+  *.tar) list_flags=-tf ;;
+  *)
+    untar "${tarball}" "${strip_components}"
+    return
+    ;;
+  esac
+  tar "${list_flags}" "${tarball}" | while IFS= read -r member; do
+    case "${member}" in */) continue ;; esac
+    path="${member#./}"
+    i=0
+    while [ "$i" -lt "${strip_components}" ]; do
+      case "${path}" in */*) path="${path#*/}" ;; *) path="" ;; esac
+      i=$((i + 1))
+    done
+    [ -n "${path}" ] || continue
+    {{- with .Unpack.GetExclude }}
+    case "${path}" in {{ unpackPatterns . }}) continue ;; esac
+    {{- end }}
+    {{- with .Unpack.GetInclude }}
+    case "${path}" in {{ unpackPatterns . }}) ;; *) continue ;; esac
+    {{- end }}
+    echo "${member}"
+  done >"${tarball}.members"
+  if [ ! -s "${tarball}.members" ]; then
+    log_crit "unpack.include and unpack.exclude select no files of ${tarball}"
+    return 1
+  fi
+  tar --no-same-owner -x"${list_flags#-t}" "${tarball}" --strip-components "${strip_components}" -T "${tarball}.members"
+}
+{{- end }}
+
+{{- if .Unpack.HasFilters }}
+{{- template "unpack_filter_functions" . }}
+{{- end }}
+
+{{- define "extra_file_functions" }}
+{{- if .VerifyChecksums }}
+
+# Verify an extra file downloaded into TMPDIR like the asset
+verify_extra_file() {
+  extra="$1"
+  extra_hash=$(find_embedded_checksum "$VERSION" "$extra")
+  if [ -n "$extra_hash" ]; then
+    got=$(hash_compute "${TMPDIR}/${extra}")
+    if [ "$got" != "$extra_hash" ]; then
+      log_crit "Checksum verification failed for ${extra}"
+      log_crit "Expected: ${extra_hash}"
+      log_crit "Got: ${got}"
+      return 1
+    fi
+  elif [ -n "$CHECKSUM_URL" ]; then
+    if [ ! -f "${TMPDIR}/${CHECKSUM_FILENAME}" ]; then
+      log_info "Downloading checksums from ${CHECKSUM_URL}"
+      {{ .DownloadFunc }} "${TMPDIR}/${CHECKSUM_FILENAME}" "${CHECKSUM_URL}"
+      {{- if .SignatureFunctions }}
+      verify_checksum_signature
+      {{- end }}
+    fi
+    hash_verify "${TMPDIR}/${extra}" "${TMPDIR}/${CHECKSUM_FILENAME}"
+  else
+    {{- if .Checksums.GetRequired }}
+    log_crit "No checksum found for ${extra}; refusing to install an unverified asset (checksums.required)"
+    return 1
+    {{- else }}
+    log_warn "No checksum found for ${extra}, skipping verification."
+    {{- end }}
+  fi
+}
+{{- end }}
+
+# Install an extra file from TMPDIR into a directory, extracting archives
+install_extra_file() {
+  extra="$1"
+  dest="$2"
+  {{- if .Features.DryRun }}
+  if [ "$DRY_RUN" = "1" ]; then
+    log_info "[DRY RUN] Would install ${extra} into ${dest}"
+    return 0
+  fi
+  {{- end }}
+  mkdir -p "${dest}"
+  case "${extra}" in
+  *.tar.gz | *.tgz | *.tar.xz | *.tar.bz2 | *.tbz2 | *.tbz | *.tar | *.zip)
+    log_info "Extracting ${extra} into ${dest}"
+    (cd "${dest}" && untar "${TMPDIR}/${extra}" 0)
+    ;;
+  {{- if .ZstdFunctions }}
+  *.tar.zst | *.tzst)
+    log_info "Extracting ${extra} into ${dest}"
+    (cd "${dest}" && untar_zstd "${TMPDIR}/${extra}" 0)
+    ;;
+  {{- end }}
+  {{- if .SevenZipFunctions }}
+  *.7z)
+    log_info "Extracting ${extra} into ${dest}"
+    (cd "${dest}" && untar_7z "${TMPDIR}/${extra}" 0)
+    ;;
+  {{- end }}
+  *)
+    log_info "Installing ${extra} into ${dest}"
+    cp "${TMPDIR}/${extra}" "${dest}/${extra}"
+    ;;
+  esac
+}
+{{- end }}
+
+{{- if and (eq .ScriptType "installer") .Asset.ExtraFiles }}
+{{- template "extra_file_functions" . }}
+{{- end }}
+
+{{- define "completion_functions" }}
+
+# Install a shell completion script of the extracted archive
+install_completion() {
+  src="${TMPDIR}/$2"
+  dest="$3"
+  if [ ! -f "${src}" ]; then
+    log_warn "Skipping $1 completion: $2 not found in ${ASSET_FILENAME}"
+    return 0
+  fi
+  {{- if .Features.DryRun }}
+  if [ "$DRY_RUN" = "1" ]; then
+    log_info "[DRY RUN] Would install $1 completion to ${dest}"
+    return 0
+  fi
+  {{- end }}
+  log_info "Installing $1 completion to ${dest}"
+  install -d "$(dirname "${dest}")"
+  install -m 644 "${src}" "${dest}"
+}
+
+# Install the shell completions into the share directory next to BINDIR
+install_completions() {
+  if [ "${UNAME_OS}" = "windows" ]; then
+    log_info "Skipping shell completions: completions are not installed on windows"
+    return 0
+  fi
+  if [ -z "${EXT}" ] || [ "${EXT}" = ".exe" ]; then
+    log_info "Skipping shell completions: ${ASSET_FILENAME} is not an archive"
+    return 0
+  fi
+  install_prefix=$(dirname "${BINDIR}")
+  {{- range .Completions.Files }}
+  install_completion {{ .Shell }} "{{ .Path }}" "${install_prefix}/{{ completionDest .Shell "${COMPLETION_NAME}" }}"
+  {{- end }}
+}
+{{- end }}
+
+{{- if and (eq .ScriptType "installer") .Completions.Files }}
+{{- template "completion_functions" . }}
+{{- end }}
+
+{{- define "wrapper_functions" }}
+
+# Install a wrapper script read from stdin into BINDIR
+install_wrapper() {
+  wrapper="${BINDIR}/$1"
+  if [ "${UNAME_OS}" = "windows" ]; then
+    cat >/dev/null
+    log_info "Skipping wrapper $1: wrappers are not supported on windows"
+    return 0
+  fi
+  {{- if .Features.DryRun }}
+  if [ "$DRY_RUN" = "1" ]; then
+    cat >/dev/null
+    log_info "[DRY RUN] Would install wrapper ${wrapper}"
+    return 0
+  fi
+  {{- end }}
+  log_info "Installing wrapper to ${wrapper}"
+  cat >"${wrapper}"
+  chmod +x "${wrapper}"
+}
+{{- end }}
+
+{{- if .Wrappers }}
+{{- template "wrapper_functions" . }}
+{{- end }}
+
+{{- define "fallback_functions" }}
+
+# Print why a directory cannot be installed into: it is a system directory of an
+# immutable distribution or on a read-only file system. Prints nothing otherwise.
+# /usr/local stays writable on ostree-based systems, so only the probe checks it.
+bindir_read_only() {
+  for dir in /usr /bin /sbin /lib /nix/store; do
+    case "$1" in
+    /usr/local | /usr/local/*) ;;
+    "${dir}" | "${dir}"/*)
+      if [ -e /etc/NIXOS ]; then
+        echo "${dir} is read-only on NixOS"
+        return 0
+      fi
+      if [ -e /run/ostree-booted ]; then
+        echo "${dir} is read-only on an ostree-based immutable system"
+        return 0
+      fi
+      ;;
+    esac
+  done
+  {{- if .Features.DryRun }}
+  if [ "$DRY_RUN" = "1" ]; then
+    return 0
+  fi
+  {{- end }}
+  # Probe the nearest existing directory, where BINDIR would be created
+  dir="$1"
+  while [ ! -d "${dir}" ]; do
+    dir=$(dirname "${dir}")
+  done
+  probe="${dir}/.binstaller-probe.$$"
+  if err=$(LC_ALL=C touch "${probe}" 2>&1); then
+    rm -f "${probe}"
+  else
+    case "${err}" in
+    *"Read-only file system"*) echo "${dir} is on a read-only file system" ;;
+    esac
+  fi
+}
+
+# Redirect a read-only BINDIR to ~/.local/bin, or fail with -s
+resolve_bindir() {
+  reason=$(bindir_read_only "${BINDIR}")
+  if [ -z "${reason}" ]; then
+    return 0
+  fi
+  fallback="${HOME}/.local/bin"
+  if [ "${NO_FALLBACK}" = "1" ] || [ "${NO_FALLBACK}" = "true" ] || [ -z "${HOME}" ] || [ "${BINDIR}" = "${fallback}" ]; then
+    log_crit "Cannot install into ${BINDIR}: ${reason}"
+    log_crit "Choose another directory with -b"
+    exit 1
+  fi
+  log_warn "Cannot install into ${BINDIR}: ${reason}"
+  log_warn "Installing into ${fallback} instead; make sure it is on PATH, or pass -s to fail instead"
+  BINDIR="${fallback}"
+}
+{{- end }}
+
+{{- if and (eq .ScriptType "installer") .Features.Fallback }}
+{{- template "fallback_functions" . }}
+{{- end }}
+
+{{- define "attestation_functions" }}
+
+# Verify the GitHub artifact attestation of a file downloaded into TMPDIR with
+# the GitHub CLI, which is skipped when gh is not installed
+verify_attestation() {
+  file="$1"
+  if ! is_command gh; then
+    log_info "gh not found, skipping attestation verification of ${file}"
+    return 0
+  fi
+  log_info "Verifying attestation for ${file}"
+  if ! gh attestation verify "${TMPDIR}/${file}" --repo "${REPO}" --predicate-type '{{ .Attestation.GetPredicateType }}'
+    {{- with .Attestation.GetSignerWorkflow }} --signer-workflow '{{ . }}'{{ end }} >/dev/null; then
+    {{- if .Attestation.GetRequired }}
+    log_crit "Attestation verification failed for ${file}"
+    return 1
+    {{- else }}
+    log_err "Attestation verification failed for ${file}; installing it anyway (attestation.required is false)"
+    {{- end }}
+  fi
+}
+{{- end }}
+
+{{- if and (eq .ScriptType "installer") .Attestation }}
+{{- template "attestation_functions" . }}
+{{- end }}
+
+{{- define "usage_ping_function" }}
+
+# Send the usage ping described at the top of this script
+usage_ping() {
+  if [ "${BINSTALLER_NO_USAGE_PING:-0}" != "0" ] || [ "${DO_NOT_TRACK:-0}" != "0" ]; then
+    log_debug "Usage ping disabled"
+    return 0
+  fi
+  {{- if .Features.DryRun }}
+  if [ "$DRY_RUN" = "1" ]; then
+    return 0
+  fi
+  {{- end }}
+  log_debug "Sending usage ping to {{ .UsagePingURL }}"
+  # The ping is sent once, bypassing the retries of the download settings
+  if is_command curl; then
+    command curl -fsS -o /dev/null -A binstaller --max-time 5 '{{ .UsagePingURL }}' >/dev/null 2>&1 || true
+  elif is_command wget; then
+    command wget -q -O /dev/null -U binstaller -T 5 '{{ .UsagePingURL }}' >/dev/null 2>&1 || true
+  fi
+}
+{{- end }}
+
+{{- if .UsagePingURL }}
+{{- template "usage_ping_function" . }}
+{{- end }}
 
 {{- define "parse_args_installer" }}
 parse_args() {
+  {{- if .Features.BinDirEnv }}
+  # v0 installers read the installation directory from BINDIR
+  V0_BINDIR="${BINDIR}"
+  {{- end }}
   BINDIR="{{ deref .DefaultBinDir }}"
+  {{- if .Features.BinDirEnv }}
+  if [ -n "${V0_BINDIR}" ]; then
+    log_warn "BINDIR is deprecated, use -b or BINSTALLER_BIN instead"
+    BINDIR="${V0_BINDIR}"
+  fi
+  {{- end }}
+  {{- if .Features.DryRun }}
   DRY_RUN=0
-  while getopts "b:dqh?xn" arg; do
+  {{- end }}
+  {{- if .Features.Fallback }}
+  NO_FALLBACK="${BINSTALLER_NO_FALLBACK:-0}"
+  {{- end }}
+  {{- if .Completions.Files }}
+  COMPLETIONS="${BINSTALLER_COMPLETIONS:-0}"
+  {{- end }}
+  TRACE=0
+  while getopts "b:d{{ if .Features.Quiet }}q{{ end }}h?x{{ if .Features.DryRun }}n{{ end }}{{ if .Features.Fallback }}s{{ end }}{{ if .Completions.Files }}c{{ end }}o:a:-:" arg; do
+    if [ "$arg" = "-" ]; then
+      {{- if .Features.Compat }}
+      # v0 long options, e.g. --bindir=DIR or --bindir DIR
+      arg_value=""
+      case "$OPTARG" in
+      *=*)
+        arg_value="${OPTARG#*=}"
+        OPTARG="${OPTARG%%=*}"
+        ;;
+      esac
+      case "$OPTARG" in
+      bindir)
+        if [ -z "$arg_value" ]; then
+          eval "arg_value=\${$OPTIND}"
+          OPTIND=$((OPTIND + 1))
+        fi
+        if [ -z "$arg_value" ]; then
+          log_err "--$OPTARG requires a value"
+          usage "$0"
+        fi
+        ;;
+      esac
+      {{- end }}
+      case "$OPTARG" in
+      log-json)
+        log_set_json 1
+        continue
+        ;;
+      {{- if .Features.Compat }}
+      bindir) arg="b" ;;
+      debug) arg="d" ;;
+      {{- if .Features.Quiet }}
+      quiet) arg="q" ;;
+      {{- end }}
+      {{- if .Features.DryRun }}
+      dry-run) arg="n" ;;
+      {{- end }}
+      help) arg="h" ;;
+      {{- end }}
+      *)
+        log_err "unknown option --$OPTARG"
+        usage "$0"
+        ;;
+      esac
+      {{- if .Features.Compat }}
+      log_warn "--$OPTARG is deprecated, use -$arg instead"
+      OPTARG="$arg_value"
+      {{- end }}
+    fi
     case "$arg" in
     b) BINDIR="$OPTARG" ;;
     d) log_set_priority 10 ;;
+    o) BINSTALLER_OS="$OPTARG" ;;
+    a) BINSTALLER_ARCH="$OPTARG" ;;
+    {{- if .Features.Quiet }}
     q) log_set_priority 3 ;;
+    {{- end }}
     h | \?) usage "$0" ;;
-    x) set -x ;;
+    x)
+      TRACE=1
+      set -x
+      ;;
+    {{- if .Features.DryRun }}
     n) DRY_RUN=1 ;;
+    {{- end }}
+    {{- if .Features.Fallback }}
+    s) NO_FALLBACK=1 ;;
+    {{- end }}
+    {{- if .Completions.Files }}
+    c) COMPLETIONS=1 ;;
+    {{- end }}
     esac
   done
   shift $((OPTIND - 1))
@@ -161,8 +856,18 @@ configure_from_env() {
   # Override log level if explicitly set via environment variables
   if [ "${BINSTALLER_DEBUG}" = "1" ] || [ "${BINSTALLER_DEBUG}" = "true" ]; then
     log_set_priority 10
+  {{- if .Features.Quiet }}
   elif [ "${BINSTALLER_QUIET}" = "1" ] || [ "${BINSTALLER_QUIET}" = "true" ]; then
     log_set_priority 3
+  {{- end }}
+  fi
+  if [ "${BINSTALLER_LOG_JSON}" = "1" ] || [ "${BINSTALLER_LOG_JSON}" = "true" ]; then
+    log_set_json 1
+  fi
+  TRACE=0
+  if [ "${BINSTALLER_TRACE}" = "1" ] || [ "${BINSTALLER_TRACE}" = "true" ]; then
+    TRACE=1
+    set -x
   fi
 }
 {{- end }}
@@ -180,6 +885,12 @@ tag_to_version() {
   REALTAG="{{ .TargetVersion }}"
   VERSION=${REALTAG#v} # Strip leading 'v'
   TAG="$REALTAG"
+  {{- if .ReleaseVars }}
+  # Release metadata is resolved at generation time
+  {{- range .ReleaseVars }}
+  {{ .Name }}={{ quote .Value }}
+  {{- end }}
+  {{- end }}
   {{- if eq .ScriptType "installer" }}
   log_info "Installing ${NAME} version ${VERSION}"
   {{- else }}
@@ -187,8 +898,24 @@ tag_to_version() {
   {{- end }}
   {{- else }}
   if [ "$TAG" = "latest" ]; then
+    {{- $source := versionSource . }}
+    {{- if and .GitLabHost (eq $source "github-tags") }}
+    log_info "checking GitLab for latest tag"
+    REALTAG=$(gitlab_latest_tag "{{ .GitLabProjectAPI }}") && true
+    {{- else if and .GitLabHost (ne $source "http-json") }}
+    log_info "checking GitLab for latest release"
+    REALTAG=$(gitlab_release "{{ .GitLabProjectAPI }}") && true
+    {{- else if eq $source "github-tags" }}
     log_info "checking GitHub for latest tag"
-    REALTAG=$(github_release "${REPO}" "${TAG}") && true
+    REALTAG=$(github_latest_tag "${REPO}") && true
+    {{- else if eq $source "http-json" }}
+    VERSION_URL="{{ .Version.URL | deref }}"
+    log_info "checking ${VERSION_URL} for latest version"
+    REALTAG=$(http_json_version "${VERSION_URL}" '{{ jsonPathJQ .Version.GetJSONPath }}' '{{ jsonPathKey .Version.GetJSONPath }}') && true
+    {{- else }}
+    log_info "checking GitHub for latest tag"
+    REALTAG=$(github_release "${REPO}" "${TAG}"{{ if ne .GitHubHost "github.com" }} "https://{{ .GitHubHost }}"{{ end }}) && true
+    {{- end }}
     test -n "$REALTAG" || {
       log_crit "Could not determine latest tag for ${REPO}"
       exit 1
@@ -198,7 +925,11 @@ tag_to_version() {
     REALTAG="$TAG"
   fi
   if test -z "$REALTAG"; then
-    log_crit "unable to find '${TAG}' - use 'latest' or see https://github.com/${REPO}/releases for details"
+    {{- if .GitLabHost }}
+    log_crit "unable to find '${TAG}' - use 'latest' or see https://{{ .GitLabHost }}/${REPO}/-/releases for details"
+    {{- else }}
+    log_crit "unable to find '${TAG}' - use 'latest' or see https://{{ .GitHubHost }}/${REPO}/releases for details"
+    {{- end }}
     exit 1
   fi
   VERSION=${REALTAG#v} # Strip leading 'v'
@@ -238,9 +969,11 @@ resolve_asset_filename() {
   if
     {{- if .When.OS }} [ "${UNAME_OS}" = '{{ deref .When.OS }}' ] && {{- end }}
     {{- if .When.Arch }} [ "${UNAME_ARCH}" = '{{ deref .When.Arch }}' ] && {{- end }}
+    {{- if .When.OSVersion }} os_version_matches '{{ deref .When.OSVersion }}' && {{- end }}
     {{- " true" }}
   then
     {{- "\n   " -}}
+    {{- if not (or .OS .Arch .EXT .Template .Binaries) }} : {{- end }}
     {{- if .OS }} OS='{{ deref .OS }}' {{- end }}
     {{- if .Arch }} ARCH='{{ deref .Arch }}' {{- end }}
     {{- if .EXT }} EXT='{{ deref .EXT }}' {{- end }}
@@ -255,6 +988,22 @@ resolve_asset_filename() {
   if [ -z "${ASSET_FILENAME}" ]; then
     ASSET_FILENAME="{{ deref .Asset.Template }}"
   fi
+  {{- if hasFallbacks .Asset.Rules }}
+  # --- Fallback candidates, tried in order when ASSET_FILENAME is missing ---
+  ASSET_FALLBACKS=""
+  {{- range .Asset.Rules }}
+  {{- if .FallbackTemplates }}
+  if
+    {{- if .When.OS }} [ "${UNAME_OS}" = '{{ deref .When.OS }}' ] && {{- end }}
+    {{- if .When.Arch }} [ "${UNAME_ARCH}" = '{{ deref .When.Arch }}' ] && {{- end }}
+    {{- if .When.OSVersion }} os_version_matches '{{ deref .When.OSVersion }}' && {{- end }}
+    {{- " true" }}
+  then
+    ASSET_FALLBACKS="{{ fallbacks .FallbackTemplates }}"
+  fi
+  {{- end }}
+  {{- end }}
+  {{- end }}
 }
 {{- end }}
 
@@ -275,28 +1024,35 @@ cleanup() {
 
 {{- template "cleanup" . }}
 
-{{- define "execute_download_verify" }}
-  STRIP_COMPONENTS={{ if .Unpack }}{{ deref .Unpack.StripComponents | default 0 }}{{ else }}0{{ end }}
-  CHECKSUM_FILENAME="{{ if .Checksums }}{{ deref .Checksums.Template }}{{ end }}"
+{{- define "log_resolved" }}
 
-  # --- Construct URLs ---
-  GITHUB_DOWNLOAD="https://github.com/${REPO}/releases/download"
-  ASSET_URL="${GITHUB_DOWNLOAD}/${TAG}/${ASSET_FILENAME}"
-  CHECKSUM_URL=""
-  if [ -n "$CHECKSUM_FILENAME" ]; then
-    CHECKSUM_URL="${GITHUB_DOWNLOAD}/${TAG}/${CHECKSUM_FILENAME}"
+# log_resolved logs the resolved variables and the checksum source $1
+# (embedded, the checksum file URL or none) in one line when tracing, and as
+# one JSON object with --log-json
+log_resolved() {
+  if [ "$_logjson" = 1 ]; then
+    printf '{"level":"info","repo":"%s","step":"resolve","msg":"resolved","os":"%s","arch":"%s","tag":"%s","version":"%s","asset_url":"%s","checksum":"%s"}\n' \
+      "$(log_json_escape "${REPO}")" "$(log_json_escape "${OS}")" "$(log_json_escape "${ARCH}")" "$(log_json_escape "${TAG}")" \
+      "$(log_json_escape "${VERSION}")" "$(log_json_escape "${ASSET_URL}")" "$(log_json_escape "$1")" 1>&2
+  elif [ "${TRACE}" = 1 ]; then
+    echoerr "$(log_prefix)" resolved "os=${OS}" "arch=${ARCH}" "tag=${TAG}" "version=${VERSION}" "asset_url=${ASSET_URL}" "checksum=$1"
   fi
+}
+{{- end }}
 
-  # --- Download and Verify ---
-  TMPDIR=$(mktemp -d)
-  # Set up cleanup trap that includes progress clearing
-  trap cleanup EXIT HUP INT TERM
-  log_debug "Downloading files into ${TMPDIR}"
-  log_info "Downloading ${ASSET_URL}"
-  github_http_download "${TMPDIR}/${ASSET_FILENAME}" "${ASSET_URL}"
+{{- template "log_resolved" . }}
+
+{{- define "verify_checksums" }}
+  log_set_step verify
+{{- if .VerifyChecksums }}
 
   # Try to find embedded checksum first
   EMBEDDED_HASH=$(find_embedded_checksum "$VERSION" "$ASSET_FILENAME")
+  if [ -n "$EMBEDDED_HASH" ]; then
+    log_resolved embedded
+  else
+    log_resolved "${CHECKSUM_URL:-none}"
+  fi
 
   if [ -n "$EMBEDDED_HASH" ]; then
     log_info "Using embedded checksum for verification"
@@ -313,24 +1069,141 @@ cleanup() {
   elif [ -n "$CHECKSUM_URL" ]; then
     # Fall back to downloading checksum file
     log_info "Downloading checksums from ${CHECKSUM_URL}"
-    github_http_download "${TMPDIR}/${CHECKSUM_FILENAME}" "${CHECKSUM_URL}"
+    {{ .DownloadFunc }} "${TMPDIR}/${CHECKSUM_FILENAME}" "${CHECKSUM_URL}"
+    {{- if .SignatureFunctions }}
+    verify_checksum_signature
+    {{- end }}
     log_info "Verifying checksum ..."
     hash_verify "${TMPDIR}/${ASSET_FILENAME}" "${TMPDIR}/${CHECKSUM_FILENAME}"
   else
-    log_info "No checksum found, skipping verification."
+    {{- if .Checksums.GetRequired }}
+    log_crit "No checksum found for ${ASSET_FILENAME}; refusing to install an unverified asset (checksums.required)"
+    return 1
+    {{- else }}
+    log_warn "No checksum found, skipping verification."
+    {{- end }}
+  fi
+  {{- if .Checksums.GetAlgorithm.IsWeak }}
+  if { [ -n "$EMBEDDED_HASH" ] || [ -n "$CHECKSUM_URL" ]; } && [ "${BINSTALLER_ALLOW_WEAK_HASH}" != "1" ] && [ "${BINSTALLER_ALLOW_WEAK_HASH}" != "true" ]; then
+    {{- if .Checksums.GetRequired }}
+    log_crit "{{ .Checksums.GetAlgorithm }} checksums are too weak to verify ${ASSET_FILENAME}; refusing to install an unverified asset (checksums.required)"
+    log_crit "Set BINSTALLER_ALLOW_WEAK_HASH=1 to accept {{ .Checksums.GetAlgorithm }} checksums"
+    return 1
+    {{- else }}
+    log_err "{{ .Checksums.GetAlgorithm }} checksums are too weak to verify ${ASSET_FILENAME}; treating it as unverified (set BINSTALLER_ALLOW_WEAK_HASH=1 to accept them)"
+    {{- end }}
+  fi
+  {{- end }}
+{{- else }}
+  log_resolved none
+
+  log_warn "No checksum found, skipping verification."
+{{- end }}
+{{- end }}
+
+{{- define "execute_download_verify" }}
+  STRIP_COMPONENTS={{ if .Unpack }}{{ deref .Unpack.StripComponents | default 0 }}{{ else }}0{{ end }}
+  {{- if .VerifyChecksums }}
+  CHECKSUM_FILENAME="{{ if .Checksums }}{{ deref .Checksums.Template }}{{ end }}"
+  {{- range .Asset.Rules }}
+  {{- if .ChecksumTemplate }}
+  if
+    {{- if .When.OS }} [ "${UNAME_OS}" = '{{ deref .When.OS }}' ] && {{- end }}
+    {{- if .When.Arch }} [ "${UNAME_ARCH}" = '{{ deref .When.Arch }}' ] && {{- end }}
+    {{- if .When.OSVersion }} os_version_matches '{{ deref .When.OSVersion }}' && {{- end }}
+    {{- " true" }}
+  then
+    CHECKSUM_FILENAME="{{ deref .ChecksumTemplate }}"
+  fi
+  {{- end }}
+  {{- end }}
+  {{- end }}
+
+  # --- Construct URLs ---
+  {{- if .GitLabHost }}
+  GITLAB_DOWNLOAD="https://{{ .GitLabHost }}/${REPO}/-/releases"
+  {{- else }}
+  GITHUB_DOWNLOAD="https://{{ .GitHubHost }}/${REPO}/releases/download"
+  {{- end }}
+  ASSET_URL="{{ releaseFileURL .GitLabHost "${ASSET_FILENAME}" }}"
+  {{- if .VerifyChecksums }}
+  CHECKSUM_URL=""
+  if [ -n "$CHECKSUM_FILENAME" ]; then
+    CHECKSUM_URL="{{ releaseFileURL .GitLabHost "${CHECKSUM_FILENAME}" }}"
+  fi
+  {{- end }}
+
+  # --- Download and Verify ---
+  log_set_step download
+  TMPDIR=$(mktemp -d)
+  # Set up cleanup trap that includes progress clearing
+  trap cleanup EXIT HUP INT TERM
+  log_debug "Downloading files into ${TMPDIR}"
+  log_info "Downloading ${ASSET_URL}"
+  {{- if hasFallbacks .Asset.Rules }}
+  if ! {{ .DownloadFunc }} "${TMPDIR}/${ASSET_FILENAME}" "${ASSET_URL}"; then
+    ASSET_DOWNLOADED=""
+    for candidate in ${ASSET_FALLBACKS}; do
+      log_info "${ASSET_FILENAME} not available, trying ${candidate}"
+      ASSET_FILENAME="${candidate}"
+      ASSET_URL="{{ releaseFileURL .GitLabHost "${ASSET_FILENAME}" }}"
+      if {{ .DownloadFunc }} "${TMPDIR}/${ASSET_FILENAME}" "${ASSET_URL}"; then
+        ASSET_DOWNLOADED=1
+        break
+      fi
+    done
+    if [ -z "${ASSET_DOWNLOADED}" ]; then
+      log_crit "None of the asset candidates could be downloaded"
+      return 1
+    fi
   fi
+  {{- else }}
+  {{ .DownloadFunc }} "${TMPDIR}/${ASSET_FILENAME}" "${ASSET_URL}"
+  {{- end }}
+{{- template "verify_checksums" . }}
+  {{- if and (eq .ScriptType "installer") .Attestation }}
+  verify_attestation "${ASSET_FILENAME}"
+  {{- end }}
 
+  log_set_step extract
   if [ -z "${EXT}" ] || [ "${EXT}" = ".exe" ]; then
     log_debug "Target is raw binary"
   else
     log_info "Extracting ${ASSET_FILENAME}..."
-    (cd "${TMPDIR}" && untar "${ASSET_FILENAME}" "${STRIP_COMPONENTS}")
+    {{- if or .ZstdFunctions .SevenZipFunctions }}
+    case "${ASSET_FILENAME}" in
+    {{- if .ZstdFunctions }}
+    *.tar.zst | *.tzst) (cd "${TMPDIR}" && untar_zstd "${ASSET_FILENAME}" "${STRIP_COMPONENTS}") ;;
+    *.zst) (cd "${TMPDIR}" && unzstd "${ASSET_FILENAME}") ;;
+    {{- end }}
+    {{- if .SevenZipFunctions }}
+    *.7z) (cd "${TMPDIR}" && untar_7z "${ASSET_FILENAME}" "${STRIP_COMPONENTS}") ;;
+    {{- end }}
+    *) (cd "${TMPDIR}" && {{ if .Unpack.HasFilters }}untar_filtered{{ else }}untar{{ end }} "${ASSET_FILENAME}" "${STRIP_COMPONENTS}") ;;
+    esac
+    {{- else }}
+    (cd "${TMPDIR}" && {{ if .Unpack.HasFilters }}untar_filtered{{ else }}untar{{ end }} "${ASSET_FILENAME}" "${STRIP_COMPONENTS}")
+    {{- end }}
   fi
+  {{- if and (eq .ScriptType "installer") .Asset.ExtraFiles }}
+
+  # --- Download and Verify extra files ---
+  {{- range $i, $extra := .Asset.ExtraFiles }}
+  EXTRA_FILENAME_{{ $i }}="{{ deref $extra.Template }}"
+  log_info "Downloading {{ releaseFileURL $.GitLabHost (printf "${EXTRA_FILENAME_%d}" $i) }}"
+  {{ $.DownloadFunc }} "${TMPDIR}/${EXTRA_FILENAME_{{ $i }}}" "{{ releaseFileURL $.GitLabHost (printf "${EXTRA_FILENAME_%d}" $i) }}"
+  {{- if $.VerifyChecksums }}
+  verify_extra_file "${EXTRA_FILENAME_{{ $i }}}"
+  {{- end }}
+  {{- end }}
+  {{- end }}
 {{- end }}
 
 {{- define "execute_install" }}
   # Install the binary
+  log_set_step install
   INSTALL_PATH="${BINDIR}/${BINARY_NAME}"
+  {{- if .Features.DryRun }}
 
   if [ "$DRY_RUN" = "1" ]; then
     log_info "[DRY RUN] ${BINARY_NAME} dry-run installation succeeded! (Would install to: ${INSTALL_PATH})"
@@ -340,11 +1213,26 @@ cleanup() {
     install "${BINARY_PATH}" "${INSTALL_PATH}"
     log_info "${BINARY_NAME} installation complete!"
   fi
+  {{- else }}
+  log_info "Installing binary to ${INSTALL_PATH}"
+  test ! -d "${BINDIR}" && install -d "${BINDIR}"
+  install "${BINARY_PATH}" "${INSTALL_PATH}"
+  log_info "${BINARY_NAME} installation complete!"
+  {{- end }}
 {{- end }}
 
 {{- define "execute_run" }}
   # Make binary executable for runner script
+  log_set_step run
   chmod +x "${BINARY_PATH}"
+  {{- if .RuntimeEnv }}
+  # Export the environment the binary needs (runtime_env)
+  RUNTIME_BINDIR=$(dirname "${BINARY_PATH}")
+  RUNTIME_INSTALL_DIR=$(dirname "${RUNTIME_BINDIR}")
+  {{- range $name, $value := .RuntimeEnv }}
+  export {{ $name }}={{ runtimeEnvValue $value }}
+  {{- end }}
+  {{- end }}
   # Run the binary directly with provided arguments (already shifted)
   if [ $# -gt 0 ]; then
     log_info "Running ${BINARY_NAME} with $# argument(s)"
@@ -377,6 +1265,13 @@ execute() {
     case "${BINARY_NAME}" in *.exe) ;; *) BINARY_NAME="${BINARY_NAME}.exe" ;; esac
     case "${BINARY_PATH}" in *.exe) ;; *) BINARY_PATH="${BINARY_PATH}.exe" ;; esac
   fi
+  {{- if and (eq $i 0) $.BreakingFunctions }}
+
+  warn_breaking_changes "${BINDIR}/${BINARY_NAME}"
+  {{- end }}
+  {{- if and (eq $i 0) (eq $.ScriptType "installer") $.Completions.Files }}
+  COMPLETION_NAME="${BINARY_NAME}"
+  {{- end }}
 
   if [ ! -f "${BINARY_PATH}" ]; then
     log_crit "Binary not found: ${BINARY_PATH}"
@@ -397,6 +1292,32 @@ execute() {
   {{- template "execute_run" $ }}
   {{- end }}
   {{- end }}
+  {{- if and (eq .ScriptType "installer") .Asset.ExtraFiles }}
+
+  # Install the extra files
+  {{- range $i, $extra := .Asset.ExtraFiles }}
+  install_extra_file "${EXTRA_FILENAME_{{ $i }}}" "${BINDIR}/{{ deref $extra.Dest | default "." }}"
+  {{- end }}
+  {{- end }}
+  {{- if and (eq .ScriptType "installer") .Completions.Files }}
+
+  # Install the shell completions when asked to
+  if [ "${COMPLETIONS}" = "1" ] || [ "${COMPLETIONS}" = "true" ]; then
+    install_completions
+  fi
+  {{- end }}
+  {{- if .Wrappers }}
+
+  # Install the wrappers
+  {{- range .Wrappers }}
+  install_wrapper {{ quote .Name }} <<'BINSTALLER_WRAPPER'
+{{ .Script }}BINSTALLER_WRAPPER
+  {{- end }}
+  {{- end }}
+  {{- if .UsagePingURL }}
+
+  usage_ping
+  {{- end }}
 }
 
 # --- Configuration  ---
@@ -410,6 +1331,20 @@ log_prefix() {
 }
 {{ if eq .ScriptType "runner" }}
 configure_from_env
+# Leading --os and --arch options choose the platform; the rest goes to the binary
+while [ $# -gt 0 ]; do
+  case "$1" in
+  --os | --arch)
+    [ $# -ge 2 ] || usage "$0"
+    if [ "$1" = --os ]; then BINSTALLER_OS="$2"; else BINSTALLER_ARCH="$2"; fi
+    shift 2
+    ;;
+  --os=*) BINSTALLER_OS="${1#--os=}" && shift ;;
+  --arch=*) BINSTALLER_ARCH="${1#--arch=}" && shift ;;
+  --) shift && break ;;
+  *) break ;;
+  esac
+done
 {{- else }}
 parse_args "$@"
 {{- end }}
@@ -418,6 +1353,9 @@ progress_init
 progress_pulse_start
 
 # --- Determine target platform ---
+log_set_step platform
+# Options (-o/-a, or --os/--arch of runners) set BINSTALLER_OS/BINSTALLER_ARCH,
+# which take precedence over detection
 OS="${BINSTALLER_OS:-$(uname_os)}"
 UNAME_OS="${OS}"
 {{ if and .Asset.ArchEmulation (deref .Asset.ArchEmulation.Rosetta2) }}
@@ -430,17 +1368,44 @@ fi
 {{ else }}
 ARCH="${BINSTALLER_ARCH:-$(uname_arch)}"
 {{- end }}
+{{- if .ProbeByteOrder }}
+if [ -z "${BINSTALLER_ARCH}" ]; then
+  case "${ARCH}" in
+  mips | mips64)
+    # uname -m reports mips and mips64 for both byte orders
+    if [ "$(printf '\001\000' | od -An -tu2 | tr -d ' ')" = 1 ]; then
+      ARCH="${ARCH}le"
+    fi
+    ;;
+  esac
+fi
+{{- end }}
 {{ with .Asset.Rules }}
 {{- range . }}
 {{- if .When.Arch -}} UNAME_ARCH="${ARCH}" {{- break }}{{ end }}
 {{- end }}
 {{- end }}
 log_info "Detected Platform: ${OS}/${ARCH}"
+{{- if .OSVersionFunctions }}
+OS_VERSION="$(uname_os_version "${OS}")"
+log_info "Detected OS version: ${OS_VERSION:-unknown}"
+{{- end }}
 
 # --- Validate platform ---
 uname_os_check "$OS"
 uname_arch_check "$ARCH"
+{{- if and (eq .ScriptType "installer") .Features.Fallback }}
+resolve_bindir
+{{- end }}
+{{- if .Bootstrap }}
+
+if [ "${BINSTALLER_BOOTSTRAP}" = "1" ] || [ "${BINSTALLER_BOOTSTRAP}" = "true" ]; then
+  bootstrap_binst
+  exit 0
+fi
+{{- end }}
 
+log_set_step version
 tag_to_version
 
 resolve_asset_filename
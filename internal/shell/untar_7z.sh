@@ -0,0 +1,26 @@
+untar_7z() {
+  archive=$1
+  strip_components=${2:-0} # default 0
+  sevenzip=""
+  for cmd in 7zz 7z 7za 7zr; do
+    if is_command "${cmd}"; then
+      sevenzip=${cmd}
+      break
+    fi
+  done
+  if [ -z "${sevenzip}" ]; then
+    log_err "untar_7z: cannot extract ${archive}: 7z not found"
+    log_err "Install 7-Zip (e.g. 'apt-get install 7zip', 'apk add 7zip', 'dnf install p7zip' or 'brew install sevenzip') and run the installer again"
+    return 1
+  fi
+  if [ "${strip_components}" -eq 0 ]; then
+    "${sevenzip}" x -y -bd "${archive}" >/dev/null
+    return
+  fi
+  # 7z has no --strip-components: extract to a subdir and move the members
+  # below the stripped directories up
+  extract_dir=$(basename "${archive%.7z}")_extracted
+  "${sevenzip}" x -y -bd -o"${extract_dir}" "${archive}" >/dev/null || return 1
+  find "${extract_dir}" -mindepth $((strip_components + 1)) -maxdepth $((strip_components + 1)) -exec mv {} . \;
+  rm -rf "${extract_dir}"
+}
@@ -0,0 +1,26 @@
+untar_zstd() {
+  tarball=$1
+  strip_components=${2:-0} # default 0
+  if is_command zstd; then
+    zstd -q -d -c "${tarball}" >"${tarball}.tar" || return 1
+    tar --no-same-owner -xf "${tarball}.tar" --strip-components "${strip_components}"
+    return
+  fi
+  # bsdtar (macOS, FreeBSD) and recent GNU tar can read zstd archives themselves
+  if tar --no-same-owner --zstd -xf "${tarball}" --strip-components "${strip_components}" 2>/dev/null; then
+    return 0
+  fi
+  log_err "untar_zstd: cannot extract ${tarball}: zstd not found and tar has no zstd support"
+  log_err "Install zstd (e.g. 'apt-get install zstd', 'apk add zstd', 'dnf install zstd' or 'brew install zstd') and run the installer again"
+  return 1
+}
+unzstd() {
+  file=$1
+  if is_command zstd; then
+    zstd -q -d -f --rm "${file}" -o "${file%.zst}"
+    return
+  fi
+  log_err "unzstd: cannot decompress ${file}: zstd not found"
+  log_err "Install zstd (e.g. 'apt-get install zstd', 'apk add zstd', 'dnf install zstd' or 'brew install zstd') and run the installer again"
+  return 1
+}
@@ -0,0 +1,61 @@
+package shell
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// wrapperScript is a wrapper installer scripts install
+type wrapperScript struct {
+	Name   string
+	Script string
+}
+
+// Wrapper returns the POSIX shell script of a wrapper declared in the spec. It
+// runs its binary from the directory it is installed into with the env and
+// args of the wrapper.
+func Wrapper(installSpec *spec.InstallSpec, w *spec.WrapperElement) string {
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	fmt.Fprintf(&b, "# %s runs %s of %s with the environment and arguments of its InstallSpec.\n", w.GetName(), installSpec.WrapperBinary(w), installSpec.GetRepo())
+	b.WriteString("# Generated by binstaller.\n")
+	b.WriteString("WRAPPER_BINDIR=$(CDPATH= cd -- \"$(dirname -- \"$0\")\" && pwd)\n")
+	b.WriteString("WRAPPER_INSTALL_DIR=$(dirname -- \"${WRAPPER_BINDIR}\")\n")
+	for _, name := range slices.Sorted(maps.Keys(w.Env)) {
+		fmt.Fprintf(&b, "export %s=%s\n", name, dirRefWord(w.Env[name], "WRAPPER_"))
+	}
+	fmt.Fprintf(&b, "exec \"${WRAPPER_BINDIR}\"/%s", singleQuote(installSpec.WrapperBinary(w)))
+	for _, arg := range w.Args {
+		b.WriteString(" " + dirRefWord(arg, "WRAPPER_"))
+	}
+	b.WriteString(" \"$@\"\n")
+	return b.String()
+}
+
+// dirRefWord returns value as one shell word: literal parts are single-quoted,
+// and ${INSTALL_DIR} and ${BINDIR} become the double-quoted shell variables
+// with prefix, e.g. "${RUNTIME_BINDIR}"
+func dirRefWord(value, prefix string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range spec.RuntimeEnvRef.FindAllStringSubmatchIndex(value, -1) {
+		if m[0] > last {
+			b.WriteString(singleQuote(value[last:m[0]]))
+		}
+		b.WriteString(`"${` + prefix + value[m[2]:m[3]] + `}"`)
+		last = m[1]
+	}
+	if last < len(value) || value == "" {
+		b.WriteString(singleQuote(value[last:]))
+	}
+	return b.String()
+}
+
+// singleQuote quotes s for the shell
+func singleQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,49 @@
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+func TestWrapper(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+	installSpec := &spec.InstallSpec{
+		Name: spec.StringPtr("mytool"),
+		Repo: spec.StringPtr("owner/mytool"),
+		Wrappers: []spec.WrapperElement{{
+			Name: spec.StringPtr("mytool-local"),
+			Env:  map[string]string{"MYTOOL_HOME": "${INSTALL_DIR}/share/mytool"},
+			Args: []string{"--config", "${BINDIR}/../etc/it's.yml"},
+		}},
+	}
+
+	binDir := filepath.Join(t.TempDir(), "my tools", "bin")
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(binDir, "mytool"), []byte("#!/bin/sh\necho \"$MYTOOL_HOME\" \"$@\"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	wrapper := filepath.Join(binDir, "mytool-local")
+	if err := os.WriteFile(wrapper, []byte(Wrapper(installSpec, &installSpec.Wrappers[0])), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := exec.Command(wrapper, "run", "a b").Output()
+	if err != nil {
+		t.Fatalf("wrapper failed: %v", err)
+	}
+	installDir := filepath.Dir(binDir)
+	want := filepath.Join(installDir, "share", "mytool") + " --config " + binDir + "/../etc/it's.yml run a b"
+	if strings.TrimSpace(string(got)) != want {
+		t.Errorf("wrapper ran mytool with %q, want %q", got, want)
+	}
+}
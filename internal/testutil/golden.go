@@ -0,0 +1,98 @@
+// Package testutil provides golden-file snapshots of generated scripts. Golden
+// files are compared with freshly generated output and reported as unified
+// diffs, so template changes are reviewed as changes to full scripts.
+package testutil
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// EnvUpdateGolden rewrites golden files instead of comparing them when set to 1 or true
+const EnvUpdateGolden = "BINSTALLER_UPDATE_GOLDEN"
+
+// TB is the part of testing.TB used by AssertGolden
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+	Fatalf(format string, args ...any)
+}
+
+// UpdateRequested reports whether EnvUpdateGolden asks to rewrite golden files
+func UpdateRequested() bool {
+	v := strings.ToLower(os.Getenv(EnvUpdateGolden))
+	return v == "1" || v == "true"
+}
+
+// AssertGolden compares got with the golden file at path and reports the diff
+// on mismatch. When UpdateRequested, the golden file is rewritten instead.
+func AssertGolden(t TB, path string, got []byte) {
+	t.Helper()
+	if UpdateRequested() {
+		if _, err := UpdateGolden(path, got, 0644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file (run with %s=1 to create it): %v", EnvUpdateGolden, err)
+	}
+	if diff := UnifiedDiff(path, path+" (generated)", want, got); diff != "" {
+		t.Errorf("%s is out of date (run with %s=1 to update it):\n%s", path, EnvUpdateGolden, diff)
+	}
+}
+
+// UpdateGolden writes got to the golden file at path and returns the diff from
+// its previous content, or "" when it was already up to date. New files are
+// created with perm; existing files keep their mode.
+func UpdateGolden(path string, got []byte, perm os.FileMode) (string, error) {
+	want, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+	diff := UnifiedDiff(path, path, want, got)
+	if diff == "" {
+		return "", nil
+	}
+	if err := os.WriteFile(path, got, perm); err != nil {
+		return "", err
+	}
+	return diff, nil
+}
+
+// Snapshot is a spec of a golden corpus and the golden file of its generated installer
+type Snapshot struct {
+	Name       string
+	SpecPath   string
+	GoldenPath string
+}
+
+// Corpus returns a snapshot for every NAME.binstaller.yml spec in dir, with
+// NAME.install.sh as its golden file
+func Corpus(dir string) ([]Snapshot, error) {
+	specs, err := filepath.Glob(filepath.Join(dir, "*.binstaller.yml"))
+	if err != nil {
+		return nil, err
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("no *.binstaller.yml specs found in %s", dir)
+	}
+	sort.Strings(specs)
+
+	snapshots := make([]Snapshot, 0, len(specs))
+	for _, specPath := range specs {
+		name := strings.TrimSuffix(filepath.Base(specPath), ".binstaller.yml")
+		snapshots = append(snapshots, Snapshot{
+			Name:       name,
+			SpecPath:   specPath,
+			GoldenPath: filepath.Join(dir, name+".install.sh"),
+		})
+	}
+	return snapshots, nil
+}
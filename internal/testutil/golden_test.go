@@ -0,0 +1,124 @@
+package testutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{
+			name: "equal",
+			a:    "a\nb\n",
+			b:    "a\nb\n",
+			want: "",
+		},
+		{
+			name: "changed line",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			b:    "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			want: "--- a\n+++ b\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name: "added to empty file",
+			a:    "",
+			b:    "x\n",
+			want: "--- a\n+++ b\n@@ -0,0 +1,1 @@\n+x\n",
+		},
+		{
+			name: "distant changes in separate hunks",
+			a:    "a\n1\n2\n3\n4\n5\n6\n7\n8\nb\n",
+			b:    "A\n1\n2\n3\n4\n5\n6\n7\n8\nB\n",
+			want: "--- a\n+++ b\n@@ -1,4 +1,4 @@\n-a\n+A\n 1\n 2\n 3\n@@ -7,4 +7,4 @@\n 6\n 7\n 8\n-b\n+B\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UnifiedDiff("a", "b", []byte(tt.a), []byte(tt.b)); got != tt.want {
+				t.Errorf("UnifiedDiff() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// recorder is a TB that records failures instead of failing the test
+type recorder struct {
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) { r.Errorf(format, args...) }
+
+func TestAssertGolden(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tool.install.sh")
+	if err := os.WriteFile(path, []byte("echo old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &recorder{}
+	AssertGolden(r, path, []byte("echo old\n"))
+	if len(r.errors) != 0 {
+		t.Fatalf("AssertGolden() reported %v for matching output", r.errors)
+	}
+
+	AssertGolden(r, path, []byte("echo new\n"))
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], "-echo old\n+echo new") {
+		t.Fatalf("AssertGolden() reported %v, want a diff", r.errors)
+	}
+
+	t.Setenv(EnvUpdateGolden, "1")
+	r = &recorder{}
+	AssertGolden(r, path, []byte("echo new\n"))
+	got, _ := os.ReadFile(path)
+	if len(r.errors) != 0 || string(got) != "echo new\n" {
+		t.Fatalf("AssertGolden() with %s=1 reported %v and wrote %q", EnvUpdateGolden, r.errors, got)
+	}
+}
+
+func TestUpdateGolden(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tool.install.sh")
+
+	diff, err := UpdateGolden(path, []byte("echo hi\n"), 0755)
+	if err != nil {
+		t.Fatalf("UpdateGolden() error = %v", err)
+	}
+	if !strings.Contains(diff, "+echo hi") {
+		t.Errorf("UpdateGolden() diff = %q, want the added line", diff)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("UpdateGolden() created %v, %v; want mode 0755", info, err)
+	}
+
+	diff, err = UpdateGolden(path, []byte("echo hi\n"), 0755)
+	if err != nil || diff != "" {
+		t.Errorf("UpdateGolden() of unchanged file = %q, %v; want no diff", diff, err)
+	}
+}
+
+func TestCorpus(t *testing.T) {
+	snapshots, err := Corpus("../../testdata")
+	if err != nil {
+		t.Fatalf("Corpus() error = %v", err)
+	}
+	for _, s := range snapshots {
+		if _, err := os.Stat(s.GoldenPath); err != nil {
+			t.Errorf("%s: golden file missing: %v", s.Name, err)
+		}
+	}
+
+	if _, err := Corpus(t.TempDir()); err == nil {
+		t.Error("Corpus() of an empty directory succeeded, want error")
+	}
+}
@@ -3,19 +3,24 @@ package archive
 import (
 	"archive/tar"
 	"archive/zip"
+	"compress/bzip2"
 	"compress/gzip"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/ulikunitz/xz"
 )
 
 // Extractor handles extraction of various archive formats
 type Extractor struct {
 	stripComponents int
+	filter          *Filter
+	modTimes        bool
 }
 
 // NewExtractor creates a new archive extractor
@@ -25,6 +30,21 @@ func NewExtractor(stripComponents int) *Extractor {
 	}
 }
 
+// WithFilter limits the extraction of tar and zip archives to the paths
+// selected by filter
+func (e *Extractor) WithFilter(filter *Filter) *Extractor {
+	e.filter = filter
+	return e
+}
+
+// WithModTimes sets whether files and directories extracted from tar and zip
+// archives keep the modification times of the archive, including sub-second
+// precision when the archive records it
+func (e *Extractor) WithModTimes(preserve bool) *Extractor {
+	e.modTimes = preserve
+	return e
+}
+
 // Extract extracts an archive to the specified destination directory
 func (e *Extractor) Extract(archivePath, destDir string) error {
 	ext := strings.ToLower(filepath.Ext(archivePath))
@@ -46,10 +66,30 @@ func (e *Extractor) Extract(archivePath, destDir string) error {
 		}
 		// Plain xz file (not a tar archive)
 		return e.extractXz(archivePath, destDir)
+	case ".zst":
+		// Check if it's a tar.zst
+		if strings.HasSuffix(strings.ToLower(archivePath), ".tar.zst") {
+			return e.extractTarZst(archivePath, destDir)
+		}
+		// Plain zstd file (not a tar archive)
+		return e.extractZst(archivePath, destDir)
+	case ".tzst":
+		return e.extractTarZst(archivePath, destDir)
+	case ".bz2":
+		// Check if it's a tar.bz2
+		if strings.HasSuffix(strings.ToLower(archivePath), ".tar.bz2") {
+			return e.extractTarBz2(archivePath, destDir)
+		}
+		// Plain bzip2 file (not a tar archive)
+		return e.extractBz2(archivePath, destDir)
+	case ".tbz2", ".tbz":
+		return e.extractTarBz2(archivePath, destDir)
 	case ".tar":
 		return e.extractTar(archivePath, destDir)
 	case ".zip":
 		return e.extractZip(archivePath, destDir)
+	case ".7z":
+		return e.extract7z(archivePath, destDir)
 	default:
 		// Not an archive, likely a standalone binary
 		// Copy the file to destDir
@@ -90,6 +130,40 @@ func (e *Extractor) extractTarXz(archivePath, destDir string) error {
 	return e.extractTarReader(xzReader, destDir)
 }
 
+// extractTarZst extracts a tar.zst archive
+func (e *Extractor) extractTarZst(archivePath, destDir string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	zstdReader, err := newZstdReader(file)
+	if err != nil {
+		return fmt.Errorf("failed to create zstd reader: %w", err)
+	}
+	defer zstdReader.Close()
+
+	return e.extractTarReader(zstdReader, destDir)
+}
+
+// extractTarBz2 extracts a tar.bz2 archive
+func (e *Extractor) extractTarBz2(archivePath, destDir string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	return e.extractTarReader(bzip2.NewReader(file), destDir)
+}
+
+// newZstdReader returns a streaming zstd decoder; a single goroutine is
+// enough for archives read sequentially
+func newZstdReader(r io.Reader) (*zstd.Decoder, error) {
+	return zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+}
+
 // extractTar extracts a tar archive
 func (e *Extractor) extractTar(archivePath, destDir string) error {
 	file, err := os.Open(archivePath)
@@ -101,9 +175,17 @@ func (e *Extractor) extractTar(archivePath, destDir string) error {
 	return e.extractTarReader(file, destDir)
 }
 
+// extractedDir is a directory whose modification time is restored once its
+// contents are extracted
+type extractedDir struct {
+	path    string
+	modTime time.Time
+}
+
 // extractTarReader extracts from a tar reader
 func (e *Extractor) extractTarReader(r io.Reader, destDir string) error {
 	tarReader := tar.NewReader(r)
+	var dirs []extractedDir
 
 	for {
 		header, err := tarReader.Next()
@@ -116,7 +198,7 @@ func (e *Extractor) extractTarReader(r io.Reader, destDir string) error {
 
 		// Apply strip components
 		path := e.stripPath(header.Name)
-		if path == "" {
+		if path == "" || !e.filter.Match(path) {
 			continue
 		}
 
@@ -126,13 +208,25 @@ func (e *Extractor) extractTarReader(r io.Reader, destDir string) error {
 			return fmt.Errorf("tar entry %q: %w", header.Name, err)
 		}
 
+		// FileInfo maps the setuid, setgid and sticky bits of tar modes, which
+		// are left out; only permissions are kept
+		perm := header.FileInfo().Mode().Perm()
 		switch header.Typeflag {
 		case tar.TypeDir:
-			if err := os.MkdirAll(targetPath, os.FileMode(header.Mode)); err != nil {
+			// Directories stay writable so that their contents can be extracted
+			if err := os.MkdirAll(targetPath, perm|0700); err != nil {
 				return fmt.Errorf("failed to create directory: %w", err)
 			}
+			dirs = append(dirs, extractedDir{path: targetPath, modTime: header.ModTime})
 		case tar.TypeReg:
-			if err := e.extractTarFile(tarReader, targetPath, os.FileMode(header.Mode)); err != nil {
+			if err := e.extractTarFile(tarReader, targetPath, perm); err != nil {
+				return err
+			}
+			if err := e.setModTime(targetPath, header.ModTime); err != nil {
+				return err
+			}
+		case tar.TypeLink:
+			if err := e.extractTarHardLink(header, targetPath, destDir); err != nil {
 				return err
 			}
 		case tar.TypeSymlink:
@@ -152,6 +246,65 @@ func (e *Extractor) extractTarReader(r io.Reader, destDir string) error {
 		}
 	}
 
+	// Extracting files into a directory changes its modification time, so
+	// directories are restored last, innermost first
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := e.setModTime(dirs[i].path, dirs[i].modTime); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// extractTarHardLink links targetPath to a file extracted earlier from the
+// same archive
+func (e *Extractor) extractTarHardLink(header *tar.Header, targetPath, destDir string) error {
+	linkname := e.stripPath(header.Linkname)
+	if linkname == "" {
+		return fmt.Errorf("tar entry %q: hard link target %q is removed by strip_components", header.Name, header.Linkname)
+	}
+	sourcePath, err := securePath(linkname, destDir)
+	if err != nil {
+		return fmt.Errorf("tar entry %q: invalid hard link target: %w", header.Name, err)
+	}
+	info, err := os.Lstat(sourcePath)
+	if err != nil {
+		return fmt.Errorf("tar entry %q: hard link target %q was not extracted: %w", header.Name, header.Linkname, err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("tar entry %q: hard link target %q is not a regular file", header.Name, header.Linkname)
+	}
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+		return fmt.Errorf("failed to create parent directory for hard link: %w", err)
+	}
+	if err := removeExisting(targetPath); err != nil {
+		return err
+	}
+	if err := os.Link(sourcePath, targetPath); err != nil {
+		return fmt.Errorf("failed to create hard link: %w", err)
+	}
+	return nil
+}
+
+// setModTime sets the modification time of an extracted file when the
+// extractor preserves them
+func (e *Extractor) setModTime(path string, modTime time.Time) error {
+	if !e.modTimes || modTime.IsZero() {
+		return nil
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		return fmt.Errorf("failed to set modification time: %w", err)
+	}
+	return nil
+}
+
+// removeExisting removes a file left at path by an earlier archive entry, so
+// that the new entry gets its own mode and is never written through a symlink
+func removeExisting(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
 	return nil
 }
 
@@ -161,8 +314,11 @@ func (e *Extractor) extractTarFile(tarReader *tar.Reader, destPath string, mode
 	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
 		return fmt.Errorf("failed to create parent directory: %w", err)
 	}
+	if err := removeExisting(destPath); err != nil {
+		return err
+	}
 
-	file, err := os.OpenFile(destPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	file, err := os.OpenFile(destPath, os.O_CREATE|os.O_WRONLY|os.O_EXCL, mode)
 	if err != nil {
 		return fmt.Errorf("failed to create file: %w", err)
 	}
@@ -183,10 +339,11 @@ func (e *Extractor) extractZip(archivePath, destDir string) error {
 	}
 	defer reader.Close()
 
+	var dirs []extractedDir
 	for _, file := range reader.File {
 		// Apply strip components
 		path := e.stripPath(file.Name)
-		if path == "" {
+		if path == "" || !e.filter.Match(path) {
 			continue
 		}
 
@@ -200,9 +357,10 @@ func (e *Extractor) extractZip(archivePath, destDir string) error {
 		mode := file.FileInfo().Mode()
 
 		if mode.IsDir() {
-			if err := os.MkdirAll(targetPath, file.Mode()); err != nil {
+			if err := os.MkdirAll(targetPath, mode.Perm()|0700); err != nil {
 				return fmt.Errorf("failed to create directory: %w", err)
 			}
+			dirs = append(dirs, extractedDir{path: targetPath, modTime: file.Modified})
 			continue
 		}
 
@@ -217,6 +375,15 @@ func (e *Extractor) extractZip(archivePath, destDir string) error {
 		if err := e.extractZipFile(file, targetPath); err != nil {
 			return err
 		}
+		if err := e.setModTime(targetPath, file.Modified); err != nil {
+			return err
+		}
+	}
+
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := e.setModTime(dirs[i].path, dirs[i].modTime); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -235,7 +402,11 @@ func (e *Extractor) extractZipFile(file *zip.File, destPath string) error {
 	}
 	defer srcFile.Close()
 
-	dstFile, err := os.OpenFile(destPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, file.Mode())
+	if err := removeExisting(destPath); err != nil {
+		return err
+	}
+
+	dstFile, err := os.OpenFile(destPath, os.O_CREATE|os.O_WRONLY|os.O_EXCL, file.Mode().Perm())
 	if err != nil {
 		return fmt.Errorf("failed to create file: %w", err)
 	}
@@ -321,6 +492,74 @@ func (e *Extractor) extractXz(archivePath, destDir string) error {
 	return nil
 }
 
+// extractZst extracts a plain zstd file (not tar.zst)
+func (e *Extractor) extractZst(archivePath, destDir string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	zstdReader, err := newZstdReader(file)
+	if err != nil {
+		return fmt.Errorf("failed to create zstd reader: %w", err)
+	}
+	defer zstdReader.Close()
+
+	// Ensure destination directory exists
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	// Extract to a file with .zst extension removed
+	baseName := filepath.Base(archivePath)
+	baseName = strings.TrimSuffix(baseName, ".zst")
+
+	destPath := filepath.Join(destDir, baseName)
+	destFile, err := os.OpenFile(destPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create destination file: %w", err)
+	}
+	defer destFile.Close()
+
+	if _, err := io.Copy(destFile, zstdReader); err != nil {
+		return fmt.Errorf("failed to decompress file: %w", err)
+	}
+
+	return nil
+}
+
+// extractBz2 extracts a plain bzip2 file (not tar.bz2)
+func (e *Extractor) extractBz2(archivePath, destDir string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	// Ensure destination directory exists
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	// Extract to a file with .bz2 extension removed
+	baseName := filepath.Base(archivePath)
+	baseName = strings.TrimSuffix(baseName, ".bz2")
+
+	destPath := filepath.Join(destDir, baseName)
+	destFile, err := os.OpenFile(destPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create destination file: %w", err)
+	}
+	defer destFile.Close()
+
+	if _, err := io.Copy(destFile, bzip2.NewReader(file)); err != nil {
+		return fmt.Errorf("failed to decompress file: %w", err)
+	}
+
+	return nil
+}
+
 // copyFile copies a file to the destination directory
 func (e *Extractor) copyFile(srcPath, destDir string) error {
 	srcFile, err := os.Open(srcPath)
@@ -0,0 +1,98 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// FuzzSecurePath checks that securePath never returns a path outside destDir.
+func FuzzSecurePath(f *testing.F) {
+	f.Add("bin/tool")
+	f.Add("../etc/passwd")
+	f.Add("/etc/passwd")
+	f.Add("a/../../b")
+	f.Add("./a/./b/")
+	f.Add("..")
+
+	destDir := f.TempDir()
+	f.Fuzz(func(t *testing.T, path string) {
+		target, err := securePath(path, destDir)
+		if err != nil {
+			return
+		}
+		rel, err := filepath.Rel(destDir, target)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			t.Fatalf("securePath(%q) = %q escapes %q", path, target, destDir)
+		}
+	})
+}
+
+// FuzzStripPath checks that stripPath never produces a path with more
+// components than its input.
+func FuzzStripPath(f *testing.F) {
+	f.Add("mytool-v1.0.0/bin/mytool", 1)
+	f.Add("/abs/path", 2)
+	f.Add("a//b/../c", 1)
+	f.Add("", 3)
+
+	f.Fuzz(func(t *testing.T, path string, strip int) {
+		if strip < 0 || strip > 16 {
+			return
+		}
+		e := NewExtractor(strip)
+		got := e.stripPath(path)
+		if strip == 0 {
+			if got != path {
+				t.Fatalf("stripPath(%q, 0) = %q, want input unchanged", path, got)
+			}
+			return
+		}
+		if got == "" {
+			return
+		}
+		if len(strings.Split(got, string(filepath.Separator))) > len(strings.Split(filepath.Clean(path), string(filepath.Separator))) {
+			t.Fatalf("stripPath(%q, %d) = %q has more components than input", path, strip, got)
+		}
+	})
+}
+
+// FuzzExtractTar feeds arbitrary tar streams to the extractor and checks that
+// nothing is written outside the destination directory.
+func FuzzExtractTar(f *testing.F) {
+	for _, name := range []string{"bin/tool", "../escape", "/abs", "dir/../../escape"} {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		_ = tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: 4, Typeflag: tar.TypeReg})
+		_, _ = tw.Write([]byte("test"))
+		_ = tw.Close()
+		f.Add(buf.Bytes(), 0)
+	}
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	_ = tw.WriteHeader(&tar.Header{Name: "link", Linkname: "../../etc/passwd", Typeflag: tar.TypeSymlink})
+	_ = tw.Close()
+	f.Add(buf.Bytes(), 0)
+
+	f.Fuzz(func(t *testing.T, data []byte, strip int) {
+		if strip < 0 || strip > 4 {
+			return
+		}
+		root := t.TempDir()
+		destDir := filepath.Join(root, "dest")
+		_ = NewExtractor(strip).extractTarReader(bytes.NewReader(data), destDir)
+
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, entry := range entries {
+			if entry.Name() != "dest" {
+				t.Fatalf("extraction wrote %q outside destination directory", entry.Name())
+			}
+		}
+	})
+}
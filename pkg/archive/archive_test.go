@@ -3,11 +3,17 @@ package archive
 import (
 	"archive/tar"
 	"archive/zip"
+	"bytes"
 	"compress/gzip"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/dsnet/compress/bzip2"
+	grarchive "github.com/goreleaser/goreleaser/v2/pkg/archive"
+	"github.com/goreleaser/goreleaser/v2/pkg/config"
+	"github.com/klauspost/compress/zstd"
 	"github.com/ulikunitz/xz"
 )
 
@@ -442,6 +448,150 @@ func TestExtractPlainXz(t *testing.T) {
 	}
 }
 
+func TestExtractPlainZst(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	zstPath := filepath.Join(tmpDir, "binary.zst")
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(zstPath, encoder.EncodeAll([]byte("binary content"), nil), 0644); err != nil {
+		t.Fatalf("Failed to create test zst: %v", err)
+	}
+
+	extractor := NewExtractor(0)
+	destDir := filepath.Join(tmpDir, "extracted")
+	if err := extractor.Extract(zstPath, destDir); err != nil {
+		t.Fatalf("Failed to extract zst: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(destDir, "binary"))
+	if err != nil {
+		t.Fatalf("Failed to read extracted file: %v", err)
+	}
+	if string(content) != "binary content" {
+		t.Errorf("Expected content 'binary content', got '%s'", string(content))
+	}
+
+	entries, err := List(zstPath)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name != "binary" {
+		t.Errorf("List() = %+v, want the single entry binary", entries)
+	}
+}
+
+func TestListTarZst(t *testing.T) {
+	tmpDir := t.TempDir()
+	archivePath := filepath.Join(tmpDir, "tool.tar.zst")
+	createGoReleaserArchive(t, archivePath, "tar.zst", map[string]os.FileMode{"tool": 0755}, time.Now())
+	if !IsArchive(archivePath) {
+		t.Error("IsArchive() = false for a tar.zst archive")
+	}
+	entries, err := List(archivePath)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name != "tool_1.0.0_linux_amd64/tool" {
+		t.Errorf("List() = %+v, want tool_1.0.0_linux_amd64/tool", entries)
+	}
+}
+
+func TestExtractTarBz2(t *testing.T) {
+	for _, name := range []string{"tool.tar.bz2", "tool.tbz2", "tool.tbz"} {
+		t.Run(name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			var tarData bytes.Buffer
+			tarWriter := tar.NewWriter(&tarData)
+			content := "binary content"
+			if err := tarWriter.WriteHeader(&tar.Header{Name: "root/bin/tool", Mode: 0755, Size: int64(len(content))}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tarWriter.Write([]byte(content)); err != nil {
+				t.Fatal(err)
+			}
+			if err := tarWriter.Close(); err != nil {
+				t.Fatal(err)
+			}
+			archivePath := filepath.Join(tmpDir, name)
+			writeTestBz2(t, archivePath, tarData.Bytes())
+			if !IsArchive(archivePath) {
+				t.Errorf("IsArchive() = false for %s", name)
+			}
+
+			destDir := filepath.Join(tmpDir, "extracted")
+			if err := NewExtractor(1).Extract(archivePath, destDir); err != nil {
+				t.Fatalf("Failed to extract %s: %v", name, err)
+			}
+			got, err := os.ReadFile(filepath.Join(destDir, "bin", "tool"))
+			if err != nil {
+				t.Fatalf("Failed to read extracted file: %v", err)
+			}
+			if string(got) != content {
+				t.Errorf("Expected content %q, got %q", content, got)
+			}
+
+			entries, err := List(archivePath)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if len(entries) != 1 || entries[0].Name != "root/bin/tool" {
+				t.Errorf("List() = %+v, want root/bin/tool", entries)
+			}
+		})
+	}
+}
+
+func TestExtractPlainBz2(t *testing.T) {
+	tmpDir := t.TempDir()
+	bz2Path := filepath.Join(tmpDir, "binary.bz2")
+	writeTestBz2(t, bz2Path, []byte("binary content"))
+	if IsArchive(bz2Path) {
+		t.Error("IsArchive() = true for a plain bzip2 file")
+	}
+
+	destDir := filepath.Join(tmpDir, "extracted")
+	if err := NewExtractor(0).Extract(bz2Path, destDir); err != nil {
+		t.Fatalf("Failed to extract bz2: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(destDir, "binary"))
+	if err != nil {
+		t.Fatalf("Failed to read extracted file: %v", err)
+	}
+	if string(content) != "binary content" {
+		t.Errorf("Expected content 'binary content', got '%s'", string(content))
+	}
+
+	entries, err := List(bz2Path)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name != "binary" {
+		t.Errorf("List() = %+v, want the single entry binary", entries)
+	}
+}
+
+// writeTestBz2 writes data compressed with bzip2 to path
+func writeTestBz2(t *testing.T, path string, data []byte) {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := bzip2.NewWriter(&buf, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func createTestTarXz(path string) error {
 	file, err := os.Create(path)
 	if err != nil {
@@ -538,3 +688,196 @@ func createTestPlainXz(path string, content string) error {
 	_, err = xzWriter.Write([]byte(content))
 	return err
 }
+
+// createGoReleaserArchive packages files the way a GoReleaser archives pipe
+// does, wrapped in a directory, with the given modes and modification time
+func createGoReleaserArchive(t *testing.T, path, format string, modes map[string]os.FileMode, mtime time.Time) {
+	t.Helper()
+	srcDir := t.TempDir()
+	out, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	a, err := grarchive.New(out, format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, mode := range modes {
+		src := filepath.Join(srcDir, filepath.Base(name))
+		if err := os.WriteFile(src, []byte(name), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := a.Add(config.File{
+			Source:      src,
+			Destination: "tool_1.0.0_linux_amd64/" + name,
+			Info:        config.FileInfo{Mode: mode, ParsedMTime: mtime},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExtractGoReleaserArchives(t *testing.T) {
+	modes := map[string]os.FileMode{
+		"tool":           0755,
+		"README.md":      0644,
+		"completions/sh": 0600,
+	}
+	mtime := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, format := range []string{"tar.gz", "tar.xz", "tar.zst", "tar", "zip"} {
+		t.Run(format, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			archivePath := filepath.Join(tmpDir, "tool."+format)
+			createGoReleaserArchive(t, archivePath, format, modes, mtime)
+
+			destDir := filepath.Join(tmpDir, "extracted")
+			if err := NewExtractor(1).WithModTimes(true).Extract(archivePath, destDir); err != nil {
+				t.Fatalf("Failed to extract: %v", err)
+			}
+			for name, mode := range modes {
+				info, err := os.Stat(filepath.Join(destDir, name))
+				if err != nil {
+					t.Fatalf("Expected file %s not found: %v", name, err)
+				}
+				// The umask of the test process may clear group and other bits
+				if got := info.Mode().Perm(); got&0700 != mode&0700 || got&^mode != 0 {
+					t.Errorf("%s mode = %v, want %v", name, got, mode)
+				}
+				if !info.ModTime().Equal(mtime) {
+					t.Errorf("%s mtime = %v, want %v", name, info.ModTime(), mtime)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractTarHardLinks(t *testing.T) {
+	tmpDir := t.TempDir()
+	tarPath := filepath.Join(tmpDir, "links.tar")
+	file, err := os.Create(tarPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tw := tar.NewWriter(file)
+	content := "binary"
+	entries := []*tar.Header{
+		{Name: "root/bin/tool", Typeflag: tar.TypeReg, Mode: 0755, Size: int64(len(content))},
+		{Name: "root/bin/tool-alias", Typeflag: tar.TypeLink, Linkname: "root/bin/tool"},
+	}
+	for _, header := range entries {
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			tw.Write([]byte(content))
+		}
+	}
+	tw.Close()
+	file.Close()
+
+	destDir := filepath.Join(tmpDir, "extracted")
+	if err := NewExtractor(1).Extract(tarPath, destDir); err != nil {
+		t.Fatalf("Failed to extract: %v", err)
+	}
+	original, err := os.Stat(filepath.Join(destDir, "bin/tool"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	alias, err := os.Stat(filepath.Join(destDir, "bin/tool-alias"))
+	if err != nil {
+		t.Fatalf("Hard link not extracted: %v", err)
+	}
+	if !os.SameFile(original, alias) {
+		t.Error("bin/tool-alias should be a hard link to bin/tool")
+	}
+	if alias.Mode().Perm()&0100 == 0 {
+		t.Errorf("bin/tool-alias mode = %v, want executable", alias.Mode())
+	}
+
+	// Hard links to files that were not extracted are rejected
+	filter, err := NewFilter(nil, []string{"bin/tool"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = NewExtractor(1).WithFilter(filter).Extract(tarPath, filepath.Join(tmpDir, "filtered"))
+	if err == nil {
+		t.Error("Extract() expected error for a hard link to a file that was not extracted")
+	}
+}
+
+func TestExtractTarModTimes(t *testing.T) {
+	tmpDir := t.TempDir()
+	tarPath := filepath.Join(tmpDir, "mtime.tar")
+	file, err := os.Create(tarPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Date(2025, 1, 2, 3, 4, 5, 123456789, time.UTC)
+	tw := tar.NewWriter(file)
+	// Sub-second modification times need PAX headers
+	for _, header := range []*tar.Header{
+		{Name: "dir/", Typeflag: tar.TypeDir, Mode: 0755, ModTime: mtime, Format: tar.FormatPAX},
+		{Name: "dir/file", Typeflag: tar.TypeReg, Mode: 0644, ModTime: mtime, Format: tar.FormatPAX},
+	} {
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tw.Close()
+	file.Close()
+
+	for _, preserve := range []bool{true, false} {
+		destDir := t.TempDir()
+		if err := NewExtractor(0).WithModTimes(preserve).Extract(tarPath, destDir); err != nil {
+			t.Fatalf("Failed to extract: %v", err)
+		}
+		for _, name := range []string{"dir", "dir/file"} {
+			info, err := os.Stat(filepath.Join(destDir, name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := info.ModTime().Equal(mtime); got != preserve {
+				t.Errorf("WithModTimes(%v): %s mtime = %v, archive has %v", preserve, name, info.ModTime(), mtime)
+			}
+		}
+	}
+}
+
+func TestExtractReplacesExistingFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	tarPath := filepath.Join(tmpDir, "replace.tar")
+	file, err := os.Create(tarPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tw := tar.NewWriter(file)
+	for _, header := range []*tar.Header{
+		{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "dir/../file"},
+		{Name: "link", Typeflag: tar.TypeReg, Mode: 0755, Size: 3},
+	} {
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			tw.Write([]byte("new"))
+		}
+	}
+	tw.Close()
+	file.Close()
+
+	destDir := filepath.Join(tmpDir, "extracted")
+	if err := NewExtractor(0).Extract(tarPath, destDir); err != nil {
+		t.Fatalf("Failed to extract: %v", err)
+	}
+	info, err := os.Lstat(filepath.Join(destDir, "link"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Mode().IsRegular() || info.Mode().Perm()&0100 == 0 {
+		t.Errorf("link mode = %v, want an executable regular file", info.Mode())
+	}
+}
@@ -0,0 +1,86 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFilterMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		path    string
+		want    bool
+	}{
+		{name: "no patterns", path: "any/file", want: true},
+		{name: "include file", include: []string{"bin/tool"}, path: "bin/tool", want: true},
+		{name: "include other file", include: []string{"bin/tool"}, path: "bin/other", want: false},
+		{name: "star crosses slash", include: []string{"*/tool"}, path: "a/b/tool", want: true},
+		{name: "include directory", include: []string{"share/man"}, path: "share/man/man1/tool.1", want: true},
+		{name: "include directory with trailing slash", include: []string{"share/"}, path: "share/tool/data", want: true},
+		{name: "include prefix is not a directory", include: []string{"share"}, path: "shared/data", want: false},
+		{name: "question mark", include: []string{"tool?"}, path: "tool2", want: true},
+		{name: "character class", include: []string{"lib/[!.]*"}, path: "lib/.hidden", want: false},
+		{name: "exclude", exclude: []string{"*.md"}, path: "docs/README.md", want: false},
+		{name: "exclude wins over include", include: []string{"share"}, exclude: []string{"share/doc"}, path: "share/doc/guide.html", want: false},
+		{name: "dot slash path", include: []string{"bin/*"}, path: "./bin/tool", want: true},
+		{name: "dot is literal", include: []string{"tool.1"}, path: "tool-1", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := NewFilter(tt.include, tt.exclude)
+			if err != nil {
+				t.Fatalf("NewFilter() error = %v", err)
+			}
+			if got := filter.Match(tt.path); got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewFilter([]string{"bin/[abc"}, nil); err == nil {
+		t.Error("NewFilter() expected error for unterminated character class")
+	}
+}
+
+func TestExtractWithFilter(t *testing.T) {
+	tmpDir := t.TempDir()
+	tarGzPath := filepath.Join(tmpDir, "test.tar.gz")
+	if err := createTestTarGzNested(tarGzPath); err != nil {
+		t.Fatalf("Failed to create test tar.gz: %v", err)
+	}
+	zipPath := filepath.Join(tmpDir, "test.zip")
+	if err := createTestZip(zipPath); err != nil {
+		t.Fatalf("Failed to create test zip: %v", err)
+	}
+
+	for _, tt := range []struct {
+		archive         string
+		stripComponents int
+	}{
+		{archive: tarGzPath, stripComponents: 1},
+		{archive: zipPath, stripComponents: 0},
+	} {
+		t.Run(filepath.Base(tt.archive), func(t *testing.T) {
+			filter, err := NewFilter([]string{"dir1"}, []string{"*/file2.txt"})
+			if err != nil {
+				t.Fatalf("NewFilter() error = %v", err)
+			}
+			destDir := filepath.Join(t.TempDir(), "extracted")
+			if err := NewExtractor(tt.stripComponents).WithFilter(filter).Extract(tt.archive, destDir); err != nil {
+				t.Fatalf("Failed to extract: %v", err)
+			}
+			for path, want := range map[string]bool{
+				"dir1/file1.txt": true,
+				"dir1/file2.txt": false,
+				"file3.txt":      false,
+			} {
+				if _, err := os.Stat(filepath.Join(destDir, path)); (err == nil) != want {
+					t.Errorf("%s extracted = %v, want %v", path, err == nil, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ulikunitz/xz"
+)
+
+// Entry is a file of an archive
+type Entry struct {
+	// Name is the path of the file in the archive
+	Name string
+	Size int64
+	Mode os.FileMode
+	// Linkname is the target of symlinks and hard links
+	Linkname string
+	// HardLink reports whether the entry is a hard link to Linkname
+	HardLink bool
+}
+
+// IsArchive reports whether a file is a tar, zip or 7z archive, whose entries
+// Extract extracts with strip components and the filter applied
+func IsArchive(path string) bool {
+	name := strings.ToLower(path)
+	for _, ext := range []string{".tar.gz", ".tgz", ".tar.xz", ".tar.zst", ".tzst", ".tar.bz2", ".tbz2", ".tbz", ".tar", ".zip", ".7z"} {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// List returns the entries of an archive without extracting it. Files that
+// Extract decompresses or copies as is are listed as a single entry.
+func List(archivePath string) ([]Entry, error) {
+	name := strings.ToLower(archivePath)
+	switch {
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		file, err := os.Open(archivePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open archive: %w", err)
+		}
+		defer file.Close()
+		gzReader, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gzip reader: %w", err)
+		}
+		defer gzReader.Close()
+		return listTar(gzReader)
+	case strings.HasSuffix(name, ".tar.xz"):
+		file, err := os.Open(archivePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open archive: %w", err)
+		}
+		defer file.Close()
+		xzReader, err := xz.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create xz reader: %w", err)
+		}
+		return listTar(xzReader)
+	case strings.HasSuffix(name, ".tar.zst"), strings.HasSuffix(name, ".tzst"):
+		file, err := os.Open(archivePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open archive: %w", err)
+		}
+		defer file.Close()
+		zstdReader, err := newZstdReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create zstd reader: %w", err)
+		}
+		defer zstdReader.Close()
+		return listTar(zstdReader)
+	case strings.HasSuffix(name, ".tar.bz2"), strings.HasSuffix(name, ".tbz2"), strings.HasSuffix(name, ".tbz"):
+		file, err := os.Open(archivePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open archive: %w", err)
+		}
+		defer file.Close()
+		return listTar(bzip2.NewReader(file))
+	case strings.HasSuffix(name, ".tar"):
+		file, err := os.Open(archivePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open archive: %w", err)
+		}
+		defer file.Close()
+		return listTar(file)
+	case strings.HasSuffix(name, ".zip"):
+		return listZip(archivePath)
+	case strings.HasSuffix(name, ".7z"):
+		return list7z(archivePath)
+	}
+
+	// Plain gzip, xz, zstd and bzip2 files are decompressed, anything else is copied
+	info, err := os.Stat(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	base := filepath.Base(archivePath)
+	size := info.Size()
+	switch filepath.Ext(name) {
+	case ".gz":
+		base = strings.TrimSuffix(base, filepath.Ext(base))
+		size = -1
+	case ".xz", ".zst", ".bz2":
+		base = strings.TrimSuffix(base, filepath.Ext(base))
+		size = -1
+	}
+	return []Entry{{Name: base, Size: size, Mode: 0755}}, nil
+}
+
+// listTar returns the entries of a tar stream
+func listTar(r io.Reader) ([]Entry, error) {
+	tarReader := tar.NewReader(r)
+	var entries []Entry
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tar header: %w", err)
+		}
+		entries = append(entries, Entry{
+			Name:     header.Name,
+			Size:     header.Size,
+			Mode:     header.FileInfo().Mode(),
+			Linkname: header.Linkname,
+			HardLink: header.Typeflag == tar.TypeLink,
+		})
+	}
+	return entries, nil
+}
+
+// listZip returns the entries of a zip archive
+func listZip(archivePath string) ([]Entry, error) {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zip archive: %w", err)
+	}
+	defer reader.Close()
+	entries := make([]Entry, 0, len(reader.File))
+	for _, file := range reader.File {
+		entries = append(entries, Entry{
+			Name: file.Name,
+			Size: int64(file.UncompressedSize64),
+			Mode: file.Mode(),
+		})
+	}
+	return entries, nil
+}
+
+// Target returns the path an archive entry is extracted to, relative to the
+// destination directory, or "" when strip components or the filter skip it
+func (e *Extractor) Target(name string) string {
+	path := e.stripPath(name)
+	if path == "" || !e.filter.Match(path) {
+		return ""
+	}
+	return filepath.Clean(path)
+}
@@ -0,0 +1,150 @@
+package archive
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/bodgit/sevenzip"
+)
+
+// extract7z extracts a 7-Zip archive
+func (e *Extractor) extract7z(archivePath, destDir string) error {
+	reader, err := sevenzip.OpenReader(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open 7z archive: %w", err)
+	}
+	defer reader.Close()
+
+	var dirs []extractedDir
+	for _, file := range reader.File {
+		// Apply strip components
+		path := e.stripPath(file.Name)
+		if path == "" || !e.filter.Match(path) {
+			continue
+		}
+
+		// Validate and secure the target path
+		targetPath, err := securePath(path, destDir)
+		if err != nil {
+			return fmt.Errorf("7z entry %q: %w", file.Name, err)
+		}
+
+		mode := file.Mode()
+		if mode.IsDir() {
+			if err := os.MkdirAll(targetPath, mode.Perm()|0700); err != nil {
+				return fmt.Errorf("failed to create directory: %w", err)
+			}
+			dirs = append(dirs, extractedDir{path: targetPath, modTime: file.Modified})
+			continue
+		}
+
+		if mode&os.ModeSymlink != 0 {
+			if err := e.extract7zSymlink(file, targetPath, destDir); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := e.extract7zFile(file, targetPath); err != nil {
+			return err
+		}
+		if err := e.setModTime(targetPath, file.Modified); err != nil {
+			return err
+		}
+	}
+
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := e.setModTime(dirs[i].path, dirs[i].modTime); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// extract7zFile extracts a single file from a 7z archive. Archives created on
+// Windows carry no permission bits, so their files are made executable like
+// the binaries they usually are.
+func (e *Extractor) extract7zFile(file *sevenzip.File, destPath string) error {
+	// Ensure parent directory exists
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return fmt.Errorf("failed to create parent directory: %w", err)
+	}
+
+	srcFile, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open file in 7z archive: %w", err)
+	}
+	defer srcFile.Close()
+
+	if err := removeExisting(destPath); err != nil {
+		return err
+	}
+
+	perm := file.Mode().Perm()
+	if file.Attributes&0xf0000000 == 0 {
+		perm = 0755
+	}
+	dstFile, err := os.OpenFile(destPath, os.O_CREATE|os.O_WRONLY|os.O_EXCL, perm)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer dstFile.Close()
+
+	if _, err := io.Copy(dstFile, srcFile); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
+
+// extract7zSymlink creates a symlink stored in a 7z archive, whose content is
+// the link target
+func (e *Extractor) extract7zSymlink(file *sevenzip.File, targetPath, destDir string) error {
+	srcFile, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open symlink in 7z archive: %w", err)
+	}
+	defer srcFile.Close()
+
+	linkTargetBytes, err := io.ReadAll(srcFile)
+	if err != nil {
+		return fmt.Errorf("failed to read symlink target: %w", err)
+	}
+	linkTarget := string(linkTargetBytes)
+
+	// Validate the symlink before creating it
+	if err := validateSymlink(targetPath, linkTarget, destDir); err != nil {
+		return fmt.Errorf("7z entry %q: %w", file.Name, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+		return fmt.Errorf("failed to create parent directory for symlink: %w", err)
+	}
+
+	if err := os.Symlink(linkTarget, targetPath); err != nil {
+		return fmt.Errorf("failed to create symlink: %w", err)
+	}
+
+	return nil
+}
+
+// list7z returns the entries of a 7z archive
+func list7z(archivePath string) ([]Entry, error) {
+	reader, err := sevenzip.OpenReader(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open 7z archive: %w", err)
+	}
+	defer reader.Close()
+	entries := make([]Entry, 0, len(reader.File))
+	for _, file := range reader.File {
+		entries = append(entries, Entry{
+			Name: file.Name,
+			Size: int64(file.UncompressedSize),
+			Mode: file.Mode(),
+		})
+	}
+	return entries, nil
+}
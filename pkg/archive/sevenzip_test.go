@@ -0,0 +1,198 @@
+package archive
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"unicode/utf16"
+)
+
+// sevenZipEntry is a member of an archive written by writeTest7z
+type sevenZipEntry struct {
+	name       string
+	attributes uint32
+	content    string
+}
+
+// unix7zAttributes returns the attributes 7-Zip stores for a file with the
+// POSIX mode of a Unix file type
+func unix7zAttributes(mode uint32) uint32 {
+	return mode<<16 | 0x8000
+}
+
+// writeTest7z writes a 7z archive of entries to path. The file data is stored
+// with the copy method in a single folder; entries without content are
+// directories.
+func writeTest7z(t *testing.T, path string, entries []sevenZipEntry) {
+	t.Helper()
+	var data bytes.Buffer
+	var sizes []uint64
+	var emptyStreams []bool
+	hasEmpty := false
+	for _, entry := range entries {
+		empty := entry.content == ""
+		emptyStreams = append(emptyStreams, empty)
+		if empty {
+			hasEmpty = true
+			continue
+		}
+		data.WriteString(entry.content)
+		sizes = append(sizes, uint64(len(entry.content)))
+	}
+
+	var h bytes.Buffer
+	number := func(v uint64) {
+		n := 0
+		for n < 8 && v >= 1<<(7*(n+1)) {
+			n++
+		}
+		first := byte(0xff << (8 - n))
+		if n < 8 {
+			first |= byte(v >> (8 * n))
+		}
+		h.WriteByte(first)
+		for i := 0; i < n; i++ {
+			h.WriteByte(byte(v >> (8 * i)))
+		}
+	}
+
+	h.WriteByte(0x01) // Header
+	h.WriteByte(0x04) // MainStreamsInfo
+	h.Write([]byte{0x06, 0x00, 0x01, 0x09})
+	number(uint64(data.Len()))
+	h.WriteByte(0x00)
+	// One folder with a single copy coder
+	h.Write([]byte{0x07, 0x0b, 0x01, 0x00, 0x01, 0x01, 0x00, 0x0c})
+	number(uint64(data.Len()))
+	h.WriteByte(0x00)
+	h.Write([]byte{0x08, 0x0d})
+	number(uint64(len(sizes)))
+	if len(sizes) > 1 {
+		h.WriteByte(0x09)
+		for _, size := range sizes[:len(sizes)-1] {
+			number(size)
+		}
+	}
+	h.Write([]byte{0x00, 0x00})
+
+	h.WriteByte(0x05) // FilesInfo
+	number(uint64(len(entries)))
+	if hasEmpty {
+		bits := make([]byte, (len(entries)+7)/8)
+		for i, empty := range emptyStreams {
+			if empty {
+				bits[i/8] |= 0x80 >> (i % 8)
+			}
+		}
+		h.WriteByte(0x0e)
+		number(uint64(len(bits)))
+		h.Write(bits)
+	}
+	var names bytes.Buffer
+	names.WriteByte(0x00)
+	for _, entry := range entries {
+		for _, c := range utf16.Encode([]rune(entry.name + "\x00")) {
+			binary.Write(&names, binary.LittleEndian, c)
+		}
+	}
+	h.WriteByte(0x11)
+	number(uint64(names.Len()))
+	h.Write(names.Bytes())
+	h.WriteByte(0x15)
+	number(uint64(2 + 4*len(entries)))
+	h.Write([]byte{0x01, 0x00})
+	for _, entry := range entries {
+		binary.Write(&h, binary.LittleEndian, entry.attributes)
+	}
+	h.Write([]byte{0x00, 0x00})
+
+	var start bytes.Buffer
+	binary.Write(&start, binary.LittleEndian, uint64(data.Len()))
+	binary.Write(&start, binary.LittleEndian, uint64(h.Len()))
+	binary.Write(&start, binary.LittleEndian, crc32.ChecksumIEEE(h.Bytes()))
+
+	var out bytes.Buffer
+	out.Write([]byte{'7', 'z', 0xbc, 0xaf, 0x27, 0x1c, 0x00, 0x04})
+	binary.Write(&out, binary.LittleEndian, crc32.ChecksumIEEE(start.Bytes()))
+	out.Write(start.Bytes())
+	out.Write(data.Bytes())
+	out.Write(h.Bytes())
+	if err := os.WriteFile(path, out.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExtract7z(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not preserved on windows")
+	}
+	tmpDir := t.TempDir()
+	archivePath := filepath.Join(tmpDir, "tool.7z")
+	writeTest7z(t, archivePath, []sevenZipEntry{
+		{name: "tool_1.0.0", attributes: unix7zAttributes(0o40755) | 0x10},
+		{name: "tool_1.0.0/tool", attributes: unix7zAttributes(0o100755), content: "binary"},
+		{name: "tool_1.0.0/README.md", attributes: unix7zAttributes(0o100644), content: "readme"},
+		{name: "tool_1.0.0/tool-link", attributes: unix7zAttributes(0o120777), content: "tool"},
+		// Created on Windows, without POSIX attributes
+		{name: "tool_1.0.0/tool.exe", attributes: 0x20, content: "windows binary"},
+	})
+	if !IsArchive(archivePath) {
+		t.Error("IsArchive() = false for a 7z archive")
+	}
+
+	destDir := filepath.Join(tmpDir, "extracted")
+	if err := NewExtractor(1).Extract(archivePath, destDir); err != nil {
+		t.Fatalf("Failed to extract 7z: %v", err)
+	}
+	for name, want := range map[string]struct {
+		content string
+		mode    os.FileMode
+	}{
+		"tool":      {"binary", 0755},
+		"README.md": {"readme", 0644},
+		"tool.exe":  {"windows binary", 0755},
+	} {
+		path := filepath.Join(destDir, name)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Expected file %s not found: %v", name, err)
+		}
+		if string(content) != want.content {
+			t.Errorf("%s content = %q, want %q", name, content, want.content)
+		}
+		// The umask of the test process may clear group and other bits
+		info, _ := os.Stat(path)
+		if got := info.Mode().Perm(); got&0700 != want.mode&0700 || got&^want.mode != 0 {
+			t.Errorf("%s mode = %v, want %v", name, got, want.mode)
+		}
+	}
+	if target, err := os.Readlink(filepath.Join(destDir, "tool-link")); err != nil || target != "tool" {
+		t.Errorf("tool-link = %q, %v, want a symlink to tool", target, err)
+	}
+
+	entries, err := List(archivePath)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(entries) != 5 || entries[1].Name != "tool_1.0.0/tool" || entries[1].Size != 6 {
+		t.Errorf("List() = %+v, want the 5 entries of the archive", entries)
+	}
+}
+
+func TestExtract7zRejectsTraversal(t *testing.T) {
+	tmpDir := t.TempDir()
+	archivePath := filepath.Join(tmpDir, "evil.7z")
+	writeTest7z(t, archivePath, []sevenZipEntry{
+		{name: "../evil", attributes: unix7zAttributes(0o100644), content: "evil"},
+	})
+	if err := NewExtractor(0).Extract(archivePath, filepath.Join(tmpDir, "extracted")); err == nil {
+		t.Error("Extract() should reject entries outside the destination directory")
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "evil")); !os.IsNotExist(err) {
+		t.Error("an entry was written outside the destination directory")
+	}
+}
@@ -1,8 +1,12 @@
 package asset
 
 import (
+	"context"
 	"fmt"
+	"runtime"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/binary-install/binstaller/pkg/spec"
 	"github.com/buildkite/interpolate"
@@ -12,22 +16,267 @@ import (
 type FilenameGenerator struct {
 	Spec    *spec.InstallSpec
 	Version string
+	// OSVersion is matched against when.os_version of rules, e.g. ubuntu-24.04.
+	// Rules with os_version never match when it is empty.
+	OSVersion string
+	// Concurrency is the number of platforms GenerateAll generates at once
+	// (GOMAXPROCS when zero)
+	Concurrency int
+	// ReleaseVars are the values of spec.ReleaseVariables of the version's
+	// release. Set them before generating filenames, as filenames are memoized.
+	ReleaseVars map[string]string
+
+	// candidates memoizes Candidates; nil for generators not created by
+	// NewFilenameGenerator. Copies of the generator share it.
+	candidates *sync.Map
+}
+
+// candidatesKey identifies a memoized Candidates result
+type candidatesKey struct {
+	version, osVersion, os, arch string
+}
+
+// candidatesResult is a memoized Candidates result
+type candidatesResult struct {
+	filenames []string
+	err       error
 }
 
-// NewFilenameGenerator creates a new filename generator
+// NewFilenameGenerator creates a new filename generator. Its filenames are
+// memoized, so the spec must not change while the generator is in use.
 func NewFilenameGenerator(spec *spec.InstallSpec, version string) *FilenameGenerator {
 	return &FilenameGenerator{
-		Spec:    spec,
-		Version: version,
+		Spec:       spec,
+		Version:    version,
+		candidates: &sync.Map{},
 	}
 }
 
+// Platform is an OS/Arch pair in lowercase
+type Platform struct {
+	OS   string
+	Arch string
+}
+
+// String returns the platform as os/arch
+func (p Platform) String() string {
+	return p.OS + "/" + p.Arch
+}
+
+// Platforms returns the platforms assets are generated for: supported_platforms,
+// or every OS/Arch combination without them
+func (g *FilenameGenerator) Platforms() []Platform {
+	platforms := g.Spec.SupportedPlatforms
+	if len(platforms) == 0 {
+		platforms = g.GetAllPossiblePlatforms()
+	}
+	result := make([]Platform, 0, len(platforms))
+	for _, p := range platforms {
+		osName, arch := spec.PlatformOSString(p.OS), spec.PlatformArchString(p.Arch)
+		if osName != "" && arch != "" {
+			result = append(result, Platform{OS: strings.ToLower(osName), Arch: strings.ToLower(arch)})
+		}
+	}
+	return result
+}
+
+// GenerateAll returns the Candidates of every platform of Platforms, generated
+// concurrently. Platforms whose filenames cannot be generated are left out.
+func (g *FilenameGenerator) GenerateAll(ctx context.Context) (map[Platform][]string, error) {
+	platforms := g.Platforms()
+	results := make([][]string, len(platforms))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	workers := g.Concurrency
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	for range min(workers, len(platforms)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				results[i], _ = g.Candidates(platforms[i].OS, platforms[i].Arch)
+			}
+		}()
+	}
+	var err error
+	for i := range platforms {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	all := make(map[Platform][]string, len(platforms))
+	for i, p := range platforms {
+		if len(results[i]) > 0 {
+			all[p] = results[i]
+		}
+	}
+	return all, nil
+}
+
 // GenerateFilename creates an asset filename for a specific OS and Arch
 func (g *FilenameGenerator) GenerateFilename(osInput, archInput string) (string, error) {
 	if g.Spec == nil || g.Spec.Asset == nil || spec.StringValue(g.Spec.Asset.Template) == "" {
 		return "", fmt.Errorf("asset template not defined in spec")
 	}
 
+	template, additionalVars := g.platformVars(osInput, archInput)
+
+	// Perform variable substitution in the template
+	filename, err := g.interpolateTemplate(template, additionalVars)
+	if err != nil {
+		return "", fmt.Errorf("failed to interpolate asset template: %w", err)
+	}
+
+	return filename, nil
+}
+
+// Candidates returns the asset filenames to try for a specific OS and Arch, in order:
+// the filename from the template, then the fallback templates of the last matching
+// rule that has any
+func (g *FilenameGenerator) Candidates(osInput, archInput string) ([]string, error) {
+	if g.candidates == nil {
+		return g.generateCandidates(osInput, archInput)
+	}
+	key := candidatesKey{version: g.Version, osVersion: g.OSVersion, os: osInput, arch: archInput}
+	cached, ok := g.candidates.Load(key)
+	if !ok {
+		filenames, err := g.generateCandidates(osInput, archInput)
+		cached, _ = g.candidates.LoadOrStore(key, candidatesResult{filenames: filenames, err: err})
+	}
+	result := cached.(candidatesResult)
+	return slices.Clone(result.filenames), result.err
+}
+
+// generateCandidates computes Candidates without memoization
+func (g *FilenameGenerator) generateCandidates(osInput, archInput string) ([]string, error) {
+	filename, err := g.GenerateFilename(osInput, archInput)
+	if err != nil {
+		return nil, err
+	}
+	candidates := []string{filename}
+	_, additionalVars := g.platformVars(osInput, archInput)
+	for _, template := range g.FallbackTemplates(osInput, archInput) {
+		fallback, err := g.interpolateTemplate(template, additionalVars)
+		if err != nil {
+			return nil, fmt.Errorf("failed to interpolate fallback template: %w", err)
+		}
+		if !slices.Contains(candidates, fallback) {
+			candidates = append(candidates, fallback)
+		}
+	}
+	return candidates, nil
+}
+
+// FallbackTemplates returns the fallback filename templates of a specific OS and Arch.
+// The last matching rule with fallback_templates wins.
+func (g *FilenameGenerator) FallbackTemplates(osInput, archInput string) []string {
+	if g.Spec.GetAsset() == nil {
+		return nil
+	}
+	var templates []string
+	osMatch := strings.ToLower(osInput)
+	archMatch := strings.ToLower(archInput)
+	for _, rule := range g.Spec.Asset.Rules {
+		if g.ruleMatches(rule, osMatch, archMatch) && len(rule.FallbackTemplates) > 0 {
+			templates = rule.FallbackTemplates
+		}
+	}
+	return templates
+}
+
+// Resolution describes how the asset filename of a platform was resolved
+type Resolution struct {
+	// Rules holds the indexes of the asset rules that matched, in application order
+	Rules []int
+	// Template is the asset template after applying rules
+	Template string
+	// OS, Arch, and EXT are the placeholder values after applying naming conventions and rules
+	OS   string
+	Arch string
+	EXT  string
+	// Filename is the resolved asset filename
+	Filename string
+}
+
+// Resolve resolves the asset filename for a specific OS and Arch, recording which rules applied
+func (g *FilenameGenerator) Resolve(osInput, archInput string) (*Resolution, error) {
+	filename, err := g.GenerateFilename(osInput, archInput)
+	if err != nil {
+		return nil, err
+	}
+	template, vars := g.platformVars(osInput, archInput)
+	resolution := &Resolution{
+		Template: template,
+		OS:       vars["OS"],
+		Arch:     vars["ARCH"],
+		EXT:      vars["EXT"],
+		Filename: filename,
+	}
+	osMatch := strings.ToLower(osInput)
+	archMatch := strings.ToLower(archInput)
+	for i, rule := range g.Spec.Asset.Rules {
+		if g.ruleMatches(rule, osMatch, archMatch) {
+			resolution.Rules = append(resolution.Rules, i)
+		}
+	}
+	return resolution, nil
+}
+
+// DeltaFilename creates the filename of the patch from fromTag to the generator's version
+// for a specific OS and Arch, using asset.delta.template
+func (g *FilenameGenerator) DeltaFilename(osInput, archInput, fromTag string) (string, error) {
+	template := g.Spec.GetAsset().GetDelta().GetTemplate()
+	if template == "" {
+		return "", fmt.Errorf("delta template not defined in spec")
+	}
+	assetFilename, err := g.GenerateFilename(osInput, archInput)
+	if err != nil {
+		return "", err
+	}
+
+	_, additionalVars := g.platformVars(osInput, archInput)
+	additionalVars["FROM_TAG"] = fromTag
+	additionalVars["FROM_VERSION"] = strings.TrimPrefix(fromTag, "v")
+	additionalVars["ASSET_FILENAME"] = assetFilename
+
+	filename, err := g.interpolateTemplate(template, additionalVars)
+	if err != nil {
+		return "", fmt.Errorf("failed to interpolate delta template: %w", err)
+	}
+	return filename, nil
+}
+
+// ExtraFilenames returns the filenames of asset.extra_files for a specific OS and
+// Arch, in spec order
+func (g *FilenameGenerator) ExtraFilenames(osInput, archInput string) ([]string, error) {
+	extras := g.Spec.GetAsset().ExtraFiles
+	if len(extras) == 0 {
+		return nil, nil
+	}
+	_, additionalVars := g.platformVars(osInput, archInput)
+	filenames := make([]string, 0, len(extras))
+	for _, extra := range extras {
+		filename, err := g.interpolateTemplate(extra.GetTemplate(), additionalVars)
+		if err != nil {
+			return nil, fmt.Errorf("failed to interpolate extra file template: %w", err)
+		}
+		filenames = append(filenames, filename)
+	}
+	return filenames, nil
+}
+
+// platformVars returns the asset template and the OS, ARCH, and EXT values
+// for a specific OS and Arch after applying naming conventions and rules
+func (g *FilenameGenerator) platformVars(osInput, archInput string) (string, map[string]string) {
 	// Keep original values for rule matching
 	osMatch := strings.ToLower(osInput)
 	archMatch := strings.ToLower(archInput)
@@ -49,9 +298,7 @@ func (g *FilenameGenerator) GenerateFilename(osInput, archInput string) (string,
 
 	// Check if any rule applies - use osMatch/archMatch for condition checking
 	for _, rule := range g.Spec.Asset.Rules {
-		if rule.When != nil &&
-			(spec.StringValue(rule.When.OS) == "" || spec.StringValue(rule.When.OS) == osMatch) &&
-			(spec.StringValue(rule.When.Arch) == "" || spec.StringValue(rule.When.Arch) == archMatch) {
+		if g.ruleMatches(rule, osMatch, archMatch) {
 			if spec.StringValue(rule.OS) != "" {
 				osValue = spec.StringValue(rule.OS)
 			}
@@ -68,22 +315,78 @@ func (g *FilenameGenerator) GenerateFilename(osInput, archInput string) (string,
 	}
 
 	// Asset templates support OS, ARCH, and EXT in addition to NAME and VERSION
-	additionalVars := map[string]string{
+	return template, map[string]string{
 		"OS":   osValue,
 		"ARCH": archValue,
 		"EXT":  ext,
 	}
+}
 
-	// Perform variable substitution in the template
-	filename, err := g.interpolateTemplate(template, additionalVars)
-	if err != nil {
-		return "", fmt.Errorf("failed to interpolate asset template: %w", err)
+// ChecksumTemplate returns the checksum file template for a specific OS and Arch.
+// The last matching rule with a checksum_template wins, falling back to checksums.template.
+func (g *FilenameGenerator) ChecksumTemplate(osInput, archInput string) string {
+	template := g.Spec.GetChecksums().GetTemplate()
+	if g.Spec.GetAsset() == nil {
+		return template
+	}
+	osMatch := strings.ToLower(osInput)
+	archMatch := strings.ToLower(archInput)
+	for _, rule := range g.Spec.Asset.Rules {
+		if g.ruleMatches(rule, osMatch, archMatch) && rule.GetChecksumTemplate() != "" {
+			template = rule.GetChecksumTemplate()
+		}
+	}
+	return template
+}
+
+// PossibleChecksumTemplates returns the distinct checksum file templates selected
+// by any supported platform, in first-use order
+func (g *FilenameGenerator) PossibleChecksumTemplates() []string {
+	platforms := g.Spec.SupportedPlatforms
+	if len(platforms) == 0 {
+		platforms = g.GetAllPossiblePlatforms()
 	}
 
-	return filename, nil
+	var templates []string
+	seen := make(map[string]bool)
+	for _, platform := range platforms {
+		template := g.ChecksumTemplate(spec.PlatformOSString(platform.OS), spec.PlatformArchString(platform.Arch))
+		if template != "" && !seen[template] {
+			seen[template] = true
+			templates = append(templates, template)
+		}
+	}
+	return templates
+}
+
+// Binaries returns the binaries of a specific OS and Arch.
+// The last matching rule with binaries wins, falling back to asset.binaries.
+func (g *FilenameGenerator) Binaries(osInput, archInput string) []spec.BinaryElement {
+	if g.Spec.GetAsset() == nil {
+		return nil
+	}
+	binaries := g.Spec.Asset.Binaries
+	osMatch := strings.ToLower(osInput)
+	archMatch := strings.ToLower(archInput)
+	for _, rule := range g.Spec.Asset.Rules {
+		if g.ruleMatches(rule, osMatch, archMatch) && len(rule.Binaries) > 0 {
+			binaries = rule.Binaries
+		}
+	}
+	return binaries
 }
 
-// GeneratePossibleFilenames generates all possible asset filenames based on the asset template
+// ruleMatches reports whether a rule's when condition matches the lowercase OS and Arch
+// and the generator's OS version
+func (g *FilenameGenerator) ruleMatches(rule spec.RuleElement, osMatch, archMatch string) bool {
+	return rule.When != nil &&
+		(spec.StringValue(rule.When.OS) == "" || spec.StringValue(rule.When.OS) == osMatch) &&
+		(spec.StringValue(rule.When.Arch) == "" || spec.StringValue(rule.When.Arch) == archMatch) &&
+		(spec.StringValue(rule.When.OSVersion) == "" || MatchOSVersion(spec.StringValue(rule.When.OSVersion), g.OSVersion))
+}
+
+// GeneratePossibleFilenames generates all possible asset filenames based on the asset template,
+// including the extra files
 func (g *FilenameGenerator) GeneratePossibleFilenames() map[string]bool {
 	if g.Spec == nil || g.Spec.Asset == nil || spec.StringValue(g.Spec.Asset.Template) == "" {
 		return nil
@@ -91,24 +394,32 @@ func (g *FilenameGenerator) GeneratePossibleFilenames() map[string]bool {
 
 	// Use map for O(1) lookup performance
 	filenames := make(map[string]bool)
-	var platforms []spec.Platform
 
-	// Determine which platforms to use
-	if len(g.Spec.SupportedPlatforms) > 0 {
-		platforms = g.Spec.SupportedPlatforms
-	} else {
-		// Generate all possible combinations from spec constants
-		platforms = g.GetAllPossiblePlatforms()
+	// Generate filename for each platform, once without an OS version and once
+	// for every os_version pattern so OS version-specific assets are included
+	osVersions := []string{""}
+	for _, rule := range g.Spec.Asset.Rules {
+		if pattern := rule.GetWhen().GetOSVersion(); pattern != "" && !slices.Contains(osVersions, pattern) {
+			osVersions = append(osVersions, pattern)
+		}
 	}
-
-	// Generate filename for each platform
-	for _, platform := range platforms {
-		filename, err := g.GenerateFilename(spec.PlatformOSString(platform.OS), spec.PlatformArchString(platform.Arch))
+	for _, osVersion := range osVersions {
+		generator := *g
+		generator.OSVersion = osVersion
+		all, err := generator.GenerateAll(context.Background())
 		if err != nil {
 			continue
 		}
-		if filename != "" {
-			filenames[filename] = true
+		for platform, candidates := range all {
+			extras, err := generator.ExtraFilenames(platform.OS, platform.Arch)
+			if err != nil {
+				continue
+			}
+			for _, filename := range append(candidates, extras...) {
+				if filename != "" {
+					filenames[filename] = true
+				}
+			}
 		}
 	}
 
@@ -117,6 +428,11 @@ func (g *FilenameGenerator) GeneratePossibleFilenames() map[string]bool {
 
 // GetAllPossiblePlatforms returns all possible OS/Arch combinations from spec constants
 func (g *FilenameGenerator) GetAllPossiblePlatforms() []spec.Platform {
+	return slices.Clone(allPossiblePlatforms())
+}
+
+// allPossiblePlatforms computes the OS/Arch combinations once
+var allPossiblePlatforms = sync.OnceValue(func() []spec.Platform {
 	// Get all OS and Arch values from spec constants
 	osValues := GetAllOSValues()
 	archValues := GetAllArchValues()
@@ -135,7 +451,7 @@ func (g *FilenameGenerator) GetAllPossiblePlatforms() []spec.Platform {
 	}
 
 	return platforms
-}
+})
 
 // interpolateTemplate performs variable substitution in a template string
 func (g *FilenameGenerator) interpolateTemplate(template string, additionalVars map[string]string) (string, error) {
@@ -149,6 +465,10 @@ func (g *FilenameGenerator) interpolateTemplate(template string, additionalVars
 	version := strings.TrimPrefix(g.Version, "v")
 	envMap["VERSION"] = version
 
+	for k, v := range g.ReleaseVars {
+		envMap[k] = v
+	}
+
 	// Merge additional variables (OS, ARCH, EXT for asset templates)
 	for k, v := range additionalVars {
 		envMap[k] = v
@@ -0,0 +1,81 @@
+package asset
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// Performance budget for filename generation across every known platform.
+// The checksum embedder calls GeneratePossibleFilenames once per version, so
+// this must stay cheap. Enforced by TestGeneratePossibleFilenamesBudget when
+// BINSTALLER_PERF_GATE=1 (see `make bench-gate`).
+const budgetGeneratePossibleFilenames = 10_000_000 // 10ms for all platforms with 50 rules
+
+// benchSpec returns a spec with 50 rules and no supported_platforms, so that
+// filenames are generated for every OS/Arch combination.
+func benchSpec() *spec.InstallSpec {
+	oses := GetAllOSValues()
+	arches := GetAllArchValues()
+	asset := spec.NewAsset("${NAME}_${VERSION}_${OS}_${ARCH}${EXT}").
+		WithDefaultExtension(".tar.gz").
+		WithOSNamingConvention(spec.Titlecase)
+	for i := 0; i < 50; i++ {
+		asset.WithRules(spec.NewRule(string(oses[i%len(oses)]), string(arches[i%len(arches)])).
+			WithTemplate("${NAME}-${VERSION}-${OS}-${ARCH}-custom${EXT}").
+			WithExt(".zip"))
+	}
+	return spec.NewInstallSpec("owner/bench-tool").WithAsset(asset)
+}
+
+func BenchmarkGenerateFilename(b *testing.B) {
+	g := NewFilenameGenerator(benchSpec(), "1.2.3")
+	platforms := g.GetAllPossiblePlatforms()
+	b.ReportAllocs()
+	for b.Loop() {
+		for _, p := range platforms {
+			if _, err := g.GenerateFilename(spec.PlatformOSString(p.OS), spec.PlatformArchString(p.Arch)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkGeneratePossibleFilenames(b *testing.B) {
+	g := NewFilenameGenerator(benchSpec(), "1.2.3")
+	b.ReportAllocs()
+	for b.Loop() {
+		if len(g.GeneratePossibleFilenames()) == 0 {
+			b.Fatal("no filenames generated")
+		}
+	}
+}
+
+func BenchmarkGenerateAll(b *testing.B) {
+	installSpec := benchSpec()
+	b.ReportAllocs()
+	for b.Loop() {
+		// A new generator per iteration measures generation, not memoization
+		if _, err := NewFilenameGenerator(installSpec, "1.2.3").GenerateAll(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestGeneratePossibleFilenamesBudget(t *testing.T) {
+	if os.Getenv("BINSTALLER_PERF_GATE") != "1" {
+		t.Skip("set BINSTALLER_PERF_GATE=1 to enforce the filename generation performance budget")
+	}
+	g := NewFilenameGenerator(benchSpec(), "1.2.3")
+	result := testing.Benchmark(func(b *testing.B) {
+		for b.Loop() {
+			g.GeneratePossibleFilenames()
+		}
+	})
+	if got := result.NsPerOp(); got > budgetGeneratePossibleFilenames {
+		t.Errorf("GeneratePossibleFilenames took %v per op, budget is %v", time.Duration(got), time.Duration(budgetGeneratePossibleFilenames))
+	}
+}
@@ -0,0 +1,28 @@
+package asset
+
+import (
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// FuzzGenerateFilename checks that asset template interpolation never panics
+// on arbitrary templates, rule overrides, and platform inputs.
+func FuzzGenerateFilename(f *testing.F) {
+	f.Add("${NAME}_${VERSION}_${OS}_${ARCH}${EXT}", ".zip", "linux", "amd64", "v1.0.0")
+	f.Add("${NAME:-default}-${TAG}", "", "Darwin", "ARM64", "1.0.0")
+	f.Add("$${ESCAPED}${", ".tar.gz", "windows", "386", "")
+	f.Add("${ASSET_FILENAME}", "", "", "", "v")
+
+	f.Fuzz(func(t *testing.T, template, ext, osInput, archInput, version string) {
+		s := spec.NewInstallSpec("owner/tool").
+			WithAsset(spec.NewAsset(template).
+				WithDefaultExtension(".tar.gz").
+				WithOSNamingConvention(spec.Titlecase).
+				WithRules(
+					spec.NewRule(osInput, "").WithExt(ext),
+					spec.NewRule("", archInput).WithTemplate(template+"-"+ext),
+				))
+		_, _ = NewFilenameGenerator(s, version).GenerateFilename(osInput, archInput)
+	})
+}
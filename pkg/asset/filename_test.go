@@ -1,7 +1,11 @@
 package asset
 
 import (
+	"context"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/binary-install/binstaller/pkg/spec"
 )
@@ -231,3 +235,215 @@ func TestGeneratePossibleFilenamesAllPlatforms(t *testing.T) {
 		}
 	}
 }
+
+func TestChecksumTemplate(t *testing.T) {
+	testSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}.tar.gz").
+			WithRules(
+				spec.NewRule("linux", "").WithChecksumTemplate("checksums-linux.txt"),
+				spec.NewRule("darwin", "").WithChecksumTemplate("checksums-darwin.txt"),
+				spec.NewRule("darwin", "arm64").WithChecksumTemplate("checksums-darwin-arm64.txt"),
+				spec.NewRule("windows", "").WithExt(".zip"),
+			)).
+		WithChecksums(spec.NewChecksums("checksums.txt")).
+		WithSupportedPlatforms("linux/amd64", "linux/arm64", "darwin/amd64", "darwin/arm64", "windows/amd64")
+
+	generator := NewFilenameGenerator(testSpec, "1.0.0")
+	tests := []struct {
+		os, arch string
+		want     string
+	}{
+		{"linux", "amd64", "checksums-linux.txt"},
+		{"Linux", "arm64", "checksums-linux.txt"},
+		{"darwin", "amd64", "checksums-darwin.txt"},
+		{"darwin", "arm64", "checksums-darwin-arm64.txt"},
+		{"windows", "amd64", "checksums.txt"},
+	}
+	for _, tt := range tests {
+		if got := generator.ChecksumTemplate(tt.os, tt.arch); got != tt.want {
+			t.Errorf("ChecksumTemplate(%s, %s) = %q, want %q", tt.os, tt.arch, got, tt.want)
+		}
+	}
+
+	want := []string{"checksums-linux.txt", "checksums-darwin.txt", "checksums-darwin-arm64.txt", "checksums.txt"}
+	got := generator.PossibleChecksumTemplates()
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("PossibleChecksumTemplates() = %v, want %v", got, want)
+	}
+}
+
+func TestDeltaFilename(t *testing.T) {
+	testSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${VERSION}_${OS}_${ARCH}${EXT}").
+			WithDefaultExtension(".tar.gz").
+			WithOSNamingConvention(spec.Titlecase).
+			WithRules(spec.NewRule("", "amd64").WithArch("x86_64")).
+			WithDelta(spec.NewDelta("${NAME}_${FROM_VERSION}_to_${VERSION}_${OS}_${ARCH}.patch.zst")))
+	testSpec.SetDefaults()
+
+	generator := NewFilenameGenerator(testSpec, "v1.1.0")
+	got, err := generator.DeltaFilename("linux", "amd64", "v1.0.0")
+	if err != nil {
+		t.Fatalf("DeltaFilename() error = %v", err)
+	}
+	if want := "tool_1.0.0_to_1.1.0_Linux_x86_64.patch.zst"; got != want {
+		t.Errorf("DeltaFilename() = %q, want %q", got, want)
+	}
+
+	testSpec.Asset.Delta = spec.NewDelta("${ASSET_FILENAME}.from-${FROM_TAG}.bsdiff")
+	got, err = generator.DeltaFilename("darwin", "arm64", "v1.0.0")
+	if err != nil {
+		t.Fatalf("DeltaFilename() error = %v", err)
+	}
+	if want := "tool_1.1.0_Darwin_arm64.tar.gz.from-v1.0.0.bsdiff"; got != want {
+		t.Errorf("DeltaFilename() = %q, want %q", got, want)
+	}
+
+	testSpec.Asset.Delta = nil
+	if _, err := generator.DeltaFilename("linux", "amd64", "v1.0.0"); err == nil {
+		t.Error("DeltaFilename() expected error without delta template")
+	}
+}
+
+func TestExtraFilenames(t *testing.T) {
+	testSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${VERSION}_${OS}_${ARCH}${EXT}").
+			WithDefaultExtension(".tar.gz").
+			WithRules(spec.NewRule("", "amd64").WithArch("x86_64")).
+			WithExtraFile("${NAME}_${VERSION}_data.tar.gz", "../share").
+			WithExtraFile("${NAME}_${VERSION}_${OS}_${ARCH}.plugins${EXT}", ""))
+	testSpec.SetDefaults()
+
+	generator := NewFilenameGenerator(testSpec, "v1.1.0")
+	got, err := generator.ExtraFilenames("linux", "amd64")
+	if err != nil {
+		t.Fatalf("ExtraFilenames() error = %v", err)
+	}
+	want := []string{"tool_1.1.0_data.tar.gz", "tool_1.1.0_linux_x86_64.plugins.tar.gz"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtraFilenames() = %q, want %q", got, want)
+	}
+
+	possible := generator.GeneratePossibleFilenames()
+	for _, filename := range want {
+		if !possible[filename] {
+			t.Errorf("GeneratePossibleFilenames() should include %s", filename)
+		}
+	}
+}
+
+func TestGenerateFilenameReleaseVars(t *testing.T) {
+	testSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}-${PUBLISHED_DATE}-${OS}${EXT}").
+			WithDefaultExtension(".tar.gz").
+			WithExtraFile("${NAME}-${RELEASE_NAME}.1", "tool.1"))
+	testSpec.SetDefaults()
+
+	generator := NewFilenameGenerator(testSpec, "nightly")
+	generator.ReleaseVars = spec.ReleaseVars("build-42", time.Date(2024, 5, 1, 23, 0, 0, 0, time.FixedZone("", -2*60*60)))
+	filename, err := generator.GenerateFilename("linux", "amd64")
+	if err != nil {
+		t.Fatalf("GenerateFilename() error = %v", err)
+	}
+	if want := "tool-2024-05-02-linux.tar.gz"; filename != want {
+		t.Errorf("GenerateFilename() = %q, want %q", filename, want)
+	}
+	extras, err := generator.ExtraFilenames("linux", "amd64")
+	if err != nil {
+		t.Fatalf("ExtraFilenames() error = %v", err)
+	}
+	if want := []string{"tool-build-42.1"}; !reflect.DeepEqual(extras, want) {
+		t.Errorf("ExtraFilenames() = %v, want %v", extras, want)
+	}
+}
+
+func TestCandidates(t *testing.T) {
+	testSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}-${VERSION}-${ARCH}-unknown-linux-musl${EXT}").
+			WithDefaultExtension(".tar.gz").
+			WithRules(
+				spec.NewRule("linux", "").WithFallbackTemplates(
+					"${NAME}-${VERSION}-${ARCH}-unknown-linux-gnu${EXT}",
+					"${NAME}-${VERSION}-${ARCH}-unknown-linux-musl${EXT}",
+				),
+				spec.NewRule("", "amd64").WithArch("x86_64"),
+				spec.NewRule("darwin", "").WithTemplate("${NAME}-${VERSION}-${ARCH}-apple-darwin${EXT}"),
+			))
+	testSpec.SetDefaults()
+
+	generator := NewFilenameGenerator(testSpec, "1.0.0")
+	tests := []struct {
+		os, arch string
+		want     []string
+	}{
+		{"linux", "amd64", []string{"tool-1.0.0-x86_64-unknown-linux-musl.tar.gz", "tool-1.0.0-x86_64-unknown-linux-gnu.tar.gz"}},
+		{"linux", "arm64", []string{"tool-1.0.0-arm64-unknown-linux-musl.tar.gz", "tool-1.0.0-arm64-unknown-linux-gnu.tar.gz"}},
+		{"darwin", "arm64", []string{"tool-1.0.0-arm64-apple-darwin.tar.gz"}},
+	}
+	for _, tt := range tests {
+		got, err := generator.Candidates(tt.os, tt.arch)
+		if err != nil {
+			t.Fatalf("Candidates(%s, %s) error = %v", tt.os, tt.arch, err)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("Candidates(%s, %s) = %v, want %v", tt.os, tt.arch, got, tt.want)
+		}
+	}
+
+	possible := generator.GeneratePossibleFilenames()
+	if !possible["tool-1.0.0-x86_64-unknown-linux-gnu.tar.gz"] {
+		t.Error("GeneratePossibleFilenames() should include fallback candidates")
+	}
+}
+
+func TestGenerateAll(t *testing.T) {
+	testSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}${EXT}").
+			WithDefaultExtension(".tar.gz").
+			WithRules(
+				spec.NewRule("windows", "").WithExt(".zip"),
+				spec.NewRule("linux", "").WithFallbackTemplates("${NAME}_${OS}_${ARCH}_static${EXT}"),
+			)).
+		WithSupportedPlatforms("linux/amd64", "windows/arm64")
+	testSpec.SetDefaults()
+
+	generator := NewFilenameGenerator(testSpec, "v1.0.0")
+	generator.Concurrency = 2
+	all, err := generator.GenerateAll(context.Background())
+	if err != nil {
+		t.Fatalf("GenerateAll() error = %v", err)
+	}
+	want := map[Platform][]string{
+		{OS: "linux", Arch: "amd64"}:   {"tool_linux_amd64.tar.gz", "tool_linux_amd64_static.tar.gz"},
+		{OS: "windows", Arch: "arm64"}: {"tool_windows_arm64.zip"},
+	}
+	if len(all) != len(want) {
+		t.Fatalf("GenerateAll() = %v, want %v", all, want)
+	}
+	for platform, filenames := range want {
+		if strings.Join(all[platform], ",") != strings.Join(filenames, ",") {
+			t.Errorf("GenerateAll()[%s] = %v, want %v", platform, all[platform], filenames)
+		}
+	}
+
+	// Results are memoized but callers get their own copy
+	all[Platform{OS: "linux", Arch: "amd64"}][0] = "changed"
+	if got, _ := generator.Candidates("linux", "amd64"); got[0] != "tool_linux_amd64.tar.gz" {
+		t.Errorf("Candidates() after changing a GenerateAll result = %v", got)
+	}
+
+	// Without supported_platforms every OS/Arch combination is generated
+	all, err = NewFilenameGenerator(spec.NewInstallSpec("owner/tool").WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}")), "v1.0.0").GenerateAll(context.Background())
+	if err != nil {
+		t.Fatalf("GenerateAll() error = %v", err)
+	}
+	if len(all) != len(GetAllOSValues())*len(GetAllArchValues()) {
+		t.Errorf("GenerateAll() generated %d platforms, want every combination", len(all))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := generator.GenerateAll(ctx); err == nil {
+		t.Error("GenerateAll() with a canceled context succeeded, want error")
+	}
+}
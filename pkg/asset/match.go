@@ -0,0 +1,137 @@
+package asset
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// versionSentinel stands in for ${VERSION} (and "v" + it for ${TAG}) when
+// matching filenames of an unknown version
+const versionSentinel = "binstversionsentinel"
+
+// Match is a platform whose asset candidates include a filename
+type Match struct {
+	Platform Platform
+	// OSVersion is the when.os_version pattern the filename was resolved with,
+	// empty when it does not depend on the OS version
+	OSVersion string
+	// Candidate is the index of the filename in Candidates: 0 for the asset
+	// template, fallback templates after it
+	Candidate int
+	// Tag is the version the filename was generated with: the generator's
+	// version, or the one parsed from the filename. Empty when the filename
+	// does not include the version.
+	Tag string
+	// Resolution is how the asset template of the platform was resolved
+	Resolution *Resolution
+}
+
+// Match returns the platforms, in Platforms order, that resolve to filename as
+// their asset or one of its fallbacks. Without a generator version the version
+// is parsed from the filename.
+func (g *FilenameGenerator) Match(filename string) ([]Match, error) {
+	if g.Spec == nil || g.Spec.Asset == nil || g.Spec.GetAsset().GetTemplate() == "" {
+		return nil, fmt.Errorf("asset template not defined in spec")
+	}
+
+	// Filenames only depend on the OS version through rules, so try each
+	// os_version pattern after the rules without one, like GeneratePossibleFilenames
+	osVersions := []string{""}
+	for _, rule := range g.Spec.Asset.Rules {
+		if pattern := rule.GetWhen().GetOSVersion(); pattern != "" && !slices.Contains(osVersions, pattern) {
+			osVersions = append(osVersions, pattern)
+		}
+	}
+
+	var matches []Match
+	for _, platform := range g.Platforms() {
+		for _, osVersion := range osVersions {
+			generator := *g
+			generator.OSVersion = osVersion
+			candidate, tag, ok, err := generator.matchCandidates(platform, filename)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			if osVersion != "" && slices.ContainsFunc(matches, func(m Match) bool { return m.Platform == platform }) {
+				// The OS version rules do not change the filename of this platform
+				continue
+			}
+			resolution, err := generator.Resolve(platform.OS, platform.Arch)
+			if err != nil {
+				return nil, err
+			}
+			matches = append(matches, Match{Platform: platform, OSVersion: osVersion, Candidate: candidate, Tag: tag, Resolution: resolution})
+		}
+	}
+	return matches, nil
+}
+
+// matchCandidates returns the index of filename in the candidates of a platform
+// and the version it was generated with
+func (g *FilenameGenerator) matchCandidates(platform Platform, filename string) (int, string, bool, error) {
+	if g.Version != "" {
+		candidates, err := g.Candidates(platform.OS, platform.Arch)
+		if err != nil {
+			return 0, "", false, err
+		}
+		i := slices.Index(candidates, filename)
+		return i, g.Version, i >= 0, nil
+	}
+
+	sentinel := *g
+	sentinel.Version = "v" + versionSentinel
+	sentinel.candidates = nil
+	patterns, err := sentinel.Candidates(platform.OS, platform.Arch)
+	if err != nil {
+		return 0, "", false, err
+	}
+	for i, pattern := range patterns {
+		tag, ok := parseVersion(pattern, filename)
+		if !ok {
+			continue
+		}
+		// Generate the filename again so every occurrence of the version agrees
+		generator := *g
+		generator.Version = tag
+		generator.candidates = nil
+		candidates, err := generator.Candidates(platform.OS, platform.Arch)
+		if err != nil {
+			return 0, "", false, err
+		}
+		if i < len(candidates) && candidates[i] == filename {
+			return i, tag, true, nil
+		}
+	}
+	return 0, "", false, nil
+}
+
+// parseVersion returns the tag of filename when it matches pattern, a filename
+// generated with the version sentinel. Tags of filenames with only ${VERSION}
+// get a "v" prefix; an empty tag means the pattern does not include the version.
+func parseVersion(pattern, filename string) (string, bool) {
+	if !strings.Contains(pattern, versionSentinel) {
+		return "", pattern == filename
+	}
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.ReplaceAll(expr, "v"+versionSentinel, "(?P<tag>.+?)")
+	expr = strings.ReplaceAll(expr, versionSentinel, "(?P<version>.+?)")
+	re, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return "", false
+	}
+	m := re.FindStringSubmatch(filename)
+	if m == nil {
+		return "", false
+	}
+	for i, name := range re.SubexpNames() {
+		if name == "tag" {
+			return m[i], true
+		}
+	}
+	return "v" + m[re.SubexpIndex("version")], true
+}
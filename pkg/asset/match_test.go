@@ -0,0 +1,65 @@
+package asset
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+func TestMatch(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${VERSION}_${OS}_${ARCH}${EXT}").
+			WithDefaultExtension(".tar.gz").
+			WithRules(
+				spec.NewRule("", "amd64").WithArch("x86_64"),
+				spec.NewRule("windows", "").WithExt(".zip"),
+				spec.NewRule("darwin", "").WithArch("all").WithFallbackTemplates("${NAME}_${TAG}_macos${EXT}"),
+				spec.NewRule("linux", "").WithOSVersion("alpine*").WithTemplate("${NAME}_${VERSION}_${OS}_${ARCH}_musl${EXT}"),
+			)).
+		WithSupportedPlatforms("linux/amd64", "linux/arm64", "darwin/amd64", "darwin/arm64", "windows/amd64")
+	installSpec.SetDefaults()
+
+	type match struct {
+		platform  string
+		osVersion string
+		candidate int
+		tag       string
+		rules     []int
+	}
+	tests := []struct {
+		name     string
+		version  string
+		filename string
+		want     []match
+	}{
+		{"parsed version", "", "tool_1.2.3_linux_x86_64.tar.gz", []match{{"linux/amd64", "", 0, "v1.2.3", []int{0}}}},
+		{"given version", "v1.2.3", "tool_1.2.3_windows_x86_64.zip", []match{{"windows/amd64", "", 0, "v1.2.3", []int{0, 1}}}},
+		{"other version", "v2.0.0", "tool_1.2.3_windows_x86_64.zip", nil},
+		{"shared asset", "", "tool_1.2.3_darwin_all.tar.gz", []match{
+			{"darwin/amd64", "", 0, "v1.2.3", []int{0, 2}},
+			{"darwin/arm64", "", 0, "v1.2.3", []int{2}},
+		}},
+		{"fallback with tag", "", "tool_v1.2.3-rc.1_macos.tar.gz", []match{
+			{"darwin/amd64", "", 1, "v1.2.3-rc.1", []int{0, 2}},
+			{"darwin/arm64", "", 1, "v1.2.3-rc.1", []int{2}},
+		}},
+		{"os version", "", "tool_1.2.3_linux_arm64_musl.tar.gz", []match{{"linux/arm64", "alpine*", 0, "v1.2.3", []int{3}}}},
+		{"unknown", "", "tool_1.2.3_freebsd_amd64.tar.gz", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := NewFilenameGenerator(installSpec, tt.version).Match(tt.filename)
+			if err != nil {
+				t.Fatalf("Match() error = %v", err)
+			}
+			var got []match
+			for _, m := range matches {
+				got = append(got, match{m.Platform.String(), m.OSVersion, m.Candidate, m.Tag, m.Resolution.Rules})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Match() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
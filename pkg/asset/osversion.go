@@ -0,0 +1,80 @@
+package asset
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// DetectOSVersion returns the OS version of this host matched by when.os_version:
+// macos-MAJOR on macOS and ID-VERSION_ID from /etc/os-release on Linux.
+// BINSTALLER_OS_VERSION overrides detection. It returns "" when the version is unknown.
+func DetectOSVersion(goos string) string {
+	if v := os.Getenv("BINSTALLER_OS_VERSION"); v != "" {
+		return v
+	}
+	switch goos {
+	case "darwin":
+		out, err := exec.Command("sw_vers", "-productVersion").Output()
+		if err != nil {
+			return ""
+		}
+		major, _, _ := strings.Cut(strings.TrimSpace(string(out)), ".")
+		if major == "" {
+			return ""
+		}
+		return "macos-" + major
+	case "linux":
+		f, err := os.Open("/etc/os-release")
+		if err != nil {
+			return ""
+		}
+		defer f.Close()
+		return parseOSRelease(f)
+	}
+	return ""
+}
+
+// parseOSRelease returns ID-VERSION_ID (or ID alone) from os-release content
+func parseOSRelease(r io.Reader) string {
+	var id, versionID string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(value, `"'`)
+		switch key {
+		case "ID":
+			id = value
+		case "VERSION_ID":
+			versionID = value
+		}
+	}
+	if id == "" || versionID == "" {
+		return id
+	}
+	return id + "-" + versionID
+}
+
+// MatchOSVersion reports whether a when.os_version pattern matches osVersion or its
+// name alone (the part before the first '-'). A pattern always matches itself.
+func MatchOSVersion(pattern, osVersion string) bool {
+	if osVersion == "" {
+		return false
+	}
+	if pattern == osVersion {
+		return true
+	}
+	name, _, _ := strings.Cut(osVersion, "-")
+	for _, v := range []string{osVersion, name} {
+		if ok, _ := path.Match(pattern, v); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package asset
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+func TestParseOSRelease(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"ubuntu", "NAME=\"Ubuntu\"\nID=ubuntu\nVERSION_ID=\"24.04\"\n", "ubuntu-24.04"},
+		{"alpine", "ID=alpine\nVERSION_ID=3.20.3\nPRETTY_NAME=\"Alpine Linux v3.20\"\n", "alpine-3.20.3"},
+		{"rolling release", "ID=arch\nBUILD_ID=rolling\n", "arch"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseOSRelease(strings.NewReader(tt.content)); got != tt.want {
+				t.Errorf("parseOSRelease() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchOSVersion(t *testing.T) {
+	tests := []struct {
+		pattern, osVersion string
+		want               bool
+	}{
+		{"alpine", "alpine-3.20.3", true},
+		{"alpine-3.20*", "alpine-3.20.3", true},
+		{"ubuntu", "alpine-3.20.3", false},
+		{"macos-1[34]", "macos-14", true},
+		{"macos-1[34]", "macos-15", false},
+		{"macos", "macos-15", true},
+		{"arch", "arch", true},
+		{"*", "", false},
+		{"macos-1[34]", "macos-1[34]", true},
+	}
+	for _, tt := range tests {
+		if got := MatchOSVersion(tt.pattern, tt.osVersion); got != tt.want {
+			t.Errorf("MatchOSVersion(%q, %q) = %v, want %v", tt.pattern, tt.osVersion, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateFilenameOSVersion(t *testing.T) {
+	testSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}-${VERSION}-${OS}-${ARCH}${EXT}").
+			WithDefaultExtension(".tar.gz").
+			WithRules(spec.NewRule("linux", "").WithOSVersion("alpine").
+				WithTemplate("${NAME}-${VERSION}-${ARCH}-unknown-linux-musl${EXT}"))).
+		WithSupportedPlatforms("linux/amd64")
+	testSpec.SetDefaults()
+
+	generator := NewFilenameGenerator(testSpec, "1.0.0")
+	for _, tt := range []struct{ osVersion, want string }{
+		{"", "tool-1.0.0-linux-amd64.tar.gz"},
+		{"ubuntu-24.04", "tool-1.0.0-linux-amd64.tar.gz"},
+		{"alpine-3.20.3", "tool-1.0.0-amd64-unknown-linux-musl.tar.gz"},
+	} {
+		generator.OSVersion = tt.osVersion
+		got, err := generator.GenerateFilename("linux", "amd64")
+		if err != nil {
+			t.Fatalf("GenerateFilename() error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("GenerateFilename() with OS version %q = %q, want %q", tt.osVersion, got, tt.want)
+		}
+	}
+
+	generator.OSVersion = ""
+	possible := generator.GeneratePossibleFilenames()
+	for _, want := range []string{"tool-1.0.0-linux-amd64.tar.gz", "tool-1.0.0-amd64-unknown-linux-musl.tar.gz"} {
+		if !possible[want] {
+			t.Errorf("GeneratePossibleFilenames() should include %s, got %v", want, possible)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package asset
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/binary-install/binstaller/pkg/httpclient"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// BaseURL returns the web URL of the service hosting the releases of the spec,
+// e.g. https://github.com or https://gitlab.example.com. GitHub specs without a
+// host use httpclient.GitHubServerURL (GITHUB_SERVER_URL).
+func BaseURL(installSpec *spec.InstallSpec) string {
+	if installSpec.GetSource() != spec.Gitlab && spec.StringValue(installSpec.Host) == "" {
+		return httpclient.GitHubServerURL()
+	}
+	return "https://" + installSpec.GetHost()
+}
+
+// GitHubAPIURL returns the API URL of the GitHub instance hosting the releases
+// of the spec, e.g. https://api.github.com or https://github.example.com/api/v3
+func GitHubAPIURL(installSpec *spec.InstallSpec) string {
+	return httpclient.GitHubAPIURL(BaseURL(installSpec))
+}
+
+// DownloadURL returns the URL of a release file of the spec's repository.
+// baseURL is the web URL of the release service (BaseURL when empty).
+func DownloadURL(installSpec *spec.InstallSpec, baseURL, tag, filename string) string {
+	if baseURL == "" {
+		baseURL = BaseURL(installSpec)
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	if installSpec.GetSource() == spec.Gitlab {
+		// The permalink of the release link with the direct asset path /filename
+		return fmt.Sprintf("%s/%s/-/releases/%s/downloads/%s", baseURL, installSpec.GetRepo(), tag, filename)
+	}
+	return fmt.Sprintf("%s/%s/releases/download/%s/%s", baseURL, installSpec.GetRepo(), tag, filename)
+}
+
+// ReleasesURL returns the web page listing the releases of the spec's repository
+func ReleasesURL(installSpec *spec.InstallSpec) string {
+	if installSpec.GetSource() == spec.Gitlab {
+		return fmt.Sprintf("%s/%s/-/releases", BaseURL(installSpec), installSpec.GetRepo())
+	}
+	return fmt.Sprintf("%s/%s/releases", BaseURL(installSpec), installSpec.GetRepo())
+}
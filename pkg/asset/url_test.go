@@ -0,0 +1,82 @@
+package asset
+
+import (
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+func TestDownloadURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		installSpec *spec.InstallSpec
+		baseURL     string
+		want        string
+	}{
+		{
+			name:        "GitHub",
+			installSpec: spec.NewInstallSpec("owner/tool"),
+			want:        "https://github.com/owner/tool/releases/download/v1.0.0/tool.tar.gz",
+		},
+		{
+			name:        "GitHub with base URL",
+			installSpec: spec.NewInstallSpec("owner/tool"),
+			baseURL:     "http://127.0.0.1:8080/",
+			want:        "http://127.0.0.1:8080/owner/tool/releases/download/v1.0.0/tool.tar.gz",
+		},
+		{
+			name:        "GitHub Enterprise Server",
+			installSpec: spec.NewInstallSpec("owner/tool").WithSource(spec.Github, "github.example.com"),
+			want:        "https://github.example.com/owner/tool/releases/download/v1.0.0/tool.tar.gz",
+		},
+		{
+			name:        "gitlab.com",
+			installSpec: spec.NewInstallSpec("group/sub/tool").WithSource(spec.Gitlab, ""),
+			want:        "https://gitlab.com/group/sub/tool/-/releases/v1.0.0/downloads/tool.tar.gz",
+		},
+		{
+			name:        "self-managed GitLab",
+			installSpec: spec.NewInstallSpec("group/tool").WithSource(spec.Gitlab, "gitlab.example.com:8443"),
+			want:        "https://gitlab.example.com:8443/group/tool/-/releases/v1.0.0/downloads/tool.tar.gz",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DownloadURL(tt.installSpec, tt.baseURL, "v1.0.0", "tool.tar.gz"); got != tt.want {
+				t.Errorf("DownloadURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReleasesURL(t *testing.T) {
+	if got, want := ReleasesURL(spec.NewInstallSpec("owner/tool")), "https://github.com/owner/tool/releases"; got != want {
+		t.Errorf("ReleasesURL() = %q, want %q", got, want)
+	}
+	gitLab := spec.NewInstallSpec("group/tool").WithSource(spec.Gitlab, "")
+	if got, want := ReleasesURL(gitLab), "https://gitlab.com/group/tool/-/releases"; got != want {
+		t.Errorf("ReleasesURL() = %q, want %q", got, want)
+	}
+}
+
+func TestGitHubAPIURL(t *testing.T) {
+	t.Setenv("GITHUB_SERVER_URL", "")
+	t.Setenv("GITHUB_API_URL", "")
+	if got, want := GitHubAPIURL(spec.NewInstallSpec("owner/tool")), "https://api.github.com"; got != want {
+		t.Errorf("GitHubAPIURL() = %q, want %q", got, want)
+	}
+	ghes := spec.NewInstallSpec("owner/tool").WithSource(spec.Github, "github.example.com")
+	if got, want := GitHubAPIURL(ghes), "https://github.example.com/api/v3"; got != want {
+		t.Errorf("GitHubAPIURL() = %q, want %q", got, want)
+	}
+
+	// Specs without a host use the instance of GITHUB_SERVER_URL
+	t.Setenv("GITHUB_SERVER_URL", "https://ghes.example.com")
+	if got, want := DownloadURL(spec.NewInstallSpec("owner/tool"), "", "v1", "tool"), "https://ghes.example.com/owner/tool/releases/download/v1/tool"; got != want {
+		t.Errorf("DownloadURL() = %q, want %q", got, want)
+	}
+	if got, want := GitHubAPIURL(spec.NewInstallSpec("owner/tool")), "https://ghes.example.com/api/v3"; got != want {
+		t.Errorf("GitHubAPIURL() = %q, want %q", got, want)
+	}
+}
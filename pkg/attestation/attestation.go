@@ -0,0 +1,194 @@
+// Package attestation verifies GitHub artifact attestations of release assets,
+// such as the SLSA build provenance published by actions/attest-build-provenance.
+// Attestations are fetched from the GitHub API by the digest of the asset and
+// verified as Sigstore bundles signed by a GitHub Actions workflow.
+package attestation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/cosign"
+	"github.com/binary-install/binstaller/pkg/httpclient"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+const (
+	// DefaultAPIBaseURL is the GitHub API the attestations are fetched from
+	DefaultAPIBaseURL = "https://api.github.com"
+	// GitHubActionsIssuer is the OIDC issuer of GitHub Actions workflow identities
+	GitHubActionsIssuer = "https://token.actions.githubusercontent.com"
+	// inTotoPayloadType is the DSSE payload type of in-toto statements
+	inTotoPayloadType = "application/vnd.in-toto+json"
+)
+
+// ErrNoAttestation is returned when the repository has no attestation for the digest
+var ErrNoAttestation = errors.New("no attestation found")
+
+// Verifier verifies the attestations of a repository
+type Verifier struct {
+	// Repo is the repository the attested workflow must have run for, as owner/repo
+	Repo string
+	// SignerWorkflow, when set, is the workflow that must have signed the
+	// attestation, as owner/repo/path/to/workflow.yml
+	SignerWorkflow string
+	// PredicateType is the predicate type the attestation must have (default: spec.DefaultPredicateType)
+	PredicateType string
+	// APIBaseURL is the GitHub API base URL (default: DefaultAPIBaseURL)
+	APIBaseURL string
+	// RekorURL, RekorPublicKey and FulcioRoots override the public Sigstore
+	// instance, see cosign.Verifier
+	RekorURL       string
+	RekorPublicKey []byte
+	FulcioRoots    []byte
+	// Client is the HTTP client for GitHub API requests (default: httpclient.NewGitHubClient())
+	Client *http.Client
+}
+
+// statement is the part of an in-toto statement that is verified
+type statement struct {
+	Type    string `json:"_type"`
+	Subject []struct {
+		Name   string            `json:"name"`
+		Digest map[string]string `json:"digest"`
+	} `json:"subject"`
+	PredicateType string `json:"predicateType"`
+}
+
+// Verify checks that an attestation of the repository for the asset with the
+// given SHA-256 digest is signed by a trusted workflow, has the predicate type
+// and lists the digest as a subject. It returns ErrNoAttestation when the
+// repository has no attestation for the digest.
+func (v *Verifier) Verify(ctx context.Context, digest string) error {
+	bundles, err := v.fetchBundles(ctx, digest)
+	if err != nil {
+		return err
+	}
+	if len(bundles) == 0 {
+		return fmt.Errorf("%w for sha256:%s in %s", ErrNoAttestation, digest, v.Repo)
+	}
+
+	sigstore := &cosign.Verifier{
+		Identities: []cosign.Identity{{
+			CertificateIdentityRegexp: v.identityRegexp(),
+			CertificateOIDCIssuer:     GitHubActionsIssuer,
+			SourceRepositoryURI:       "https://github.com/" + v.Repo,
+		}},
+		RekorURL:       v.RekorURL,
+		RekorPublicKey: v.RekorPublicKey,
+		FulcioRoots:    v.FulcioRoots,
+	}
+	var errs []error
+	for _, bundle := range bundles {
+		envelope, err := sigstore.VerifyBundle(ctx, bundle)
+		if err == nil {
+			err = v.checkStatement(envelope, digest)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		log.Infof("Verified %s attestation of sha256:%s", v.predicateType(), digest)
+		return nil
+	}
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return fmt.Errorf("none of the %d attestations verified: %w", len(errs), errors.Join(errs...))
+}
+
+// identityRegexp returns the regular expression the workflow identity of the
+// signing certificate must match
+func (v *Verifier) identityRegexp() string {
+	if v.SignerWorkflow == "" {
+		return `^https://github\.com/`
+	}
+	return `(?i)^https://github\.com/` + regexp.QuoteMeta(v.SignerWorkflow) + `@`
+}
+
+func (v *Verifier) predicateType() string {
+	if v.PredicateType != "" {
+		return v.PredicateType
+	}
+	return spec.DefaultPredicateType
+}
+
+// checkStatement checks that the envelope is an in-toto statement with the
+// predicate type about digest
+func (v *Verifier) checkStatement(envelope *cosign.Envelope, digest string) error {
+	if envelope.PayloadType != inTotoPayloadType {
+		return fmt.Errorf("attestation payload type is %s, want %s", envelope.PayloadType, inTotoPayloadType)
+	}
+	var st statement
+	if err := json.Unmarshal(envelope.Payload, &st); err != nil {
+		return fmt.Errorf("failed to parse attestation statement: %w", err)
+	}
+	if !strings.HasPrefix(st.Type, "https://in-toto.io/Statement/") {
+		return fmt.Errorf("attestation is not an in-toto statement: %s", st.Type)
+	}
+	if st.PredicateType != v.predicateType() {
+		return fmt.Errorf("attestation predicate type is %s, want %s", st.PredicateType, v.predicateType())
+	}
+	for _, subject := range st.Subject {
+		if strings.EqualFold(subject.Digest["sha256"], digest) {
+			return nil
+		}
+	}
+	return fmt.Errorf("attestation does not list sha256:%s as a subject", digest)
+}
+
+// fetchBundles returns the Sigstore bundles of the attestations of the repository for digest
+func (v *Verifier) fetchBundles(ctx context.Context, digest string) ([]json.RawMessage, error) {
+	baseURL := strings.TrimSuffix(v.APIBaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultAPIBaseURL
+	}
+	url := fmt.Sprintf("%s/repos/%s/attestations/sha256:%s", baseURL, v.Repo, digest)
+	log.Debugf("Fetching attestations from %s", url)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	client := v.Client
+	if client == nil {
+		client = httpclient.NewGitHubClient()
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch attestations: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w for sha256:%s in %s", ErrNoAttestation, digest, v.Repo)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("GET %s returned status %d: %s", url, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var result struct {
+		Attestations []struct {
+			Bundle json.RawMessage `json:"bundle"`
+		} `json:"attestations"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse attestations: %w", err)
+	}
+	var bundles []json.RawMessage
+	for _, a := range result.Attestations {
+		if len(a.Bundle) > 0 && string(a.Bundle) != "null" {
+			bundles = append(bundles, a.Bundle)
+		}
+	}
+	return bundles, nil
+}
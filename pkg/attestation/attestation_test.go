@@ -0,0 +1,116 @@
+package attestation
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/cosign"
+)
+
+const testDigest = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+func TestCheckStatement(t *testing.T) {
+	tests := []struct {
+		name          string
+		payloadType   string
+		payload       string
+		predicateType string
+		wantErr       string
+	}{
+		{
+			name:    "provenance of the digest",
+			payload: `{"_type":"https://in-toto.io/Statement/v1","subject":[{"name":"other","digest":{"sha256":"00"}},{"name":"tool.tar.gz","digest":{"sha256":"` + strings.ToUpper(testDigest) + `"}}],"predicateType":"https://slsa.dev/provenance/v1"}`,
+		},
+		{
+			name:          "custom predicate type",
+			payload:       `{"_type":"https://in-toto.io/Statement/v1","subject":[{"digest":{"sha256":"` + testDigest + `"}}],"predicateType":"https://spdx.dev/Document/v2.3"}`,
+			predicateType: "https://spdx.dev/Document/v2.3",
+		},
+		{
+			name:    "other predicate type",
+			payload: `{"_type":"https://in-toto.io/Statement/v1","subject":[{"digest":{"sha256":"` + testDigest + `"}}],"predicateType":"https://spdx.dev/Document/v2.3"}`,
+			wantErr: "predicate type",
+		},
+		{
+			name:    "other subject",
+			payload: `{"_type":"https://in-toto.io/Statement/v1","subject":[{"digest":{"sha256":"00"}}],"predicateType":"https://slsa.dev/provenance/v1"}`,
+			wantErr: "does not list",
+		},
+		{
+			name:        "not in-toto",
+			payloadType: "text/plain",
+			payload:     `{}`,
+			wantErr:     "payload type",
+		},
+		{
+			name:    "not a statement",
+			payload: `{"_type":"https://example.com/Other"}`,
+			wantErr: "not an in-toto statement",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payloadType := tt.payloadType
+			if payloadType == "" {
+				payloadType = inTotoPayloadType
+			}
+			v := &Verifier{Repo: "owner/repo", PredicateType: tt.predicateType}
+			err := v.checkStatement(&cosign.Envelope{PayloadType: payloadType, Payload: []byte(tt.payload)}, testDigest)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkStatement() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkStatement() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyFetch(t *testing.T) {
+	var status int
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/owner/repo/attestations/sha256:"+testDigest {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+	v := &Verifier{Repo: "owner/repo", APIBaseURL: server.URL, Client: server.Client()}
+
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		wantErr  string
+		wantNone bool
+	}{
+		{name: "not found", status: http.StatusNotFound, body: `{"message":"Not Found"}`, wantNone: true},
+		{name: "empty list", status: http.StatusOK, body: `{"attestations":[]}`, wantNone: true},
+		{name: "server error", status: http.StatusInternalServerError, body: "boom", wantErr: "status 500"},
+		{name: "invalid bundle", status: http.StatusOK, body: `{"attestations":[{"bundle":{"mediaType":"x"}}]}`, wantErr: "no DSSE envelope"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body = tt.status, tt.body
+			err := v.Verify(t.Context(), testDigest)
+			if err == nil {
+				t.Fatal("Verify() should fail")
+			}
+			if got := errors.Is(err, ErrNoAttestation); got != tt.wantNone {
+				t.Errorf("Verify() error = %v, ErrNoAttestation = %v, want %v", err, got, tt.wantNone)
+			}
+			if tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+// Package auth logs in to GitHub with the OAuth device flow
+// (https://docs.github.com/en/apps/oauth-apps/building-oauth-apps/authorizing-oauth-apps#device-flow)
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultClientID is the client ID of the binstaller OAuth app. Release builds
+// set it with -ldflags "-X github.com/binary-install/binstaller/pkg/auth.DefaultClientID=...".
+var DefaultClientID = ""
+
+// DefaultBaseURL is the GitHub web URL serving the device flow endpoints
+const DefaultBaseURL = "https://github.com"
+
+// slowDownInterval is added to the polling interval when GitHub answers slow_down
+const slowDownInterval = 5 * time.Second
+
+// ErrAccessDenied is returned when the user cancels the authorization
+var ErrAccessDenied = errors.New("authorization was denied")
+
+// ErrExpired is returned when the device code expires before the user
+// authorizes it
+var ErrExpired = errors.New("the device code expired; run the login again")
+
+// DeviceFlow requests a token for an OAuth app with the device flow
+type DeviceFlow struct {
+	ClientID string
+	Scopes   []string
+	// BaseURL is the GitHub web URL (DefaultBaseURL when empty)
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// DeviceCode is the code the user enters at VerificationURI to authorize the
+// login
+type DeviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+}
+
+// Token is an OAuth access token
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	Scope       string `json:"scope"`
+}
+
+// oauthError is the error of an OAuth endpoint
+type oauthError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// RequestCode starts the device flow and returns the code for the user
+func (f *DeviceFlow) RequestCode(ctx context.Context) (*DeviceCode, error) {
+	if f.ClientID == "" {
+		return nil, errors.New("no OAuth client ID configured")
+	}
+	form := url.Values{"client_id": {f.ClientID}}
+	if len(f.Scopes) > 0 {
+		form.Set("scope", strings.Join(f.Scopes, " "))
+	}
+	var result struct {
+		DeviceCode
+		oauthError
+	}
+	if err := f.post(ctx, "/login/device/code", form, &result); err != nil {
+		return nil, err
+	}
+	if result.Error != "" {
+		return nil, fmt.Errorf("failed to request a device code: %s", result.describe())
+	}
+	if result.DeviceCode.DeviceCode == "" {
+		return nil, errors.New("failed to request a device code: empty response")
+	}
+	return &result.DeviceCode, nil
+}
+
+// PollToken waits until the user authorizes code and returns the token
+func (f *DeviceFlow) PollToken(ctx context.Context, code *DeviceCode) (*Token, error) {
+	interval := time.Duration(code.Interval) * time.Second
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(code.ExpiresIn)*time.Second)
+	defer cancel()
+	form := url.Values{
+		"client_id":   {f.ClientID},
+		"device_code": {code.DeviceCode},
+		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, ErrExpired
+			}
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+
+		var result struct {
+			Token
+			oauthError
+			Interval int `json:"interval"`
+		}
+		if err := f.post(ctx, "/login/oauth/access_token", form, &result); err != nil {
+			return nil, err
+		}
+		switch result.Error {
+		case "":
+			if result.AccessToken == "" {
+				return nil, errors.New("no access token in the response")
+			}
+			return &result.Token, nil
+		case "authorization_pending":
+		case "slow_down":
+			interval += slowDownInterval
+			if result.Interval > 0 {
+				interval = time.Duration(result.Interval) * time.Second
+			}
+		case "expired_token":
+			return nil, ErrExpired
+		case "access_denied":
+			return nil, ErrAccessDenied
+		default:
+			return nil, fmt.Errorf("failed to get the access token: %s", result.describe())
+		}
+	}
+}
+
+// post sends a form to an OAuth endpoint and decodes the JSON response
+func (f *DeviceFlow) post(ctx context.Context, path string, form url.Values, v any) error {
+	baseURL := f.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(baseURL, "/")+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	client := f.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", req.URL, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse the response of %s: %w", req.URL, err)
+	}
+	return nil
+}
+
+// describe returns the OAuth error with its description
+func (e oauthError) describe() string {
+	if e.ErrorDescription != "" {
+		return e.Error + ": " + e.ErrorDescription
+	}
+	return e.Error
+}
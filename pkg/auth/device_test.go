@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDeviceFlow(t *testing.T) {
+	var polls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if r.Form.Get("client_id") != "client" {
+			t.Errorf("client_id = %q", r.Form.Get("client_id"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/login/device/code":
+			if got := r.Form.Get("scope"); got != "repo read:org" {
+				t.Errorf("scope = %q", got)
+			}
+			w.Write([]byte(`{"device_code":"dc","user_code":"ABCD-1234","verification_uri":"https://github.com/login/device","expires_in":60,"interval":0}`))
+		case "/login/oauth/access_token":
+			if r.Form.Get("device_code") != "dc" || r.Form.Get("grant_type") != "urn:ietf:params:oauth:grant-type:device_code" {
+				t.Errorf("unexpected token request %v", r.Form)
+			}
+			polls++
+			switch polls {
+			case 1:
+				w.Write([]byte(`{"error":"authorization_pending"}`))
+			case 2:
+				w.Write([]byte(`{"access_token":"gho_test","token_type":"bearer","scope":"repo,read:org"}`))
+			default:
+				w.Write([]byte(`{"error":"access_denied"}`))
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	flow := &DeviceFlow{ClientID: "client", Scopes: []string{"repo", "read:org"}, BaseURL: server.URL}
+	ctx := context.Background()
+	code, err := flow.RequestCode(ctx)
+	if err != nil {
+		t.Fatalf("RequestCode() error = %v", err)
+	}
+	if code.UserCode != "ABCD-1234" || code.VerificationURI != "https://github.com/login/device" {
+		t.Errorf("RequestCode() = %+v", code)
+	}
+	token, err := flow.PollToken(ctx, code)
+	if err != nil {
+		t.Fatalf("PollToken() error = %v", err)
+	}
+	if token.AccessToken != "gho_test" || polls != 2 {
+		t.Errorf("PollToken() = %+v after %d polls", token, polls)
+	}
+
+	if _, err := flow.PollToken(ctx, code); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("PollToken() error = %v, want ErrAccessDenied", err)
+	}
+
+	if _, err := (&DeviceFlow{BaseURL: server.URL}).RequestCode(ctx); err == nil {
+		t.Error("RequestCode() without a client ID should fail")
+	}
+}
@@ -0,0 +1,293 @@
+// Package cache stores verified release assets so later installs can reuse them,
+// for example as the base for delta updates.
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EnvDir overrides the cache directory
+const EnvDir = "BINSTALLER_CACHE_DIR"
+
+// ResponsesDir is the directory under Dir() holding cached HTTP responses
+const ResponsesDir = "http"
+
+// Kinds of cache entries
+const (
+	// KindAssets are the release assets of one tag
+	KindAssets = "assets"
+	// KindTools are the binaries of one tag installed for binst exec
+	KindTools = "tools"
+	// KindResponse is one cached HTTP response
+	KindResponse = "response"
+)
+
+// Dir returns the binstaller cache directory.
+// It uses $BINSTALLER_CACHE_DIR if set, otherwise <user cache dir>/binstaller.
+func Dir() (string, error) {
+	if dir := os.Getenv(EnvDir); dir != "" {
+		return dir, nil
+	}
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine user cache directory: %w", err)
+	}
+	return filepath.Join(base, "binstaller"), nil
+}
+
+// Store is an on-disk cache of release assets laid out as
+// <root>/assets/<owner>/<repo>/<tag>/<filename>, with binaries installed for
+// binst exec under <root>/tools/<owner>/<repo>/<tag>
+type Store struct {
+	Root string
+}
+
+// New returns a Store rooted at Dir()
+func New() (*Store, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	return &Store{Root: dir}, nil
+}
+
+// Path returns the cache path for an asset
+func (s *Store) Path(repo, tag, filename string) string {
+	return filepath.Join(s.repoDir(repo), tag, filename)
+}
+
+// ToolDir returns the directory holding the installed binaries of a release
+func (s *Store) ToolDir(repo, tag string) (string, error) {
+	if !validComponent(tag) {
+		return "", fmt.Errorf("invalid cache key %s", tag)
+	}
+	return filepath.Join(s.Root, KindTools, filepath.FromSlash(repo), tag), nil
+}
+
+// Lookup returns the cache path for an asset if it exists
+func (s *Store) Lookup(repo, tag, filename string) (string, bool) {
+	path := s.Path(repo, tag, filename)
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return "", false
+	}
+	return path, true
+}
+
+// Put copies the file at src into the cache
+func (s *Store) Put(repo, tag, filename, src string) error {
+	if !validComponent(tag) || !validComponent(filename) {
+		return fmt.Errorf("invalid cache key %s/%s", tag, filename)
+	}
+	dest := s.Path(repo, tag, filename)
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", src, err)
+	}
+	defer in.Close()
+
+	// Write to a temporary file first so concurrent installs never see partial assets
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".tmp-"+filename)
+	if err != nil {
+		return fmt.Errorf("failed to create cache file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, in); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return fmt.Errorf("failed to store cache file: %w", err)
+	}
+	return nil
+}
+
+// Tags returns the cached tags of a repository, most recently stored first
+func (s *Store) Tags(repo string) ([]string, error) {
+	entries, err := os.ReadDir(s.repoDir(repo))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	type cachedTag struct {
+		name    string
+		modTime int64
+	}
+	var tags []cachedTag
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		tags = append(tags, cachedTag{entry.Name(), info.ModTime().UnixNano()})
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].modTime > tags[j].modTime
+	})
+
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.name
+	}
+	return names, nil
+}
+
+// repoDir returns the cache directory of an owner/repo
+func (s *Store) repoDir(repo string) string {
+	return filepath.Join(s.Root, KindAssets, filepath.FromSlash(repo))
+}
+
+// Entry is a removable part of the cache: the assets or installed binaries of
+// one release, or one cached HTTP response
+type Entry struct {
+	Kind string
+	// Repo and Tag identify the release of assets and tools entries
+	Repo string
+	Tag  string
+	Path string
+	Size int64
+	// ModTime is when the entry was last written
+	ModTime time.Time
+}
+
+// Entries lists the releases cached under assets and tools and the cached
+// HTTP responses
+func (s *Store) Entries() ([]Entry, error) {
+	var entries []Entry
+	for _, kind := range []string{KindAssets, KindTools} {
+		root := filepath.Join(s.Root, kind)
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if errors.Is(err, fs.ErrNotExist) && path == root {
+				return fs.SkipDir
+			}
+			if err != nil || !d.IsDir() || path == root {
+				return err
+			}
+			// Tag directories hold files; repository directories only directories
+			children, err := os.ReadDir(path)
+			if err != nil {
+				return err
+			}
+			if !hasFile(children) {
+				return nil
+			}
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			entry := Entry{Kind: kind, Repo: filepath.ToSlash(filepath.Dir(rel)), Tag: filepath.Base(rel), Path: path}
+			if entry.Size, entry.ModTime, err = usage(path); err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+			return fs.SkipDir
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cache directory: %w", err)
+		}
+	}
+
+	responses, err := os.ReadDir(filepath.Join(s.Root, ResponsesDir))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+	for _, d := range responses {
+		info, err := d.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		entries = append(entries, Entry{
+			Kind:    KindResponse,
+			Path:    filepath.Join(s.Root, ResponsesDir, d.Name()),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+	}
+	return entries, nil
+}
+
+// Remove deletes a cache entry and the directories left empty by its removal
+func (s *Store) Remove(e Entry) error {
+	if err := os.RemoveAll(e.Path); err != nil {
+		return err
+	}
+	root := filepath.Join(s.Root, e.Kind)
+	for dir := filepath.Dir(e.Path); e.Kind != KindResponse && strings.HasPrefix(dir, root+string(filepath.Separator)); dir = filepath.Dir(dir) {
+		// Remove fails on the first directory that is not empty
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// Size returns the total size of the files in the cache
+func (s *Store) Size() (int64, error) {
+	size, _, err := usage(s.Root)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	return size, err
+}
+
+// hasFile reports whether entries include something other than a
+// directory
+func hasFile(entries []fs.DirEntry) bool {
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// usage returns the total size of the files under path and the latest
+// modification time of path and its contents
+func usage(path string) (int64, time.Time, error) {
+	var size int64
+	var modTime time.Time
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		if info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+		return nil
+	})
+	return size, modTime, err
+}
+
+// validComponent reports whether name is usable as a single path component
+func validComponent(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
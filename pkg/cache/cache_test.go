@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDir(t *testing.T) {
+	t.Setenv(EnvDir, "/tmp/binstaller-cache")
+	dir, err := Dir()
+	if err != nil {
+		t.Fatalf("Dir() error = %v", err)
+	}
+	if dir != "/tmp/binstaller-cache" {
+		t.Errorf("Dir() = %q, want %q", dir, "/tmp/binstaller-cache")
+	}
+}
+
+func TestStore(t *testing.T) {
+	store := &Store{Root: t.TempDir()}
+	src := filepath.Join(t.TempDir(), "asset")
+	if err := os.WriteFile(src, []byte("asset"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := store.Lookup("owner/repo", "v1.0.0", "tool.tar.gz"); ok {
+		t.Error("Lookup() found asset in empty cache")
+	}
+	tags, err := store.Tags("owner/repo")
+	if err != nil || len(tags) != 0 {
+		t.Errorf("Tags() = %v, %v, want empty", tags, err)
+	}
+
+	for i, tag := range []string{"v1.0.0", "v1.2.0", "v1.1.0"} {
+		if err := store.Put("owner/repo", tag, "tool.tar.gz", src); err != nil {
+			t.Fatalf("Put(%s) error = %v", tag, err)
+		}
+		mtime := time.Now().Add(time.Duration(i) * time.Minute)
+		if err := os.Chtimes(filepath.Join(store.Root, "assets", "owner", "repo", tag), mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path, ok := store.Lookup("owner/repo", "v1.2.0", "tool.tar.gz")
+	if !ok {
+		t.Fatal("Lookup() did not find stored asset")
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "asset" {
+		t.Errorf("cached asset = %q, %v, want %q", data, err, "asset")
+	}
+
+	tags, err = store.Tags("owner/repo")
+	if err != nil {
+		t.Fatalf("Tags() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"v1.1.0", "v1.2.0", "v1.0.0"}, tags); diff != "" {
+		t.Errorf("Tags() mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := store.Put("owner/repo", "..", "tool.tar.gz", src); err == nil {
+		t.Error("Put() expected error for path traversal tag")
+	}
+}
+
+func TestStoreEntries(t *testing.T) {
+	store := &Store{Root: t.TempDir()}
+	if entries, err := store.Entries(); err != nil || len(entries) != 0 {
+		t.Fatalf("Entries() of an empty cache = %v, %v, want none", entries, err)
+	}
+	src := filepath.Join(t.TempDir(), "asset")
+	if err := os.WriteFile(src, []byte("asset"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, repo := range []string{"owner/repo", "group/sub/project"} {
+		if err := store.Put(repo, "v1.0.0", "tool.tar.gz", src); err != nil {
+			t.Fatal(err)
+		}
+	}
+	toolDir, err := store.ToolDir("owner/repo", "v2.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(toolDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(toolDir, "tool"), []byte("binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(store.Root, ResponsesDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(store.Root, ResponsesDir, "abc.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := store.Entries()
+	if err != nil {
+		t.Fatalf("Entries() error = %v", err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, fmt.Sprintf("%s %s@%s %d", e.Kind, e.Repo, e.Tag, e.Size))
+	}
+	want := []string{
+		"assets group/sub/project@v1.0.0 5",
+		"assets owner/repo@v1.0.0 5",
+		"tools owner/repo@v2.0.0 6",
+		"response @ 2",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Entries() mismatch (-want +got):\n%s", diff)
+	}
+	if size, err := store.Size(); err != nil || size != 18 {
+		t.Errorf("Size() = %d, %v, want 18", size, err)
+	}
+
+	// Removing the only release of a repository removes its directories too
+	if err := store.Remove(entries[0]); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(store.Root, KindAssets, "group")); !os.IsNotExist(err) {
+		t.Errorf("Remove() left the empty repository directory: %v", err)
+	}
+	if _, ok := store.Lookup("owner/repo", "v1.0.0", "tool.tar.gz"); !ok {
+		t.Error("Remove() removed the assets of another repository")
+	}
+}
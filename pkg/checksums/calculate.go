@@ -1,6 +1,7 @@
 package checksums
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,6 +10,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/apex/log"
 	"github.com/binary-install/binstaller/pkg/asset"
@@ -108,6 +110,7 @@ func (e *Embedder) calculateChecksums() (map[string]string, error) {
 type checksumResult struct {
 	Filename string
 	Hash     string
+	size     int64
 }
 
 // downloadFile downloads a file from a URL to a local path
@@ -152,9 +155,12 @@ func (e *Embedder) fetchReleaseAssets() ([]GitHubReleaseAsset, error) {
 	if repo == "" {
 		return nil, fmt.Errorf("repository not specified")
 	}
+	if e.Spec.GetSource() != spec.Github {
+		return nil, fmt.Errorf("calculate mode lists GitHub release assets and does not support source: %s; use --mode download", e.Spec.GetSource())
+	}
 
 	// Construct GitHub API URL
-	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/releases/tags/%s", repo, e.Version)
+	apiURL := fmt.Sprintf("%s/repos/%s/releases/tags/%s", asset.GitHubAPIURL(e.Spec), repo, e.Version)
 
 	// Create authenticated request
 	req, err := httpclient.NewRequestWithGitHubAuth("GET", apiURL)
@@ -186,6 +192,7 @@ func (e *Embedder) fetchReleaseAssets() ([]GitHubReleaseAsset, error) {
 // matchAssetsToTemplate matches GitHub assets to the configured template and extracts platform information
 func (e *Embedder) matchAssetsToTemplate(assets []GitHubReleaseAsset) ([]assetWithDigest, error) {
 	generator := asset.NewFilenameGenerator(e.Spec, e.Version)
+	generator.ReleaseVars = e.ReleaseVars
 	var platforms []spec.Platform
 
 	// Determine which platforms to check
@@ -198,13 +205,21 @@ func (e *Embedder) matchAssetsToTemplate(assets []GitHubReleaseAsset) ([]assetWi
 
 	var matchedAssets []assetWithDigest
 
+	// Generate the filenames of every platform at once
+	all, err := generator.GenerateAll(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
 	// For each platform, check if there's a matching asset
 	for _, platform := range platforms {
-		filename, err := generator.GenerateFilename(spec.PlatformOSString(platform.OS), spec.PlatformArchString(platform.Arch))
-		if err != nil {
-			log.Warnf("Failed to generate filename for %s/%s: %v", spec.PlatformOSString(platform.OS), spec.PlatformArchString(platform.Arch), err)
+		key := asset.Platform{OS: strings.ToLower(spec.PlatformOSString(platform.OS)), Arch: strings.ToLower(spec.PlatformArchString(platform.Arch))}
+		candidates := all[key]
+		if len(candidates) == 0 {
+			log.Warnf("Failed to generate filename for %s", key)
 			continue
 		}
+		filename := candidates[0]
 
 		// Skip empty filenames
 		if filename == "" {
@@ -237,7 +252,8 @@ func (e *Embedder) matchAssetsToTemplate(assets []GitHubReleaseAsset) ([]assetWi
 	return matchedAssets, nil
 }
 
-// downloadAndCalculateChecksums downloads assets and calculates their checksums
+// downloadAndCalculateChecksums downloads assets and calculates their checksums,
+// e.Concurrency assets at a time. Assets that fail are logged and left out.
 func (e *Embedder) downloadAndCalculateChecksums(assets []assetWithDigest) (map[string]string, error) {
 	checksums := make(map[string]string)
 
@@ -248,39 +264,39 @@ func (e *Embedder) downloadAndCalculateChecksums(assets []assetWithDigest) (map[
 	}
 	defer os.RemoveAll(tempDir)
 
-	// Use a wait group to process assets concurrently
-	var wg sync.WaitGroup
+	workers := e.Concurrency
+	if workers < 1 {
+		workers = DefaultConcurrency
+	}
+	workers = min(workers, len(assets))
+	log.Infof("Downloading %d assets (concurrency %d)...", len(assets), workers)
+
+	work := make(chan assetWithDigest)
 	resultCh := make(chan *checksumResult, len(assets))
 	errorCh := make(chan error, len(assets))
-
-	// Process each asset
-	for _, asset := range assets {
+	var done atomic.Int32
+	var wg sync.WaitGroup
+	for range workers {
 		wg.Add(1)
-		go func(a assetWithDigest) {
+		go func() {
 			defer wg.Done()
-
-			// Download the asset
-			assetPath := filepath.Join(tempDir, a.Name)
-
-			log.Infof("Downloading %s", a.URL)
-			if err := downloadFile(a.URL, assetPath); err != nil {
-				errorCh <- fmt.Errorf("failed to download asset %s: %w", a.Name, err)
-				return
-			}
-
-			// Calculate the checksum
-			hash, err := ComputeHash(assetPath, spec.AlgorithmString(e.Spec.Checksums.Algorithm))
-			if err != nil {
-				errorCh <- fmt.Errorf("failed to compute hash for %s: %w", a.Name, err)
-				return
-			}
-
-			resultCh <- &checksumResult{
-				Filename: a.Name,
-				Hash:     hash,
+			for a := range work {
+				result, err := e.downloadAndCalculateChecksum(a, filepath.Join(tempDir, a.Name))
+				n := done.Add(1)
+				if err != nil {
+					log.Warnf("[%d/%d] %s failed", n, len(assets), a.Name)
+					errorCh <- err
+					continue
+				}
+				log.Infof("[%d/%d] %s (%d bytes)", n, len(assets), a.Name, result.size)
+				resultCh <- result
 			}
-		}(asset)
+		}()
+	}
+	for _, a := range assets {
+		work <- a
 	}
+	close(work)
 
 	// Wait for all downloads and hash calculations to finish
 	wg.Wait()
@@ -299,3 +315,21 @@ func (e *Embedder) downloadAndCalculateChecksums(assets []assetWithDigest) (map[
 
 	return checksums, nil
 }
+
+// downloadAndCalculateChecksum downloads an asset to assetPath and calculates
+// its checksum
+func (e *Embedder) downloadAndCalculateChecksum(a assetWithDigest, assetPath string) (*checksumResult, error) {
+	log.Debugf("Downloading %s", a.URL)
+	if err := downloadFile(a.URL, assetPath); err != nil {
+		return nil, fmt.Errorf("failed to download asset %s: %w", a.Name, err)
+	}
+	info, err := os.Stat(assetPath)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := ComputeHash(assetPath, spec.AlgorithmString(e.Spec.Checksums.Algorithm))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute hash for %s: %w", a.Name, err)
+	}
+	return &checksumResult{Filename: a.Name, Hash: hash, size: info.Size()}, nil
+}
@@ -6,7 +6,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/binary-install/binstaller/pkg/spec"
 )
@@ -492,3 +494,50 @@ func TestCalculateChecksumsErrorHandling(t *testing.T) {
 		t.Error("Expected error for nonexistent repository")
 	}
 }
+
+func TestDownloadAndCalculateChecksumsConcurrency(t *testing.T) {
+	var active, peak atomic.Int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := active.Add(1)
+		defer active.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		if strings.Contains(r.URL.Path, "broken") {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer mockServer.Close()
+
+	var assets []assetWithDigest
+	for i := range 10 {
+		name := fmt.Sprintf("tool_%d.tar.gz", i)
+		if i == 3 {
+			name = "tool_broken.tar.gz"
+		}
+		assets = append(assets, assetWithDigest{Name: name, URL: mockServer.URL + "/" + name})
+	}
+	sha256 := spec.Sha256
+	embedder := &Embedder{
+		Spec:        &spec.InstallSpec{Checksums: &spec.Checksums{Algorithm: &sha256}},
+		Version:     "1.0.0",
+		Concurrency: 3,
+	}
+
+	checksums, err := embedder.downloadAndCalculateChecksums(assets)
+	if err != nil {
+		t.Fatalf("downloadAndCalculateChecksums() error = %v", err)
+	}
+	if len(checksums) != 9 {
+		t.Errorf("downloadAndCalculateChecksums() returned %d checksums, want 9 without the broken asset", len(checksums))
+	}
+	if got := peak.Load(); got > 3 {
+		t.Errorf("%d downloads ran at once, want at most 3", got)
+	}
+}
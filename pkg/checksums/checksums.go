@@ -2,11 +2,12 @@ package checksums
 
 import (
 	"bufio"
+	"context"
+	"crypto/md5"
 	"crypto/sha1"
 	"crypto/sha256"
 	"crypto/sha512"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
 	"hash"
 	"io"
@@ -19,6 +20,7 @@ import (
 	"github.com/apex/log"
 	"github.com/binary-install/binstaller/pkg/asset"
 	"github.com/binary-install/binstaller/pkg/httpclient"
+	"github.com/binary-install/binstaller/pkg/resolver"
 	"github.com/binary-install/binstaller/pkg/spec"
 	"github.com/buildkite/interpolate"
 	"github.com/goccy/go-yaml"
@@ -35,8 +37,14 @@ const (
 	EmbedModeChecksumFile EmbedMode = "checksum-file"
 	// EmbedModeCalculate downloads assets and calculates checksums
 	EmbedModeCalculate EmbedMode = "calculate"
+	// EmbedModeGoReleaserArtifacts reads checksums from GoReleaser's dist/artifacts.json
+	EmbedModeGoReleaserArtifacts EmbedMode = "goreleaser-artifacts"
 )
 
+// DefaultConcurrency is the number of assets calculate mode downloads at once
+// by default
+const DefaultConcurrency = 4
+
 // Embedder manages the process of embedding checksums
 type Embedder struct {
 	Mode         EmbedMode
@@ -44,6 +52,12 @@ type Embedder struct {
 	Spec         *spec.InstallSpec
 	SpecAST      *ast.File
 	ChecksumFile string
+	// Concurrency is the number of assets calculate mode downloads at once
+	// (DefaultConcurrency when zero)
+	Concurrency int
+	// ReleaseVars are the values of spec.ReleaseVariables of Version's
+	// release, fetched by Embed when the asset templates reference them
+	ReleaseVars map[string]string
 }
 
 // Embed performs the checksum embedding process and returns the updated spec
@@ -61,13 +75,23 @@ func (e *Embedder) Embed() error {
 	}
 
 	// Validate checksum template for embed-checksums command
-	// Note: ${ASSET_FILENAME} is supported in runtime verification and in calculate mode
-	// but not in download or checksum-file modes because those modes work with a single
-	// checksum file that doesn't have per-asset filenames
-	if e.Mode != "" && e.Mode != EmbedModeCalculate && e.Spec.Checksums.Template != nil && strings.Contains(spec.StringValue(e.Spec.Checksums.Template), "${ASSET_FILENAME}") {
+	// Note: ${ASSET_FILENAME} is supported in runtime verification and in calculate and
+	// goreleaser-artifacts modes but not in download or checksum-file modes because those
+	// modes work with a single checksum file that doesn't have per-asset filenames
+	if e.Mode != "" && e.Mode != EmbedModeCalculate && e.Mode != EmbedModeGoReleaserArtifacts && slices.ContainsFunc(allChecksumTemplates(e.Spec), func(t string) bool {
+		return strings.Contains(t, "${ASSET_FILENAME}")
+	}) {
 		return fmt.Errorf("${ASSET_FILENAME} is not supported in checksum templates for embed-checksums. Use 'binst embed-checksums --mode calculate' instead to generate checksums for all platforms")
 	}
 
+	// GoReleaser records the tag it just released, which may not be published yet
+	if e.Mode == EmbedModeGoReleaserArtifacts && (e.Version == "" || e.Version == "latest") {
+		if tag := goreleaserTag(e.ChecksumFile); tag != "" {
+			log.Infof("Using version from GoReleaser metadata: %s", tag)
+			e.Version = tag
+		}
+	}
+
 	// Resolve version if it's "latest"
 	resolvedVersion, err := e.resolveVersion(e.Version)
 	if err != nil {
@@ -75,6 +99,14 @@ func (e *Embedder) Embed() error {
 	}
 	e.Version = resolvedVersion
 
+	if e.ReleaseVars == nil && e.Spec.UsesReleaseVariables() {
+		metadata, err := resolver.New(e.Spec).ReleaseMetadata(context.Background(), e.Version)
+		if err != nil {
+			return err
+		}
+		e.ReleaseVars = metadata.Vars()
+	}
+
 	// Initialize embedded checksums map if it doesn't exist
 	if e.Spec.Checksums.EmbeddedChecksums == nil {
 		e.Spec.Checksums.EmbeddedChecksums = make(map[string][]spec.EmbeddedChecksum)
@@ -94,6 +126,8 @@ func (e *Embedder) Embed() error {
 		checksums, embedErr = e.parseChecksumFile()
 	case EmbedModeCalculate:
 		checksums, embedErr = e.calculateChecksums()
+	case EmbedModeGoReleaserArtifacts:
+		checksums, embedErr = e.parseGoReleaserArtifacts()
 	default:
 		return fmt.Errorf("invalid mode: %s", e.Mode)
 	}
@@ -123,12 +157,8 @@ func (e *Embedder) Embed() error {
 	if err != nil {
 		return err
 	}
-	// Create a checksumConfig with all existing checksums to preserve them
-	checksumConfig := spec.ChecksumConfig{
-		Algorithm:         e.Spec.Checksums.Algorithm,
-		Template:          e.Spec.Checksums.Template,
-		EmbeddedChecksums: e.Spec.Checksums.EmbeddedChecksums,
-	}
+	// Copy the whole checksums section to preserve existing checksums and settings
+	checksumConfig := *e.Spec.Checksums
 	node, err := yaml.ValueToNode(checksumConfig)
 	if err != nil {
 		return err
@@ -145,11 +175,6 @@ func (e *Embedder) Embed() error {
 	return nil
 }
 
-// githubRelease represents the minimal structure needed from GitHub release API
-type githubRelease struct {
-	TagName string `json:"tag_name"`
-}
-
 // resolveVersion resolves "latest" or empty version to an actual version string
 func (e *Embedder) resolveVersion(version string) (string, error) {
 	if version != "latest" && version != "" {
@@ -160,74 +185,61 @@ func (e *Embedder) resolveVersion(version string) (string, error) {
 		return "", fmt.Errorf("repository not specified in spec")
 	}
 
-	// Use GitHub API to get the latest release
-	url := fmt.Sprintf("https://api.github.com/repos/%s/releases/latest", spec.StringValue(e.Spec.Repo))
-
 	// Log authentication status for debugging
-	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+	if token := httpclient.GitHubToken(); token != "" {
 		log.Debugf("Using GitHub token for latest release API call (length: %d)", len(token))
 	} else {
-		log.Warnf("No GITHUB_TOKEN found for latest release API call (may hit rate limits)")
+		log.Warnf("No GitHub token found for latest release API call (may hit rate limits)")
 	}
 
-	// Set up the request with Accept header for JSON response
-	req, err := httpclient.NewRequestWithGitHubAuth("GET", url)
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-
-	// Send the request
-	client := httpclient.NewGitHubClient()
-	resp, err := client.Do(req)
+	resolved, err := resolver.New(e.Spec).Latest(context.Background())
 	if err != nil {
 		return "", fmt.Errorf("failed to get latest release: %w", err)
 	}
-	defer resp.Body.Close()
 
-	// Check for successful response
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		bodyText := string(bodyBytes)
-		if bodyText != "" {
-			return "", fmt.Errorf("failed to get latest release, status code: %d, response: %s", resp.StatusCode, bodyText)
-		}
-		return "", fmt.Errorf("failed to get latest release, status code: %d", resp.StatusCode)
-	}
-
-	// Parse the JSON response
-	var release githubRelease
-	decoder := json.NewDecoder(resp.Body)
-	if err := decoder.Decode(&release); err != nil {
-		return "", fmt.Errorf("failed to parse GitHub API response: %w", err)
-	}
-
-	if release.TagName == "" {
-		return "", fmt.Errorf("empty tag name returned from GitHub")
-	}
-
-	log.Infof("Resolved latest version: %s", release.TagName)
-	return release.TagName, nil
+	log.Infof("Resolved latest version: %s", resolved)
+	return resolved, nil
 }
 
-// downloadAndParseChecksumFile downloads a checksum file from GitHub releases and parses it
+// downloadAndParseChecksumFile downloads the checksum files from GitHub releases and parses them.
+// Projects that split checksums per platform select files with asset rules' checksum_template,
+// so every distinct checksum file is downloaded and the results are merged.
 func (e *Embedder) downloadAndParseChecksumFile() (map[string]string, error) {
-	// Create the expected checksum URL using the spec template
-	checksumFilename := e.createChecksumFilename()
-	if checksumFilename == "" {
+	checksums := make(map[string]string)
+	generator := asset.NewFilenameGenerator(e.Spec, e.Version)
+	for _, template := range generator.PossibleChecksumTemplates() {
+		// Create the expected checksum filename using the template
+		checksumFilename := e.createChecksumFilenameFromTemplate(template, "")
+		if checksumFilename == "" {
+			return nil, fmt.Errorf("unable to generate checksum filename")
+		}
+		fileChecksums, err := e.downloadChecksumFile(checksumFilename)
+		if err != nil {
+			return nil, err
+		}
+		for filename, hash := range fileChecksums {
+			checksums[filename] = hash
+		}
+	}
+	if len(checksums) == 0 {
 		return nil, fmt.Errorf("unable to generate checksum filename")
 	}
 
-	checksumURL := fmt.Sprintf("https://github.com/%s/releases/download/%s/%s",
-		spec.StringValue(e.Spec.Repo), e.Version, checksumFilename)
+	// Filter checksums based on asset template
+	return e.filterChecksums(checksums), nil
+}
+
+// downloadChecksumFile downloads a single checksum file from GitHub releases and parses it
+func (e *Embedder) downloadChecksumFile(checksumFilename string) (map[string]string, error) {
+	checksumURL := asset.DownloadURL(e.Spec, "", e.Version, checksumFilename)
 
 	log.Infof("Downloading checksums from %s", checksumURL)
 
 	// Log authentication status for debugging
-	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+	if token := httpclient.GitHubToken(); token != "" {
 		log.Debugf("Using GitHub token for authentication (length: %d)", len(token))
 	} else {
-		log.Warnf("No GITHUB_TOKEN found, making unauthenticated request (may hit rate limits)")
+		log.Warnf("No GitHub token found, making unauthenticated request (may hit rate limits)")
 	}
 
 	// Create a temporary file to store the checksum file
@@ -273,14 +285,19 @@ func (e *Embedder) downloadAndParseChecksumFile() (map[string]string, error) {
 		return nil, fmt.Errorf("failed to save checksum file: %w", err)
 	}
 
-	// Parse the checksum file
-	checksums, err := parseChecksumFileInternal(tempFilePath)
-	if err != nil {
-		return nil, err
+	// Verify the checksum file signature before trusting its contents
+	if hasChecksumFileSignature(e.Spec) {
+		content, err := os.ReadFile(tempFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read checksum file: %w", err)
+		}
+		if err := verifyChecksumFileSignature(context.Background(), e.Spec, e.Version, checksumFilename, content, fetchReleaseSibling(context.Background(), checksumURL)); err != nil {
+			return nil, err
+		}
 	}
 
-	// Filter checksums based on asset template
-	return e.filterChecksums(checksums), nil
+	// Parse the checksum file
+	return parseChecksumFileInternal(tempFilePath)
 }
 
 // parseChecksumFile parses a local checksum file
@@ -289,6 +306,17 @@ func (e *Embedder) parseChecksumFile() (map[string]string, error) {
 		return nil, fmt.Errorf("checksum file path is required for checksum-file mode")
 	}
 
+	// Verify the checksum file signature before trusting its contents
+	if hasChecksumFileSignature(e.Spec) {
+		content, err := os.ReadFile(e.ChecksumFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read checksum file: %w", err)
+		}
+		if err := verifyChecksumFileSignature(context.Background(), e.Spec, e.Version, filepath.Base(e.ChecksumFile), content, fetchLocalSibling(e.ChecksumFile)); err != nil {
+			return nil, err
+		}
+	}
+
 	log.Infof("Parsing checksums from file: %s", e.ChecksumFile)
 	checksums, err := parseChecksumFileInternal(e.ChecksumFile)
 	if err != nil {
@@ -330,6 +358,9 @@ func parseChecksumFileInternal(checksumFile string) (map[string]string, error) {
 
 		// If the filename starts with *, remove it (common in standard checksums)
 		filename = strings.TrimPrefix(filename, "*")
+		if filename == "" {
+			continue
+		}
 
 		checksums[filename] = hash
 	}
@@ -345,6 +376,22 @@ func parseChecksumFileInternal(checksumFile string) (map[string]string, error) {
 	return checksums, nil
 }
 
+// allChecksumTemplates returns checksums.template and every asset rule's checksum_template
+func allChecksumTemplates(installSpec *spec.InstallSpec) []string {
+	var templates []string
+	if t := installSpec.GetChecksums().GetTemplate(); t != "" {
+		templates = append(templates, t)
+	}
+	if installSpec.GetAsset() != nil {
+		for _, rule := range installSpec.Asset.Rules {
+			if t := rule.GetChecksumTemplate(); t != "" {
+				templates = append(templates, t)
+			}
+		}
+	}
+	return templates
+}
+
 // interpolateTemplate performs variable substitution in a template string
 func (e *Embedder) interpolateTemplate(template string, additionalVars map[string]string) (string, error) {
 	// Create base environment map with variables supported by all templates
@@ -374,12 +421,16 @@ func (e *Embedder) createChecksumFilename() string {
 
 // createChecksumFilenameWithAsset creates the checksum filename with optional asset filename support
 func (e *Embedder) createChecksumFilenameWithAsset(assetFilename string) string {
-	if e.Spec.Checksums == nil || spec.StringValue(e.Spec.Checksums.Template) == "" {
+	return e.createChecksumFilenameFromTemplate(e.Spec.GetChecksums().GetTemplate(), assetFilename)
+}
+
+// createChecksumFilenameFromTemplate creates a checksum filename from the given template
+// with optional asset filename support
+func (e *Embedder) createChecksumFilenameFromTemplate(template, assetFilename string) string {
+	if template == "" {
 		return ""
 	}
 
-	template := spec.StringValue(e.Spec.Checksums.Template)
-
 	// Check for unsupported ASSET_FILENAME variable in embed-checksums mode
 	if strings.Contains(template, "${ASSET_FILENAME}") {
 		if e.Mode != "" {
@@ -410,6 +461,7 @@ func (e *Embedder) createChecksumFilenameWithAsset(assetFilename string) string
 func (e *Embedder) filterChecksums(checksums map[string]string) map[string]string {
 	// Generate all possible asset filenames
 	generator := asset.NewFilenameGenerator(e.Spec, e.Version)
+	generator.ReleaseVars = e.ReleaseVars
 	possibleFilenames := generator.GeneratePossibleFilenames()
 	if len(possibleFilenames) == 0 {
 		log.Warn("No possible asset filenames could be generated, returning all checksums")
@@ -444,6 +496,8 @@ func ComputeHash(filepath string, algorithm string) (string, error) {
 		h = sha256.New()
 	case "sha1":
 		h = sha1.New()
+	case "md5":
+		h = md5.New()
 	case "sha512":
 		h = sha512.New()
 	default:
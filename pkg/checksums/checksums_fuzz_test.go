@@ -0,0 +1,53 @@
+package checksums
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// FuzzParseChecksumContent checks that checksum file parsing never panics and
+// only yields well-formed entries.
+func FuzzParseChecksumContent(f *testing.F) {
+	f.Add("e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  tool_1.0.0_linux_amd64.tar.gz\n")
+	f.Add("abc *tool.zip\r\n# comment\n\nbad-line\n")
+	f.Add("abc\tdeployment/m2/file.tar.gz")
+	f.Add("*\n* *\n")
+
+	f.Fuzz(func(t *testing.T, content string) {
+		for filename, hash := range parseChecksumContent(content) {
+			if filename == "" || hash == "" {
+				t.Fatalf("empty entry parsed: filename=%q hash=%q", filename, hash)
+			}
+			if strings.ContainsAny(hash, " \t\n") {
+				t.Fatalf("hash %q contains whitespace", hash)
+			}
+		}
+	})
+}
+
+// FuzzParseChecksumFileInternal checks that the file-based parser agrees with
+// the in-memory parser on every non-empty result.
+func FuzzParseChecksumFileInternal(f *testing.F) {
+	f.Add("abc  tool.tar.gz\ndef *tool.zip\n")
+	f.Add("# only a comment\n")
+
+	dir := f.TempDir()
+	f.Fuzz(func(t *testing.T, content string) {
+		path := filepath.Join(dir, "checksums.txt")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		fromFile, err := parseChecksumFileInternal(path)
+		if err != nil {
+			return
+		}
+		fromContent := parseChecksumContent(content)
+		for filename, hash := range fromFile {
+			if fromContent[filename] != hash {
+				t.Fatalf("parsers disagree for %q: file=%q content=%q", filename, hash, fromContent[filename])
+			}
+		}
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/binary-install/binstaller/pkg/spec"
 	"github.com/goccy/go-yaml"
@@ -216,6 +217,26 @@ func TestFilterChecksumsNoAssetTemplate(t *testing.T) {
 	}
 }
 
+func TestFilterChecksumsReleaseVars(t *testing.T) {
+	testSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}-${PUBLISHED_DATE}-${OS}-${ARCH}${EXT}").WithDefaultExtension(".tar.gz")).
+		WithSupportedPlatforms("linux/amd64")
+	testSpec.SetDefaults()
+
+	embedder := &Embedder{
+		Spec:        testSpec,
+		Version:     "nightly",
+		ReleaseVars: spec.ReleaseVars("nightly", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)),
+	}
+	filtered := embedder.filterChecksums(map[string]string{
+		"tool-2024-05-01-linux-amd64.tar.gz": "abc123",
+		"tool-2024-04-30-linux-amd64.tar.gz": "def456",
+	})
+	if len(filtered) != 1 || filtered["tool-2024-05-01-linux-amd64.tar.gz"] != "abc123" {
+		t.Errorf("filterChecksums() = %v, want only the asset of the release date", filtered)
+	}
+}
+
 func TestEmbedder_EmbedWithMissingChecksumsField(t *testing.T) {
 	// Test that the embed command works when checksums field is missing (GitHub issue #84)
 	tempDir, err := os.MkdirTemp("", "embed-checksums-test")
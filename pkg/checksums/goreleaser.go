@@ -0,0 +1,125 @@
+package checksums
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+)
+
+// goreleaserArtifact represents the fields binstaller needs from an entry in
+// GoReleaser's dist/artifacts.json
+type goreleaserArtifact struct {
+	Name  string         `json:"name"`
+	Path  string         `json:"path"`
+	Type  string         `json:"type"`
+	Extra map[string]any `json:"extra"`
+}
+
+// goreleaserMetadata represents the fields binstaller needs from GoReleaser's
+// dist/metadata.json
+type goreleaserMetadata struct {
+	Tag string `json:"tag"`
+}
+
+// parseGoReleaserArtifacts reads checksums from GoReleaser's artifact metadata.
+// GoReleaser records the checksum of each uploadable artifact in its "Checksum"
+// extra as "<algorithm>:<hash>". If no artifact carries one (e.g. the checksum
+// pipe ran with a different algorithm), the checksum file artifact is parsed instead.
+func (e *Embedder) parseGoReleaserArtifacts() (map[string]string, error) {
+	if e.ChecksumFile == "" {
+		return nil, fmt.Errorf("artifacts file path is required for goreleaser-artifacts mode")
+	}
+
+	log.Infof("Reading GoReleaser artifacts from file: %s", e.ChecksumFile)
+	artifacts, err := readGoReleaserArtifacts(e.ChecksumFile)
+	if err != nil {
+		return nil, err
+	}
+
+	algorithm := string(e.Spec.GetChecksums().GetAlgorithm())
+	checksums := make(map[string]string)
+	var checksumFiles []string
+	for _, a := range artifacts {
+		if a.Type == "Checksum" {
+			checksumFiles = append(checksumFiles, a.Path)
+			continue
+		}
+		value, ok := a.Extra["Checksum"].(string)
+		if !ok || value == "" || a.Name == "" {
+			continue
+		}
+		algo, hash, ok := strings.Cut(value, ":")
+		if !ok || hash == "" {
+			log.Warnf("Ignoring malformed checksum for artifact %s: %s", a.Name, value)
+			continue
+		}
+		if !strings.EqualFold(algo, algorithm) {
+			log.Debugf("Ignoring %s checksum for artifact %s, spec uses %s", algo, a.Name, algorithm)
+			continue
+		}
+		checksums[a.Name] = hash
+	}
+
+	if len(checksums) == 0 {
+		for _, path := range checksumFiles {
+			path = resolveGoReleaserPath(e.ChecksumFile, path)
+			log.Infof("No %s checksums in artifact metadata, parsing checksum file: %s", algorithm, path)
+			fileChecksums, err := parseChecksumFileInternal(path)
+			if err != nil {
+				return nil, err
+			}
+			for filename, hash := range fileChecksums {
+				checksums[filename] = hash
+			}
+		}
+	}
+
+	if len(checksums) == 0 {
+		return nil, fmt.Errorf("no %s checksums found in GoReleaser artifacts file %s", algorithm, e.ChecksumFile)
+	}
+
+	// Filter checksums based on asset template
+	return e.filterChecksums(checksums), nil
+}
+
+// readGoReleaserArtifacts parses GoReleaser's artifacts.json
+func readGoReleaserArtifacts(artifactsFile string) ([]goreleaserArtifact, error) {
+	data, err := os.ReadFile(artifactsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read artifacts file: %w", err)
+	}
+	var artifacts []goreleaserArtifact
+	if err := json.Unmarshal(data, &artifacts); err != nil {
+		return nil, fmt.Errorf("failed to parse artifacts file %s: %w", artifactsFile, err)
+	}
+	return artifacts, nil
+}
+
+// goreleaserTag returns the release tag recorded in the metadata.json that
+// GoReleaser writes next to artifacts.json, or "" if it is unavailable
+func goreleaserTag(artifactsFile string) string {
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(artifactsFile), "metadata.json"))
+	if err != nil {
+		return ""
+	}
+	var metadata goreleaserMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		log.Debugf("Failed to parse GoReleaser metadata: %v", err)
+		return ""
+	}
+	return metadata.Tag
+}
+
+// resolveGoReleaserPath resolves an artifact path from artifacts.json.
+// GoReleaser records paths relative to the project root (e.g. dist/checksums.txt),
+// so fall back to the artifacts file directory when the path doesn't exist as-is.
+func resolveGoReleaserPath(artifactsFile, path string) string {
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+	return filepath.Join(filepath.Dir(artifactsFile), filepath.Base(path))
+}
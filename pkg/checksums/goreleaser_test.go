@@ -0,0 +1,167 @@
+package checksums
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/google/go-cmp/cmp"
+)
+
+const goreleaserTestConfig = `name: test-tool
+repo: test-owner/test-repo
+asset:
+  template: ${NAME}_${VERSION}_${OS}_${ARCH}${EXT}
+  default_extension: .tar.gz
+  rules:
+    - when:
+        os: windows
+      ext: .zip
+supported_platforms:
+  - os: linux
+    arch: amd64
+  - os: windows
+    arch: amd64
+`
+
+const goreleaserTestArtifacts = `[
+  {"name": "test-tool", "path": "dist/test-tool_linux_amd64_v1/test-tool", "goos": "linux", "goarch": "amd64", "type": "Binary", "extra": {"Binary": "test-tool"}},
+  {"name": "test-tool_1.0.0_linux_amd64.tar.gz", "path": "dist/test-tool_1.0.0_linux_amd64.tar.gz", "goos": "linux", "goarch": "amd64", "type": "Archive", "extra": {"Checksum": "sha256:abc123", "Format": "tar.gz"}},
+  {"name": "test-tool_1.0.0_windows_amd64.zip", "path": "dist/test-tool_1.0.0_windows_amd64.zip", "goos": "windows", "goarch": "amd64", "type": "Archive", "extra": {"Checksum": "sha256:def456", "Format": "zip"}},
+  {"name": "test-tool_1.0.0_linux_amd64.deb", "path": "dist/test-tool_1.0.0_linux_amd64.deb", "type": "Linux Package", "extra": {"Checksum": "sha256:0123ab"}},
+  {"name": "checksums.txt", "path": "dist/checksums.txt", "type": "Checksum", "extra": {}}
+]`
+
+func TestEmbedder_GoReleaserArtifacts(t *testing.T) {
+	tests := []struct {
+		name      string
+		artifacts string
+		checksums string // contents of dist/checksums.txt, if any
+		metadata  string // contents of dist/metadata.json, if any
+		version   string
+		want      map[string]string
+		wantVer   string
+	}{
+		{
+			name:      "checksums from artifact extras",
+			artifacts: goreleaserTestArtifacts,
+			version:   "v1.0.0",
+			want: map[string]string{
+				"test-tool_1.0.0_linux_amd64.tar.gz": "abc123",
+				"test-tool_1.0.0_windows_amd64.zip":  "def456",
+			},
+			wantVer: "v1.0.0",
+		},
+		{
+			name:      "version from goreleaser metadata",
+			artifacts: goreleaserTestArtifacts,
+			metadata:  `{"project_name": "test-tool", "tag": "v1.0.0", "version": "1.0.0"}`,
+			want: map[string]string{
+				"test-tool_1.0.0_linux_amd64.tar.gz": "abc123",
+				"test-tool_1.0.0_windows_amd64.zip":  "def456",
+			},
+			wantVer: "v1.0.0",
+		},
+		{
+			name: "falls back to checksum file artifact",
+			artifacts: `[
+  {"name": "test-tool_1.0.0_linux_amd64.tar.gz", "path": "dist/test-tool_1.0.0_linux_amd64.tar.gz", "type": "Archive", "extra": {"Checksum": "sha512:ffff"}},
+  {"name": "checksums.txt", "path": "dist/checksums.txt", "type": "Checksum"}
+]`,
+			checksums: "abc123  test-tool_1.0.0_linux_amd64.tar.gz\n999999  unrelated.txt\n",
+			version:   "v1.0.0",
+			want: map[string]string{
+				"test-tool_1.0.0_linux_amd64.tar.gz": "abc123",
+			},
+			wantVer: "v1.0.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			distDir := filepath.Join(tempDir, "dist")
+			if err := os.MkdirAll(distDir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			configFile := filepath.Join(tempDir, "config.yml")
+			artifactsFile := filepath.Join(distDir, "artifacts.json")
+			files := map[string]string{
+				configFile:                              goreleaserTestConfig,
+				artifactsFile:                           tt.artifacts,
+				filepath.Join(distDir, "checksums.txt"): tt.checksums,
+				filepath.Join(distDir, "metadata.json"): tt.metadata,
+			}
+			for path, content := range files {
+				if content == "" {
+					continue
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ast, err := parser.ParseFile(configFile, parser.ParseComments)
+			if err != nil {
+				t.Fatalf("Failed to parse config file: %v", err)
+			}
+			var installSpec spec.InstallSpec
+			if err := yaml.Unmarshal([]byte(goreleaserTestConfig), &installSpec); err != nil {
+				t.Fatalf("Failed to unmarshal config: %v", err)
+			}
+
+			embedder := &Embedder{
+				Mode:         EmbedModeGoReleaserArtifacts,
+				Version:      tt.version,
+				Spec:         &installSpec,
+				SpecAST:      ast,
+				ChecksumFile: artifactsFile,
+			}
+			if err := embedder.Embed(); err != nil {
+				t.Fatalf("Embed() failed: %v", err)
+			}
+
+			got := make(map[string]string)
+			for _, ec := range installSpec.Checksums.EmbeddedChecksums[tt.wantVer] {
+				got[spec.StringValue(ec.Filename)] = spec.StringValue(ec.Hash)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("embedded checksums mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEmbedder_GoReleaserArtifactsErrors(t *testing.T) {
+	tempDir := t.TempDir()
+	artifactsFile := filepath.Join(tempDir, "artifacts.json")
+	if err := os.WriteFile(artifactsFile, []byte(`[{"name": "a.tar.gz", "type": "Archive"}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	invalidFile := filepath.Join(tempDir, "invalid.json")
+	if err := os.WriteFile(invalidFile, []byte(`{not json`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, file := range map[string]string{
+		"no file":      "",
+		"missing file": filepath.Join(tempDir, "missing.json"),
+		"invalid json": invalidFile,
+		"no checksums": artifactsFile,
+	} {
+		t.Run(name, func(t *testing.T) {
+			embedder := &Embedder{
+				Mode:         EmbedModeGoReleaserArtifacts,
+				Version:      "v1.0.0",
+				Spec:         spec.NewInstallSpec("owner/a").WithAsset(spec.NewAsset("${NAME}${EXT}")),
+				ChecksumFile: file,
+			}
+			if _, err := embedder.parseGoReleaserArtifacts(); err == nil {
+				t.Error("parseGoReleaserArtifacts() expected error, got nil")
+			}
+		})
+	}
+}
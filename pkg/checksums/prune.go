@@ -0,0 +1,45 @@
+package checksums
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+)
+
+// PruneEmbeddedChecksums removes the embedded checksums of all but the keep
+// newest versions, ordered by spec.CompareVersions, from the spec and its AST.
+// It returns the removed versions, newest first.
+func PruneEmbeddedChecksums(installSpec *spec.InstallSpec, specAST *ast.File, keep int) ([]string, error) {
+	if keep < 1 {
+		return nil, fmt.Errorf("the number of versions to keep must be at least 1")
+	}
+	if installSpec.Checksums == nil || len(installSpec.Checksums.EmbeddedChecksums) <= keep {
+		return nil, nil
+	}
+
+	versions := make([]string, 0, len(installSpec.Checksums.EmbeddedChecksums))
+	for version := range installSpec.Checksums.EmbeddedChecksums {
+		versions = append(versions, version)
+	}
+	slices.SortFunc(versions, func(a, b string) int { return spec.CompareVersions(b, a) })
+	removed := versions[keep:]
+	for _, version := range removed {
+		delete(installSpec.Checksums.EmbeddedChecksums, version)
+	}
+
+	p, err := yaml.PathString("$.checksums")
+	if err != nil {
+		return nil, err
+	}
+	node, err := yaml.ValueToNode(*installSpec.Checksums)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.MergeFromNode(specAST, node); err != nil {
+		return nil, fmt.Errorf("failed to update embedded checksums: %w", err)
+	}
+	return removed, nil
+}
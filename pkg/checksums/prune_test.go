@@ -0,0 +1,75 @@
+package checksums
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPruneEmbeddedChecksums(t *testing.T) {
+	config := `# Tool installer
+name: tool
+repo: owner/tool
+checksums:
+  # Checksums published with each release
+  template: checksums.txt
+  embedded_checksums:
+    v1.2.0:
+      - filename: tool_linux_amd64
+        hash: "12"
+    v1.10.0:
+      - filename: tool_linux_amd64
+        hash: "110"
+    v1.9.0:
+      - filename: tool_linux_amd64
+        hash: "19"
+    v1.10.1:
+      - filename: tool_linux_amd64
+        hash: "1101"
+`
+	specAST, err := parser.ParseBytes([]byte(config), parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var installSpec spec.InstallSpec
+	if err := yaml.UnmarshalWithOptions([]byte(config), &installSpec, yaml.UseOrderedMap()); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := PruneEmbeddedChecksums(&installSpec, specAST, 2)
+	if err != nil {
+		t.Fatalf("PruneEmbeddedChecksums() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"v1.9.0", "v1.2.0"}, removed); diff != "" {
+		t.Errorf("PruneEmbeddedChecksums() removed mismatch (-want +got):\n%s", diff)
+	}
+
+	var pruned spec.InstallSpec
+	if err := yaml.Unmarshal([]byte(specAST.String()), &pruned); err != nil {
+		t.Fatalf("pruned spec does not parse: %v\n%s", err, specAST.String())
+	}
+	var versions []string
+	for version := range pruned.Checksums.EmbeddedChecksums {
+		versions = append(versions, version)
+	}
+	if len(versions) != 2 || pruned.Checksums.EmbeddedChecksums["v1.10.0"] == nil || pruned.Checksums.EmbeddedChecksums["v1.10.1"] == nil {
+		t.Errorf("pruned spec has the checksums of %v, want v1.10.0 and v1.10.1:\n%s", versions, specAST.String())
+	}
+	for _, comment := range []string{"# Tool installer", "# Checksums published with each release"} {
+		if !strings.Contains(specAST.String(), comment) {
+			t.Errorf("pruning lost the comment %q:\n%s", comment, specAST.String())
+		}
+	}
+
+	// Nothing is removed when no more versions than kept are embedded
+	if removed, err := PruneEmbeddedChecksums(&installSpec, specAST, 2); err != nil || len(removed) != 0 {
+		t.Errorf("PruneEmbeddedChecksums() again = %v, %v, want nothing removed", removed, err)
+	}
+	if _, err := PruneEmbeddedChecksums(&installSpec, specAST, 0); err == nil {
+		t.Error("PruneEmbeddedChecksums() accepted keeping no versions")
+	}
+}
@@ -0,0 +1,158 @@
+package checksums
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/cosign"
+	"github.com/binary-install/binstaller/pkg/httpclient"
+	"github.com/binary-install/binstaller/pkg/signature"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// ErrSignatureVerification is returned when a checksum file fails signature verification.
+// Unlike a missing checksum, it is never downgraded to a warning.
+var ErrSignatureVerification = errors.New("checksum file signature verification failed")
+
+// verifyChecksumFileSignature verifies the signatures of the checksum file
+// checksumName of version configured in checksums.cosign and checksums.signature.
+// fetch returns the file with the given name published next to the checksum file.
+func verifyChecksumFileSignature(ctx context.Context, installSpec *spec.InstallSpec, version, checksumName string, content []byte, fetch func(name string) ([]byte, error)) error {
+	if err := verifyCosignSignature(ctx, installSpec, version, checksumName, content, fetch); err != nil {
+		return err
+	}
+	return verifyDetachedSignature(ctx, installSpec, version, checksumName, content, fetch)
+}
+
+// verifyCosignSignature verifies the cosign signature of the checksum file of
+// version when checksums.cosign is configured. Only the identities trusted for
+// version are accepted.
+func verifyCosignSignature(ctx context.Context, installSpec *spec.InstallSpec, version, checksumName string, content []byte, fetch func(name string) ([]byte, error)) error {
+	cfg := installSpec.GetChecksums().GetCosign()
+	if cfg == nil {
+		return nil
+	}
+	trusted, err := cfg.TrustedIdentities(version)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSignatureVerification, err)
+	}
+	if len(trusted) == 0 {
+		return fmt.Errorf("%w: no cosign identity is trusted for version %s", ErrSignatureVerification, version)
+	}
+	identities := make([]cosign.Identity, 0, len(trusted))
+	var names []string
+	for _, id := range trusted {
+		identities = append(identities, cosign.Identity{
+			CertificateIdentityRegexp: spec.StringValue(id.CertificateIdentityRegexp),
+			CertificateOIDCIssuer:     spec.StringValue(id.CertificateOidcIssuer),
+		})
+		names = append(names, spec.StringValue(id.CertificateIdentityRegexp))
+	}
+
+	sig, err := fetch(checksumName + ".sig")
+	if err != nil {
+		return fmt.Errorf("%w: failed to get signature: %w", ErrSignatureVerification, err)
+	}
+	certificate, err := fetch(checksumName + ".pem")
+	if err != nil {
+		return fmt.Errorf("%w: failed to get certificate: %w", ErrSignatureVerification, err)
+	}
+
+	verifier := &cosign.Verifier{
+		Identities: identities,
+		RekorURL:   cfg.GetRekorURL(),
+	}
+	if err := verifier.VerifyBlob(ctx, content, sig, certificate); err != nil {
+		return fmt.Errorf("%w: %w", ErrSignatureVerification, err)
+	}
+
+	log.Infof("Verified cosign signature of checksum file (trusted identities: %s)", strings.Join(names, ", "))
+	return nil
+}
+
+// verifyDetachedSignature verifies the GPG or minisign signature of the checksum
+// file when checksums.signature is configured
+func verifyDetachedSignature(ctx context.Context, installSpec *spec.InstallSpec, version, checksumName string, content []byte, fetch func(name string) ([]byte, error)) error {
+	cfg := installSpec.GetChecksums().GetSignature()
+	if cfg == nil {
+		return nil
+	}
+	embedder := &Embedder{Spec: installSpec, Version: version}
+	signatureName, err := embedder.interpolateTemplate(cfg.GetTemplate(), map[string]string{"CHECKSUM_FILENAME": checksumName})
+	if err != nil {
+		return fmt.Errorf("%w: invalid signature template: %w", ErrSignatureVerification, err)
+	}
+	signatureContent, err := fetch(signatureName)
+	if err != nil {
+		return fmt.Errorf("%w: failed to get signature: %w", ErrSignatureVerification, err)
+	}
+	key := []byte(cfg.GetKey())
+	if keyring := cfg.GetKeyring(); keyring != "" {
+		if key, err = download(ctx, keyring); err != nil {
+			return fmt.Errorf("%w: failed to get public key: %w", ErrSignatureVerification, err)
+		}
+	}
+
+	switch cfg.GetFormat() {
+	case spec.Minisign:
+		comment, err := signature.VerifyMinisign(key, content, signatureContent)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrSignatureVerification, err)
+		}
+		log.Infof("Verified minisign signature of checksum file (trusted comment: %s)", comment)
+	default:
+		fingerprint, err := signature.VerifyGPG(key, content, signatureContent)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrSignatureVerification, err)
+		}
+		log.Infof("Verified gpg signature of checksum file (key %s)", fingerprint)
+	}
+	return nil
+}
+
+// hasChecksumFileSignature reports whether the checksum file must be verified
+// before it is trusted
+func hasChecksumFileSignature(installSpec *spec.InstallSpec) bool {
+	checksums := installSpec.GetChecksums()
+	return checksums.GetCosign() != nil || checksums.GetSignature() != nil
+}
+
+// fetchLocalSibling returns a fetch function reading files next to a local checksum file
+func fetchLocalSibling(checksumFile string) func(string) ([]byte, error) {
+	return func(name string) ([]byte, error) {
+		return os.ReadFile(filepath.Join(filepath.Dir(checksumFile), name))
+	}
+}
+
+// fetchReleaseSibling returns a fetch function downloading files next to a checksum file URL
+func fetchReleaseSibling(ctx context.Context, checksumURL string) func(string) ([]byte, error) {
+	base := checksumURL[:strings.LastIndex(checksumURL, "/")+1]
+	return func(name string) ([]byte, error) {
+		return download(ctx, base+name)
+	}
+}
+
+// download returns the content of url
+func download(ctx context.Context, url string) ([]byte, error) {
+	log.Debugf("Downloading %s", url)
+	req, err := httpclient.NewRequestWithGitHubAuth("GET", url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := httpclient.NewGitHubClient().Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s, status code: %d", url, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,187 @@
+package checksums
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/binary-install/binstaller/pkg/spec"
+	"golang.org/x/crypto/blake2b"
+)
+
+func TestEmbedder_ChecksumFileSignatureRequired(t *testing.T) {
+	tempDir := t.TempDir()
+	checksumFile := filepath.Join(tempDir, "checksums.txt")
+	if err := os.WriteFile(checksumFile, []byte("abc123  tool_1.0.0_linux_amd64.tar.gz\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	newSpec := func() *spec.InstallSpec {
+		return spec.NewInstallSpec("owner/tool").
+			WithAsset(spec.NewAsset("${NAME}_${VERSION}_${OS}_${ARCH}.tar.gz")).
+			WithChecksums(spec.NewChecksums("checksums.txt"))
+	}
+
+	t.Run("unsigned file is accepted without cosign config", func(t *testing.T) {
+		embedder := &Embedder{Mode: EmbedModeChecksumFile, Version: "v1.0.0", Spec: newSpec(), ChecksumFile: checksumFile}
+		if _, err := embedder.parseChecksumFile(); err != nil {
+			t.Fatalf("parseChecksumFile() error = %v", err)
+		}
+	})
+
+	t.Run("missing signature is rejected with cosign config", func(t *testing.T) {
+		s := newSpec()
+		s.Checksums.WithCosign(spec.NewCosign(`^https://github\.com/owner/tool/`, "https://token.actions.githubusercontent.com"))
+		embedder := &Embedder{Mode: EmbedModeChecksumFile, Version: "v1.0.0", Spec: s, ChecksumFile: checksumFile}
+		_, err := embedder.parseChecksumFile()
+		if !errors.Is(err, ErrSignatureVerification) {
+			t.Fatalf("parseChecksumFile() error = %v, want ErrSignatureVerification", err)
+		}
+	})
+
+	t.Run("invalid signature is rejected with cosign config", func(t *testing.T) {
+		for suffix, content := range map[string]string{".sig": "bm90LWEtc2lnbmF0dXJl", ".pem": "bm90LWEtY2VydA=="} {
+			if err := os.WriteFile(checksumFile+suffix, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		s := newSpec()
+		s.Checksums.WithCosign(spec.NewCosign(`^https://github\.com/owner/tool/`, "https://token.actions.githubusercontent.com"))
+		embedder := &Embedder{Mode: EmbedModeChecksumFile, Version: "v1.0.0", Spec: s, ChecksumFile: checksumFile}
+		_, err := embedder.parseChecksumFile()
+		if !errors.Is(err, ErrSignatureVerification) {
+			t.Fatalf("parseChecksumFile() error = %v, want ErrSignatureVerification", err)
+		}
+	})
+
+	t.Run("version outside every identity window is rejected", func(t *testing.T) {
+		s := newSpec()
+		s.Checksums.WithCosign((&spec.Cosign{}).
+			WithIdentity(`^https://github\.com/owner/tool/`, "https://token.actions.githubusercontent.com", "v2.0.0", ""))
+		embedder := &Embedder{Mode: EmbedModeChecksumFile, Version: "v1.0.0", Spec: s, ChecksumFile: checksumFile}
+		_, err := embedder.parseChecksumFile()
+		if !errors.Is(err, ErrSignatureVerification) || !strings.Contains(err.Error(), "no cosign identity is trusted for version v1.0.0") {
+			t.Fatalf("parseChecksumFile() error = %v, want no trusted identity for v1.0.0", err)
+		}
+	})
+}
+
+// writeMinisignSignature signs file with a new key the way 'minisign -S' does
+// and returns the public key line
+func writeMinisignSignature(t *testing.T, file string) string {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyID := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	hash := blake2b.Sum512(content)
+	sig := ed25519.Sign(private, hash[:])
+	comment := "file:" + filepath.Base(file)
+	global := ed25519.Sign(private, append(append([]byte{}, sig...), comment...))
+	minisig := "untrusted comment: signature from minisign secret key\n" +
+		base64.StdEncoding.EncodeToString(append(append([]byte("ED"), keyID...), sig...)) + "\n" +
+		"trusted comment: " + comment + "\n" +
+		base64.StdEncoding.EncodeToString(global) + "\n"
+	if err := os.WriteFile(file+".minisig", []byte(minisig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), keyID...), public...))
+}
+
+func TestEmbedder_ChecksumFileMinisign(t *testing.T) {
+	checksumFile := filepath.Join(t.TempDir(), "checksums.txt")
+	if err := os.WriteFile(checksumFile, []byte("abc123  tool_1.0.0_linux_amd64.tar.gz\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	key := writeMinisignSignature(t, checksumFile)
+	newEmbedder := func(key string) *Embedder {
+		s := spec.NewInstallSpec("owner/tool").
+			WithAsset(spec.NewAsset("${NAME}_${VERSION}_${OS}_${ARCH}.tar.gz")).
+			WithChecksums(spec.NewChecksums("checksums.txt").WithSignature(spec.NewSignature(spec.Minisign, key)))
+		return &Embedder{Mode: EmbedModeChecksumFile, Version: "v1.0.0", Spec: s, ChecksumFile: checksumFile}
+	}
+
+	if _, err := newEmbedder(key).parseChecksumFile(); err != nil {
+		t.Fatalf("parseChecksumFile() error = %v", err)
+	}
+
+	otherFile := filepath.Join(t.TempDir(), "other.txt")
+	if err := os.WriteFile(otherFile, []byte("other"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	otherKey := writeMinisignSignature(t, otherFile)
+	if _, err := newEmbedder(otherKey).parseChecksumFile(); !errors.Is(err, ErrSignatureVerification) {
+		t.Errorf("parseChecksumFile() with another key error = %v, want ErrSignatureVerification", err)
+	}
+
+	if err := os.WriteFile(checksumFile, []byte("evil  tool_1.0.0_linux_amd64.tar.gz\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newEmbedder(key).parseChecksumFile(); !errors.Is(err, ErrSignatureVerification) {
+		t.Errorf("parseChecksumFile() of a modified file error = %v, want ErrSignatureVerification", err)
+	}
+}
+
+func TestEmbedder_ChecksumFileGPGKeyring(t *testing.T) {
+	entity, err := openpgp.NewEntity("release", "", "release@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keyring bytes.Buffer
+	w, err := armor.Encode(&keyring, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := entity.Serialize(w); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(keyring.Bytes())
+	}))
+	defer server.Close()
+
+	checksumFile := filepath.Join(t.TempDir(), "checksums.txt")
+	content := "abc123  tool_1.0.0_linux_amd64.tar.gz\n"
+	if err := os.WriteFile(checksumFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var sig bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&sig, entity, strings.NewReader(content), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(filepath.Dir(checksumFile), "checksums.txt.asc"), sig.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${VERSION}_${OS}_${ARCH}.tar.gz")).
+		WithChecksums(spec.NewChecksums("checksums.txt").WithSignature(&spec.Signature{
+			Template: spec.StringPtr("${CHECKSUM_FILENAME}.asc"),
+			Keyring:  spec.StringPtr(server.URL + "/release.asc"),
+		}))
+	embedder := &Embedder{Mode: EmbedModeChecksumFile, Version: "v1.0.0", Spec: s, ChecksumFile: checksumFile}
+	if _, err := embedder.parseChecksumFile(); err != nil {
+		t.Fatalf("parseChecksumFile() error = %v", err)
+	}
+
+	s.Checksums.Signature.Template = spec.StringPtr("${CHECKSUM_FILENAME}.sig")
+	if _, err := embedder.parseChecksumFile(); !errors.Is(err, ErrSignatureVerification) {
+		t.Errorf("parseChecksumFile() without signature error = %v, want ErrSignatureVerification", err)
+	}
+}
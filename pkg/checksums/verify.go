@@ -2,12 +2,15 @@ package checksums
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 
 	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/asset"
 	"github.com/binary-install/binstaller/pkg/httpclient"
 	"github.com/binary-install/binstaller/pkg/spec"
 )
@@ -16,6 +19,21 @@ import (
 type Verifier struct {
 	Spec    *spec.InstallSpec
 	Version string
+	// OS and Arch select per-platform checksum files via asset rules' checksum_template.
+	// When empty, checksums.template is used.
+	OS   string
+	Arch string
+	// OSVersion is matched against asset rules' when.os_version
+	OSVersion string
+	// AllowWeakAlgorithm accepts md5 and sha1 checksums as verification. By default
+	// an asset matching a weak checksum is treated as unverified.
+	AllowWeakAlgorithm bool
+	// DownloadBaseURL is the web URL of the release service (defaults to asset.BaseURL of the spec)
+	DownloadBaseURL string
+
+	// checksumFiles caches parsed checksum files by URL
+	mu            sync.Mutex
+	checksumFiles map[string]map[string]string
 }
 
 // NewVerifier creates a new checksum verifier
@@ -59,8 +77,8 @@ func (v *Verifier) getChecksumWithAssetFilename(ctx context.Context, filename, a
 	}
 
 	// If not found in embedded checksums, try to download checksum file
-	if spec.StringValue(v.Spec.Checksums.Template) != "" {
-		checksumMap, err := v.downloadChecksumFileWithAssetFilename(ctx, assetFilename)
+	if template := v.checksumTemplate(); template != "" {
+		checksumMap, err := v.downloadChecksumFileWithAssetFilename(ctx, template, assetFilename)
 		if err != nil {
 			return "", fmt.Errorf("failed to download checksum file: %w", err)
 		}
@@ -79,18 +97,35 @@ func (v *Verifier) getChecksumWithAssetFilename(ctx context.Context, filename, a
 
 // VerifyFile verifies a file against its expected checksum
 func (v *Verifier) VerifyFile(ctx context.Context, filepath, filename string) error {
+	_, err := v.Verify(ctx, filepath, filename)
+	return err
+}
+
+// Verify verifies a file against its expected checksum and reports whether it
+// was verified. Files without a checksum, or with only a weak one, are accepted
+// unverified unless checksums are required.
+func (v *Verifier) Verify(ctx context.Context, filepath, filename string) (bool, error) {
 	expectedHash, err := v.getChecksumWithAssetFilename(ctx, filename, filename)
+	if errors.Is(err, ErrSignatureVerification) {
+		return false, err
+	}
 	if err != nil {
+		if v.Spec.GetChecksums().GetRequired() {
+			return false, fmt.Errorf("checksums are required but none could be found for %s: %w", filename, err)
+		}
 		// Skip verification with warning when checksums are not found
 		// This matches the behavior of generated shell scripts
 		log.Warnf("No checksum found for %s, skipping verification: %v", filename, err)
-		return nil
+		return false, nil
 	}
 
 	// If no checksum was found (nil error but empty hash), skip verification
 	if expectedHash == "" {
+		if v.Spec.GetChecksums().GetRequired() {
+			return false, fmt.Errorf("checksums are required but none could be found for %s", filename)
+		}
 		log.Warnf("No checksum found for %s, skipping verification", filename)
-		return nil
+		return false, nil
 	}
 
 	algorithm := "sha256" // default
@@ -100,32 +135,62 @@ func (v *Verifier) VerifyFile(ctx context.Context, filepath, filename string) er
 
 	actualHash, err := ComputeHash(filepath, algorithm)
 	if err != nil {
-		return fmt.Errorf("failed to compute hash: %w", err)
+		return false, fmt.Errorf("failed to compute hash: %w", err)
 	}
 
 	if actualHash != expectedHash {
-		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", filename, expectedHash, actualHash)
+		return false, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", filename, expectedHash, actualHash)
+	}
+
+	if weak := spec.Algorithm(algorithm); weak.IsWeak() && !v.AllowWeakAlgorithm {
+		if v.Spec.GetChecksums().GetRequired() {
+			return false, fmt.Errorf("checksums are required but %s checksums are too weak to verify %s (allow them explicitly to accept)", algorithm, filename)
+		}
+		log.Warnf("%s checksums are too weak to verify %s; treating it as unverified", algorithm, filename)
+		return false, nil
 	}
 
 	log.Infof("Checksum verified for %s", filename)
-	return nil
+	return true, nil
+}
+
+// ChecksumFilename returns the name of the release file listing the checksum of
+// assetFilename on the verifier's platform, or "" when the spec has no checksum file
+func (v *Verifier) ChecksumFilename(assetFilename string) string {
+	embedder := &Embedder{Spec: v.Spec, Version: v.Version}
+	return embedder.createChecksumFilenameFromTemplate(v.checksumTemplate(), assetFilename)
+}
+
+// checksumTemplate returns the checksum file template for the verifier's platform
+func (v *Verifier) checksumTemplate() string {
+	if v.OS == "" && v.Arch == "" {
+		return v.Spec.GetChecksums().GetTemplate()
+	}
+	generator := asset.NewFilenameGenerator(v.Spec, v.Version)
+	generator.OSVersion = v.OSVersion
+	return generator.ChecksumTemplate(v.OS, v.Arch)
 }
 
 // downloadChecksumFileWithAssetFilename downloads and parses the checksum file with asset filename support
-func (v *Verifier) downloadChecksumFileWithAssetFilename(ctx context.Context, assetFilename string) (map[string]string, error) {
+func (v *Verifier) downloadChecksumFileWithAssetFilename(ctx context.Context, template, assetFilename string) (map[string]string, error) {
 	// Create embedder to reuse checksum template interpolation
 	embedder := &Embedder{
 		Spec:    v.Spec,
 		Version: v.Version,
 	}
 
-	checksumFilename := embedder.createChecksumFilenameWithAsset(assetFilename)
+	checksumFilename := embedder.createChecksumFilenameFromTemplate(template, assetFilename)
 	if checksumFilename == "" {
 		return nil, fmt.Errorf("unable to generate checksum filename")
 	}
 
-	checksumURL := fmt.Sprintf("https://github.com/%s/releases/download/%s/%s",
-		spec.StringValue(v.Spec.Repo), v.Version, checksumFilename)
+	checksumURL := asset.DownloadURL(v.Spec, v.DownloadBaseURL, v.Version, checksumFilename)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if cached, ok := v.checksumFiles[checksumURL]; ok {
+		return cached, nil
+	}
 
 	log.Infof("Downloading checksums from %s", checksumURL)
 
@@ -153,7 +218,17 @@ func (v *Verifier) downloadChecksumFileWithAssetFilename(ctx context.Context, as
 		return nil, fmt.Errorf("failed to read checksum file: %w", err)
 	}
 
-	return parseChecksumContent(string(content)), nil
+	// Verify the checksum file signature before trusting its contents
+	if err := verifyChecksumFileSignature(ctx, v.Spec, v.Version, checksumFilename, content, fetchReleaseSibling(ctx, checksumURL)); err != nil {
+		return nil, err
+	}
+
+	checksumMap := parseChecksumContent(string(content))
+	if v.checksumFiles == nil {
+		v.checksumFiles = make(map[string]map[string]string)
+	}
+	v.checksumFiles[checksumURL] = checksumMap
+	return checksumMap, nil
 }
 
 // parseChecksumContent parses checksum file content into a map
@@ -179,6 +254,9 @@ func parseChecksumContent(content string) map[string]string {
 
 		// If the filename starts with *, remove it
 		filename = strings.TrimPrefix(filename, "*")
+		if filename == "" {
+			continue
+		}
 
 		checksums[filename] = hash
 	}
@@ -208,3 +208,84 @@ def456 *file2.zip
 		})
 	}
 }
+
+func TestVerifyFileRequired(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "test.txt")
+	if err := os.WriteFile(testFile, []byte("test content"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	// The embedded checksums do not cover v2.0.0
+	installSpec := spec.NewInstallSpec("owner/repo").
+		WithChecksums(spec.NewChecksums("").WithEmbeddedChecksum("v1.0.0", "test.txt", "abc"))
+	verifier := NewVerifier(installSpec, "v2.0.0")
+	if err := verifier.VerifyFile(context.Background(), testFile, "test.txt"); err != nil {
+		t.Errorf("VerifyFile() without required checksums error = %v, want skipped verification", err)
+	}
+	verified, err := verifier.Verify(context.Background(), testFile, "test.txt")
+	if err != nil || verified {
+		t.Errorf("Verify() without required checksums = %v, %v, want skipped verification", verified, err)
+	}
+
+	installSpec.Checksums.WithRequired(true)
+	err = verifier.VerifyFile(context.Background(), testFile, "test.txt")
+	if err == nil || !strings.Contains(err.Error(), "checksums are required") {
+		t.Errorf("VerifyFile() with required checksums error = %v, want refusal", err)
+	}
+}
+
+func TestVerifyFileWeakAlgorithm(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "test.txt")
+	if err := os.WriteFile(testFile, []byte("test content"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	hash, err := ComputeHash(testFile, "sha1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	installSpec := spec.NewInstallSpec("owner/repo").
+		WithChecksums(spec.NewChecksums("").WithAlgorithm(spec.Sha1).WithEmbeddedChecksum("v1.0.0", "test.txt", hash))
+	verifier := NewVerifier(installSpec, "v1.0.0")
+	if err := verifier.VerifyFile(context.Background(), testFile, "test.txt"); err != nil {
+		t.Errorf("VerifyFile() error = %v, want the asset treated as unverified", err)
+	}
+
+	installSpec.Checksums.WithRequired(true)
+	err = verifier.VerifyFile(context.Background(), testFile, "test.txt")
+	if err == nil || !strings.Contains(err.Error(), "too weak") {
+		t.Errorf("VerifyFile() with required checksums error = %v, want refusal of sha1", err)
+	}
+
+	verifier.AllowWeakAlgorithm = true
+	if err := verifier.VerifyFile(context.Background(), testFile, "test.txt"); err != nil {
+		t.Errorf("VerifyFile() with weak algorithms allowed error = %v", err)
+	}
+
+	// A mismatch is still an error even though the algorithm is weak
+	verifier.AllowWeakAlgorithm = false
+	installSpec.Checksums.EmbeddedChecksums["v1.0.0"][0].Hash = spec.StringPtr(strings.Repeat("0", 40))
+	err = verifier.VerifyFile(context.Background(), testFile, "test.txt")
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("VerifyFile() error = %v, want checksum mismatch", err)
+	}
+
+	// md5 assets install as unverified, or verified with weak algorithms allowed
+	md5Hash, err := ComputeHash(testFile, "md5")
+	if err != nil {
+		t.Fatalf("ComputeHash(md5) error = %v", err)
+	}
+	if want := "9473fdd0d880a43c21b7778d34872157"; md5Hash != want {
+		t.Errorf("ComputeHash(md5) = %s, want %s", md5Hash, want)
+	}
+	installSpec = spec.NewInstallSpec("owner/repo").
+		WithChecksums(spec.NewChecksums("").WithAlgorithm(spec.Md5).WithEmbeddedChecksum("v1.0.0", "test.txt", md5Hash))
+	verifier = NewVerifier(installSpec, "v1.0.0")
+	if err := verifier.VerifyFile(context.Background(), testFile, "test.txt"); err != nil {
+		t.Errorf("VerifyFile() with md5 error = %v, want the asset treated as unverified", err)
+	}
+	verifier.AllowWeakAlgorithm = true
+	if err := verifier.VerifyFile(context.Background(), testFile, "test.txt"); err != nil {
+		t.Errorf("VerifyFile() with md5 allowed error = %v", err)
+	}
+}
@@ -0,0 +1,213 @@
+// Package chocolatey renders Chocolatey packages (a .nuspec and chocolateyInstall.ps1)
+// from InstallSpecs.
+package chocolatey
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/binary-install/binstaller/pkg/export"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// GeneratedHeader marks files rendered by this package
+const GeneratedHeader = "Code generated by binstaller. DO NOT EDIT."
+
+// InstallScriptPath is the path of the install script inside the package
+const InstallScriptPath = "tools/chocolateyInstall.ps1"
+
+// Package is a Chocolatey package for one InstallSpec release
+type Package struct {
+	ID        string
+	Version   string
+	Repo      string
+	Algorithm string
+	// URL and URL64 are the 32-bit and 64-bit Windows assets; either may be empty
+	URL, Checksum     string
+	URL64, Checksum64 string
+	// Archive reports whether the assets are archives rather than raw executables
+	Archive bool
+	// Tarball reports whether the assets are compressed tarballs that extract in two steps
+	Tarball  bool
+	Binaries []Binary
+	// Header is the script_header comment block of the install script
+	Header []string
+}
+
+// Binary is an executable shimmed by Chocolatey
+type Binary struct {
+	Name string // executable name including .exe
+	Path string // path relative to the tools directory
+}
+
+// New builds the Chocolatey package of installSpec at tag from its windows/386
+// and windows/amd64 assets
+func New(installSpec *spec.InstallSpec, tag string, hash export.HashFunc) (*Package, error) {
+	installSpec.SetDefaults()
+	if installSpec.GetRepo() == "" {
+		return nil, fmt.Errorf("repo not specified in spec")
+	}
+
+	version := strings.TrimPrefix(tag, "v")
+	pkg := &Package{
+		ID:      strings.ToLower(installSpec.GetName()),
+		Version: version,
+		Repo:    installSpec.GetRepo(),
+		Header:  installSpec.GetScriptHeader().Lines(),
+	}
+	for _, arch := range []string{"386", "amd64"} {
+		if !export.Supported(installSpec, "windows", arch) {
+			continue
+		}
+		a, err := export.Resolve(installSpec, tag, "windows", arch, hash)
+		if err != nil {
+			return nil, err
+		}
+		pkg.Algorithm = a.Algorithm
+		if arch == "amd64" {
+			pkg.URL64, pkg.Checksum64 = a.URL, a.Hash
+		} else {
+			pkg.URL, pkg.Checksum = a.URL, a.Hash
+		}
+
+		// Both architectures share one install script, so the 64-bit layout wins
+		pkg.Archive = a.Archive
+		pkg.Tarball = strings.Contains(a.Filename, ".tar.")
+		pkg.Binaries = packageBinaries(a)
+	}
+	if pkg.URL == "" && pkg.URL64 == "" {
+		return nil, fmt.Errorf("%s supports neither windows/386 nor windows/amd64", pkg.Repo)
+	}
+	return pkg, nil
+}
+
+// packageBinaries returns the binaries of a Windows asset relative to the tools
+// directory. A raw executable is saved under its binary name.
+func packageBinaries(a *export.Asset) []Binary {
+	var binaries []Binary
+	for _, b := range a.Binaries {
+		binaryPath := b.Name
+		if a.Archive {
+			binaryPath = b.Path
+		}
+		binaries = append(binaries, Binary{Name: b.Name, Path: binaryPath})
+	}
+	return binaries
+}
+
+// Files renders the package files keyed by their path inside the package directory
+func (p *Package) Files() (map[string][]byte, error) {
+	funcs := template.FuncMap{
+		"xml": func(s string) (string, error) {
+			var buf bytes.Buffer
+			err := xml.EscapeText(&buf, []byte(s))
+			return buf.String(), err
+		},
+		"ps":      powershellString,
+		"winPath": func(s string) string { return strings.ReplaceAll(s, "/", `\`) },
+		"base":    path.Base,
+	}
+	files := make(map[string][]byte)
+	for name, text := range map[string]string{
+		p.ID + ".nuspec":  nuspecTemplate,
+		InstallScriptPath: installScriptTemplate,
+	} {
+		tmpl, err := template.New(name).Funcs(funcs).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s template: %w", name, err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, p); err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", name, err)
+		}
+		files[name] = buf.Bytes()
+	}
+	return files, nil
+}
+
+// Owner returns the GitHub owner of the package's repository
+func (p *Package) Owner() string {
+	owner, _, _ := strings.Cut(p.Repo, "/")
+	return owner
+}
+
+// powershellString quotes s as a PowerShell single-quoted string literal
+func powershellString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+const nuspecTemplate = `<?xml version="1.0" encoding="utf-8"?>
+<!-- ` + GeneratedHeader + ` -->
+<package xmlns="http://schemas.microsoft.com/packaging/2015/06/nuspec.xsd">
+  <metadata>
+    <id>{{ xml .ID }}</id>
+    <version>{{ xml .Version }}</version>
+    <title>{{ xml .ID }}</title>
+    <authors>{{ xml .Owner }}</authors>
+    <projectUrl>https://github.com/{{ xml .Repo }}</projectUrl>
+    <description>Release binaries of {{ xml .Repo }}</description>
+    <tags>{{ xml .ID }}</tags>
+  </metadata>
+  <files>
+    <file src="tools\**" target="tools" />
+  </files>
+</package>
+`
+
+const installScriptTemplate = `
+{{- range .Header }}#{{ if . }} {{ . }}{{ end }}
+{{ end }}
+{{- if .Header }}#
+{{ end -}}
+# ` + GeneratedHeader + `
+$ErrorActionPreference = 'Stop'
+$toolsDir = "$(Split-Path -Parent $MyInvocation.MyCommand.Definition)"
+
+$packageArgs = @{
+  packageName    = $env:ChocolateyPackageName
+{{- if .Archive }}
+  unzipLocation  = $toolsDir
+{{- else }}
+  fileFullPath   = Join-Path $toolsDir {{ ps (index .Binaries 0).Name }}
+{{- end }}
+{{- if .URL }}
+  url            = {{ ps .URL }}
+  checksum       = {{ ps .Checksum }}
+  checksumType   = {{ ps .Algorithm }}
+{{- end }}
+{{- if .URL64 }}
+  url64bit       = {{ ps .URL64 }}
+  checksum64     = {{ ps .Checksum64 }}
+  checksumType64 = {{ ps .Algorithm }}
+{{- end }}
+}
+{{- if .Archive }}
+Install-ChocolateyZipPackage @packageArgs
+{{- if .Tarball }}
+
+# The first extraction only decompresses the tarball
+Get-ChildItem -Path $toolsDir -Filter '*.tar' | ForEach-Object {
+  Get-ChocolateyUnzip -FileFullPath $_.FullName -Destination $toolsDir
+  Remove-Item $_.FullName
+}
+{{- end }}
+{{- range .Binaries }}
+{{- if ne (base .Path) .Name }}
+
+Move-Item -Force (Join-Path $toolsDir {{ ps (winPath .Path) }}) (Join-Path (Split-Path -Parent (Join-Path $toolsDir {{ ps (winPath .Path) }})) {{ ps .Name }})
+{{- end }}
+{{- end }}
+
+# Only shim the configured binaries
+$binaries = @({{ range $i, $b := .Binaries }}{{ if $i }}, {{ end }}{{ ps $b.Name }}{{ end }})
+Get-ChildItem -Path $toolsDir -Filter '*.exe' -Recurse |
+  Where-Object { $binaries -notcontains $_.Name } |
+  ForEach-Object { New-Item -ItemType File -Path "$($_.FullName).ignore" -Force | Out-Null }
+{{- else }}
+Get-ChocolateyWebFile @packageArgs
+{{- end }}
+`
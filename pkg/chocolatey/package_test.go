@@ -0,0 +1,132 @@
+package chocolatey
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+func fakeHash(osName, arch, filename string) (string, error) {
+	return fmt.Sprintf("hash-%s-%s", osName, arch), nil
+}
+
+func TestPackageFilesArchive(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/My-Tool").
+		WithAsset(spec.NewAsset("${NAME}_${VERSION}_${OS}_${ARCH}${EXT}").
+			WithDefaultExtension(".tar.gz").
+			WithRules(
+				spec.NewRule("windows", "").WithExt(".zip"),
+				spec.NewRule("", "amd64").WithArch("x86_64"),
+			).
+			WithBinary("mytool", "dist/my-tool")).
+		WithSupportedPlatforms("linux/amd64", "windows/386", "windows/amd64")
+
+	pkg, err := New(installSpec, "v1.2.3", fakeHash)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	files, err := pkg.Files()
+	if err != nil {
+		t.Fatalf("Files() error = %v", err)
+	}
+
+	nuspec := string(files["my-tool.nuspec"])
+	for _, want := range []string{
+		"<id>my-tool</id>",
+		"<version>1.2.3</version>",
+		"<authors>owner</authors>",
+		"<projectUrl>https://github.com/owner/My-Tool</projectUrl>",
+	} {
+		if !strings.Contains(nuspec, want) {
+			t.Errorf("nuspec missing %q:\n%s", want, nuspec)
+		}
+	}
+
+	script := string(files[InstallScriptPath])
+	for _, want := range []string{
+		"  unzipLocation  = $toolsDir",
+		"  url            = 'https://github.com/owner/My-Tool/releases/download/v1.2.3/My-Tool_1.2.3_windows_386.zip'",
+		"  checksum       = 'hash-windows-386'",
+		"  url64bit       = 'https://github.com/owner/My-Tool/releases/download/v1.2.3/My-Tool_1.2.3_windows_x86_64.zip'",
+		"  checksum64     = 'hash-windows-amd64'",
+		"  checksumType64 = 'sha256'",
+		"Install-ChocolateyZipPackage @packageArgs",
+		`Move-Item -Force (Join-Path $toolsDir 'dist\my-tool.exe')`,
+		"$binaries = @('mytool.exe')",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("install script missing %q:\n%s", want, script)
+		}
+	}
+	if strings.Contains(script, "Get-ChocolateyUnzip") {
+		t.Error("install script extracts a tarball for zip assets")
+	}
+}
+
+func TestPackageFilesRawBinary(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}-${OS}-${ARCH}.exe")).
+		WithChecksums(spec.NewChecksums("sums.txt").WithAlgorithm(spec.Sha512)).
+		WithSupportedPlatforms("windows/amd64")
+
+	pkg, err := New(installSpec, "v2.0.0", fakeHash)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	files, err := pkg.Files()
+	if err != nil {
+		t.Fatalf("Files() error = %v", err)
+	}
+	script := string(files[InstallScriptPath])
+	for _, want := range []string{
+		"  fileFullPath   = Join-Path $toolsDir 'tool.exe'",
+		"  checksumType64 = 'sha512'",
+		"Get-ChocolateyWebFile @packageArgs",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("install script missing %q:\n%s", want, script)
+		}
+	}
+	if strings.Contains(script, "  url            =") {
+		t.Error("install script has a 32-bit URL for an amd64-only spec")
+	}
+
+	installSpec.SupportedPlatforms = nil
+	installSpec.WithSupportedPlatforms("linux/amd64")
+	if _, err := New(installSpec, "v2.0.0", fakeHash); err == nil {
+		t.Error("New() expected error without Windows platforms")
+	}
+}
+
+func TestPackageFilesScriptHeader(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}-${OS}-${ARCH}.exe")).
+		WithSupportedPlatforms("windows/amd64")
+
+	for _, tt := range []struct {
+		name   string
+		header *spec.ScriptHeader
+		want   string
+	}{
+		{"none", nil, "# " + GeneratedHeader + "\n"},
+		{"header", &spec.ScriptHeader{Copyright: spec.StringPtr("Copyright 2025 Example Corp."), License: spec.StringPtr("MIT")},
+			"# Copyright 2025 Example Corp.\n# SPDX-License-Identifier: MIT\n#\n# " + GeneratedHeader + "\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			installSpec.ScriptHeader = tt.header
+			pkg, err := New(installSpec, "v1.0.0", fakeHash)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			files, err := pkg.Files()
+			if err != nil {
+				t.Fatalf("Files() error = %v", err)
+			}
+			if script := string(files[InstallScriptPath]); !strings.HasPrefix(script, tt.want) {
+				t.Errorf("install script starts with:\n%s\nwant:\n%s", script[:min(len(script), len(tt.want)+40)], tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package cosign
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Envelope is the verified content of a DSSE envelope
+type Envelope struct {
+	// PayloadType is the media type of the payload, e.g. application/vnd.in-toto+json
+	PayloadType string
+	// Payload is the signed content
+	Payload []byte
+}
+
+// bundle is a Sigstore bundle (v0.1 to v0.3) signing a DSSE envelope, the
+// format of GitHub artifact attestations
+type bundle struct {
+	VerificationMaterial struct {
+		Certificate *struct {
+			RawBytes string `json:"rawBytes"`
+		} `json:"certificate"`
+		X509CertificateChain *struct {
+			Certificates []struct {
+				RawBytes string `json:"rawBytes"`
+			} `json:"certificates"`
+		} `json:"x509CertificateChain"`
+		TlogEntries []struct {
+			LogIndex    string `json:"logIndex"`
+			KindVersion struct {
+				Kind string `json:"kind"`
+			} `json:"kindVersion"`
+		} `json:"tlogEntries"`
+	} `json:"verificationMaterial"`
+	DSSEEnvelope *struct {
+		Payload     string `json:"payload"`
+		PayloadType string `json:"payloadType"`
+		Signatures  []struct {
+			Sig string `json:"sig"`
+		} `json:"signatures"`
+	} `json:"dsseEnvelope"`
+}
+
+// dsseRekord is the body of a dsse Rekor entry
+type dsseRekord struct {
+	Kind string `json:"kind"`
+	Spec struct {
+		PayloadHash struct {
+			Algorithm string `json:"algorithm"`
+			Value     string `json:"value"`
+		} `json:"payloadHash"`
+		Signatures []struct {
+			Signature string `json:"signature"`
+			Verifier  string `json:"verifier"`
+		} `json:"signatures"`
+	} `json:"spec"`
+}
+
+// VerifyBundle verifies a Sigstore bundle signing a DSSE envelope with a
+// keyless certificate and returns the signed envelope.
+//
+// The same checks as VerifyBlob are made, with the signature computed over the
+// DSSE pre-authentication encoding and the transparency log entry looked up in
+// Rekor by the log index recorded in the bundle.
+func (v *Verifier) VerifyBundle(ctx context.Context, data []byte) (*Envelope, error) {
+	identities, err := v.requiredIdentities()
+	if err != nil {
+		return nil, err
+	}
+
+	var b bundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle: %w", err)
+	}
+	if b.DSSEEnvelope == nil {
+		return nil, errors.New("bundle has no DSSE envelope")
+	}
+	if len(b.DSSEEnvelope.Signatures) != 1 {
+		return nil, fmt.Errorf("bundle envelope has %d signatures, want 1", len(b.DSSEEnvelope.Signatures))
+	}
+	cert, err := b.certificate()
+	if err != nil {
+		return nil, err
+	}
+	payload, err := base64.StdEncoding.DecodeString(b.DSSEEnvelope.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode envelope payload: %w", err)
+	}
+	sig, err := base64.StdEncoding.DecodeString(b.DSSEEnvelope.Signatures[0].Sig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode envelope signature: %w", err)
+	}
+
+	if err := verifySignature(cert, dssePAE(b.DSSEEnvelope.PayloadType, payload), sig); err != nil {
+		return nil, err
+	}
+	if err := checkIdentities(cert, identities); err != nil {
+		return nil, err
+	}
+
+	index, err := b.logIndex()
+	if err != nil {
+		return nil, err
+	}
+	entry, err := v.fetchDSSEEntry(ctx, index, payload, sig, cert)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.verifyCertificate(cert, entry.IntegratedTime); err != nil {
+		return nil, err
+	}
+
+	return &Envelope{PayloadType: b.DSSEEnvelope.PayloadType, Payload: payload}, nil
+}
+
+// certificate returns the signing certificate of the bundle
+func (b *bundle) certificate() (*x509.Certificate, error) {
+	var raw string
+	switch m := b.VerificationMaterial; {
+	case m.Certificate != nil:
+		raw = m.Certificate.RawBytes
+	case m.X509CertificateChain != nil && len(m.X509CertificateChain.Certificates) > 0:
+		raw = m.X509CertificateChain.Certificates[0].RawBytes
+	default:
+		return nil, errors.New("bundle has no signing certificate")
+	}
+	der, err := base64.StdEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	return cert, nil
+}
+
+// logIndex returns the Rekor log index of the dsse entry recorded in the bundle
+func (b *bundle) logIndex() (int64, error) {
+	for _, tlog := range b.VerificationMaterial.TlogEntries {
+		if tlog.KindVersion.Kind != "dsse" {
+			continue
+		}
+		index, err := strconv.ParseInt(tlog.LogIndex, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid log index %q in bundle: %w", tlog.LogIndex, err)
+		}
+		return index, nil
+	}
+	return 0, errors.New("bundle has no dsse transparency log entry")
+}
+
+// dssePAE returns the DSSE pre-authentication encoding the envelope signature covers
+func dssePAE(payloadType string, payload []byte) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "DSSEv1 %d %s %d ", len(payloadType), payloadType, len(payload))
+	b.Write(payload)
+	return b.Bytes()
+}
+
+// fetchDSSEEntry returns the Rekor entry at index after checking that it records
+// sig and cert over payload and verifying its inclusion proof and signed entry
+// timestamp
+func (v *Verifier) fetchDSSEEntry(ctx context.Context, index int64, payload, sig []byte, cert *x509.Certificate) (*rekorLogEntry, error) {
+	rekorURL := strings.TrimSuffix(v.RekorURL, "/")
+	if rekorURL == "" {
+		rekorURL = DefaultRekorURL
+	}
+
+	var entries map[string]rekorLogEntry
+	if err := v.doJSON(ctx, http.MethodGet, fmt.Sprintf("%s/api/v1/log/entries?logIndex=%d", rekorURL, index), nil, &entries); err != nil {
+		return nil, fmt.Errorf("failed to fetch Rekor entry %d: %w", index, err)
+	}
+	for uuid, entry := range entries {
+		body, err := base64.StdEncoding.DecodeString(entry.Body)
+		if err != nil {
+			return nil, fmt.Errorf("rekor entry %s: invalid body: %w", uuid, err)
+		}
+		if !dsseEntryMatches(body, payload, sig, cert) {
+			return nil, fmt.Errorf("rekor entry %d does not record the bundle signature", index)
+		}
+		if err := verifyInclusion(uuid, body, entry.Verification.InclusionProof); err != nil {
+			return nil, fmt.Errorf("rekor entry %s: %w", uuid, err)
+		}
+		if err := v.verifyEntryTimestamp(&entry); err != nil {
+			return nil, fmt.Errorf("rekor entry %s: %w", uuid, err)
+		}
+		return &entry, nil
+	}
+	return nil, fmt.Errorf("no Rekor entry found at log index %d", index)
+}
+
+// dsseEntryMatches reports whether a dsse Rekor body records sig and cert over payload
+func dsseEntryMatches(body, payload, sig []byte, cert *x509.Certificate) bool {
+	var rekord dsseRekord
+	if err := json.Unmarshal(body, &rekord); err != nil || rekord.Kind != "dsse" {
+		return false
+	}
+	digest := sha256.Sum256(payload)
+	if rekord.Spec.PayloadHash.Algorithm != "sha256" || rekord.Spec.PayloadHash.Value != hex.EncodeToString(digest[:]) {
+		return false
+	}
+	for _, s := range rekord.Spec.Signatures {
+		entrySig, err := base64.StdEncoding.DecodeString(s.Signature)
+		if err != nil || !bytes.Equal(entrySig, sig) {
+			continue
+		}
+		entryCert, err := base64.StdEncoding.DecodeString(s.Verifier)
+		if err != nil {
+			continue
+		}
+		if block, _ := pem.Decode(entryCert); block != nil && bytes.Equal(block.Bytes, cert.Raw) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,223 @@
+package cosign
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testBundle is a Sigstore bundle of a DSSE envelope with a fake Fulcio CA and
+// Rekor log holding its entry
+type testBundle struct {
+	bundle  map[string]any
+	server  *httptest.Server
+	rootPEM []byte
+	rekor   *testRekorLog
+	// tamper changes the entry before the log signs it, forge after
+	tamper func(*rekorLogEntry)
+	forge  func(*rekorLogEntry)
+}
+
+func newTestBundle(t *testing.T, payload []byte) *testBundle {
+	t.Helper()
+	repoExt, _ := asn1.MarshalWithParams("https://github.com/owner/repo", "utf8")
+	cert := newTestCertificate(t, testIdentity, pkix.Extension{Id: oidSourceRepositoryURI, Value: repoExt})
+
+	payloadType := "application/vnd.in-toto+json"
+	digest := sha256.Sum256(dssePAE(payloadType, payload))
+	sig, err := ecdsa.SignASN1(rand.Reader, cert.key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := &testBundle{bundle: map[string]any{
+		"mediaType": "application/vnd.dev.sigstore.bundle.v0.3+json",
+		"verificationMaterial": map[string]any{
+			"certificate": map[string]any{"rawBytes": base64.StdEncoding.EncodeToString(cert.der)},
+			"tlogEntries": []map[string]any{{"logIndex": "2", "kindVersion": map[string]any{"kind": "dsse", "version": "0.0.1"}}},
+		},
+		"dsseEnvelope": map[string]any{
+			"payload":     base64.StdEncoding.EncodeToString(payload),
+			"payloadType": payloadType,
+			"signatures":  []map[string]any{{"sig": base64.StdEncoding.EncodeToString(sig)}},
+		},
+	}, rootPEM: cert.rootPEM, rekor: newTestRekorLog(t)}
+
+	var rekord dsseRekord
+	rekord.Kind = "dsse"
+	payloadHash := sha256.Sum256(payload)
+	rekord.Spec.PayloadHash.Algorithm = "sha256"
+	rekord.Spec.PayloadHash.Value = hex.EncodeToString(payloadHash[:])
+	rekord.Spec.Signatures = append(rekord.Spec.Signatures, struct {
+		Signature string `json:"signature"`
+		Verifier  string `json:"verifier"`
+	}{base64.StdEncoding.EncodeToString(sig), base64.StdEncoding.EncodeToString(cert.pem)})
+	body, _ := json.Marshal(rekord)
+
+	// Log with three entries; ours is the last one
+	leaf := func(b []byte) []byte { h := sha256.Sum256(append([]byte{0x00}, b...)); return h[:] }
+	l0, l1, l2 := leaf([]byte("entry0")), leaf([]byte("entry1")), leaf(body)
+	left := hashChildren(l0, l1)
+	rootHash := hashChildren(left, l2)
+	uuid := "24296fb24b8ad77a" + hex.EncodeToString(l2)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/log/entries", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("logIndex") != "2" {
+			http.NotFound(w, r)
+			return
+		}
+		entry := rekorLogEntry{
+			Body:           base64.StdEncoding.EncodeToString(body),
+			IntegratedTime: time.Now().Unix(),
+			LogIndex:       2,
+		}
+		entry.Verification.InclusionProof = &rekorInclusionProof{
+			LogIndex: 2,
+			TreeSize: 3,
+			RootHash: hex.EncodeToString(rootHash),
+			Hashes:   []string{hex.EncodeToString(left)},
+		}
+		if b.tamper != nil {
+			b.tamper(&entry)
+		}
+		b.rekor.sign(t, &entry)
+		if b.forge != nil {
+			b.forge(&entry)
+		}
+		json.NewEncoder(w).Encode(map[string]rekorLogEntry{uuid: entry})
+	})
+	b.server = httptest.NewServer(mux)
+	t.Cleanup(b.server.Close)
+	return b
+}
+
+func (b *testBundle) verifier() *Verifier {
+	return &Verifier{
+		Identities: []Identity{{
+			CertificateIdentityRegexp: `^https://github\.com/`,
+			CertificateOIDCIssuer:     testIssuer,
+			SourceRepositoryURI:       "https://github.com/Owner/Repo",
+		}},
+		RekorURL:       b.server.URL,
+		RekorPublicKey: b.rekor.pem,
+		FulcioRoots:    b.rootPEM,
+	}
+}
+
+func TestVerifyBundle(t *testing.T) {
+	payload := []byte(`{"_type":"https://in-toto.io/Statement/v1"}`)
+
+	tests := []struct {
+		name    string
+		setup   func(b *testBundle, v *Verifier)
+		wantErr string
+	}{
+		{
+			name:  "valid",
+			setup: func(b *testBundle, v *Verifier) {},
+		},
+		{
+			name: "tampered payload",
+			setup: func(b *testBundle, v *Verifier) {
+				b.bundle["dsseEnvelope"].(map[string]any)["payload"] = base64.StdEncoding.EncodeToString([]byte(`{}`))
+			},
+			wantErr: "signature does not match",
+		},
+		{
+			name: "tampered payload type",
+			setup: func(b *testBundle, v *Verifier) {
+				b.bundle["dsseEnvelope"].(map[string]any)["payloadType"] = "text/plain"
+			},
+			wantErr: "signature does not match",
+		},
+		{
+			name: "source repository mismatch",
+			setup: func(b *testBundle, v *Verifier) {
+				v.Identities[0].SourceRepositoryURI = "https://github.com/other/repo"
+			},
+			wantErr: "source repository",
+		},
+		{
+			name: "signer workflow mismatch",
+			setup: func(b *testBundle, v *Verifier) {
+				v.Identities[0].CertificateIdentityRegexp = `^https://github\.com/owner/repo/\.github/workflows/other\.yml@`
+			},
+			wantErr: "does not match",
+		},
+		{
+			name: "entry of another signature",
+			setup: func(b *testBundle, v *Verifier) {
+				b.tamper = func(e *rekorLogEntry) { e.Body = base64.StdEncoding.EncodeToString([]byte(`{"kind":"dsse"}`)) }
+			},
+			wantErr: "does not record the bundle signature",
+		},
+		{
+			name: "bad inclusion proof",
+			setup: func(b *testBundle, v *Verifier) {
+				b.tamper = func(e *rekorLogEntry) { e.Verification.InclusionProof.Hashes[0] = strings.Repeat("00", 32) }
+			},
+			wantErr: "inclusion proof",
+		},
+		{
+			name: "logged outside certificate validity",
+			setup: func(b *testBundle, v *Verifier) {
+				b.tamper = func(e *rekorLogEntry) { e.IntegratedTime = time.Now().Add(time.Hour).Unix() }
+			},
+			wantErr: "outside the certificate validity",
+		},
+		{
+			name: "forged integrated time",
+			setup: func(b *testBundle, v *Verifier) {
+				b.forge = func(e *rekorLogEntry) { e.IntegratedTime-- }
+			},
+			wantErr: "signed entry timestamp does not verify",
+		},
+		{
+			name: "untrusted root",
+			setup: func(b *testBundle, v *Verifier) {
+				v.FulcioRoots = newTestCertificate(t, testIdentity).rootPEM
+			},
+			wantErr: "trusted Fulcio CA",
+		},
+		{
+			name: "no transparency log entry",
+			setup: func(b *testBundle, v *Verifier) {
+				b.bundle["verificationMaterial"].(map[string]any)["tlogEntries"] = []any{}
+			},
+			wantErr: "no dsse transparency log entry",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBundle(t, payload)
+			v := b.verifier()
+			tt.setup(b, v)
+			data, _ := json.Marshal(b.bundle)
+			envelope, err := v.VerifyBundle(t.Context(), data)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("VerifyBundle() error = %v", err)
+				}
+				if string(envelope.Payload) != string(payload) || envelope.PayloadType != "application/vnd.in-toto+json" {
+					t.Errorf("VerifyBundle() = %+v, want the signed payload", envelope)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("VerifyBundle() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,313 @@
+// Package cosign verifies cosign keyless signatures on release files, such as
+// the checksums.txt.sig/checksums.txt.pem pair produced by GoReleaser's signs
+// pipe, using the Sigstore Fulcio certificate authority and Rekor transparency log.
+package cosign
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/binary-install/binstaller/pkg/httpclient"
+)
+
+const (
+	// DefaultRekorURL is the public Sigstore Rekor transparency log
+	DefaultRekorURL = "https://rekor.sigstore.dev"
+)
+
+var (
+	// oidIssuerV1 is the Fulcio OIDC issuer extension holding a raw string (deprecated)
+	oidIssuerV1 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+	// oidIssuerV2 is the Fulcio OIDC issuer extension holding a DER-encoded UTF8String
+	oidIssuerV2 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}
+	// oidSourceRepositoryURI is the Fulcio extension holding the repository a
+	// GitHub Actions workflow ran for, as a DER-encoded UTF8String
+	oidSourceRepositoryURI = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 12}
+)
+
+// Identity is a trusted signing identity
+type Identity struct {
+	// CertificateIdentityRegexp must match a SAN URI or email of the signing certificate
+	CertificateIdentityRegexp string
+	// CertificateOIDCIssuer must equal the OIDC issuer recorded in the signing certificate
+	CertificateOIDCIssuer string
+	// SourceRepositoryURI, when set, must equal the source repository recorded in the
+	// signing certificate (ignoring case), e.g. https://github.com/owner/repo
+	SourceRepositoryURI string
+}
+
+// Verifier verifies cosign keyless blob signatures
+type Verifier struct {
+	// CertificateIdentityRegexp must match a SAN URI or email of the signing certificate
+	CertificateIdentityRegexp string
+	// CertificateOIDCIssuer must equal the OIDC issuer recorded in the signing certificate
+	CertificateOIDCIssuer string
+	// Identities are further trusted identities; a signature is accepted if the
+	// certificate matches any of them or the identity above
+	Identities []Identity
+	// RekorURL is the transparency log to look up the signature in (default: DefaultRekorURL)
+	RekorURL string
+	// RekorPublicKey is the PEM public key entries of RekorURL are signed with
+	// (default: the key of the public Sigstore Rekor log)
+	RekorPublicKey []byte
+	// FulcioRoots are the PEM root and intermediate certificates signing
+	// certificates must chain to (default: the public Sigstore Fulcio CA)
+	FulcioRoots []byte
+	// Client is the HTTP client used for Rekor requests (default: httpclient.NewGitHubClient())
+	Client *http.Client
+}
+
+// VerifyBlob verifies that signature is a valid cosign keyless signature of blob made
+// with certificate. Both signature and certificate accept the base64 encoding written
+// by `cosign sign-blob`; certificate may also be a plain PEM block.
+//
+// The following are checked, in order:
+//   - the signature over blob verifies with the certificate's public key
+//   - the certificate identity and OIDC issuer match a trusted identity
+//   - the signature is recorded in Rekor with a valid inclusion proof and a
+//     signed entry timestamp verifying with the pinned Rekor key
+//   - the certificate was valid when Rekor recorded the signature
+//   - the certificate chains to the pinned Fulcio certificates at that time
+func (v *Verifier) VerifyBlob(ctx context.Context, blob, signature, certificate []byte) error {
+	identities, err := v.requiredIdentities()
+	if err != nil {
+		return err
+	}
+
+	cert, err := parseCertificate(certificate)
+	if err != nil {
+		return err
+	}
+	sig, err := decodeBase64(signature)
+	if err != nil {
+		return fmt.Errorf("failed to decode signature: %w", err)
+	}
+
+	if err := verifySignature(cert, blob, sig); err != nil {
+		return err
+	}
+	if err := checkIdentities(cert, identities); err != nil {
+		return err
+	}
+
+	entry, err := v.findRekorEntry(ctx, blob, sig, cert)
+	if err != nil {
+		return err
+	}
+	return v.verifyCertificate(cert, entry.IntegratedTime)
+}
+
+// verifyCertificate checks that the certificate was valid when Rekor recorded
+// the signature at integratedTime and chains to the pinned Fulcio certificates
+// then. integratedTime must come from an entry whose signed entry timestamp
+// verified.
+func (v *Verifier) verifyCertificate(cert *x509.Certificate, integratedTime int64) error {
+	logged := time.Unix(integratedTime, 0)
+	if logged.Before(cert.NotBefore) || logged.After(cert.NotAfter) {
+		return fmt.Errorf("signature was logged at %s, outside the certificate validity period (%s to %s)",
+			logged.UTC(), cert.NotBefore.UTC(), cert.NotAfter.UTC())
+	}
+
+	roots, intermediates, err := v.trustBundle()
+	if err != nil {
+		return err
+	}
+	if _, err := cert.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   logged,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}); err != nil {
+		return fmt.Errorf("certificate is not issued by the trusted Fulcio CA: %w", err)
+	}
+
+	return nil
+}
+
+// parseCertificate parses a PEM certificate, optionally base64-encoded
+func parseCertificate(data []byte) (*x509.Certificate, error) {
+	data = bytes.TrimSpace(data)
+	if !bytes.HasPrefix(data, []byte("-----BEGIN")) {
+		decoded, err := decodeBase64(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode certificate: %w", err)
+		}
+		data = decoded
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("no PEM certificate found")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	return cert, nil
+}
+
+// decodeBase64 decodes standard base64 ignoring surrounding whitespace
+func decodeBase64(data []byte) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+}
+
+// verifySignature verifies sig over the SHA-256 digest of blob with the certificate's key
+func verifySignature(cert *x509.Certificate, blob, sig []byte) error {
+	digest := sha256.Sum256(blob)
+	var ok bool
+	switch pub := cert.PublicKey.(type) {
+	case *ecdsa.PublicKey:
+		ok = ecdsa.VerifyASN1(pub, digest[:], sig)
+	case *rsa.PublicKey:
+		ok = rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig) == nil
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(pub, blob, sig)
+	default:
+		return fmt.Errorf("unsupported certificate key type %T", cert.PublicKey)
+	}
+	if !ok {
+		return errors.New("signature does not match the signed content")
+	}
+	return nil
+}
+
+// identities returns the trusted identities, starting with the top-level one when set
+func (v *Verifier) identities() []Identity {
+	var identities []Identity
+	if v.CertificateIdentityRegexp != "" || v.CertificateOIDCIssuer != "" {
+		identities = append(identities, Identity{
+			CertificateIdentityRegexp: v.CertificateIdentityRegexp,
+			CertificateOIDCIssuer:     v.CertificateOIDCIssuer,
+		})
+	}
+	return append(identities, v.Identities...)
+}
+
+// requiredIdentities returns the trusted identities, failing unless each has
+// both a certificate identity and an OIDC issuer
+func (v *Verifier) requiredIdentities() ([]Identity, error) {
+	identities := v.identities()
+	if len(identities) == 0 {
+		return nil, errors.New("certificate identity and OIDC issuer are required for cosign verification")
+	}
+	for _, id := range identities {
+		if id.CertificateIdentityRegexp == "" || id.CertificateOIDCIssuer == "" {
+			return nil, errors.New("certificate identity and OIDC issuer are required for cosign verification")
+		}
+	}
+	return identities, nil
+}
+
+// checkIdentities checks that the certificate matches at least one of the identities
+func checkIdentities(cert *x509.Certificate, identities []Identity) error {
+	var errs []error
+	for _, id := range identities {
+		err := checkIdentity(cert, id)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return fmt.Errorf("certificate matches none of the %d trusted identities: %w", len(identities), errors.Join(errs...))
+}
+
+// checkIdentity checks the certificate SAN and OIDC issuer against an identity
+func checkIdentity(cert *x509.Certificate, id Identity) error {
+	re, err := regexp.Compile(id.CertificateIdentityRegexp)
+	if err != nil {
+		return fmt.Errorf("invalid certificate identity regexp: %w", err)
+	}
+
+	var identities []string
+	for _, uri := range cert.URIs {
+		identities = append(identities, uri.String())
+	}
+	identities = append(identities, cert.EmailAddresses...)
+
+	matched := false
+	for _, identity := range identities {
+		if re.MatchString(identity) {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return fmt.Errorf("certificate identity %v does not match %q", identities, id.CertificateIdentityRegexp)
+	}
+
+	issuer, err := certificateIssuer(cert)
+	if err != nil {
+		return err
+	}
+	if issuer != id.CertificateOIDCIssuer {
+		return fmt.Errorf("certificate OIDC issuer %q does not match %q", issuer, id.CertificateOIDCIssuer)
+	}
+
+	if id.SourceRepositoryURI != "" {
+		repo, err := certificateExtension(cert, oidSourceRepositoryURI)
+		if err != nil {
+			return fmt.Errorf("failed to read source repository: %w", err)
+		}
+		if !strings.EqualFold(repo, id.SourceRepositoryURI) {
+			return fmt.Errorf("certificate source repository %q does not match %q", repo, id.SourceRepositoryURI)
+		}
+	}
+	return nil
+}
+
+// certificateExtension returns the DER-encoded UTF8String of a Fulcio extension
+func certificateExtension(cert *x509.Certificate, oid asn1.ObjectIdentifier) (string, error) {
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(oid) {
+			var value string
+			if _, err := asn1.UnmarshalWithParams(ext.Value, &value, "utf8"); err != nil {
+				return "", fmt.Errorf("failed to parse extension %s: %w", oid, err)
+			}
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("certificate has no extension %s", oid)
+}
+
+// certificateIssuer returns the OIDC issuer recorded by Fulcio in the certificate
+func certificateIssuer(cert *x509.Certificate) (string, error) {
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(oidIssuerV2) {
+			var issuer string
+			if _, err := asn1.UnmarshalWithParams(ext.Value, &issuer, "utf8"); err != nil {
+				return "", fmt.Errorf("failed to parse OIDC issuer extension: %w", err)
+			}
+			return issuer, nil
+		}
+	}
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(oidIssuerV1) {
+			return string(ext.Value), nil
+		}
+	}
+	return "", errors.New("certificate has no OIDC issuer extension")
+}
+
+func (v *Verifier) client() *http.Client {
+	if v.Client != nil {
+		return v.Client
+	}
+	return httpclient.NewGitHubClient()
+}
@@ -0,0 +1,392 @@
+package cosign
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testIdentity = "https://github.com/owner/repo/.github/workflows/release.yml@refs/tags/v1.0.0"
+	testIssuer   = "https://token.actions.githubusercontent.com"
+)
+
+// testSigstore is a fake Fulcio CA and Rekor log holding a single signed blob
+type testSigstore struct {
+	blob      []byte
+	sig       []byte
+	certPEM   []byte
+	rootPEM   []byte
+	server    *httptest.Server
+	entryBody []byte
+	rekor     *testRekorLog
+	// tamper changes the entry before the log signs it, forge after
+	tamper func(*rekorLogEntry)
+	forge  func(*rekorLogEntry)
+}
+
+// testRekorLog signs entries like a Rekor log
+type testRekorLog struct {
+	key   *ecdsa.PrivateKey
+	pem   []byte
+	logID string
+}
+
+func newTestRekorLog(t *testing.T) *testRekorLog {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logID := sha256.Sum256(der)
+	return &testRekorLog{
+		key:   key,
+		pem:   pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}),
+		logID: hex.EncodeToString(logID[:]),
+	}
+}
+
+// sign sets the log ID and signed entry timestamp of entry
+func (l *testRekorLog) sign(t *testing.T, entry *rekorLogEntry) {
+	entry.LogID = l.logID
+	payload := fmt.Sprintf(`{"body":%q,"integratedTime":%d,"logID":%q,"logIndex":%d}`, entry.Body, entry.IntegratedTime, entry.LogID, entry.LogIndex)
+	digest := sha256.Sum256([]byte(payload))
+	set, err := ecdsa.SignASN1(rand.Reader, l.key, digest[:])
+	if err != nil {
+		t.Error(err)
+	}
+	entry.Verification.SignedEntryTimestamp = base64.StdEncoding.EncodeToString(set)
+}
+
+func newTestSigstore(t *testing.T, blob []byte, identity string) *testSigstore {
+	t.Helper()
+	now := time.Now()
+	cert := newTestCertificate(t, identity)
+
+	digest := sha256.Sum256(blob)
+	sig, err := ecdsa.SignASN1(rand.Reader, cert.key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &testSigstore{
+		blob:    blob,
+		sig:     sig,
+		certPEM: cert.pem,
+		rootPEM: cert.rootPEM,
+		rekor:   newTestRekorLog(t),
+	}
+
+	var rekord hashedRekord
+	rekord.Kind = "hashedrekord"
+	rekord.Spec.Data.Hash.Algorithm = "sha256"
+	rekord.Spec.Data.Hash.Value = hex.EncodeToString(digest[:])
+	rekord.Spec.Signature.Content = base64.StdEncoding.EncodeToString(sig)
+	rekord.Spec.Signature.PublicKey.Content = base64.StdEncoding.EncodeToString(s.certPEM)
+	s.entryBody, _ = json.Marshal(rekord)
+
+	// Log with three entries; ours is the last one
+	leaf := func(b []byte) []byte { h := sha256.Sum256(append([]byte{0x00}, b...)); return h[:] }
+	l0, l1, l2 := leaf([]byte("entry0")), leaf([]byte("entry1")), leaf(s.entryBody)
+	left := hashChildren(l0, l1)
+	rootHash := hashChildren(left, l2)
+	uuid := "24296fb24b8ad77a" + hex.EncodeToString(l2)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/index/retrieve", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]string{uuid})
+	})
+	mux.HandleFunc("GET /api/v1/log/entries/{uuid}", func(w http.ResponseWriter, r *http.Request) {
+		entry := rekorLogEntry{
+			Body:           base64.StdEncoding.EncodeToString(s.entryBody),
+			IntegratedTime: now.Unix(),
+			LogIndex:       2,
+		}
+		entry.Verification.InclusionProof = &rekorInclusionProof{
+			LogIndex: 2,
+			TreeSize: 3,
+			RootHash: hex.EncodeToString(rootHash),
+			Hashes:   []string{hex.EncodeToString(left)},
+		}
+		if s.tamper != nil {
+			s.tamper(&entry)
+		}
+		s.rekor.sign(t, &entry)
+		if s.forge != nil {
+			s.forge(&entry)
+		}
+		json.NewEncoder(w).Encode(map[string]rekorLogEntry{r.PathValue("uuid"): entry})
+	})
+	s.server = httptest.NewServer(mux)
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+// testCertificate is a Fulcio-like leaf certificate with its key and root
+type testCertificate struct {
+	key     *ecdsa.PrivateKey
+	der     []byte
+	pem     []byte
+	rootPEM []byte
+}
+
+// newTestCertificate issues a code signing certificate for identity from a new
+// root, with the GitHub Actions OIDC issuer and any extra extensions
+func newTestCertificate(t *testing.T, identity string, extensions ...pkix.Extension) *testCertificate {
+	t.Helper()
+	now := time.Now()
+
+	rootKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-fulcio"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	rootDER, err := x509.CreateCertificate(rand.Reader, rootTemplate, rootTemplate, &rootKey.PublicKey, rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, _ := x509.ParseCertificate(rootDER)
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuerExt, _ := asn1.MarshalWithParams(testIssuer, "utf8")
+	identityURL, _ := url.Parse(identity)
+	leafTemplate := &x509.Certificate{
+		SerialNumber:    big.NewInt(2),
+		NotBefore:       now.Add(-time.Minute),
+		NotAfter:        now.Add(10 * time.Minute),
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		URIs:            []*url.URL{identityURL},
+		ExtraExtensions: append([]pkix.Extension{{Id: oidIssuerV2, Value: issuerExt}}, extensions...),
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, root, &leafKey.PublicKey, rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testCertificate{
+		key:     leafKey,
+		der:     leafDER,
+		pem:     pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafDER}),
+		rootPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: rootDER}),
+	}
+}
+
+func (s *testSigstore) verifier() *Verifier {
+	return &Verifier{
+		CertificateIdentityRegexp: `^https://github\.com/owner/repo/\.github/workflows/release\.yml@refs/tags/`,
+		CertificateOIDCIssuer:     testIssuer,
+		RekorURL:                  s.server.URL,
+		RekorPublicKey:            s.rekor.pem,
+		FulcioRoots:               s.rootPEM,
+	}
+}
+
+func TestVerifyBlob(t *testing.T) {
+	blob := []byte("abc123  tool_1.0.0_linux_amd64.tar.gz\n")
+	encode := func(b []byte) []byte { return []byte(base64.StdEncoding.EncodeToString(b)) }
+
+	tests := []struct {
+		name    string
+		setup   func(s *testSigstore, v *Verifier) (blob, sig, cert []byte)
+		wantErr string
+	}{
+		{
+			name: "valid base64 signature and certificate",
+			setup: func(s *testSigstore, v *Verifier) ([]byte, []byte, []byte) {
+				return s.blob, encode(s.sig), encode(s.certPEM)
+			},
+		},
+		{
+			name: "valid PEM certificate",
+			setup: func(s *testSigstore, v *Verifier) ([]byte, []byte, []byte) {
+				return s.blob, encode(s.sig), s.certPEM
+			},
+		},
+		{
+			name: "tampered blob",
+			setup: func(s *testSigstore, v *Verifier) ([]byte, []byte, []byte) {
+				return []byte("evil  tool_1.0.0_linux_amd64.tar.gz\n"), encode(s.sig), encode(s.certPEM)
+			},
+			wantErr: "signature does not match",
+		},
+		{
+			name: "identity mismatch",
+			setup: func(s *testSigstore, v *Verifier) ([]byte, []byte, []byte) {
+				v.CertificateIdentityRegexp = `^https://github\.com/other/repo/`
+				return s.blob, encode(s.sig), encode(s.certPEM)
+			},
+			wantErr: "does not match",
+		},
+		{
+			name: "issuer mismatch",
+			setup: func(s *testSigstore, v *Verifier) ([]byte, []byte, []byte) {
+				v.CertificateOIDCIssuer = "https://accounts.google.com"
+				return s.blob, encode(s.sig), encode(s.certPEM)
+			},
+			wantErr: "OIDC issuer",
+		},
+		{
+			name: "bad inclusion proof",
+			setup: func(s *testSigstore, v *Verifier) ([]byte, []byte, []byte) {
+				s.tamper = func(e *rekorLogEntry) { e.Verification.InclusionProof.Hashes[0] = strings.Repeat("00", 32) }
+				return s.blob, encode(s.sig), encode(s.certPEM)
+			},
+			wantErr: "inclusion proof",
+		},
+		{
+			name: "logged outside certificate validity",
+			setup: func(s *testSigstore, v *Verifier) ([]byte, []byte, []byte) {
+				s.tamper = func(e *rekorLogEntry) { e.IntegratedTime = time.Now().Add(time.Hour).Unix() }
+				return s.blob, encode(s.sig), encode(s.certPEM)
+			},
+			wantErr: "outside the certificate validity",
+		},
+		{
+			name: "forged integrated time",
+			setup: func(s *testSigstore, v *Verifier) ([]byte, []byte, []byte) {
+				s.forge = func(e *rekorLogEntry) { e.IntegratedTime-- }
+				return s.blob, encode(s.sig), encode(s.certPEM)
+			},
+			wantErr: "signed entry timestamp does not verify",
+		},
+		{
+			name: "missing signed entry timestamp",
+			setup: func(s *testSigstore, v *Verifier) ([]byte, []byte, []byte) {
+				s.forge = func(e *rekorLogEntry) { e.Verification.SignedEntryTimestamp = "" }
+				return s.blob, encode(s.sig), encode(s.certPEM)
+			},
+			wantErr: "missing signed entry timestamp",
+		},
+		{
+			name: "untrusted log",
+			setup: func(s *testSigstore, v *Verifier) ([]byte, []byte, []byte) {
+				v.RekorPublicKey = newTestRekorLog(t).pem
+				return s.blob, encode(s.sig), encode(s.certPEM)
+			},
+			wantErr: "not the trusted Rekor log",
+		},
+		{
+			name: "untrusted root",
+			setup: func(s *testSigstore, v *Verifier) ([]byte, []byte, []byte) {
+				v.FulcioRoots = newTestCertificate(t, testIdentity).rootPEM
+				return s.blob, encode(s.sig), encode(s.certPEM)
+			},
+			wantErr: "trusted Fulcio CA",
+		},
+		{
+			name: "rotated identity matches",
+			setup: func(s *testSigstore, v *Verifier) ([]byte, []byte, []byte) {
+				v.CertificateIdentityRegexp, v.CertificateOIDCIssuer = "", ""
+				v.Identities = []Identity{
+					{CertificateIdentityRegexp: `^https://github\.com/owner/repo/\.github/workflows/old\.yml@`, CertificateOIDCIssuer: testIssuer},
+					{CertificateIdentityRegexp: `^https://github\.com/owner/repo/\.github/workflows/release\.yml@`, CertificateOIDCIssuer: testIssuer},
+				}
+				return s.blob, encode(s.sig), encode(s.certPEM)
+			},
+		},
+		{
+			name: "no trusted identity matches",
+			setup: func(s *testSigstore, v *Verifier) ([]byte, []byte, []byte) {
+				v.CertificateIdentityRegexp = `^https://github\.com/other/repo/`
+				v.Identities = []Identity{{CertificateIdentityRegexp: `^https://github\.com/owner/repo/`, CertificateOIDCIssuer: "https://accounts.google.com"}}
+				return s.blob, encode(s.sig), encode(s.certPEM)
+			},
+			wantErr: "none of the 2 trusted identities",
+		},
+		{
+			name: "incomplete rotated identity",
+			setup: func(s *testSigstore, v *Verifier) ([]byte, []byte, []byte) {
+				v.Identities = []Identity{{CertificateIdentityRegexp: `^https://github\.com/owner/repo/`}}
+				return s.blob, encode(s.sig), encode(s.certPEM)
+			},
+			wantErr: "required",
+		},
+		{
+			name: "missing identity configuration",
+			setup: func(s *testSigstore, v *Verifier) ([]byte, []byte, []byte) {
+				v.CertificateIdentityRegexp = ""
+				return s.blob, encode(s.sig), encode(s.certPEM)
+			},
+			wantErr: "required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSigstore(t, blob, testIdentity)
+			v := s.verifier()
+			b, sig, cert := tt.setup(s, v)
+			err := v.VerifyBlob(t.Context(), b, sig, cert)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("VerifyBlob() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("VerifyBlob() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPublicTrustRoot(t *testing.T) {
+	roots, intermediates, err := (&Verifier{}).trustBundle()
+	if err != nil {
+		t.Fatalf("trustBundle() error = %v", err)
+	}
+	block, _ := pem.Decode(publicFulcioRoots[strings.LastIndex(string(publicFulcioRoots), "-----BEGIN"):])
+	intermediate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := intermediate.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   intermediate.NotBefore.Add(time.Hour),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}); err != nil {
+		t.Errorf("Fulcio intermediate does not chain to the pinned root: %v", err)
+	}
+
+	_, logID, err := (&Verifier{}).rekorKey()
+	if err != nil {
+		t.Fatalf("rekorKey() error = %v", err)
+	}
+	// The log ID of rekor.sigstore.dev
+	if want := "c0d23d6ad406973f9559f3ba2d1ca01f84147d8ffc5b8445c224f98b9591801d"; logID != want {
+		t.Errorf("rekorKey() log ID = %s, want %s", logID, want)
+	}
+}
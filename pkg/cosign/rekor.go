@@ -0,0 +1,208 @@
+package cosign
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// rekorLogEntry is a Rekor log entry as returned by GET /api/v1/log/entries/{uuid}
+type rekorLogEntry struct {
+	Body           string `json:"body"`
+	IntegratedTime int64  `json:"integratedTime"`
+	LogID          string `json:"logID"`
+	LogIndex       int64  `json:"logIndex"`
+	Verification   struct {
+		InclusionProof       *rekorInclusionProof `json:"inclusionProof"`
+		SignedEntryTimestamp string               `json:"signedEntryTimestamp"`
+	} `json:"verification"`
+}
+
+// rekorInclusionProof is the Merkle inclusion proof of a Rekor log entry
+type rekorInclusionProof struct {
+	LogIndex int64    `json:"logIndex"`
+	RootHash string   `json:"rootHash"`
+	TreeSize int64    `json:"treeSize"`
+	Hashes   []string `json:"hashes"`
+}
+
+// hashedRekord is the body of a hashedrekord Rekor entry
+type hashedRekord struct {
+	Kind string `json:"kind"`
+	Spec struct {
+		Data struct {
+			Hash struct {
+				Algorithm string `json:"algorithm"`
+				Value     string `json:"value"`
+			} `json:"hash"`
+		} `json:"data"`
+		Signature struct {
+			Content   string `json:"content"`
+			PublicKey struct {
+				Content string `json:"content"`
+			} `json:"publicKey"`
+		} `json:"signature"`
+	} `json:"spec"`
+}
+
+// findRekorEntry returns the Rekor entry recording sig and cert for blob,
+// after verifying its inclusion proof and signed entry timestamp
+func (v *Verifier) findRekorEntry(ctx context.Context, blob, sig []byte, cert *x509.Certificate) (*rekorLogEntry, error) {
+	rekorURL := strings.TrimSuffix(v.RekorURL, "/")
+	if rekorURL == "" {
+		rekorURL = DefaultRekorURL
+	}
+
+	digest := sha256.Sum256(blob)
+	digestHex := hex.EncodeToString(digest[:])
+
+	var uuids []string
+	query, _ := json.Marshal(map[string]string{"hash": "sha256:" + digestHex})
+	if err := v.doJSON(ctx, http.MethodPost, rekorURL+"/api/v1/index/retrieve", query, &uuids); err != nil {
+		return nil, fmt.Errorf("failed to search Rekor: %w", err)
+	}
+
+	for _, uuid := range uuids {
+		var entries map[string]rekorLogEntry
+		if err := v.doJSON(ctx, http.MethodGet, rekorURL+"/api/v1/log/entries/"+uuid, nil, &entries); err != nil {
+			return nil, fmt.Errorf("failed to fetch Rekor entry %s: %w", uuid, err)
+		}
+		for entryUUID, entry := range entries {
+			body, err := base64.StdEncoding.DecodeString(entry.Body)
+			if err != nil {
+				continue
+			}
+			if !entryMatches(body, digestHex, sig, cert) {
+				continue
+			}
+			if err := verifyInclusion(entryUUID, body, entry.Verification.InclusionProof); err != nil {
+				return nil, fmt.Errorf("rekor entry %s: %w", entryUUID, err)
+			}
+			if err := v.verifyEntryTimestamp(&entry); err != nil {
+				return nil, fmt.Errorf("rekor entry %s: %w", entryUUID, err)
+			}
+			return &entry, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no Rekor entry found for signature over sha256:%s", digestHex)
+}
+
+// entryMatches reports whether a hashedrekord body records sig and cert over digestHex
+func entryMatches(body []byte, digestHex string, sig []byte, cert *x509.Certificate) bool {
+	var rekord hashedRekord
+	if err := json.Unmarshal(body, &rekord); err != nil || rekord.Kind != "hashedrekord" {
+		return false
+	}
+	if rekord.Spec.Data.Hash.Algorithm != "sha256" || rekord.Spec.Data.Hash.Value != digestHex {
+		return false
+	}
+	entrySig, err := base64.StdEncoding.DecodeString(rekord.Spec.Signature.Content)
+	if err != nil || !bytes.Equal(entrySig, sig) {
+		return false
+	}
+	entryCert, err := base64.StdEncoding.DecodeString(rekord.Spec.Signature.PublicKey.Content)
+	if err != nil {
+		return false
+	}
+	block, _ := pem.Decode(entryCert)
+	return block != nil && bytes.Equal(block.Bytes, cert.Raw)
+}
+
+// verifyInclusion verifies the RFC 6962 Merkle inclusion proof of an entry body
+func verifyInclusion(uuid string, body []byte, proof *rekorInclusionProof) error {
+	if proof == nil {
+		return errors.New("missing inclusion proof")
+	}
+
+	leaf := sha256.Sum256(append([]byte{0x00}, body...))
+	// Entry UUIDs are the leaf hash, optionally prefixed with a 16 character tree ID
+	if !strings.HasSuffix(uuid, hex.EncodeToString(leaf[:])) {
+		return errors.New("entry UUID does not match its body")
+	}
+
+	root, err := hex.DecodeString(proof.RootHash)
+	if err != nil {
+		return fmt.Errorf("invalid root hash: %w", err)
+	}
+	path := make([][]byte, 0, len(proof.Hashes))
+	for _, h := range proof.Hashes {
+		b, err := hex.DecodeString(h)
+		if err != nil {
+			return fmt.Errorf("invalid proof hash: %w", err)
+		}
+		path = append(path, b)
+	}
+
+	if proof.LogIndex < 0 || proof.LogIndex >= proof.TreeSize {
+		return fmt.Errorf("log index %d out of range for tree size %d", proof.LogIndex, proof.TreeSize)
+	}
+	fn, sn := proof.LogIndex, proof.TreeSize-1
+	r := leaf[:]
+	for _, p := range path {
+		if sn == 0 {
+			return errors.New("inclusion proof is too long")
+		}
+		if fn&1 == 1 || fn == sn {
+			r = hashChildren(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = hashChildren(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 || !bytes.Equal(r, root) {
+		return errors.New("inclusion proof does not match the log root hash")
+	}
+	return nil
+}
+
+// hashChildren returns the RFC 6962 hash of an interior Merkle tree node
+func hashChildren(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0x01})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// doJSON performs an HTTP request and decodes the JSON response into out
+func (v *Verifier) doJSON(ctx context.Context, method, url string, body []byte, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := v.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s %s returned status %d: %s", method, url, resp.StatusCode, strings.TrimSpace(string(bodyBytes)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse response from %s: %w", url, err)
+	}
+	return nil
+}
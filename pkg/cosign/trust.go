@@ -0,0 +1,109 @@
+package cosign
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	_ "embed"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// publicFulcioRoots holds the root and intermediate certificates of the public
+// Sigstore Fulcio CA, from the Sigstore TUF root
+//
+//go:embed fulcio_roots.pem
+var publicFulcioRoots []byte
+
+// publicRekorKey is the public key of the public Sigstore Rekor log, from the
+// Sigstore TUF root
+//
+//go:embed rekor.pub
+var publicRekorKey []byte
+
+// trustBundle returns the pinned Fulcio root and intermediate certificates
+func (v *Verifier) trustBundle() (*x509.CertPool, *x509.CertPool, error) {
+	data := v.FulcioRoots
+	if len(data) == 0 {
+		data = publicFulcioRoots
+	}
+
+	roots := x509.NewCertPool()
+	intermediates := x509.NewCertPool()
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid Fulcio certificate: %w", err)
+		}
+		if cert.CheckSignatureFrom(cert) == nil {
+			roots.AddCert(cert)
+		} else {
+			intermediates.AddCert(cert)
+		}
+	}
+	return roots, intermediates, nil
+}
+
+// rekorKey returns the pinned Rekor public key and its log ID
+func (v *Verifier) rekorKey() (*ecdsa.PublicKey, string, error) {
+	data := v.RekorPublicKey
+	if len(data) == 0 {
+		data = publicRekorKey
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, "", errors.New("no PEM Rekor public key found")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid Rekor public key: %w", err)
+	}
+	key, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, "", fmt.Errorf("unsupported Rekor public key type %T", pub)
+	}
+	logID := sha256.Sum256(block.Bytes)
+	return key, hex.EncodeToString(logID[:]), nil
+}
+
+// verifyEntryTimestamp verifies the signed entry timestamp (SET) of a Rekor
+// entry with the pinned Rekor key. The SET covers the entry body, log index
+// and integratedTime, which is only trusted once it verifies.
+func (v *Verifier) verifyEntryTimestamp(entry *rekorLogEntry) error {
+	key, logID, err := v.rekorKey()
+	if err != nil {
+		return err
+	}
+	if entry.LogID != logID {
+		return fmt.Errorf("entry is from log %s, not the trusted Rekor log %s", entry.LogID, logID)
+	}
+	set, err := base64.StdEncoding.DecodeString(entry.Verification.SignedEntryTimestamp)
+	if err != nil || len(set) == 0 {
+		return errors.New("missing signed entry timestamp")
+	}
+
+	// Rekor signs the canonical JSON of these fields, with keys in sorted order
+	payload, err := json.Marshal(struct {
+		Body           string `json:"body"`
+		IntegratedTime int64  `json:"integratedTime"`
+		LogID          string `json:"logID"`
+		LogIndex       int64  `json:"logIndex"`
+	}{entry.Body, entry.IntegratedTime, entry.LogID, entry.LogIndex})
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256(payload)
+	if !ecdsa.VerifyASN1(key, digest[:], set) {
+		return errors.New("signed entry timestamp does not verify with the Rekor public key")
+	}
+	return nil
+}
@@ -0,0 +1,336 @@
+package datasource
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/httpclient"
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/pkg/errors"
+)
+
+// cargoDistConfigFiles are the files holding the dist configuration, in the
+// order their settings are applied
+var cargoDistConfigFiles = []string{"Cargo.toml", "dist.toml", "dist-workspace.toml"}
+
+// cargoDistAdapter implements the SourceAdapter interface for Rust projects
+// released with cargo-dist (https://opensource.axo.dev/cargo-dist/).
+type cargoDistAdapter struct {
+	repo         string
+	filePath     string
+	commit       string
+	nameOverride string
+}
+
+// NewCargoDistAdapter creates a new adapter for cargo-dist sources. filePath is
+// a Cargo.toml or dist-workspace.toml; the other files next to it are read too.
+// Without filePath the files are fetched from repo at commit.
+func NewCargoDistAdapter(repo, filePath, commit, nameOverride string) SourceAdapter {
+	return &cargoDistAdapter{
+		repo:         repo,
+		filePath:     filePath,
+		commit:       commit,
+		nameOverride: nameOverride,
+	}
+}
+
+// cargoDistProject is the part of a cargo-dist configuration that determines
+// the release artifacts
+type cargoDistProject struct {
+	Name           string   // Package name, which names the artifacts
+	Repository     string   // package.repository URL
+	Binaries       []string // [[bin]] targets, the package name when empty
+	Targets        []string // Rust target triples
+	UnixArchive    string   // unix-archive, .tar.xz by default
+	WindowsArchive string   // windows-archive, .zip by default
+	Checksum       string   // checksum algorithm, sha256 by default
+}
+
+// GenerateInstallSpec generates an InstallSpec from the cargo-dist configuration
+func (a *cargoDistAdapter) GenerateInstallSpec(ctx context.Context) (*spec.InstallSpec, error) {
+	log.Infof("generating InstallSpec using cargoDistAdapter")
+	files, err := a.loadFiles(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load cargo-dist config")
+	}
+	project, err := parseCargoDistProject(files)
+	if err != nil {
+		return nil, err
+	}
+	installSpec, err := mapCargoDistToInstallSpec(project, a.nameOverride, a.repo)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to map cargo-dist config to InstallSpec")
+	}
+	log.Info("successfully generated InstallSpec from cargo-dist source")
+	return installSpec, nil
+}
+
+// loadFiles returns the contents of the cargo-dist configuration files by name
+func (a *cargoDistAdapter) loadFiles(ctx context.Context) (map[string]string, error) {
+	files := map[string]string{}
+	if a.filePath != "" {
+		data, err := os.ReadFile(a.filePath)
+		if err != nil {
+			return nil, err
+		}
+		files[filepath.Base(a.filePath)] = string(data)
+		for _, name := range cargoDistConfigFiles {
+			if _, ok := files[name]; ok {
+				continue
+			}
+			if data, err := os.ReadFile(filepath.Join(filepath.Dir(a.filePath), name)); err == nil {
+				files[name] = string(data)
+			}
+		}
+		return files, nil
+	}
+	if a.repo == "" {
+		return nil, errors.New("--file or --repo is required for the cargo-dist source")
+	}
+	repo := normalizeRepo(a.repo)
+	for _, name := range cargoDistConfigFiles {
+		data, err := fetchRepoFile(ctx, repo, a.commit, name)
+		if err != nil {
+			log.Debugf("%s not loaded from %s: %v", name, repo, err)
+			continue
+		}
+		files[name] = string(data)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Cargo.toml or dist-workspace.toml found in %s", repo)
+	}
+	return files, nil
+}
+
+// fetchRepoFile downloads a file of a GitHub repository at commit (HEAD when empty)
+func fetchRepoFile(ctx context.Context, repo, commit, path string) ([]byte, error) {
+	if commit == "" {
+		commit = "HEAD"
+	}
+	url := fmt.Sprintf("%s/%s/%s/%s", httpclient.GitHubRawURL(""), repo, commit, path)
+	log.Infof("fetching config from URL: %s", url)
+	req, err := httpclient.NewRequestWithGitHubAuth("GET", url)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create request for %s", url)
+	}
+	resp, err := httpclient.NewGitHubClient().Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch %s", url)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: status %d", url, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// parseCargoDistProject reads the package and dist settings from the
+// configuration files. dist-workspace.toml and dist.toml keep them in [dist],
+// Cargo.toml in [workspace.metadata.dist] and [package.metadata.dist].
+func parseCargoDistProject(files map[string]string) (*cargoDistProject, error) {
+	project := &cargoDistProject{}
+	dist := map[string]any{}
+	found := false
+	for _, name := range cargoDistConfigFiles {
+		data, ok := files[name]
+		if !ok {
+			continue
+		}
+		doc, err := parseTOML(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s", name)
+		}
+		for _, path := range []string{"workspace.metadata.dist", "package.metadata.dist", "dist"} {
+			if table := tomlTable(doc, path); table != nil {
+				found = true
+				for k, v := range table {
+					dist[k] = v
+				}
+			}
+		}
+		if name != "Cargo.toml" {
+			continue
+		}
+		pkg := tomlTable(doc, "package")
+		project.Name = tomlString(pkg, "name")
+		project.Repository = tomlString(pkg, "repository")
+		if project.Repository == "" {
+			// repository.workspace = true inherits the workspace value
+			project.Repository = tomlString(tomlTable(doc, "workspace.package"), "repository")
+		}
+		bins, _ := doc["bin"].([]any)
+		for _, bin := range bins {
+			if table, ok := bin.(map[string]any); ok && tomlString(table, "name") != "" {
+				project.Binaries = append(project.Binaries, tomlString(table, "name"))
+			}
+		}
+	}
+	if !found {
+		return nil, errors.New("no cargo-dist configuration found; expected [dist] in dist-workspace.toml or [workspace.metadata.dist] in Cargo.toml")
+	}
+
+	project.Targets = tomlStrings(dist, "targets")
+	project.UnixArchive = cmp.Or(tomlString(dist, "unix-archive"), ".tar.xz")
+	project.WindowsArchive = cmp.Or(tomlString(dist, "windows-archive"), ".zip")
+	project.Checksum = "sha256"
+	switch v := dist["checksum"].(type) {
+	case string:
+		project.Checksum = v
+	case bool:
+		if !v {
+			project.Checksum = "false"
+		}
+	}
+	return project, nil
+}
+
+// rustTarget is a Rust target triple split into the parts of dist artifact
+// names, e.g. x86_64 and unknown-linux-musl, and the platform it runs on
+type rustTarget struct {
+	triple       string
+	arch, os     string
+	goos, goarch string
+}
+
+// rustArchs maps the architecture of Rust target triples to GOARCH
+var rustArchs = map[string]string{
+	"x86_64":      "amd64",
+	"aarch64":     "arm64",
+	"i686":        "386",
+	"i586":        "386",
+	"armv7":       "armv7",
+	"arm":         "armv6",
+	"powerpc64le": "ppc64le",
+	"powerpc64":   "ppc64",
+	"s390x":       "s390x",
+	"riscv64gc":   "riscv64",
+	"loongarch64": "loong64",
+}
+
+// parseRustTarget splits a target triple, reporting false for platforms
+// without a binstaller equivalent
+func parseRustTarget(triple string) (rustTarget, bool) {
+	arch, rest, ok := strings.Cut(triple, "-")
+	goarch := rustArchs[arch]
+	if !ok || goarch == "" {
+		return rustTarget{}, false
+	}
+	t := rustTarget{triple: triple, arch: arch, os: rest, goarch: goarch}
+	switch {
+	case strings.Contains(rest, "android"):
+		t.goos = "android"
+	case strings.Contains(rest, "linux"):
+		t.goos = "linux"
+	case strings.Contains(rest, "apple-darwin"):
+		t.goos = "darwin"
+	case strings.Contains(rest, "windows"):
+		t.goos = "windows"
+	default:
+		for _, goos := range []string{"freebsd", "netbsd", "openbsd", "illumos", "solaris"} {
+			if strings.HasSuffix(rest, goos) {
+				t.goos = goos
+			}
+		}
+	}
+	return t, t.goos != ""
+}
+
+// preferredTarget reports whether a is preferred over b for the same platform:
+// static musl builds run on any Linux, and msvc builds are the Windows default
+func preferredTarget(a, b rustTarget) bool {
+	return strings.Contains(a.os, "musl") && !strings.Contains(b.os, "musl") ||
+		strings.HasSuffix(a.os, "msvc") && !strings.HasSuffix(b.os, "msvc")
+}
+
+// mapCargoDistToInstallSpec converts a cargo-dist project to an InstallSpec.
+// Artifacts are named NAME-TRIPLE.EXT; unix archives hold a NAME-TRIPLE
+// directory while Windows zips hold the binaries at the top.
+func mapCargoDistToInstallSpec(project *cargoDistProject, nameOverride, repoOverride string) (*spec.InstallSpec, error) {
+	repo := repoOverride
+	if repo == "" {
+		if !strings.Contains(project.Repository, "github.com/") {
+			log.Warnf("could not determine the GitHub repository from package.repository %q. Use --repo flag.", project.Repository)
+		}
+		repo = strings.TrimSuffix(project.Repository, ".git")
+	}
+	repo = normalizeRepo(repo)
+
+	s := spec.NewInstallSpec(repo)
+	app := project.Name
+	if app == "" {
+		app = cmp.Or(nameOverride, s.GetName())
+		log.Warnf("Cargo.toml has no [package] name; assuming the artifacts are named after %q. Use --name flag for another package.", app)
+	}
+	if app == "" {
+		return nil, errors.New("could not determine the package name")
+	}
+	s.WithName(cmp.Or(nameOverride, app))
+	binaries := project.Binaries
+	if len(binaries) == 0 {
+		binaries = []string{app}
+	}
+
+	nameTemplate := "${NAME}"
+	if s.GetName() != app {
+		nameTemplate = app
+	}
+	asset := spec.NewAsset(nameTemplate + "-${ARCH}-${OS}${EXT}").WithDefaultExtension(project.UnixArchive)
+
+	// One target per platform, each spelled out by a rule since the triple
+	// parts vary by platform
+	selected := map[string]rustTarget{}
+	for _, triple := range project.Targets {
+		t, ok := parseRustTarget(triple)
+		if !ok {
+			log.Warnf("Ignoring unsupported cargo-dist target %s", triple)
+			continue
+		}
+		key := t.goos + "/" + t.goarch
+		if prev, ok := selected[key]; !ok || preferredTarget(t, prev) {
+			selected[key] = t
+		}
+	}
+	if len(selected) == 0 {
+		return nil, errors.New("cargo-dist config lists no supported targets")
+	}
+	targets := make([]rustTarget, 0, len(selected))
+	for _, t := range selected {
+		targets = append(targets, t)
+	}
+	slices.SortFunc(targets, func(a, b rustTarget) int {
+		return cmp.Or(cmp.Compare(a.goos, b.goos), cmp.Compare(a.goarch, b.goarch))
+	})
+	for _, t := range targets {
+		rule := spec.NewRule(t.goos, t.goarch).WithArch(t.arch).WithOS(t.os)
+		for _, bin := range binaries {
+			if t.goos == "windows" {
+				rule.WithBinary(bin, bin+".exe")
+			} else {
+				rule.WithBinary(bin, app+"-"+t.triple+"/"+bin)
+			}
+		}
+		if t.goos == "windows" {
+			rule.WithExt(project.WindowsArchive)
+		}
+		asset.WithRules(rule)
+		s.WithSupportedPlatforms(t.goos + "/" + t.goarch)
+	}
+	s.WithAsset(asset)
+
+	switch project.Checksum {
+	case "sha256", "sha512":
+		s.WithChecksums(spec.NewChecksums("${ASSET_FILENAME}." + project.Checksum).WithAlgorithm(spec.Algorithm(project.Checksum)))
+	case "false":
+	default:
+		log.Warnf("checksum algorithm %s is not supported; no checksums configured", project.Checksum)
+	}
+	return s, nil
+}
@@ -0,0 +1,192 @@
+package datasource_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/datasource"
+	"github.com/goccy/go-yaml"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCargoDistAdapter_DistWorkspace(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "Cargo.toml"), `
+[package]
+name = "mytool"
+version = "0.3.0"
+repository = "https://github.com/owner/mytool.git"
+description = """
+A tool, with a "quoted" word.
+"""
+
+[[bin]]
+name = "mytool"
+path = "src/main.rs"
+
+[[bin]]
+name = "mytool-helper"
+
+[dependencies]
+serde = { version = "1", features = ["derive"] }
+
+[profile.dist]
+inherits = "release"
+lto = "thin"
+`)
+	writeFile(t, filepath.Join(dir, "dist-workspace.toml"), `
+[workspace]
+members = ["cargo:."]
+
+# Config for 'dist'
+[dist]
+cargo-dist-version = "0.28.0"
+ci = "github"
+installers = ["shell", "powershell"]
+targets = [
+  "aarch64-apple-darwin",
+  "x86_64-apple-darwin",
+  "x86_64-unknown-linux-gnu",
+  "x86_64-unknown-linux-musl", # static build
+  "armv7-unknown-linux-gnueabihf",
+  "x86_64-pc-windows-msvc",
+  "wasm32-wasip1",
+]
+unix-archive = ".tar.gz"
+`)
+
+	installSpec, err := datasource.NewCargoDistAdapter("", filepath.Join(dir, "dist-workspace.toml"), "", "").GenerateInstallSpec(context.Background())
+	if err != nil {
+		t.Fatalf("GenerateInstallSpec() error = %v", err)
+	}
+	got, err := yaml.Marshal(installSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `name: mytool
+repo: owner/mytool
+asset:
+  template: ${NAME}-${ARCH}-${OS}${EXT}
+  default_extension: .tar.gz
+  rules:
+  - when:
+      os: darwin
+      arch: amd64
+    os: apple-darwin
+    arch: x86_64
+    binaries:
+    - name: mytool
+      path: mytool-x86_64-apple-darwin/mytool
+    - name: mytool-helper
+      path: mytool-x86_64-apple-darwin/mytool-helper
+  - when:
+      os: darwin
+      arch: arm64
+    os: apple-darwin
+    arch: aarch64
+    binaries:
+    - name: mytool
+      path: mytool-aarch64-apple-darwin/mytool
+    - name: mytool-helper
+      path: mytool-aarch64-apple-darwin/mytool-helper
+  - when:
+      os: linux
+      arch: amd64
+    os: unknown-linux-musl
+    arch: x86_64
+    binaries:
+    - name: mytool
+      path: mytool-x86_64-unknown-linux-musl/mytool
+    - name: mytool-helper
+      path: mytool-x86_64-unknown-linux-musl/mytool-helper
+  - when:
+      os: linux
+      arch: armv7
+    os: unknown-linux-gnueabihf
+    arch: armv7
+    binaries:
+    - name: mytool
+      path: mytool-armv7-unknown-linux-gnueabihf/mytool
+    - name: mytool-helper
+      path: mytool-armv7-unknown-linux-gnueabihf/mytool-helper
+  - when:
+      os: windows
+      arch: amd64
+    os: pc-windows-msvc
+    arch: x86_64
+    ext: .zip
+    binaries:
+    - name: mytool
+      path: mytool.exe
+    - name: mytool-helper
+      path: mytool-helper.exe
+checksums:
+  algorithm: sha256
+  template: ${ASSET_FILENAME}.sha256
+supported_platforms:
+- os: darwin
+  arch: amd64
+- os: darwin
+  arch: arm64
+- os: linux
+  arch: amd64
+- os: linux
+  arch: armv7
+- os: windows
+  arch: amd64
+`
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("InstallSpec mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCargoDistAdapter_CargoMetadata(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "Cargo.toml"), `
+[workspace]
+members = ["crates/*"]
+
+[workspace.package]
+repository = "https://github.com/owner/repo"
+
+[workspace.metadata.dist]
+targets = ["x86_64-unknown-linux-gnu"]
+checksum = "sha512"
+`)
+
+	installSpec, err := datasource.NewCargoDistAdapter("", filepath.Join(dir, "Cargo.toml"), "", "rtool").GenerateInstallSpec(context.Background())
+	if err != nil {
+		t.Fatalf("GenerateInstallSpec() error = %v", err)
+	}
+	if got := installSpec.GetRepo(); got != "owner/repo" {
+		t.Errorf("repo = %q, want owner/repo", got)
+	}
+	if got := installSpec.GetAsset().GetTemplate(); got != "${NAME}-${ARCH}-${OS}${EXT}" {
+		t.Errorf("template = %q", got)
+	}
+	if got := installSpec.GetAsset().GetDefaultExtension(); got != ".tar.xz" {
+		t.Errorf("default extension = %q, want dist's default .tar.xz", got)
+	}
+	if got := installSpec.GetChecksums().GetTemplate(); got != "${ASSET_FILENAME}.sha512" {
+		t.Errorf("checksums template = %q", got)
+	}
+	if got := installSpec.Asset.Rules[0].Binaries[0].GetPath(); got != "rtool-x86_64-unknown-linux-gnu/rtool" {
+		t.Errorf("binary path = %q", got)
+	}
+
+	writeFile(t, filepath.Join(dir, "Cargo.toml"), "[package]\nname = \"plain\"\n")
+	_, err = datasource.NewCargoDistAdapter("", filepath.Join(dir, "Cargo.toml"), "", "").GenerateInstallSpec(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "no cargo-dist configuration") {
+		t.Errorf("GenerateInstallSpec() without dist config error = %v", err)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,160 @@
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/binary-install/binstaller/pkg/httpclient"
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/buildkite/interpolate"
+)
+
+// gitHubAPIBaseURL and gitHubBaseURL are overridable for testing
+var (
+	gitHubAPIBaseURL = httpclient.GitHubAPIURL("")
+	gitHubBaseURL    = httpclient.GitHubServerURL()
+)
+
+// checksumFileTemplates are the common names of release checksum files, most specific first
+var checksumFileTemplates = []string{
+	"${NAME}_${VERSION}_checksums.txt",
+	"${NAME}-${VERSION}-checksums.txt",
+	"${NAME}_checksums.txt",
+	"checksums.txt",
+	"SHA256SUMS",
+	"SHA256SUMS.txt",
+	"sha256sums.txt",
+	"SHA512SUMS",
+	"sha512sums.txt",
+}
+
+// perAssetChecksumSuffixes are the suffixes of checksum files published next to each asset
+var perAssetChecksumSuffixes = []string{".sha256", ".sha256sum", ".sha512"}
+
+// algorithmsByHashLength maps hex digest lengths to hash algorithms
+var algorithmsByHashLength = map[int]spec.Algorithm{
+	32:  spec.Md5,
+	40:  spec.Sha1,
+	64:  spec.Sha256,
+	128: spec.Sha512,
+}
+
+// gitHubRelease is the part of the GitHub release API response used for discovery
+type gitHubRelease struct {
+	TagName string `json:"tag_name"`
+	Assets  []struct {
+		Name string `json:"name"`
+	} `json:"assets"`
+}
+
+// DiscoverChecksums probes the latest release of the spec's repository for a
+// checksum file and returns the checksums configuration to use, or nil when the
+// release publishes none. The algorithm is detected from the file contents.
+func DiscoverChecksums(ctx context.Context, installSpec *spec.InstallSpec) (*spec.Checksums, error) {
+	repo := installSpec.GetRepo()
+	release, err := fetchLatestRelease(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	var assets []string
+	for _, a := range release.Assets {
+		assets = append(assets, a.Name)
+	}
+	version := strings.TrimPrefix(release.TagName, "v")
+
+	template, filename := findChecksumFile(installSpec.GetName(), version, assets)
+	if template == "" {
+		return nil, nil
+	}
+	content, err := fetchReleaseFile(ctx, repo, release.TagName, filename)
+	if err != nil {
+		return nil, err
+	}
+	algorithm, ok := detectChecksumAlgorithm(content)
+	if !ok {
+		return nil, fmt.Errorf("could not detect the hash algorithm of %s", filename)
+	}
+	return spec.NewChecksums(template).WithAlgorithm(algorithm), nil
+}
+
+// findChecksumFile returns the checksums.template matching one of the release
+// assets and the asset it matched, or empty strings when there is none
+func findChecksumFile(name, version string, assets []string) (string, string) {
+	published := make(map[string]bool, len(assets))
+	for _, a := range assets {
+		published[a] = true
+	}
+
+	env := interpolate.NewMapEnv(map[string]string{"NAME": name, "VERSION": version})
+	for _, template := range checksumFileTemplates {
+		filename, err := interpolate.Interpolate(env, template)
+		if err == nil && published[filename] {
+			return template, filename
+		}
+	}
+
+	for _, suffix := range perAssetChecksumSuffixes {
+		for _, a := range assets {
+			if strings.HasSuffix(a, suffix) && published[strings.TrimSuffix(a, suffix)] {
+				return "${ASSET_FILENAME}" + suffix, a
+			}
+		}
+	}
+	return "", ""
+}
+
+// detectChecksumAlgorithm returns the algorithm of the hex digests in a checksum file
+func detectChecksumAlgorithm(content []byte) (spec.Algorithm, bool) {
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		hash := fields[0]
+		if strings.Trim(strings.ToLower(hash), "0123456789abcdef") != "" {
+			return "", false
+		}
+		algorithm, ok := algorithmsByHashLength[len(hash)]
+		return algorithm, ok
+	}
+	return "", false
+}
+
+// fetchLatestRelease returns the latest release of repo
+func fetchLatestRelease(ctx context.Context, repo string) (*gitHubRelease, error) {
+	body, err := httpGet(ctx, fmt.Sprintf("%s/repos/%s/releases/latest", gitHubAPIBaseURL, repo))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch latest release: %w", err)
+	}
+	var release gitHubRelease
+	if err := json.Unmarshal(body, &release); err != nil {
+		return nil, fmt.Errorf("failed to decode latest release: %w", err)
+	}
+	return &release, nil
+}
+
+// fetchReleaseFile downloads a release asset of repo
+func fetchReleaseFile(ctx context.Context, repo, tag, filename string) ([]byte, error) {
+	return httpGet(ctx, fmt.Sprintf("%s/%s/releases/download/%s/%s", gitHubBaseURL, repo, tag, filename))
+}
+
+// httpGet returns the body of a GitHub URL
+func httpGet(ctx context.Context, url string) ([]byte, error) {
+	req, err := httpclient.NewRequestWithGitHubAuth("GET", url)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpclient.NewGitHubClient().Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s returned status %d", url, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,106 @@
+package datasource
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+func TestFindChecksumFile(t *testing.T) {
+	tests := []struct {
+		name         string
+		assets       []string
+		wantTemplate string
+		wantFilename string
+	}{
+		{
+			name:         "goreleaser name",
+			assets:       []string{"tool_1.2.3_linux_amd64.tar.gz", "tool_1.2.3_checksums.txt", "checksums.txt"},
+			wantTemplate: "${NAME}_${VERSION}_checksums.txt",
+			wantFilename: "tool_1.2.3_checksums.txt",
+		},
+		{
+			name:         "SHA256SUMS",
+			assets:       []string{"tool-linux-amd64", "SHA256SUMS", "SHA256SUMS.sig"},
+			wantTemplate: "SHA256SUMS",
+			wantFilename: "SHA256SUMS",
+		},
+		{
+			name:         "per-asset checksum",
+			assets:       []string{"tool-linux-amd64.tar.gz", "tool-linux-amd64.tar.gz.sha256"},
+			wantTemplate: "${ASSET_FILENAME}.sha256",
+			wantFilename: "tool-linux-amd64.tar.gz.sha256",
+		},
+		{
+			name:   "orphan per-asset checksum",
+			assets: []string{"tool-linux-amd64.tar.gz", "other.sha256"},
+		},
+		{
+			name:   "no checksum file",
+			assets: []string{"tool-linux-amd64.tar.gz"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template, filename := findChecksumFile("tool", "1.2.3", tt.assets)
+			if template != tt.wantTemplate || filename != tt.wantFilename {
+				t.Errorf("findChecksumFile() = %q, %q, want %q, %q", template, filename, tt.wantTemplate, tt.wantFilename)
+			}
+		})
+	}
+}
+
+func TestDetectChecksumAlgorithm(t *testing.T) {
+	tests := []struct {
+		content string
+		want    spec.Algorithm
+		wantOK  bool
+	}{
+		{"# comment\n\n" + strings.Repeat("a", 64) + "  tool.tar.gz\n", spec.Sha256, true},
+		{strings.Repeat("B", 128) + " *tool.tar.gz\n", spec.Sha512, true},
+		{strings.Repeat("0", 40) + "\n", spec.Sha1, true},
+		{strings.Repeat("f", 32) + "  tool.tar.gz\n", spec.Md5, true},
+		{"not-a-hash  tool.tar.gz\n", "", false},
+		{strings.Repeat("a", 50) + "  tool.tar.gz\n", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := detectChecksumAlgorithm([]byte(tt.content))
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("detectChecksumAlgorithm(%q) = %q, %v, want %q, %v", tt.content, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestDiscoverChecksums(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/tool/releases/latest":
+			w.Write([]byte(`{"tag_name":"v1.2.3","assets":[{"name":"tool_1.2.3_linux_amd64.tar.gz"},{"name":"checksums.txt"}]}`))
+		case "/owner/tool/releases/download/v1.2.3/checksums.txt":
+			w.Write([]byte(strings.Repeat("c", 128) + "  tool_1.2.3_linux_amd64.tar.gz\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	oldAPI, oldBase := gitHubAPIBaseURL, gitHubBaseURL
+	gitHubAPIBaseURL, gitHubBaseURL = server.URL, server.URL
+	defer func() { gitHubAPIBaseURL, gitHubBaseURL = oldAPI, oldBase }()
+
+	checksums, err := DiscoverChecksums(context.Background(), spec.NewInstallSpec("owner/tool"))
+	if err != nil {
+		t.Fatalf("DiscoverChecksums() error = %v", err)
+	}
+	if checksums.GetTemplate() != "checksums.txt" || checksums.GetAlgorithm() != spec.Sha512 {
+		t.Errorf("DiscoverChecksums() = %q (%s), want checksums.txt (sha512)", checksums.GetTemplate(), checksums.GetAlgorithm())
+	}
+
+	if _, err := DiscoverChecksums(context.Background(), spec.NewInstallSpec("owner/missing")); err == nil {
+		t.Error("DiscoverChecksums() should fail when the release cannot be fetched")
+	}
+}
@@ -31,5 +31,19 @@ func (g *GitHubAdapter) GenerateInstallSpec(ctx context.Context) (*spec.InstallS
 	if err := ctrl.GenerateRegistry(ctx, param, logE, g.repo); err != nil {
 		return nil, err
 	}
-	return genSpecFromRegistryYAML(ctx, &registry)
+	installSpec, err := genSpecFromRegistryYAML(ctx, &registry)
+	if err != nil {
+		return nil, err
+	}
+	if installSpec.GetChecksums().GetTemplate() == "" {
+		checksums, err := DiscoverChecksums(ctx, installSpec)
+		switch {
+		case err != nil:
+			log.Warnf("Checksum discovery failed: %v", err)
+		case checksums != nil:
+			log.Infof("Discovered checksum file %s (%s)", checksums.GetTemplate(), checksums.GetAlgorithm())
+			installSpec.Checksums = checksums
+		}
+	}
+	return installSpec, nil
 }
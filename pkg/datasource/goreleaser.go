@@ -416,7 +416,7 @@ func loadFromGitHub(repo, configPath, specifiedCommitHash string) (*config.Proje
 	if configPath == "" {
 		return nil, errors.New("config path within repository must be specified")
 	}
-	url := fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s", repo, commitHash, configPath)
+	url := fmt.Sprintf("%s/%s/%s/%s", httpclient.GitHubRawURL(""), repo, commitHash, configPath)
 	log.Infof("fetching config from URL: %s", url)
 	req, err := httpclient.NewRequestWithGitHubAuth("GET", url)
 	if err != nil {
@@ -464,6 +464,7 @@ func loadFromFile(file string) (*config.Project, error) {
 // normalizeRepo cleans up a repository string.
 // Adapted from main.go.
 func normalizeRepo(repo string) string {
+	repo = strings.TrimPrefix(repo, httpclient.GitHubServerURL()+"/")
 	repo = strings.TrimPrefix(repo, "https://github.com/")
 	repo = strings.TrimPrefix(repo, "http://github.com/")
 	repo = strings.TrimPrefix(repo, "github.com/")
@@ -0,0 +1,213 @@
+package datasource
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/goccy/go-yaml"
+	"github.com/pkg/errors"
+)
+
+// SourceSpec is an InstallSpec generated from one source
+type SourceSpec struct {
+	Source string
+	Spec   *spec.InstallSpec
+}
+
+// Conflict is a field that several sources generated different values for.
+// Mappings are merged key by key, so a conflict is about a scalar or a list,
+// such as asset.template or asset.rules.
+type Conflict struct {
+	// Path is the dotted path of the field, e.g. asset.template
+	Path string
+	// Sources are the sources that generated a value, in merge order
+	Sources []string
+	// Values are the values of the sources
+	Values []any
+}
+
+// ConflictResolver returns the index of the value to keep for a conflict
+type ConflictResolver func(c Conflict) (int, error)
+
+// MergeSourceSpecs merges the InstallSpecs generated from several sources into
+// one. Fields only one source knows are kept as they are, mappings are merged
+// key by key, and every field the sources disagree on is passed to resolve.
+func MergeSourceSpecs(specs []SourceSpec, resolve ConflictResolver) (*spec.InstallSpec, error) {
+	if len(specs) == 0 {
+		return nil, errors.New("no specs to merge")
+	}
+	docs := make([]map[string]any, len(specs))
+	sources := make([]string, len(specs))
+	for i, s := range specs {
+		data, err := yaml.Marshal(s.Spec)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal %s spec", s.Source)
+		}
+		if err := yaml.Unmarshal(data, &docs[i]); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal %s spec", s.Source)
+		}
+		sources[i] = s.Source
+	}
+
+	merged, err := mergeMaps("", docs, sources, resolve)
+	if err != nil {
+		return nil, err
+	}
+	data, err := yaml.Marshal(merged)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal merged spec")
+	}
+	var result spec.InstallSpec
+	if err := yaml.Unmarshal(data, &result); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal merged spec")
+	}
+	return &result, nil
+}
+
+// mergeMaps merges the mappings at path of the sources; a nil mapping stands
+// for a source without the field
+func mergeMaps(path string, docs []map[string]any, sources []string, resolve ConflictResolver) (map[string]any, error) {
+	keys := map[string]bool{}
+	for _, doc := range docs {
+		for key := range doc {
+			keys[key] = true
+		}
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	merged := map[string]any{}
+	for _, key := range sorted {
+		fieldPath := key
+		if path != "" {
+			fieldPath = path + "." + key
+		}
+		var c Conflict
+		c.Path = fieldPath
+		for i, doc := range docs {
+			if value, ok := doc[key]; ok && value != nil {
+				c.Sources = append(c.Sources, sources[i])
+				c.Values = append(c.Values, value)
+			}
+		}
+		value, err := mergeValues(c, resolve)
+		if err != nil {
+			return nil, err
+		}
+		merged[key] = value
+	}
+	return merged, nil
+}
+
+// mergeValues merges the values of a field, resolving a conflict when they differ
+func mergeValues(c Conflict, resolve ConflictResolver) (any, error) {
+	if len(c.Values) == 1 {
+		return c.Values[0], nil
+	}
+	maps := make([]map[string]any, 0, len(c.Values))
+	for _, value := range c.Values {
+		if m, ok := value.(map[string]any); ok {
+			maps = append(maps, m)
+		}
+	}
+	if len(maps) == len(c.Values) {
+		return mergeMaps(c.Path, maps, c.Sources, resolve)
+	}
+
+	same := true
+	for _, value := range c.Values[1:] {
+		if !reflect.DeepEqual(value, c.Values[0]) {
+			same = false
+			break
+		}
+	}
+	if same {
+		return c.Values[0], nil
+	}
+	i, err := resolve(c)
+	if err != nil {
+		return nil, err
+	}
+	if i < 0 || i >= len(c.Values) {
+		return nil, fmt.Errorf("invalid choice for %s", c.Path)
+	}
+	log.Infof("%s: using the value from %s", c.Path, c.Sources[i])
+	return c.Values[i], nil
+}
+
+// FirstSourceResolver keeps the value of the source merged first
+func FirstSourceResolver(Conflict) (int, error) {
+	return 0, nil
+}
+
+// PrecedenceResolver keeps the value of the source preferred for the field by
+// prefer, which maps field paths to sources. A path also covers the fields
+// below it, and the longest matching path wins, so asset=aqua together with
+// asset.template=goreleaser takes the asset template from goreleaser and the
+// rest of the asset from aqua. Other conflicts are passed to fallback.
+func PrecedenceResolver(prefer map[string]string, fallback ConflictResolver) ConflictResolver {
+	return func(c Conflict) (int, error) {
+		best := ""
+		for path := range prefer {
+			if (c.Path == path || strings.HasPrefix(c.Path, path+".")) && len(path) > len(best) {
+				best = path
+			}
+		}
+		if best != "" {
+			for i, source := range c.Sources {
+				if source == prefer[best] {
+					return i, nil
+				}
+			}
+		}
+		return fallback(c)
+	}
+}
+
+// PromptResolver asks which value to keep for every conflict, showing the
+// values on w and reading the number of the choice from r. An empty answer
+// keeps the first value.
+func PromptResolver(r io.Reader, w io.Writer) ConflictResolver {
+	reader := bufio.NewReader(r)
+	return func(c Conflict) (int, error) {
+		fmt.Fprintf(w, "Sources disagree on %s:\n", c.Path)
+		for i, value := range c.Values {
+			data, err := yaml.Marshal(value)
+			if err != nil {
+				return 0, err
+			}
+			fmt.Fprintf(w, "  %d) %s:\n", i+1, c.Sources[i])
+			for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+				fmt.Fprintf(w, "       %s\n", line)
+			}
+		}
+		for {
+			fmt.Fprintf(w, "Keep which value? [1-%d] (default 1): ", len(c.Values))
+			answer, err := reader.ReadString('\n')
+			answer = strings.TrimSpace(answer)
+			if answer == "" {
+				if err != nil && err != io.EOF {
+					return 0, err
+				}
+				return 0, nil
+			}
+			if n, convErr := strconv.Atoi(answer); convErr == nil && n >= 1 && n <= len(c.Values) {
+				return n - 1, nil
+			}
+			if err != nil {
+				return 0, fmt.Errorf("invalid choice %q for %s", answer, c.Path)
+			}
+			fmt.Fprintf(w, "Enter a number between 1 and %d\n", len(c.Values))
+		}
+	}
+}
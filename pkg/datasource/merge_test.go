@@ -0,0 +1,106 @@
+package datasource
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+func mergeTestSpecs() []SourceSpec {
+	goreleaser := spec.NewInstallSpec("owner/tool").
+		WithName("tool").
+		WithSupportedPlatforms("linux/amd64", "darwin/arm64").
+		WithAsset(spec.NewAsset("${NAME}_${VERSION}_${OS}_${ARCH}${EXT}").
+			WithDefaultExtension(".tar.gz").
+			WithRules(spec.NewRule("windows", "").WithExt(".zip")))
+	aqua := spec.NewInstallSpec("owner/tool").
+		WithName("tool").
+		WithAsset(spec.NewAsset("tool_${VERSION}_${OS}_${ARCH}${EXT}").
+			WithDefaultExtension(".tar.gz").
+			WithRules(spec.NewRule("darwin", "").WithOS("macOS")))
+	rosetta2 := true
+	aqua.Asset.ArchEmulation = &spec.ArchEmulation{Rosetta2: &rosetta2}
+	return []SourceSpec{{Source: "goreleaser", Spec: goreleaser}, {Source: "aqua", Spec: aqua}}
+}
+
+func TestMergeSourceSpecs(t *testing.T) {
+	var conflicts []string
+	merged, err := MergeSourceSpecs(mergeTestSpecs(), func(c Conflict) (int, error) {
+		conflicts = append(conflicts, c.Path)
+		return FirstSourceResolver(c)
+	})
+	if err != nil {
+		t.Fatalf("MergeSourceSpecs() error = %v", err)
+	}
+	if got := strings.Join(conflicts, ","); got != "asset.rules,asset.template" {
+		t.Errorf("conflicts = %s, want asset.rules,asset.template", got)
+	}
+	if merged.Asset.GetTemplate() != "${NAME}_${VERSION}_${OS}_${ARCH}${EXT}" {
+		t.Errorf("asset.template = %s, want the goreleaser template", merged.Asset.GetTemplate())
+	}
+	if len(merged.Asset.Rules) != 1 || merged.Asset.Rules[0].When.GetOS() != "windows" {
+		t.Errorf("asset.rules = %+v, want the goreleaser rules", merged.Asset.Rules)
+	}
+	// Fields only one source generates are kept
+	if merged.Asset.ArchEmulation == nil || merged.Asset.ArchEmulation.Rosetta2 == nil || !*merged.Asset.ArchEmulation.Rosetta2 {
+		t.Error("asset.arch_emulation of aqua was dropped")
+	}
+	if len(merged.SupportedPlatforms) != 2 || merged.GetName() != "tool" {
+		t.Errorf("merged spec = %+v, want the platforms and name kept", merged)
+	}
+}
+
+func TestPrecedenceResolver(t *testing.T) {
+	resolve := PrecedenceResolver(map[string]string{
+		"asset":          "aqua",
+		"asset.template": "goreleaser",
+	}, func(c Conflict) (int, error) {
+		return 0, errors.New("unexpected fallback for " + c.Path)
+	})
+	merged, err := MergeSourceSpecs(mergeTestSpecs(), resolve)
+	if err != nil {
+		t.Fatalf("MergeSourceSpecs() error = %v", err)
+	}
+	if merged.Asset.GetTemplate() != "${NAME}_${VERSION}_${OS}_${ARCH}${EXT}" {
+		t.Errorf("asset.template = %s, want the goreleaser template", merged.Asset.GetTemplate())
+	}
+	if len(merged.Asset.Rules) != 1 || merged.Asset.Rules[0].When.GetOS() != "darwin" {
+		t.Errorf("asset.rules = %+v, want the aqua rules", merged.Asset.Rules)
+	}
+
+	// A preferred source without a value falls back
+	_, err = PrecedenceResolver(map[string]string{"asset": "github"}, func(c Conflict) (int, error) {
+		return 0, errors.New("fallback")
+	})(Conflict{Path: "asset.template", Sources: []string{"goreleaser", "aqua"}, Values: []any{"a", "b"}})
+	if err == nil || err.Error() != "fallback" {
+		t.Errorf("resolver error = %v, want the fallback", err)
+	}
+}
+
+func TestPromptResolver(t *testing.T) {
+	c := Conflict{Path: "asset.template", Sources: []string{"goreleaser", "aqua"}, Values: []any{"a", "b"}}
+	tests := []struct {
+		input   string
+		want    int
+		wantErr bool
+	}{
+		{"2\n", 1, false},
+		{"\n", 0, false},
+		{"", 0, false},
+		{"3\n2\n", 1, false},
+		{"x", 0, true},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		got, err := PromptResolver(strings.NewReader(tt.input), &out)(c)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("PromptResolver(%q) = %d, %v, want %d (error %v)", tt.input, got, err, tt.want, tt.wantErr)
+		}
+		if !strings.Contains(out.String(), "Sources disagree on asset.template") || !strings.Contains(out.String(), "2) aqua:") {
+			t.Errorf("PromptResolver(%q) output =\n%s", tt.input, out.String())
+		}
+	}
+}
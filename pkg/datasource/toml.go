@@ -0,0 +1,346 @@
+package datasource
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseTOML parses the subset of TOML used by Cargo.toml and dist-workspace.toml
+// into nested maps: tables, arrays of tables, dotted keys, strings, booleans,
+// arrays and inline tables. Numbers and dates are kept as their raw text.
+func parseTOML(data string) (map[string]any, error) {
+	p := &tomlParser{s: strings.ReplaceAll(data, "\r\n", "\n"), line: 1}
+	root := map[string]any{}
+	current := root
+	for {
+		p.skipBlank(true)
+		if p.eof() {
+			return root, nil
+		}
+		var err error
+		if p.peek() == '[' {
+			current, err = p.tableHeader(root)
+		} else {
+			err = p.keyValue(current)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", p.line, err)
+		}
+		p.skipBlank(false)
+		if !p.eof() && p.peek() != '\n' {
+			return nil, fmt.Errorf("line %d: unexpected %q", p.line, p.peek())
+		}
+	}
+}
+
+// tomlParser is a recursive descent parser over a TOML document
+type tomlParser struct {
+	s    string
+	i    int
+	line int
+}
+
+func (p *tomlParser) eof() bool { return p.i >= len(p.s) }
+
+func (p *tomlParser) peek() byte { return p.s[p.i] }
+
+// skipBlank skips spaces and comments, and newlines too when newlines is set
+func (p *tomlParser) skipBlank(newlines bool) {
+	for !p.eof() {
+		switch c := p.peek(); {
+		case c == ' ' || c == '\t':
+			p.i++
+		case c == '#':
+			for !p.eof() && p.peek() != '\n' {
+				p.i++
+			}
+		case c == '\n' && newlines:
+			p.i++
+			p.line++
+		default:
+			return
+		}
+	}
+}
+
+// tableHeader parses [table] or [[array.of.tables]] and returns the table the
+// following keys belong to
+func (p *tomlParser) tableHeader(root map[string]any) (map[string]any, error) {
+	p.i++
+	array := !p.eof() && p.peek() == '['
+	if array {
+		p.i++
+	}
+	keys, err := p.keyPath()
+	if err != nil {
+		return nil, err
+	}
+	closing := "]"
+	if array {
+		closing = "]]"
+	}
+	if !strings.HasPrefix(p.s[p.i:], closing) {
+		return nil, fmt.Errorf("unterminated table header")
+	}
+	p.i += len(closing)
+
+	parent, err := descend(root, keys[:len(keys)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := keys[len(keys)-1]
+	if !array {
+		return descend(parent, []string{last})
+	}
+	table := map[string]any{}
+	switch existing := parent[last].(type) {
+	case nil:
+		parent[last] = []any{table}
+	case []any:
+		parent[last] = append(existing, table)
+	default:
+		return nil, fmt.Errorf("%s is not an array of tables", last)
+	}
+	return table, nil
+}
+
+// descend returns the table at keys below t, creating missing tables. The last
+// table of an array of tables stands for the array.
+func descend(t map[string]any, keys []string) (map[string]any, error) {
+	for _, key := range keys {
+		switch next := t[key].(type) {
+		case nil:
+			child := map[string]any{}
+			t[key] = child
+			t = child
+		case map[string]any:
+			t = next
+		case []any:
+			last, ok := next[len(next)-1].(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%s is not a table", key)
+			}
+			t = last
+		default:
+			return nil, fmt.Errorf("%s is not a table", key)
+		}
+	}
+	return t, nil
+}
+
+// keyValue parses key = value into t
+func (p *tomlParser) keyValue(t map[string]any) error {
+	keys, err := p.keyPath()
+	if err != nil {
+		return err
+	}
+	if p.eof() || p.peek() != '=' {
+		return fmt.Errorf("expected = after %s", strings.Join(keys, "."))
+	}
+	p.i++
+	p.skipBlank(false)
+	value, err := p.value()
+	if err != nil {
+		return err
+	}
+	parent, err := descend(t, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+	parent[keys[len(keys)-1]] = value
+	return nil
+}
+
+// keyPath parses a dotted key of bare or quoted keys
+func (p *tomlParser) keyPath() ([]string, error) {
+	var keys []string
+	for {
+		p.skipBlank(false)
+		if p.eof() {
+			return nil, fmt.Errorf("unexpected end of input in key")
+		}
+		var key string
+		if c := p.peek(); c == '"' || c == '\'' {
+			var err error
+			if key, err = p.str(); err != nil {
+				return nil, err
+			}
+		} else {
+			start := p.i
+			for !p.eof() && isBareKeyChar(p.peek()) {
+				p.i++
+			}
+			if start == p.i {
+				return nil, fmt.Errorf("invalid key character %q", p.peek())
+			}
+			key = p.s[start:p.i]
+		}
+		keys = append(keys, key)
+		p.skipBlank(false)
+		if p.eof() || p.peek() != '.' {
+			return keys, nil
+		}
+		p.i++
+	}
+}
+
+func isBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+// value parses a string, array, inline table, boolean or other scalar
+func (p *tomlParser) value() (any, error) {
+	if p.eof() {
+		return nil, fmt.Errorf("missing value")
+	}
+	switch p.peek() {
+	case '"', '\'':
+		return p.str()
+	case '[':
+		return p.array()
+	case '{':
+		return p.inlineTable()
+	}
+	start := p.i
+	for !p.eof() && !strings.ContainsRune(",]}#\n \t", rune(p.peek())) {
+		p.i++
+	}
+	raw := strings.TrimSpace(p.s[start:p.i])
+	switch raw {
+	case "":
+		return nil, fmt.Errorf("missing value")
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	return raw, nil
+}
+
+// str parses a basic, literal or multi-line string
+func (p *tomlParser) str() (string, error) {
+	quote := p.s[p.i : p.i+1]
+	if strings.HasPrefix(p.s[p.i:], quote+quote+quote) {
+		delim := quote + quote + quote
+		end := strings.Index(p.s[p.i+3:], delim)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated multi-line string")
+		}
+		body := p.s[p.i+3 : p.i+3+end]
+		p.line += strings.Count(body, "\n")
+		p.i += 3 + end + 3
+		body = strings.TrimPrefix(body, "\n")
+		if quote == "'" {
+			return body, nil
+		}
+		return unquoteBasic(body)
+	}
+	p.i++
+	start := p.i
+	for !p.eof() && p.peek() != quote[0] && p.peek() != '\n' {
+		if quote == `"` && p.peek() == '\\' {
+			p.i++
+		}
+		p.i++
+	}
+	if p.eof() || p.peek() != quote[0] {
+		return "", fmt.Errorf("unterminated string")
+	}
+	body := p.s[start:p.i]
+	p.i++
+	if quote == "'" {
+		return body, nil
+	}
+	return unquoteBasic(body)
+}
+
+// unquoteBasic resolves the escapes of a basic string, which TOML shares with Go
+func unquoteBasic(body string) (string, error) {
+	if !strings.Contains(body, `\`) {
+		return body, nil
+	}
+	s, err := strconv.Unquote(`"` + strings.ReplaceAll(body, "\n", `\n`) + `"`)
+	if err != nil {
+		return "", fmt.Errorf("invalid escape in string %q", body)
+	}
+	return s, nil
+}
+
+// array parses an array, which may span lines
+func (p *tomlParser) array() ([]any, error) {
+	p.i++
+	var values []any
+	for {
+		p.skipBlank(true)
+		if p.eof() {
+			return nil, fmt.Errorf("unterminated array")
+		}
+		if p.peek() == ']' {
+			p.i++
+			return values, nil
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+		p.skipBlank(true)
+		if !p.eof() && p.peek() == ',' {
+			p.i++
+		}
+	}
+}
+
+// inlineTable parses { key = value, ... }
+func (p *tomlParser) inlineTable() (map[string]any, error) {
+	p.i++
+	t := map[string]any{}
+	for {
+		p.skipBlank(false)
+		if p.eof() {
+			return nil, fmt.Errorf("unterminated inline table")
+		}
+		if p.peek() == '}' {
+			p.i++
+			return t, nil
+		}
+		if err := p.keyValue(t); err != nil {
+			return nil, err
+		}
+		p.skipBlank(false)
+		if !p.eof() && p.peek() == ',' {
+			p.i++
+		}
+	}
+}
+
+// tomlTable returns the table at the dotted path below t, or nil
+func tomlTable(t map[string]any, path string) map[string]any {
+	for _, key := range strings.Split(path, ".") {
+		next, ok := t[key].(map[string]any)
+		if !ok {
+			return nil
+		}
+		t = next
+	}
+	return t
+}
+
+// tomlString returns the string value of key in t, or ""
+func tomlString(t map[string]any, key string) string {
+	s, _ := t[key].(string)
+	return s
+}
+
+// tomlStrings returns the strings of an array value of key in t
+func tomlStrings(t map[string]any, key string) []string {
+	values, _ := t[key].([]any)
+	var result []string
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
@@ -0,0 +1,62 @@
+package datasource
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseTOML(t *testing.T) {
+	doc, err := parseTOML(`# comment
+title = "a \"quoted\" \u00e9"
+path = 'C:\bin'
+"quoted.key" = true
+site.url = "https://example.com" # trailing comment
+
+[server]
+ports = [ 80,
+  443, ]
+limits = { cpu = 2, tags = ["a", 'b'] }
+
+[[bin]]
+name = "one"
+
+[[bin]]
+name = "two"
+
+[bin.meta]
+x = false
+`)
+	if err != nil {
+		t.Fatalf("parseTOML() error = %v", err)
+	}
+	want := map[string]any{
+		"title":      `a "quoted" é`,
+		"path":       `C:\bin`,
+		"quoted.key": true,
+		"site":       map[string]any{"url": "https://example.com"},
+		"server": map[string]any{
+			"ports":  []any{"80", "443"},
+			"limits": map[string]any{"cpu": "2", "tags": []any{"a", "b"}},
+		},
+		"bin": []any{
+			map[string]any{"name": "one"},
+			map[string]any{"name": "two", "meta": map[string]any{"x": false}},
+		},
+	}
+	if diff := cmp.Diff(want, doc); diff != "" {
+		t.Errorf("parseTOML() mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, invalid := range []string{
+		"key",
+		"key = \"unterminated\n",
+		"[table\n",
+		"a = 1 b = 2\n",
+		"a = 1\n[[a]]\n",
+	} {
+		if _, err := parseTOML(invalid); err == nil {
+			t.Errorf("parseTOML(%q) error = nil, want error", invalid)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// Package delta applies binary patches that turn a previously downloaded
+// release asset into a newer one.
+package delta
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Format identifies a binary patch format
+type Format string
+
+const (
+	// FormatZstd is a zstd frame compressed with the old file as raw dictionary
+	// (zstd --patch-from=OLD NEW)
+	FormatZstd Format = "zstd"
+	// FormatBsdiff is the BSDIFF40 format produced by bsdiff
+	FormatBsdiff Format = "bsdiff"
+)
+
+// maxWindowSize bounds the zstd window so patches against large assets decode
+const maxWindowSize = 1 << 31
+
+// MaxSize bounds the size of a patched file. Patch headers are untrusted, so a
+// larger announced or decoded size fails instead of being allocated.
+const MaxSize = 1 << 31
+
+// ApplyFile applies the patch at patchPath to oldPath and writes the result to newPath
+func ApplyFile(format Format, oldPath, patchPath, newPath string) error {
+	old, err := os.ReadFile(oldPath)
+	if err != nil {
+		return fmt.Errorf("failed to read old file: %w", err)
+	}
+	patch, err := os.ReadFile(patchPath)
+	if err != nil {
+		return fmt.Errorf("failed to read patch: %w", err)
+	}
+	result, err := Apply(format, old, patch)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(newPath, result, 0644); err != nil {
+		return fmt.Errorf("failed to write patched file: %w", err)
+	}
+	return nil
+}
+
+// Apply applies patch to old and returns the new contents
+func Apply(format Format, old, patch []byte) ([]byte, error) {
+	switch format {
+	case FormatZstd, "":
+		return applyZstd(old, patch)
+	case FormatBsdiff:
+		return applyBsdiff(old, patch)
+	default:
+		return nil, fmt.Errorf("unsupported patch format: %s", format)
+	}
+}
+
+// applyZstd decodes a zstd --patch-from patch using old as raw dictionary
+func applyZstd(old, patch []byte) ([]byte, error) {
+	decoder, err := zstd.NewReader(nil,
+		zstd.WithDecoderDictRaw(0, old),
+		zstd.WithDecoderMaxWindow(maxWindowSize),
+		zstd.WithDecoderMaxMemory(MaxSize),
+		zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd decoder: %w", err)
+	}
+	defer decoder.Close()
+
+	result, err := decoder.DecodeAll(patch, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply zstd patch: %w", err)
+	}
+	return result, nil
+}
+
+// bsdiffMagic is the header of BSDIFF40 patches
+const bsdiffMagic = "BSDIFF40"
+
+// applyBsdiff applies a BSDIFF40 patch.
+//
+// Layout: 32 byte header (magic, control length, diff length, new size), followed by
+// bzip2-compressed control, diff, and extra blocks.
+func applyBsdiff(old, patch []byte) ([]byte, error) {
+	if len(patch) < 32 || string(patch[:8]) != bsdiffMagic {
+		return nil, errors.New("not a BSDIFF40 patch")
+	}
+	ctrlLen := offtin(patch[8:16])
+	diffLen := offtin(patch[16:24])
+	newSize := offtin(patch[24:32])
+	if ctrlLen < 0 || diffLen < 0 || newSize < 0 || ctrlLen > int64(len(patch)-32) || diffLen > int64(len(patch)-32)-ctrlLen {
+		return nil, errors.New("corrupt BSDIFF40 header")
+	}
+	if newSize > MaxSize {
+		return nil, fmt.Errorf("BSDIFF40 patch announces %d bytes, more than the %d byte limit", newSize, int64(MaxSize))
+	}
+
+	body := patch[32:]
+	ctrl := bzip2.NewReader(bytes.NewReader(body[:ctrlLen]))
+	diff := bzip2.NewReader(bytes.NewReader(body[ctrlLen : ctrlLen+diffLen]))
+	extra := bzip2.NewReader(bytes.NewReader(body[ctrlLen+diffLen:]))
+
+	result := make([]byte, newSize)
+	var newPos, oldPos int64
+	var buf [24]byte
+	for newPos < newSize {
+		if _, err := io.ReadFull(ctrl, buf[:]); err != nil {
+			return nil, fmt.Errorf("corrupt BSDIFF40 control block: %w", err)
+		}
+		add, copyLen, seek := offtin(buf[0:8]), offtin(buf[8:16]), offtin(buf[16:24])
+
+		// Add old data to diff bytes
+		if add < 0 || add > newSize-newPos {
+			return nil, errors.New("corrupt BSDIFF40 patch: diff out of range")
+		}
+		if _, err := io.ReadFull(diff, result[newPos:newPos+add]); err != nil {
+			return nil, fmt.Errorf("corrupt BSDIFF40 diff block: %w", err)
+		}
+		for i := int64(0); i < add; i++ {
+			if oldPos+i >= 0 && oldPos+i < int64(len(old)) {
+				result[newPos+i] += old[oldPos+i]
+			}
+		}
+		newPos += add
+		oldPos += add
+
+		// Copy extra bytes
+		if copyLen < 0 || copyLen > newSize-newPos {
+			return nil, errors.New("corrupt BSDIFF40 patch: extra out of range")
+		}
+		if _, err := io.ReadFull(extra, result[newPos:newPos+copyLen]); err != nil {
+			return nil, fmt.Errorf("corrupt BSDIFF40 extra block: %w", err)
+		}
+		newPos += copyLen
+		oldPos += seek
+	}
+	return result, nil
+}
+
+// offtin decodes bsdiff's sign-magnitude little-endian 64-bit integer
+func offtin(b []byte) int64 {
+	v := binary.LittleEndian.Uint64(b)
+	n := int64(v &^ (1 << 63))
+	if v&(1<<63) != 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,145 @@
+package delta
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dsnet/compress/bzip2"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestApplyZstd(t *testing.T) {
+	old := bytes.Repeat([]byte("binstaller release asset v1.0.0\n"), 512)
+	want := append(bytes.Repeat([]byte("binstaller release asset v1.1.0\n"), 512), []byte("trailer")...)
+
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderDictRaw(0, old))
+	if err != nil {
+		t.Fatal(err)
+	}
+	patch := encoder.EncodeAll(want, nil)
+	encoder.Close()
+
+	got, err := Apply(FormatZstd, old, patch)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("Apply() result does not match the new file")
+	}
+}
+
+func TestApplyBsdiff(t *testing.T) {
+	old := []byte("hello old world, this stays the same")
+	want := []byte("hello new world, this stays the same!!")
+
+	got, err := Apply(FormatBsdiff, old, makeBsdiff(t, old, want))
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Apply() = %q, want %q", got, want)
+	}
+}
+
+func TestApplyBsdiffCorrupt(t *testing.T) {
+	tests := map[string][]byte{
+		"short":     []byte("BSDIFF40"),
+		"bad magic": bytes.Repeat([]byte{0}, 32),
+		"bad sizes": append([]byte(bsdiffMagic), bytes.Repeat([]byte{0xff}, 24)...),
+		"huge size": append([]byte(bsdiffMagic), binary.LittleEndian.AppendUint64(make([]byte, 16), 1<<40)...),
+	}
+	for name, patch := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Apply(FormatBsdiff, []byte("old"), patch); err == nil {
+				t.Error("Apply() expected error for corrupt patch")
+			}
+		})
+	}
+}
+
+func TestApplyFile(t *testing.T) {
+	dir := t.TempDir()
+	old := []byte("old contents")
+	want := []byte("new contents")
+
+	oldPath := filepath.Join(dir, "old")
+	patchPath := filepath.Join(dir, "patch")
+	newPath := filepath.Join(dir, "new")
+	if err := os.WriteFile(oldPath, old, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(patchPath, makeBsdiff(t, old, want), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ApplyFile(FormatBsdiff, oldPath, patchPath, newPath); err != nil {
+		t.Fatalf("ApplyFile() error = %v", err)
+	}
+	got, err := os.ReadFile(newPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("ApplyFile() wrote %q, want %q", got, want)
+	}
+
+	if err := ApplyFile("xdelta", oldPath, patchPath, newPath); err == nil {
+		t.Error("ApplyFile() expected error for unsupported format")
+	}
+}
+
+// makeBsdiff builds a minimal BSDIFF40 patch: one control entry diffing the common
+// prefix against old and emitting the rest as extra bytes.
+func makeBsdiff(t *testing.T, old, new []byte) []byte {
+	t.Helper()
+	n := min(len(old), len(new))
+	diff := make([]byte, n)
+	for i := range n {
+		diff[i] = new[i] - old[i]
+	}
+	extra := new[n:]
+
+	ctrl := make([]byte, 24)
+	offtout(ctrl[0:8], int64(n))
+	offtout(ctrl[8:16], int64(len(extra)))
+	offtout(ctrl[16:24], 0)
+
+	ctrlBz := bzip2Compress(t, ctrl)
+	diffBz := bzip2Compress(t, diff)
+	extraBz := bzip2Compress(t, extra)
+
+	header := make([]byte, 32)
+	copy(header, bsdiffMagic)
+	offtout(header[8:16], int64(len(ctrlBz)))
+	offtout(header[16:24], int64(len(diffBz)))
+	offtout(header[24:32], int64(len(new)))
+
+	return bytes.Join([][]byte{header, ctrlBz, diffBz, extraBz}, nil)
+}
+
+func offtout(b []byte, v int64) {
+	if v < 0 {
+		binary.LittleEndian.PutUint64(b, uint64(-v)|1<<63)
+		return
+	}
+	binary.LittleEndian.PutUint64(b, uint64(v))
+}
+
+func bzip2Compress(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := bzip2.NewWriter(&buf, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,426 @@
+// Package aqua renders aqua registry package entries from InstallSpecs, the
+// reverse of the aqua registry datasource.
+package aqua
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/binary-install/binstaller/pkg/asset"
+	"github.com/binary-install/binstaller/pkg/export"
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/buildkite/interpolate"
+	"github.com/goccy/go-yaml"
+)
+
+// GeneratedComment is the comment heading every registry rendered by this package
+const GeneratedComment = "Code generated by binstaller. DO NOT EDIT."
+
+// runtimes are the platforms aqua installs packages on, in registry order
+var runtimes = []struct{ os, arch string }{
+	{"darwin", "amd64"},
+	{"darwin", "arm64"},
+	{"linux", "amd64"},
+	{"linux", "arm64"},
+	{"windows", "amd64"},
+	{"windows", "arm64"},
+}
+
+// formats are the archive formats of aqua, longer extensions first
+var formats = []string{
+	"tar.br", "tar.bz2", "tar.gz", "tar.lz4", "tar.sz", "tar.xz", "tar.zst",
+	"tbr", "tbz", "tbz2", "tgz", "tlz4", "tsz", "txz",
+	"zip", "gz", "bz2", "lz4", "sz", "xz", "zst", "dmg", "pkg", "rar", "tar",
+}
+
+// Registry is an aqua registry.yaml
+type Registry struct {
+	Packages []*Package `yaml:"packages"`
+}
+
+// Package is an aqua github_release package
+type Package struct {
+	Type            string            `yaml:"type"`
+	RepoOwner       string            `yaml:"repo_owner"`
+	RepoName        string            `yaml:"repo_name"`
+	Description     string            `yaml:"description"`
+	Link            string            `yaml:"link,omitempty"`
+	Asset           string            `yaml:"asset"`
+	Format          string            `yaml:"format"`
+	Rosetta2        bool              `yaml:"rosetta2,omitempty"`
+	Files           []*File           `yaml:"files,omitempty"`
+	Replacements    map[string]string `yaml:"replacements,omitempty"`
+	FormatOverrides []*FormatOverride `yaml:"format_overrides,omitempty"`
+	Overrides       []*Override       `yaml:"overrides,omitempty"`
+	Checksum        *Checksum         `yaml:"checksum,omitempty"`
+	SupportedEnvs   []string          `yaml:"supported_envs,omitempty"`
+}
+
+// File is an executable of a package
+type File struct {
+	Name string `yaml:"name"`
+	// Src is the path inside the archive, empty when it is Name
+	Src string `yaml:"src,omitempty"`
+}
+
+// FormatOverride is the archive format of one OS
+type FormatOverride struct {
+	GOOS   string `yaml:"goos"`
+	Format string `yaml:"format"`
+}
+
+// Override is the package settings of an OS, or of one platform when GOArch is set
+type Override struct {
+	GOOS         string            `yaml:"goos"`
+	GOArch       string            `yaml:"goarch,omitempty"`
+	Asset        string            `yaml:"asset,omitempty"`
+	Format       string            `yaml:"format,omitempty"`
+	Files        []*File           `yaml:"files,omitempty"`
+	Replacements map[string]string `yaml:"replacements,omitempty"`
+	Checksum     *Checksum         `yaml:"checksum,omitempty"`
+}
+
+// empty reports whether the override changes nothing
+func (o *Override) empty() bool {
+	return o.Asset == "" && o.Format == "" && o.Files == nil && len(o.Replacements) == 0 && o.Checksum == nil
+}
+
+// Checksum is the release checksum file of a package
+type Checksum struct {
+	Type      string `yaml:"type"`
+	Asset     string `yaml:"asset"`
+	Algorithm string `yaml:"algorithm"`
+}
+
+// platform is what one runtime resolves to after applying the spec's rules
+type platform struct {
+	os, arch  string
+	osValue   string
+	archValue string
+	asset     string
+	format    string
+	files     []*File
+	checksum  *Checksum
+}
+
+// New builds the aqua registry entry of installSpec. Asset rules become
+// replacements, format_overrides and overrides by resolving every aqua runtime
+// the spec supports; os_version rules and fallback templates have no aqua
+// equivalent and are left out.
+func New(installSpec *spec.InstallSpec) (*Registry, error) {
+	installSpec.SetDefaults()
+	owner, repoName, ok := strings.Cut(installSpec.GetRepo(), "/")
+	if !ok || strings.Contains(repoName, "/") {
+		return nil, fmt.Errorf("invalid GitHub repository %q", installSpec.GetRepo())
+	}
+	if installSpec.GetSource() == spec.Gitlab || !strings.HasPrefix(asset.BaseURL(installSpec), "https://github.com") {
+		return nil, fmt.Errorf("aqua github_release packages only support releases on github.com")
+	}
+	if installSpec.GetUnpack().GetStripComponents() > 0 {
+		return nil, fmt.Errorf("aqua packages cannot strip leading archive path components (unpack.strip_components)")
+	}
+	if installSpec.GetAsset().GetTemplate() == "" {
+		return nil, fmt.Errorf("asset template not defined in spec")
+	}
+
+	pkg := &Package{
+		Type:        "github_release",
+		RepoOwner:   owner,
+		RepoName:    repoName,
+		Description: "Release binaries of " + installSpec.GetRepo(),
+		Link:        installSpec.GetMetadata().GetHomepage(),
+		Rosetta2:    installSpec.GetAsset().GetRosetta2(),
+	}
+	c := &converter{name: installSpec.GetName(), os: "{{.OS}}"}
+
+	generator := asset.NewFilenameGenerator(installSpec, "")
+	var resolutions []*asset.Resolution
+	var platforms []*platform
+	for _, rt := range runtimes {
+		if !export.Supported(installSpec, rt.os, rt.arch) {
+			continue
+		}
+		resolution, err := generator.Resolve(rt.os, rt.arch)
+		if err != nil {
+			return nil, err
+		}
+		resolutions = append(resolutions, resolution)
+		platforms = append(platforms, &platform{os: rt.os, arch: rt.arch, osValue: resolution.OS, archValue: resolution.Arch})
+	}
+	if len(platforms) == 0 {
+		return nil, fmt.Errorf("%s supports none of the darwin, linux and windows amd64/arm64 platforms of aqua", installSpec.GetRepo())
+	}
+
+	// Aqua titlecases every OS or none, so the naming convention only carries
+	// over when no rule renames an OS
+	expectedOS := func(osName string) string { return osName }
+	if installSpec.GetAsset().GetOSNamingConvention() == spec.Titlecase && !slices.ContainsFunc(platforms, func(p *platform) bool {
+		return p.osValue != titleCase(p.os)
+	}) {
+		c.os = "{{title .OS}}"
+		expectedOS = titleCase
+	}
+
+	for i, p := range platforms {
+		var err error
+		if p.asset, p.format, err = c.asset(resolutions[i].Template, resolutions[i].EXT); err != nil {
+			return nil, err
+		}
+		if p.files, err = c.files(generator.Binaries(p.os, p.arch), p.format != "raw"); err != nil {
+			return nil, err
+		}
+		if p.checksum, err = c.checksum(installSpec, generator.ChecksumTemplate(p.os, p.arch)); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	if pkg.Asset, pkg.Format, err = c.asset(installSpec.GetAsset().GetTemplate(), installSpec.GetAsset().GetDefaultExtension()); err != nil {
+		return nil, err
+	}
+	if pkg.Files, err = c.files(installSpec.Asset.Binaries, pkg.Format != "raw"); err != nil {
+		return nil, err
+	}
+	if pkg.Checksum, err = c.checksum(installSpec, installSpec.GetChecksums().GetTemplate()); err != nil {
+		return nil, err
+	}
+
+	// Replacements shared by every platform of an OS or of an architecture
+	pkg.Replacements = make(map[string]string)
+	for _, key := range uniqueKeys(platforms, func(p *platform) string { return p.os }) {
+		if value, ok := sharedValue(platforms, func(p *platform) bool { return p.os == key }, func(p *platform) string { return p.osValue }); ok && value != expectedOS(key) {
+			pkg.Replacements[key] = value
+		}
+	}
+	for _, key := range uniqueKeys(platforms, func(p *platform) string { return p.arch }) {
+		if value, ok := sharedValue(platforms, func(p *platform) bool { return p.arch == key }, func(p *platform) string { return p.archValue }); ok && value != key {
+			pkg.Replacements[key] = value
+		}
+	}
+
+	if len(pkg.Replacements) == 0 {
+		pkg.Replacements = nil
+	}
+
+	// Formats shared by every platform of an OS
+	formats := make(map[string]string)
+	for _, osName := range uniqueKeys(platforms, func(p *platform) string { return p.os }) {
+		formats[osName] = pkg.Format
+		if format, ok := sharedValue(platforms, func(p *platform) bool { return p.os == osName }, func(p *platform) string { return p.format }); ok && format != pkg.Format {
+			pkg.FormatOverrides = append(pkg.FormatOverrides, &FormatOverride{GOOS: osName, Format: format})
+			formats[osName] = format
+		}
+	}
+
+	// Whatever is left differs per platform and becomes an override, merged
+	// into one override per OS when all of its platforms agree
+	residuals := make(map[*platform]*Override)
+	for _, p := range platforms {
+		ov := &Override{GOOS: p.os, GOArch: p.arch, Replacements: make(map[string]string)}
+		if osValue, ok := pkg.Replacements[p.os]; (ok && osValue != p.osValue) || (!ok && p.osValue != expectedOS(p.os)) {
+			ov.Replacements[p.os] = p.osValue
+		}
+		if archValue, ok := pkg.Replacements[p.arch]; (ok && archValue != p.archValue) || (!ok && p.archValue != p.arch) {
+			ov.Replacements[p.arch] = p.archValue
+		}
+		if p.asset != pkg.Asset {
+			ov.Asset = p.asset
+		}
+		if p.format != formats[p.os] {
+			ov.Format = p.format
+		}
+		if !reflect.DeepEqual(p.files, pkg.Files) {
+			ov.Files = p.files
+			if ov.Files == nil {
+				ov.Files = []*File{{Name: c.name}}
+			}
+		}
+		if !reflect.DeepEqual(p.checksum, pkg.Checksum) {
+			ov.Checksum = p.checksum
+		}
+		if len(ov.Replacements) == 0 {
+			ov.Replacements = nil
+		}
+		residuals[p] = ov
+	}
+	for _, osName := range uniqueKeys(platforms, func(p *platform) string { return p.os }) {
+		var overrides []*Override
+		for _, p := range platforms {
+			if p.os == osName {
+				overrides = append(overrides, residuals[p])
+			}
+		}
+		if shared := sharedOverride(overrides); shared != nil {
+			if !shared.empty() {
+				pkg.Overrides = append(pkg.Overrides, shared)
+			}
+			continue
+		}
+		for _, ov := range overrides {
+			if !ov.empty() {
+				pkg.Overrides = append(pkg.Overrides, ov)
+			}
+		}
+	}
+
+	for _, p := range installSpec.SupportedPlatforms {
+		pkg.SupportedEnvs = append(pkg.SupportedEnvs, spec.PlatformOSString(p.OS)+"/"+spec.PlatformArchString(p.Arch))
+	}
+	return &Registry{Packages: []*Package{pkg}}, nil
+}
+
+// converter converts InstallSpec templates to aqua templates
+type converter struct {
+	name string
+	// os is the aqua expression of ${OS}
+	os string
+}
+
+// template converts an InstallSpec template to an aqua template, with ${EXT} as ext
+func (c *converter) template(tmpl, ext string) (string, error) {
+	return interpolate.Interpolate(interpolate.NewMapEnv(map[string]string{
+		"NAME":           c.name,
+		"TAG":            "{{.Version}}",
+		"VERSION":        "{{trimV .Version}}",
+		"OS":             c.os,
+		"ARCH":           "{{.Arch}}",
+		"EXT":            ext,
+		"ASSET_FILENAME": "{{.Asset}}",
+	}), tmpl)
+}
+
+// asset returns the aqua asset template and format of an asset template and
+// extension. Archive extensions from ${EXT} are referenced through {{.Format}};
+// other assets keep their filename and are raw unless it ends in a format.
+func (c *converter) asset(tmpl, ext string) (string, string, error) {
+	format := strings.TrimPrefix(ext, ".")
+	usesEXT := strings.Contains(tmpl, "${EXT}") || strings.Contains(tmpl, "$EXT")
+	if usesEXT && slices.Contains(formats, format) {
+		ext = ".{{.Format}}"
+	}
+	assetTmpl, err := c.template(tmpl, ext)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to convert asset template %q: %w", tmpl, err)
+	}
+	if ext == ".{{.Format}}" {
+		return assetTmpl, format, nil
+	}
+	for _, format := range formats {
+		if strings.HasSuffix(strings.ToLower(assetTmpl), "."+format) {
+			return assetTmpl, format, nil
+		}
+	}
+	return assetTmpl, "raw", nil
+}
+
+// files returns the aqua files of binaries, nil for the default single file
+// named after the spec. Raw executables have no path inside an archive.
+func (c *converter) files(binaries []spec.BinaryElement, archive bool) ([]*File, error) {
+	var files []*File
+	for _, b := range binaries {
+		f := &File{Name: spec.StringValue(b.Name)}
+		if f.Name == "" {
+			f.Name = c.name
+		}
+		if archive {
+			src, err := c.template(spec.StringValue(b.Path), "")
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert binary path %q: %w", spec.StringValue(b.Path), err)
+			}
+			if src != f.Name {
+				f.Src = src
+			}
+		}
+		files = append(files, f)
+	}
+	if len(files) == 1 && *files[0] == (File{Name: c.name}) {
+		return nil, nil
+	}
+	return files, nil
+}
+
+// checksum returns the aqua checksum of a checksum file template, nil without one
+func (c *converter) checksum(installSpec *spec.InstallSpec, tmpl string) (*Checksum, error) {
+	if tmpl == "" {
+		return nil, nil
+	}
+	checksumTmpl, err := c.template(tmpl, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert checksum template %q: %w", tmpl, err)
+	}
+	return &Checksum{
+		Type:      "github_release",
+		Asset:     checksumTmpl,
+		Algorithm: spec.AlgorithmString(installSpec.GetChecksums().Algorithm),
+	}, nil
+}
+
+// uniqueKeys returns the distinct keys of platforms in first-seen order
+func uniqueKeys(platforms []*platform, key func(*platform) string) []string {
+	var keys []string
+	for _, p := range platforms {
+		if !slices.Contains(keys, key(p)) {
+			keys = append(keys, key(p))
+		}
+	}
+	return keys
+}
+
+// sharedValue returns the value of the platforms matching filter when they all agree
+func sharedValue(platforms []*platform, filter func(*platform) bool, value func(*platform) string) (string, bool) {
+	values := make(map[string]bool)
+	var shared string
+	for _, p := range platforms {
+		if filter(p) {
+			shared = value(p)
+			values[shared] = true
+		}
+	}
+	return shared, len(values) == 1
+}
+
+// sharedOverride returns the override of an OS when all of its platform
+// overrides agree, nil otherwise. Replacements merge as long as no key maps to
+// two values, since each platform only looks up its own OS and architecture.
+func sharedOverride(overrides []*Override) *Override {
+	shared := *overrides[0]
+	shared.GOArch = ""
+	shared.Replacements = make(map[string]string)
+	for _, ov := range overrides {
+		other := *ov
+		other.GOArch = ""
+		other.Replacements = shared.Replacements
+		if !reflect.DeepEqual(shared, other) {
+			return nil
+		}
+		for key, value := range ov.Replacements {
+			if merged, ok := shared.Replacements[key]; ok && merged != value {
+				return nil
+			}
+			shared.Replacements[key] = value
+		}
+	}
+	if len(shared.Replacements) == 0 {
+		shared.Replacements = nil
+	}
+	return &shared
+}
+
+// titleCase capitalizes the first letter of an OS like aqua's title function
+func titleCase(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// Render renders the registry as YAML
+func (r *Registry) Render() ([]byte, error) {
+	out, err := yaml.MarshalWithOptions(r, yaml.IndentSequence(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to render aqua registry: %w", err)
+	}
+	return append([]byte("# "+GeneratedComment+"\n"), out...), nil
+}
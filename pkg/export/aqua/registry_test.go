@@ -0,0 +1,169 @@
+package aqua
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/asset"
+	"github.com/binary-install/binstaller/pkg/datasource"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+func TestRender(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/my-tool").
+		WithAsset(spec.NewAsset("${NAME}_${VERSION}_${OS}_${ARCH}${EXT}").
+			WithDefaultExtension(".tar.gz").
+			WithBinary("my-tool", "${NAME}_${VERSION}/my-tool").
+			WithRules(
+				spec.NewRule("windows", "").WithExt(".zip"),
+				spec.NewRule("", "amd64").WithArch("x86_64"),
+				spec.NewRule("darwin", "").WithOS("macOS"),
+				spec.NewRule("linux", "arm64").WithTemplate("${NAME}-linux-aarch64").WithExt(""),
+			)).
+		WithChecksums(spec.NewChecksums("${NAME}_${VERSION}_checksums.txt")).
+		WithSupportedPlatforms("darwin/amd64", "darwin/arm64", "linux/amd64", "linux/arm64", "windows/amd64")
+
+	r, err := New(installSpec)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	got, err := r.Render()
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	want := `# ` + GeneratedComment + `
+packages:
+  - type: github_release
+    repo_owner: owner
+    repo_name: my-tool
+    description: Release binaries of owner/my-tool
+    asset: my-tool_{{trimV .Version}}_{{.OS}}_{{.Arch}}.{{.Format}}
+    format: tar.gz
+    files:
+      - name: my-tool
+        src: my-tool_{{trimV .Version}}/my-tool
+    replacements:
+      amd64: x86_64
+      darwin: macOS
+    format_overrides:
+      - goos: windows
+        format: zip
+    overrides:
+      - goos: linux
+        goarch: arm64
+        asset: my-tool-linux-aarch64
+        format: raw
+        files:
+          - name: my-tool
+    checksum:
+      type: github_release
+      asset: my-tool_{{trimV .Version}}_checksums.txt
+      algorithm: sha256
+    supported_envs:
+      - darwin/amd64
+      - darwin/arm64
+      - linux/amd64
+      - linux/arm64
+      - windows/amd64
+`
+	if string(got) != want {
+		t.Errorf("Render() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestNewTitlecase(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/my-tool").
+		WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}${EXT}").
+			WithDefaultExtension(".tar.gz").
+			WithOSNamingConvention(spec.Titlecase))
+
+	r, err := New(installSpec)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	pkg := r.Packages[0]
+	if want := "my-tool_{{title .OS}}_{{.Arch}}.{{.Format}}"; pkg.Asset != want {
+		t.Errorf("Asset = %q, want %q", pkg.Asset, want)
+	}
+	if len(pkg.Replacements) != 0 || len(pkg.Overrides) != 0 {
+		t.Errorf("Replacements = %v, Overrides = %v, want none", pkg.Replacements, pkg.Overrides)
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	tests := map[string]*spec.InstallSpec{
+		"gitlab": spec.NewInstallSpec("group/sub/tool").WithSource(spec.Gitlab, "").
+			WithAsset(spec.NewAsset("${NAME}${EXT}")),
+		"strip components": spec.NewInstallSpec("owner/tool").
+			WithAsset(spec.NewAsset("${NAME}${EXT}")).
+			WithUnpack(spec.NewUnpack(1)),
+		"no aqua platform": spec.NewInstallSpec("owner/tool").
+			WithAsset(spec.NewAsset("${NAME}${EXT}")).
+			WithSupportedPlatforms("linux/386"),
+	}
+	for name, installSpec := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := New(installSpec); err == nil {
+				t.Error("New() error = nil, want error")
+			}
+		})
+	}
+}
+
+// TestRoundTrip checks that importing an exported registry with the aqua
+// datasource resolves the same asset filenames
+func TestRoundTrip(t *testing.T) {
+	tests := map[string]*spec.InstallSpec{
+		"archives": spec.NewInstallSpec("owner/my-tool").
+			WithAsset(spec.NewAsset("${NAME}_${VERSION}_${OS}_${ARCH}${EXT}").
+				WithDefaultExtension(".tar.gz").
+				WithRules(
+					spec.NewRule("windows", "").WithExt(".zip"),
+					spec.NewRule("", "arm64").WithArch("aarch64"),
+				)),
+		"raw with per-platform names": spec.NewInstallSpec("owner/my-tool").
+			WithAsset(spec.NewAsset("${NAME}-${TAG}-${OS}-${ARCH}${EXT}").
+				WithRules(
+					spec.NewRule("windows", "").WithExt(".exe"),
+					spec.NewRule("darwin", "").WithArch("universal"),
+					spec.NewRule("linux", "amd64").WithArch("x64"),
+				)),
+	}
+	for name, installSpec := range tests {
+		t.Run(name, func(t *testing.T) {
+			r, err := New(installSpec)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			out, err := r.Render()
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			imported, err := datasource.NewAquaRegistryAdapterFromReader(bytes.NewReader(out)).GenerateInstallSpec(context.Background())
+			if err != nil {
+				t.Fatalf("GenerateInstallSpec() error = %v\n%s", err, out)
+			}
+			imported.Name = installSpec.Name
+			imported.SetDefaults()
+
+			want := asset.NewFilenameGenerator(installSpec, "v1.2.3")
+			got := asset.NewFilenameGenerator(imported, "v1.2.3")
+			for _, rt := range runtimes {
+				wantName, err := want.GenerateFilename(rt.os, rt.arch)
+				if err != nil {
+					t.Fatal(err)
+				}
+				gotName, err := got.GenerateFilename(rt.os, rt.arch)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if gotName != wantName {
+					t.Errorf("%s/%s: filename = %q, want %q\n%s", rt.os, rt.arch, gotName, wantName, strings.TrimSpace(string(out)))
+				}
+			}
+		})
+	}
+}
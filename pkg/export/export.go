@@ -0,0 +1,129 @@
+// Package export resolves the release assets of an InstallSpec per platform for
+// the package manifests rendered by its subpackages.
+package export
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/binary-install/binstaller/pkg/archive"
+	"github.com/binary-install/binstaller/pkg/asset"
+	"github.com/binary-install/binstaller/pkg/checksums"
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/buildkite/interpolate"
+)
+
+// HashFunc returns the hash of the release asset of a platform using the spec's checksum algorithm
+type HashFunc func(osName, arch, filename string) (string, error)
+
+// Asset is the release asset of one platform
+type Asset struct {
+	OS       string
+	Arch     string
+	Filename string
+	URL      string
+	// Hash is the checksum of the asset using Algorithm
+	Hash      string
+	Algorithm string
+	// ChecksumURL is the release file listing Hash, empty when the spec has none
+	ChecksumURL string
+	// Archive reports whether the asset is an archive rather than a raw executable
+	Archive  bool
+	Binaries []Binary
+}
+
+// Binary is an executable of a release asset
+type Binary struct {
+	// Name is the installed name, with .exe on Windows
+	Name string
+	// Path is the path inside the extracted archive, or the asset filename of a raw executable
+	Path string
+}
+
+// Supported reports whether installSpec supports a platform. Specs without
+// supported_platforms support every platform.
+func Supported(installSpec *spec.InstallSpec, osName, arch string) bool {
+	if len(installSpec.SupportedPlatforms) == 0 {
+		return true
+	}
+	for _, p := range installSpec.SupportedPlatforms {
+		if spec.PlatformOSString(p.OS) == osName && spec.PlatformArchString(p.Arch) == arch {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns the release asset of installSpec at tag for a platform
+func Resolve(installSpec *spec.InstallSpec, tag, osName, arch string, hash HashFunc) (*Asset, error) {
+	installSpec.SetDefaults()
+	generator := asset.NewFilenameGenerator(installSpec, tag)
+	resolution, err := generator.Resolve(osName, arch)
+	if err != nil {
+		return nil, err
+	}
+	a := &Asset{
+		OS:        osName,
+		Arch:      arch,
+		Filename:  resolution.Filename,
+		URL:       asset.DownloadURL(installSpec, "", tag, resolution.Filename),
+		Algorithm: "sha256",
+		Archive:   resolution.EXT != "" && resolution.EXT != ".exe",
+	}
+	if installSpec.Checksums != nil {
+		a.Algorithm = spec.AlgorithmString(installSpec.Checksums.Algorithm)
+	}
+	if a.Hash, err = hash(osName, arch, a.Filename); err != nil {
+		return nil, fmt.Errorf("failed to get checksum of %s: %w", a.Filename, err)
+	}
+	verifier := checksums.NewVerifier(installSpec, tag)
+	verifier.OS, verifier.Arch = osName, arch
+	if checksumFilename := verifier.ChecksumFilename(a.Filename); checksumFilename != "" {
+		a.ChecksumURL = asset.DownloadURL(installSpec, "", tag, checksumFilename)
+	}
+
+	var names, paths []string
+	for _, b := range generator.Binaries(osName, arch) {
+		name := spec.StringValue(b.Name)
+		if name == "" {
+			name = installSpec.GetName()
+		}
+		binaryPath, err := interpolate.Interpolate(interpolate.NewMapEnv(map[string]string{"ASSET_FILENAME": a.Filename}), spec.StringValue(b.Path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to interpolate binary path: %w", err)
+		}
+		if binaryPath == "" {
+			binaryPath = name
+		}
+		names, paths = append(names, name), append(paths, path.Clean(binaryPath))
+	}
+	// Templates may spell out the archive extension instead of using ${EXT}.
+	// The asset is still an executable when its binary is the asset itself.
+	if !a.Archive && archive.IsArchive(a.Filename) {
+		a.Archive = slices.ContainsFunc(paths, func(p string) bool { return p != a.Filename })
+	}
+	for i, name := range names {
+		binaryPath := a.Filename
+		if a.Archive {
+			binaryPath = paths[i]
+		}
+		if osName == "windows" {
+			name = withExe(name)
+			if a.Archive {
+				binaryPath = withExe(binaryPath)
+			}
+		}
+		a.Binaries = append(a.Binaries, Binary{Name: name, Path: binaryPath})
+	}
+	return a, nil
+}
+
+// withExe adds the .exe extension to a Windows executable name
+func withExe(name string) string {
+	if strings.HasSuffix(strings.ToLower(name), ".exe") {
+		return name
+	}
+	return name + ".exe"
+}
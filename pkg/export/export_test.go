@@ -0,0 +1,67 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+func TestResolve(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${VERSION}_${OS}_${ARCH}${EXT}").
+			WithDefaultExtension(".tar.gz").
+			WithBinary("tool", "dist/tool").
+			WithRules(spec.NewRule("windows", "").WithExt(".zip"))).
+		WithChecksums(spec.NewChecksums("checksums-${TAG}.txt"))
+	hash := func(osName, arch, filename string) (string, error) { return "hash-of-" + filename, nil }
+
+	a, err := Resolve(installSpec, "v1.0.0", "windows", "amd64", hash)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if a.Filename != "tool_1.0.0_windows_amd64.zip" || !a.Archive || a.Hash != "hash-of-tool_1.0.0_windows_amd64.zip" || a.Algorithm != "sha256" {
+		t.Errorf("Resolve() = %+v", a)
+	}
+	if want := "https://github.com/owner/tool/releases/download/v1.0.0/checksums-v1.0.0.txt"; a.ChecksumURL != want {
+		t.Errorf("Resolve() ChecksumURL = %q, want %q", a.ChecksumURL, want)
+	}
+	if len(a.Binaries) != 1 || a.Binaries[0] != (Binary{Name: "tool.exe", Path: "dist/tool.exe"}) {
+		t.Errorf("Resolve() binaries = %+v, want tool.exe at dist/tool.exe", a.Binaries)
+	}
+
+	a, err = Resolve(installSpec, "v1.0.0", "linux", "arm64", hash)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if len(a.Binaries) != 1 || a.Binaries[0] != (Binary{Name: "tool", Path: "dist/tool"}) {
+		t.Errorf("Resolve() binaries = %+v, want tool at dist/tool", a.Binaries)
+	}
+
+	installSpec.WithSupportedPlatforms("linux/amd64")
+	if Supported(installSpec, "windows", "amd64") || !Supported(installSpec, "linux", "amd64") {
+		t.Error("Supported() does not follow supported_platforms")
+	}
+}
+
+func TestResolveSpelledOutArchive(t *testing.T) {
+	hash := func(osName, arch, filename string) (string, error) { return "", nil }
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}.tar.gz").WithBinary("tool", "bin/tool"))
+	a, err := Resolve(installSpec, "v1.0.0", "linux", "amd64", hash)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if !a.Archive || len(a.Binaries) != 1 || a.Binaries[0].Path != "bin/tool" {
+		t.Errorf("Resolve() = %+v, want an archive with bin/tool", a)
+	}
+
+	// The default binary of a template without ${EXT} is the asset itself
+	installSpec = spec.NewInstallSpec("owner/tool").WithAsset(spec.NewAsset("${NAME}_${OS}_${ARCH}.tar.gz"))
+	a, err = Resolve(installSpec, "v1.0.0", "linux", "amd64", hash)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if a.Archive || len(a.Binaries) != 1 || a.Binaries[0].Path != "tool_linux_amd64.tar.gz" {
+		t.Errorf("Resolve() = %+v, want the asset as the executable", a)
+	}
+}
@@ -0,0 +1,65 @@
+package homebrew
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// CaskToken converts a formula name to a cask token, e.g. My_Tool to my-tool
+func CaskToken(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '@':
+			return r
+		}
+		return '-'
+	}, name)
+}
+
+// RenderCask renders the release as a Homebrew cask that links the binaries.
+// Casks stage archives as they are, so binaries use their path in the archive.
+func (f *Formula) RenderCask() ([]byte, error) {
+	tmpl, err := template.New("cask").Funcs(template.FuncMap{
+		"ruby":    rubyString,
+		"token":   CaskToken,
+		"brewOS":  func(os string) string { return map[string]string{"darwin": "macos", "linux": "linux"}[os] },
+		"brewCPU": func(arch string) string { return map[string]string{"amd64": "intel", "arm64": "arm"}[arch] },
+	}).Parse(caskTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cask template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, f); err != nil {
+		return nil, fmt.Errorf("failed to render cask: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+const caskTemplate = GeneratedHeader + `
+cask {{ token .Name | ruby }} do
+  version {{ ruby .Version }}
+
+  name {{ ruby .Name }}
+  desc {{ printf "Release binaries of %s" .Repo | ruby }}
+  homepage {{ ruby .Homepage }}
+{{- range .OSGroups }}
+
+  on_{{ brewOS (index . 0).OS }} do
+{{- range . }}
+    on_{{ brewCPU .Arch }} do
+      url {{ ruby .URL }}
+      sha256 {{ ruby .SHA256 }}
+{{- range .Binaries }}
+      binary {{ ruby (index .Paths 0) }}, target: {{ ruby .Name }}
+{{- end }}
+    end
+{{- end }}
+  end
+{{- end }}
+end
+`
@@ -0,0 +1,223 @@
+// Package homebrew renders Homebrew formulas and casks from InstallSpecs.
+package homebrew
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/binary-install/binstaller/pkg/asset"
+	"github.com/binary-install/binstaller/pkg/export"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// GeneratedHeader is the first line of every formula rendered by this package
+const GeneratedHeader = "# Code generated by binstaller. DO NOT EDIT."
+
+// Formula is a Homebrew formula for one InstallSpec release
+type Formula struct {
+	Name      string
+	ClassName string
+	Repo      string
+	Version   string
+	// Homepage is metadata.homepage, or else the repository URL
+	Homepage string
+	// License is the SPDX identifier of metadata.license, if any
+	License   string
+	Platforms []Platform
+}
+
+// Platform is the release asset and binaries of one Homebrew platform
+type Platform struct {
+	OS       string // darwin or linux
+	Arch     string // amd64 or arm64
+	URL      string
+	SHA256   string
+	Binaries []Binary
+}
+
+// Binary is an executable installed into the formula's bin directory
+type Binary struct {
+	Name string
+	// Paths are candidate locations relative to the staged release, in preference order
+	Paths []string
+}
+
+// homebrewPlatforms are the platforms Homebrew supports, in formula order
+var homebrewPlatforms = []struct{ os, arch string }{
+	{"darwin", "amd64"},
+	{"darwin", "arm64"},
+	{"linux", "amd64"},
+	{"linux", "arm64"},
+}
+
+// New builds the formula of installSpec at tag. Only the darwin and linux
+// amd64/arm64 platforms supported by the spec are included.
+func New(installSpec *spec.InstallSpec, tag string, hash export.HashFunc) (*Formula, error) {
+	installSpec.SetDefaults()
+	if installSpec.GetRepo() == "" {
+		return nil, fmt.Errorf("repo not specified in spec")
+	}
+	if installSpec.Checksums != nil {
+		if algo := spec.AlgorithmString(installSpec.Checksums.Algorithm); algo != "sha256" {
+			return nil, fmt.Errorf("homebrew formulas require sha256 checksums, got %s", algo)
+		}
+	}
+
+	version := strings.TrimPrefix(tag, "v")
+	f := &Formula{
+		Name:      installSpec.GetName(),
+		ClassName: ClassName(installSpec.GetName()),
+		Repo:      installSpec.GetRepo(),
+		Version:   version,
+		Homepage:  installSpec.GetMetadata().GetHomepage(),
+		License:   installSpec.GetMetadata().GetLicense(),
+	}
+	if f.Homepage == "" {
+		f.Homepage = asset.BaseURL(installSpec) + "/" + f.Repo
+	}
+
+	rosetta2 := installSpec.GetAsset().GetRosetta2()
+	for _, p := range homebrewPlatforms {
+		assetArch := p.arch
+		if !export.Supported(installSpec, p.os, p.arch) {
+			// Apple Silicon can run amd64 releases through Rosetta 2
+			if p.os != "darwin" || p.arch != "arm64" || !rosetta2 || !export.Supported(installSpec, "darwin", "amd64") {
+				continue
+			}
+			assetArch = "amd64"
+		}
+
+		resolved, err := export.Resolve(installSpec, tag, p.os, assetArch, hash)
+		if err != nil {
+			return nil, err
+		}
+		f.Platforms = append(f.Platforms, Platform{
+			OS:       p.os,
+			Arch:     p.arch,
+			URL:      resolved.URL,
+			SHA256:   resolved.Hash,
+			Binaries: formulaBinaries(resolved.Binaries),
+		})
+	}
+	if len(f.Platforms) == 0 {
+		return nil, fmt.Errorf("%s supports no macOS or Linux amd64/arm64 platform", f.Repo)
+	}
+	return f, nil
+}
+
+// formulaBinaries converts the binaries of a release asset to paths relative to
+// the staged release. Homebrew stages archives with a single top-level directory
+// from inside that directory, so nested paths also try the path without their
+// first component.
+func formulaBinaries(binaries []export.Binary) []Binary {
+	var result []Binary
+	for _, b := range binaries {
+		paths := []string{b.Path}
+		if _, rest, ok := strings.Cut(b.Path, "/"); ok {
+			paths = append(paths, rest)
+		}
+		result = append(result, Binary{Name: b.Name, Paths: paths})
+	}
+	return result
+}
+
+// ClassName converts a formula name to its Ruby class name, e.g. golangci-lint to GolangciLint
+func ClassName(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range strings.ReplaceAll(name, "@", "AT") {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Render renders the formula as Ruby source
+func (f *Formula) Render() ([]byte, error) {
+	tmpl, err := template.New("formula").Funcs(template.FuncMap{
+		"ruby":     rubyString,
+		"brewOS":   func(os string) string { return map[string]string{"darwin": "macos", "linux": "linux"}[os] },
+		"cpuCheck": cpuCheck,
+	}).Parse(formulaTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse formula template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, f); err != nil {
+		return nil, fmt.Errorf("failed to render formula: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// OSGroups returns the formula's platforms grouped by OS, in formula order
+func (f *Formula) OSGroups() [][]Platform {
+	var groups [][]Platform
+	for _, p := range f.Platforms {
+		if n := len(groups); n > 0 && groups[n-1][0].OS == p.OS {
+			groups[n-1] = append(groups[n-1], p)
+			continue
+		}
+		groups = append(groups, []Platform{p})
+	}
+	return groups
+}
+
+// cpuCheck returns the Ruby condition selecting an architecture
+func cpuCheck(osName, arch string) string {
+	if arch == "arm64" {
+		if osName == "linux" {
+			return "Hardware::CPU.arm? && Hardware::CPU.is_64_bit?"
+		}
+		return "Hardware::CPU.arm?"
+	}
+	return "Hardware::CPU.intel?"
+}
+
+// rubyString quotes s as a Ruby double-quoted string literal
+func rubyString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `#`, `\#`)
+	return `"` + r.Replace(s) + `"`
+}
+
+const formulaTemplate = GeneratedHeader + `
+class {{ .ClassName }} < Formula
+  desc {{ printf "Release binaries of %s" .Repo | ruby }}
+  homepage {{ ruby .Homepage }}
+  version {{ ruby .Version }}
+{{- if .License }}
+  license {{ ruby .License }}
+{{- end }}
+{{- range .OSGroups }}
+
+  on_{{ brewOS (index . 0).OS }} do
+{{- range . }}
+    if {{ cpuCheck .OS .Arch }}
+      url {{ ruby .URL }}
+      sha256 {{ ruby .SHA256 }}
+
+      def install
+{{- range .Binaries }}
+{{- if eq (len .Paths) 1 }}
+        bin.install {{ ruby (index .Paths 0) }} => {{ ruby .Name }}
+{{- else }}
+        bin.install (File.exist?({{ ruby (index .Paths 0) }}) ? {{ ruby (index .Paths 0) }} : {{ ruby (index .Paths 1) }}) => {{ ruby .Name }}
+{{- end }}
+{{- end }}
+      end
+    end
+{{- end }}
+  end
+{{- end }}
+end
+`
@@ -0,0 +1,178 @@
+package homebrew
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+func TestClassName(t *testing.T) {
+	tests := map[string]string{
+		"fzf":           "Fzf",
+		"golangci-lint": "GolangciLint",
+		"gh_setup":      "GhSetup",
+		"node@20":       "NodeAT20",
+		"7zip":          "7zip",
+	}
+	for name, want := range tests {
+		if got := ClassName(name); got != want {
+			t.Errorf("ClassName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func fakeHash(osName, arch, filename string) (string, error) {
+	return fmt.Sprintf("%s-%s", osName, arch), nil
+}
+
+func TestRender(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/my-tool").
+		WithAsset(spec.NewAsset("${NAME}_${VERSION}_${OS}_${ARCH}${EXT}").
+			WithDefaultExtension(".tar.gz").
+			WithBinary("my-tool", "bin/my-tool").
+			WithRules(spec.NewRule("", "amd64").WithArch("x86_64"))).
+		WithSupportedPlatforms("darwin/amd64", "darwin/arm64", "linux/amd64", "windows/amd64")
+
+	f, err := New(installSpec, "v1.2.3", fakeHash)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	got, err := f.Render()
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	want := GeneratedHeader + `
+class MyTool < Formula
+  desc "Release binaries of owner/my-tool"
+  homepage "https://github.com/owner/my-tool"
+  version "1.2.3"
+
+  on_macos do
+    if Hardware::CPU.intel?
+      url "https://github.com/owner/my-tool/releases/download/v1.2.3/my-tool_1.2.3_darwin_x86_64.tar.gz"
+      sha256 "darwin-amd64"
+
+      def install
+        bin.install (File.exist?("bin/my-tool") ? "bin/my-tool" : "my-tool") => "my-tool"
+      end
+    end
+    if Hardware::CPU.arm?
+      url "https://github.com/owner/my-tool/releases/download/v1.2.3/my-tool_1.2.3_darwin_arm64.tar.gz"
+      sha256 "darwin-arm64"
+
+      def install
+        bin.install (File.exist?("bin/my-tool") ? "bin/my-tool" : "my-tool") => "my-tool"
+      end
+    end
+  end
+
+  on_linux do
+    if Hardware::CPU.intel?
+      url "https://github.com/owner/my-tool/releases/download/v1.2.3/my-tool_1.2.3_linux_x86_64.tar.gz"
+      sha256 "linux-amd64"
+
+      def install
+        bin.install (File.exist?("bin/my-tool") ? "bin/my-tool" : "my-tool") => "my-tool"
+      end
+    end
+  end
+end
+`
+	if string(got) != want {
+		t.Errorf("Render() mismatch\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestNewRosetta2AndRawBinary(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}-${OS}-${ARCH}").WithRosetta2(true)).
+		WithSupportedPlatforms("darwin/amd64")
+
+	f, err := New(installSpec, "v2.0.0", fakeHash)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if len(f.Platforms) != 2 {
+		t.Fatalf("New() platforms = %+v, want darwin amd64 and arm64 via Rosetta 2", f.Platforms)
+	}
+	arm := f.Platforms[1]
+	if arm.Arch != "arm64" || !strings.HasSuffix(arm.URL, "/tool-darwin-amd64") || arm.SHA256 != "darwin-amd64" {
+		t.Errorf("Rosetta 2 platform = %+v", arm)
+	}
+	if got := arm.Binaries[0].Paths; len(got) != 1 || got[0] != "tool-darwin-amd64" {
+		t.Errorf("raw binary paths = %v", got)
+	}
+
+	installSpec.Checksums = spec.NewChecksums("sums.txt").WithAlgorithm(spec.Sha512)
+	if _, err := New(installSpec, "v2.0.0", fakeHash); err == nil {
+		t.Error("New() expected error for non-sha256 checksums")
+	}
+}
+
+func TestRubyString(t *testing.T) {
+	if got, want := rubyString(`a"b\c#{d}`), `"a\"b\\c\#{d}"`; got != want {
+		t.Errorf("rubyString() = %s, want %s", got, want)
+	}
+}
+
+func TestRenderCask(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/My_Tool").
+		WithAsset(spec.NewAsset("${NAME}_${VERSION}_${OS}_${ARCH}.tar.gz").
+			WithBinary("my-tool", "bin/my-tool")).
+		WithSupportedPlatforms("darwin/arm64", "linux/amd64")
+	installSpec.Metadata = &spec.Metadata{Homepage: spec.StringPtr("https://example.com/my-tool")}
+
+	f, err := New(installSpec, "v1.2.3", fakeHash)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	got, err := f.RenderCask()
+	if err != nil {
+		t.Fatalf("RenderCask() error = %v", err)
+	}
+
+	want := GeneratedHeader + `
+cask "my-tool" do
+  version "1.2.3"
+
+  name "My_Tool"
+  desc "Release binaries of owner/My_Tool"
+  homepage "https://example.com/my-tool"
+
+  on_macos do
+    on_arm do
+      url "https://github.com/owner/My_Tool/releases/download/v1.2.3/My_Tool_1.2.3_darwin_arm64.tar.gz"
+      sha256 "darwin-arm64"
+      binary "bin/my-tool", target: "my-tool"
+    end
+  end
+
+  on_linux do
+    on_intel do
+      url "https://github.com/owner/My_Tool/releases/download/v1.2.3/My_Tool_1.2.3_linux_amd64.tar.gz"
+      sha256 "linux-amd64"
+      binary "bin/my-tool", target: "my-tool"
+    end
+  end
+end
+`
+	if string(got) != want {
+		t.Errorf("RenderCask() mismatch\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Formulas also carry the license and homepage of the metadata
+	installSpec.Metadata.License = spec.StringPtr("MIT")
+	if f, err = New(installSpec, "v1.2.3", fakeHash); err != nil {
+		t.Fatal(err)
+	}
+	formula, err := f.Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(formula), "  homepage \"https://example.com/my-tool\"\n  version \"1.2.3\"\n  license \"MIT\"\n") {
+		t.Errorf("Render() lacks the metadata:\n%s", formula)
+	}
+}
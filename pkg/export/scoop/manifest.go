@@ -0,0 +1,198 @@
+// Package scoop renders Scoop app manifests from InstallSpecs.
+package scoop
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/binary-install/binstaller/pkg/asset"
+	"github.com/binary-install/binstaller/pkg/export"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// GeneratedComment is the comment of every manifest rendered by this package
+const GeneratedComment = "Code generated by binstaller. DO NOT EDIT."
+
+// scoopArches maps the Windows architectures Scoop supports to its names for them
+var scoopArches = []struct{ arch, name string }{
+	{"amd64", "64bit"},
+	{"386", "32bit"},
+	{"arm64", "arm64"},
+}
+
+// Manifest is a Scoop app manifest for one InstallSpec release
+type Manifest struct {
+	Comment      string                   `json:"##"`
+	Version      string                   `json:"version"`
+	Description  string                   `json:"description"`
+	Homepage     string                   `json:"homepage"`
+	License      string                   `json:"license,omitempty"`
+	Architecture map[string]*Architecture `json:"architecture"`
+	// Bin is the executables shared by every architecture
+	Bin        any         `json:"bin,omitempty"`
+	Checkver   *Checkver   `json:"checkver,omitempty"`
+	Autoupdate *Autoupdate `json:"autoupdate,omitempty"`
+}
+
+// Architecture is the download of one architecture
+type Architecture struct {
+	URL  string `json:"url"`
+	Hash string `json:"hash,omitempty"`
+	// Bin is the executables of the architecture when they differ between architectures
+	Bin any `json:"bin,omitempty"`
+}
+
+// Checkver finds the latest version of the app
+type Checkver struct {
+	GitHub string `json:"github"`
+}
+
+// Autoupdate is how Scoop derives the manifest of a newer version
+type Autoupdate struct {
+	Architecture map[string]*AutoupdateURL `json:"architecture"`
+	Hash         *AutoupdateURL            `json:"hash,omitempty"`
+}
+
+// AutoupdateURL is a URL with the version replaced by $version
+type AutoupdateURL struct {
+	URL string `json:"url"`
+}
+
+// New builds the Scoop manifest of installSpec at tag from its windows/amd64,
+// windows/386 and windows/arm64 assets
+func New(installSpec *spec.InstallSpec, tag string, hash export.HashFunc) (*Manifest, error) {
+	installSpec.SetDefaults()
+	if installSpec.GetRepo() == "" {
+		return nil, fmt.Errorf("repo not specified in spec")
+	}
+
+	version := strings.TrimPrefix(tag, "v")
+	m := &Manifest{
+		Comment:      GeneratedComment,
+		Version:      version,
+		Description:  "Release binaries of " + installSpec.GetRepo(),
+		Homepage:     installSpec.GetMetadata().GetHomepage(),
+		License:      installSpec.GetMetadata().GetLicense(),
+		Architecture: make(map[string]*Architecture),
+	}
+	repoURL := asset.BaseURL(installSpec) + "/" + installSpec.GetRepo()
+	if m.Homepage == "" {
+		m.Homepage = repoURL
+	}
+
+	autoupdate := &Autoupdate{Architecture: make(map[string]*AutoupdateURL)}
+	var bins []any
+	sharedBin := true
+	for _, a := range scoopArches {
+		if !export.Supported(installSpec, "windows", a.arch) {
+			continue
+		}
+		resolved, err := export.Resolve(installSpec, tag, "windows", a.arch, hash)
+		if err != nil {
+			return nil, err
+		}
+		hashValue, err := scoopHash(resolved.Algorithm, resolved.Hash)
+		if err != nil {
+			return nil, err
+		}
+		url := resolved.URL
+		if !resolved.Archive && len(resolved.Binaries) > 0 {
+			// Scoop renames a raw executable to the fragment of its URL
+			url += "#/" + resolved.Binaries[0].Name
+		}
+		bin := scoopBin(resolved)
+		if len(bins) > 0 && !jsonEqual(bins[0], bin) {
+			sharedBin = false
+		}
+		bins = append(bins, bin)
+
+		m.Architecture[a.name] = &Architecture{URL: url, Hash: hashValue, Bin: bin}
+		autoupdate.Architecture[a.name] = &AutoupdateURL{URL: versionPlaceholder(url, version)}
+		if resolved.ChecksumURL != "" {
+			autoupdate.Hash = &AutoupdateURL{URL: versionPlaceholder(resolved.ChecksumURL, version)}
+		}
+	}
+	if len(m.Architecture) == 0 {
+		return nil, fmt.Errorf("%s supports no windows/amd64, windows/386 or windows/arm64 platform", installSpec.GetRepo())
+	}
+	if sharedBin {
+		m.Bin = bins[0]
+		for _, arch := range m.Architecture {
+			arch.Bin = nil
+		}
+	}
+
+	// Scoop checks for new versions only on github.com
+	if installSpec.GetSource() != spec.Gitlab && strings.HasPrefix(repoURL, "https://github.com/") {
+		m.Checkver = &Checkver{GitHub: repoURL}
+		m.Autoupdate = autoupdate
+	}
+	return m, nil
+}
+
+// scoopBin returns the bin entry of an asset: the path of a single binary, or a
+// list of paths, with [path, alias] pairs for binaries installed under another name
+func scoopBin(a *export.Asset) any {
+	var bins []any
+	for _, b := range a.Binaries {
+		binPath := strings.ReplaceAll(b.Path, "/", `\`)
+		switch {
+		case !a.Archive:
+			// The URL fragment renames a raw executable to the binary name
+			bins = append(bins, b.Name)
+		case path.Base(b.Path) == b.Name:
+			bins = append(bins, binPath)
+		default:
+			bins = append(bins, []string{binPath, strings.TrimSuffix(b.Name, ".exe")})
+		}
+	}
+	if len(bins) == 1 {
+		if s, ok := bins[0].(string); ok {
+			return s
+		}
+	}
+	return bins
+}
+
+// scoopHash returns a hash in Scoop's notation, prefixed with its algorithm
+// unless it is sha256
+func scoopHash(algorithm, hash string) (string, error) {
+	switch algorithm {
+	case "sha256":
+		return hash, nil
+	case "sha1", "sha512", "md5":
+		return algorithm + ":" + hash, nil
+	default:
+		return "", fmt.Errorf("scoop manifests do not support %s checksums", algorithm)
+	}
+}
+
+// versionPlaceholder replaces the version in a URL by Scoop's $version
+func versionPlaceholder(url, version string) string {
+	if version == "" {
+		return url
+	}
+	return strings.ReplaceAll(url, version, "$version")
+}
+
+// jsonEqual reports whether a and b have the same JSON encoding
+func jsonEqual(a, b any) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+// Render renders the manifest as JSON
+func (m *Manifest) Render() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "    ")
+	if err := enc.Encode(m); err != nil {
+		return nil, fmt.Errorf("failed to render scoop manifest: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,103 @@
+package scoop
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+func fakeHash(osName, arch, filename string) (string, error) {
+	return fmt.Sprintf("%s-%s", osName, arch), nil
+}
+
+func TestRender(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/my-tool").
+		WithAsset(spec.NewAsset("${NAME}_${VERSION}_${OS}_${ARCH}${EXT}").
+			WithDefaultExtension(".tar.gz").
+			WithBinary("my-tool", "bin/my-tool").
+			WithRules(
+				spec.NewRule("windows", "").WithExt(".zip"),
+				spec.NewRule("", "amd64").WithArch("x86_64"),
+			)).
+		WithChecksums(spec.NewChecksums("${NAME}_${VERSION}_checksums.txt")).
+		WithSupportedPlatforms("linux/amd64", "windows/amd64", "windows/arm64")
+
+	m, err := New(installSpec, "v1.2.3", fakeHash)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	got, err := m.Render()
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	want := `{
+    "##": "` + GeneratedComment + `",
+    "version": "1.2.3",
+    "description": "Release binaries of owner/my-tool",
+    "homepage": "https://github.com/owner/my-tool",
+    "architecture": {
+        "64bit": {
+            "url": "https://github.com/owner/my-tool/releases/download/v1.2.3/my-tool_1.2.3_windows_x86_64.zip",
+            "hash": "windows-amd64"
+        },
+        "arm64": {
+            "url": "https://github.com/owner/my-tool/releases/download/v1.2.3/my-tool_1.2.3_windows_arm64.zip",
+            "hash": "windows-arm64"
+        }
+    },
+    "bin": "bin\\my-tool.exe",
+    "checkver": {
+        "github": "https://github.com/owner/my-tool"
+    },
+    "autoupdate": {
+        "architecture": {
+            "64bit": {
+                "url": "https://github.com/owner/my-tool/releases/download/v$version/my-tool_$version_windows_x86_64.zip"
+            },
+            "arm64": {
+                "url": "https://github.com/owner/my-tool/releases/download/v$version/my-tool_$version_windows_arm64.zip"
+            }
+        },
+        "hash": {
+            "url": "https://github.com/owner/my-tool/releases/download/v$version/my-tool_$version_checksums.txt"
+        }
+    }
+}
+`
+	if string(got) != want {
+		t.Errorf("Render() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestNewRawExecutable(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}-${OS}-${ARCH}${EXT}").WithBinary("tool", "${ASSET_FILENAME}")).
+		WithSupportedPlatforms("windows/386", "windows/amd64")
+	installSpec.Asset.WithRules(spec.NewRule("windows", "").WithExt(".exe"))
+	installSpec.Metadata = &spec.Metadata{License: spec.StringPtr("MIT")}
+	installSpec.Checksums = &spec.Checksums{Algorithm: spec.AlgorithmPtr("sha512")}
+
+	m, err := New(installSpec, "v2.0.0", fakeHash)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if m.License != "MIT" || m.Bin != "tool.exe" {
+		t.Errorf("New() license = %q, bin = %v, want MIT and tool.exe", m.License, m.Bin)
+	}
+	arch := m.Architecture["32bit"]
+	if arch == nil || arch.URL != "https://github.com/owner/tool/releases/download/v2.0.0/tool-windows-386.exe#/tool.exe" || arch.Hash != "sha512:windows-386" {
+		t.Errorf("New() 32bit = %+v", arch)
+	}
+	if m.Autoupdate == nil || m.Autoupdate.Hash != nil {
+		t.Errorf("New() autoupdate = %+v, want one without a checksum file", m.Autoupdate)
+	}
+
+	installSpec.SupportedPlatforms = nil
+	installSpec.WithSupportedPlatforms("linux/amd64")
+	if _, err := New(installSpec, "v2.0.0", fakeHash); err == nil || !strings.Contains(err.Error(), "supports no windows") {
+		t.Errorf("New() without windows assets = %v, want error", err)
+	}
+}
@@ -0,0 +1,69 @@
+// Package filelock provides exclusive advisory locks on files, so separate
+// binst processes can serialize their access to shared state.
+package filelock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ErrLocked is returned by TryAcquire when another process holds the lock
+var ErrLocked = errors.New("file is locked by another process")
+
+// pollInterval is how often Acquire retries a held lock
+var pollInterval = 100 * time.Millisecond
+
+// Lock is an exclusive lock held on a file
+type Lock struct {
+	f *os.File
+}
+
+// TryAcquire takes the exclusive lock on path, creating the file if needed. It
+// returns ErrLocked without waiting when the lock is held elsewhere.
+func TryAcquire(path string) (*Lock, error) {
+	f, err := os.OpenFile(path, os.O_RDONLY|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	locked, err := tryLock(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+	if !locked {
+		f.Close()
+		return nil, ErrLocked
+	}
+	return &Lock{f: f}, nil
+}
+
+// Acquire takes the exclusive lock on path, waiting until it is released
+// elsewhere or ctx is done
+func Acquire(ctx context.Context, path string) (*Lock, error) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		l, err := TryAcquire(path)
+		if !errors.Is(err, ErrLocked) {
+			return l, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for the lock on %s: %w", path, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// Release releases the lock. The lock file is left in place so that waiting
+// processes keep locking the same file.
+func (l *Lock) Release() error {
+	if err := unlock(l.f); err != nil {
+		l.f.Close()
+		return err
+	}
+	return l.f.Close()
+}
@@ -0,0 +1,48 @@
+package filelock
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tool.lock")
+	l, err := TryAcquire(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := TryAcquire(path); !errors.Is(err, ErrLocked) {
+		t.Fatalf("TryAcquire() of a held lock = %v, want ErrLocked", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := Acquire(ctx, path); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Acquire() of a held lock = %v, want context.DeadlineExceeded", err)
+	}
+
+	// A waiting Acquire gets the lock once it is released
+	done := make(chan error, 1)
+	go func() {
+		l2, err := Acquire(context.Background(), path)
+		if err == nil {
+			err = l2.Release()
+		}
+		done <- err
+	}()
+	time.Sleep(2 * pollInterval)
+	if err := l.Release(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Acquire() did not get the released lock")
+	}
+}
@@ -0,0 +1,227 @@
+package httpclient
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/cache"
+)
+
+// EnvNoCache disables the response cache when set to 1, like --no-cache
+const EnvNoCache = "BINSTALLER_NO_HTTP_CACHE"
+
+// maxCachedBody is the size of the largest response body that is cached
+const maxCachedBody = 4 << 20
+
+// cacheDisabled is set by DisableCache
+var cacheDisabled atomic.Bool
+
+// DisableCache stops clients from reading and writing the response cache, for
+// the --no-cache flag
+func DisableCache() {
+	cacheDisabled.Store(true)
+}
+
+// cacheEnabled reports whether the response cache is used
+func cacheEnabled() bool {
+	return !cacheDisabled.Load() && os.Getenv(EnvNoCache) != "1"
+}
+
+// responseCacheDir returns the directory of the response cache, or "" when the
+// cache directory cannot be determined
+func responseCacheDir() string {
+	dir, err := cache.Dir()
+	if err != nil {
+		log.Debugf("HTTP response cache disabled: %v", err)
+		return ""
+	}
+	return filepath.Join(dir, cache.ResponsesDir)
+}
+
+// cachedResponse is a response stored in the cache
+type cachedResponse struct {
+	URL          string      `json:"url"`
+	ETag         string      `json:"etag,omitempty"`
+	LastModified string      `json:"last_modified,omitempty"`
+	Header       http.Header `json:"header"`
+	Body         []byte      `json:"body"`
+}
+
+// cacheTransport is a RoundTripper caching JSON API responses on disk with
+// their ETag and Last-Modified validators. Repeated requests are sent as
+// conditional requests; GitHub does not count a 304 Not Modified answer
+// against the rate limit, and the cached body is returned in its place.
+type cacheTransport struct {
+	Base http.RoundTripper
+	// Dir holds one file per cached response
+	Dir string
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.Dir == "" || !cacheEnabled() || req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return t.Base.RoundTrip(req)
+	}
+	path := filepath.Join(t.Dir, cacheKey(req)+".json")
+	cached := readCachedResponse(path, req.URL.String())
+
+	if cached != nil && req.Header.Get("If-None-Match") == "" && req.Header.Get("If-Modified-Since") == "" {
+		conditional := req.Clone(req.Context())
+		if cached.ETag != "" {
+			conditional.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			conditional.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+		req = conditional
+	}
+
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		log.Debugf("Using cached response of %s", req.URL.Redacted())
+		return cached.response(req, resp), nil
+	}
+	if !cacheable(resp) {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCachedBody+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if len(body) > maxCachedBody {
+		// Too large to cache: hand the body back with the part read so far
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	entry := &cachedResponse{
+		URL:          req.URL.String(),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Header:       resp.Header.Clone(),
+		Body:         body,
+	}
+	if err := writeCachedResponse(path, entry); err != nil {
+		log.Debugf("Failed to cache the response of %s: %v", req.URL.Redacted(), err)
+	}
+	return resp, nil
+}
+
+// cacheKey identifies the cached response of a request. Responses depend on
+// the credentials and the media type the request asks for, so the
+// Authorization and Accept headers are part of the key.
+func cacheKey(req *http.Request) string {
+	h := sha256.New()
+	for _, part := range []string{req.URL.String(), req.Header.Get("Accept"), req.Header.Get("Authorization"), req.Header.Get("PRIVATE-TOKEN")} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// cacheable reports whether resp is a successful JSON response with a
+// validator that allows revalidating it later
+func cacheable(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+	if resp.Header.Get("ETag") == "" && resp.Header.Get("Last-Modified") == "" {
+		return false
+	}
+	if strings.Contains(resp.Header.Get("Cache-Control"), "no-store") {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
+
+// response returns the cached response as the answer to req. The headers of
+// notModified, such as the current rate limit, replace the cached ones.
+func (c *cachedResponse) response(req *http.Request, notModified *http.Response) *http.Response {
+	io.Copy(io.Discard, notModified.Body)
+	notModified.Body.Close()
+
+	header := c.Header.Clone()
+	for name, values := range notModified.Header {
+		switch name {
+		case "Content-Length", "Content-Type", "Content-Encoding", "Transfer-Encoding":
+			// These describe the empty body of the 304 response
+		default:
+			header[name] = values
+		}
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         notModified.Proto,
+		ProtoMajor:    notModified.ProtoMajor,
+		ProtoMinor:    notModified.ProtoMinor,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(c.Body)),
+		ContentLength: int64(len(c.Body)),
+		Request:       req,
+	}
+}
+
+// readCachedResponse reads the cached response of url at path, or returns nil
+// when there is none
+func readCachedResponse(path, url string) *cachedResponse {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var c cachedResponse
+	if err := json.Unmarshal(data, &c); err != nil || c.URL != url {
+		log.Debugf("Ignoring invalid cached response %s", path)
+		return nil
+	}
+	return &c
+}
+
+// writeCachedResponse writes c to path atomically, readable only by the user
+// since responses to authenticated requests may be private
+func writeCachedResponse(path string, c *cachedResponse) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".response-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to store cached response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package httpclient
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// etagServer serves a JSON document with an ETag, answering matching
+// conditional requests with 304 Not Modified
+type etagServer struct {
+	*httptest.Server
+	body        string
+	contentType string
+	requests    int
+	notModified int
+}
+
+func newETagServer(t *testing.T, contentType string) *etagServer {
+	s := &etagServer{body: `{"tag_name":"v1.0.0"}`, contentType: contentType}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests++
+		etag := `"` + s.body + `"`
+		w.Header().Set("X-RateLimit-Remaining", "59")
+		if r.Header.Get("If-None-Match") == etag {
+			s.notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Content-Type", s.contentType)
+		w.Header().Set("X-RateLimit-Remaining", "58")
+		io.WriteString(w, s.body)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// get sends a GET request with authorization through transport and returns the
+// status, body and X-RateLimit-Remaining header of the response
+func get(t *testing.T, transport http.RoundTripper, url, authorization string) (int, string, string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip() error = %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(body), resp.Header.Get("X-RateLimit-Remaining")
+}
+
+func TestCacheTransport(t *testing.T) {
+	t.Setenv(EnvNoCache, "")
+	server := newETagServer(t, "application/json; charset=utf-8")
+	dir := t.TempDir()
+	transport := &cacheTransport{Base: http.DefaultTransport, Dir: dir}
+
+	status, body, remaining := get(t, transport, server.URL+"/repos/owner/tool/releases/latest", "")
+	if status != http.StatusOK || body != server.body || remaining != "58" {
+		t.Fatalf("first response = %d %q (remaining %s), want 200 %q (remaining 58)", status, body, remaining, server.body)
+	}
+
+	status, body, remaining = get(t, transport, server.URL+"/repos/owner/tool/releases/latest", "")
+	if status != http.StatusOK || body != server.body {
+		t.Errorf("cached response = %d %q, want 200 %q", status, body, server.body)
+	}
+	if remaining != "59" {
+		t.Errorf("cached response X-RateLimit-Remaining = %s, want 59 from the 304 response", remaining)
+	}
+	if server.notModified != 1 {
+		t.Errorf("conditional requests answered with 304 = %d, want 1", server.notModified)
+	}
+
+	// Responses are cached per credentials
+	get(t, transport, server.URL+"/repos/owner/tool/releases/latest", "Bearer token")
+	if server.notModified != 1 {
+		t.Error("a request with other credentials was sent as a conditional request")
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("cache has %d entries, want 2", len(entries))
+	}
+	for _, e := range entries {
+		if info, err := e.Info(); err != nil || info.Mode().Perm() != 0600 {
+			t.Errorf("cache entry %s mode = %v, want 0600", e.Name(), info.Mode().Perm())
+		}
+	}
+
+	// A changed document replaces the cached one
+	server.body = `{"tag_name":"v2.0.0"}`
+	if _, body, _ := get(t, transport, server.URL+"/repos/owner/tool/releases/latest", ""); body != server.body {
+		t.Errorf("changed response body = %q, want %q", body, server.body)
+	}
+	if _, body, _ := get(t, transport, server.URL+"/repos/owner/tool/releases/latest", ""); body != server.body {
+		t.Errorf("cached changed response body = %q, want %q", body, server.body)
+	}
+	if server.notModified != 2 {
+		t.Errorf("conditional requests answered with 304 = %d, want 2", server.notModified)
+	}
+}
+
+func TestCacheTransportSkips(t *testing.T) {
+	t.Run("not JSON", func(t *testing.T) {
+		t.Setenv(EnvNoCache, "")
+		server := newETagServer(t, "application/octet-stream")
+		dir := t.TempDir()
+		transport := &cacheTransport{Base: http.DefaultTransport, Dir: dir}
+		get(t, transport, server.URL+"/tool.tar.gz", "")
+		get(t, transport, server.URL+"/tool.tar.gz", "")
+		if server.notModified != 0 {
+			t.Error("a release asset was revalidated from the cache")
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+			t.Errorf("cache has %d entries, want none", len(entries))
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Setenv(EnvNoCache, "1")
+		server := newETagServer(t, "application/json")
+		dir := t.TempDir()
+		transport := &cacheTransport{Base: http.DefaultTransport, Dir: dir}
+		get(t, transport, server.URL+"/repos/owner/tool/releases/latest", "")
+		get(t, transport, server.URL+"/repos/owner/tool/releases/latest", "")
+		if server.notModified != 0 {
+			t.Errorf("%s=1 still sent conditional requests", EnvNoCache)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+			t.Errorf("cache has %d entries, want none", len(entries))
+		}
+	})
+
+	t.Run("corrupt entry", func(t *testing.T) {
+		t.Setenv(EnvNoCache, "")
+		server := newETagServer(t, "application/json")
+		dir := t.TempDir()
+		transport := &cacheTransport{Base: http.DefaultTransport, Dir: dir}
+		get(t, transport, server.URL+"/repos/owner/tool/releases/latest", "")
+		entries, _ := os.ReadDir(dir)
+		for _, e := range entries {
+			os.WriteFile(filepath.Join(dir, e.Name()), []byte("{"), 0600)
+		}
+		status, body, _ := get(t, transport, server.URL+"/repos/owner/tool/releases/latest", "")
+		if status != http.StatusOK || !strings.Contains(body, "v1.0.0") {
+			t.Errorf("response with a corrupt cache entry = %d %q", status, body)
+		}
+		if server.notModified != 0 {
+			t.Error("a corrupt cache entry was revalidated")
+		}
+	})
+}
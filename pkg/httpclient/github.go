@@ -0,0 +1,100 @@
+package httpclient
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+)
+
+const (
+	// DefaultGitHubServerURL is the web URL of github.com
+	DefaultGitHubServerURL = "https://github.com"
+	// DefaultGitHubAPIURL is the API URL of github.com
+	DefaultGitHubAPIURL = "https://api.github.com"
+	// DefaultGitHubRawURL serves the raw files of github.com repositories
+	DefaultGitHubRawURL = "https://raw.githubusercontent.com"
+)
+
+// gitHubHostsKey is the context key of the GitHub Enterprise Server hosts of
+// a request
+type gitHubHostsKey struct{}
+
+// WithGitHubHost returns a copy of ctx whose requests to host, a GitHub
+// Enterprise Server instance, carry the token of that instance (see
+// enterpriseToken). github.com and the host of GITHUB_SERVER_URL are always
+// known.
+func WithGitHubHost(ctx context.Context, host string) context.Context {
+	hosts, _ := ctx.Value(gitHubHostsKey{}).([]string)
+	return context.WithValue(ctx, gitHubHostsKey{}, append(slices.Clip(hosts), strings.ToLower(host)))
+}
+
+// isGitHubHost reports whether host is a GitHub Enterprise Server instance
+// added to ctx or the host of GITHUB_SERVER_URL or GITHUB_API_URL
+func isGitHubHost(ctx context.Context, host string) bool {
+	host = strings.ToLower(host)
+	if hosts, _ := ctx.Value(gitHubHostsKey{}).([]string); slices.Contains(hosts, host) {
+		return true
+	}
+	return isActionsHost(host)
+}
+
+// isActionsHost reports whether host is the host of GITHUB_SERVER_URL or
+// GITHUB_API_URL, the instance GitHub Actions runs on
+func isActionsHost(host string) bool {
+	for _, env := range []string{"GITHUB_SERVER_URL", "GITHUB_API_URL"} {
+		if u, err := url.Parse(os.Getenv(env)); err == nil && u.Host != "" && strings.EqualFold(u.Host, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// GitHubServerURL returns the web URL of the GitHub instance binstaller talks
+// to by default: GITHUB_SERVER_URL, as GitHub Actions sets it on GitHub
+// Enterprise Server, or https://github.com
+func GitHubServerURL() string {
+	if serverURL := strings.TrimSuffix(os.Getenv("GITHUB_SERVER_URL"), "/"); serverURL != "" {
+		return serverURL
+	}
+	return DefaultGitHubServerURL
+}
+
+// GitHubAPIURL returns the API URL of the GitHub instance at serverURL
+// (GitHubServerURL when empty): GITHUB_API_URL for the GITHUB_SERVER_URL
+// instance, https://api.github.com for github.com, and serverURL/api/v3 for
+// GitHub Enterprise Server
+func GitHubAPIURL(serverURL string) string {
+	serverURL = strings.TrimSuffix(serverURL, "/")
+	if serverURL == "" {
+		serverURL = GitHubServerURL()
+	}
+	if apiURL := strings.TrimSuffix(os.Getenv("GITHUB_API_URL"), "/"); apiURL != "" && strings.EqualFold(serverURL, GitHubServerURL()) {
+		return apiURL
+	}
+	if isDotCom(serverURL) {
+		return DefaultGitHubAPIURL
+	}
+	return serverURL + "/api/v3"
+}
+
+// GitHubRawURL returns the URL serving raw repository files of the GitHub
+// instance at serverURL (GitHubServerURL when empty), followed by
+// /OWNER/REPO/REF/PATH
+func GitHubRawURL(serverURL string) string {
+	serverURL = strings.TrimSuffix(serverURL, "/")
+	if serverURL == "" {
+		serverURL = GitHubServerURL()
+	}
+	if isDotCom(serverURL) {
+		return DefaultGitHubRawURL
+	}
+	return serverURL + "/raw"
+}
+
+// isDotCom reports whether serverURL is github.com
+func isDotCom(serverURL string) bool {
+	u, err := url.Parse(serverURL)
+	return err == nil && strings.EqualFold(u.Host, "github.com")
+}
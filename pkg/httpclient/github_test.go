@@ -0,0 +1,90 @@
+package httpclient
+
+import (
+	"context"
+	"net/url"
+	"testing"
+)
+
+func TestGitHubURLs(t *testing.T) {
+	t.Setenv("GITHUB_SERVER_URL", "")
+	t.Setenv("GITHUB_API_URL", "")
+	if got := GitHubServerURL(); got != DefaultGitHubServerURL {
+		t.Errorf("GitHubServerURL() = %q, want %q", got, DefaultGitHubServerURL)
+	}
+	if got := GitHubAPIURL(""); got != DefaultGitHubAPIURL {
+		t.Errorf("GitHubAPIURL() = %q, want %q", got, DefaultGitHubAPIURL)
+	}
+	if got := GitHubRawURL("https://github.com/"); got != DefaultGitHubRawURL {
+		t.Errorf("GitHubRawURL(github.com) = %q, want %q", got, DefaultGitHubRawURL)
+	}
+	if got := GitHubAPIURL("https://github.example.com"); got != "https://github.example.com/api/v3" {
+		t.Errorf("GitHubAPIURL(GHES) = %q", got)
+	}
+	if got := GitHubRawURL("https://github.example.com"); got != "https://github.example.com/raw" {
+		t.Errorf("GitHubRawURL(GHES) = %q", got)
+	}
+
+	// GitHub Actions on GitHub Enterprise Server
+	t.Setenv("GITHUB_SERVER_URL", "https://ghes.example.com/")
+	t.Setenv("GITHUB_API_URL", "https://ghes.example.com/api/v3/")
+	if got := GitHubServerURL(); got != "https://ghes.example.com" {
+		t.Errorf("GitHubServerURL() = %q, want GITHUB_SERVER_URL", got)
+	}
+	if got := GitHubAPIURL(""); got != "https://ghes.example.com/api/v3" {
+		t.Errorf("GitHubAPIURL() = %q, want GITHUB_API_URL", got)
+	}
+	if got := GitHubAPIURL("https://github.com"); got != DefaultGitHubAPIURL {
+		t.Errorf("GitHubAPIURL(github.com) = %q, want %q", got, DefaultGitHubAPIURL)
+	}
+}
+
+func TestAuthTokenGitHubEnterprise(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "ghp_dotcom")
+	t.Setenv("GH_ENTERPRISE_TOKEN", "")
+	t.Setenv("GITHUB_ENTERPRISE_TOKEN", "")
+	t.Setenv("GITHUB_SERVER_URL", "https://ghes.example.com")
+	t.Setenv("BINSTALLER_NO_KEYRING", "")
+	oldLookup := lookupStoredToken
+	lookupStoredToken = func(host string) (string, string) {
+		if host == "github.example.com" {
+			return "ghp_stored", "test"
+		}
+		return "", ""
+	}
+	defer func() { lookupStoredToken = oldLookup }()
+	enterpriseTokens.Clear()
+	defer enterpriseTokens.Clear()
+
+	ctx := WithGitHubHost(context.Background(), "GitHub.Example.com")
+	ctx = WithGitHubHost(ctx, "tokenless.example.com")
+	tests := []struct {
+		ctx  context.Context
+		url  string
+		want string
+	}{
+		{ctx: ctx, url: "https://github.example.com/api/v3/repos/owner/tool", want: "ghp_stored"},
+		// The github.com token is never sent to an Enterprise Server host
+		{ctx: ctx, url: "https://tokenless.example.com/api/v3/repos/owner/tool", want: ""},
+		// GitHub Actions' token belongs to the instance it runs on
+		{ctx: ctx, url: "https://ghes.example.com/owner/tool/releases/download/v1/tool", want: "ghp_dotcom"},
+		{ctx: ctx, url: "https://other.example.com/owner/tool", want: ""},
+		// Hosts are only known to the requests of their context
+		{ctx: context.Background(), url: "https://github.example.com/api/v3/repos/owner/tool", want: ""},
+	}
+	for _, tt := range tests {
+		u, err := url.Parse(tt.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := authToken(tt.ctx, u); got != tt.want {
+			t.Errorf("authToken(%s) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+
+	t.Setenv("GH_ENTERPRISE_TOKEN", "ghe_env")
+	u, _ := url.Parse("https://tokenless.example.com/api/v3")
+	if got := authToken(ctx, u); got != "ghe_env" {
+		t.Errorf("authToken() with GH_ENTERPRISE_TOKEN = %q, want %q", got, "ghe_env")
+	}
+}
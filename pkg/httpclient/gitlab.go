@@ -0,0 +1,46 @@
+package httpclient
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// gitLabHosts holds the self-managed GitLab instances GITLAB_TOKEN is sent to
+var gitLabHosts sync.Map
+
+// AddGitLabHost makes clients send GITLAB_TOKEN to host, a self-managed GitLab
+// instance. gitlab.com is always known.
+func AddGitLabHost(host string) {
+	gitLabHosts.Store(strings.ToLower(host), true)
+}
+
+// isGitLabHost reports whether host is gitlab.com or an added GitLab instance
+func isGitLabHost(host string) bool {
+	host = strings.ToLower(host)
+	if host == "gitlab.com" {
+		return true
+	}
+	_, ok := gitLabHosts.Load(host)
+	return ok
+}
+
+// authToken returns the token for a request to u made with ctx: GITLAB_TOKEN
+// for GitLab instances, the GitHub token for github.com, the token of the
+// instance for GitHub Enterprise Server, and "" for any other host. A GitHub
+// token is never sent to GitLab, and the github.com token is never sent to
+// another host.
+func authToken(ctx context.Context, u *url.URL) string {
+	if isGitLabHost(u.Host) {
+		return os.Getenv("GITLAB_TOKEN")
+	}
+	if isGitHubURL(u) {
+		return GitHubToken()
+	}
+	if isGitHubHost(ctx, u.Host) {
+		return enterpriseToken(u.Host)
+	}
+	return ""
+}
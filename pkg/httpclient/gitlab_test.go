@@ -0,0 +1,35 @@
+package httpclient
+
+import (
+	"context"
+	"net/url"
+	"testing"
+)
+
+func TestAuthToken(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "ghp_test")
+	t.Setenv("GITLAB_TOKEN", "glpat_test")
+	AddGitLabHost("GitLab.Example.com:8443")
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "https://api.github.com/repos/owner/tool", want: "ghp_test"},
+		{url: "https://gitlab.com/api/v4/projects/group%2Ftool", want: "glpat_test"},
+		{url: "https://gitlab.example.com:8443/group/tool/-/releases", want: "glpat_test"},
+		{url: "https://gitlab.example.com/group/tool/-/releases", want: ""},
+		{url: "https://example.com/tool.tar.gz", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := authToken(context.Background(), u); got != tt.want {
+				t.Errorf("authToken() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,19 +1,72 @@
 package httpclient
 
 import (
+	"fmt"
 	"net/http"
-	"os"
+	"net/url"
 	"strings"
+
+	"github.com/binary-install/binstaller/pkg/metrics"
 )
 
 // NewGitHubClient creates an HTTP client configured for GitHub API requests.
-// It automatically adds the GitHub token from GITHUB_TOKEN environment variable if available.
+// It automatically adds the GitHub token from GitHubToken if available.
+// Redirects leaving the host of a request never carry its Authorization header,
+// see stripAuthOnRedirect. Network errors, server errors and rate limits are
+// retried with exponential backoff following RetryPolicyFromEnv, and JSON API
+// responses are cached and revalidated with conditional requests, see
+// cacheTransport.
 func NewGitHubClient() *http.Client {
+	return NewGitHubClientWithPolicy(RetryPolicyFromEnv())
+}
+
+// NewGitHubClientWithPolicy creates a client like NewGitHubClient retrying
+// requests following policy
+func NewGitHubClientWithPolicy(policy RetryPolicy) *http.Client {
 	return &http.Client{
-		Transport: &gitHubTransport{
-			Base: http.DefaultTransport,
+		Transport: &retryTransport{
+			Base: &gitHubTransport{
+				Base: &cacheTransport{
+					Base: http.DefaultTransport,
+					Dir:  responseCacheDir(),
+				},
+			},
+			Policy: policy,
 		},
+		CheckRedirect: stripAuthOnRedirect,
+	}
+}
+
+// NewClientWithPolicy creates a client for URLs given by the user, such as
+// install --asset-url. It retries requests following policy like
+// NewGitHubClientWithPolicy but never sends a token.
+func NewClientWithPolicy(policy RetryPolicy) *http.Client {
+	return &http.Client{
+		Transport: &retryTransport{Base: http.DefaultTransport, Policy: policy},
+	}
+}
+
+// maxRedirects is the number of redirects a client follows, as net/http does
+const maxRedirects = 10
+
+// stripAuthOnRedirect removes the Authorization header from a redirect to
+// another host. GitHub redirects release asset downloads to signed
+// objects.githubusercontent.com URLs, which reject requests carrying another
+// Authorization header. net/http only strips it for hosts outside the domain of
+// the first request, and keeps it for its subdomains.
+func stripAuthOnRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	if !sameHost(req, via[len(via)-1]) {
+		req.Header.Del("Authorization")
 	}
+	return nil
+}
+
+// sameHost reports whether a and b are requests to the same host and port
+func sameHost(a, b *http.Request) bool {
+	return strings.EqualFold(a.URL.Host, b.URL.Host)
 }
 
 // gitHubTransport is a custom RoundTripper that adds GitHub authentication
@@ -26,15 +79,21 @@ func (t *gitHubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	// Clone the request to avoid modifying the original
 	req2 := req.Clone(req.Context())
 
-	// Add GitHub token if available and the request is to GitHub
+	// Add the GitHub or GitLab token if available and the request is to that service
 	// Only set Authorization header if it's not already present
-	if isGitHubURL(req2.URL.String()) {
-		if token := os.Getenv("GITHUB_TOKEN"); token != "" && req2.Header.Get("Authorization") == "" {
+	// On redirects, only add the token when staying on the host that redirected
+	gitHub := !isGitLabHost(req2.URL.Host) && (isGitHubURL(req2.URL) || isGitHubHost(req.Context(), req2.URL.Host))
+	if req.Response == nil || sameHost(req, req.Response.Request) {
+		if token := authToken(req.Context(), req2.URL); token != "" && req2.Header.Get("Authorization") == "" {
 			req2.Header.Set("Authorization", "Bearer "+token)
 		}
 	}
 
-	return t.Base.RoundTrip(req2)
+	resp, err := t.Base.RoundTrip(req2)
+	if gitHub && err == nil {
+		metrics.RecordGitHubResponse(resp)
+	}
+	return resp, err
 }
 
 // NewRequestWithGitHubAuth creates a new HTTP request and adds GitHub authentication if available.
@@ -45,17 +104,18 @@ func NewRequestWithGitHubAuth(method, url string) (*http.Request, error) {
 		return nil, err
 	}
 
-	// Add GitHub token if available and the URL is GitHub
-	if isGitHubURL(url) {
-		if token := os.Getenv("GITHUB_TOKEN"); token != "" {
-			req.Header.Set("Authorization", "Bearer "+token)
-		}
+	// Add the GitHub or GitLab token if available and the URL is that service
+	if token := authToken(req.Context(), req.URL); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
 	}
 
 	return req, nil
 }
 
-// isGitHubURL checks if a URL is a GitHub URL
-func isGitHubURL(url string) bool {
-	return strings.Contains(url, "github.com") || strings.Contains(url, "api.github.com") || strings.Contains(url, "githubusercontent.com")
+// isGitHubURL reports whether u is on github.com: github.com, api.github.com or
+// a githubusercontent.com subdomain. Hosts are matched exactly, so lookalikes
+// such as github.com.example.com never get the token.
+func isGitHubURL(u *url.URL) bool {
+	host := strings.ToLower(u.Hostname())
+	return host == "github.com" || host == "api.github.com" || strings.HasSuffix(host, ".githubusercontent.com")
 }
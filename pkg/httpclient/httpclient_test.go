@@ -1,14 +1,19 @@
 package httpclient
 
 import (
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"strings"
 	"testing"
 )
 
 func TestNewRequestWithGitHubAuth(t *testing.T) {
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("BINSTALLER_NO_KEYRING", "1")
 	tests := []struct {
 		name      string
 		url       string
@@ -81,6 +86,8 @@ func TestNewRequestWithGitHubAuth(t *testing.T) {
 }
 
 func TestGitHubTransport(t *testing.T) {
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("BINSTALLER_NO_KEYRING", "1")
 	// Create a test server that echoes back the Authorization header
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		auth := r.Header.Get("Authorization")
@@ -225,11 +232,30 @@ func TestIsGitHubURL(t *testing.T) {
 			url:  "http://github.com/owner/repo",
 			want: true,
 		},
+		{
+			name: "lookalike host",
+			url:  "https://github.com.example.com/owner/repo",
+			want: false,
+		},
+		{
+			name: "github.com in the path",
+			url:  "https://example.com/github.com/owner/repo",
+			want: false,
+		},
+		{
+			name: "githubusercontent.com lookalike",
+			url:  "https://evilgithubusercontent.com/file",
+			want: false,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := isGitHubURL(tt.url); got != tt.want {
+			u, err := url.Parse(tt.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := isGitHubURL(u); got != tt.want {
 				t.Errorf("isGitHubURL() = %v, want %v", got, tt.want)
 			}
 		})
@@ -243,12 +269,123 @@ func TestNewGitHubClient(t *testing.T) {
 	}
 
 	// Check that the transport is set correctly
-	transport, ok := client.Transport.(*gitHubTransport)
+	retry, ok := client.Transport.(*retryTransport)
+	if !ok {
+		t.Fatal("NewGitHubClient() did not set retryTransport")
+	}
+	if retry.Policy != DefaultRetryPolicy {
+		t.Errorf("retryTransport.Policy = %+v, want %+v", retry.Policy, DefaultRetryPolicy)
+	}
+
+	transport, ok := retry.Base.(*gitHubTransport)
 	if !ok {
-		t.Error("NewGitHubClient() did not set gitHubTransport")
+		t.Fatal("NewGitHubClient() did not set gitHubTransport")
+	}
+
+	cache, ok := transport.Base.(*cacheTransport)
+	if !ok {
+		t.Fatal("NewGitHubClient() did not set cacheTransport")
+	}
+
+	if cache.Base != http.DefaultTransport {
+		t.Error("cacheTransport.Base is not http.DefaultTransport")
+	}
+}
+
+// hostTransport sends requests for each host to a test server
+type hostTransport map[string]*httptest.Server
+
+func (t hostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	server, ok := t[req.URL.Host]
+	if !ok {
+		return nil, fmt.Errorf("unexpected host %s", req.URL.Host)
+	}
+	newReq := req.Clone(req.Context())
+	newReq.URL.Host = strings.TrimPrefix(server.URL, "http://")
+	newReq.URL.Scheme = "http"
+	resp, err := http.DefaultTransport.RoundTrip(newReq)
+	if err == nil {
+		resp.Request = req
+	}
+	return resp, err
+}
+
+func TestGitHubClientStripsAuthOnRedirect(t *testing.T) {
+	const token = "ghp_testtoken"
+	t.Setenv("GITHUB_TOKEN", token)
+
+	assets := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			http.Error(w, "Only one auth mechanism allowed", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte("asset"))
+	}))
+	defer assets.Close()
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		switch r.URL.Path {
+		case "/old-owner/tool/releases/download/v1.0.0/tool.tar.gz":
+			http.Redirect(w, r, "/owner/tool/releases/download/v1.0.0/tool.tar.gz", http.StatusMovedPermanently)
+		default:
+			http.Redirect(w, r, "https://objects.githubusercontent.com/signed?X-Amz-Signature=abc", http.StatusFound)
+		}
+	}))
+	defer github.Close()
+
+	newClient := func() *http.Client {
+		client := NewGitHubClient()
+		client.Transport.(*retryTransport).Base.(*gitHubTransport).Base = hostTransport{
+			"github.com":                    github,
+			"objects.githubusercontent.com": assets,
+		}
+		return client
 	}
 
-	if transport.Base != http.DefaultTransport {
-		t.Error("gitHubTransport.Base is not http.DefaultTransport")
+	tests := []struct {
+		name string
+		auth string
+	}{
+		{name: "token from environment"},
+		{name: "token set by the caller", auth: "Bearer caller_token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "https://github.com/old-owner/tool/releases/download/v1.0.0/tool.tar.gz", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			resp, err := newClient().Do(req)
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != http.StatusOK || string(body) != "asset" {
+				t.Errorf("Do() = %d %q, want the asset without forwarding Authorization", resp.StatusCode, body)
+			}
+		})
+	}
+
+	// Subdomains of the first host are other hosts, too
+	req, err := http.NewRequest("GET", "https://github.com/owner/tool/releases/download/v1.0.0/tool.tar.gz", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer caller_token")
+	via := []*http.Request{req}
+	next, _ := http.NewRequest("GET", "https://codeload.github.com/owner/tool", nil)
+	next.Header.Set("Authorization", "Bearer caller_token")
+	if err := stripAuthOnRedirect(next, via); err != nil {
+		t.Fatal(err)
+	}
+	if got := next.Header.Get("Authorization"); got != "" {
+		t.Errorf("Authorization on redirect to a subdomain = %q, want it stripped", got)
 	}
 }
@@ -0,0 +1,272 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/apex/log"
+)
+
+// RetryPolicy configures how failed requests are retried
+type RetryPolicy struct {
+	// Attempts is the maximum number of attempts of a request, including the
+	// first one. 1 or less disables retries.
+	Attempts int
+	// MinBackoff is the delay before the first retry. It doubles on every
+	// further retry.
+	MinBackoff time.Duration
+	// MaxBackoff caps the delay between attempts. Responses asking to retry
+	// later than MaxBackoff, with Retry-After or X-RateLimit-Reset, are not
+	// retried.
+	MaxBackoff time.Duration
+	// Jitter is the fraction of a backoff randomly added or removed, so that
+	// concurrent clients do not retry in lockstep
+	Jitter float64
+	// Timeout limits each attempt, including reading the response body. An
+	// attempt timing out before the response arrives is retried. 0 means no
+	// limit.
+	Timeout time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy of NewGitHubClient, before
+// BINSTALLER_HTTP_RETRIES is applied
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts:   4,
+	MinBackoff: time.Second,
+	MaxBackoff: 30 * time.Second,
+	Jitter:     0.2,
+}
+
+// RetryPolicyFromEnv returns DefaultRetryPolicy with the number of retries set
+// by BINSTALLER_HTTP_RETRIES, if it is a valid non-negative integer
+func RetryPolicyFromEnv() RetryPolicy {
+	return DefaultRetryPolicy.WithEnv()
+}
+
+// WithEnv returns p with the number of retries set by BINSTALLER_HTTP_RETRIES,
+// if it is a valid non-negative integer
+func (p RetryPolicy) WithEnv() RetryPolicy {
+	policy := p
+	env := os.Getenv("BINSTALLER_HTTP_RETRIES")
+	if env == "" {
+		return policy
+	}
+	retries, err := strconv.Atoi(env)
+	if err != nil || retries < 0 {
+		log.Warnf("ignoring invalid BINSTALLER_HTTP_RETRIES=%q: expected a non-negative integer", env)
+		return policy
+	}
+	policy.Attempts = retries + 1
+	return policy
+}
+
+// Backoff returns the delay before retry number retry, counting from 1,
+// without jitter
+func (p RetryPolicy) Backoff(retry int) time.Duration {
+	d := p.MinBackoff
+	for i := 1; i < retry && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, p.MaxBackoff)
+}
+
+// jitter randomly adds or removes up to p.Jitter of d
+func (p RetryPolicy) jitter(d time.Duration) time.Duration {
+	if p.Jitter <= 0 || d <= 0 {
+		return d
+	}
+	return d + time.Duration((rand.Float64()*2-1)*p.Jitter*float64(d))
+}
+
+// retryTransport is a RoundTripper retrying requests failing with network
+// errors, server errors or rate limits
+type retryTransport struct {
+	Base   http.RoundTripper
+	Policy RetryPolicy
+	// sleep waits for d unless ctx is done first, replaced in tests
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Requests whose body cannot be sent again are only attempted once
+	if t.Policy.Attempts <= 1 || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+		return t.attempt(req)
+	}
+
+	for attempt := 1; ; attempt++ {
+		attemptReq := req
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		resp, err := t.attempt(attemptReq)
+		if attempt >= t.Policy.Attempts {
+			return resp, err
+		}
+		delay, reason, retry := t.retryAfter(attempt, resp, err)
+		if !retry {
+			return resp, err
+		}
+		if resp != nil {
+			// Drain the body so the connection can be reused
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+			resp.Body.Close()
+		}
+
+		log.Warnf("%s %s failed (%s), retrying in %s (attempt %d of %d)", req.Method, req.URL.Redacted(), reason, delay.Round(time.Millisecond), attempt+1, t.Policy.Attempts)
+		sleep := t.sleep
+		if sleep == nil {
+			sleep = sleepContext
+		}
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// errAttemptTimeout is the cause of the cancellation of an attempt exceeding
+// RetryPolicy.Timeout
+var errAttemptTimeout = errors.New("attempt timed out")
+
+// attempt sends req once, cancelling it when it takes longer than
+// t.Policy.Timeout. The timeout keeps running while the response body is read.
+func (t *retryTransport) attempt(req *http.Request) (*http.Response, error) {
+	timeout := t.Policy.Timeout
+	if timeout <= 0 {
+		return t.Base.RoundTrip(req)
+	}
+	ctx, cancel := context.WithCancelCause(req.Context())
+	timer := time.AfterFunc(timeout, func() { cancel(errAttemptTimeout) })
+	stop := func() {
+		timer.Stop()
+		cancel(nil)
+	}
+	resp, err := t.Base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		timedOut := context.Cause(ctx) == errAttemptTimeout && req.Context().Err() == nil
+		stop()
+		if timedOut {
+			// Not wrapping the cancellation lets retryAfter retry the request
+			return nil, fmt.Errorf("no response within %s", timeout)
+		}
+		return nil, err
+	}
+	resp.Body = &timeoutBody{ReadCloser: resp.Body, ctx: ctx, timeout: timeout, stop: stop}
+	return resp, nil
+}
+
+// timeoutBody is the body of a response whose attempt has a timeout. Closing
+// it stops the timer.
+type timeoutBody struct {
+	io.ReadCloser
+	ctx     context.Context
+	timeout time.Duration
+	stop    func()
+}
+
+// Read implements io.Reader, reporting a read cancelled by the timeout as such
+func (b *timeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF && context.Cause(b.ctx) == errAttemptTimeout {
+		return n, fmt.Errorf("transfer not completed within %s: %w", b.timeout, errAttemptTimeout)
+	}
+	return n, err
+}
+
+// Close implements io.Closer
+func (b *timeoutBody) Close() error {
+	defer b.stop()
+	return b.ReadCloser.Close()
+}
+
+// retryAfter reports whether the result of an attempt is transient, why, and
+// how long to wait before the next attempt
+func (t *retryTransport) retryAfter(attempt int, resp *http.Response, err error) (time.Duration, string, bool) {
+	backoff := t.Policy.jitter(t.Policy.Backoff(attempt))
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return 0, "", false
+		}
+		// A host that does not exist will not exist on the next attempt either
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return 0, "", false
+		}
+		return backoff, err.Error(), true
+	}
+
+	reason := resp.Status
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode == http.StatusRequestTimeout:
+	case resp.StatusCode == http.StatusForbidden && isRateLimited(resp):
+		reason = "rate limit exceeded"
+	case resp.StatusCode == http.StatusInternalServerError,
+		resp.StatusCode == http.StatusBadGateway,
+		resp.StatusCode == http.StatusServiceUnavailable,
+		resp.StatusCode == http.StatusGatewayTimeout:
+	default:
+		return 0, "", false
+	}
+
+	if delay, ok := serverDelay(resp, time.Now()); ok {
+		if delay > t.Policy.MaxBackoff {
+			// Waiting that long is worse than failing with the server's answer
+			return 0, "", false
+		}
+		return max(delay, backoff), reason, true
+	}
+	return backoff, reason, true
+}
+
+// isRateLimited reports whether a 403 response is a GitHub rate limit rather
+// than a permission error
+func isRateLimited(resp *http.Response) bool {
+	return resp.Header.Get("X-RateLimit-Remaining") == "0" || resp.Header.Get("Retry-After") != ""
+}
+
+// serverDelay returns the delay a response asks for with its Retry-After
+// header, in seconds or as an HTTP date, or with GitHub's X-RateLimit-Reset
+// header once the rate limit is exhausted
+func serverDelay(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if v := resp.Header.Get("Retry-After"); v != "" {
+		if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if at, err := http.ParseTime(v); err == nil {
+			return max(at.Sub(now), 0), true
+		}
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return max(time.Unix(reset, 0).Sub(now), 0), true
+		}
+	}
+	return 0, false
+}
+
+// sleepContext waits for d, or returns the error of ctx if it is done first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("waiting to retry: %w", ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,289 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestRetryTransport returns a retryTransport recording its delays instead
+// of sleeping
+func newTestRetryTransport(attempts int) (*retryTransport, *[]time.Duration) {
+	var delays []time.Duration
+	return &retryTransport{
+		Base: http.DefaultTransport,
+		Policy: RetryPolicy{
+			Attempts:   attempts,
+			MinBackoff: time.Second,
+			MaxBackoff: 10 * time.Second,
+		},
+		sleep: func(ctx context.Context, d time.Duration) error {
+			delays = append(delays, d)
+			return nil
+		},
+	}, &delays
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name       string
+		responses  []func(w http.ResponseWriter)
+		attempts   int
+		wantStatus int
+		wantCalls  int
+		wantDelays []time.Duration
+	}{
+		{
+			name: "success",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusOK) },
+			},
+			attempts:   3,
+			wantStatus: http.StatusOK,
+			wantCalls:  1,
+		},
+		{
+			name: "server errors with exponential backoff",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadGateway) },
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) },
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusOK) },
+			},
+			attempts:   3,
+			wantStatus: http.StatusOK,
+			wantCalls:  3,
+			wantDelays: []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name: "attempts exhausted",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusInternalServerError) },
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusInternalServerError) },
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusOK) },
+			},
+			attempts:   2,
+			wantStatus: http.StatusInternalServerError,
+			wantCalls:  2,
+			wantDelays: []time.Duration{time.Second},
+		},
+		{
+			name: "client error",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusNotFound) },
+			},
+			attempts:   3,
+			wantStatus: http.StatusNotFound,
+			wantCalls:  1,
+		},
+		{
+			name: "forbidden",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusForbidden) },
+			},
+			attempts:   3,
+			wantStatus: http.StatusForbidden,
+			wantCalls:  1,
+		},
+		{
+			name: "retry after",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("Retry-After", "5")
+					w.WriteHeader(http.StatusTooManyRequests)
+				},
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusOK) },
+			},
+			attempts:   3,
+			wantStatus: http.StatusOK,
+			wantCalls:  2,
+			wantDelays: []time.Duration{5 * time.Second},
+		},
+		{
+			name: "retry after beyond max backoff",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("Retry-After", "3600")
+					w.WriteHeader(http.StatusTooManyRequests)
+				},
+			},
+			attempts:   3,
+			wantStatus: http.StatusTooManyRequests,
+			wantCalls:  1,
+		},
+		{
+			name: "rate limit reset",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("X-RateLimit-Remaining", "0")
+					w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10))
+					w.WriteHeader(http.StatusForbidden)
+				},
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusOK) },
+			},
+			attempts:   3,
+			wantStatus: http.StatusOK,
+			wantCalls:  2,
+			wantDelays: []time.Duration{time.Second},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if r.Method == http.MethodPost && string(body) != "payload" {
+					t.Errorf("attempt %d body = %q, want %q", calls+1, body, "payload")
+				}
+				tt.responses[calls](w)
+				calls++
+			}))
+			defer server.Close()
+
+			transport, delays := newTestRetryTransport(tt.attempts)
+			for _, method := range []string{http.MethodGet, http.MethodPost} {
+				calls = 0
+				*delays = nil
+				req, err := http.NewRequest(method, server.URL, strings.NewReader("payload"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				resp, err := transport.RoundTrip(req)
+				if err != nil {
+					t.Fatalf("RoundTrip() error = %v", err)
+				}
+				resp.Body.Close()
+				if resp.StatusCode != tt.wantStatus {
+					t.Errorf("%s status = %d, want %d", method, resp.StatusCode, tt.wantStatus)
+				}
+				if calls != tt.wantCalls {
+					t.Errorf("%s calls = %d, want %d", method, calls, tt.wantCalls)
+				}
+				if len(*delays) != len(tt.wantDelays) {
+					t.Fatalf("%s delays = %v, want %v", method, *delays, tt.wantDelays)
+				}
+				for i, d := range *delays {
+					if d != tt.wantDelays[i] {
+						t.Errorf("%s delays = %v, want %v", method, *delays, tt.wantDelays)
+						break
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRetryTransportNetworkError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	transport, delays := newTestRetryTransport(3)
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	if _, err := transport.RoundTrip(req); err == nil {
+		t.Fatal("RoundTrip() error = nil, want connection error")
+	}
+	if len(*delays) != 2 {
+		t.Errorf("delays = %v, want 2 retries", *delays)
+	}
+}
+
+func TestRetryTransportCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	transport := &retryTransport{Base: http.DefaultTransport, Policy: RetryPolicy{Attempts: 3, MinBackoff: time.Hour, MaxBackoff: time.Hour}}
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	_, err := transport.RoundTrip(req)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("RoundTrip() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestRetryTransportTimeout(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			// The first attempt gets no response in time
+			<-r.Context().Done()
+		case 2:
+			// The second one stalls in the middle of the body
+			w.Write([]byte("partial"))
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}
+	}))
+	defer server.Close()
+
+	transport, delays := newTestRetryTransport(3)
+	transport.Policy.Timeout = 50 * time.Millisecond
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip() error = %v, want the timeout retried", err)
+	}
+	defer resp.Body.Close()
+	if len(*delays) != 1 {
+		t.Errorf("delays = %v, want 1 retry", *delays)
+	}
+	if _, err := io.ReadAll(resp.Body); !errors.Is(err, errAttemptTimeout) {
+		t.Errorf("reading the stalled body error = %v, want %v", err, errAttemptTimeout)
+	}
+
+	// The timeout also applies without retries
+	transport.Policy.Attempts = 1
+	calls.Store(0)
+	if _, err := transport.RoundTrip(req); err == nil || !strings.Contains(err.Error(), "no response within") {
+		t.Errorf("RoundTrip() without retries error = %v, want a timeout", err)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{MinBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, w := range want {
+		if got := policy.Backoff(i + 1); got != w {
+			t.Errorf("Backoff(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for range 100 {
+		if d := policy.jitter(4 * time.Second); d < 2*time.Second || d > 6*time.Second {
+			t.Fatalf("jitter(4s) = %v, want within 2s..6s", d)
+		}
+	}
+}
+
+func TestRetryPolicyFromEnv(t *testing.T) {
+	tests := []struct {
+		env  string
+		want int
+	}{
+		{"", DefaultRetryPolicy.Attempts},
+		{"0", 1},
+		{"5", 6},
+		{"-1", DefaultRetryPolicy.Attempts},
+		{"many", DefaultRetryPolicy.Attempts},
+	}
+	for _, tt := range tests {
+		t.Setenv("BINSTALLER_HTTP_RETRIES", tt.env)
+		if got := RetryPolicyFromEnv().Attempts; got != tt.want {
+			t.Errorf("BINSTALLER_HTTP_RETRIES=%q: Attempts = %d, want %d", tt.env, got, tt.want)
+		}
+	}
+}
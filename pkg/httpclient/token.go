@@ -0,0 +1,329 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/goccy/go-yaml"
+	"github.com/zalando/go-keyring"
+)
+
+// gitHubHost is the host whose stored credentials are used
+const gitHubHost = "github.com"
+
+// keyringTimeout bounds the OS keychain lookup, which may wait on a locked keychain
+const keyringTimeout = 5 * time.Second
+
+var (
+	storedTokenOnce   sync.Once
+	storedTokenVal    string
+	storedTokenSource string
+	// lookupStoredToken finds a token stored outside the environment and
+	// where it is stored; tests replace it
+	lookupStoredToken = storedToken
+	// enterpriseTokens caches the stored tokens of GitHub Enterprise Server
+	// hosts, see enterpriseToken
+	enterpriseTokens sync.Map
+)
+
+// GitHubToken returns the token for GitHub requests. GITHUB_TOKEN and GH_TOKEN
+// take precedence; without them the token stored by binst auth login is used,
+// then the token gh stored at login, from the OS keychain (macOS Keychain,
+// Windows Credential Manager, Secret Service) or a hosts.yml file. Set
+// BINSTALLER_NO_KEYRING=1 to only use the environment.
+func GitHubToken() string {
+	token, _ := gitHubTokenWithSource()
+	return token
+}
+
+// GitHubTokenSource describes where GitHubToken takes its token from, or
+// returns "" when there is no token
+func GitHubTokenSource() string {
+	_, source := gitHubTokenWithSource()
+	return source
+}
+
+func gitHubTokenWithSource() (string, string) {
+	for _, name := range []string{"GITHUB_TOKEN", "GH_TOKEN"} {
+		if token := os.Getenv(name); token != "" {
+			return token, name + " environment variable"
+		}
+	}
+	if os.Getenv("BINSTALLER_NO_KEYRING") == "1" {
+		return "", ""
+	}
+	storedTokenOnce.Do(func() {
+		storedTokenVal, storedTokenSource = lookupStoredToken(gitHubHost)
+		if storedTokenVal != "" {
+			log.Debugf("Using the GitHub token from %s", storedTokenSource)
+		}
+	})
+	return storedTokenVal, storedTokenSource
+}
+
+// enterpriseToken returns the token for host, a GitHub Enterprise Server
+// instance: GH_ENTERPRISE_TOKEN or GITHUB_ENTERPRISE_TOKEN as gh reads them,
+// GITHUB_TOKEN or GH_TOKEN when host is the GITHUB_SERVER_URL instance GitHub
+// Actions runs on, then the token binst auth login or gh stored for host.
+// Without any of them it returns "" rather than the github.com token.
+func enterpriseToken(host string) string {
+	host = strings.ToLower(host)
+	for _, name := range []string{"GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"} {
+		if token := os.Getenv(name); token != "" {
+			return token
+		}
+	}
+	if isActionsHost(host) {
+		for _, name := range []string{"GITHUB_TOKEN", "GH_TOKEN"} {
+			if token := os.Getenv(name); token != "" {
+				return token
+			}
+		}
+	}
+	if os.Getenv("BINSTALLER_NO_KEYRING") == "1" {
+		return ""
+	}
+	if token, ok := enterpriseTokens.Load(host); ok {
+		return token.(string)
+	}
+	token, source := lookupStoredToken(host)
+	if token != "" {
+		log.Debugf("Using the %s token from %s", host, source)
+	}
+	enterpriseTokens.Store(host, token)
+	return token
+}
+
+// storedToken returns the token binst auth login or gh stored for host and
+// where it is stored, or "" when there is none
+func storedToken(host string) (string, string) {
+	if token := keyringToken(loginKeyringService(host), ""); token != "" {
+		return token, "binst auth login (OS keychain)"
+	}
+	if path := LoginTokenFile(); path != "" {
+		if token := hostsFileToken(path, host); token != "" {
+			return token, "binst auth login (" + path + ")"
+		}
+	}
+	return gitHubCLIToken(host)
+}
+
+// gitHubCLIToken returns the token gh stored for host and where it is stored,
+// or "" when there is none
+func gitHubCLIToken(host string) (string, string) {
+	if token := keyringToken("gh:"+host, ""); token != "" {
+		return token, "gh (OS keychain)"
+	}
+	path := filepath.Join(gitHubCLIConfigDir(), "hosts.yml")
+	if token := hostsFileToken(path, host); token != "" {
+		return token, "gh (" + path + ")"
+	}
+	return "", ""
+}
+
+// loginKeyringService is the OS keychain service of the token binst auth
+// login stores for host
+func loginKeyringService(host string) string {
+	return "binstaller:" + host
+}
+
+// LoginTokenFile returns the file binst auth login stores tokens in when no OS
+// keychain is available: hosts.yml in $XDG_CONFIG_HOME/binstaller (falling
+// back to ~/.config/binstaller)
+func LoginTokenFile() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "binstaller", "hosts.yml")
+}
+
+// StoreLoginToken stores the token of binst auth login for host in the OS
+// keychain, or in LoginTokenFile with owner-only permissions when no keychain
+// is available. It returns where the token was stored.
+func StoreLoginToken(host, token string) (string, error) {
+	err := withKeyring(func() error {
+		return keyring.Set(loginKeyringService(host), "", token)
+	})
+	if err == nil {
+		return "the OS keychain", nil
+	}
+	log.Debugf("Failed to store the token in the OS keychain: %v", err)
+
+	path := LoginTokenFile()
+	if path == "" {
+		return "", errors.New("no OS keychain and no home directory to store the token in")
+	}
+	hosts, err := readHostsFile(path)
+	if err != nil {
+		return "", err
+	}
+	hosts[host] = hostsEntry{OAuthToken: token}
+	if err := writeHostsFile(path, hosts); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// DeleteLoginToken removes the token of binst auth login for host from the OS
+// keychain and LoginTokenFile. It reports whether a token was removed.
+func DeleteLoginToken(host string) (bool, error) {
+	deleted := false
+	err := withKeyring(func() error {
+		return keyring.Delete(loginKeyringService(host), "")
+	})
+	if err == nil {
+		deleted = true
+	}
+
+	path := LoginTokenFile()
+	if path == "" {
+		return deleted, nil
+	}
+	hosts, err := readHostsFile(path)
+	if err != nil {
+		return deleted, err
+	}
+	if _, ok := hosts[host]; !ok {
+		return deleted, nil
+	}
+	delete(hosts, host)
+	if len(hosts) == 0 {
+		if err := os.Remove(path); err != nil {
+			return deleted, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		return true, nil
+	}
+	return true, writeHostsFile(path, hosts)
+}
+
+// keyringAvailable reports whether an OS keychain can be used
+func keyringAvailable() bool {
+	// Without a session bus the Secret Service lookup would try to start one
+	return runtime.GOOS == "darwin" || runtime.GOOS == "windows" || os.Getenv("DBUS_SESSION_BUS_ADDRESS") != ""
+}
+
+// withKeyring runs a keychain operation, giving up after keyringTimeout
+func withKeyring(op func() error) error {
+	if !keyringAvailable() {
+		return errors.New("no OS keychain available")
+	}
+	result := make(chan error, 1)
+	go func() {
+		result <- op()
+	}()
+	select {
+	case err := <-result:
+		return err
+	case <-time.After(keyringTimeout):
+		return errors.New("timed out waiting for the OS keychain")
+	}
+}
+
+// keyringToken reads a secret from the OS keychain, giving up after keyringTimeout
+func keyringToken(service, user string) string {
+	var secret string
+	err := withKeyring(func() error {
+		var err error
+		secret, err = keyring.Get(service, user)
+		return err
+	})
+	if err != nil {
+		if keyringAvailable() && !errors.Is(err, keyring.ErrNotFound) {
+			log.Debugf("Failed to read %s from the OS keychain: %v", service, err)
+		}
+		return ""
+	}
+	return secret
+}
+
+// hostsEntry is the entry of a host in a hosts.yml file
+type hostsEntry struct {
+	OAuthToken string `yaml:"oauth_token"`
+}
+
+// hostsFileToken returns the oauth_token of host in a hosts.yml file, the
+// format gh writes when no keychain is available
+func hostsFileToken(path, host string) string {
+	hosts, err := readHostsFile(path)
+	if err != nil {
+		log.Debugf("%v", err)
+		return ""
+	}
+	return hosts[host].OAuthToken
+}
+
+// readHostsFile reads a hosts.yml file; a missing file has no hosts
+func readHostsFile(path string) (map[string]hostsEntry, error) {
+	hosts := map[string]hostsEntry{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return hosts, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, &hosts); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if hosts == nil {
+		hosts = map[string]hostsEntry{}
+	}
+	return hosts, nil
+}
+
+// writeHostsFile writes a hosts.yml file readable by its owner only
+func writeHostsFile(path string, hosts map[string]hostsEntry) error {
+	data, err := yaml.Marshal(hosts)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".hosts-*.yml")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// gitHubCLIConfigDir returns the configuration directory of gh
+func gitHubCLIConfigDir() string {
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh")
+	}
+	if dir := os.Getenv("AppData"); runtime.GOOS == "windows" && dir != "" {
+		return filepath.Join(dir, "GitHub CLI")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gh")
+}
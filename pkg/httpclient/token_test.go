@@ -0,0 +1,153 @@
+package httpclient
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+// resetStoredToken makes GitHubToken look up the stored token again with lookup
+func resetStoredToken(t *testing.T, lookup func(string) (string, string)) {
+	t.Helper()
+	oldLookup := lookupStoredToken
+	reset := func() {
+		storedTokenOnce = sync.Once{}
+		storedTokenVal = ""
+		storedTokenSource = ""
+	}
+	lookupStoredToken = lookup
+	reset()
+	t.Cleanup(func() {
+		lookupStoredToken = oldLookup
+		reset()
+	})
+}
+
+func TestGitHubToken(t *testing.T) {
+	var lookups int
+	resetStoredToken(t, func(host string) (string, string) {
+		lookups++
+		if host != "github.com" {
+			t.Errorf("stored token looked up for %s", host)
+		}
+		return "stored_token", "test"
+	})
+
+	tests := []struct {
+		name       string
+		env        map[string]string
+		want       string
+		wantLookup bool
+	}{
+		{name: "GITHUB_TOKEN", env: map[string]string{"GITHUB_TOKEN": "env_token", "GH_TOKEN": "gh_token"}, want: "env_token"},
+		{name: "GH_TOKEN", env: map[string]string{"GH_TOKEN": "gh_token"}, want: "gh_token"},
+		{name: "stored", want: "stored_token", wantLookup: true},
+		{name: "stored disabled", env: map[string]string{"BINSTALLER_NO_KEYRING": "1"}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"GITHUB_TOKEN", "GH_TOKEN", "BINSTALLER_NO_KEYRING"} {
+				t.Setenv(name, tt.env[name])
+			}
+			before := lookups
+			if got := GitHubToken(); got != tt.want {
+				t.Errorf("GitHubToken() = %q, want %q", got, tt.want)
+			}
+			if got := lookups > before; got != tt.wantLookup {
+				t.Errorf("stored token looked up = %v, want %v", got, tt.wantLookup)
+			}
+		})
+	}
+
+	// The stored token is looked up once per process
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("BINSTALLER_NO_KEYRING", "")
+	GitHubToken()
+	if lookups != 1 {
+		t.Errorf("stored token looked up %d times, want 1", lookups)
+	}
+}
+
+func TestGitHubCLIToken(t *testing.T) {
+	keyring.MockInit()
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "unix:path=/nonexistent")
+	configDir := t.TempDir()
+	t.Setenv("GH_CONFIG_DIR", configDir)
+
+	if got, _ := gitHubCLIToken("github.com"); got != "" {
+		t.Errorf("gitHubCLIToken() without login = %q, want empty", got)
+	}
+
+	hosts := "github.com:\n    user: octocat\n    oauth_token: gho_file\n    git_protocol: https\nghe.example.com:\n    oauth_token: gho_ghe\n"
+	if err := os.WriteFile(filepath.Join(configDir, "hosts.yml"), []byte(hosts), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := gitHubCLIToken("github.com"); got != "gho_file" {
+		t.Errorf("gitHubCLIToken() from hosts.yml = %q, want gho_file", got)
+	}
+
+	if err := keyring.Set("gh:github.com", "", "gho_keyring"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := gitHubCLIToken("github.com"); got != "gho_keyring" {
+		t.Errorf("gitHubCLIToken() from the keychain = %q, want gho_keyring", got)
+	}
+}
+
+func TestLoginToken(t *testing.T) {
+	keyring.MockInit()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("GH_CONFIG_DIR", t.TempDir())
+
+	// Without a keychain the token is stored in a file only its owner can read
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "")
+	if runtime.GOOS == "linux" {
+		where, err := StoreLoginToken("github.com", "gho_file")
+		if err != nil {
+			t.Fatalf("StoreLoginToken() error = %v", err)
+		}
+		if where != LoginTokenFile() {
+			t.Errorf("StoreLoginToken() stored in %q, want %q", where, LoginTokenFile())
+		}
+		info, err := os.Stat(LoginTokenFile())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("token file mode = %v, want 0600", perm)
+		}
+		if got, source := storedToken("github.com"); got != "gho_file" || source != "binst auth login ("+LoginTokenFile()+")" {
+			t.Errorf("storedToken() = %q, %q", got, source)
+		}
+		if deleted, err := DeleteLoginToken("github.com"); err != nil || !deleted {
+			t.Errorf("DeleteLoginToken() = %v, %v", deleted, err)
+		}
+		if _, err := os.Stat(LoginTokenFile()); !os.IsNotExist(err) {
+			t.Errorf("token file not removed: %v", err)
+		}
+	}
+
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "unix:path=/nonexistent")
+	if err := keyring.Set("gh:github.com", "", "gho_gh"); err != nil {
+		t.Fatal(err)
+	}
+	where, err := StoreLoginToken("github.com", "gho_login")
+	if err != nil || where != "the OS keychain" {
+		t.Fatalf("StoreLoginToken() = %q, %v", where, err)
+	}
+	// The login token takes precedence over gh's token
+	if got, source := storedToken("github.com"); got != "gho_login" || source != "binst auth login (OS keychain)" {
+		t.Errorf("storedToken() = %q, %q", got, source)
+	}
+	if deleted, err := DeleteLoginToken("github.com"); err != nil || !deleted {
+		t.Errorf("DeleteLoginToken() = %v, %v", deleted, err)
+	}
+	if got, source := storedToken("github.com"); got != "gho_gh" || source != "gh (OS keychain)" {
+		t.Errorf("storedToken() after logout = %q, %q", got, source)
+	}
+}
@@ -0,0 +1,141 @@
+// Package jsonpath implements the small JSONPath subset used to extract versions
+// from JSON documents: dot notation, bracket notation, and array indexes.
+package jsonpath
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Segment is a single step of a path: an object key or an array index
+type Segment struct {
+	Key   string
+	Index int
+	// IsIndex reports whether the segment is an array index
+	IsIndex bool
+}
+
+// Path is a parsed JSONPath
+type Path []Segment
+
+// identifier matches keys usable in dot notation and as jq identifiers
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// dotKey matches keys accepted in dot notation
+var dotKey = regexp.MustCompile(`^[A-Za-z0-9_-]+`)
+
+// Parse parses a JSONPath such as "$.releases[0].tag" or "$['latest-version']"
+func Parse(path string) (Path, error) {
+	rest, ok := strings.CutPrefix(path, "$")
+	if !ok {
+		return nil, fmt.Errorf("JSONPath must start with '$': %s", path)
+	}
+
+	var p Path
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "."):
+			key := dotKey.FindString(rest[1:])
+			if key == "" {
+				return nil, fmt.Errorf("invalid key in JSONPath %s at %q", path, rest)
+			}
+			p = append(p, Segment{Key: key})
+			rest = rest[1+len(key):]
+		case strings.HasPrefix(rest, "['"):
+			end := strings.Index(rest[2:], "']")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated key in JSONPath %s", path)
+			}
+			key := rest[2 : 2+end]
+			if key == "" || strings.ContainsAny(key, `'"\`) {
+				return nil, fmt.Errorf("invalid key in JSONPath %s: %q", path, key)
+			}
+			p = append(p, Segment{Key: key})
+			rest = rest[2+end+2:]
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated index in JSONPath %s", path)
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid index in JSONPath %s: %q", path, rest[1:end])
+			}
+			p = append(p, Segment{Index: index, IsIndex: true})
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("unexpected %q in JSONPath %s", rest, path)
+		}
+	}
+	if len(p) == 0 {
+		return nil, fmt.Errorf("JSONPath %s selects the whole document", path)
+	}
+	return p, nil
+}
+
+// Eval evaluates the path against a document decoded with encoding/json
+func (p Path) Eval(doc any) (any, error) {
+	current := doc
+	for i, seg := range p {
+		if seg.IsIndex {
+			list, ok := current.([]any)
+			if !ok || seg.Index >= len(list) {
+				return nil, fmt.Errorf("no element [%d] at %s", seg.Index, p[:i])
+			}
+			current = list[seg.Index]
+			continue
+		}
+		obj, ok := current.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("no key %q at %s", seg.Key, p[:i])
+		}
+		current, ok = obj[seg.Key]
+		if !ok {
+			return nil, fmt.Errorf("no key %q at %s", seg.Key, p[:i])
+		}
+	}
+	return current, nil
+}
+
+// JQ returns the equivalent jq filter
+func (p Path) JQ() string {
+	var b strings.Builder
+	for _, seg := range p {
+		switch {
+		case seg.IsIndex:
+			fmt.Fprintf(&b, "[%d]", seg.Index)
+		case identifier.MatchString(seg.Key):
+			b.WriteString("." + seg.Key)
+		default:
+			fmt.Fprintf(&b, `.["%s"]`, seg.Key)
+		}
+	}
+	return b.String()
+}
+
+// LastKey returns the key of the last segment, or empty if the path ends in an array index
+func (p Path) LastKey() string {
+	if len(p) == 0 || p[len(p)-1].IsIndex {
+		return ""
+	}
+	return p[len(p)-1].Key
+}
+
+// String returns the path in JSONPath notation
+func (p Path) String() string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, seg := range p {
+		switch {
+		case seg.IsIndex:
+			fmt.Fprintf(&b, "[%d]", seg.Index)
+		case dotKey.FindString(seg.Key) == seg.Key:
+			b.WriteString("." + seg.Key)
+		default:
+			fmt.Fprintf(&b, "['%s']", seg.Key)
+		}
+	}
+	return b.String()
+}
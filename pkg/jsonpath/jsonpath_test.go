@@ -0,0 +1,77 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseAndEval(t *testing.T) {
+	var doc any
+	if err := json.Unmarshal([]byte(`{
+		"version": "1.2.3",
+		"stable": {"version": "v1.2.0"},
+		"releases": [{"tag": "v2.0.0-rc1"}, {"tag": "v1.9.0"}],
+		"latest-version": "3.0.0",
+		"count": 3
+	}`), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path    string
+		want    any
+		jq      string
+		lastKey string
+	}{
+		{"$.version", "1.2.3", ".version", "version"},
+		{"$.stable.version", "v1.2.0", ".stable.version", "version"},
+		{"$.releases[1].tag", "v1.9.0", ".releases[1].tag", "tag"},
+		{"$['latest-version']", "3.0.0", `.["latest-version"]`, "latest-version"},
+		{"$.latest-version", "3.0.0", `.["latest-version"]`, "latest-version"},
+		{"$.releases[0]", map[string]any{"tag": "v2.0.0-rc1"}, ".releases[0]", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			p, err := Parse(tt.path)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			got, err := p.Eval(doc)
+			if err != nil {
+				t.Fatalf("Eval() error = %v", err)
+			}
+			if gotJSON, _ := json.Marshal(got); string(gotJSON) != mustMarshal(tt.want) {
+				t.Errorf("Eval() = %s, want %s", gotJSON, mustMarshal(tt.want))
+			}
+			if p.JQ() != tt.jq {
+				t.Errorf("JQ() = %q, want %q", p.JQ(), tt.jq)
+			}
+			if p.LastKey() != tt.lastKey {
+				t.Errorf("LastKey() = %q, want %q", p.LastKey(), tt.lastKey)
+			}
+		})
+	}
+
+	for _, path := range []string{"$.missing", "$.version.sub", "$.releases[5].tag", "$.count[0]"} {
+		p, err := Parse(path)
+		if err != nil {
+			t.Fatalf("Parse(%s) error = %v", path, err)
+		}
+		if _, err := p.Eval(doc); err == nil {
+			t.Errorf("Eval(%s) expected error", path)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, path := range []string{"", "$", "version", "$..version", "$[abc]", "$[-1]", "$['a", `$['it''s']`, "$.a b", "$.*"} {
+		if _, err := Parse(path); err == nil {
+			t.Errorf("Parse(%q) expected error", path)
+		}
+	}
+}
+
+func mustMarshal(v any) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
@@ -0,0 +1,133 @@
+// Package lockfile reads and writes binstaller.lock, which pins the release
+// tag and asset digests of every tool of a project for reproducible installs.
+package lockfile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// DefaultPath is the lockfile written by 'binst lock' and read by 'binst install'
+const DefaultPath = "binstaller.lock"
+
+// FormatVersion is the version of the lockfile format
+const FormatVersion = 1
+
+// Lock is the content of a lockfile
+type Lock struct {
+	Version int `json:"version"`
+	// Tools are sorted by name and repo
+	Tools []Tool `json:"tools"`
+}
+
+// Tool is the locked release of one tool
+type Tool struct {
+	Name string `json:"name"`
+	Repo string `json:"repo"`
+	// Requested is the version the tag was resolved from, the spec's
+	// default_version at lock time, e.g. latest
+	Requested string `json:"requested"`
+	Tag       string `json:"tag"`
+	// Assets are the assets of the locked platforms, sorted by platform
+	Assets []Asset `json:"assets,omitempty"`
+}
+
+// Asset is a release asset of one platform and its digest
+type Asset struct {
+	// Platform is os/arch
+	Platform string `json:"platform"`
+	Filename string `json:"filename"`
+	SHA256   string `json:"sha256"`
+}
+
+// Read reads the lockfile at path. A missing file is reported as an error
+// matching os.ErrNotExist.
+func Read(path string) (*Lock, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var l Lock
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil, fmt.Errorf("failed to parse lockfile %s: %w", path, err)
+	}
+	if l.Version != FormatVersion {
+		return nil, fmt.Errorf("unsupported lockfile version %d in %s (supported: %d)", l.Version, path, FormatVersion)
+	}
+	return &l, nil
+}
+
+// ReadOptional reads the lockfile at path, returning nil when it does not exist
+func ReadOptional(path string) (*Lock, error) {
+	l, err := Read(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return l, err
+}
+
+// Write writes the lockfile to path with its tools and assets sorted, so the
+// same lock always produces the same file
+func (l *Lock) Write(path string) error {
+	l.Version = FormatVersion
+	sort.Slice(l.Tools, func(i, j int) bool {
+		if l.Tools[i].Name != l.Tools[j].Name {
+			return l.Tools[i].Name < l.Tools[j].Name
+		}
+		return l.Tools[i].Repo < l.Tools[j].Repo
+	})
+	for _, t := range l.Tools {
+		sort.Slice(t.Assets, func(i, j int) bool {
+			if t.Assets[i].Platform != t.Assets[j].Platform {
+				return t.Assets[i].Platform < t.Assets[j].Platform
+			}
+			return t.Assets[i].Filename < t.Assets[j].Filename
+		})
+	}
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create lockfile directory: %w", err)
+		}
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Tool returns the locked tool with name and repo, or nil
+func (l *Lock) Tool(name, repo string) *Tool {
+	if l == nil {
+		return nil
+	}
+	for i := range l.Tools {
+		if l.Tools[i].Name == name && l.Tools[i].Repo == repo {
+			return &l.Tools[i]
+		}
+	}
+	return nil
+}
+
+// Set adds t to the lock, replacing the tool with the same name and repo
+func (l *Lock) Set(t Tool) {
+	if existing := l.Tool(t.Name, t.Repo); existing != nil {
+		*existing = t
+		return
+	}
+	l.Tools = append(l.Tools, t)
+}
+
+// Asset returns the locked asset with filename, or nil
+func (t *Tool) Asset(filename string) *Asset {
+	for i := range t.Assets {
+		if t.Assets[i].Filename == filename {
+			return &t.Assets[i]
+		}
+	}
+	return nil
+}
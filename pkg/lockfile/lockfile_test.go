@@ -0,0 +1,64 @@
+package lockfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", DefaultPath)
+	l := &Lock{}
+	l.Set(Tool{Name: "gh", Repo: "cli/cli", Requested: "latest", Tag: "v2.0.0", Assets: []Asset{
+		{Platform: "linux/amd64", Filename: "gh_linux_amd64.tar.gz", SHA256: "bb"},
+		{Platform: "darwin/arm64", Filename: "gh_darwin_arm64.zip", SHA256: "aa"},
+	}})
+	l.Set(Tool{Name: "fzf", Repo: "junegunn/fzf", Requested: "latest", Tag: "v0.1.0"})
+	l.Set(Tool{Name: "gh", Repo: "cli/cli", Requested: "latest", Tag: "v2.1.0", Assets: l.Tools[0].Assets})
+	if err := l.Write(path); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	got, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(got.Tools) != 2 || got.Tools[0].Name != "fzf" || got.Tools[1].Tag != "v2.1.0" {
+		t.Fatalf("Read() tools = %+v, want fzf and gh v2.1.0 sorted by name", got.Tools)
+	}
+	gh := got.Tool("gh", "cli/cli")
+	if gh == nil || gh.Assets[0].Platform != "darwin/arm64" {
+		t.Fatalf("Tool(gh) = %+v, want assets sorted by platform", gh)
+	}
+	if a := gh.Asset("gh_linux_amd64.tar.gz"); a == nil || a.SHA256 != "bb" {
+		t.Errorf("Asset() = %+v, want the linux asset", a)
+	}
+	if got.Tool("gh", "other/gh") != nil || gh.Asset("missing") != nil {
+		t.Error("Tool() or Asset() matched an unknown entry")
+	}
+
+	// Writing the same lock again produces the same file
+	first, _ := os.ReadFile(path)
+	if err := got.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	if second, _ := os.ReadFile(path); string(first) != string(second) {
+		t.Errorf("rewritten lockfile differs:\n%s\n%s", first, second)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	dir := t.TempDir()
+	if l, err := ReadOptional(filepath.Join(dir, "missing.lock")); l != nil || err != nil {
+		t.Errorf("ReadOptional(missing) = %v, %v, want nil", l, err)
+	}
+
+	path := filepath.Join(dir, DefaultPath)
+	if err := os.WriteFile(path, []byte(`{"version": 2, "tools": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Read(path); err == nil || !strings.Contains(err.Error(), "unsupported lockfile version 2") {
+		t.Errorf("Read() error = %v, want an unsupported version", err)
+	}
+}
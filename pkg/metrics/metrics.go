@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Default is the registry the binst instrumentation records into
+var Default = NewRegistry()
+
+// Results of checksum verifications
+const (
+	VerificationVerified   = "verified"
+	VerificationUnverified = "unverified"
+	VerificationFailed     = "failed"
+)
+
+// RecordDownload records an asset download of n bytes that took d
+func RecordDownload(d time.Duration, n int64, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	Default.Add("binst_downloads_total", "Asset downloads by result.", 1, "result", result)
+	Default.Add("binst_download_bytes_total", "Bytes of downloaded assets.", float64(n))
+	Default.Observe("binst_download_duration_seconds", "Time spent downloading assets.", d.Seconds(), "result", result)
+}
+
+// RecordVerification records the result of verifying an asset checksum, one of
+// VerificationVerified, VerificationUnverified or VerificationFailed
+func RecordVerification(result string) {
+	Default.Add("binst_checksum_verifications_total", "Asset checksum verifications by result.", 1, "result", result)
+}
+
+// RecordOperation records an operation, such as installing or generating a
+// script for one spec, that took d
+func RecordOperation(operation string, d time.Duration, err error) {
+	if err != nil {
+		Default.Add("binst_failures_total", "Failed operations.", 1, "operation", operation)
+	}
+	Default.Observe("binst_operation_duration_seconds", "Time spent in operations.", d.Seconds(), "operation", operation)
+}
+
+// RecordCommand records a binst command that took d
+func RecordCommand(command string, d time.Duration, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	Default.Observe("binst_command_duration_seconds", "Time spent in binst commands by result.", d.Seconds(), "command", command, "result", result)
+}
+
+// RecordGitHubResponse records a response from GitHub and the rate limit usage
+// reported in its X-RateLimit headers
+func RecordGitHubResponse(resp *http.Response) {
+	Default.Add("binst_github_requests_total", "Requests to GitHub by status code.", 1, "code", strconv.Itoa(resp.StatusCode))
+	resource := resp.Header.Get("X-RateLimit-Resource")
+	if resource == "" {
+		resource = "core"
+	}
+	for header, name := range map[string]string{
+		"X-RateLimit-Limit":     "binst_github_rate_limit",
+		"X-RateLimit-Remaining": "binst_github_rate_limit_remaining",
+		"X-RateLimit-Used":      "binst_github_rate_limit_used",
+	} {
+		v, err := strconv.ParseFloat(resp.Header.Get(header), 64)
+		if err != nil {
+			continue
+		}
+		Default.Set(name, "GitHub API rate limit from the "+header+" header of the last response.", v, "resource", resource)
+	}
+}
+
+// WriteFile writes the metrics of Default to path. The file is replaced
+// atomically, so that collectors such as the node_exporter textfile collector
+// never read a partial file.
+func WriteFile(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".binst-metrics-*")
+	if err != nil {
+		return fmt.Errorf("failed to create metrics file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := Default.Write(tmp); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write metrics file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRegistryWrite(t *testing.T) {
+	r := NewRegistry()
+	r.Add("binst_downloads_total", "Asset downloads by result.", 1, "result", "success")
+	r.Add("binst_downloads_total", "Asset downloads by result.", 2, "result", "success")
+	r.Add("binst_downloads_total", "Asset downloads by result.", 1, "result", "failure")
+	r.Set("binst_github_rate_limit_remaining", "Remaining requests.", 4999, "resource", "core")
+	r.Observe("binst_operation_duration_seconds", "Time spent.", 0.5, "operation", "install")
+	r.Observe("binst_operation_duration_seconds", "Time spent.", 1.25, "operation", "install")
+	r.Add("binst_failures_total", "Failed\noperations.", 1, "operation", `gen "a\b"`)
+
+	var buf bytes.Buffer
+	if err := r.Write(&buf); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	want := `# HELP binst_downloads_total Asset downloads by result.
+# TYPE binst_downloads_total counter
+binst_downloads_total{result="failure"} 1
+binst_downloads_total{result="success"} 3
+# HELP binst_failures_total Failed\noperations.
+# TYPE binst_failures_total counter
+binst_failures_total{operation="gen \"a\\b\""} 1
+# HELP binst_github_rate_limit_remaining Remaining requests.
+# TYPE binst_github_rate_limit_remaining gauge
+binst_github_rate_limit_remaining{resource="core"} 4999
+# HELP binst_operation_duration_seconds Time spent.
+# TYPE binst_operation_duration_seconds summary
+binst_operation_duration_seconds_sum{operation="install"} 1.75
+binst_operation_duration_seconds_count{operation="install"} 2
+`
+	if buf.String() != want {
+		t.Errorf("Write() =\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestRecordGitHubResponseAndWriteFile(t *testing.T) {
+	old := Default
+	Default = NewRegistry()
+	defer func() { Default = old }()
+
+	header := http.Header{}
+	header.Set("X-RateLimit-Limit", "5000")
+	header.Set("X-RateLimit-Remaining", "4321")
+	header.Set("X-RateLimit-Resource", "core")
+	RecordGitHubResponse(&http.Response{StatusCode: http.StatusOK, Header: header})
+	RecordGitHubResponse(&http.Response{StatusCode: http.StatusForbidden, Header: http.Header{}})
+
+	path := filepath.Join(t.TempDir(), "binst.prom")
+	if err := WriteFile(path); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`binst_github_requests_total{code="200"} 1`,
+		`binst_github_requests_total{code="403"} 1`,
+		`binst_github_rate_limit{resource="core"} 5000`,
+		`binst_github_rate_limit_remaining{resource="core"} 4321`,
+	} {
+		if !strings.Contains(string(data), line+"\n") {
+			t.Errorf("metrics file lacks %q:\n%s", line, data)
+		}
+	}
+	if strings.Contains(string(data), "binst_github_rate_limit_used") {
+		t.Errorf("metrics file has a rate limit gauge without a header:\n%s", data)
+	}
+}
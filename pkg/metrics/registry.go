@@ -0,0 +1,142 @@
+// Package metrics collects counters and durations of binst operations and writes
+// them in the Prometheus text exposition format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Metric types of the text exposition format
+const (
+	typeCounter = "counter"
+	typeGauge   = "gauge"
+	typeSummary = "summary"
+)
+
+// Registry holds metric families keyed by name. It is safe for concurrent use.
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]*family
+}
+
+// family is a metric name with its samples keyed by their rendered labels
+type family struct {
+	name    string
+	help    string
+	typ     string
+	samples map[string]*sample
+}
+
+// sample is the value of a counter or gauge, or the sum and count of a summary
+type sample struct {
+	value float64
+	count uint64
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family)}
+}
+
+// Add adds v to the counter name with the given label name/value pairs
+func (r *Registry) Add(name, help string, v float64, labels ...string) {
+	r.update(name, help, typeCounter, labels, func(s *sample) { s.value += v })
+}
+
+// Set sets the gauge name with the given label name/value pairs to v
+func (r *Registry) Set(name, help string, v float64, labels ...string) {
+	r.update(name, help, typeGauge, labels, func(s *sample) { s.value = v })
+}
+
+// Observe records a duration in seconds in the summary name with the given
+// label name/value pairs, exposed as name_sum and name_count
+func (r *Registry) Observe(name, help string, seconds float64, labels ...string) {
+	r.update(name, help, typeSummary, labels, func(s *sample) {
+		s.value += seconds
+		s.count++
+	})
+}
+
+func (r *Registry) update(name, help, typ string, labels []string, fn func(*sample)) {
+	key := renderLabels(labels)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f, ok := r.families[name]
+	if !ok {
+		f = &family{name: name, help: help, typ: typ, samples: make(map[string]*sample)}
+		r.families[name] = f
+	}
+	s, ok := f.samples[key]
+	if !ok {
+		s = &sample{}
+		f.samples[key] = s
+	}
+	fn(s)
+}
+
+// Write writes all metrics to w in the Prometheus text format, sorted by name
+// and labels so that the output is stable
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	bw := bufio.NewWriter(w)
+	for _, name := range names {
+		f := r.families[name]
+		fmt.Fprintf(bw, "# HELP %s %s\n", f.name, helpEscaper.Replace(f.help))
+		fmt.Fprintf(bw, "# TYPE %s %s\n", f.name, f.typ)
+		keys := make([]string, 0, len(f.samples))
+		for key := range f.samples {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			s := f.samples[key]
+			if f.typ == typeSummary {
+				fmt.Fprintf(bw, "%s_sum%s %s\n", f.name, key, formatValue(s.value))
+				fmt.Fprintf(bw, "%s_count%s %d\n", f.name, key, s.count)
+				continue
+			}
+			fmt.Fprintf(bw, "%s%s %s\n", f.name, key, formatValue(s.value))
+		}
+	}
+	return bw.Flush()
+}
+
+// renderLabels renders label name/value pairs as {a="1",b="2"}, or "" without labels
+func renderLabels(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	if len(labels)%2 != 0 {
+		panic("metrics: labels must be name/value pairs")
+	}
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i < len(labels); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, labels[i], labelValueEscaper.Replace(labels[i+1])))
+	}
+	sort.Strings(pairs)
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// labelValueEscaper escapes backslashes, quotes and newlines in label values
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// helpEscaper escapes backslashes and newlines in HELP text
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+// formatValue formats a sample value as the text format expects
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,164 @@
+// Package overlay layers shared and local configuration on top of an InstallSpec.
+//
+// Layers are merged in a fixed order, later layers taking precedence:
+//  1. Organization defaults fetched from $BINSTALLER_DEFAULTS_URL
+//  2. The repository's InstallSpec
+//  3. Local user overrides from ~/.config/binstaller/overrides.yml
+//
+// Mappings are merged key by key; scalars and lists in a later layer replace
+// the earlier value as a whole.
+package overlay
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/httpclient"
+	"github.com/goccy/go-yaml"
+)
+
+const (
+	// EnvDefaultsURL is the URL or path of the organization defaults layer
+	EnvDefaultsURL = "BINSTALLER_DEFAULTS_URL"
+	// EnvOverridesFile overrides the path of the local overrides layer
+	EnvOverridesFile = "BINSTALLER_OVERRIDES"
+)
+
+// Layers locates the configuration layers surrounding a repository spec
+type Layers struct {
+	// DefaultsURL is an http(s) URL or local path of the defaults layer (empty to skip)
+	DefaultsURL string
+	// OverridesFile is the path of the local overrides layer (empty to skip)
+	OverridesFile string
+	// Client fetches DefaultsURL (defaults to httpclient.NewGitHubClient())
+	Client *http.Client
+}
+
+// DefaultLayers returns the layers configured by the environment
+func DefaultLayers() *Layers {
+	return &Layers{
+		DefaultsURL:   os.Getenv(EnvDefaultsURL),
+		OverridesFile: DefaultOverridesFile(),
+	}
+}
+
+// DefaultOverridesFile returns $BINSTALLER_OVERRIDES, or overrides.yml in
+// $XDG_CONFIG_HOME/binstaller (falling back to ~/.config/binstaller)
+func DefaultOverridesFile() string {
+	if path := os.Getenv(EnvOverridesFile); path != "" {
+		return path
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "binstaller", "overrides.yml")
+}
+
+// Apply merges the defaults layer, specYAML, and the overrides layer and returns the merged YAML.
+// A missing overrides file is skipped; a configured defaults URL that cannot be fetched is an error
+// so that fleet policy is never silently dropped.
+func (l *Layers) Apply(ctx context.Context, specYAML []byte) ([]byte, error) {
+	merged := map[string]any{}
+
+	if l.DefaultsURL != "" {
+		data, err := l.fetchDefaults(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load defaults from %s: %w", l.DefaultsURL, err)
+		}
+		if err := mergeYAML(merged, data, l.DefaultsURL); err != nil {
+			return nil, err
+		}
+		log.Debugf("Applied config defaults from %s", l.DefaultsURL)
+	}
+
+	if err := mergeYAML(merged, specYAML, "install spec"); err != nil {
+		return nil, err
+	}
+
+	if l.OverridesFile != "" {
+		data, err := os.ReadFile(l.OverridesFile)
+		switch {
+		case os.IsNotExist(err):
+		case err != nil:
+			return nil, fmt.Errorf("failed to read overrides %s: %w", l.OverridesFile, err)
+		default:
+			if err := mergeYAML(merged, data, l.OverridesFile); err != nil {
+				return nil, err
+			}
+			log.Debugf("Applied config overrides from %s", l.OverridesFile)
+		}
+	}
+
+	out, err := yaml.Marshal(merged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal merged config: %w", err)
+	}
+	return out, nil
+}
+
+// fetchDefaults reads the defaults layer from a URL or local path
+func (l *Layers) fetchDefaults(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(l.DefaultsURL, "https://") && !strings.HasPrefix(l.DefaultsURL, "http://") {
+		return os.ReadFile(strings.TrimPrefix(l.DefaultsURL, "file://"))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", l.DefaultsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	client := l.Client
+	if client == nil {
+		client = httpclient.NewGitHubClient()
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// mergeYAML decodes a YAML mapping and merges it into dst
+func mergeYAML(dst map[string]any, data []byte, source string) error {
+	var layer map[string]any
+	if err := yaml.Unmarshal(data, &layer); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", source, err)
+	}
+	Merge(dst, layer)
+	return nil
+}
+
+// Merge merges src into dst. Nested mappings are merged recursively;
+// any other value in src replaces the value in dst.
+func Merge(dst, src map[string]any) {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]any)
+		dstMap, dstIsMap := dst[key].(map[string]any)
+		if srcIsMap && dstIsMap {
+			Merge(dstMap, srcMap)
+			continue
+		}
+		if srcIsMap {
+			// Copy so later merges never modify the source layer
+			copied := map[string]any{}
+			Merge(copied, srcMap)
+			value = copied
+		}
+		dst[key] = value
+	}
+}
@@ -0,0 +1,135 @@
+package overlay
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/goccy/go-yaml"
+)
+
+func TestMerge(t *testing.T) {
+	dst := map[string]any{
+		"name": "tool",
+		"asset": map[string]any{
+			"template": "${NAME}_${OS}.tar.gz",
+			"rules":    []any{"a", "b"},
+		},
+	}
+	src := map[string]any{
+		"asset": map[string]any{
+			"rules": []any{"c"},
+		},
+		"checksums": map[string]any{"algorithm": "sha512"},
+	}
+	Merge(dst, src)
+
+	want := map[string]any{
+		"name": "tool",
+		"asset": map[string]any{
+			"template": "${NAME}_${OS}.tar.gz",
+			"rules":    []any{"c"},
+		},
+		"checksums": map[string]any{"algorithm": "sha512"},
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("Merge() = %#v, want %#v", dst, want)
+	}
+
+	// Later merges must not modify the source layer
+	Merge(dst, map[string]any{"checksums": map[string]any{"template": "sums.txt"}})
+	if len(src["checksums"].(map[string]any)) != 1 {
+		t.Errorf("Merge() modified source layer: %#v", src)
+	}
+}
+
+func TestApply(t *testing.T) {
+	defaults := `
+default_bin_dir: /opt/org/bin
+checksums:
+  algorithm: sha512
+  template: org-checksums.txt
+`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(defaults))
+	}))
+	defer srv.Close()
+
+	overrides := filepath.Join(t.TempDir(), "overrides.yml")
+	if err := os.WriteFile(overrides, []byte("default_bin_dir: /home/me/bin\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	specYAML := []byte(`
+repo: owner/tool
+checksums:
+  template: ${NAME}_checksums.txt
+`)
+	layers := &Layers{DefaultsURL: srv.URL, OverridesFile: overrides, Client: srv.Client()}
+	merged, err := layers.Apply(context.Background(), specYAML)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	var got spec.InstallSpec
+	if err := yaml.Unmarshal(merged, &got); err != nil {
+		t.Fatalf("failed to parse merged YAML: %v\n%s", err, merged)
+	}
+	if v := spec.StringValue(got.Repo); v != "owner/tool" {
+		t.Errorf("repo = %q", v)
+	}
+	if v := spec.StringValue(got.DefaultBinDir); v != "/home/me/bin" {
+		t.Errorf("default_bin_dir = %q, want user override", v)
+	}
+	if v := got.GetChecksums().GetTemplate(); v != "${NAME}_checksums.txt" {
+		t.Errorf("checksums.template = %q, want repo value", v)
+	}
+	if got.Checksums.Algorithm == nil || *got.Checksums.Algorithm != spec.Sha512 {
+		t.Errorf("checksums.algorithm = %v, want org default sha512", got.Checksums.Algorithm)
+	}
+
+	// Merging is deterministic
+	again, err := layers.Apply(context.Background(), specYAML)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if string(again) != string(merged) {
+		t.Errorf("Apply() is not deterministic:\n%s\n---\n%s", merged, again)
+	}
+}
+
+func TestApplyMissingLayers(t *testing.T) {
+	layers := &Layers{OverridesFile: filepath.Join(t.TempDir(), "missing.yml")}
+	merged, err := layers.Apply(context.Background(), []byte("repo: owner/tool\n"))
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if string(merged) != "repo: owner/tool\n" {
+		t.Errorf("Apply() = %q", merged)
+	}
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	layers = &Layers{DefaultsURL: srv.URL, Client: srv.Client()}
+	if _, err := layers.Apply(context.Background(), []byte("repo: owner/tool\n")); err == nil {
+		t.Error("Apply() expected error when defaults URL cannot be fetched")
+	}
+}
+
+func TestDefaultOverridesFile(t *testing.T) {
+	t.Setenv(EnvOverridesFile, "/etc/binstaller/overrides.yml")
+	if got := DefaultOverridesFile(); got != "/etc/binstaller/overrides.yml" {
+		t.Errorf("DefaultOverridesFile() = %q", got)
+	}
+
+	t.Setenv(EnvOverridesFile, "")
+	t.Setenv("XDG_CONFIG_HOME", "/xdg")
+	if got, want := DefaultOverridesFile(), filepath.Join("/xdg", "binstaller", "overrides.yml"); got != want {
+		t.Errorf("DefaultOverridesFile() = %q, want %q", got, want)
+	}
+}
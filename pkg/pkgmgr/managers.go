@@ -0,0 +1,173 @@
+package pkgmgr
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+// brew probes Homebrew formulae
+type brew struct{}
+
+func (brew) Command() string { return "brew" }
+
+func (brew) Probe(ctx context.Context, run Runner, name string) (*Package, error) {
+	out, err := run(ctx, "brew", "info", "--json=v2", "--formula", name)
+	if err != nil {
+		// brew exits non-zero for unknown formulae
+		return nil, nil
+	}
+	var info struct {
+		Formulae []struct {
+			Versions struct {
+				Stable string `json:"stable"`
+			} `json:"versions"`
+			Installed []struct {
+				Version string `json:"version"`
+			} `json:"installed"`
+		} `json:"formulae"`
+	}
+	if err := json.Unmarshal(out, &info); err != nil {
+		return nil, err
+	}
+	if len(info.Formulae) == 0 {
+		return nil, nil
+	}
+	f := info.Formulae[0]
+	pkg := &Package{Manager: "brew", Name: name, Version: f.Versions.Stable, InstallCommand: "brew install " + name}
+	if len(f.Installed) > 0 {
+		// Strip the bottle revision, e.g. 1.7.1_1
+		version, _, _ := strings.Cut(f.Installed[len(f.Installed)-1].Version, "_")
+		pkg.Version, pkg.Installed = version, true
+	}
+	return pkg, nil
+}
+
+// apt probes Debian and Ubuntu packages
+type apt struct{}
+
+func (apt) Command() string { return "apt-cache" }
+
+func (apt) Probe(ctx context.Context, run Runner, name string) (*Package, error) {
+	out, err := run(ctx, "apt-cache", "policy", name)
+	if err != nil {
+		return nil, err
+	}
+	var installed, candidate string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		if v, ok := fieldValue(scanner.Text(), "Installed"); ok && installed == "" {
+			installed = v
+		}
+		if v, ok := fieldValue(scanner.Text(), "Candidate"); ok && candidate == "" {
+			candidate = v
+		}
+	}
+	pkg := &Package{Manager: "apt", Name: name, InstallCommand: "sudo apt-get install " + name}
+	switch {
+	case installed != "" && installed != "(none)":
+		pkg.Version, pkg.Installed = upstreamVersion(installed), true
+	case candidate != "" && candidate != "(none)":
+		pkg.Version = upstreamVersion(candidate)
+	default:
+		return nil, nil
+	}
+	return pkg, nil
+}
+
+// apk probes Alpine packages
+type apk struct{}
+
+func (apk) Command() string { return "apk" }
+
+func (apk) Probe(ctx context.Context, run Runner, name string) (*Package, error) {
+	out, err := run(ctx, "apk", "policy", name)
+	if err != nil {
+		return nil, err
+	}
+	// NAME policy:
+	//   1.7.1-r0:
+	//     lib/apk/db/installed
+	//     https://dl-cdn.alpinelinux.org/alpine/v3.20/main
+	var pkg *Package
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "    "):
+			if pkg != nil && trimmed == "lib/apk/db/installed" {
+				pkg.Installed = true
+				return pkg, nil
+			}
+		case strings.HasPrefix(line, "  ") && strings.HasSuffix(trimmed, ":"):
+			if pkg != nil {
+				// Only the first (newest) version is a candidate
+				continue
+			}
+			pkg = &Package{Manager: "apk", Name: name, Version: upstreamVersion(strings.TrimSuffix(trimmed, ":")), InstallCommand: "sudo apk add " + name}
+		}
+	}
+	return pkg, nil
+}
+
+// dnf probes Fedora and RHEL packages
+type dnf struct{}
+
+func (dnf) Command() string { return "dnf" }
+
+func (dnf) Probe(ctx context.Context, run Runner, name string) (*Package, error) {
+	out, err := run(ctx, "dnf", "info", "--quiet", name)
+	if err != nil {
+		// dnf exits non-zero for unknown packages
+		return nil, nil
+	}
+	var pkg *Package
+	installedSection := false
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasSuffix(line, "Packages") {
+			installedSection = strings.HasPrefix(line, "Installed")
+			continue
+		}
+		v, ok := fieldValue(line, "Version")
+		if !ok {
+			continue
+		}
+		if pkg == nil || (installedSection && !pkg.Installed) {
+			pkg = &Package{Manager: "dnf", Name: name, Version: v, Installed: installedSection, InstallCommand: "sudo dnf install " + name}
+		}
+	}
+	return pkg, nil
+}
+
+// pacman probes Arch Linux packages
+type pacman struct{}
+
+func (pacman) Command() string { return "pacman" }
+
+func (pacman) Probe(ctx context.Context, run Runner, name string) (*Package, error) {
+	pkg := &Package{Manager: "pacman", Name: name, InstallCommand: "sudo pacman -S " + name}
+	// pacman -Q prints "NAME VERSION" for installed packages
+	if out, err := run(ctx, "pacman", "-Q", name); err == nil {
+		if fields := strings.Fields(string(out)); len(fields) == 2 {
+			pkg.Version, pkg.Installed = upstreamVersion(fields[1]), true
+			return pkg, nil
+		}
+	}
+	out, err := run(ctx, "pacman", "-Si", name)
+	if err != nil {
+		return nil, nil
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		if v, ok := fieldValue(scanner.Text(), "Version"); ok {
+			pkg.Version = upstreamVersion(v)
+			return pkg, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,87 @@
+package pkgmgr
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+)
+
+// Owner is a Manager that can tell which package installed a file
+type Owner interface {
+	// Owns returns the installed package that provides path, or nil when none does
+	Owns(ctx context.Context, run Runner, path string) (*Package, error)
+}
+
+// FindOwner returns the installed package that provides the file at path, or
+// nil when no available package manager installed it
+func (p *Prober) FindOwner(ctx context.Context, path string) *Package {
+	for _, m := range p.Managers {
+		owner, ok := m.(Owner)
+		if !ok {
+			continue
+		}
+		if _, err := p.LookPath(m.Command()); err != nil {
+			continue
+		}
+		pkg, err := owner.Owns(ctx, p.Run, path)
+		if err != nil {
+			log.Debugf("%s: failed to find the owner of %s: %v", m.Command(), path, err)
+			continue
+		}
+		if pkg != nil {
+			return pkg
+		}
+	}
+	return nil
+}
+
+// Owns finds the formula or cask of a file linked from the Homebrew Cellar or Caskroom
+func (brew) Owns(ctx context.Context, run Runner, path string) (*Package, error) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return nil, err
+	}
+	resolved = filepath.ToSlash(resolved)
+	for _, dir := range []string{"/Cellar/", "/Caskroom/"} {
+		if _, rest, ok := strings.Cut(resolved, dir); ok {
+			name, _, _ := strings.Cut(rest, "/")
+			return &Package{Manager: "brew", Name: name, Installed: true}, nil
+		}
+	}
+	return nil, nil
+}
+
+// Owns asks dpkg which package installed a file, also trying the file's real
+// path since merged-/usr systems register /usr/bin rather than /bin
+func (apt) Owns(ctx context.Context, run Runner, path string) (*Package, error) {
+	paths := []string{path}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil && resolved != path {
+		paths = append(paths, resolved)
+	}
+	for _, p := range paths {
+		// dpkg -S prints "PACKAGE[:ARCH][, PACKAGE...]: PATH" and exits non-zero for unknown files
+		out, err := run(ctx, "dpkg", "-S", p)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(out))
+		for scanner.Scan() {
+			line := scanner.Text()
+			if strings.HasPrefix(line, "diversion ") {
+				continue
+			}
+			packages, _, ok := strings.Cut(line, ": ")
+			if !ok {
+				continue
+			}
+			name, _, _ := strings.Cut(packages, ", ")
+			name, _, _ = strings.Cut(name, ":")
+			return &Package{Manager: "apt", Name: name, Installed: true}, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,48 @@
+package pkgmgr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindOwner(t *testing.T) {
+	dir := t.TempDir()
+	cellar := filepath.Join(dir, "Cellar", "jq", "1.7.1", "bin")
+	if err := os.MkdirAll(cellar, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(cellar, "jq"), nil, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	bin := filepath.Join(dir, "bin")
+	if err := os.MkdirAll(bin, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(cellar, "jq"), filepath.Join(bin, "jq")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(bin, "rg"), nil, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	prober := &Prober{
+		Managers: []Manager{brew{}, apt{}, apk{}},
+		LookPath: func(file string) (string, error) { return file, nil },
+		Run: fakeRunner(map[string]string{
+			"dpkg -S " + filepath.Join(bin, "rg"): "diversion by foo from: /usr/bin/rg\nripgrep:amd64: /usr/bin/rg\n",
+		}),
+	}
+	tests := map[string]*Package{
+		filepath.Join(bin, "jq"):    {Manager: "brew", Name: "jq", Installed: true},
+		filepath.Join(bin, "rg"):    {Manager: "apt", Name: "ripgrep", Installed: true},
+		filepath.Join(bin, "other"): nil,
+	}
+	for path, want := range tests {
+		got := prober.FindOwner(context.Background(), path)
+		if (got == nil) != (want == nil) || (got != nil && *got != *want) {
+			t.Errorf("FindOwner(%s) = %+v, want %+v", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// Package pkgmgr probes the host's package managers for the version of a package
+// they have installed or would install, so binst can defer to the system package.
+package pkgmgr
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/apex/log"
+)
+
+// Package is a package known to a package manager
+type Package struct {
+	Manager string
+	Name    string
+	// Version is the upstream version, without epoch or distribution revision
+	Version   string
+	Installed bool
+	// InstallCommand installs the package with the package manager
+	InstallCommand string
+}
+
+// Runner runs a command and returns its standard output
+type Runner func(ctx context.Context, name string, args ...string) ([]byte, error)
+
+// Manager probes one package manager
+type Manager interface {
+	// Command is the package manager executable, used to detect whether it is available
+	Command() string
+	// Probe returns the installed or candidate package, or nil when the manager has no such package
+	Probe(ctx context.Context, run Runner, name string) (*Package, error)
+}
+
+var (
+	mu       sync.Mutex
+	managers []Manager
+)
+
+// Register adds a package manager; managers are probed in registration order
+func Register(m Manager) {
+	mu.Lock()
+	defer mu.Unlock()
+	managers = append(managers, m)
+}
+
+// Managers returns the registered package managers
+func Managers() []Manager {
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]Manager(nil), managers...)
+}
+
+func init() {
+	Register(brew{})
+	Register(apt{})
+	Register(apk{})
+	Register(dnf{})
+	Register(pacman{})
+}
+
+// Prober finds a package in the package managers available on the host
+type Prober struct {
+	Managers []Manager
+	LookPath func(file string) (string, error)
+	Run      Runner
+}
+
+// NewProber returns a prober for the registered package managers
+func NewProber() *Prober {
+	return &Prober{Managers: Managers(), LookPath: exec.LookPath, Run: runCommand}
+}
+
+// Find returns the package from every available package manager that has it
+func (p *Prober) Find(ctx context.Context, name string) []Package {
+	var found []Package
+	for _, m := range p.Managers {
+		if _, err := p.LookPath(m.Command()); err != nil {
+			continue
+		}
+		pkg, err := m.Probe(ctx, p.Run, name)
+		if err != nil {
+			log.Debugf("%s: failed to probe %s: %v", m.Command(), name, err)
+			continue
+		}
+		if pkg != nil && pkg.Version != "" {
+			found = append(found, *pkg)
+		}
+	}
+	return found
+}
+
+// FindVersion returns the first package at version (with or without a 'v' prefix)
+func (p *Prober) FindVersion(ctx context.Context, name, version string) *Package {
+	version = strings.TrimPrefix(version, "v")
+	for _, pkg := range p.Find(ctx, name) {
+		if pkg.Version == version {
+			return &pkg
+		}
+	}
+	return nil
+}
+
+func runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, name, args...).Output()
+}
+
+// upstreamVersion strips the epoch ("1:") and the distribution revision ("-1ubuntu2", "-r0")
+func upstreamVersion(v string) string {
+	if i := strings.Index(v, ":"); i >= 0 {
+		v = v[i+1:]
+	}
+	if i := strings.LastIndex(v, "-"); i > 0 {
+		v = v[:i]
+	}
+	return v
+}
+
+// fieldValue returns the value of a "Key : value" line
+func fieldValue(line, key string) (string, bool) {
+	k, v, ok := strings.Cut(line, ":")
+	if !ok || strings.TrimSpace(k) != key {
+		return "", false
+	}
+	return strings.TrimSpace(v), true
+}
@@ -0,0 +1,104 @@
+package pkgmgr
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeRunner returns canned output for each command line
+func fakeRunner(outputs map[string]string) Runner {
+	return func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		out, ok := outputs[strings.Join(append([]string{name}, args...), " ")]
+		if !ok {
+			return nil, errors.New("exit status 1")
+		}
+		return []byte(out), nil
+	}
+}
+
+func TestProbe(t *testing.T) {
+	tests := []struct {
+		name    string
+		manager Manager
+		outputs map[string]string
+		want    *Package
+	}{
+		{
+			name:    "apt installed",
+			manager: apt{},
+			outputs: map[string]string{"apt-cache policy jq": "jq:\n  Installed: 1.7.1-3build1\n  Candidate: 1.7.1-3build1\n  Version table:\n"},
+			want:    &Package{Manager: "apt", Name: "jq", Version: "1.7.1", Installed: true, InstallCommand: "sudo apt-get install jq"},
+		},
+		{
+			name:    "apt candidate with epoch",
+			manager: apt{},
+			outputs: map[string]string{"apt-cache policy jq": "jq:\n  Installed: (none)\n  Candidate: 1:1.7.1-3\n"},
+			want:    &Package{Manager: "apt", Name: "jq", Version: "1.7.1", InstallCommand: "sudo apt-get install jq"},
+		},
+		{
+			name:    "apt unknown package",
+			manager: apt{},
+			outputs: map[string]string{"apt-cache policy jq": ""},
+		},
+		{
+			name:    "apk installed",
+			manager: apk{},
+			outputs: map[string]string{"apk policy jq": "jq policy:\n  1.7.1-r0:\n    lib/apk/db/installed\n    https://dl-cdn.alpinelinux.org/alpine/v3.20/main\n"},
+			want:    &Package{Manager: "apk", Name: "jq", Version: "1.7.1", Installed: true, InstallCommand: "sudo apk add jq"},
+		},
+		{
+			name:    "brew installed with revision",
+			manager: brew{},
+			outputs: map[string]string{"brew info --json=v2 --formula jq": `{"formulae":[{"versions":{"stable":"1.7.1"},"installed":[{"version":"1.7.1_1"}]}]}`},
+			want:    &Package{Manager: "brew", Name: "jq", Version: "1.7.1", Installed: true, InstallCommand: "brew install jq"},
+		},
+		{
+			name:    "dnf available",
+			manager: dnf{},
+			outputs: map[string]string{"dnf info --quiet jq": "Available Packages\nName         : jq\nVersion      : 1.7.1\nRelease      : 8.fc40\n"},
+			want:    &Package{Manager: "dnf", Name: "jq", Version: "1.7.1", InstallCommand: "sudo dnf install jq"},
+		},
+		{
+			name:    "pacman sync database",
+			manager: pacman{},
+			outputs: map[string]string{"pacman -Si jq": "Repository      : extra\nName            : jq\nVersion         : 1.7.1-2\n"},
+			want:    &Package{Manager: "pacman", Name: "jq", Version: "1.7.1", InstallCommand: "sudo pacman -S jq"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.manager.Probe(context.Background(), fakeRunner(tt.outputs), "jq")
+			if err != nil {
+				t.Fatalf("Probe() error = %v", err)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("Probe() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindVersion(t *testing.T) {
+	p := &Prober{
+		Managers: []Manager{brew{}, apt{}},
+		LookPath: func(file string) (string, error) {
+			if file == "brew" {
+				return "", errors.New("not found")
+			}
+			return "/usr/bin/" + file, nil
+		},
+		Run: fakeRunner(map[string]string{
+			"apt-cache policy jq": "jq:\n  Installed: (none)\n  Candidate: 1.7.1-3\n",
+			// Never probed because brew is not on PATH
+			"brew info --json=v2 --formula jq": `{"formulae":[{"versions":{"stable":"1.8.0"}}]}`,
+		}),
+	}
+	if got := p.FindVersion(context.Background(), "jq", "v1.7.1"); got == nil || got.Manager != "apt" {
+		t.Errorf("FindVersion(v1.7.1) = %+v, want the apt package", got)
+	}
+	if got := p.FindVersion(context.Background(), "jq", "1.8.0"); got != nil {
+		t.Errorf("FindVersion(1.8.0) = %+v, want nil", got)
+	}
+}
@@ -0,0 +1,281 @@
+// Package receipt records what 'binst install' installed, one JSON receipt per
+// tool, so later commands know the installed version, binaries and runtime
+// environment of a tool.
+package receipt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/binary-install/binstaller/pkg/filelock"
+)
+
+const (
+	// EnvDir overrides the receipts directory
+	EnvDir = "BINSTALLER_RECEIPTS_DIR"
+	// EnvSystemDir overrides the system-wide receipts directory
+	EnvSystemDir = "BINSTALLER_SYSTEM_RECEIPTS_DIR"
+)
+
+// Dir returns the directory holding receipts.
+// It uses $BINSTALLER_RECEIPTS_DIR if set, otherwise <user config dir>/binstaller/receipts.
+func Dir() (string, error) {
+	if dir := os.Getenv(EnvDir); dir != "" {
+		return dir, nil
+	}
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine user config directory: %w", err)
+	}
+	return filepath.Join(base, "binstaller", "receipts"), nil
+}
+
+// SystemDir returns the directory holding the receipts of tools installed into
+// shared locations. It uses $BINSTALLER_SYSTEM_RECEIPTS_DIR if set, otherwise
+// /var/lib/binstaller/receipts (%ProgramData%\binstaller\receipts on Windows).
+func SystemDir() string {
+	if dir := os.Getenv(EnvSystemDir); dir != "" {
+		return dir
+	}
+	if runtime.GOOS == "windows" {
+		base := os.Getenv("ProgramData")
+		if base == "" {
+			base = `C:\ProgramData`
+		}
+		return filepath.Join(base, "binstaller", "receipts")
+	}
+	return "/var/lib/binstaller/receipts"
+}
+
+// Shared reports whether binDir is a shared location, outside the home
+// directory of the current user
+func Shared(binDir string) bool {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return true
+	}
+	abs, err := filepath.Abs(binDir)
+	if err != nil {
+		return true
+	}
+	rel, err := filepath.Rel(home, abs)
+	return err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// ProjectDir is the directory of a project holding the tools installed for it,
+// in ProjectDir/bin, and their receipts, in ProjectDir/receipts
+const ProjectDir = ".binstaller"
+
+// ProjectStore returns the Store of the tools installed into binDir when it is
+// the bin directory of a project (PROJECT/.binstaller/bin), or nil otherwise.
+// Project tools are recorded in the project so they do not replace the
+// receipts of the user's own installs of the same tools.
+func ProjectStore(binDir string) *Store {
+	abs, err := filepath.Abs(binDir)
+	if err != nil || filepath.Base(abs) != "bin" || filepath.Base(filepath.Dir(abs)) != ProjectDir {
+		return nil
+	}
+	return &Store{Dir: filepath.Join(filepath.Dir(abs), "receipts")}
+}
+
+// ForBinDir returns the Store for tools installed into binDir: the store of
+// the project for a project bin directory (see ProjectStore), the store in
+// SystemDir() for shared locations, unless $BINSTALLER_RECEIPTS_DIR is set,
+// and the store in Dir() otherwise
+func ForBinDir(binDir string) (*Store, error) {
+	if store := ProjectStore(binDir); store != nil {
+		return store, nil
+	}
+	if os.Getenv(EnvDir) == "" && Shared(binDir) {
+		return &Store{Dir: SystemDir()}, nil
+	}
+	return New()
+}
+
+// Receipt describes one installed tool
+type Receipt struct {
+	Name string `json:"name"`
+	Repo string `json:"repo"`
+	Tag  string `json:"tag"`
+	// BinDir is the absolute directory the binaries were installed into
+	BinDir   string   `json:"bin_dir"`
+	Binaries []string `json:"binaries"`
+	// RuntimeEnv is the runtime_env of the spec expanded for BinDir
+	RuntimeEnv  map[string]string `json:"runtime_env,omitempty"`
+	InstalledAt time.Time         `json:"installed_at"`
+}
+
+// Installed reports whether a binary of the receipt is still in BinDir. A
+// receipt without binaries is always considered installed.
+func (r *Receipt) Installed() bool {
+	if len(r.Binaries) == 0 {
+		return true
+	}
+	for _, binary := range r.Binaries {
+		for _, name := range []string{binary, binary + ".exe"} {
+			if _, err := os.Stat(filepath.Join(r.BinDir, name)); err == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Store reads and writes receipts in a directory
+type Store struct {
+	Dir string
+}
+
+// New returns a Store in Dir()
+func New() (*Store, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	return &Store{Dir: dir}, nil
+}
+
+// path returns the receipt file of the tool named name
+func (s *Store) path(name string) (string, error) {
+	if name == "" || name == "." || name == ".." || filepath.Base(name) != name {
+		return "", fmt.Errorf("invalid tool name for a receipt: %q", name)
+	}
+	return filepath.Join(s.Dir, name+".json"), nil
+}
+
+// Write records r, replacing the receipt of a previous install of the tool
+func (s *Store) Write(r *Receipt) error {
+	path, err := s.path(r.Name)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create receipts directory: %w", err)
+	}
+	// Write to a temporary file first so a receipt is never left half written
+	tmp, err := os.CreateTemp(s.Dir, "."+r.Name+"-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write receipt: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	// Receipts in a shared store are read by every user
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write receipt: %w", err)
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write receipt: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write receipt: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write receipt: %w", err)
+	}
+	return nil
+}
+
+// Read returns the receipt of the tool named name, or nil when it has none
+func (s *Store) Read(name string) (*Receipt, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var r Receipt
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse receipt %s: %w", path, err)
+	}
+	return &r, nil
+}
+
+// Remove deletes the receipt of the tool named name
+func (s *Store) Remove(name string) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove receipt: %w", err)
+	}
+	return nil
+}
+
+// List returns the receipts of every tool in the store, ordered by name
+func (s *Store) List() ([]*Receipt, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var receipts []*Receipt
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		// Skip lock files and the temporary files of receipts being written
+		if !ok || entry.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		r, err := s.Read(name)
+		if err != nil {
+			return nil, err
+		}
+		if r != nil {
+			receipts = append(receipts, r)
+		}
+	}
+	return receipts, nil
+}
+
+// Lock takes the install lock of the tool named name, waiting while another
+// process holds it, so concurrent installs of a tool do not race on its
+// binaries and receipt
+func (s *Store) Lock(ctx context.Context, name string) (*filelock.Lock, error) {
+	path, err := s.lockPath(name)
+	if err != nil {
+		return nil, err
+	}
+	return filelock.Acquire(ctx, path)
+}
+
+// TryLock takes the install lock of the tool named name like Lock, but returns
+// filelock.ErrLocked instead of waiting while another process holds it
+func (s *Store) TryLock(name string) (*filelock.Lock, error) {
+	path, err := s.lockPath(name)
+	if err != nil {
+		return nil, err
+	}
+	return filelock.TryAcquire(path)
+}
+
+// lockPath returns the lock file of the tool named name, creating the
+// receipts directory
+func (s *Store) lockPath(name string) (string, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create receipts directory: %w", err)
+	}
+	return strings.TrimSuffix(path, ".json") + ".lock", nil
+}
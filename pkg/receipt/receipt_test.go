@@ -0,0 +1,174 @@
+package receipt
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/binary-install/binstaller/pkg/filelock"
+)
+
+func TestStore(t *testing.T) {
+	store := &Store{Dir: filepath.Join(t.TempDir(), "receipts")}
+	if r, err := store.Read("tool"); err != nil || r != nil {
+		t.Fatalf("Read() of a missing receipt = %v, %v, want nil", r, err)
+	}
+
+	want := &Receipt{
+		Name:        "tool",
+		Repo:        "owner/tool",
+		Tag:         "v1.0.0",
+		BinDir:      "/opt/tool/bin",
+		Binaries:    []string{"tool"},
+		RuntimeEnv:  map[string]string{"TOOL_HOME": "/opt/tool/share/tool"},
+		InstalledAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	if err := store.Write(want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.Read("tool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Tag != want.Tag || got.BinDir != want.BinDir || got.RuntimeEnv["TOOL_HOME"] != want.RuntimeEnv["TOOL_HOME"] || !got.InstalledAt.Equal(want.InstalledAt) {
+		t.Errorf("Read() = %+v, want %+v", got, want)
+	}
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(store.Dir)
+	if err != nil || len(entries) != 1 {
+		t.Errorf("receipts directory has %v, %v, want only tool.json", entries, err)
+	}
+
+	if err := store.Write(&Receipt{Name: "../escape"}); err == nil {
+		t.Error("Write() accepted a name outside the receipts directory")
+	}
+}
+
+func TestForBinDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("AppData", filepath.Join(home, "AppData"))
+	t.Setenv(EnvDir, "")
+	systemDir := filepath.Join(t.TempDir(), "system")
+	t.Setenv(EnvSystemDir, systemDir)
+
+	store, err := ForBinDir(filepath.Join(home, ".local", "bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.Dir == systemDir {
+		t.Errorf("ForBinDir() of a bin dir in the home directory = %s, want the user store", store.Dir)
+	}
+
+	store, err = ForBinDir(filepath.Join(t.TempDir(), "shared", "bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.Dir != systemDir {
+		t.Errorf("ForBinDir() of a shared bin dir = %s, want %s", store.Dir, systemDir)
+	}
+
+	// $BINSTALLER_RECEIPTS_DIR takes the receipts of every tool outside projects
+	userDir := t.TempDir()
+	t.Setenv(EnvDir, userDir)
+	store, err = ForBinDir(filepath.Join(t.TempDir(), "shared", "bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.Dir != userDir {
+		t.Errorf("ForBinDir() with %s set = %s, want %s", EnvDir, store.Dir, userDir)
+	}
+
+	// Project tools are recorded in the project
+	project := t.TempDir()
+	store, err = ForBinDir(filepath.Join(project, ProjectDir, "bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(project, ProjectDir, "receipts"); store.Dir != want {
+		t.Errorf("ForBinDir() of a project bin dir = %s, want %s", store.Dir, want)
+	}
+}
+
+func TestStoreList(t *testing.T) {
+	store := &Store{Dir: filepath.Join(t.TempDir(), "receipts")}
+	if receipts, err := store.List(); err != nil || len(receipts) != 0 {
+		t.Fatalf("List() of a missing store = %v, %v, want none", receipts, err)
+	}
+	for _, name := range []string{"zig", "act"} {
+		if err := store.Write(&Receipt{Name: name, Tag: "v1.0.0"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lock, err := store.Lock(context.Background(), "act")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Release()
+
+	receipts, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(receipts) != 2 || receipts[0].Name != "act" || receipts[1].Name != "zig" {
+		t.Errorf("List() = %+v, want the receipts of act and zig", receipts)
+	}
+
+	if err := store.Remove("zig"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if receipts, err := store.List(); err != nil || len(receipts) != 1 || receipts[0].Name != "act" {
+		t.Errorf("List() after Remove() = %+v, %v, want the receipt of act", receipts, err)
+	}
+}
+
+func TestStoreLock(t *testing.T) {
+	store := &Store{Dir: filepath.Join(t.TempDir(), "receipts")}
+	lock, err := store.Lock(context.Background(), "tool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.TryLock("tool"); !errors.Is(err, filelock.ErrLocked) {
+		t.Errorf("TryLock() of a locked tool = %v, want filelock.ErrLocked", err)
+	}
+	other, err := store.TryLock("other")
+	if err != nil {
+		t.Fatalf("TryLock() of another tool: %v", err)
+	}
+	if err := other.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Lock(context.Background(), "../escape"); err == nil {
+		t.Error("Lock() accepted a name outside the receipts directory")
+	}
+}
+
+func TestReceiptInstalled(t *testing.T) {
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "tool.exe"), nil, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		binaries []string
+		want     bool
+	}{
+		{binaries: nil, want: true},
+		{binaries: []string{"tool"}, want: true},
+		{binaries: []string{"other", "tool"}, want: true},
+		{binaries: []string{"other"}, want: false},
+	}
+	for _, tt := range tests {
+		r := &Receipt{Name: "tool", BinDir: binDir, Binaries: tt.binaries}
+		if got := r.Installed(); got != tt.want {
+			t.Errorf("Installed() with binaries %v = %v, want %v", tt.binaries, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Release channels
+const (
+	ChannelStable = "stable"
+	ChannelBeta   = "beta"
+)
+
+// Channels are the release channels that can be resolved
+var Channels = []string{ChannelStable, ChannelBeta}
+
+// Channel returns the tag a release channel points to: stable is the newest
+// release that is not a pre-release, and beta the newest pre-release that is
+// newer than stable, or stable itself when there is none
+func (r *Resolver) Channel(ctx context.Context, channel string) (string, error) {
+	if channel != ChannelStable && channel != ChannelBeta {
+		return "", fmt.Errorf("unknown channel %q: must be one of %s", channel, strings.Join(Channels, ", "))
+	}
+	releases, err := r.Releases(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	beta := ""
+	for _, release := range releases {
+		if !release.Prerelease {
+			if channel == ChannelBeta && beta != "" {
+				return beta, nil
+			}
+			return release.Tag, nil
+		}
+		if beta == "" {
+			beta = release.Tag
+		}
+	}
+	if channel == ChannelBeta && beta != "" {
+		return beta, nil
+	}
+	return "", fmt.Errorf("no %s release found for %s", channel, r.Spec.GetRepo())
+}
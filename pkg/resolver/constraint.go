@@ -0,0 +1,120 @@
+package resolver
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// comparisonTerm matches a comparison of a version range, e.g. ">= 2024.05.01"
+var comparisonTerm = regexp.MustCompile(`^(>=|<=|!=|>|<|=)?\s*([^\s<>=!]+)$`)
+
+// comparison is a single comparison of a version range
+type comparison struct {
+	op      string
+	version string
+}
+
+// versionConstraint is a version range. Semantic versions are matched by semver
+// rules; versions of other schemes, such as dates or build numbers, are matched
+// against the plain comparisons of the range (>=, >, <=, <, = and !=) with
+// spec.CompareVersions.
+type versionConstraint struct {
+	// semver is nil when the range is not a valid semver range
+	semver *semver.Constraints
+	// comparisons are the alternatives (||) of comparisons that must all hold,
+	// or nil when the range uses semver-only syntax like ~ or ^
+	comparisons [][]comparison
+}
+
+// parseVersionConstraint parses a version range
+func parseVersionConstraint(expr string) (*versionConstraint, error) {
+	c := &versionConstraint{}
+	semverConstraint, semverErr := semver.NewConstraint(expr)
+	if semverErr == nil {
+		c.semver = semverConstraint
+	}
+	c.comparisons = parseComparisons(expr)
+	if c.semver == nil && c.comparisons == nil {
+		return nil, fmt.Errorf("invalid version range %q: %w", expr, semverErr)
+	}
+	return c, nil
+}
+
+// parseComparisons parses expr as alternatives of comma- or space-separated
+// comparisons, or returns nil when it is not one
+func parseComparisons(expr string) [][]comparison {
+	if strings.Contains(expr, " - ") {
+		return nil
+	}
+	var alternatives [][]comparison
+	for _, alternative := range strings.Split(expr, "||") {
+		// Join operators and their versions before splitting on spaces
+		for _, op := range []string{">=", "<=", "!=", ">", "<", "="} {
+			alternative = strings.ReplaceAll(alternative, op+" ", op)
+		}
+		var terms []comparison
+		for _, term := range strings.FieldsFunc(alternative, func(r rune) bool { return r == ',' || r == ' ' }) {
+			m := comparisonTerm.FindStringSubmatch(term)
+			if m == nil || spec.CheckVersion(m[2]) != nil || strings.ContainsAny(m[2], "~^*") ||
+				strings.HasSuffix(m[2], ".x") || strings.HasSuffix(m[2], ".X") {
+				return nil
+			}
+			op := m[1]
+			if op == "" {
+				op = "="
+			}
+			terms = append(terms, comparison{op: op, version: m[2]})
+		}
+		if len(terms) == 0 {
+			return nil
+		}
+		alternatives = append(alternatives, terms)
+	}
+	return alternatives
+}
+
+// Check reports whether version lies within the range
+func (c *versionConstraint) Check(version string) bool {
+	if c.semver != nil && spec.IsSemanticVersion(version) {
+		return c.semver.Check(semver.MustParse(version))
+	}
+	if c.comparisons == nil || spec.CheckVersion(version) != nil {
+		return false
+	}
+	for _, terms := range c.comparisons {
+		if matchesAll(version, terms) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAll reports whether version satisfies all comparisons
+func matchesAll(version string, terms []comparison) bool {
+	for _, term := range terms {
+		cmp := spec.CompareVersions(version, term.version)
+		var ok bool
+		switch term.op {
+		case ">=":
+			ok = cmp >= 0
+		case ">":
+			ok = cmp > 0
+		case "<=":
+			ok = cmp <= 0
+		case "<":
+			ok = cmp < 0
+		case "!=":
+			ok = cmp != 0
+		default:
+			ok = cmp == 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
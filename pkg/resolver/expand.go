@@ -0,0 +1,176 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// tagsPerPage and maxTagPages bound the releases or tags listed for a range
+const (
+	tagsPerPage = 100
+	maxTagPages = 10
+)
+
+// IsVersionSet reports whether expr is a comma-separated list of versions or a
+// semver range (e.g. ">=1.2, <2" or "~1.4") rather than a single version
+func IsVersionSet(expr string) bool {
+	return strings.Contains(expr, ",") || isRange(expr)
+}
+
+// isRange reports whether expr uses semver constraint syntax
+func isRange(expr string) bool {
+	expr = strings.TrimSpace(expr)
+	return strings.ContainsAny(expr, "<>=~^*|") || strings.Contains(expr, " - ") ||
+		strings.HasSuffix(expr, ".x") || strings.HasSuffix(expr, ".X")
+}
+
+// Expand returns the versions of expr: the listed versions of a comma-separated
+// list as given, or the tags satisfying a version range, oldest first. Tags of
+// other schemes than semver, such as dates, only match the plain comparisons
+// of a range (see versionConstraint), and tags without a version number none.
+func (r *Resolver) Expand(ctx context.Context, expr string) ([]string, error) {
+	var list []string
+	for _, part := range strings.Split(expr, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" || isRange(part) {
+			list = nil
+			break
+		}
+		list = append(list, part)
+	}
+	if list != nil {
+		return list, nil
+	}
+
+	constraint, err := parseVersionConstraint(expr)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := r.Tags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var matches []string
+	for _, tag := range tags {
+		if constraint.Check(tag) {
+			matches = append(matches, tag)
+		} else if spec.CheckVersion(tag) != nil {
+			log.Debugf("skipping tag %s without a version number", tag)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no tags of %s match %q", r.Spec.GetRepo(), expr)
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return spec.CompareVersions(matches[i], matches[j]) < 0 })
+	return matches, nil
+}
+
+// Release is a release (or git tag) of the repository
+type Release struct {
+	Tag        string
+	Prerelease bool
+	// published is when the release was published, zero for git tags
+	published time.Time
+}
+
+// Tags lists the tags of the repository's releases, or of its git tags for the
+// github-tags version source, newest first
+func (r *Resolver) Tags(ctx context.Context) ([]string, error) {
+	releases, err := r.Releases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]string, len(releases))
+	for i, release := range releases {
+		tags[i] = release.Tag
+	}
+	return tags, nil
+}
+
+// Releases lists the repository's published releases newest first by their
+// publication date, or its git tags for the github-tags version source and
+// repositories without releases (see version.tag_fallback), newest first by
+// spec.CompareVersions. Git tags are pre-releases when they are semantic
+// versions with a pre-release part, and are left out unless they match
+// version.tag_pattern.
+func (r *Resolver) Releases(ctx context.Context) ([]Release, error) {
+	repo := r.Spec.GetRepo()
+	if repo == "" {
+		return nil, fmt.Errorf("repository not specified in spec")
+	}
+	useTags := r.Spec.GetVersion().GetSource() == spec.GithubTags
+	releases, err := r.listReleases(ctx, useTags)
+	if err == nil && !useTags && len(releases) == 0 && r.Spec.GetVersion().GetTagFallback() {
+		log.Infof("%s has no releases, listing its tags", repo)
+		return r.listReleases(ctx, true)
+	}
+	return releases, err
+}
+
+// listReleases lists the published releases, or the git tags with useTags,
+// newest first
+func (r *Resolver) listReleases(ctx context.Context, useTags bool) ([]Release, error) {
+	repo := r.Spec.GetRepo()
+	pattern, err := r.tagPattern()
+	if err != nil {
+		return nil, err
+	}
+	endpoint := "releases"
+	if useTags {
+		endpoint = "tags"
+	}
+
+	var releases []Release
+	for page := 1; page <= maxTagPages; page++ {
+		var entries []struct {
+			Name        string    `json:"name"`
+			TagName     string    `json:"tag_name"`
+			Draft       bool      `json:"draft"`
+			Prerelease  bool      `json:"prerelease"`
+			PublishedAt time.Time `json:"published_at"`
+		}
+		url := fmt.Sprintf("%s/repos/%s/%s?per_page=%d&page=%d", r.apiBaseURL(), repo, endpoint, tagsPerPage, page)
+		if err := r.getJSON(ctx, url, &entries); err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", endpoint, err)
+		}
+		for _, e := range entries {
+			switch {
+			case useTags && pattern != nil && !pattern.MatchString(e.Name):
+				// Left out by version.tag_pattern
+			case useTags:
+				releases = append(releases, Release{Tag: e.Name, Prerelease: spec.IsPrerelease(e.Name)})
+			case !e.Draft:
+				releases = append(releases, Release{Tag: e.TagName, Prerelease: e.Prerelease, published: e.PublishedAt})
+			}
+		}
+		if len(entries) < tagsPerPage {
+			sortNewestFirst(releases, useTags)
+			return releases, nil
+		}
+	}
+	log.Warnf("only the newest %d %s of %s were considered", len(releases), endpoint, repo)
+	sortNewestFirst(releases, useTags)
+	return releases, nil
+}
+
+// sortNewestFirst sorts releases by their publication date, which unlike
+// their tags is comparable whatever the version scheme, or git tags, which
+// have none, by spec.CompareVersions. Tags without a version number come last.
+func sortNewestFirst(releases []Release, tags bool) {
+	sort.SliceStable(releases, func(i, j int) bool {
+		a, b := releases[i], releases[j]
+		if !tags {
+			return a.published.After(b.published)
+		}
+		if okA, okB := spec.CheckVersion(a.Tag) == nil, spec.CheckVersion(b.Tag) == nil; okA != okB {
+			return okA
+		}
+		return spec.CompareVersions(a.Tag, b.Tag) > 0
+	})
+}
@@ -0,0 +1,62 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+// ReleaseMetadata is the metadata of a release that asset templates can
+// reference through spec.ReleaseVariables
+type ReleaseMetadata struct {
+	// Name is the title of the release, or its tag when it has none
+	Name        string
+	PublishedAt time.Time
+}
+
+// Vars returns the values of spec.ReleaseVariables of the release
+func (m *ReleaseMetadata) Vars() map[string]string {
+	return spec.ReleaseVars(m.Name, m.PublishedAt)
+}
+
+// ReleaseMetadata fetches the metadata of the release of tag from the GitHub
+// or GitLab releases API
+func (r *Resolver) ReleaseMetadata(ctx context.Context, tag string) (*ReleaseMetadata, error) {
+	repo := r.Spec.GetRepo()
+	if repo == "" {
+		return nil, fmt.Errorf("repository not specified in spec")
+	}
+
+	var release struct {
+		Name        string    `json:"name"`
+		PublishedAt time.Time `json:"published_at"`
+		// ReleasedAt is the publication date of GitLab releases
+		ReleasedAt time.Time `json:"released_at"`
+	}
+	var releaseURL string
+	if r.Spec.GetSource() == spec.Gitlab {
+		releaseURL = fmt.Sprintf("%s/releases/%s", r.gitLabProjectURL(), url.PathEscape(tag))
+	} else {
+		releaseURL = fmt.Sprintf("%s/repos/%s/releases/tags/%s", r.apiBaseURL(), repo, url.PathEscape(tag))
+	}
+	log.Infof("fetching metadata of release %s", tag)
+	if err := r.getJSON(ctx, releaseURL, &release); err != nil {
+		return nil, fmt.Errorf("failed to fetch release %s: %w", tag, err)
+	}
+
+	metadata := &ReleaseMetadata{Name: release.Name, PublishedAt: release.PublishedAt}
+	if metadata.PublishedAt.IsZero() {
+		metadata.PublishedAt = release.ReleasedAt
+	}
+	if metadata.PublishedAt.IsZero() {
+		return nil, fmt.Errorf("release %s of %s has no publication date", tag, repo)
+	}
+	if metadata.Name == "" {
+		metadata.Name = tag
+	}
+	return metadata, nil
+}
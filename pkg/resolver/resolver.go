@@ -0,0 +1,293 @@
+// Package resolver resolves "latest" to a concrete version using the spec's version source.
+package resolver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/binary-install/binstaller/pkg/asset"
+	"github.com/binary-install/binstaller/pkg/httpclient"
+	"github.com/binary-install/binstaller/pkg/jsonpath"
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/buildkite/interpolate"
+)
+
+// DefaultAPIBaseURL is the GitHub API base URL of github.com
+const DefaultAPIBaseURL = httpclient.DefaultGitHubAPIURL
+
+// Resolver resolves versions for an InstallSpec
+type Resolver struct {
+	Spec *spec.InstallSpec
+	// APIBaseURL is the GitHub API base URL (defaults to the API of the spec's
+	// GitHub instance, see asset.GitHubAPIURL)
+	APIBaseURL string
+	// GitLabBaseURL is the web URL of the GitLab instance of source: gitlab
+	// specs (defaults to https:// and the spec's host)
+	GitLabBaseURL string
+	// Client is the HTTP client (defaults to httpclient.NewGitHubClient())
+	Client *http.Client
+}
+
+// New creates a resolver for the given spec
+func New(installSpec *spec.InstallSpec) *Resolver {
+	return &Resolver{
+		Spec:       installSpec,
+		APIBaseURL: asset.GitHubAPIURL(installSpec),
+		Client:     httpclient.NewGitHubClient(),
+	}
+}
+
+// Resolve returns version unchanged unless it is empty or "latest",
+// in which case the latest version is resolved from the configured source
+func (r *Resolver) Resolve(ctx context.Context, version string) (string, error) {
+	if version != "" && version != "latest" {
+		return version, nil
+	}
+	return r.Latest(ctx)
+}
+
+// Latest resolves the latest version from the configured source
+func (r *Resolver) Latest(ctx context.Context) (string, error) {
+	repo := r.Spec.GetRepo()
+	if repo == "" {
+		return "", fmt.Errorf("repository not specified in spec")
+	}
+
+	versionConfig := r.Spec.GetVersion()
+	if r.Spec.GetSource() == spec.Gitlab && versionConfig.GetSource() != spec.HTTPJSON {
+		return r.latestFromGitLab(ctx, versionConfig.GetSource())
+	}
+	switch source := versionConfig.GetSource(); source {
+	case spec.GithubReleases:
+		log.Info("checking GitHub for latest tag")
+		var release struct {
+			TagName string `json:"tag_name"`
+		}
+		err := r.getJSON(ctx, fmt.Sprintf("%s/repos/%s/releases/latest", r.apiBaseURL(), repo), &release)
+		if isNotFound(err) && versionConfig.GetTagFallback() {
+			log.Infof("%s has no latest release, checking GitHub for latest tag", repo)
+			return r.latestTag(ctx, fmt.Sprintf("%s/repos/%s/tags", r.apiBaseURL(), repo))
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch latest release: %w", err)
+		}
+		if release.TagName == "" {
+			return "", fmt.Errorf("no tag_name found in GitHub response")
+		}
+		return release.TagName, nil
+
+	case spec.GithubTags:
+		log.Info("checking GitHub for latest tag")
+		return r.latestTag(ctx, fmt.Sprintf("%s/repos/%s/tags", r.apiBaseURL(), repo))
+
+	case spec.HTTPJSON:
+		return r.latestFromJSON(ctx, versionConfig)
+
+	default:
+		return "", fmt.Errorf("unsupported version source: %s", source)
+	}
+}
+
+// latestFromGitLab resolves the latest release, or the latest tag for the
+// github-tags source, of the spec's GitLab project
+func (r *Resolver) latestFromGitLab(ctx context.Context, source spec.Source) (string, error) {
+	projectURL := r.gitLabProjectURL()
+
+	switch source {
+	case spec.GithubReleases:
+		log.Info("checking GitLab for latest release")
+		var release struct {
+			TagName string `json:"tag_name"`
+		}
+		err := r.getJSON(ctx, projectURL+"/releases/permalink/latest", &release)
+		if isNotFound(err) && r.Spec.GetVersion().GetTagFallback() {
+			log.Infof("%s has no latest release, checking GitLab for latest tag", r.Spec.GetRepo())
+			return r.latestTag(ctx, projectURL+"/repository/tags")
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch latest release: %w", err)
+		}
+		if release.TagName == "" {
+			return "", fmt.Errorf("no tag_name found in GitLab response")
+		}
+		return release.TagName, nil
+
+	case spec.GithubTags:
+		log.Info("checking GitLab for latest tag")
+		return r.latestTag(ctx, projectURL+"/repository/tags")
+
+	default:
+		return "", fmt.Errorf("unsupported version source: %s", source)
+	}
+}
+
+// latestTag returns the highest version among the tags listed by the tags
+// endpoint tagsURL of GitHub or GitLab that match version.tag_pattern. The
+// endpoints sort tags by name or update time, not by version, so v0.9.0 may
+// come before v0.10.0.
+func (r *Resolver) latestTag(ctx context.Context, tagsURL string) (string, error) {
+	pattern, err := r.tagPattern()
+	if err != nil {
+		return "", err
+	}
+	latest := ""
+	for page := 1; page <= maxTagPages; page++ {
+		var tags []struct {
+			Name string `json:"name"`
+		}
+		if err := r.getJSON(ctx, fmt.Sprintf("%s?per_page=%d&page=%d", tagsURL, tagsPerPage, page), &tags); err != nil {
+			return "", fmt.Errorf("failed to fetch tags: %w", err)
+		}
+		for _, tag := range tags {
+			if tag.Name == "" || (pattern != nil && !pattern.MatchString(tag.Name)) {
+				continue
+			}
+			if latest == "" || spec.CompareVersions(tag.Name, latest) > 0 {
+				latest = tag.Name
+			}
+		}
+		if len(tags) < tagsPerPage {
+			break
+		}
+	}
+	if latest != "" {
+		return latest, nil
+	}
+	if pattern != nil {
+		return "", fmt.Errorf("no tags of %s match %s", r.Spec.GetRepo(), pattern)
+	}
+	return "", fmt.Errorf("no tags found for %s", r.Spec.GetRepo())
+}
+
+// tagPattern returns the compiled version.tag_pattern, or nil without one
+func (r *Resolver) tagPattern() (*regexp.Regexp, error) {
+	pattern := r.Spec.GetVersion().GetTagPattern()
+	if pattern == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version.tag_pattern: %w", err)
+	}
+	return re, nil
+}
+
+// gitLabProjectURL returns the API URL of the spec's GitLab project
+func (r *Resolver) gitLabProjectURL() string {
+	baseURL := strings.TrimSuffix(r.GitLabBaseURL, "/")
+	if baseURL == "" {
+		baseURL = asset.BaseURL(r.Spec)
+	}
+	// The API addresses projects by their URL-encoded path
+	return fmt.Sprintf("%s/api/v4/projects/%s", baseURL, url.PathEscape(r.Spec.GetRepo()))
+}
+
+// latestFromJSON extracts the version from a JSON document with a JSONPath
+func (r *Resolver) latestFromJSON(ctx context.Context, versionConfig *spec.Version) (string, error) {
+	path, err := jsonpath.Parse(versionConfig.GetJSONPath())
+	if err != nil {
+		return "", err
+	}
+	url, err := interpolate.Interpolate(interpolate.NewMapEnv(map[string]string{
+		"NAME": r.Spec.GetName(),
+		"REPO": r.Spec.GetRepo(),
+	}), versionConfig.GetURL())
+	if err != nil {
+		return "", fmt.Errorf("failed to interpolate version URL: %w", err)
+	}
+	if url == "" {
+		return "", fmt.Errorf("version.url is required for the %s source", spec.HTTPJSON)
+	}
+
+	log.Infof("checking %s for latest version", url)
+	var doc any
+	if err := r.getJSON(ctx, url, &doc); err != nil {
+		return "", fmt.Errorf("failed to fetch version document: %w", err)
+	}
+	value, err := path.Eval(doc)
+	if err != nil {
+		return "", fmt.Errorf("failed to extract version: %w", err)
+	}
+
+	var version string
+	switch v := value.(type) {
+	case string:
+		version = v
+	case float64:
+		version = fmt.Sprint(v)
+	default:
+		return "", fmt.Errorf("value at %s is not a string: %v", path, value)
+	}
+	version = strings.TrimSpace(version)
+	if version == "" {
+		return "", fmt.Errorf("empty version at %s", path)
+	}
+	return version, nil
+}
+
+// statusError is the error of an unsuccessful API response
+type statusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("status %d: %s", e.StatusCode, e.Body)
+}
+
+// isNotFound reports whether err is a 404 Not Found API response
+func isNotFound(err error) bool {
+	var se *statusError
+	return errors.As(err, &se) && se.StatusCode == http.StatusNotFound
+}
+
+// getJSON fetches url and decodes the JSON response into v
+func (r *Resolver) getJSON(ctx context.Context, url string, v any) error {
+	if r.Spec.GetSource() == spec.Github && spec.StringValue(r.Spec.Host) != "" {
+		// Only the resolver's requests send the token of the spec's GitHub Enterprise Server
+		ctx = httpclient.WithGitHubHost(ctx, r.Spec.GetHost())
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if strings.HasPrefix(url, r.apiBaseURL()) {
+		req.Header.Set("Accept", "application/vnd.github.v3+json")
+	}
+
+	client := r.Client
+	if client == nil {
+		client = httpclient.NewGitHubClient()
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return &statusError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// apiBaseURL returns the GitHub API base URL
+func (r *Resolver) apiBaseURL() string {
+	if r.APIBaseURL == "" {
+		return asset.GitHubAPIURL(r.Spec)
+	}
+	return strings.TrimSuffix(r.APIBaseURL, "/")
+}
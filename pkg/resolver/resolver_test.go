@@ -0,0 +1,322 @@
+package resolver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+)
+
+func TestResolve(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/repos/owner/tool/releases/latest":
+			w.Write([]byte(`{"tag_name": "v1.0.0"}`))
+		case "/repos/owner/tool/tags":
+			w.Write([]byte(`[{"name": "v1.1.0"}, {"name": "v1.0.0"}]`))
+		case "/repos/owner/sorted/tags":
+			// GitHub sorts tags by name, so v0.9.0 comes before v0.10.0
+			w.Write([]byte(`[{"name": "v0.9.0"}, {"name": "v0.10.0"}, {"name": "v0.1.0"}]`))
+		case "/repos/owner/empty/tags":
+			w.Write([]byte(`[]`))
+		case "/repos/owner/tagged/tags", "/api/v4/projects/group%2Ftagged/repository/tags":
+			w.Write([]byte(`[{"name": "nightly"}, {"name": "v2.0.0"}, {"name": "v1.9.0"}]`))
+		case "/api/v4/projects/group%2Fsub%2Ftool/releases/permalink/latest":
+			w.Write([]byte(`{"name": "Release 3.0", "tag_name": "v3.0.0"}`))
+		case "/api/v4/projects/group%2Fsub%2Ftool/repository/tags":
+			w.Write([]byte(`[{"name": "v3.1.0", "message": ""}]`))
+		case "/tool/release.json":
+			w.Write([]byte(`{"stable": {"version": "2.0.0"}, "build": 42}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		spec    *spec.InstallSpec
+		version string
+		want    string
+		wantErr bool
+	}{
+		{"explicit version", spec.NewInstallSpec("owner/tool"), "v0.9.0", "v0.9.0", false},
+		{"github releases", spec.NewInstallSpec("owner/tool"), "latest", "v1.0.0", false},
+		{"github tags", spec.NewInstallSpec("owner/tool").WithVersion(spec.NewVersion(spec.GithubTags)), "", "v1.1.0", false},
+		{"github tags by version", spec.NewInstallSpec("owner/sorted").WithVersion(spec.NewVersion(spec.GithubTags)), "latest", "v0.10.0", false},
+		{"github tags empty", spec.NewInstallSpec("owner/empty").WithVersion(spec.NewVersion(spec.GithubTags)), "latest", "", true},
+		{
+			"http json",
+			spec.NewInstallSpec("owner/tool").WithVersion(spec.NewVersion(spec.HTTPJSON).
+				WithURL(server.URL + "/${NAME}/release.json").WithJSONPath("$.stable.version")),
+			"latest", "2.0.0", false,
+		},
+		{
+			"http json number",
+			spec.NewInstallSpec("owner/tool").WithVersion(spec.NewVersion(spec.HTTPJSON).
+				WithURL(server.URL + "/tool/release.json").WithJSONPath("$.build")),
+			"latest", "42", false,
+		},
+		{
+			"http json missing key",
+			spec.NewInstallSpec("owner/tool").WithVersion(spec.NewVersion(spec.HTTPJSON).
+				WithURL(server.URL + "/tool/release.json")),
+			"latest", "", true,
+		},
+		{"missing release", spec.NewInstallSpec("owner/missing"), "latest", "", true},
+		{"gitlab releases", spec.NewInstallSpec("group/sub/tool").WithSource(spec.Gitlab, ""), "latest", "v3.0.0", false},
+		{"gitlab tags", spec.NewInstallSpec("group/sub/tool").WithSource(spec.Gitlab, "").WithVersion(spec.NewVersion(spec.GithubTags)), "latest", "v3.1.0", false},
+		{"gitlab missing release", spec.NewInstallSpec("group/missing").WithSource(spec.Gitlab, ""), "latest", "", true},
+		{"tag fallback", spec.NewInstallSpec("owner/tagged"), "latest", "v2.0.0", false},
+		{"tag fallback with pattern", spec.NewInstallSpec("owner/tagged").WithVersion(spec.NewVersion(spec.GithubReleases).WithTagPattern(`^v\d`)), "latest", "v2.0.0", false},
+		{"tag fallback disabled", spec.NewInstallSpec("owner/tagged").WithVersion(spec.NewVersion(spec.GithubReleases).WithTagFallback(false)), "latest", "", true},
+		{"github tags with pattern", spec.NewInstallSpec("owner/tagged").WithVersion(spec.NewVersion(spec.GithubTags).WithTagPattern(`^v1\.`)), "latest", "v1.9.0", false},
+		{"github tags without match", spec.NewInstallSpec("owner/tagged").WithVersion(spec.NewVersion(spec.GithubTags).WithTagPattern(`^v3\.`)), "latest", "", true},
+		{"gitlab tag fallback", spec.NewInstallSpec("group/tagged").WithSource(spec.Gitlab, "").WithVersion(spec.NewVersion(spec.GithubReleases).WithTagPattern(`^v`)), "latest", "v2.0.0", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.spec.SetDefaults()
+			r := New(tt.spec)
+			r.APIBaseURL = server.URL
+			r.GitLabBaseURL = server.URL
+			got, err := r.Resolve(context.Background(), tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsVersionSet(t *testing.T) {
+	tests := map[string]bool{
+		"v1.2.3":          false,
+		"latest":          false,
+		"v1.2.3,v1.3.0":   true,
+		">=1.2, <2":       true,
+		"~1.4":            true,
+		"^2":              true,
+		"1.x":             true,
+		"1.0.0 - 1.4.0":   true,
+		"<1 || >=2.0.0-0": true,
+	}
+	for expr, want := range tests {
+		if got := IsVersionSet(expr); got != want {
+			t.Errorf("IsVersionSet(%q) = %v, want %v", expr, got, want)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/tool/releases":
+			w.Write([]byte(`[{"tag_name": "v2.0.0"}, {"tag_name": "v1.3.0"}, {"tag_name": "v1.3.0-rc.1"}, {"tag_name": "v1.2.0"}, {"tag_name": "nightly"}, {"tag_name": "v1.1.0"}]`))
+		case "/repos/owner/tool/tags":
+			w.Write([]byte(`[{"name": "v0.2.0"}, {"name": "v0.1.0"}]`))
+		case "/repos/owner/tagged/releases":
+			w.Write([]byte(`[]`))
+		case "/repos/owner/tagged/tags":
+			w.Write([]byte(`[{"name": "tool/v1.1.0"}, {"name": "v1.2.0"}, {"name": "v1.0.0"}]`))
+		case "/repos/owner/dated/releases":
+			w.Write([]byte(`[{"tag_name": "2024.10.01"}, {"tag_name": "2024.9.30"}, {"tag_name": "2024.05.01.1"}, {"tag_name": "2024.05.01"}]`))
+		case "/repos/owner/builds/releases":
+			w.Write([]byte(`[{"tag_name": "b5200"}, {"tag_name": "b4999"}, {"tag_name": "b5123"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		spec    *spec.InstallSpec
+		expr    string
+		want    []string
+		wantErr bool
+	}{
+		{"list", spec.NewInstallSpec("owner/tool"), "v1.0.0, v1.1.0", []string{"v1.0.0", "v1.1.0"}, false},
+		{"range", spec.NewInstallSpec("owner/tool"), ">=1.2, <2", []string{"v1.2.0", "v1.3.0"}, false},
+		{"tilde range", spec.NewInstallSpec("owner/tool"), "~1.1", []string{"v1.1.0"}, false},
+		{"github tags", spec.NewInstallSpec("owner/tool").WithVersion(spec.NewVersion(spec.GithubTags)), "<1", []string{"v0.1.0", "v0.2.0"}, false},
+		{"no match", spec.NewInstallSpec("owner/tool"), ">=3", nil, true},
+		{"invalid range", spec.NewInstallSpec("owner/tool"), ">=1.2, <<2", nil, true},
+		{"missing repo", spec.NewInstallSpec("owner/missing"), ">=1", nil, true},
+		{"tag fallback", spec.NewInstallSpec("owner/tagged"), ">=1.1", []string{"v1.2.0"}, false},
+		{"tag fallback with pattern", spec.NewInstallSpec("owner/tagged").WithVersion(spec.NewVersion(spec.GithubReleases).WithTagPattern("^v1[.]0")), ">=1", []string{"v1.0.0"}, false},
+		{"tag fallback disabled", spec.NewInstallSpec("owner/tagged").WithVersion(spec.NewVersion(spec.GithubReleases).WithTagFallback(false)), ">=1", nil, true},
+		{"date range", spec.NewInstallSpec("owner/dated"), ">=2024.05.01.1, <2024.10", []string{"2024.05.01.1", "2024.9.30"}, false},
+		{"date range with semver syntax", spec.NewInstallSpec("owner/dated"), "~2024.9", []string{"2024.9.30"}, false},
+		{"build numbers", spec.NewInstallSpec("owner/builds"), ">b5000", []string{"b5123", "b5200"}, false},
+		{"build numbers alternatives", spec.NewInstallSpec("owner/builds"), "<b5000 || >=b5200", []string{"b4999", "b5200"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.spec.SetDefaults()
+			r := New(tt.spec)
+			r.APIBaseURL = server.URL
+			got, err := r.Expand(context.Background(), tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Expand() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReleasesOrder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/tool/releases":
+			w.Write([]byte(`[{"tag_name": "2024.05.01", "published_at": "2024-05-01T10:00:00Z"}, {"tag_name": "hotfix-2", "published_at": "2024-06-01T10:00:00Z"}, {"tag_name": "2024.04.01", "published_at": "2024-04-01T10:00:00Z"}]`))
+		case "/repos/owner/tool/tags":
+			w.Write([]byte(`[{"name": "nightly"}, {"name": "2024.9.30"}, {"name": "2024.10.01"}, {"name": "2024-05-01"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		spec *spec.InstallSpec
+		want []string
+	}{
+		{"releases by publication date", spec.NewInstallSpec("owner/tool"), []string{"hotfix-2", "2024.05.01", "2024.04.01"}},
+		{"tags by version", spec.NewInstallSpec("owner/tool").WithVersion(spec.NewVersion(spec.GithubTags)), []string{"2024.10.01", "2024.9.30", "2024-05-01", "nightly"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.spec.SetDefaults()
+			r := New(tt.spec)
+			r.APIBaseURL = server.URL
+			releases, err := r.Releases(context.Background())
+			if err != nil {
+				t.Fatalf("Releases() error = %v", err)
+			}
+			var got []string
+			for _, release := range releases {
+				if release.Prerelease {
+					t.Errorf("%s is not a pre-release", release.Tag)
+				}
+				got = append(got, release.Tag)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Releases() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChannel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/tool/releases":
+			w.Write([]byte(`[{"tag_name": "v2.0.0-rc.2", "draft": true, "prerelease": true}, {"tag_name": "v2.0.0-rc.1", "prerelease": true}, {"tag_name": "v1.3.0"}, {"tag_name": "v1.3.0-rc.1", "prerelease": true}]`))
+		case "/repos/owner/stable-only/releases":
+			w.Write([]byte(`[{"tag_name": "v1.0.0"}]`))
+		case "/repos/owner/beta-only/releases":
+			w.Write([]byte(`[{"tag_name": "v0.1.0-alpha", "prerelease": true}]`))
+		case "/repos/owner/tool/tags":
+			w.Write([]byte(`[{"name": "v3.0.0-beta.1"}, {"name": "v2.1.0"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		spec    *spec.InstallSpec
+		channel string
+		want    string
+		wantErr bool
+	}{
+		{"stable", spec.NewInstallSpec("owner/tool"), ChannelStable, "v1.3.0", false},
+		{"beta skips drafts", spec.NewInstallSpec("owner/tool"), ChannelBeta, "v2.0.0-rc.1", false},
+		{"beta follows stable", spec.NewInstallSpec("owner/stable-only"), ChannelBeta, "v1.0.0", false},
+		{"beta without stable", spec.NewInstallSpec("owner/beta-only"), ChannelBeta, "v0.1.0-alpha", false},
+		{"no stable", spec.NewInstallSpec("owner/beta-only"), ChannelStable, "", true},
+		{"github tags", spec.NewInstallSpec("owner/tool").WithVersion(spec.NewVersion(spec.GithubTags)), ChannelBeta, "v3.0.0-beta.1", false},
+		{"unknown channel", spec.NewInstallSpec("owner/tool"), "nightly", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.spec.SetDefaults()
+			r := New(tt.spec)
+			r.APIBaseURL = server.URL
+			got, err := r.Channel(context.Background(), tt.channel)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Channel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Channel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReleaseMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/repos/owner/tool/releases/tags/v1.0.0":
+			w.Write([]byte(`{"name": "Spring release", "tag_name": "v1.0.0", "published_at": "2024-05-01T12:30:00Z"}`))
+		case "/repos/owner/tool/releases/tags/nightly":
+			w.Write([]byte(`{"name": "", "tag_name": "nightly", "published_at": "2024-06-02T23:00:00-02:00"}`))
+		case "/repos/owner/tool/releases/tags/draft":
+			w.Write([]byte(`{"name": "Draft", "tag_name": "draft", "published_at": null}`))
+		case "/api/v4/projects/group%2Ftool/releases/v3.0.0":
+			w.Write([]byte(`{"name": "Release 3.0", "tag_name": "v3.0.0", "released_at": "2024-07-03T08:00:00Z"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		spec    *spec.InstallSpec
+		tag     string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"github", spec.NewInstallSpec("owner/tool"), "v1.0.0", map[string]string{
+			"RELEASE_NAME": "Spring release", "PUBLISHED_AT": "2024-05-01T12:30:00Z", "PUBLISHED_DATE": "2024-05-01",
+		}, false},
+		{"untitled release in UTC", spec.NewInstallSpec("owner/tool"), "nightly", map[string]string{
+			"RELEASE_NAME": "nightly", "PUBLISHED_AT": "2024-06-03T01:00:00Z", "PUBLISHED_DATE": "2024-06-03",
+		}, false},
+		{"unpublished", spec.NewInstallSpec("owner/tool"), "draft", nil, true},
+		{"missing", spec.NewInstallSpec("owner/tool"), "v9.9.9", nil, true},
+		{"gitlab", spec.NewInstallSpec("group/tool").WithSource(spec.Gitlab, ""), "v3.0.0", map[string]string{
+			"RELEASE_NAME": "Release 3.0", "PUBLISHED_AT": "2024-07-03T08:00:00Z", "PUBLISHED_DATE": "2024-07-03",
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.spec.SetDefaults()
+			r := New(tt.spec)
+			r.APIBaseURL = server.URL
+			r.GitLabBaseURL = server.URL
+			metadata, err := r.ReleaseMetadata(context.Background(), tt.tag)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReleaseMetadata() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := metadata.Vars(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Vars() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
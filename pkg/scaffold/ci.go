@@ -0,0 +1,126 @@
+package scaffold
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// CI template kinds
+const (
+	AzurePipelines = "azure-pipelines"
+	GitLabCI       = "gitlab-ci"
+)
+
+// unameArches maps Go architectures to the patterns of `uname -m` matching them
+var unameArches = map[string]string{
+	"amd64": "x86_64|amd64",
+	"arm64": "aarch64|arm64",
+}
+
+// InstallScript renders the POSIX shell script of the CI templates, which
+// installs the binaries of the release into $bin_dir after verifying the asset
+// against its pinned sha256
+func (r *Release) InstallScript() (string, error) {
+	tmpl, err := template.New("install").Funcs(template.FuncMap{
+		"shell":     shellString,
+		"unameArch": func(goArch string) string { return unameArches[goArch] },
+	}).Parse(ciInstallScript)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse install script template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, r); err != nil {
+		return "", fmt.Errorf("failed to render install script: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// indentLines indents every non-empty line of s by n spaces
+func indentLines(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = pad + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// The install script runs in a subshell so that its shell options do not leak
+// into the CI job
+const ciInstallScript = `(
+  set -eu
+  case "$(uname -m)" in
+{{- range .Platforms }}
+  {{ unameArch .Arch }})
+    url={{ shell .URL }}
+    asset={{ shell .Filename }}
+    sha256={{ shell .SHA256 }}
+    ;;
+{{- end }}
+  *)
+    echo "unsupported architecture: $(uname -m)" >&2
+    exit 1
+    ;;
+  esac
+  tmp="$(mktemp -d)"
+  trap 'rm -rf "$tmp"' EXIT
+  curl -fsSL -o "$tmp/$asset" "$url"
+  (cd "$tmp" && echo "$sha256  $asset" | sha256sum -c -)
+  mkdir -p "$bin_dir"
+{{- with index .Platforms 0 }}
+{{- if .Archive }}
+  mkdir "$tmp/x"
+  case "$asset" in
+  *.zip) unzip -q -d "$tmp/x" "$tmp/$asset" ;;
+  *) tar --no-same-owner -xf "$tmp/$asset" -C "$tmp/x" --strip-components {{ .StripComponents }} ;;
+  esac
+{{- range .Binaries }}
+  install -m 755 "$tmp/x/"{{ shell .Path }} "$bin_dir/"{{ shell .Name }}
+{{- end }}
+{{- else }}
+  install -m 755 "$tmp/$asset" "$bin_dir/"{{ shell (index .Binaries 0).Name }}
+{{- end }}
+{{- end }}
+)
+`
+
+const azurePipelinesTemplate = GeneratedHeader + `
+# Azure Pipelines steps template installing {{ .Name }} {{ .Version }} on Linux agents
+# from https://github.com/{{ .Repo }}, verified against pinned sha256 checksums.
+# Requires curl. Use it from a pipeline with:
+#
+#   steps:
+#     - template: {{ .Name }}.azure-pipelines.yml
+steps:
+  - bash: |
+      bin_dir="${AGENT_TOOLSDIRECTORY:-$HOME/.local/share}"/{{ shell (printf "%s/%s/bin" .Name .Version) }}
+{{ indent 6 .InstallScript }}
+      echo "##vso[task.prependpath]$bin_dir"
+    displayName: {{ quote (printf "Install %s %s" .Name .Version) }}
+`
+
+const gitLabCITemplate = GeneratedHeader + `
+# GitLab CI template installing {{ .Name }} {{ .Version }} on Linux runners from
+# https://github.com/{{ .Repo }}, verified against pinned sha256 checksums.
+# Requires curl in the job image. Include it and extend the jobs that need {{ .Name }}:
+#
+#   include:
+#     - local: {{ .Name }}.gitlab-ci.yml
+#   lint:
+#     extends: .install-{{ .Name }}
+#     script:
+#       - {{ (index .Binaries 0).Name }} --version
+#
+# Jobs with their own before_script can add
+# !reference [.install-{{ .Name }}, before_script] to it instead.
+.install-{{ .Name }}:
+  before_script:
+    - |
+      bin_dir="${CI_PROJECT_DIR:-$HOME}/.binstaller/bin"
+{{ indent 6 .InstallScript }}
+      export PATH="$bin_dir:$PATH"
+`
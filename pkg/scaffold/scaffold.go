@@ -0,0 +1,314 @@
+// Package scaffold renders starting-point Linux store manifests (snapcraft.yaml and
+// flatpak-builder manifests) that package an InstallSpec's release assets, and
+// CI templates (Azure Pipelines, GitLab CI) that install them.
+package scaffold
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/binary-install/binstaller/pkg/asset"
+	"github.com/binary-install/binstaller/pkg/export"
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/buildkite/interpolate"
+)
+
+// GeneratedHeader is the first line of every manifest rendered by this package
+const GeneratedHeader = "# Generated by binstaller from the InstallSpec; review before publishing."
+
+// Manifest kinds
+const (
+	Snapcraft = "snapcraft"
+	Flatpak   = "flatpak"
+)
+
+// Release is the Linux release assets of one InstallSpec version
+type Release struct {
+	Name      string
+	Owner     string
+	Repo      string
+	Version   string
+	Platforms []Platform
+}
+
+// Platform is the release asset of one Linux architecture
+type Platform struct {
+	Arch            string // Go architecture name (amd64, arm64)
+	URL             string
+	Filename        string
+	SHA256          string
+	Archive         bool
+	StripComponents int
+	Binaries        []Binary
+}
+
+// Binary is an executable copied out of the release asset
+type Binary struct {
+	Name string
+	Path string // path inside the extracted asset, or the asset filename for raw binaries
+}
+
+// linuxArches maps the Linux architectures supported by both stores to their
+// snap and flatpak names
+var linuxArches = []struct{ goArch, snap, flatpak string }{
+	{"amd64", "amd64", "x86_64"},
+	{"arm64", "arm64", "aarch64"},
+}
+
+// New resolves the Linux amd64/arm64 assets of installSpec at tag
+func New(installSpec *spec.InstallSpec, tag string, hash export.HashFunc) (*Release, error) {
+	installSpec.SetDefaults()
+	if installSpec.GetRepo() == "" {
+		return nil, fmt.Errorf("repo not specified in spec")
+	}
+	if installSpec.Checksums != nil {
+		if algo := spec.AlgorithmString(installSpec.Checksums.Algorithm); algo != "sha256" {
+			return nil, fmt.Errorf("store manifests pin sha256 checksums, got %s", algo)
+		}
+	}
+
+	owner, _, _ := strings.Cut(installSpec.GetRepo(), "/")
+	version := strings.TrimPrefix(tag, "v")
+	r := &Release{
+		Name:    strings.ToLower(installSpec.GetName()),
+		Owner:   owner,
+		Repo:    installSpec.GetRepo(),
+		Version: version,
+	}
+
+	stripComponents := 0
+	if installSpec.Unpack != nil && installSpec.Unpack.StripComponents != nil {
+		stripComponents = int(*installSpec.Unpack.StripComponents)
+	}
+	supported := linuxSupport(installSpec)
+	generator := asset.NewFilenameGenerator(installSpec, tag)
+	for _, a := range linuxArches {
+		if !supported[a.goArch] {
+			continue
+		}
+		resolution, err := generator.Resolve("linux", a.goArch)
+		if err != nil {
+			return nil, err
+		}
+		sha256, err := hash("linux", a.goArch, resolution.Filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get checksum of %s: %w", resolution.Filename, err)
+		}
+		p := Platform{
+			Arch:            a.goArch,
+			URL:             fmt.Sprintf("https://github.com/%s/releases/download/%s/%s", r.Repo, tag, resolution.Filename),
+			Filename:        resolution.Filename,
+			SHA256:          sha256,
+			Archive:         resolution.EXT != "" && resolution.EXT != ".exe",
+			StripComponents: stripComponents,
+		}
+		for _, b := range generator.Binaries("linux", a.goArch) {
+			name := spec.StringValue(b.Name)
+			if name == "" {
+				name = installSpec.GetName()
+			}
+			binaryPath, err := interpolate.Interpolate(interpolate.NewMapEnv(map[string]string{"ASSET_FILENAME": resolution.Filename}), spec.StringValue(b.Path))
+			if err != nil {
+				return nil, fmt.Errorf("failed to interpolate binary path: %w", err)
+			}
+			if binaryPath == "" {
+				binaryPath = name
+			}
+			p.Binaries = append(p.Binaries, Binary{Name: name, Path: path.Clean(binaryPath)})
+		}
+		r.Platforms = append(r.Platforms, p)
+	}
+	if len(r.Platforms) == 0 {
+		return nil, fmt.Errorf("%s supports neither linux/amd64 nor linux/arm64", r.Repo)
+	}
+	return r, nil
+}
+
+// linuxSupport returns the Linux architectures the spec supports
+func linuxSupport(installSpec *spec.InstallSpec) map[string]bool {
+	supported := make(map[string]bool)
+	if len(installSpec.SupportedPlatforms) == 0 {
+		supported["amd64"], supported["arm64"] = true, true
+		return supported
+	}
+	for _, p := range installSpec.SupportedPlatforms {
+		if spec.PlatformOSString(p.OS) == "linux" {
+			supported[spec.PlatformArchString(p.Arch)] = true
+		}
+	}
+	return supported
+}
+
+// AppID returns the flatpak application ID, io.github.OWNER.NAME
+func (r *Release) AppID() string {
+	clean := func(s string) string { return strings.ReplaceAll(s, "-", "_") }
+	return "io.github." + clean(r.Owner) + "." + clean(r.Name)
+}
+
+// Binaries returns the binaries of the first platform; store manifests expose one set of commands
+func (r *Release) Binaries() []Binary {
+	return r.Platforms[0].Binaries
+}
+
+// sourcePath returns the path of a binary in the pulled sources. Raw binaries
+// are saved under the name of the first binary.
+func (r *Release) sourcePath(b Binary) string {
+	if !r.Platforms[0].Archive {
+		return r.Platforms[0].Binaries[0].Name
+	}
+	return b.Path
+}
+
+// Render renders the manifest of the given kind
+func (r *Release) Render(kind string) ([]byte, error) {
+	var text string
+	switch kind {
+	case Snapcraft:
+		text = snapcraftTemplate
+	case Flatpak:
+		text = flatpakTemplate
+	case AzurePipelines:
+		text = azurePipelinesTemplate
+	case GitLabCI:
+		text = gitLabCITemplate
+	default:
+		return nil, fmt.Errorf("unknown manifest kind %q", kind)
+	}
+
+	tmpl, err := template.New(kind).Funcs(template.FuncMap{
+		"snapArch":    func(goArch string) string { return archName(goArch, Snapcraft) },
+		"flatpakArch": func(goArch string) string { return archName(goArch, Flatpak) },
+		"quote":       yamlString,
+		"shell":       shellString,
+		"sourcePath":  (*Release).sourcePath,
+		"indent":      indentLines,
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s template: %w", kind, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, r); err != nil {
+		return nil, fmt.Errorf("failed to render %s manifest: %w", kind, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// archName returns the store's name of a Go architecture
+func archName(goArch, kind string) string {
+	for _, a := range linuxArches {
+		if a.goArch == goArch {
+			if kind == Flatpak {
+				return a.flatpak
+			}
+			return a.snap
+		}
+	}
+	return goArch
+}
+
+// yamlString quotes s as a YAML single-quoted scalar
+func yamlString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// shellString quotes s for a POSIX shell
+func shellString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+const snapcraftTemplate = GeneratedHeader + `
+name: {{ .Name }}
+base: core24
+version: {{ quote .Version }}
+summary: {{ quote (printf "Release binaries of %s" .Repo) }}
+description: |
+  {{ .Name }} packaged from the GitHub releases of https://github.com/{{ .Repo }}.
+grade: stable
+confinement: strict
+
+platforms:
+{{- range .Platforms }}
+  {{ snapArch .Arch }}:
+{{- end }}
+
+parts:
+  {{ .Name }}:
+    plugin: nil
+    build-packages: [curl, unzip]
+    override-pull: |
+      case "$CRAFT_ARCH_BUILD_FOR" in
+{{- range .Platforms }}
+      {{ snapArch .Arch }})
+        url={{ shell .URL }}
+        asset={{ shell .Filename }}
+        sha256={{ shell .SHA256 }}
+        ;;
+{{- end }}
+      *)
+        echo "unsupported architecture: $CRAFT_ARCH_BUILD_FOR" >&2
+        exit 1
+        ;;
+      esac
+      curl -fsSL -o "$asset" "$url"
+      echo "$sha256  $asset" | sha256sum -c -
+{{- with index .Platforms 0 }}
+{{- if .Archive }}
+      case "$asset" in
+      *.zip) unzip -q "$asset" ;;
+      *) tar --no-same-owner -xf "$asset" --strip-components {{ .StripComponents }} ;;
+      esac
+{{- else }}
+      mv "$asset" {{ shell (index .Binaries 0).Name }}
+{{- end }}
+{{- end }}
+    override-build: |
+{{- range .Binaries }}
+      install -D -m 755 {{ shell (sourcePath $ .) }} "$CRAFT_PART_INSTALL/bin/{{ .Name }}"
+{{- end }}
+
+apps:
+{{- range .Binaries }}
+  {{ .Name }}:
+    command: bin/{{ .Name }}
+    # Adjust the interfaces to what {{ .Name }} needs
+    plugs: [home, network]
+{{- end }}
+`
+
+const flatpakTemplate = GeneratedHeader + `
+app-id: {{ .AppID }}
+runtime: org.freedesktop.Platform
+runtime-version: '24.08'
+sdk: org.freedesktop.Sdk
+command: {{ (index .Binaries 0).Name }}
+# Adjust the sandbox permissions to what {{ .Name }} needs
+finish-args:
+  - --share=network
+  - --filesystem=home
+
+modules:
+  - name: {{ .Name }}
+    buildsystem: simple
+    build-commands:
+{{- range .Binaries }}
+      - install -D -m 755 {{ shell (sourcePath $ .) }} /app/bin/{{ .Name }}
+{{- end }}
+    sources:
+{{- range .Platforms }}
+{{- if .Archive }}
+      - type: archive
+        url: {{ .URL }}
+        sha256: {{ .SHA256 }}
+        strip-components: {{ .StripComponents }}
+{{- else }}
+      - type: file
+        url: {{ .URL }}
+        sha256: {{ .SHA256 }}
+        dest-filename: {{ (index .Binaries 0).Name }}
+{{- end }}
+        only-arches: [{{ flatpakArch .Arch }}]
+{{- end }}
+`
@@ -0,0 +1,202 @@
+package scaffold
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/binary-install/binstaller/pkg/spec"
+	"github.com/goccy/go-yaml"
+)
+
+func fakeHash(osName, arch, filename string) (string, error) {
+	return fmt.Sprintf("hash-%s-%s", osName, arch), nil
+}
+
+func TestRenderArchive(t *testing.T) {
+	installSpec := spec.NewInstallSpec("my-org/My-Tool").
+		WithAsset(spec.NewAsset("${NAME}_${VERSION}_${OS}_${ARCH}${EXT}").
+			WithDefaultExtension(".tar.gz").
+			WithRules(spec.NewRule("", "amd64").WithArch("x86_64")).
+			WithBinary("mytool", "dist/my-tool")).
+		WithSupportedPlatforms("linux/amd64", "linux/arm64", "darwin/arm64")
+
+	release, err := New(installSpec, "v1.2.3", fakeHash)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if got, want := release.AppID(), "io.github.my_org.my_tool"; got != want {
+		t.Errorf("AppID() = %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		kind string
+		want []string
+	}{
+		{
+			kind: Snapcraft,
+			want: []string{
+				"name: my-tool",
+				"version: '1.2.3'",
+				"  amd64:\n  arm64:\n",
+				"        url='https://github.com/my-org/My-Tool/releases/download/v1.2.3/My-Tool_1.2.3_linux_x86_64.tar.gz'",
+				"        sha256='hash-linux-arm64'",
+				`echo "$sha256  $asset" | sha256sum -c -`,
+				`tar --no-same-owner -xf "$asset" --strip-components 0`,
+				`install -D -m 755 'dist/my-tool' "$CRAFT_PART_INSTALL/bin/mytool"`,
+				"  mytool:\n    command: bin/mytool",
+			},
+		},
+		{
+			kind: Flatpak,
+			want: []string{
+				"app-id: io.github.my_org.my_tool",
+				"command: mytool",
+				"      - install -D -m 755 'dist/my-tool' /app/bin/mytool",
+				"      - type: archive\n        url: https://github.com/my-org/My-Tool/releases/download/v1.2.3/My-Tool_1.2.3_linux_x86_64.tar.gz\n        sha256: hash-linux-amd64\n        strip-components: 0\n        only-arches: [x86_64]",
+				"        only-arches: [aarch64]",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			content, err := release.Render(tt.kind)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			manifest := string(content)
+			if !strings.HasPrefix(manifest, GeneratedHeader+"\n") {
+				t.Errorf("manifest does not start with the generated header:\n%s", manifest)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(manifest, want) {
+					t.Errorf("manifest missing %q:\n%s", want, manifest)
+				}
+			}
+			var parsed map[string]any
+			if err := yaml.Unmarshal(content, &parsed); err != nil {
+				t.Errorf("manifest is not valid YAML: %v\n%s", err, manifest)
+			}
+		})
+	}
+}
+
+func TestRenderRawBinary(t *testing.T) {
+	installSpec := spec.NewInstallSpec("owner/tool").
+		WithAsset(spec.NewAsset("${NAME}-${OS}-${ARCH}")).
+		WithSupportedPlatforms("linux/amd64")
+
+	release, err := New(installSpec, "v2.0.0", fakeHash)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	snap, err := release.Render(Snapcraft)
+	if err != nil {
+		t.Fatalf("Render(snapcraft) error = %v", err)
+	}
+	for _, want := range []string{`mv "$asset" 'tool'`, `install -D -m 755 'tool' "$CRAFT_PART_INSTALL/bin/tool"`} {
+		if !strings.Contains(string(snap), want) {
+			t.Errorf("snapcraft.yaml missing %q:\n%s", want, snap)
+		}
+	}
+	if strings.Contains(string(snap), "arm64") || strings.Contains(string(snap), "tar ") {
+		t.Errorf("snapcraft.yaml includes unsupported platforms or extraction:\n%s", snap)
+	}
+
+	flatpak, err := release.Render(Flatpak)
+	if err != nil {
+		t.Fatalf("Render(flatpak) error = %v", err)
+	}
+	if want := "      - type: file\n        url: https://github.com/owner/tool/releases/download/v2.0.0/tool-linux-amd64\n        sha256: hash-linux-amd64\n        dest-filename: tool\n"; !strings.Contains(string(flatpak), want) {
+		t.Errorf("flatpak manifest missing %q:\n%s", want, flatpak)
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		installSpec *spec.InstallSpec
+		wantErr     string
+	}{
+		{
+			name: "no linux platform",
+			installSpec: spec.NewInstallSpec("owner/tool").
+				WithAsset(spec.NewAsset("${NAME}-${OS}-${ARCH}")).
+				WithSupportedPlatforms("darwin/arm64", "linux/386"),
+			wantErr: "supports neither linux/amd64 nor linux/arm64",
+		},
+		{
+			name: "non-sha256 checksums",
+			installSpec: spec.NewInstallSpec("owner/tool").
+				WithAsset(spec.NewAsset("${NAME}-${OS}-${ARCH}")).
+				WithChecksums(spec.NewChecksums("sums.txt").WithAlgorithm(spec.Sha512)),
+			wantErr: "pin sha256 checksums, got sha512",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.installSpec, "v1.0.0", fakeHash)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("New() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenderCI(t *testing.T) {
+	installSpec := spec.NewInstallSpec("my-org/My-Tool").
+		WithAsset(spec.NewAsset("${NAME}_${VERSION}_${OS}_${ARCH}${EXT}").
+			WithDefaultExtension(".tar.gz").
+			WithBinary("mytool", "dist/my-tool")).
+		WithSupportedPlatforms("linux/amd64", "linux/arm64")
+	release, err := New(installSpec, "v1.2.3", fakeHash)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		kind string
+		want []string
+	}{
+		{
+			kind: AzurePipelines,
+			want: []string{
+				"steps:\n  - bash: |\n",
+				`      bin_dir="${AGENT_TOOLSDIRECTORY:-$HOME/.local/share}"/'my-tool/1.2.3/bin'`,
+				"        x86_64|amd64)\n          url='https://github.com/my-org/My-Tool/releases/download/v1.2.3/My-Tool_1.2.3_linux_amd64.tar.gz'",
+				"          sha256='hash-linux-arm64'",
+				`install -m 755 "$tmp/x/"'dist/my-tool' "$bin_dir/"'mytool'`,
+				`      echo "##vso[task.prependpath]$bin_dir"`,
+				"    displayName: 'Install my-tool 1.2.3'",
+			},
+		},
+		{
+			kind: GitLabCI,
+			want: []string{
+				".install-my-tool:\n  before_script:\n    - |\n",
+				`      bin_dir="${CI_PROJECT_DIR:-$HOME}/.binstaller/bin"`,
+				"        aarch64|arm64)\n",
+				`echo "$sha256  $asset" | sha256sum -c -`,
+				`      export PATH="$bin_dir:$PATH"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			content, err := release.Render(tt.kind)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(content), want) {
+					t.Errorf("template missing %q:\n%s", want, content)
+				}
+			}
+			var parsed map[string]any
+			if err := yaml.Unmarshal(content, &parsed); err != nil {
+				t.Errorf("template is not valid YAML: %v\n%s", err, content)
+			}
+		})
+	}
+}
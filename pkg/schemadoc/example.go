@@ -0,0 +1,332 @@
+package schemadoc
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// Example kinds
+const (
+	ExampleMinimal = "minimal"
+	ExampleFull    = "full"
+)
+
+// ExampleConfig synthesizes an example config from a JSON schema. ExampleMinimal
+// shows only the required fields and ExampleFull every field of the root type;
+// any other kind names a type whose fields are all shown. Values come from the
+// schema defaults and enums, the YAML examples and quoted values of the
+// descriptions, or a placeholder of the field's type. Each field is preceded
+// by the summary of its description as a comment.
+func ExampleConfig(schemaJSON []byte, kind string) ([]byte, error) {
+	p, err := newParser(schemaJSON)
+	if err != nil {
+		return nil, err
+	}
+	w := &exampleWriter{p: p, full: kind != ExampleMinimal, writing: make(map[string]bool)}
+	w.collectSamples()
+
+	name := p.root
+	if kind != ExampleMinimal && kind != ExampleFull {
+		name = p.lookupType(kind)
+		if name == "" {
+			return nil, fmt.Errorf("unknown example %q (supported: %s, %s, or a type: %s)", kind, ExampleMinimal, ExampleFull, strings.Join(p.typeNames(), ", "))
+		}
+	}
+
+	lines := w.object(name, p.defs[name])
+	return []byte(strings.Join(lines, "\n") + "\n"), nil
+}
+
+// lookupType returns the definition name matching name case-insensitively, or ""
+func (p *parser) lookupType(name string) string {
+	for def := range p.defs {
+		if strings.EqualFold(def, name) {
+			return def
+		}
+	}
+	return ""
+}
+
+// typeNames returns the sorted names of the object types of the schema
+func (p *parser) typeNames() []string {
+	var names []string
+	for name, def := range p.defs {
+		if len(asMap(get(def, "properties"))) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// exampleWriter renders example YAML for schema types
+type exampleWriter struct {
+	p    *parser
+	full bool
+	// samples are the parsed YAML examples of the descriptions, those of the
+	// root type first
+	samples []any
+	// writing holds the types being written, to stop at recursive references
+	writing map[string]bool
+	// path holds the keys of the fields being written
+	path []string
+}
+
+// collectSamples parses the YAML code blocks of every type description
+func (w *exampleWriter) collectSamples() {
+	names := []string{w.p.root}
+	for _, name := range w.p.typeNames() {
+		if name != w.p.root {
+			names = append(names, name)
+		}
+	}
+	for _, name := range names {
+		for _, b := range splitBlocks(str(get(w.p.defs[name], "description"))) {
+			if !b.Code || (b.Lang != "" && b.Lang != "yaml") {
+				continue
+			}
+			var sample any
+			if err := yaml.UnmarshalWithOptions([]byte(b.Text), &sample, yaml.UseOrderedMap()); err == nil {
+				w.samples = append(w.samples, sample)
+			}
+		}
+	}
+}
+
+// sample returns the first value of the current field in the description
+// examples that is a scalar, or a list or map when wantCollection is set.
+// Values under the same parent key are preferred, so that checksums.template
+// is not taken from an asset.template example.
+func (w *exampleWriter) sample(wantCollection bool) (any, bool) {
+	if len(w.path) == 0 {
+		return nil, false
+	}
+	key := w.path[len(w.path)-1]
+	if len(w.path) > 1 {
+		parent := w.path[len(w.path)-2]
+		for _, s := range w.samples {
+			if v, ok := findKey(s, "", parent, key, wantCollection); ok {
+				return v, true
+			}
+		}
+	}
+	for _, s := range w.samples {
+		if v, ok := findKey(s, "", "", key, wantCollection); ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// findKey searches v depth-first for key, under the parent key unless parent
+// is empty. within is the key v is the value of.
+func findKey(v any, within, parent, key string, wantCollection bool) (any, bool) {
+	switch v := v.(type) {
+	case yaml.MapSlice:
+		for _, item := range v {
+			k := fmt.Sprint(item.Key)
+			if k == key && (parent == "" || parent == within) && item.Value != nil && isCollection(item.Value) == wantCollection {
+				return item.Value, true
+			}
+			if found, ok := findKey(item.Value, k, parent, key, wantCollection); ok {
+				return found, true
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if found, ok := findKey(item, within, parent, key, wantCollection); ok {
+				return found, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// isCollection reports whether v is a YAML list or map
+func isCollection(v any) bool {
+	switch v.(type) {
+	case yaml.MapSlice, []any:
+		return true
+	}
+	return false
+}
+
+// object returns the example lines of an object type's fields
+func (w *exampleWriter) object(name string, node yaml.MapSlice) []string {
+	if w.writing[name] {
+		return nil
+	}
+	w.writing[name] = true
+	defer delete(w.writing, name)
+
+	required := make(map[string]bool)
+	if list, ok := get(node, "required").([]any); ok {
+		for _, r := range list {
+			required[str(r)] = true
+		}
+	}
+
+	var lines []string
+	for _, item := range asMap(get(node, "properties")) {
+		key := fmt.Sprint(item.Key)
+		if !w.full && !required[key] {
+			continue
+		}
+		prop := asMap(item.Value)
+		if blocks := splitBlocks(str(get(prop, "description"))); len(blocks) > 0 && !blocks[0].Code {
+			lines = append(lines, "# "+strings.Join(strings.Fields(blocks[0].Text), " "))
+		}
+		w.path = append(w.path, key)
+		inline, block := w.value(prop, str(get(prop, "description")))
+		w.path = w.path[:len(w.path)-1]
+		if block == nil {
+			lines = append(lines, key+": "+inline)
+			continue
+		}
+		lines = append(lines, key+":")
+		lines = append(lines, indent(block, "  ", "  ")...)
+	}
+	return lines
+}
+
+// value returns the example of the current field as an inline scalar, or as
+// block lines for objects, lists and maps. description is the description of
+// the field, which may quote example values.
+func (w *exampleWriter) value(node yaml.MapSlice, description string) (string, []string) {
+	if def := get(node, "default"); def != nil {
+		return formatScalar(def), nil
+	}
+	if c := get(node, "const"); c != nil {
+		return formatScalar(c), nil
+	}
+	if ref := get(node, "$ref"); ref != nil {
+		name := refName(ref)
+		if w.writing[name] {
+			// A recursive reference, such as the tools of an InstallSpec
+			return "{}", nil
+		}
+		def := w.p.defs[name]
+		if elem := asMap(get(def, "unevaluatedProperties")); elem != nil && len(asMap(get(def, "properties"))) == 0 {
+			return "", w.mapValue(elem, description)
+		}
+		return "", w.object(name, def)
+	}
+	if alternatives, ok := get(node, "anyOf").([]any); ok && len(alternatives) > 0 {
+		return w.value(asMap(alternatives[0]), description)
+	}
+
+	switch str(get(node, "type")) {
+	case "array":
+		items := asMap(get(node, "items"))
+		inline, block := w.value(items, description)
+		if block == nil {
+			if list, ok := w.sample(true); ok {
+				if l, ok := list.([]any); ok && len(l) > 0 && !isCollection(l[0]) {
+					inline = formatScalar(l[0])
+				}
+			}
+			return "", []string{"- " + inline}
+		}
+		return "", indent(block, "- ", "  ")
+	case "object":
+		if elem := asMap(get(node, "additionalProperties")); elem != nil {
+			return "", w.mapValue(elem, description)
+		}
+		return "{}", nil
+	}
+	return w.scalar(node, description), nil
+}
+
+// mapValue returns the example lines of a map with a single entry
+func (w *exampleWriter) mapValue(elem yaml.MapSlice, description string) []string {
+	entry := "example"
+	if m, ok := w.sample(true); ok {
+		if m, ok := m.(yaml.MapSlice); ok && len(m) > 0 {
+			entry = formatScalar(fmt.Sprint(m[0].Key))
+		}
+	}
+	inline, block := w.value(elem, description)
+	if block == nil {
+		return []string{entry + ": " + inline}
+	}
+	return append([]string{entry + ":"}, indent(block, "  ", "  ")...)
+}
+
+// quotedValue matches a quoted value of a description
+var quotedValue = regexp.MustCompile(`"([^"\n]+)"|'([^'\n]+)'`)
+
+// scalar returns an example of a scalar field
+func (w *exampleWriter) scalar(node yaml.MapSlice, description string) string {
+	typ := str(get(node, "type"))
+	if v, ok := w.sample(false); ok && matchesType(v, typ) {
+		return formatScalar(v)
+	}
+	if typ == "string" {
+		// Prefer the values following "e.g." over other quoted words
+		if _, after, ok := strings.Cut(description, "e.g."); ok {
+			if m := quotedValue.FindStringSubmatch(after); m != nil {
+				return formatScalar(m[1] + m[2])
+			}
+		}
+		if m := quotedValue.FindStringSubmatch(description); m != nil {
+			return formatScalar(m[1] + m[2])
+		}
+		return formatScalar("")
+	}
+	switch typ {
+	case "integer", "number":
+		if min := get(node, "minimum"); min != nil {
+			return fmt.Sprint(min)
+		}
+		return "0"
+	case "boolean":
+		return "false"
+	}
+	return "null"
+}
+
+// matchesType reports whether a sample value fits a JSON schema scalar type
+func matchesType(v any, typ string) bool {
+	switch v.(type) {
+	case string:
+		return typ == "string"
+	case bool:
+		return typ == "boolean"
+	case uint64, int64, int, float64:
+		return typ == "integer" || typ == "number"
+	}
+	return false
+}
+
+// formatScalar formats a scalar as YAML, quoting strings that would otherwise
+// read as another type
+func formatScalar(v any) string {
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Sprint(v)
+	}
+	var parsed any
+	if s != "" && !strings.ContainsAny(s, "\n#:{}[]&*!|>'\"%@`,") && !strings.HasPrefix(s, "- ") &&
+		yaml.Unmarshal([]byte(s), &parsed) == nil && parsed == s {
+		return s
+	}
+	return strconv.Quote(s)
+}
+
+// indent prefixes the first line with first and the following lines with rest
+func indent(lines []string, first, rest string) []string {
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		if i == 0 {
+			out[i] = first + line
+		} else {
+			out[i] = rest + line
+		}
+	}
+	return out
+}
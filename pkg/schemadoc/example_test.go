@@ -0,0 +1,57 @@
+package schemadoc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExampleConfig(t *testing.T) {
+	minimal, err := ExampleConfig([]byte(testSchema), ExampleMinimal)
+	if err != nil {
+		t.Fatalf("ExampleConfig(minimal) error = %v", err)
+	}
+	// repo has no default, so the example of the root description is used
+	if want := "# Repository | owner/name\nrepo: a/b\n"; string(minimal) != want {
+		t.Errorf("ExampleConfig(minimal) =\n%s\nwant\n%s", minimal, want)
+	}
+
+	full, err := ExampleConfig([]byte(testSchema), ExampleFull)
+	if err != nil {
+		t.Fatalf("ExampleConfig(full) error = %v", err)
+	}
+	for _, want := range []string{
+		"items:\n  - sum:\n      hash: \"\"\n",
+		"sums:\n  example:\n    - hash: \"\"\n",
+		"mode: a\n",
+	} {
+		if !strings.Contains(string(full), want) {
+			t.Errorf("ExampleConfig(full) missing %q:\n%s", want, full)
+		}
+	}
+
+	item, err := ExampleConfig([]byte(testSchema), "item")
+	if err != nil || string(item) != "sum:\n  hash: \"\"\n" {
+		t.Errorf("ExampleConfig(item) = %q, %v", item, err)
+	}
+
+	if _, err := ExampleConfig([]byte(testSchema), "nope"); err == nil || !strings.Contains(err.Error(), "Item, Sum") {
+		t.Errorf("ExampleConfig(nope) error = %v, want the list of types", err)
+	}
+}
+
+func TestFormatScalar(t *testing.T) {
+	tests := map[any]string{
+		"mytool":      "mytool",
+		"1.0":         `"1.0"`,
+		"true":        `"true"`,
+		"":            `""`,
+		"${NAME}.zip": `"${NAME}.zip"`,
+		"a: b":        `"a: b"`,
+		42:            "42",
+	}
+	for in, want := range tests {
+		if got := formatScalar(in); got != want {
+			t.Errorf("formatScalar(%v) = %s, want %s", in, got, want)
+		}
+	}
+}
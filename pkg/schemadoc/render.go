@@ -0,0 +1,180 @@
+package schemadoc
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// generatedNotice marks rendered documentation as generated
+const generatedNotice = "Code generated by binst schema. DO NOT EDIT."
+
+// Markdown renders the documentation as GitHub-flavored markdown
+func (d *Doc) Markdown() []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "<!-- %s -->\n\n", generatedNotice)
+	link := func(name string) string {
+		return fmt.Sprintf("[%s](#%s)", name, strings.ToLower(name))
+	}
+	for i, t := range d.Types {
+		level := "###"
+		if i == 0 {
+			level = "#"
+		}
+		fmt.Fprintf(&b, "%s %s\n\n", level, t.Name)
+		writeMarkdownBlocks(&b, t.Description)
+
+		if len(t.Fields) > 0 {
+			b.WriteString("| Field | Type | Required | Default | Description |\n")
+			b.WriteString("|-------|------|----------|---------|-------------|\n")
+			for _, f := range t.Fields {
+				required := ""
+				if f.Required {
+					required = "yes"
+				}
+				fmt.Fprintf(&b, "| `%s` | %s | %s | %s | %s |\n",
+					f.Name, markdownCell(f.Type.Format(link, markdownCode)), required, markdownCode(f.Default), markdownCell(f.Summary))
+			}
+			b.WriteString("\n")
+		}
+
+		for _, f := range t.Fields {
+			if len(f.Details) == 0 {
+				continue
+			}
+			fmt.Fprintf(&b, "**`%s`**\n\n", f.Name)
+			writeMarkdownBlocks(&b, f.Details)
+		}
+
+		if len(t.Examples) > 0 {
+			b.WriteString("**Examples**\n\n")
+			for _, example := range t.Examples {
+				if example.Caption != "" {
+					fmt.Fprintf(&b, "_%s_\n\n", example.Caption)
+				}
+				writeMarkdownBlocks(&b, []Block{example.Code})
+			}
+		}
+
+		if i == 0 && len(d.Types) > 1 {
+			b.WriteString("## Types\n\n")
+		}
+	}
+	return append(bytes.TrimRight(b.Bytes(), "\n"), '\n')
+}
+
+// writeMarkdownBlocks writes description blocks as markdown paragraphs and code blocks
+func writeMarkdownBlocks(b *bytes.Buffer, blocks []Block) {
+	for _, block := range blocks {
+		if block.Code {
+			fmt.Fprintf(b, "```%s\n%s\n```\n\n", block.Lang, block.Text)
+		} else {
+			fmt.Fprintf(b, "%s\n\n", block.Text)
+		}
+	}
+}
+
+// markdownCell escapes text for a single markdown table cell
+func markdownCell(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "|", `\|`), "\n", " ")
+}
+
+// markdownCode formats a value as inline code, or "" for no value
+func markdownCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + s + "`"
+}
+
+// HTML renders the documentation as a standalone HTML page
+func (d *Doc) HTML() []byte {
+	var b bytes.Buffer
+	title := "Reference"
+	if len(d.Types) > 0 {
+		title = d.Types[0].Name + " reference"
+	}
+	fmt.Fprintf(&b, "<!DOCTYPE html>\n<!-- %s -->\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n</head>\n<body>\n", generatedNotice, html.EscapeString(title))
+	link := func(name string) string {
+		return fmt.Sprintf(`<a href="#%s">%s</a>`, strings.ToLower(name), html.EscapeString(name))
+	}
+	for i, t := range d.Types {
+		level := 3
+		if i == 0 {
+			level = 1
+		}
+		fmt.Fprintf(&b, "<section id=\"%s\">\n<h%d>%s</h%d>\n", t.Anchor, level, html.EscapeString(t.Name), level)
+		writeHTMLBlocks(&b, t.Description)
+
+		if len(t.Fields) > 0 {
+			b.WriteString("<table>\n<thead><tr><th>Field</th><th>Type</th><th>Required</th><th>Default</th><th>Description</th></tr></thead>\n<tbody>\n")
+			for _, f := range t.Fields {
+				required := ""
+				if f.Required {
+					required = "yes"
+				}
+				fmt.Fprintf(&b, "<tr><td><code>%s</code></td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+					html.EscapeString(f.Name), f.Type.Format(link, htmlCode), required, htmlCode(f.Default), inlineHTML(f.Summary))
+			}
+			b.WriteString("</tbody>\n</table>\n")
+		}
+
+		for _, f := range t.Fields {
+			if len(f.Details) == 0 {
+				continue
+			}
+			fmt.Fprintf(&b, "<p><strong><code>%s</code></strong></p>\n", html.EscapeString(f.Name))
+			writeHTMLBlocks(&b, f.Details)
+		}
+
+		if len(t.Examples) > 0 {
+			b.WriteString("<p><strong>Examples</strong></p>\n")
+			for _, example := range t.Examples {
+				if example.Caption != "" {
+					fmt.Fprintf(&b, "<p><em>%s</em></p>\n", inlineHTML(example.Caption))
+				}
+				writeHTMLBlocks(&b, []Block{example.Code})
+			}
+		}
+		b.WriteString("</section>\n")
+
+		if i == 0 && len(d.Types) > 1 {
+			b.WriteString("<h2>Types</h2>\n")
+		}
+	}
+	b.WriteString("</body>\n</html>\n")
+	return b.Bytes()
+}
+
+// writeHTMLBlocks writes description blocks as HTML paragraphs and preformatted code
+func writeHTMLBlocks(b *bytes.Buffer, blocks []Block) {
+	for _, block := range blocks {
+		if block.Code {
+			class := ""
+			if block.Lang != "" {
+				class = fmt.Sprintf(` class="language-%s"`, html.EscapeString(block.Lang))
+			}
+			fmt.Fprintf(b, "<pre><code%s>%s</code></pre>\n", class, html.EscapeString(block.Text))
+		} else {
+			fmt.Fprintf(b, "<p>%s</p>\n", strings.ReplaceAll(inlineHTML(block.Text), "\n", "<br>\n"))
+		}
+	}
+}
+
+// inlineCode matches markdown inline code spans
+var inlineCode = regexp.MustCompile("`([^`]+)`")
+
+// inlineHTML escapes text for HTML, rendering markdown inline code spans as <code>
+func inlineHTML(s string) string {
+	return inlineCode.ReplaceAllString(html.EscapeString(s), "<code>$1</code>")
+}
+
+// htmlCode formats a value as HTML code, or "" for no value
+func htmlCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "<code>" + html.EscapeString(s) + "</code>"
+}
@@ -0,0 +1,369 @@
+// Package schemadoc renders the InstallSpec JSON schema as human-readable
+// reference documentation, in markdown or HTML.
+package schemadoc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// Doc is the documentation model of a schema: the root type followed by the
+// types it references, in order of first reference
+type Doc struct {
+	Types []Type
+}
+
+// Type is an object type of the schema
+type Type struct {
+	Name        string
+	Anchor      string
+	Description []Block
+	Examples    []Example
+	Fields      []Field
+}
+
+// Example is a code block of a type description and the paragraph introducing it
+type Example struct {
+	Caption string
+	Code    Block
+}
+
+// Field is a property of an object type
+type Field struct {
+	Name     string
+	Type     TypeExpr
+	Required bool
+	Default  string
+	// Summary is the first paragraph of the description
+	Summary string
+	// Details is the rest of the description, including its examples
+	Details []Block
+}
+
+// Block is a paragraph or fenced code block of a description
+type Block struct {
+	Code bool
+	Lang string
+	Text string
+}
+
+// TypeExpr describes the type of a field
+type TypeExpr struct {
+	// Kind is one of "scalar", "ref", "array", "map", "enum" or "union"
+	Kind string
+	// Name is the scalar type or referenced type name
+	Name string
+	// Elem is the element type of arrays and maps
+	Elem *TypeExpr
+	// Values are the allowed values of an enum
+	Values []string
+	// Alternatives are the types of a union
+	Alternatives []TypeExpr
+	// Constraints are validation keywords such as pattern or minimum
+	Constraints []Constraint
+}
+
+// Constraint is a validation keyword of a type and its value
+type Constraint struct {
+	Keyword string
+	Value   string
+}
+
+// Parse builds the documentation model of a JSON schema
+func Parse(schemaJSON []byte) (*Doc, error) {
+	p, err := newParser(schemaJSON)
+	if err != nil {
+		return nil, err
+	}
+	doc := &Doc{}
+	queue := []string{p.root}
+	seen := map[string]bool{p.root: true}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		t, refs := p.parseType(name, p.defs[name])
+		doc.Types = append(doc.Types, t)
+		for _, ref := range refs {
+			if !seen[ref] {
+				seen[ref] = true
+				queue = append(queue, ref)
+			}
+		}
+	}
+	return doc, nil
+}
+
+// parser resolves references between schema definitions
+type parser struct {
+	// root is the name of the root type, which is also stored in defs
+	root string
+	defs map[string]yaml.MapSlice
+}
+
+// newParser loads a JSON schema, keeping the order of its properties
+func newParser(schemaJSON []byte) (*parser, error) {
+	var root yaml.MapSlice
+	if err := yaml.UnmarshalWithOptions(schemaJSON, &root, yaml.UseOrderedMap()); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	p := &parser{defs: make(map[string]yaml.MapSlice)}
+	if defs, ok := get(root, "$defs").(yaml.MapSlice); ok {
+		for _, item := range defs {
+			if def, ok := item.Value.(yaml.MapSlice); ok {
+				p.defs[fmt.Sprint(item.Key)] = def
+			}
+		}
+	}
+	p.root = strings.TrimSuffix(str(get(root, "$id")), ".json")
+	if p.root == "" {
+		p.root = "InstallSpec"
+	}
+	p.defs[p.root] = root
+	return p, nil
+}
+
+// parseType builds an object type and returns the types its fields reference
+func (p *parser) parseType(name string, node yaml.MapSlice) (Type, []string) {
+	t := Type{Name: name, Anchor: strings.ToLower(name)}
+	for _, b := range splitBlocks(str(get(node, "description"))) {
+		if !b.Code {
+			t.Description = append(t.Description, b)
+			continue
+		}
+		example := Example{Code: b}
+		// A paragraph such as "Minimal example:" introduces the code block that follows it
+		if n := len(t.Description); n > 0 && strings.HasSuffix(t.Description[n-1].Text, ":") {
+			example.Caption = strings.TrimSuffix(t.Description[n-1].Text, ":")
+			t.Description = t.Description[:n-1]
+		}
+		t.Examples = append(t.Examples, example)
+	}
+
+	required := make(map[string]bool)
+	if list, ok := get(node, "required").([]any); ok {
+		for _, r := range list {
+			required[str(r)] = true
+		}
+	}
+
+	var refs []string
+	properties, _ := get(node, "properties").(yaml.MapSlice)
+	for _, item := range properties {
+		prop, _ := item.Value.(yaml.MapSlice)
+		f := Field{
+			Name:     fmt.Sprint(item.Key),
+			Type:     p.parseExpr(prop),
+			Required: required[fmt.Sprint(item.Key)],
+		}
+		if def := get(prop, "default"); def != nil {
+			f.Default = formatValue(def)
+		}
+		blocks := splitBlocks(str(get(prop, "description")))
+		if len(blocks) > 0 && !blocks[0].Code {
+			f.Summary = strings.Join(strings.Fields(blocks[0].Text), " ")
+			blocks = blocks[1:]
+		}
+		f.Details = blocks
+		refs = append(refs, f.Type.refs()...)
+		t.Fields = append(t.Fields, f)
+	}
+	return t, refs
+}
+
+// parseExpr describes the type of a schema node
+func (p *parser) parseExpr(node yaml.MapSlice) TypeExpr {
+	var e TypeExpr
+	switch {
+	case get(node, "$ref") != nil:
+		name := refName(get(node, "$ref"))
+		def := p.defs[name]
+		// Map types such as Record<T[]> are inlined rather than documented separately
+		if elem, ok := get(def, "unevaluatedProperties").(yaml.MapSlice); ok && len(asMap(get(def, "properties"))) == 0 {
+			inner := p.parseExpr(elem)
+			e = TypeExpr{Kind: "map", Elem: &inner}
+		} else {
+			e = TypeExpr{Kind: "ref", Name: name}
+		}
+	case get(node, "anyOf") != nil:
+		alternatives, _ := get(node, "anyOf").([]any)
+		var values []string
+		for _, alt := range alternatives {
+			if c := get(asMap(alt), "const"); c != nil {
+				values = append(values, formatValue(c))
+				continue
+			}
+			e.Alternatives = append(e.Alternatives, p.parseExpr(asMap(alt)))
+		}
+		if len(e.Alternatives) == 0 {
+			e = TypeExpr{Kind: "enum", Values: values}
+		} else {
+			if len(values) > 0 {
+				e.Alternatives = append([]TypeExpr{{Kind: "enum", Values: values}}, e.Alternatives...)
+			}
+			e.Kind = "union"
+		}
+	case str(get(node, "type")) == "array":
+		inner := p.parseExpr(asMap(get(node, "items")))
+		e = TypeExpr{Kind: "array", Elem: &inner}
+	case get(node, "unevaluatedProperties") != nil || get(node, "additionalProperties") != nil:
+		elem := asMap(get(node, "unevaluatedProperties"))
+		if elem == nil {
+			elem = asMap(get(node, "additionalProperties"))
+		}
+		inner := p.parseExpr(elem)
+		e = TypeExpr{Kind: "map", Elem: &inner}
+	default:
+		e = TypeExpr{Kind: "scalar", Name: str(get(node, "type"))}
+		if e.Name == "" {
+			e.Name = "any"
+		}
+	}
+
+	for _, key := range []string{"pattern", "minimum", "maximum"} {
+		if v := get(node, key); v != nil {
+			e.Constraints = append(e.Constraints, Constraint{Keyword: key, Value: fmt.Sprint(v)})
+		}
+	}
+	return e
+}
+
+// refs returns the object types referenced by the expression
+func (e TypeExpr) refs() []string {
+	switch e.Kind {
+	case "ref":
+		return []string{e.Name}
+	case "array", "map":
+		return e.Elem.refs()
+	case "union":
+		var refs []string
+		for _, alt := range e.Alternatives {
+			refs = append(refs, alt.refs()...)
+		}
+		return refs
+	}
+	return nil
+}
+
+// Format renders the expression and its constraints, formatting referenced
+// type names with link and literal values with literal
+func (e TypeExpr) Format(link, literal func(string) string) string {
+	var s string
+	switch e.Kind {
+	case "ref":
+		s = link(e.Name)
+	case "array":
+		s = e.Elem.Format(link, literal) + "[]"
+	case "map":
+		s = "map[string]" + e.Elem.Format(link, literal)
+	case "enum":
+		values := make([]string, len(e.Values))
+		for i, v := range e.Values {
+			values[i] = literal(v)
+		}
+		s = strings.Join(values, " | ")
+	case "union":
+		parts := make([]string, len(e.Alternatives))
+		for i, alt := range e.Alternatives {
+			parts[i] = alt.Format(link, literal)
+		}
+		s = strings.Join(parts, " | ")
+	default:
+		s = e.Name
+	}
+	if len(e.Constraints) > 0 {
+		constraints := make([]string, len(e.Constraints))
+		for i, c := range e.Constraints {
+			constraints[i] = c.Keyword + " " + literal(c.Value)
+		}
+		s += " (" + strings.Join(constraints, ", ") + ")"
+	}
+	return s
+}
+
+// splitBlocks splits a description into paragraphs and fenced code blocks
+func splitBlocks(description string) []Block {
+	var blocks []Block
+	var paragraph []string
+	flush := func() {
+		if len(paragraph) > 0 {
+			blocks = append(blocks, Block{Text: strings.Join(paragraph, "\n")})
+			paragraph = nil
+		}
+	}
+
+	lines := strings.Split(description, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if fence, ok := strings.CutPrefix(strings.TrimSpace(line), "```"); ok {
+			flush()
+			code := Block{Code: true, Lang: strings.TrimSpace(fence)}
+			var body []string
+			for i++; i < len(lines) && strings.TrimSpace(lines[i]) != "```"; i++ {
+				body = append(body, lines[i])
+			}
+			code.Text = strings.Join(body, "\n")
+			blocks = append(blocks, code)
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		paragraph = append(paragraph, line)
+	}
+	flush()
+	return blocks
+}
+
+// get returns the value of key in an ordered map, or nil
+func get(m yaml.MapSlice, key string) any {
+	for _, item := range m {
+		if fmt.Sprint(item.Key) == key {
+			return item.Value
+		}
+	}
+	return nil
+}
+
+// asMap returns v as an ordered map, or nil
+func asMap(v any) yaml.MapSlice {
+	m, _ := v.(yaml.MapSlice)
+	return m
+}
+
+// str returns v as a string, or ""
+// refName returns the definition name a $ref points to. The root type refers
+// to itself by the schema $id, e.g. InstallSpec.json.
+func refName(ref any) string {
+	return strings.TrimSuffix(strings.TrimPrefix(str(ref), "#/$defs/"), ".json")
+}
+
+func str(v any) string {
+	s, _ := v.(string)
+	return s
+}
+
+// formatValue formats a schema value the way it is written in a config file
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return fmt.Sprintf("%q", v)
+	case []any:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = formatValue(item)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	case yaml.MapSlice:
+		keys := make([]string, len(v))
+		for i, item := range v {
+			keys[i] = fmt.Sprintf("%v: %s", item.Key, formatValue(item.Value))
+		}
+		sort.Strings(keys)
+		return "{" + strings.Join(keys, ", ") + "}"
+	}
+	return fmt.Sprint(v)
+}
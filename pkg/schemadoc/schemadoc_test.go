@@ -0,0 +1,107 @@
+package schemadoc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testSchema = `{
+  "$id": "Config.json",
+  "type": "object",
+  "required": ["repo"],
+  "description": "Root config.\n\nMinimal example:\n` + "```yaml\\nrepo: a/b\\n```" + `",
+  "properties": {
+    "repo": {"type": "string", "pattern": "^[^/]+/[^/]+$", "description": "Repository | owner/name"},
+    "items": {"type": "array", "items": {"$ref": "#/$defs/Item"}, "description": "Items.\n\nMore about items."},
+    "sums": {"$ref": "#/$defs/RecordArraySum"},
+    "mode": {"anyOf": [{"type": "string", "const": "a"}, {"type": "string", "const": "b"}], "default": "a"}
+  },
+  "$defs": {
+    "Item": {"type": "object", "properties": {"sum": {"$ref": "#/$defs/Sum"}}},
+    "Sum": {"type": "object", "properties": {"hash": {"type": "string"}}},
+    "RecordArraySum": {"type": "object", "properties": {}, "unevaluatedProperties": {"type": "array", "items": {"$ref": "#/$defs/Sum"}}}
+  }
+}`
+
+func TestParse(t *testing.T) {
+	doc, err := Parse([]byte(testSchema))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	var names []string
+	for _, typ := range doc.Types {
+		names = append(names, typ.Name)
+	}
+	if diff := cmp.Diff([]string{"Config", "Item", "Sum"}, names); diff != "" {
+		t.Errorf("Parse() types mismatch (-want +got):\n%s", diff)
+	}
+
+	root := doc.Types[0]
+	if len(root.Examples) != 1 || root.Examples[0].Caption != "Minimal example" || root.Examples[0].Code.Text != "repo: a/b" {
+		t.Errorf("Parse() root examples = %+v, want the captioned yaml block", root.Examples)
+	}
+	if len(root.Description) != 1 || root.Description[0].Text != "Root config." {
+		t.Errorf("Parse() root description = %+v, want the caption removed", root.Description)
+	}
+
+	plain := func(s string) string { return s }
+	fields := make(map[string]Field)
+	for _, f := range root.Fields {
+		fields[f.Name] = f
+	}
+	tests := map[string]string{
+		"repo":  "string (pattern ^[^/]+/[^/]+$)",
+		"items": "Item[]",
+		"sums":  "map[string]Sum[]",
+		"mode":  `"a" | "b"`,
+	}
+	for name, want := range tests {
+		if got := fields[name].Type.Format(plain, plain); got != want {
+			t.Errorf("%s: Format() = %q, want %q", name, got, want)
+		}
+	}
+	if !fields["repo"].Required || fields["items"].Required {
+		t.Error("Parse() did not mark only repo as required")
+	}
+	if fields["mode"].Default != `"a"` {
+		t.Errorf("mode default = %q, want %q", fields["mode"].Default, `"a"`)
+	}
+	if fields["items"].Summary != "Items." || len(fields["items"].Details) != 1 {
+		t.Errorf("items summary = %q, details = %+v", fields["items"].Summary, fields["items"].Details)
+	}
+}
+
+func TestRender(t *testing.T) {
+	doc, err := Parse([]byte(testSchema))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	md := string(doc.Markdown())
+	for _, want := range []string{
+		"# Config\n",
+		"| `repo` | string (pattern `^[^/]+/[^/]+$`) | yes |  | Repository \\| owner/name |",
+		"| `items` | [Item](#item)[] |",
+		"_Minimal example_\n\n```yaml\nrepo: a/b\n```",
+		"## Types\n\n### Item\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown() missing %q:\n%s", want, md)
+		}
+	}
+
+	page := string(doc.HTML())
+	for _, want := range []string{
+		`<section id="item">`,
+		`<a href="#sum">Sum</a>[]`,
+		`<pre><code class="language-yaml">repo: a/b</code></pre>`,
+		`<td>Repository | owner/name</td>`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("HTML() missing %q:\n%s", want, page)
+		}
+	}
+}
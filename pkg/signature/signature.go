@@ -0,0 +1,155 @@
+// Package signature verifies detached GPG (OpenPGP) and minisign signatures on
+// release files, such as a checksums.txt.sig or checksums.txt.minisig published
+// next to the checksum file.
+package signature
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"golang.org/x/crypto/blake2b"
+)
+
+// VerifyGPG verifies a detached OpenPGP signature of content, armored or binary,
+// against keyring, an armored or binary set of public keys. It returns the
+// fingerprint of the key that made the signature.
+func VerifyGPG(keyring, content, signature []byte) (string, error) {
+	keys, err := readKeyRing(keyring)
+	if err != nil {
+		return "", fmt.Errorf("failed to read public keys: %w", err)
+	}
+	if len(keys) == 0 {
+		return "", errors.New("no public keys found")
+	}
+
+	var signer *openpgp.Entity
+	if isArmored(signature) {
+		signer, err = openpgp.CheckArmoredDetachedSignature(keys, bytes.NewReader(content), bytes.NewReader(signature), nil)
+	} else {
+		signer, err = openpgp.CheckDetachedSignature(keys, bytes.NewReader(content), bytes.NewReader(signature), nil)
+	}
+	if err != nil {
+		return "", fmt.Errorf("invalid gpg signature: %w", err)
+	}
+	return fmt.Sprintf("%X", signer.PrimaryKey.Fingerprint), nil
+}
+
+// readKeyRing reads binary public keys, or all blocks of armored ones as
+// exported by several 'gpg --export --armor' runs
+func readKeyRing(keyring []byte) (openpgp.EntityList, error) {
+	if !isArmored(keyring) {
+		return openpgp.ReadKeyRing(bytes.NewReader(keyring))
+	}
+	const end = "-----END PGP PUBLIC KEY BLOCK-----"
+	var keys openpgp.EntityList
+	for _, block := range strings.SplitAfter(string(keyring), end) {
+		if !strings.Contains(block, end) {
+			continue
+		}
+		entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(block))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, entities...)
+	}
+	return keys, nil
+}
+
+// isArmored reports whether data is ASCII armored
+func isArmored(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte("-----BEGIN "))
+}
+
+// minisign algorithms: Ed signs the content and ED its BLAKE2b-512 hash
+const (
+	minisignLegacy    = "Ed"
+	minisignPrehashed = "ED"
+)
+
+// VerifyMinisign verifies a minisign signature of content against publicKey,
+// either the base64 key line or the whole .pub file. The trusted comment of
+// the signature is verified too and returned.
+func VerifyMinisign(publicKey, content, signature []byte) (string, error) {
+	keyID, key, err := parseMinisignKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	lines := minisignLines(signature)
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "untrusted comment:") || !strings.HasPrefix(lines[2], "trusted comment:") {
+		return "", errors.New("malformed minisign signature")
+	}
+	sig, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(sig) != 2+8+ed25519.SignatureSize {
+		return "", errors.New("malformed minisign signature")
+	}
+	algorithm, sigKeyID, sigBytes := string(sig[:2]), sig[2:10], sig[10:]
+	if !bytes.Equal(sigKeyID, keyID) {
+		return "", fmt.Errorf("signed by key %X, not the trusted key %X", reverse(sigKeyID), reverse(keyID))
+	}
+
+	message := content
+	switch algorithm {
+	case minisignLegacy:
+	case minisignPrehashed:
+		hash := blake2b.Sum512(content)
+		message = hash[:]
+	default:
+		return "", fmt.Errorf("unsupported minisign signature algorithm %q", algorithm)
+	}
+	if !ed25519.Verify(key, message, sigBytes) {
+		return "", errors.New("invalid minisign signature")
+	}
+
+	trustedComment := strings.TrimPrefix(strings.TrimPrefix(lines[2], "trusted comment:"), " ")
+	globalSig, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil || len(globalSig) != ed25519.SignatureSize {
+		return "", errors.New("malformed minisign trusted comment signature")
+	}
+	if !ed25519.Verify(key, append(append([]byte{}, sigBytes...), trustedComment...), globalSig) {
+		return "", errors.New("invalid minisign trusted comment signature")
+	}
+	return trustedComment, nil
+}
+
+// parseMinisignKey returns the key ID and Ed25519 key of a minisign public key
+func parseMinisignKey(publicKey []byte) ([]byte, ed25519.PublicKey, error) {
+	lines := minisignLines(publicKey)
+	if len(lines) > 0 && strings.HasPrefix(lines[0], "untrusted comment:") {
+		lines = lines[1:]
+	}
+	if len(lines) != 1 {
+		return nil, nil, errors.New("malformed minisign public key")
+	}
+	raw, err := base64.StdEncoding.DecodeString(lines[0])
+	if err != nil || len(raw) != 2+8+ed25519.PublicKeySize || string(raw[:2]) != minisignLegacy {
+		return nil, nil, errors.New("malformed minisign public key")
+	}
+	return raw[2:10], ed25519.PublicKey(raw[10:]), nil
+}
+
+// minisignLines returns the non-empty lines of a minisign file
+func minisignLines(data []byte) []string {
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimRight(line, "\r"); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// reverse returns b in reverse order; minisign prints little-endian key IDs
+// as big-endian hex
+func reverse(b []byte) []byte {
+	out := make([]byte, len(b))
+	for i := range b {
+		out[len(b)-1-i] = b[i]
+	}
+	return out
+}
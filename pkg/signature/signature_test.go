@@ -0,0 +1,147 @@
+package signature
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"golang.org/x/crypto/blake2b"
+)
+
+const content = "abc123  tool_1.0.0_linux_amd64.tar.gz\n"
+
+// newGPGKey returns a new signing key and its armored public key
+func newGPGKey(t *testing.T, name string) (*openpgp.Entity, []byte) {
+	t.Helper()
+	entity, err := openpgp.NewEntity(name, "", name+"@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := entity.Serialize(w); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	return entity, buf.Bytes()
+}
+
+func TestVerifyGPG(t *testing.T) {
+	signer, publicKey := newGPGKey(t, "release")
+	_, otherKey := newGPGKey(t, "other")
+
+	var armored, binary bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&armored, signer, strings.NewReader(content), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := openpgp.DetachSign(&binary, signer, strings.NewReader(content), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, sig := range map[string][]byte{"armored": armored.Bytes(), "binary": binary.Bytes()} {
+		t.Run(name, func(t *testing.T) {
+			fingerprint, err := VerifyGPG(publicKey, []byte(content), sig)
+			if err != nil {
+				t.Fatalf("VerifyGPG() error = %v", err)
+			}
+			if want := fmt.Sprintf("%X", signer.PrimaryKey.Fingerprint); fingerprint != want {
+				t.Errorf("VerifyGPG() = %s, want %s", fingerprint, want)
+			}
+		})
+	}
+
+	t.Run("keyring with several keys", func(t *testing.T) {
+		if _, err := VerifyGPG(append(append([]byte{}, otherKey...), publicKey...), []byte(content), armored.Bytes()); err != nil {
+			t.Errorf("VerifyGPG() error = %v", err)
+		}
+	})
+	t.Run("modified content", func(t *testing.T) {
+		if _, err := VerifyGPG(publicKey, []byte(content+"evil  tool\n"), armored.Bytes()); err == nil {
+			t.Error("VerifyGPG() accepted a signature of other content")
+		}
+	})
+	t.Run("untrusted key", func(t *testing.T) {
+		if _, err := VerifyGPG(otherKey, []byte(content), armored.Bytes()); err == nil {
+			t.Error("VerifyGPG() accepted a signature by an untrusted key")
+		}
+	})
+}
+
+// minisignFiles returns a minisign public key and a signature of content made
+// with algorithm, in the formats of the minisign tool
+func minisignFiles(t *testing.T, algorithm, trustedComment string) (publicKey, signature []byte, private ed25519.PrivateKey) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyID := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	publicKey = []byte("untrusted comment: minisign public key 0807060504030201\n" +
+		base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), keyID...), public...)) + "\n")
+
+	message := []byte(content)
+	if algorithm == minisignPrehashed {
+		hash := blake2b.Sum512(message)
+		message = hash[:]
+	}
+	sig := ed25519.Sign(private, message)
+	global := ed25519.Sign(private, append(append([]byte{}, sig...), trustedComment...))
+	signature = []byte("untrusted comment: signature from minisign secret key\n" +
+		base64.StdEncoding.EncodeToString(append(append([]byte(algorithm), keyID...), sig...)) + "\n" +
+		"trusted comment: " + trustedComment + "\n" +
+		base64.StdEncoding.EncodeToString(global) + "\n")
+	return publicKey, signature, private
+}
+
+func TestVerifyMinisign(t *testing.T) {
+	for _, algorithm := range []string{minisignLegacy, minisignPrehashed} {
+		t.Run(algorithm, func(t *testing.T) {
+			publicKey, signature, _ := minisignFiles(t, algorithm, "timestamp:1714521600\tfile:checksums.txt")
+			comment, err := VerifyMinisign(publicKey, []byte(content), signature)
+			if err != nil {
+				t.Fatalf("VerifyMinisign() error = %v", err)
+			}
+			if comment != "timestamp:1714521600\tfile:checksums.txt" {
+				t.Errorf("VerifyMinisign() = %q, want the trusted comment", comment)
+			}
+			// The key line alone is accepted too
+			keyLine := strings.Split(string(publicKey), "\n")[1]
+			if _, err := VerifyMinisign([]byte(keyLine), []byte(content), signature); err != nil {
+				t.Errorf("VerifyMinisign() with the key line error = %v", err)
+			}
+		})
+	}
+
+	publicKey, signature, _ := minisignFiles(t, minisignPrehashed, "file:checksums.txt")
+	t.Run("modified content", func(t *testing.T) {
+		if _, err := VerifyMinisign(publicKey, []byte(content+"evil  tool\n"), signature); err == nil {
+			t.Error("VerifyMinisign() accepted a signature of other content")
+		}
+	})
+	t.Run("modified trusted comment", func(t *testing.T) {
+		forged := strings.Replace(string(signature), "file:checksums.txt", "file:other.txt", 1)
+		if _, err := VerifyMinisign(publicKey, []byte(content), []byte(forged)); err == nil || !strings.Contains(err.Error(), "trusted comment") {
+			t.Errorf("VerifyMinisign() error = %v, want a trusted comment error", err)
+		}
+	})
+	t.Run("untrusted key", func(t *testing.T) {
+		otherKey, _, _ := minisignFiles(t, minisignPrehashed, "")
+		if _, err := VerifyMinisign(otherKey, []byte(content), signature); err == nil {
+			t.Error("VerifyMinisign() accepted a signature by an untrusted key")
+		}
+	})
+	t.Run("malformed signature", func(t *testing.T) {
+		if _, err := VerifyMinisign(publicKey, []byte(content), []byte("not a signature")); err == nil {
+			t.Error("VerifyMinisign() accepted a malformed signature")
+		}
+	})
+}
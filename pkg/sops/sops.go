@@ -0,0 +1,86 @@
+// Package sops decrypts InstallSpecs encrypted with SOPS (https://getsops.io).
+//
+// SOPS encrypts selected values of a YAML or JSON document in place, e.g.
+//
+//	sops encrypt --age age1... --encrypted-regex '^(url|template)$' -i .config/binstaller.yml
+//
+// so that a spec for an internal tool can live in a public repository while its
+// private mirror URLs stay encrypted. Decryption is delegated to the sops
+// binary, which finds age, PGP and cloud KMS keys the usual way
+// (SOPS_AGE_KEY_FILE, ~/.config/sops/age/keys.txt, ...).
+package sops
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goccy/go-yaml"
+)
+
+// EnvBinary overrides the path of the sops binary
+const EnvBinary = "BINSTALLER_SOPS"
+
+// ErrNotInstalled is returned when an encrypted spec is read without sops on the PATH
+var ErrNotInstalled = errors.New("sops is not installed")
+
+// IsEncrypted reports whether a YAML or JSON document carries SOPS metadata
+func IsEncrypted(data []byte) bool {
+	var doc struct {
+		Sops *struct {
+			MAC string `yaml:"mac"`
+		} `yaml:"sops"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return false
+	}
+	return doc.Sops != nil && doc.Sops.MAC != ""
+}
+
+// Decrypt returns the plaintext of a SOPS-encrypted YAML or JSON document, in
+// the same format
+func Decrypt(ctx context.Context, data []byte) ([]byte, error) {
+	binary := os.Getenv(EnvBinary)
+	if binary == "" {
+		binary = "sops"
+	}
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return nil, fmt.Errorf("%w: the spec is encrypted and %s was not found: %w", ErrNotInstalled, binary, err)
+	}
+
+	format := "yaml"
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		format = "json"
+	}
+
+	// sops reads files, so the encrypted document is passed through a private temp file
+	dir, err := os.MkdirTemp("", "binst-sops-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	encrypted := filepath.Join(dir, "spec."+format)
+	if err := os.WriteFile(encrypted, data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write encrypted spec: %w", err)
+	}
+
+	log.Debugf("Decrypting spec with %s", path)
+	var stdout, stderr bytes.Buffer
+	c := exec.CommandContext(ctx, path, "--decrypt", "--input-type", format, "--output-type", format, encrypted)
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+	if err := c.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("sops failed to decrypt the spec: %s: %w", msg, err)
+		}
+		return nil, fmt.Errorf("sops failed to decrypt the spec: %w", err)
+	}
+	return stdout.Bytes(), nil
+}
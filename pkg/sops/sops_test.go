@@ -0,0 +1,72 @@
+package sops
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const encryptedSpec = `repo: owner/tool
+asset:
+    template: ENC[AES256_GCM,data:abc,iv:def,tag:ghi,type:str]
+sops:
+    age:
+        - recipient: age1example
+    mac: ENC[AES256_GCM,data:mac,iv:iv,tag:tag,type:str]
+    version: 3.9.0
+`
+
+func TestIsEncrypted(t *testing.T) {
+	tests := map[string]bool{
+		encryptedSpec:        true,
+		"repo: owner/tool\n": false,
+		`{"repo": "owner/tool", "sops": {"mac": "ENC[...]"}}`: true,
+		"sops: enabled\n": false,
+		"not: [valid":     false,
+	}
+	for data, want := range tests {
+		if got := IsEncrypted([]byte(data)); got != want {
+			t.Errorf("IsEncrypted(%q) = %v, want %v", data, got, want)
+		}
+	}
+}
+
+func TestDecrypt(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake sops is a shell script")
+	}
+	dir := t.TempDir()
+	fake := filepath.Join(dir, "sops")
+	// The fake sops checks its arguments and prints the plaintext
+	script := `#!/bin/sh
+[ "$1 $2 $3 $4 $5" = "--decrypt --input-type yaml --output-type yaml" ] || { echo "bad args: $*" >&2; exit 1; }
+grep -q age1example "$6" || { echo "no metadata" >&2; exit 1; }
+printf 'repo: owner/tool\nasset:\n  template: secret\n'
+`
+	if err := os.WriteFile(fake, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvBinary, fake)
+
+	got, err := Decrypt(context.Background(), []byte(encryptedSpec))
+	if err != nil {
+		t.Fatalf("Decrypt() error = %v", err)
+	}
+	if !strings.Contains(string(got), "template: secret") {
+		t.Errorf("Decrypt() = %q, want the plaintext", got)
+	}
+
+	// Errors from sops are reported
+	if _, err := Decrypt(context.Background(), []byte(`{"sops": {"mac": "x"}}`)); err == nil || !strings.Contains(err.Error(), "bad args") {
+		t.Errorf("Decrypt(json) error = %v, want the sops error", err)
+	}
+
+	t.Setenv(EnvBinary, filepath.Join(dir, "missing"))
+	if _, err := Decrypt(context.Background(), []byte(encryptedSpec)); !errors.Is(err, ErrNotInstalled) {
+		t.Errorf("Decrypt() without sops error = %v, want ErrNotInstalled", err)
+	}
+}
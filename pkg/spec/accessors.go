@@ -0,0 +1,1098 @@
+package spec
+
+import "strings"
+
+// This file provides a hand-written accessor and builder layer on top of the
+// quicktype-generated types in generated.go. The generated structs use pointers
+// for every field so that "unset" can be distinguished from "zero value", which
+// makes them awkward to construct and read from library code. The Get methods
+// below are nil-safe and return the documented schema defaults, and the With
+// methods set a field and return the receiver so specs can be built fluently:
+//
+//	s := spec.NewInstallSpec("owner/tool").
+//		WithAsset(spec.NewAsset("${NAME}_${VERSION}_${OS}_${ARCH}${EXT}").
+//			WithDefaultExtension(".tar.gz")).
+//		WithChecksums(spec.NewChecksums("checksums.txt"))
+
+const (
+	// DefaultSchema is the schema version used when 'schema' is not set
+	DefaultSchema = "v1"
+	// DefaultVersionValue is the version installed when 'default_version' is not set
+	DefaultVersionValue = "latest"
+	// DefaultBinDirValue is the install directory used when 'default_bin_dir' is not set
+	DefaultBinDirValue = "${BINSTALLER_BIN:-${HOME}/.local/bin}"
+	// DefaultGitHubHost is the host of GitHub releases
+	DefaultGitHubHost = "github.com"
+	// DefaultGitLabHost is the GitLab instance used when 'host' is not set
+	DefaultGitLabHost = "gitlab.com"
+)
+
+// NewInstallSpec returns an InstallSpec for the given GitHub repository
+func NewInstallSpec(repo string) *InstallSpec {
+	return &InstallSpec{Repo: StringPtrOrNil(repo)}
+}
+
+// GetSchema returns the schema version, defaulting to "v1"
+func (s *InstallSpec) GetSchema() string {
+	if s == nil || StringValue(s.Schema) == "" {
+		return DefaultSchema
+	}
+	return *s.Schema
+}
+
+// GetName returns the binary name, falling back to the repository name
+func (s *InstallSpec) GetName() string {
+	if s == nil {
+		return ""
+	}
+	if name := StringValue(s.Name); name != "" {
+		return name
+	}
+	repo := StringValue(s.Repo)
+	if i := strings.LastIndex(repo, "/"); i >= 0 {
+		return repo[i+1:]
+	}
+	return ""
+}
+
+// GetRepo returns the repository in 'owner/repo' format
+func (s *InstallSpec) GetRepo() string {
+	if s == nil {
+		return ""
+	}
+	return StringValue(s.Repo)
+}
+
+// GetSource returns the service hosting the releases, defaulting to GitHub
+func (s *InstallSpec) GetSource() ReleaseSource {
+	if s == nil || s.Source == nil {
+		return Github
+	}
+	return *s.Source
+}
+
+// GetHost returns the host of the release service: the configured host of a
+// GitLab or GitHub Enterprise Server instance, gitlab.com, or github.com
+func (s *InstallSpec) GetHost() string {
+	if host := StringValue(s.Host); host != "" {
+		return host
+	}
+	if s.GetSource() == Gitlab {
+		return DefaultGitLabHost
+	}
+	return DefaultGitHubHost
+}
+
+// GetDefaultVersion returns the default version, defaulting to "latest"
+func (s *InstallSpec) GetDefaultVersion() string {
+	if s == nil || StringValue(s.DefaultVersion) == "" {
+		return DefaultVersionValue
+	}
+	return *s.DefaultVersion
+}
+
+// GetDefaultBinDir returns the default installation directory
+func (s *InstallSpec) GetDefaultBinDir() string {
+	if s == nil || StringValue(s.DefaultBinDir) == "" {
+		return DefaultBinDirValue
+	}
+	return *s.DefaultBinDir
+}
+
+// GetMetadata returns the project metadata or nil
+func (s *InstallSpec) GetMetadata() *Metadata {
+	if s == nil {
+		return nil
+	}
+	return s.Metadata
+}
+
+// GetLicense returns the project license
+func (m *Metadata) GetLicense() string {
+	if m == nil {
+		return ""
+	}
+	return StringValue(m.License)
+}
+
+// GetHomepage returns the project homepage URL
+func (m *Metadata) GetHomepage() string {
+	if m == nil {
+		return ""
+	}
+	return StringValue(m.Homepage)
+}
+
+// GetSecurityContact returns where to report security vulnerabilities
+func (m *Metadata) GetSecurityContact() string {
+	if m == nil {
+		return ""
+	}
+	return StringValue(m.SecurityContact)
+}
+
+// GetScriptHeader returns the header of generated scripts or nil
+func (s *InstallSpec) GetScriptHeader() *ScriptHeader {
+	if s == nil {
+		return nil
+	}
+	return s.ScriptHeader
+}
+
+// GetCopyright returns the copyright line of the script header
+func (h *ScriptHeader) GetCopyright() string {
+	if h == nil {
+		return ""
+	}
+	return StringValue(h.Copyright)
+}
+
+// GetLicense returns the SPDX license identifier of the generated scripts
+func (h *ScriptHeader) GetLicense() string {
+	if h == nil {
+		return ""
+	}
+	return StringValue(h.License)
+}
+
+// GetSupportURL returns where users of the scripts get support
+func (h *ScriptHeader) GetSupportURL() string {
+	if h == nil {
+		return ""
+	}
+	return StringValue(h.SupportURL)
+}
+
+// GetText returns the free-form text of the script header
+func (h *ScriptHeader) GetText() string {
+	if h == nil {
+		return ""
+	}
+	return StringValue(h.Text)
+}
+
+// Lines returns the comment lines of the script header, without comment
+// markers: the copyright, the SPDX-License-Identifier and support lines, then
+// the lines of the text
+func (h *ScriptHeader) Lines() []string {
+	var lines []string
+	if copyright := h.GetCopyright(); copyright != "" {
+		lines = append(lines, copyright)
+	}
+	if license := h.GetLicense(); license != "" {
+		lines = append(lines, "SPDX-License-Identifier: "+license)
+	}
+	if supportURL := h.GetSupportURL(); supportURL != "" {
+		lines = append(lines, "Support: "+supportURL)
+	}
+	if text := strings.TrimRight(h.GetText(), "\n"); text != "" {
+		lines = append(lines, strings.Split(text, "\n")...)
+	}
+	return lines
+}
+
+// GetAsset returns the asset configuration or nil
+func (s *InstallSpec) GetAsset() *Asset {
+	if s == nil {
+		return nil
+	}
+	return s.Asset
+}
+
+// GetChecksums returns the checksum configuration or nil
+func (s *InstallSpec) GetChecksums() *Checksums {
+	if s == nil {
+		return nil
+	}
+	return s.Checksums
+}
+
+// GetUnpack returns the archive extraction configuration or nil
+func (s *InstallSpec) GetUnpack() *Unpack {
+	if s == nil {
+		return nil
+	}
+	return s.Unpack
+}
+
+// GetVersion returns the version resolution configuration or nil
+func (s *InstallSpec) GetVersion() *Version {
+	if s == nil {
+		return nil
+	}
+	return s.Version
+}
+
+// WithSchema sets the schema version
+func (s *InstallSpec) WithSchema(schema string) *InstallSpec {
+	s.Schema = StringPtrOrNil(schema)
+	return s
+}
+
+// WithName sets the binary name
+func (s *InstallSpec) WithName(name string) *InstallSpec {
+	s.Name = StringPtrOrNil(name)
+	return s
+}
+
+// WithRepo sets the GitHub repository
+func (s *InstallSpec) WithRepo(repo string) *InstallSpec {
+	s.Repo = StringPtrOrNil(repo)
+	return s
+}
+
+// WithSource sets the service hosting the releases and the host of a self-managed instance
+func (s *InstallSpec) WithSource(source ReleaseSource, host string) *InstallSpec {
+	s.Source = &source
+	s.Host = StringPtrOrNil(host)
+	return s
+}
+
+// WithDefaultVersion sets the default version to install
+func (s *InstallSpec) WithDefaultVersion(version string) *InstallSpec {
+	s.DefaultVersion = StringPtrOrNil(version)
+	return s
+}
+
+// WithDefaultBinDir sets the default installation directory
+func (s *InstallSpec) WithDefaultBinDir(dir string) *InstallSpec {
+	s.DefaultBinDir = StringPtrOrNil(dir)
+	return s
+}
+
+// WithVersion sets the version resolution configuration
+func (s *InstallSpec) WithVersion(version *Version) *InstallSpec {
+	s.Version = version
+	return s
+}
+
+// WithAsset sets the asset configuration
+func (s *InstallSpec) WithAsset(asset *Asset) *InstallSpec {
+	s.Asset = asset
+	return s
+}
+
+// WithChecksums sets the checksum configuration
+func (s *InstallSpec) WithChecksums(checksums *Checksums) *InstallSpec {
+	s.Checksums = checksums
+	return s
+}
+
+// WithAttestation sets the artifact attestation verification configuration
+func (s *InstallSpec) WithAttestation(attestation *Attestation) *InstallSpec {
+	s.Attestation = attestation
+	return s
+}
+
+// WithUnpack sets the archive extraction configuration
+func (s *InstallSpec) WithUnpack(unpack *Unpack) *InstallSpec {
+	s.Unpack = unpack
+	return s
+}
+
+// WithSupportedPlatforms appends platforms in "os/arch" form to the supported platforms
+func (s *InstallSpec) WithSupportedPlatforms(platforms ...string) *InstallSpec {
+	for _, p := range platforms {
+		goos, goarch, _ := strings.Cut(p, "/")
+		s.SupportedPlatforms = append(s.SupportedPlatforms, Platform{
+			OS:   SupportedPlatformOSPtr(goos),
+			Arch: SupportedPlatformArchPtr(goarch),
+		})
+	}
+	return s
+}
+
+// NewAsset returns an asset configuration with the given filename template
+func NewAsset(template string) *Asset {
+	return &Asset{Template: StringPtrOrNil(template)}
+}
+
+// GetTemplate returns the asset filename template
+func (a *Asset) GetTemplate() string {
+	if a == nil {
+		return ""
+	}
+	return StringValue(a.Template)
+}
+
+// GetDefaultExtension returns the default file extension
+func (a *Asset) GetDefaultExtension() string {
+	if a == nil {
+		return ""
+	}
+	return StringValue(a.DefaultExtension)
+}
+
+// GetOSNamingConvention returns the ${OS} casing, defaulting to lowercase
+func (a *Asset) GetOSNamingConvention() NamingConventionOS {
+	if a == nil || a.NamingConvention == nil || a.NamingConvention.OS == nil {
+		return OSLowercase
+	}
+	return *a.NamingConvention.OS
+}
+
+// GetRosetta2 reports whether Rosetta 2 emulation is enabled
+func (a *Asset) GetRosetta2() bool {
+	if a == nil || a.ArchEmulation == nil || a.ArchEmulation.Rosetta2 == nil {
+		return false
+	}
+	return *a.ArchEmulation.Rosetta2
+}
+
+// WithDefaultExtension sets the default file extension
+func (a *Asset) WithDefaultExtension(ext string) *Asset {
+	a.DefaultExtension = StringPtr(ext)
+	return a
+}
+
+// WithBinary appends a binary with the given name and path
+func (a *Asset) WithBinary(name, path string) *Asset {
+	a.Binaries = append(a.Binaries, BinaryElement{Name: StringPtr(name), Path: StringPtr(path)})
+	return a
+}
+
+// WithRules appends platform-specific rules
+func (a *Asset) WithRules(rules ...*RuleElement) *Asset {
+	for _, r := range rules {
+		a.Rules = append(a.Rules, *r)
+	}
+	return a
+}
+
+// WithOSNamingConvention sets the ${OS} casing
+func (a *Asset) WithOSNamingConvention(nc NamingConventionOS) *Asset {
+	if a.NamingConvention == nil {
+		a.NamingConvention = &NamingConvention{}
+	}
+	a.NamingConvention.OS = &nc
+	return a
+}
+
+// WithRosetta2 enables or disables Rosetta 2 emulation
+func (a *Asset) WithRosetta2(enabled bool) *Asset {
+	if a.ArchEmulation == nil {
+		a.ArchEmulation = &ArchEmulation{}
+	}
+	a.ArchEmulation.Rosetta2 = &enabled
+	return a
+}
+
+// GetDelta returns the delta update configuration, or nil if delta updates are disabled
+func (a *Asset) GetDelta() *Delta {
+	if a == nil {
+		return nil
+	}
+	return a.Delta
+}
+
+// WithDelta sets the delta update configuration
+func (a *Asset) WithDelta(delta *Delta) *Asset {
+	a.Delta = delta
+	return a
+}
+
+// NewDelta returns a delta update configuration using the given patch filename template
+func NewDelta(template string) *Delta {
+	return &Delta{Template: StringPtr(template)}
+}
+
+// GetTemplate returns the patch filename template
+func (d *Delta) GetTemplate() string {
+	if d == nil {
+		return ""
+	}
+	return StringValue(d.Template)
+}
+
+// GetFormat returns the patch format, defaulting to zstd
+func (d *Delta) GetFormat() Format {
+	if d == nil || d.Format == nil {
+		return Zstd
+	}
+	return *d.Format
+}
+
+// WithFormat sets the patch format
+func (d *Delta) WithFormat(format Format) *Delta {
+	d.Format = &format
+	return d
+}
+
+// DefaultExtraFileDest is the default installation directory of extra files,
+// relative to the binary installation directory
+const DefaultExtraFileDest = "."
+
+// WithExtraFile adds an additional file installed into dest with the binary
+func (a *Asset) WithExtraFile(template, dest string) *Asset {
+	a.ExtraFiles = append(a.ExtraFiles, ExtraFileElement{Template: StringPtr(template), Dest: StringPtrOrNil(dest)})
+	return a
+}
+
+// GetTemplate returns the filename template of the extra file
+func (e *ExtraFileElement) GetTemplate() string {
+	if e == nil {
+		return ""
+	}
+	return StringValue(e.Template)
+}
+
+// GetDest returns the installation directory of the extra file relative to the
+// binary installation directory, defaulting to DefaultExtraFileDest
+func (e *ExtraFileElement) GetDest() string {
+	if e == nil || StringValue(e.Dest) == "" {
+		return DefaultExtraFileDest
+	}
+	return *e.Dest
+}
+
+// GetName returns the binary name
+func (b *BinaryElement) GetName() string {
+	if b == nil {
+		return ""
+	}
+	return StringValue(b.Name)
+}
+
+// GetPath returns the binary path within the asset
+func (b *BinaryElement) GetPath() string {
+	if b == nil {
+		return ""
+	}
+	return StringValue(b.Path)
+}
+
+// NewRule returns a rule matching the given OS and architecture.
+// An empty value matches any OS or architecture.
+func NewRule(goos, goarch string) *RuleElement {
+	return &RuleElement{When: &When{OS: StringPtrOrNil(goos), Arch: StringPtrOrNil(goarch)}}
+}
+
+// GetTemplate returns the template override
+func (r *RuleElement) GetTemplate() string {
+	if r == nil {
+		return ""
+	}
+	return StringValue(r.Template)
+}
+
+// GetOS returns the OS override
+func (r *RuleElement) GetOS() string {
+	if r == nil {
+		return ""
+	}
+	return StringValue(r.OS)
+}
+
+// GetArch returns the architecture override
+func (r *RuleElement) GetArch() string {
+	if r == nil {
+		return ""
+	}
+	return StringValue(r.Arch)
+}
+
+// GetChecksumTemplate returns the checksum file template override
+func (r *RuleElement) GetChecksumTemplate() string {
+	if r == nil {
+		return ""
+	}
+	return StringValue(r.ChecksumTemplate)
+}
+
+// GetWhen returns the rule condition or nil
+func (r *RuleElement) GetWhen() *When {
+	if r == nil {
+		return nil
+	}
+	return r.When
+}
+
+// WithOSVersion restricts the rule to OS versions matching pattern
+func (r *RuleElement) WithOSVersion(pattern string) *RuleElement {
+	if r.When == nil {
+		r.When = &When{}
+	}
+	r.When.OSVersion = StringPtrOrNil(pattern)
+	return r
+}
+
+// WithTemplate sets the template override
+func (r *RuleElement) WithTemplate(template string) *RuleElement {
+	r.Template = StringPtrOrNil(template)
+	return r
+}
+
+// WithOS sets the OS override
+func (r *RuleElement) WithOS(os string) *RuleElement {
+	r.OS = StringPtrOrNil(os)
+	return r
+}
+
+// WithArch sets the architecture override
+func (r *RuleElement) WithArch(arch string) *RuleElement {
+	r.Arch = StringPtrOrNil(arch)
+	return r
+}
+
+// WithExt sets the extension override
+func (r *RuleElement) WithExt(ext string) *RuleElement {
+	r.EXT = StringPtr(ext)
+	return r
+}
+
+// WithFallbackTemplates appends filename templates tried when the rule's asset is missing
+func (r *RuleElement) WithFallbackTemplates(templates ...string) *RuleElement {
+	r.FallbackTemplates = append(r.FallbackTemplates, templates...)
+	return r
+}
+
+// WithChecksumTemplate sets the checksum file template override
+func (r *RuleElement) WithChecksumTemplate(template string) *RuleElement {
+	r.ChecksumTemplate = StringPtrOrNil(template)
+	return r
+}
+
+// WithBinary appends a binary override with the given name and path
+func (r *RuleElement) WithBinary(name, path string) *RuleElement {
+	r.Binaries = append(r.Binaries, BinaryElement{Name: StringPtr(name), Path: StringPtr(path)})
+	return r
+}
+
+// GetOS returns the OS condition
+func (w *When) GetOS() string {
+	if w == nil {
+		return ""
+	}
+	return StringValue(w.OS)
+}
+
+// GetArch returns the architecture condition
+func (w *When) GetArch() string {
+	if w == nil {
+		return ""
+	}
+	return StringValue(w.Arch)
+}
+
+// GetOSVersion returns the OS version pattern condition
+func (w *When) GetOSVersion() string {
+	if w == nil {
+		return ""
+	}
+	return StringValue(w.OSVersion)
+}
+
+// IsWeak reports whether the algorithm is no longer collision resistant (md5, sha1)
+func (a Algorithm) IsWeak() bool {
+	return a == Md5 || a == Sha1
+}
+
+// NewChecksums returns a checksum configuration with the given checksum filename template
+func NewChecksums(template string) *Checksums {
+	return &Checksums{Template: StringPtrOrNil(template)}
+}
+
+// GetAlgorithm returns the hash algorithm, defaulting to sha256
+func (c *Checksums) GetAlgorithm() Algorithm {
+	if c == nil || c.Algorithm == nil || *c.Algorithm == "" {
+		return Sha256
+	}
+	return *c.Algorithm
+}
+
+// GetTemplate returns the checksum filename template
+func (c *Checksums) GetTemplate() string {
+	if c == nil {
+		return ""
+	}
+	return StringValue(c.Template)
+}
+
+// GetRequired reports whether assets must be verified before they are installed
+func (c *Checksums) GetRequired() bool {
+	if c == nil || c.Required == nil {
+		return false
+	}
+	return *c.Required
+}
+
+// GetEmbeddedChecksum returns the embedded hash for the given version and filename
+func (c *Checksums) GetEmbeddedChecksum(version, filename string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	for _, ec := range c.EmbeddedChecksums[version] {
+		if StringValue(ec.Filename) == filename {
+			return StringValue(ec.Hash), true
+		}
+	}
+	return "", false
+}
+
+// GetCosign returns the cosign signature verification configuration or nil
+func (c *Checksums) GetCosign() *Cosign {
+	if c == nil {
+		return nil
+	}
+	return c.Cosign
+}
+
+// WithAlgorithm sets the hash algorithm
+func (c *Checksums) WithAlgorithm(algo Algorithm) *Checksums {
+	c.Algorithm = &algo
+	return c
+}
+
+// WithRequired sets whether assets must be verified before they are installed
+func (c *Checksums) WithRequired(required bool) *Checksums {
+	c.Required = &required
+	return c
+}
+
+// WithEmbeddedChecksum adds an embedded checksum for the given version
+func (c *Checksums) WithEmbeddedChecksum(version, filename, hash string) *Checksums {
+	if c.EmbeddedChecksums == nil {
+		c.EmbeddedChecksums = make(map[string][]EmbeddedChecksum)
+	}
+	c.EmbeddedChecksums[version] = append(c.EmbeddedChecksums[version], EmbeddedChecksum{
+		Filename: StringPtr(filename),
+		Hash:     StringPtr(hash),
+	})
+	return c
+}
+
+// WithCosign sets the cosign signature verification configuration
+func (c *Checksums) WithCosign(cosign *Cosign) *Checksums {
+	c.Cosign = cosign
+	return c
+}
+
+// NewCosign returns a cosign configuration requiring the given certificate identity and OIDC issuer
+func NewCosign(identityRegexp, oidcIssuer string) *Cosign {
+	return &Cosign{
+		CertificateIdentityRegexp: StringPtrOrNil(identityRegexp),
+		CertificateOidcIssuer:     StringPtrOrNil(oidcIssuer),
+	}
+}
+
+// GetCertificateIdentityRegexp returns the regular expression the certificate identity must match
+func (c *Cosign) GetCertificateIdentityRegexp() string {
+	if c == nil {
+		return ""
+	}
+	return StringValue(c.CertificateIdentityRegexp)
+}
+
+// GetCertificateOIDCIssuer returns the OIDC issuer the certificate must be issued for
+func (c *Cosign) GetCertificateOIDCIssuer() string {
+	if c == nil {
+		return ""
+	}
+	return StringValue(c.CertificateOidcIssuer)
+}
+
+// WithIdentity adds a trusted identity for versions in [validFrom, validUntil);
+// empty bounds are open
+func (c *Cosign) WithIdentity(identityRegexp, oidcIssuer, validFrom, validUntil string) *Cosign {
+	c.Identities = append(c.Identities, IdentityElement{
+		CertificateIdentityRegexp: StringPtrOrNil(identityRegexp),
+		CertificateOidcIssuer:     StringPtrOrNil(oidcIssuer),
+		ValidFrom:                 StringPtrOrNil(validFrom),
+		ValidUntil:                StringPtrOrNil(validUntil),
+	})
+	return c
+}
+
+// GetRekorURL returns the Rekor transparency log URL, or empty for the public instance
+func (c *Cosign) GetRekorURL() string {
+	if c == nil {
+		return ""
+	}
+	return StringValue(c.RekorURL)
+}
+
+// GetSignature returns the checksum file signature configuration or nil
+func (c *Checksums) GetSignature() *Signature {
+	if c == nil {
+		return nil
+	}
+	return c.Signature
+}
+
+// WithSignature sets the checksum file signature configuration
+func (c *Checksums) WithSignature(signature *Signature) *Checksums {
+	c.Signature = signature
+	return c
+}
+
+// NewSignature returns a signature configuration trusting the given public key
+func NewSignature(format SignatureFormat, key string) *Signature {
+	return &Signature{Format: &format, Key: StringPtrOrNil(key)}
+}
+
+// GetFormat returns the signature format, defaulting to gpg
+func (s *Signature) GetFormat() SignatureFormat {
+	if s == nil || s.Format == nil {
+		return Gpg
+	}
+	return *s.Format
+}
+
+// GetTemplate returns the signature file name template, defaulting to the
+// checksum file name with the usual extension of the format
+func (s *Signature) GetTemplate() string {
+	if s != nil && s.Template != nil {
+		return *s.Template
+	}
+	if s.GetFormat() == Minisign {
+		return "${CHECKSUM_FILENAME}.minisig"
+	}
+	return "${CHECKSUM_FILENAME}.sig"
+}
+
+// GetKey returns the inline public key
+func (s *Signature) GetKey() string {
+	if s == nil {
+		return ""
+	}
+	return StringValue(s.Key)
+}
+
+// GetKeyring returns the URL of the public key
+func (s *Signature) GetKeyring() string {
+	if s == nil {
+		return ""
+	}
+	return StringValue(s.Keyring)
+}
+
+// GetDoubleFetch returns the double-fetch configuration or nil
+func (c *Checksums) GetDoubleFetch() *DoubleFetch {
+	if c == nil {
+		return nil
+	}
+	return c.DoubleFetch
+}
+
+// WithDoubleFetch enables double-fetch comparison of unverified assets, fetching
+// the second copy from the mirror URL template when it is not empty
+func (c *Checksums) WithDoubleFetch(mirror string) *Checksums {
+	enabled := true
+	c.DoubleFetch = &DoubleFetch{Enabled: &enabled, Mirror: StringPtrOrNil(mirror)}
+	return c
+}
+
+// GetEnabled reports whether unverified assets are downloaded twice and compared
+func (d *DoubleFetch) GetEnabled() bool {
+	return d != nil && d.Enabled != nil && *d.Enabled
+}
+
+// GetMirror returns the URL template of the second download, or empty for the release
+func (d *DoubleFetch) GetMirror() string {
+	if d == nil {
+		return ""
+	}
+	return StringValue(d.Mirror)
+}
+
+// GetStripComponents returns the number of leading path components to strip
+func (u *Unpack) GetStripComponents() int64 {
+	if u == nil || u.StripComponents == nil {
+		return 0
+	}
+	return *u.StripComponents
+}
+
+// NewUnpack returns an archive extraction configuration stripping n components
+func NewUnpack(stripComponents int64) *Unpack {
+	return &Unpack{StripComponents: &stripComponents}
+}
+
+// GetInclude returns the glob patterns of the archive paths to extract
+func (u *Unpack) GetInclude() []string {
+	if u == nil {
+		return nil
+	}
+	return u.Include
+}
+
+// GetExclude returns the glob patterns of the archive paths to skip
+func (u *Unpack) GetExclude() []string {
+	if u == nil {
+		return nil
+	}
+	return u.Exclude
+}
+
+// HasFilters reports whether include or exclude patterns are set
+func (u *Unpack) HasFilters() bool {
+	return len(u.GetInclude()) > 0 || len(u.GetExclude()) > 0
+}
+
+// WithInclude appends glob patterns of the archive paths to extract
+func (u *Unpack) WithInclude(patterns ...string) *Unpack {
+	u.Include = append(u.Include, patterns...)
+	return u
+}
+
+// WithExclude appends glob patterns of the archive paths to skip
+func (u *Unpack) WithExclude(patterns ...string) *Unpack {
+	u.Exclude = append(u.Exclude, patterns...)
+	return u
+}
+
+// DefaultJSONPath is the default JSONPath for the http-json version source
+const DefaultJSONPath = "$.version"
+
+// NewVersion returns a version resolution configuration using the given source
+func NewVersion(source Source) *Version {
+	return &Version{Source: &source}
+}
+
+// GetSource returns the version source, defaulting to github-releases
+func (v *Version) GetSource() Source {
+	if v == nil || v.Source == nil {
+		return GithubReleases
+	}
+	return *v.Source
+}
+
+// GetURL returns the JSON endpoint URL of the http-json source
+func (v *Version) GetURL() string {
+	if v == nil {
+		return ""
+	}
+	return StringValue(v.URL)
+}
+
+// GetJSONPath returns the JSONPath of the http-json source, defaulting to DefaultJSONPath
+func (v *Version) GetJSONPath() string {
+	if v == nil || StringValue(v.JSONPath) == "" {
+		return DefaultJSONPath
+	}
+	return *v.JSONPath
+}
+
+// GetTagFallback reports whether the latest tag is resolved from the tags API
+// when the repository has no latest release, defaulting to true
+func (v *Version) GetTagFallback() bool {
+	if v == nil || v.TagFallback == nil {
+		return true
+	}
+	return *v.TagFallback
+}
+
+// GetTagPattern returns the regular expression tags resolved from the tags API must match
+func (v *Version) GetTagPattern() string {
+	if v == nil {
+		return ""
+	}
+	return StringValue(v.TagPattern)
+}
+
+// WithTagFallback sets whether the latest tag is resolved from the tags API
+// when the repository has no latest release
+func (v *Version) WithTagFallback(fallback bool) *Version {
+	v.TagFallback = &fallback
+	return v
+}
+
+// WithTagPattern sets the regular expression tags must match
+func (v *Version) WithTagPattern(pattern string) *Version {
+	v.TagPattern = StringPtrOrNil(pattern)
+	return v
+}
+
+// WithURL sets the JSON endpoint URL
+func (v *Version) WithURL(url string) *Version {
+	v.URL = StringPtrOrNil(url)
+	return v
+}
+
+// WithJSONPath sets the JSONPath to the version
+func (v *Version) WithJSONPath(path string) *Version {
+	v.JSONPath = StringPtrOrNil(path)
+	return v
+}
+
+// GetNotify returns the install notification configuration or nil
+func (s *InstallSpec) GetNotify() *Notify {
+	if s == nil {
+		return nil
+	}
+	return s.Notify
+}
+
+// GetWebhookURL returns the webhook URL template, or empty when notifications are off
+func (n *Notify) GetWebhookURL() string {
+	if n == nil {
+		return ""
+	}
+	return StringValue(n.WebhookURL)
+}
+
+// GetUsagePing returns the usage ping configuration or nil
+func (s *InstallSpec) GetUsagePing() *UsagePing {
+	if s == nil {
+		return nil
+	}
+	return s.UsagePing
+}
+
+// WithUsagePing sets the usage ping configuration
+func (s *InstallSpec) WithUsagePing(ping *UsagePing) *InstallSpec {
+	s.UsagePing = ping
+	return s
+}
+
+// NewUsagePing returns a usage ping configuration opted in to pinging url
+func NewUsagePing(url string) *UsagePing {
+	enabled := true
+	return &UsagePing{Enabled: &enabled, URL: StringPtrOrNil(url)}
+}
+
+// GetEnabled reports whether the usage ping is explicitly enabled
+func (u *UsagePing) GetEnabled() bool {
+	return u != nil && u.Enabled != nil && *u.Enabled
+}
+
+// GetURL returns the counting endpoint of the usage ping
+func (u *UsagePing) GetURL() string {
+	if u == nil {
+		return ""
+	}
+	return StringValue(u.URL)
+}
+
+// WithEnabled sets whether the usage ping is sent
+func (u *UsagePing) WithEnabled(enabled bool) *UsagePing {
+	u.Enabled = &enabled
+	return u
+}
+
+// GetDownload returns the download retry and timeout settings or nil
+func (s *InstallSpec) GetDownload() *Download {
+	if s == nil {
+		return nil
+	}
+	return s.Download
+}
+
+// WithDownload sets the download retry and timeout settings
+func (s *InstallSpec) WithDownload(download *Download) *InstallSpec {
+	s.Download = download
+	return s
+}
+
+// GetRetries returns the number of retries of a failed download, 0 when unset
+func (d *Download) GetRetries() int64 {
+	if d == nil || d.Retries == nil {
+		return 0
+	}
+	return *d.Retries
+}
+
+// GetRetryDelay returns the seconds to wait before a retry, 0 when unset
+func (d *Download) GetRetryDelay() int64 {
+	if d == nil || d.RetryDelay == nil {
+		return 0
+	}
+	return *d.RetryDelay
+}
+
+// GetTimeout returns the seconds a download attempt may take, 0 for no limit
+func (d *Download) GetTimeout() int64 {
+	if d == nil || d.Timeout == nil {
+		return 0
+	}
+	return *d.Timeout
+}
+
+// WithRetries sets the number of retries of a failed download
+func (d *Download) WithRetries(retries int64) *Download {
+	d.Retries = &retries
+	return d
+}
+
+// WithRetryDelay sets the seconds to wait before a retry
+func (d *Download) WithRetryDelay(seconds int64) *Download {
+	d.RetryDelay = &seconds
+	return d
+}
+
+// WithTimeout sets the seconds a download attempt may take
+func (d *Download) WithTimeout(seconds int64) *Download {
+	d.Timeout = &seconds
+	return d
+}
+
+// GetBreakingChangesURL returns the URL of the maintainer-provided list of breaking changes
+func (s *InstallSpec) GetBreakingChangesURL() string {
+	if s == nil {
+		return ""
+	}
+	return StringValue(s.BreakingChangesURL)
+}
+
+// WithBreakingChange adds a release that breaks compatibility, with migration notes
+func (s *InstallSpec) WithBreakingChange(version, notes string) *InstallSpec {
+	s.BreakingChanges = append(s.BreakingChanges, BreakingChangeElement{Version: &version, Notes: StringPtrOrNil(notes)})
+	return s
+}
+
+// GetVersion returns the first version with the breaking change
+func (b BreakingChangeElement) GetVersion() string {
+	return StringValue(b.Version)
+}
+
+// GetNotes returns the migration notes of the breaking change
+func (b BreakingChangeElement) GetNotes() string {
+	return StringValue(b.Notes)
+}
+
+// DefaultPredicateType is the predicate type of GitHub build provenance attestations
+const DefaultPredicateType = "https://slsa.dev/provenance/v1"
+
+// GetAttestation returns the artifact attestation configuration or nil
+func (s *InstallSpec) GetAttestation() *Attestation {
+	if s == nil {
+		return nil
+	}
+	return s.Attestation
+}
+
+// NewAttestation returns an attestation configuration requiring attestations signed
+// by signerWorkflow, or by any workflow when it is empty
+func NewAttestation(signerWorkflow string) *Attestation {
+	return &Attestation{SignerWorkflow: StringPtrOrNil(signerWorkflow)}
+}
+
+// GetRequired reports whether an asset without attestations is refused, defaulting to true
+func (a *Attestation) GetRequired() bool {
+	if a == nil || a.Required == nil {
+		return true
+	}
+	return *a.Required
+}
+
+// GetSignerWorkflow returns the workflow that must have signed the attestation
+func (a *Attestation) GetSignerWorkflow() string {
+	if a == nil {
+		return ""
+	}
+	return StringValue(a.SignerWorkflow)
+}
+
+// GetPredicateType returns the predicate type the attestation must have
+func (a *Attestation) GetPredicateType() string {
+	if a == nil || StringValue(a.PredicateType) == "" {
+		return DefaultPredicateType
+	}
+	return *a.PredicateType
+}
+
+// WithRequired sets whether an asset without attestations is refused
+func (a *Attestation) WithRequired(required bool) *Attestation {
+	a.Required = &required
+	return a
+}
@@ -0,0 +1,106 @@
+package spec
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGettersOnNil(t *testing.T) {
+	var s *InstallSpec
+	if got := s.GetSchema(); got != DefaultSchema {
+		t.Errorf("GetSchema() = %q, want %q", got, DefaultSchema)
+	}
+	if got := s.GetDefaultVersion(); got != "latest" {
+		t.Errorf("GetDefaultVersion() = %q, want %q", got, "latest")
+	}
+	if got := s.GetDefaultBinDir(); got != DefaultBinDirValue {
+		t.Errorf("GetDefaultBinDir() = %q, want %q", got, DefaultBinDirValue)
+	}
+	if got := s.GetName(); got != "" {
+		t.Errorf("GetName() = %q, want empty", got)
+	}
+	if got := s.GetAsset().GetTemplate(); got != "" {
+		t.Errorf("GetAsset().GetTemplate() = %q, want empty", got)
+	}
+	if got := s.GetChecksums().GetAlgorithm(); got != Sha256 {
+		t.Errorf("GetChecksums().GetAlgorithm() = %q, want %q", got, Sha256)
+	}
+	if got := s.GetUnpack().GetStripComponents(); got != 0 {
+		t.Errorf("GetUnpack().GetStripComponents() = %d, want 0", got)
+	}
+	if got := s.GetScriptHeader().Lines(); got != nil {
+		t.Errorf("GetScriptHeader().Lines() = %q, want nil", got)
+	}
+}
+
+func TestGetNameFallsBackToRepo(t *testing.T) {
+	tests := []struct {
+		name string
+		spec *InstallSpec
+		want string
+	}{
+		{"explicit name", NewInstallSpec("owner/repo").WithName("tool"), "tool"},
+		{"repo name", NewInstallSpec("owner/repo"), "repo"},
+		{"invalid repo", NewInstallSpec("repo"), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.spec.GetName(); got != tt.want {
+				t.Errorf("GetName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildersMatchLiteral(t *testing.T) {
+	built := NewInstallSpec("owner/tool").
+		WithName("tool").
+		WithAsset(NewAsset("${NAME}_${VERSION}_${OS}_${ARCH}${EXT}").
+			WithDefaultExtension(".tar.gz").
+			WithBinary("tool", "bin/tool").
+			WithRules(NewRule("windows", "").WithExt(".zip"))).
+		WithChecksums(NewChecksums("checksums.txt").
+			WithAlgorithm(Sha512).
+			WithEmbeddedChecksum("v1.0.0", "tool_1.0.0_linux_amd64.tar.gz", "abc")).
+		WithUnpack(NewUnpack(1)).
+		WithSupportedPlatforms("linux/amd64")
+
+	algo := Sha512
+	strip := int64(1)
+	want := &InstallSpec{
+		Name: StringPtr("tool"),
+		Repo: StringPtr("owner/tool"),
+		Asset: &Asset{
+			Template:         StringPtr("${NAME}_${VERSION}_${OS}_${ARCH}${EXT}"),
+			DefaultExtension: StringPtr(".tar.gz"),
+			Binaries:         []BinaryElement{{Name: StringPtr("tool"), Path: StringPtr("bin/tool")}},
+			Rules: []RuleElement{{
+				When: &When{OS: StringPtr("windows")},
+				EXT:  StringPtr(".zip"),
+			}},
+		},
+		Checksums: &Checksums{
+			Algorithm: &algo,
+			Template:  StringPtr("checksums.txt"),
+			EmbeddedChecksums: map[string][]EmbeddedChecksum{
+				"v1.0.0": {{Filename: StringPtr("tool_1.0.0_linux_amd64.tar.gz"), Hash: StringPtr("abc")}},
+			},
+		},
+		Unpack: &Unpack{StripComponents: &strip},
+		SupportedPlatforms: []Platform{
+			{OS: SupportedPlatformOSPtr("linux"), Arch: SupportedPlatformArchPtr("amd64")},
+		},
+	}
+
+	if diff := cmp.Diff(want, built); diff != "" {
+		t.Errorf("builder result mismatch (-want +got):\n%s", diff)
+	}
+
+	if hash, ok := built.GetChecksums().GetEmbeddedChecksum("v1.0.0", "tool_1.0.0_linux_amd64.tar.gz"); !ok || hash != "abc" {
+		t.Errorf("GetEmbeddedChecksum() = %q, %v, want %q, true", hash, ok, "abc")
+	}
+	if _, ok := built.GetChecksums().GetEmbeddedChecksum("v2.0.0", "tool_1.0.0_linux_amd64.tar.gz"); ok {
+		t.Error("GetEmbeddedChecksum() found checksum for unknown version")
+	}
+}
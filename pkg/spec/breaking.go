@@ -0,0 +1,60 @@
+package spec
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/goccy/go-yaml"
+)
+
+// ParseBreakingChanges parses a JSON or YAML list of breaking changes, the
+// format of breaking_changes_url
+func ParseBreakingChanges(data []byte) ([]BreakingChangeElement, error) {
+	var changes []BreakingChangeElement
+	if err := yaml.Unmarshal(data, &changes); err != nil {
+		return nil, fmt.Errorf("failed to parse breaking changes: %w", err)
+	}
+	for i, change := range changes {
+		if err := validateBreakingChange(change, fmt.Sprintf("breaking_changes[%d]", i)); err != nil {
+			return nil, err
+		}
+	}
+	return changes, nil
+}
+
+// BreakingChangesBetween returns the breaking changes an upgrade from version
+// from to version to crosses, those with from < version <= to, ordered by
+// version (see CompareVersions). Downgrades and reinstalls cross none.
+func BreakingChangesBetween(changes []BreakingChangeElement, from, to string) ([]BreakingChangeElement, error) {
+	if err := CheckVersion(from); err != nil {
+		return nil, fmt.Errorf("cannot compare installed version with breaking changes: %w", err)
+	}
+	if err := CheckVersion(to); err != nil {
+		return nil, fmt.Errorf("cannot compare version with breaking changes: %w", err)
+	}
+	var crossed []BreakingChangeElement
+	for _, change := range changes {
+		v := change.GetVersion()
+		if err := CheckVersion(v); err != nil {
+			return nil, fmt.Errorf("invalid breaking change version: %w", err)
+		}
+		if CompareVersions(from, v) >= 0 || CompareVersions(to, v) < 0 {
+			continue
+		}
+		// The spec and breaking_changes_url may both list a version
+		duplicate := false
+		for _, c := range crossed {
+			if CompareVersions(c.GetVersion(), v) == 0 && c.GetNotes() == change.GetNotes() {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			crossed = append(crossed, change)
+		}
+	}
+	sort.SliceStable(crossed, func(i, j int) bool {
+		return CompareVersions(crossed[i].GetVersion(), crossed[j].GetVersion()) < 0
+	})
+	return crossed, nil
+}
@@ -0,0 +1,71 @@
+package spec
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBreakingChangesBetween(t *testing.T) {
+	changes := NewInstallSpec("owner/tool").
+		WithBreakingChange("v3.0.0", "v3 notes").
+		WithBreakingChange("v2.0.0", "v2 notes").
+		WithBreakingChange("2.0.0", "v2 notes").
+		BreakingChanges
+
+	tests := []struct {
+		from, to string
+		want     []string
+	}{
+		{"1.9.0", "v2.0.0", []string{"v2.0.0"}},
+		{"1.9", "v3.1.0", []string{"v2.0.0", "v3.0.0"}},
+		{"v2.0.0", "v2.5.0", nil},
+		{"3.1.0", "1.0.0", nil},
+		{"1.0.0", "v2.0.0-rc.1", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from+"-"+tt.to, func(t *testing.T) {
+			crossed, err := BreakingChangesBetween(changes, tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("BreakingChangesBetween() error = %v", err)
+			}
+			var got []string
+			for _, change := range crossed {
+				got = append(got, change.GetVersion())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BreakingChangesBetween(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+
+	dated := NewInstallSpec("owner/tool").WithBreakingChange("2024.06.01", "").BreakingChanges
+	if crossed, err := BreakingChangesBetween(dated, "2024.05.01.3", "2024.10.01"); err != nil || len(crossed) != 1 {
+		t.Errorf("BreakingChangesBetween(2024.05.01.3, 2024.10.01) = %v, %v, want the 2024.06.01 change", crossed, err)
+	}
+
+	if _, err := BreakingChangesBetween(changes, "nightly", "v2.0.0"); err == nil {
+		t.Error("BreakingChangesBetween(nightly) error = nil, want error")
+	}
+}
+
+func TestParseBreakingChanges(t *testing.T) {
+	changes, err := ParseBreakingChanges([]byte(`[{"version": "v2.0.0", "notes": "https://example.com/v2"}]`))
+	if err != nil {
+		t.Fatalf("ParseBreakingChanges(JSON) error = %v", err)
+	}
+	if len(changes) != 1 || changes[0].GetVersion() != "v2.0.0" || changes[0].GetNotes() != "https://example.com/v2" {
+		t.Errorf("ParseBreakingChanges(JSON) = %+v", changes)
+	}
+
+	changes, err = ParseBreakingChanges([]byte("- version: 3.0.0\n"))
+	if err != nil {
+		t.Fatalf("ParseBreakingChanges(YAML) error = %v", err)
+	}
+	if len(changes) != 1 || changes[0].GetVersion() != "3.0.0" {
+		t.Errorf("ParseBreakingChanges(YAML) = %+v", changes)
+	}
+
+	if _, err := ParseBreakingChanges([]byte("- notes: missing version\n")); err == nil {
+		t.Error("ParseBreakingChanges() error = nil, want error for an entry without version")
+	}
+}
@@ -0,0 +1,93 @@
+package spec
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// CompletionShells are the shells of the completions section, in install order
+var CompletionShells = []string{"bash", "zsh", "fish"}
+
+// CompletionFile is a shell completion script of the release archive
+type CompletionFile struct {
+	Shell string
+	// Path is the path of the script within the extracted archive
+	Path string
+}
+
+// Files returns the completion scripts that are set, in CompletionShells order
+func (c *Completions) Files() []CompletionFile {
+	if c == nil {
+		return nil
+	}
+	var files []CompletionFile
+	for _, shell := range CompletionShells {
+		if p := c.GetPath(shell); p != "" {
+			files = append(files, CompletionFile{Shell: shell, Path: p})
+		}
+	}
+	return files
+}
+
+// GetPath returns the path of the completion script of a shell, or empty
+func (c *Completions) GetPath(shell string) string {
+	if c == nil {
+		return ""
+	}
+	switch shell {
+	case "bash":
+		return StringValue(c.Bash)
+	case "zsh":
+		return StringValue(c.Zsh)
+	case "fish":
+		return StringValue(c.Fish)
+	}
+	return ""
+}
+
+// WithCompletion sets the path of the completion script of a shell
+func (s *InstallSpec) WithCompletion(shell, path string) *InstallSpec {
+	if s.Completions == nil {
+		s.Completions = &Completions{}
+	}
+	switch shell {
+	case "bash":
+		s.Completions.Bash = StringPtr(path)
+	case "zsh":
+		s.Completions.Zsh = StringPtr(path)
+	case "fish":
+		s.Completions.Fish = StringPtr(path)
+	}
+	return s
+}
+
+// CompletionDest returns where the completion script of a shell for the
+// command name is installed, relative to the parent of the installation
+// directory
+func CompletionDest(shell, name string) string {
+	switch shell {
+	case "bash":
+		return "share/bash-completion/completions/" + name
+	case "zsh":
+		return "share/zsh/site-functions/_" + name
+	case "fish":
+		return "share/fish/vendor_completions.d/" + name + ".fish"
+	}
+	return ""
+}
+
+// validateCompletions checks that completion paths are shell-safe and stay
+// within the extracted archive
+func validateCompletions(c *Completions) error {
+	for _, f := range c.Files() {
+		field := "completions." + f.Shell
+		if err := ValidateShellSafe(f.Path, field); err != nil {
+			return err
+		}
+		if path.IsAbs(f.Path) || path.Clean(f.Path) == ".." || strings.HasPrefix(path.Clean(f.Path), "../") {
+			return fmt.Errorf("%s must be a path within the archive: %s", field, f.Path)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package spec
+
+import (
+	"fmt"
+)
+
+// TrustedIdentities returns the identities trusted to sign the given version: the
+// top-level identity, if set, followed by the identities whose window contains version
+func (c *Cosign) TrustedIdentities(version string) ([]IdentityElement, error) {
+	if c == nil {
+		return nil, nil
+	}
+	var trusted []IdentityElement
+	if c.CertificateIdentityRegexp != nil || c.CertificateOidcIssuer != nil {
+		trusted = append(trusted, IdentityElement{
+			CertificateIdentityRegexp: c.CertificateIdentityRegexp,
+			CertificateOidcIssuer:     c.CertificateOidcIssuer,
+		})
+	}
+	for _, id := range c.Identities {
+		ok, err := id.Covers(version)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			trusted = append(trusted, id)
+		}
+	}
+	return trusted, nil
+}
+
+// Covers reports whether version lies within the identity's validity window
+func (i IdentityElement) Covers(version string) (bool, error) {
+	if i.ValidFrom == nil && i.ValidUntil == nil {
+		return true, nil
+	}
+	if err := CheckVersion(version); err != nil {
+		return false, fmt.Errorf("cannot match version against cosign identity validity windows: %w", err)
+	}
+	if i.ValidFrom != nil {
+		if err := CheckVersion(*i.ValidFrom); err != nil {
+			return false, fmt.Errorf("invalid valid_from: %w", err)
+		}
+		if CompareVersions(version, *i.ValidFrom) < 0 {
+			return false, nil
+		}
+	}
+	if i.ValidUntil != nil {
+		if err := CheckVersion(*i.ValidUntil); err != nil {
+			return false, fmt.Errorf("invalid valid_until: %w", err)
+		}
+		if CompareVersions(version, *i.ValidUntil) >= 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
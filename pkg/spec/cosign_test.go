@@ -0,0 +1,46 @@
+package spec
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTrustedIdentities(t *testing.T) {
+	const issuer = "https://token.actions.githubusercontent.com"
+	c := NewCosign("^always$", issuer).
+		WithIdentity("^old$", issuer, "", "v2.0.0").
+		WithIdentity("^new$", issuer, "v2.0.0", "")
+
+	tests := []struct {
+		version string
+		want    []string
+	}{
+		{"v1.9.3", []string{"^always$", "^old$"}},
+		{"v2.0.0", []string{"^always$", "^new$"}},
+		{"2.1.0", []string{"^always$", "^new$"}},
+		{"v2.0.0-rc.1", []string{"^always$", "^old$"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			trusted, err := c.TrustedIdentities(tt.version)
+			if err != nil {
+				t.Fatalf("TrustedIdentities() error = %v", err)
+			}
+			var got []string
+			for _, id := range trusted {
+				got = append(got, StringValue(id.CertificateIdentityRegexp))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TrustedIdentities(%q) = %v, want %v", tt.version, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := c.TrustedIdentities("nightly"); err == nil {
+		t.Error("TrustedIdentities(nightly) error = nil, want error for a version windows cannot be compared with")
+	}
+	unbounded := (&Cosign{}).WithIdentity("^any$", issuer, "", "")
+	if trusted, err := unbounded.TrustedIdentities("nightly"); err != nil || len(trusted) != 1 {
+		t.Errorf("TrustedIdentities(nightly) = %v, %v, want the unbounded identity", trusted, err)
+	}
+}
@@ -99,20 +99,144 @@ type InstallSpec struct {
 	Schema *string `json:"schema,omitempty"`
 	// Binary name (defaults to repository name if not specified)
 	Name *string `json:"name,omitempty"`
-	// GitHub repository in format 'owner/repo'
+	// Repository in format 'owner/repo' ('group/subgroup/project' on GitLab)
 	Repo *string `json:"repo,omitempty"`
+	// Service hosting the releases
+	Source *ReleaseSource `json:"source,omitempty"`
+	// Host of a GitHub Enterprise Server or self-managed GitLab instance,
+	// e.g. github.example.com or gitlab.example.com (default: github.com,
+	// or gitlab.com with source: gitlab).
+	Host *string `json:"host,omitempty"`
+	// Project metadata surfaced in generated scripts and 'binst list'
+	Metadata *Metadata `json:"metadata,omitempty"`
+	// Header comment block at the top of generated scripts
+	ScriptHeader *ScriptHeader `json:"script_header,omitempty"`
 	// Default version to install
 	DefaultVersion *string `json:"default_version,omitempty"`
+	// How the latest version is resolved
+	Version *Version `json:"version,omitempty"`
 	// Default binary installation directory
 	DefaultBinDir *string `json:"default_bin_dir,omitempty"`
 	// Asset download configuration
 	Asset *Asset `json:"asset,omitempty"`
 	// Checksum verification configuration
 	Checksums *Checksums `json:"checksums,omitempty"`
+	// GitHub artifact attestation verification
+	Attestation *Attestation `json:"attestation,omitempty"`
 	// Archive extraction configuration
 	Unpack *Unpack `json:"unpack,omitempty"`
 	// List of supported OS/architecture combinations
 	SupportedPlatforms []SupportedPlatformElement `json:"supported_platforms,omitempty"`
+	// Environment variables the installed tool needs at runtime.
+	//
+	// Values may reference ${INSTALL_DIR}, the parent of the directory the
+	// binaries are installed to, and ${BINDIR}, that directory itself.
+	// 'binst exec' sets them when running the tool, runner scripts export
+	// them before running it, and 'binst install' records them in the
+	// install receipt and prints them with --print-env.
+	//
+	// Example:
+	// ```yaml
+	// runtime_env:
+	// TOOL_HOME: ${INSTALL_DIR}/share/tool
+	// ```
+	RuntimeEnv map[string]string `json:"runtime_env,omitempty"`
+	// Wrapper scripts installed next to the binaries
+	Wrappers []WrapperElement `json:"wrappers,omitempty"`
+	// Shell completion scripts of the release archive
+	Completions *Completions `json:"completions,omitempty"`
+	// Notifications sent by 'binst install'
+	Notify *Notify `json:"notify,omitempty"`
+	// Opt-in install counting by generated installers
+	UsagePing *UsagePing `json:"usage_ping,omitempty"`
+	// Retries and timeouts of downloads
+	Download *Download `json:"download,omitempty"`
+	// Releases that break compatibility, with migration notes.
+	//
+	// 'binst install' and installer scripts warn when an upgrade of an
+	// installed binary crosses one of these versions.
+	BreakingChanges []BreakingChangeElement `json:"breaking_changes,omitempty"`
+	// URL of a maintainer-provided list of breaking changes, e.g.
+	// 'https://raw.githubusercontent.com/owner/repo/main/breaking-changes.yml'.
+	//
+	// A JSON or YAML list in the format of breaking_changes, typically kept in
+	// the repository so new entries apply to existing specs. 'binst install'
+	// fetches it and merges it with breaking_changes; generated scripts only
+	// embed breaking_changes.
+	BreakingChangesURL *string `json:"breaking_changes_url,omitempty"`
+	// Tools of a multi-tool spec, each an InstallSpec.
+	//
+	// The other top-level fields are shared defaults merged into every tool:
+	// mappings merge key by key, and scalars and lists set by a tool replace
+	// the shared ones. The top level may then omit repo. 'binst install --all'
+	// installs every tool, and other commands select one with FILE#TOOL or,
+	// for 'binst gen', --name TOOL.
+	//
+	// Example:
+	// ```yaml
+	// default_bin_dir: ./bin
+	// tools:
+	// - repo: cli/cli
+	// name: gh
+	// - repo: junegunn/fzf
+	// ```
+	Tools []InstallSpec `json:"tools,omitempty"`
+}
+
+// Project metadata surfaced in generated scripts and 'binst list'
+//
+// Project metadata.
+//
+// Informational fields about the upstream project. Generated installer and
+// runner scripts carry them in their header comments so compliance scans of
+// curl | sh installers can identify the license and whom to contact, and
+// 'binst list' displays them.
+//
+// Example:
+// ```yaml
+// metadata:
+// license: MIT
+// homepage: https://github.com/owner/mytool
+// security_contact: security@example.com
+// ```
+type Metadata struct {
+	// License of the project as an SPDX expression (e.g., 'MIT', 'Apache-2.0 OR MIT')
+	License *string `json:"license,omitempty"`
+	// Project homepage URL
+	Homepage *string `json:"homepage,omitempty"`
+	// Where to report security vulnerabilities (email address or URL)
+	SecurityContact *string `json:"security_contact,omitempty"`
+}
+
+// Header comment block at the top of generated scripts
+//
+// Header comment block of generated scripts.
+//
+// Emitted right after the shebang of every generated installer and runner
+// script, and at the top of the Chocolatey install script, for organizations
+// that require legal headers on the scripts they distribute. Unlike metadata,
+// which describes the upstream project, it describes the generated scripts.
+// 'binst gen --header-*' flags override the fields.
+//
+// Example:
+// ```yaml
+// script_header:
+// copyright: Copyright 2025 Example Corp.
+// license: Apache-2.0
+// support_url: https://example.com/support
+// text: |
+// Internal distribution only.
+// ```
+type ScriptHeader struct {
+	// Copyright line, e.g. 'Copyright 2025 Example Corp.'
+	Copyright *string `json:"copyright,omitempty"`
+	// SPDX license identifier of the generated scripts, emitted as an
+	// SPDX-License-Identifier line
+	License *string `json:"license,omitempty"`
+	// Where users of the scripts get support (URL or email address)
+	SupportURL *string `json:"support_url,omitempty"`
+	// Free-form text emitted after the other fields, one comment line per line
+	Text *string `json:"text,omitempty"`
 }
 
 // Asset download configuration
@@ -131,6 +255,13 @@ type Asset struct {
 	// - ${OS}: Operating system (e.g., 'linux', 'darwin', 'windows')
 	// - ${ARCH}: Architecture (e.g., 'amd64', 'arm64', '386')
 	// - ${EXT}: File extension (from 'default_extension' or rules)
+	// - ${RELEASE_NAME}: Title of the release (its tag when it has none)
+	// - ${PUBLISHED_AT}: Publication time of the release in UTC (e.g., '2024-05-01T12:00:00Z')
+	// - ${PUBLISHED_DATE}: Publication date of the release in UTC (e.g., '2024-05-01')
+	//
+	// The release placeholders are read from the releases API when installing,
+	// or when generating a script pinned with --target-version; unpinned
+	// scripts cannot use them.
 	//
 	// Examples:
 	// - "${NAME}_${VERSION}_${OS}_${ARCH}.tar.gz"
@@ -159,6 +290,13 @@ type Asset struct {
 	NamingConvention *NamingConvention `json:"naming_convention,omitempty"`
 	// Architecture emulation configuration
 	ArchEmulation *ArchEmulation `json:"arch_emulation,omitempty"`
+	// Delta update configuration
+	Delta *Delta `json:"delta,omitempty"`
+	// Additional files downloaded and installed with the binary.
+	//
+	// For tools that need a data archive next to the binary. Each file is
+	// verified against the checksums like the asset itself.
+	ExtraFiles []ExtraFileElement `json:"extra_files,omitempty"`
 }
 
 // Architecture emulation configuration
@@ -210,6 +348,97 @@ type BinaryElement struct {
 	Path *string `json:"path,omitempty"`
 }
 
+// Delta update configuration
+//
+// Delta update configuration.
+//
+// Large binaries with frequent releases can publish binary patches next to
+// the full assets. 'binst install' applies the patch to the asset of the
+// previously installed version kept in its cache, verifies the result against
+// the release checksum, and falls back to the full download on any failure.
+// Delta updates are only used when a checksum for the new asset is available.
+//
+// Example:
+// ```yaml
+// delta:
+// template: "${NAME}_${FROM_VERSION}_to_${VERSION}_${OS}_${ARCH}.patch.zst"
+// format: zstd
+// ```
+type Delta struct {
+	// Patch filename template.
+	//
+	// Supports all asset template placeholders plus:
+	// - ${FROM_VERSION}: Cached version the patch applies to (without 'v' prefix)
+	// - ${FROM_TAG}: Cached tag the patch applies to
+	// - ${ASSET_FILENAME}: Filename of the full asset for the new version
+	Template *string `json:"template,omitempty"`
+	// Patch format.
+	//
+	// - zstd (default): 'zstd --patch-from=OLD NEW'
+	// - bsdiff: BSDIFF40 patches produced by bsdiff
+	Format *Format `json:"format,omitempty"`
+}
+
+// Wrapper script installed next to the binaries that sets environment
+// variables and default arguments before running one of them.
+//
+// Values of env and args may reference ${INSTALL_DIR}, the parent of the
+// directory the binaries are installed to, and ${BINDIR}, that directory
+// itself. 'binst install' and installer scripts install wrappers as POSIX
+// shell scripts; they are skipped on Windows.
+//
+// Example:
+// ```yaml
+// wrappers:
+// - name: mytool-local
+// binary: mytool
+// env:
+// MYTOOL_HOME: ${INSTALL_DIR}/share/mytool
+// args:
+// - --config
+// - ${INSTALL_DIR}/etc/mytool.yml
+// ```
+type WrapperElement struct {
+	// Name of the wrapper script in the installation directory.
+	// It must differ from the names of the binaries.
+	Name *string `json:"name,omitempty"`
+	// Binary the wrapper runs (default: the first binary)
+	Binary *string `json:"binary,omitempty"`
+	// Environment variables set before running the binary
+	Env map[string]string `json:"env,omitempty"`
+	// Arguments passed to the binary before the arguments of the wrapper
+	Args []string `json:"args,omitempty"`
+}
+
+// Additional release file installed with the binary.
+//
+// Archives (.tar.gz, .tgz, .tar.xz, .tar.bz2, .tar.zst, .tar, .zip and .7z) are extracted into
+// dest; other files are copied into it as is. Runner scripts do not
+// download extra files.
+//
+// Example:
+// ```yaml
+// asset:
+// template: "${NAME}_${VERSION}_${OS}_${ARCH}.tar.gz"
+// extra_files:
+// - template: "${NAME}-data_${VERSION}.tar.gz"
+// dest: ../share/mytool
+// ```
+type ExtraFileElement struct {
+	// Filename template of the file.
+	//
+	// Supports the placeholders of the asset template, with the values of
+	// the platform after rules are applied.
+	Template *string `json:"template,omitempty"`
+	// Directory the file is installed into, relative to the binary
+	// installation directory.
+	//
+	// Examples:
+	// - "." (default): Next to the binary
+	// - "../share/mytool": ~/.local/share/mytool for ~/.local/bin
+	Dest *string `json:"dest,omitempty"`
+}
+
 // Controls the casing of placeholder values
 //
 // Controls the casing of template placeholders.
@@ -283,6 +512,15 @@ type RuleElement struct {
 	// Override template for matching platforms.
 	// This completely replaces the default template when the rule matches.
 	Template *string `json:"template,omitempty"`
+	// Fallback filename templates for matching platforms, tried in order
+	// when the asset from the template is not in the release.
+	// They use the same placeholders as the template and share its ${EXT}.
+	// The last matching rule with fallback_templates wins.
+	//
+	// Example: prefer a musl build and fall back to the gnu build:
+	// template: "${NAME}-${VERSION}-${ARCH}-unknown-linux-musl${EXT}"
+	// fallback_templates: ["${NAME}-${VERSION}-${ARCH}-unknown-linux-gnu${EXT}"]
+	FallbackTemplates []string `json:"fallback_templates,omitempty"`
 	// Override OS value for matching platforms.
 	// This changes the ${OS} placeholder value in the template.
 	// Useful when the release uses different OS naming (e.g., 'mac' instead of 'darwin').
@@ -299,6 +537,11 @@ type RuleElement struct {
 	// This replaces the default binary configuration when the rule matches.
 	// Useful when different platforms have different binary names or paths.
 	Binaries []BinaryElement `json:"binaries,omitempty"`
+	// Override checksum file template for matching platforms.
+	// This replaces 'checksums.template' when the rule matches.
+	// Useful when the release splits checksums per platform
+	// (e.g., 'checksums-linux.txt', 'checksums-darwin.txt').
+	ChecksumTemplate *string `json:"checksum_template,omitempty"`
 }
 
 // Condition for applying this rule.
@@ -335,6 +578,54 @@ type When struct {
 	// Can be any string value to support custom architecture identifiers.
 	// See Platform.arch for common values.
 	Arch *string `json:"arch,omitempty"`
+	// Match the operating system version or Linux distribution.
+	//
+	// A shell-style pattern (*, ?, [...]) matched against the detected OS version:
+	// "macos-MAJOR" on macOS (e.g. macos-15) and "ID-VERSION_ID" from
+	// /etc/os-release on Linux (e.g. ubuntu-24.04, alpine-3.20.3).
+	// The pattern also matches the name alone (macos, ubuntu, alpine).
+	// Set BINSTALLER_OS_VERSION to override detection.
+	// If the version cannot be detected, the rule does not match.
+	//
+	// Example: os_version: "alpine" or "macos-1[34]"
+	OSVersion *string `json:"os_version,omitempty"`
+}
+
+// GitHub artifact attestation verification
+//
+// GitHub artifact attestation verification.
+//
+// Releases built with actions/attest-build-provenance publish signed SLSA
+// provenance for their assets. When set, 'binst install' fetches the
+// attestations of the downloaded asset from the GitHub API and only installs
+// it after verifying that one was signed by a GitHub Actions workflow of the
+// repository through Sigstore and lists the asset's digest. Generated
+// installers run 'gh attestation verify' when the GitHub CLI is available.
+//
+// This complements checksums: a checksum file only proves the asset was not
+// changed after the release, provenance proves which workflow built it.
+//
+// Example:
+// ```yaml
+// attestation:
+// signer_workflow: owner/repo/.github/workflows/release.yml
+// ```
+type Attestation struct {
+	// Whether an asset without attestations is refused.
+	//
+	// Set to false to only warn when releases published before attestations
+	// were added are installed. An attestation that fails verification is
+	// always refused by 'binst install'; generated installers cannot tell the
+	// two apart and only warn when gh attestation verify fails.
+	Required *bool `json:"required,omitempty"`
+	// Workflow that must have signed the attestation, as
+	// 'owner/repo/.github/workflows/release.yml'.
+	//
+	// Defaults to any workflow. Set it to the reusable workflow when the
+	// attestation is made by one in another repository.
+	SignerWorkflow *string `json:"signer_workflow,omitempty"`
+	// Predicate type the attestation must have
+	PredicateType *string `json:"predicate_type,omitempty"`
 }
 
 // Checksum verification configuration
@@ -383,6 +674,173 @@ type Checksums struct {
 	// This allows offline installation and protects against
 	// compromised checksum files.
 	EmbeddedChecksums map[string][]EmbeddedChecksumElement `json:"embedded_checksums,omitempty"`
+	// Refuse to install an asset that could not be verified.
+	//
+	// By default, an asset without an embedded checksum or checksum file entry
+	// is installed with a warning. When true, 'binst install' and generated
+	// installers fail instead, so an asset is never extracted or installed
+	// before its hash has been verified.
+	Required *bool `json:"required,omitempty"`
+	// Cosign keyless signature verification for the checksum file.
+	//
+	// When set, the checksum file is only trusted after its cosign signature
+	// has been verified by 'binst embed-checksums' and 'binst install'.
+	Cosign *Cosign `json:"cosign,omitempty"`
+	// GPG or minisign signature verification for the checksum file.
+	//
+	// When set, the checksum file is only trusted after its detached signature
+	// has been verified against the configured public key, by 'binst embed-checksums',
+	// 'binst install' and generated installers.
+	Signature *Signature `json:"signature,omitempty"`
+	// Download the asset twice and compare the hashes when it cannot be verified.
+	//
+	// A last-resort tamper check for assets without checksums or signatures,
+	// applied by 'binst install'. Org defaults can enable it as policy.
+	DoubleFetch *DoubleFetch `json:"double_fetch,omitempty"`
+}
+
+// Cosign keyless signature verification for the checksum file.
+//
+// When set, the checksum file is only trusted after its cosign signature
+// has been verified by 'binst embed-checksums' and 'binst install'.
+//
+// Cosign keyless signature verification configuration.
+//
+// Verifies the '<checksum file>.sig' and '<checksum file>.pem' pair published
+// next to the checksum file, as produced by GoReleaser's signs pipe with
+// 'cosign sign-blob --output-certificate'. The checksum file is trusted only if:
+// - The signature matches the certificate's public key
+// - The certificate identity and OIDC issuer match a trusted identity for the version
+// - The signature is recorded in the Rekor transparency log, signed with the pinned Sigstore Rekor key
+// - The certificate chained to the pinned Sigstore Fulcio root when it was logged
+//
+// Example:
+// ```yaml
+// checksums:
+// template: checksums.txt
+// cosign:
+// certificate_identity_regexp: ^https://github\.com/owner/repo/\.github/workflows/release\.yml@refs/tags/
+// certificate_oidc_issuer: https://token.actions.githubusercontent.com
+// ```
+type Cosign struct {
+	// Regular expression that the certificate identity must match.
+	//
+	// For GitHub Actions this is the workflow URL, e.g.
+	// "^https://github\.com/owner/repo/\.github/workflows/release\.yml@refs/tags/"
+	CertificateIdentityRegexp *string `json:"certificate_identity_regexp,omitempty"`
+	// OIDC issuer that must be recorded in the certificate.
+	//
+	// For GitHub Actions this is "https://token.actions.githubusercontent.com".
+	CertificateOidcIssuer *string `json:"certificate_oidc_issuer,omitempty"`
+	// Additional trusted signing identities, each optionally limited to a range
+	// of release versions.
+	//
+	// Use this to rotate the signing workflow: older releases keep verifying
+	// against the identity that signed them and newer releases against the
+	// new one. The top-level identity, when set, is trusted for every version.
+	Identities []IdentityElement `json:"identities,omitempty"`
+	// Rekor transparency log URL. Its entries must be signed with the public Sigstore Rekor key
+	RekorURL *string `json:"rekor_url,omitempty"`
+}
+
+// Trusted cosign signing identity with an optional validity window.
+//
+// The identity is trusted for versions v with valid_from <= v < valid_until.
+// Omitted bounds are open.
+//
+// Example:
+// ```yaml
+// cosign:
+// identities:
+// - certificate_identity_regexp: ^https://github\.com/owner/repo/\.github/workflows/release\.yml@
+// certificate_oidc_issuer: https://token.actions.githubusercontent.com
+// valid_until: v2.0.0
+// - certificate_identity_regexp: ^https://github\.com/owner/repo/\.github/workflows/publish\.yml@
+// certificate_oidc_issuer: https://token.actions.githubusercontent.com
+// valid_from: v2.0.0
+// ```
+type IdentityElement struct {
+	// Regular expression that the certificate identity must match
+	CertificateIdentityRegexp *string `json:"certificate_identity_regexp,omitempty"`
+	// OIDC issuer that must be recorded in the certificate
+	CertificateOidcIssuer *string `json:"certificate_oidc_issuer,omitempty"`
+	// First version (inclusive) signed by this identity
+	ValidFrom *string `json:"valid_from,omitempty"`
+	// First version (exclusive) no longer signed by this identity
+	ValidUntil *string `json:"valid_until,omitempty"`
+}
+
+// GPG or minisign signature verification for the checksum file.
+//
+// When set, the checksum file is only trusted after its detached signature
+// has been verified against the configured public key, by 'binst embed-checksums',
+// 'binst install' and generated installers.
+//
+// Detached GPG or minisign signature of the checksum file.
+//
+// The signature file is downloaded from the release next to the checksum file
+// and verified with the public key before any checksum is trusted. Generated
+// installers need 'gpg' or 'minisign' on the PATH.
+//
+// Example:
+// ```yaml
+// checksums:
+// template: checksums.txt
+// signature:
+// template: ${CHECKSUM_FILENAME}.asc
+// keyring: https://example.com/release-signing-key.asc
+// ```
+type Signature struct {
+	// Signature format.
+	//
+	// - gpg (default): OpenPGP detached signature, armored or binary
+	// - minisign: minisign signature
+	Format *SignatureFormat `json:"format,omitempty"`
+	// Signature file name template.
+	//
+	// Supports the checksum template variables and ${CHECKSUM_FILENAME}, the name
+	// of the checksum file. Defaults to '${CHECKSUM_FILENAME}.sig' for gpg and
+	// '${CHECKSUM_FILENAME}.minisig' for minisign.
+	Template *string `json:"template,omitempty"`
+	// Trusted public key.
+	//
+	// An armored OpenPGP public key block, which may hold several keys, or a
+	// minisign public key (the base64 line of the .pub file).
+	// Exactly one of key and keyring must be set.
+	Key *string `json:"key,omitempty"`
+	// HTTPS URL of the trusted public key, in the same format as key.
+	//
+	// Exactly one of key and keyring must be set.
+	Keyring *string `json:"keyring,omitempty"`
+}
+
+// Download the asset twice and compare the hashes when it cannot be verified.
+//
+// A last-resort tamper check for assets without checksums or signatures,
+// applied by 'binst install'. Org defaults can enable it as policy.
+//
+// Double-fetch comparison of unverified assets.
+//
+// The asset is downloaded a second time, from the mirror when one is set
+// or from the release again otherwise, and is only installed when both
+// downloads have the same hash. This detects tampering by a party that
+// controls only one of the two paths; it is no substitute for checksums.
+//
+// Example:
+// ```yaml
+// checksums:
+// double_fetch:
+// enabled: true
+// mirror: https://mirror.example.com/${REPO}/releases/download/${TAG}/${ASSET_FILENAME}
+// ```
+type DoubleFetch struct {
+	// Compare two downloads of assets that have no checksum
+	Enabled *bool `json:"enabled,omitempty"`
+	// URL template of the second download.
+	//
+	// Supports ${REPO}, ${TAG} and ${ASSET_FILENAME}. When empty, the asset
+	// is downloaded from the release twice.
+	Mirror *string `json:"mirror,omitempty"`
 }
 
 // Pre-verified checksum for a specific asset.
@@ -405,6 +863,145 @@ type EmbeddedChecksumElement struct {
 	Hash *string `json:"hash,omitempty"`
 }
 
+// Shell completion scripts of the release archive
+//
+// Shell completion scripts shipped in the release archive.
+//
+// Each value is the path of the script within the extracted archive,
+// like the path of a binary. 'binst install --completions' and
+// installers run with -c install them into the share directory next to
+// the installation directory, e.g. ~/.local/share for ~/.local/bin:
+//
+// - bash: share/bash-completion/completions/NAME
+// - zsh: share/zsh/site-functions/_NAME
+// - fish: share/fish/vendor_completions.d/NAME.fish
+//
+// NAME is the first binary. Completions are not installed on Windows
+// or from raw binary assets.
+//
+// Example:
+// ```yaml
+// completions:
+// bash: completions/mytool.bash
+// zsh: completions/_mytool
+// fish: completions/mytool.fish
+// ```
+type Completions struct {
+	// Path of the bash completion script
+	Bash *string `json:"bash,omitempty"`
+	// Path of the zsh completion script, usually named _NAME
+	Zsh *string `json:"zsh,omitempty"`
+	// Path of the fish completion script
+	Fish *string `json:"fish,omitempty"`
+}
+
+// Notifications sent by 'binst install'
+//
+// Install notifications.
+//
+// Organizations tracking tool rollout can have 'binst install' post a JSON
+// event to a webhook after every install. Nothing is sent unless
+// webhook_url is set, typically in org defaults.
+//
+// Example:
+// ```yaml
+// notify:
+// webhook_url: ${BINSTALLER_WEBHOOK_URL}
+// ```
+type Notify struct {
+	// URL the install event is posted to, which must be https.
+	//
+	// ${BINSTALLER_WEBHOOK_URL} is the only environment variable expanded, so
+	// the URL and any token in it can be provided by the environment without
+	// exposing other variables. When it is empty or expands to an empty string,
+	// no event is sent.
+	WebhookURL *string `json:"webhook_url,omitempty"`
+}
+
+// Opt-in install counting by generated installers
+//
+// Opt-in install counting by generated installers.
+//
+// When enabled, installer scripts send one plain GET request to url after
+// a successful install so maintainers can estimate how often the installer
+// is used. The request carries no identifiers: no query parameters are added,
+// no GitHub token is sent and the User-Agent is a fixed 'binstaller'. The
+// script documents the ping in its header, and users can skip it with
+// BINSTALLER_NO_USAGE_PING=1 or DO_NOT_TRACK=1. Failures are ignored. Runner
+// scripts and 'binst install' never send it.
+//
+// Both enabled: true and url are required to send it; 'binst gen' refuses
+// enabled: true without a url, and a url without an explicit enabled.
+//
+// Example:
+// ```yaml
+// usage_ping:
+// enabled: true
+// url: https://counter.example.com/mytool
+// ```
+type UsagePing struct {
+	// Explicit opt-in; must be true for url to be used
+	Enabled *bool `json:"enabled,omitempty"`
+	// Counting endpoint, an https URL without credentials, fragment or
+	// variables.
+	URL *string `json:"url,omitempty"`
+}
+
+// Retries and timeouts of downloads
+//
+// Retries and timeouts of downloads.
+//
+// Users on flaky networks or behind slow proxies can get more patient
+// downloads without patching generated scripts. 'binst install' applies
+// these settings to its downloads, and generated scripts pass them to curl
+// (--retry, --retry-delay, --max-time) or wget (--tries, --waitretry,
+// --timeout). Without them, generated scripts attempt every download once
+// and 'binst install' retries as configured by BINSTALLER_HTTP_RETRIES,
+// which also overrides retries.
+//
+// Example:
+// ```yaml
+// download:
+// retries: 5
+// retry_delay: 3
+// timeout: 120
+// ```
+type Download struct {
+	// Number of times a failed download is retried.
+	//
+	// Network errors, timeouts, rate limits and server errors (HTTP 408, 429
+	// and 5xx) are retried; a missing asset is not.
+	Retries *int64 `json:"retries,omitempty"`
+	// Seconds to wait before retrying a failed download; requires retries.
+	//
+	// curl waits this long before every retry and wget up to this long.
+	// 'binst install' doubles the delay on each further retry.
+	RetryDelay *int64 `json:"retry_delay,omitempty"`
+	// Seconds a download attempt may take before it is aborted and retried.
+	//
+	// wget, which has no limit for a whole transfer, aborts an attempt when
+	// the connection is idle for this long instead.
+	Timeout *int64 `json:"timeout,omitempty"`
+}
+
+// Release that breaks compatibility with earlier versions.
+//
+// Upgrading from a version below version to version or later prints a
+// warning with the notes.
+//
+// Example:
+// ```yaml
+// breaking_changes:
+// - version: v2.0.0
+// notes: https://example.com/mytool/migrate-to-v2
+// ```
+type BreakingChangeElement struct {
+	// Migration notes printed with the warning, typically a URL
+	Notes *string `json:"notes,omitempty"`
+	// First version with the breaking change, e.g. 'v2.0.0' or '2024.05.01'
+	Version *string `json:"version,omitempty"`
+}
+
 // Supported OS and architecture combination.
 //
 // Defines a specific platform that the binary supports.
@@ -491,6 +1088,81 @@ type Unpack struct {
 	// - 1: Remove first directory level (e.g., "mytool-v1.0.0/bin/mytool" → "bin/mytool")
 	// - 2: Remove first two directory levels
 	StripComponents *int64 `json:"strip_components,omitempty"`
+	// Glob patterns of the archive paths to extract.
+	//
+	// Patterns match paths after strip_components is applied. '*' matches
+	// any characters including '/', '?' matches one character and [...]
+	// matches a character class. A pattern matching a directory selects
+	// everything below it. When set, only matching paths are extracted.
+	//
+	// Examples:
+	// - "bin/*": Only the bin directory
+	// - "mytool": Only the binary at the archive root
+	Include []string `json:"include,omitempty"`
+	// Glob patterns of the archive paths to skip.
+	//
+	// Uses the same syntax as include and takes precedence over it.
+	//
+	// Examples:
+	// - "share/doc": Skip the documentation
+	// - "*.pdb": Skip debug symbols
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// How the latest version is resolved
+//
+// Latest version resolution configuration.
+//
+// Controls how 'latest' is resolved to a concrete tag by 'binst install',
+// 'binst check', 'binst embed-checksums', and generated installer scripts.
+//
+// Sources:
+// - github-releases (default): The latest GitHub release, falling back to the
+// latest tag when the repository has no releases (see tag_fallback)
+// - github-tags: The highest version among the repository tags, for projects
+// that push tags without creating releases
+// - http-json: A value extracted with a JSONPath from a JSON document
+//
+// With source: gitlab, github-releases and github-tags resolve the latest
+// release and tag of the GitLab project instead.
+//
+// Generated scripts evaluate the JSONPath with jq when available. Without jq
+// they use the first string value of the last key in the path, so prefer paths
+// ending in a key name that is unique in the document.
+//
+// Example:
+// ```yaml
+// version:
+// source: http-json
+// url: "https://example.com/${NAME}/release.json"
+// json_path: "$.stable.version"
+// ```
+type Version struct {
+	// Where the latest version is resolved from
+	Source *Source `json:"source,omitempty"`
+	// JSON endpoint URL for the http-json source.
+	//
+	// Available placeholders:
+	// - ${NAME}: Binary name
+	// - ${REPO}: GitHub repository in format 'owner/repo'
+	URL *string `json:"url,omitempty"`
+	// JSONPath to the version in the http-json response.
+	//
+	// Supports dot notation, bracket notation, and array indexes,
+	// e.g. "$.version", "$.releases[0].tag", "$['latest-version']".
+	JSONPath *string `json:"json_path,omitempty"`
+	// Resolve the latest tag from the tags API when the repository has no latest
+	// release (github-releases source).
+	//
+	// Many projects push version tags without publishing releases. Set to false
+	// to fail instead of falling back to tags. Generated scripts do not fall back;
+	// use the github-tags source for such projects.
+	TagFallback *bool `json:"tag_fallback,omitempty"`
+	// Regular expression (RE2 syntax) tags must match to be resolved from the tags
+	// API, e.g. "^v[0-9]+\\.[0-9]+\\.[0-9]+$" to skip nightly or monorepo tags.
+	// Applies to the github-tags source and the tag fallback of binst; generated
+	// scripts ignore it.
+	TagPattern *string `json:"tag_pattern,omitempty"`
 }
 
 type NamingConventionArch string
@@ -510,6 +1182,28 @@ const (
 	Titlecase   NamingConventionOS = "titlecase"
 )
 
+// Patch format.
+//
+// - zstd (default): 'zstd --patch-from=OLD NEW'
+// - bsdiff: BSDIFF40 patches produced by bsdiff
+type Format string
+
+const (
+	Bsdiff Format = "bsdiff"
+	Zstd   Format = "zstd"
+)
+
+// Signature format.
+//
+// - gpg (default): OpenPGP detached signature, armored or binary
+// - minisign: minisign signature
+type SignatureFormat string
+
+const (
+	Gpg      SignatureFormat = "gpg"
+	Minisign SignatureFormat = "minisign"
+)
+
 // Hash algorithm used for checksums.
 // Must match the algorithm used by the project's checksum files.
 // Most projects use sha256.
@@ -601,3 +1295,35 @@ const (
 	Wasip1    SupportedPlatformOS = "wasip1"
 	Windows   SupportedPlatformOS = "windows"
 )
+
+// Where the latest version is resolved from
+type Source string
+
+const (
+	GithubReleases Source = "github-releases"
+	GithubTags     Source = "github-tags"
+	HTTPJSON       Source = "http-json"
+)
+
+// Service hosting the releases
+//
+// Release hosting service.
+//
+// - github (default): Releases of github.com or a GitHub Enterprise Server
+// (see host), downloaded from
+// https://{host}/{repo}/releases/download/{tag}/{asset}
+// - gitlab: Releases of gitlab.com or a self-managed GitLab (see host),
+// downloaded from https://{host}/{repo}/-/releases/{tag}/downloads/{asset}.
+// The release links need the direct asset path /{asset}, as GoReleaser
+// and glab create them.
+//
+// With gitlab, the github-releases and github-tags version sources resolve
+// the latest GitLab release and tag, and GITLAB_TOKEN (never GITHUB_TOKEN)
+// authenticates requests to the instance. Attestations and 'binst
+// embed-checksums --mode calculate' are GitHub only.
+type ReleaseSource string
+
+const (
+	Github ReleaseSource = "github"
+	Gitlab ReleaseSource = "gitlab"
+)
@@ -0,0 +1,64 @@
+package spec
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// ReleaseVariables are the asset template variables resolved from the metadata
+// of the release being installed
+var ReleaseVariables = []string{"RELEASE_NAME", "PUBLISHED_AT", "PUBLISHED_DATE"}
+
+// releaseVariableRef matches references to ReleaseVariables, e.g. ${PUBLISHED_DATE}
+var releaseVariableRef = regexp.MustCompile(`\$\{?(RELEASE_NAME|PUBLISHED_AT|PUBLISHED_DATE)\b`)
+
+// ReleaseVars returns the values of ReleaseVariables of a release named name
+// and published at publishedAt
+func ReleaseVars(name string, publishedAt time.Time) map[string]string {
+	return map[string]string{
+		"RELEASE_NAME":   name,
+		"PUBLISHED_AT":   publishedAt.UTC().Format(time.RFC3339),
+		"PUBLISHED_DATE": publishedAt.UTC().Format(time.DateOnly),
+	}
+}
+
+// UsesReleaseVariables reports whether any asset filename template references
+// ReleaseVariables, so the release metadata must be fetched to resolve it
+func (s *InstallSpec) UsesReleaseVariables() bool {
+	if s.Asset == nil {
+		return false
+	}
+	templates := []string{StringValue(s.Asset.Template)}
+	for _, rule := range s.Asset.Rules {
+		templates = append(templates, StringValue(rule.Template))
+		templates = append(templates, rule.FallbackTemplates...)
+	}
+	for _, extra := range s.Asset.ExtraFiles {
+		templates = append(templates, extra.GetTemplate())
+	}
+	for _, t := range templates {
+		if releaseVariableRef.MatchString(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateReleaseVariables checks that ReleaseVariables are only referenced
+// by asset filename templates. Checksum files are looked up without the
+// release metadata.
+func validateReleaseVariables(s *InstallSpec) error {
+	if releaseVariableRef.MatchString(s.GetChecksums().GetTemplate()) {
+		return fmt.Errorf("checksums.template cannot reference release metadata variables: %s", s.GetChecksums().GetTemplate())
+	}
+	if s.Asset == nil {
+		return nil
+	}
+	for i, rule := range s.Asset.Rules {
+		if releaseVariableRef.MatchString(rule.GetChecksumTemplate()) {
+			return fmt.Errorf("asset.rules[%d].checksum_template cannot reference release metadata variables: %s", i, rule.GetChecksumTemplate())
+		}
+	}
+	return nil
+}
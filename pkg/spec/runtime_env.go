@@ -0,0 +1,59 @@
+package spec
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// envNamePattern matches the names of environment variables runtime_env may set
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// RuntimeEnvRef matches the references runtime_env values may contain,
+// ${INSTALL_DIR} and ${BINDIR}, with the variable name as the first submatch
+var RuntimeEnvRef = regexp.MustCompile(`\$\{(INSTALL_DIR|BINDIR)\}`)
+
+// validateRuntimeEnv checks that an environment such as runtime_env sets valid
+// names to values that only reference ${INSTALL_DIR} and ${BINDIR}
+func validateRuntimeEnv(env map[string]string, field string) error {
+	for name, value := range env {
+		if !envNamePattern.MatchString(name) {
+			return fmt.Errorf("%s: invalid variable name %q", field, name)
+		}
+		if err := validateDirRefs(value, field+"."+name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateDirRefs checks that a value only references ${INSTALL_DIR} and
+// ${BINDIR} and stays on one line
+func validateDirRefs(value, field string) error {
+	if strings.Contains(RuntimeEnvRef.ReplaceAllString(value, ""), "$") {
+		return fmt.Errorf("%s may only reference ${INSTALL_DIR} and ${BINDIR}: %s", field, value)
+	}
+	if strings.ContainsFunc(value, unicode.IsControl) {
+		return fmt.Errorf("%s contains a control character", field)
+	}
+	return nil
+}
+
+// ExpandRuntimeEnv returns the runtime_env of the spec for binaries installed
+// into binDir, with ${BINDIR} replaced by binDir and ${INSTALL_DIR} by its
+// parent
+func (s *InstallSpec) ExpandRuntimeEnv(binDir string) map[string]string {
+	if s == nil || len(s.RuntimeEnv) == 0 {
+		return nil
+	}
+	dirs := map[string]string{"BINDIR": binDir, "INSTALL_DIR": filepath.Dir(binDir)}
+	env := make(map[string]string, len(s.RuntimeEnv))
+	for name, value := range s.RuntimeEnv {
+		env[name] = RuntimeEnvRef.ReplaceAllStringFunc(value, func(ref string) string {
+			return dirs[RuntimeEnvRef.FindStringSubmatch(ref)[1]]
+		})
+	}
+	return env
+}
@@ -5,15 +5,15 @@ import "strings"
 // SetDefaults sets default values for the InstallSpec
 func (s *InstallSpec) SetDefaults() {
 	if s.Schema == nil || *s.Schema == "" {
-		schema := "v1"
+		schema := DefaultSchema
 		s.Schema = &schema
 	}
 	if s.DefaultVersion == nil || *s.DefaultVersion == "" {
-		version := "latest"
+		version := DefaultVersionValue
 		s.DefaultVersion = &version
 	}
 	if s.DefaultBinDir == nil || *s.DefaultBinDir == "" {
-		binDir := "${BINSTALLER_BIN:-${HOME}/.local/bin}"
+		binDir := DefaultBinDirValue
 		s.DefaultBinDir = &binDir
 	}
 	if s.Asset != nil {
@@ -30,9 +30,10 @@ func (s *InstallSpec) SetDefaults() {
 		}
 	}
 	if s.Name == nil && s.Repo != nil && *s.Repo != "" {
-		sp := strings.SplitN(*s.Repo, "/", 2)
-		if len(sp) == 2 {
-			s.Name = &sp[1]
+		// The last path segment, which also names nested GitLab projects
+		if i := strings.LastIndex(*s.Repo, "/"); i >= 0 {
+			name := (*s.Repo)[i+1:]
+			s.Name = &name
 		}
 	}
 	if s.Asset != nil && len(s.Asset.Binaries) == 0 && s.Name != nil && *s.Name != "" {
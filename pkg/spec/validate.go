@@ -2,8 +2,15 @@ package spec
 
 import (
 	"fmt"
+	"math"
+	"net/url"
+	"path"
+	"regexp"
 	"strings"
 	"unicode"
+
+	"github.com/binary-install/binstaller/pkg/jsonpath"
+	"github.com/buildkite/interpolate"
 )
 
 // dangerousPatterns defines shell patterns that could lead to command injection
@@ -52,6 +59,40 @@ func ValidateShellSafe(value, fieldName string) error {
 	return nil
 }
 
+// osVersionPattern matches the characters allowed in when.os_version
+var osVersionPattern = regexp.MustCompile(`^[A-Za-z0-9._*?!\[\]-]+$`)
+
+// unpackPattern matches the characters allowed in unpack.include and
+// unpack.exclude, which are embedded unquoted in shell case patterns
+var unpackPattern = regexp.MustCompile(`^[A-Za-z0-9._+@,=*?!\[\]/-]+$`)
+
+// validateUnpackPattern checks that an unpack filter is a relative glob that can
+// be embedded in a shell case pattern
+func validateUnpackPattern(pattern, field string) error {
+	if !unpackPattern.MatchString(pattern) {
+		return fmt.Errorf("%s: invalid pattern %q: only letters, digits, '.', '_', '+', '@', ',', '=', '-', '/' and glob characters are allowed", field, pattern)
+	}
+	if strings.HasPrefix(pattern, "/") {
+		return fmt.Errorf("%s: invalid pattern %q: patterns are relative to the archive root", field, pattern)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("%s: invalid pattern %q: %w", field, pattern, err)
+	}
+	return nil
+}
+
+// validateOSVersionPattern checks that an os_version pattern is a valid glob
+// that can be embedded in a single-quoted shell string
+func validateOSVersionPattern(pattern string) error {
+	if !osVersionPattern.MatchString(pattern) {
+		return fmt.Errorf("invalid pattern %q: only letters, digits, '.', '_', '-' and glob characters are allowed", pattern)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	return nil
+}
+
 // Validate validates all fields in InstallSpec that will be embedded in shell scripts
 func Validate(s *InstallSpec) error {
 	if s == nil {
@@ -71,6 +112,30 @@ func Validate(s *InstallSpec) error {
 		}
 	}
 
+	if err := validateSource(s); err != nil {
+		return err
+	}
+
+	// Validate metadata; it is written into script comments, so it must stay on one line
+	for field, value := range map[string]string{
+		"metadata.license":          s.GetMetadata().GetLicense(),
+		"metadata.homepage":         s.GetMetadata().GetHomepage(),
+		"metadata.security_contact": s.GetMetadata().GetSecurityContact(),
+		"script_header.copyright":   s.GetScriptHeader().GetCopyright(),
+		"script_header.license":     s.GetScriptHeader().GetLicense(),
+		"script_header.support_url": s.GetScriptHeader().GetSupportURL(),
+	} {
+		if strings.ContainsFunc(value, unicode.IsControl) {
+			return fmt.Errorf("%s contains a control character", field)
+		}
+	}
+	// The text of the script header spans lines, each written as a comment
+	if strings.ContainsFunc(s.GetScriptHeader().GetText(), func(r rune) bool {
+		return unicode.IsControl(r) && r != '\n' && r != '\t'
+	}) {
+		return fmt.Errorf("script_header.text contains a control character")
+	}
+
 	// Validate default_bin_dir
 	if s.DefaultBinDir != nil {
 		if err := ValidateShellSafe(*s.DefaultBinDir, "default_bin_dir"); err != nil {
@@ -117,6 +182,11 @@ func Validate(s *InstallSpec) error {
 
 		// Validate rules
 		for i, rule := range s.Asset.Rules {
+			if pattern := rule.GetWhen().GetOSVersion(); pattern != "" {
+				if err := validateOSVersionPattern(pattern); err != nil {
+					return fmt.Errorf("asset.rules[%d].when.os_version: %w", i, err)
+				}
+			}
 			if rule.OS != nil {
 				if err := ValidateShellSafe(*rule.OS, fmt.Sprintf("asset.rules[%d].os", i)); err != nil {
 					return err
@@ -138,6 +208,40 @@ func Validate(s *InstallSpec) error {
 					return err
 				}
 			}
+			for j, template := range rule.FallbackTemplates {
+				field := fmt.Sprintf("asset.rules[%d].fallback_templates[%d]", i, j)
+				if err := ValidateShellSafe(template, field); err != nil {
+					return err
+				}
+				// The installer keeps the candidates in a space-separated list
+				if strings.ContainsAny(template, " \t") {
+					return fmt.Errorf("%s must not contain whitespace: %s", field, template)
+				}
+			}
+			if rule.ChecksumTemplate != nil {
+				if err := ValidateShellSafe(*rule.ChecksumTemplate, fmt.Sprintf("asset.rules[%d].checksum_template", i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if s.Asset != nil {
+		for i, extra := range s.Asset.ExtraFiles {
+			if err := validateExtraFile(extra, i); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, pattern := range s.Unpack.GetInclude() {
+		if err := validateUnpackPattern(pattern, fmt.Sprintf("unpack.include[%d]", i)); err != nil {
+			return err
+		}
+	}
+	for i, pattern := range s.Unpack.GetExclude() {
+		if err := validateUnpackPattern(pattern, fmt.Sprintf("unpack.exclude[%d]", i)); err != nil {
+			return err
 		}
 	}
 
@@ -148,5 +252,361 @@ func Validate(s *InstallSpec) error {
 		}
 	}
 
+	// Required checksums need somewhere to come from
+	if s.Checksums.GetRequired() && s.Checksums.GetTemplate() == "" && len(s.Checksums.EmbeddedChecksums) == 0 && !hasRuleChecksumTemplate(s) {
+		return fmt.Errorf("checksums.required is set but no checksums.template, rule checksum_template, or embedded_checksums is configured")
+	}
+
+	if cosign := s.Checksums.GetCosign(); cosign != nil {
+		if err := validateCosign(cosign); err != nil {
+			return err
+		}
+	}
+
+	if signature := s.Checksums.GetSignature(); signature != nil {
+		if err := validateSignature(signature); err != nil {
+			return err
+		}
+	}
+
+	if attestation := s.GetAttestation(); attestation != nil {
+		if s.GetSource() != Github {
+			return fmt.Errorf("attestation is only supported for GitHub releases, not source: %s", s.GetSource())
+		}
+		if err := ValidateAttestationHost(s); err != nil {
+			return err
+		}
+		if err := validateAttestation(attestation); err != nil {
+			return err
+		}
+	}
+
+	if ping := s.GetUsagePing(); ping != nil {
+		if err := validateUsagePing(ping); err != nil {
+			return err
+		}
+	}
+
+	if webhook := s.GetNotify().GetWebhookURL(); webhook != "" {
+		if err := ValidateWebhookURL(webhook); err != nil {
+			return err
+		}
+	}
+
+	if download := s.GetDownload(); download != nil {
+		if err := validateDownload(download); err != nil {
+			return err
+		}
+	}
+
+	if err := validateRuntimeEnv(s.RuntimeEnv, "runtime_env"); err != nil {
+		return err
+	}
+	if err := validateWrappers(s); err != nil {
+		return err
+	}
+	if err := validateCompletions(s.Completions); err != nil {
+		return err
+	}
+	if err := validateReleaseVariables(s); err != nil {
+		return err
+	}
+
+	for i, change := range s.BreakingChanges {
+		if err := validateBreakingChange(change, fmt.Sprintf("breaking_changes[%d]", i)); err != nil {
+			return err
+		}
+	}
+	if raw := s.GetBreakingChangesURL(); raw != "" {
+		if parsed, err := url.Parse(raw); err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+			return fmt.Errorf("breaking_changes_url must be an https URL: %s", raw)
+		}
+	}
+
+	// Validate version source
+	if s.Version != nil {
+		if err := validateVersion(s.Version); err != nil {
+			return err
+		}
+	}
+
+	// Tools are validated once the shared fields are merged into them
+	for i, tool := range s.Tools {
+		if len(tool.Tools) > 0 {
+			return fmt.Errorf("tools[%d] cannot declare tools of its own", i)
+		}
+	}
+
+	return nil
+}
+
+// validateExtraFile checks that an extra file has a shell-safe template and a
+// dest relative to the binary installation directory
+func validateExtraFile(extra ExtraFileElement, i int) error {
+	field := fmt.Sprintf("asset.extra_files[%d]", i)
+	if extra.GetTemplate() == "" {
+		return fmt.Errorf("%s.template is required", field)
+	}
+	if err := ValidateShellSafe(extra.GetTemplate(), field+".template"); err != nil {
+		return err
+	}
+	dest := extra.GetDest()
+	if err := ValidateShellSafe(dest, field+".dest"); err != nil {
+		return err
+	}
+	if path.IsAbs(dest) {
+		return fmt.Errorf("%s.dest must be relative to the binary installation directory: %s", field, dest)
+	}
+	return nil
+}
+
+// hasRuleChecksumTemplate reports whether any asset rule sets checksum_template
+func hasRuleChecksumTemplate(s *InstallSpec) bool {
+	if s.Asset == nil {
+		return false
+	}
+	for _, rule := range s.Asset.Rules {
+		if StringValue(rule.ChecksumTemplate) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// validateCosign checks that the cosign configuration trusts at least one complete
+// identity and that identity validity windows are versions
+func validateCosign(c *Cosign) error {
+	if c.CertificateIdentityRegexp == nil && c.CertificateOidcIssuer == nil && len(c.Identities) == 0 {
+		return fmt.Errorf("checksums.cosign requires certificate_identity_regexp and certificate_oidc_issuer, or identities")
+	}
+	if (c.CertificateIdentityRegexp == nil) != (c.CertificateOidcIssuer == nil) {
+		return fmt.Errorf("checksums.cosign: certificate_identity_regexp and certificate_oidc_issuer must be set together")
+	}
+	for i, id := range c.Identities {
+		if StringValue(id.CertificateIdentityRegexp) == "" || StringValue(id.CertificateOidcIssuer) == "" {
+			return fmt.Errorf("checksums.cosign.identities[%d]: certificate_identity_regexp and certificate_oidc_issuer are required", i)
+		}
+		for field, bound := range map[string]*string{"valid_from": id.ValidFrom, "valid_until": id.ValidUntil} {
+			if bound == nil {
+				continue
+			}
+			if err := CheckVersion(*bound); err != nil {
+				return fmt.Errorf("checksums.cosign.identities[%d].%s: invalid version: %w", i, field, err)
+			}
+		}
+	}
+	return nil
+}
+
+// minisignKey matches a minisign public key: base64 of the "Ed" algorithm, an
+// 8-byte key ID and a 32-byte Ed25519 key
+var minisignKey = regexp.MustCompile(`^RW[A-Za-z0-9+/]{54}$`)
+
+// validateSignature checks that the checksum file signature has exactly one
+// source of keys and that an inline key is in the format of the signature, as
+// installers write it to a file verbatim
+func validateSignature(sig *Signature) error {
+	switch sig.GetFormat() {
+	case Gpg, Minisign:
+	default:
+		return fmt.Errorf("checksums.signature.format must be gpg or minisign: %s", sig.GetFormat())
+	}
+	if err := ValidateShellSafe(sig.GetTemplate(), "checksums.signature.template"); err != nil {
+		return err
+	}
+	key, keyring := sig.GetKey(), sig.GetKeyring()
+	if (key == "") == (keyring == "") {
+		return fmt.Errorf("checksums.signature requires exactly one of key and keyring")
+	}
+	if keyring != "" {
+		if err := ValidateShellSafe(keyring, "checksums.signature.keyring"); err != nil {
+			return err
+		}
+		if strings.ContainsAny(keyring, "$'\"\\ \t") {
+			return fmt.Errorf("checksums.signature.keyring must be a plain URL without variables, quotes or spaces: %s", keyring)
+		}
+		if parsed, err := url.Parse(keyring); err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+			return fmt.Errorf("checksums.signature.keyring must be an https URL: %s", keyring)
+		}
+		return nil
+	}
+	key = strings.TrimSpace(key)
+	if sig.GetFormat() == Minisign {
+		if !minisignKey.MatchString(key) {
+			return fmt.Errorf("checksums.signature.key is not a minisign public key")
+		}
+		return nil
+	}
+	if !strings.HasPrefix(key, "-----BEGIN PGP PUBLIC KEY BLOCK-----") || !strings.HasSuffix(key, "-----END PGP PUBLIC KEY BLOCK-----") {
+		return fmt.Errorf("checksums.signature.key must be an armored PGP public key block")
+	}
+	for _, r := range key {
+		if unicode.IsControl(r) && r != '\n' {
+			return fmt.Errorf("checksums.signature.key contains control character (code %d)", r)
+		}
+	}
+	return nil
+}
+
+// validateBreakingChange checks that a breaking change has a version that can
+// be ordered (see CheckVersion) and one-line notes, which installers print quoted
+func validateBreakingChange(change BreakingChangeElement, field string) error {
+	if change.GetVersion() == "" {
+		return fmt.Errorf("%s.version is required", field)
+	}
+	if err := CheckVersion(change.GetVersion()); err != nil {
+		return fmt.Errorf("%s.version: invalid version: %w", field, err)
+	}
+	for _, r := range change.GetNotes() {
+		if unicode.IsControl(r) {
+			return fmt.Errorf("%s.notes contains control character (code %d)", field, r)
+		}
+	}
+	return nil
+}
+
+// hostPattern matches a host name with an optional port
+var hostPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9.-]*[A-Za-z0-9])?(:[0-9]+)?$`)
+
+// validateSource checks the release service and the host scripts download from
+func validateSource(s *InstallSpec) error {
+	switch s.GetSource() {
+	case Github, Gitlab:
+	default:
+		return fmt.Errorf("unsupported source: %s", s.GetSource())
+	}
+	if host := StringValue(s.Host); host != "" && !hostPattern.MatchString(host) {
+		return fmt.Errorf("host must be a host name with an optional port: %s", host)
+	}
+	return nil
+}
+
+// signerWorkflowPattern matches attestation.signer_workflow: a workflow path
+// prefixed with the repository that holds it
+var signerWorkflowPattern = regexp.MustCompile(`^[^/]+/[^/]+/.+$`)
+
+// ValidateAttestationHost checks that the releases of s are on github.com.
+// Attestations are verified against the identities of github.com workflows and
+// the public Sigstore instance, which GitHub Enterprise Server does not use.
+func ValidateAttestationHost(s *InstallSpec) error {
+	if host := s.GetHost(); !strings.EqualFold(host, DefaultGitHubHost) {
+		return fmt.Errorf("attestation is only supported for releases on %s, not host: %s", DefaultGitHubHost, host)
+	}
+	return nil
+}
+
+// validateAttestation checks the values installers pass to gh attestation verify
+func validateAttestation(a *Attestation) error {
+	if workflow := a.GetSignerWorkflow(); workflow != "" {
+		if !signerWorkflowPattern.MatchString(workflow) {
+			return fmt.Errorf("attestation.signer_workflow must be owner/repo/path/to/workflow: %s", workflow)
+		}
+		if err := ValidateShellSafe(workflow, "attestation.signer_workflow"); err != nil {
+			return err
+		}
+	}
+	return ValidateShellSafe(StringValue(a.PredicateType), "attestation.predicate_type")
+}
+
+// validateUsagePing refuses usage ping configurations whose intent is unclear
+// and URLs that could carry identifiers or break out of the script
+func validateUsagePing(u *UsagePing) error {
+	switch {
+	case u.GetEnabled() && u.GetURL() == "":
+		return fmt.Errorf("usage_ping.enabled is true but usage_ping.url is not set")
+	case u.Enabled == nil && u.GetURL() != "":
+		return fmt.Errorf("usage_ping.url is set but usage_ping.enabled is not; set enabled: true to opt in or enabled: false to keep it off")
+	case u.GetURL() == "":
+		return nil
+	}
+	raw := u.GetURL()
+	if err := ValidateShellSafe(raw, "usage_ping.url"); err != nil {
+		return err
+	}
+	if strings.ContainsAny(raw, "$'\"\\ \t") {
+		return fmt.Errorf("usage_ping.url must be a plain URL without variables, quotes or spaces: %s", raw)
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid usage_ping.url: %w", err)
+	}
+	if parsed.Scheme != "https" || parsed.Host == "" {
+		return fmt.Errorf("usage_ping.url must be an https URL: %s", raw)
+	}
+	if parsed.User != nil || parsed.Fragment != "" {
+		return fmt.Errorf("usage_ping.url must not contain credentials or a fragment: %s", raw)
+	}
+	return nil
+}
+
+// WebhookURLEnv is the only environment variable notify.webhook_url may reference
+const WebhookURLEnv = "BINSTALLER_WEBHOOK_URL"
+
+// ValidateWebhookURL refuses notify.webhook_url values referencing environment
+// variables other than WebhookURLEnv, which would post them to the webhook, and
+// literal URLs that are not https. A reference is checked once it is expanded.
+func ValidateWebhookURL(raw string) error {
+	identifiers, err := interpolate.Identifiers(raw)
+	if err != nil {
+		return fmt.Errorf("invalid notify.webhook_url: %w", err)
+	}
+	for _, identifier := range identifiers {
+		if identifier != WebhookURLEnv {
+			return fmt.Errorf("notify.webhook_url may only reference ${%s}, not ${%s}", WebhookURLEnv, identifier)
+		}
+	}
+	if len(identifiers) > 0 {
+		return nil
+	}
+	if parsed, err := url.Parse(raw); err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return fmt.Errorf("notify.webhook_url must be an https URL")
+	}
+	return nil
+}
+
+// validateDownload checks the ranges of the download settings, which scripts
+// pass to curl and wget as numbers
+func validateDownload(d *Download) error {
+	switch {
+	case d.Retries != nil && (*d.Retries < 0 || *d.Retries > 100):
+		return fmt.Errorf("download.retries must be between 0 and 100, got %d", *d.Retries)
+	case d.RetryDelay != nil && (*d.RetryDelay < 1 || *d.RetryDelay > 3600):
+		return fmt.Errorf("download.retry_delay must be between 1 and 3600 seconds, got %d", *d.RetryDelay)
+	case d.RetryDelay != nil && d.Retries == nil:
+		return fmt.Errorf("download.retry_delay requires download.retries")
+	case d.Timeout != nil && (*d.Timeout < 1 || *d.Timeout > math.MaxInt32):
+		return fmt.Errorf("download.timeout must be a positive number of seconds, got %d", *d.Timeout)
+	}
+	return nil
+}
+
+// validateVersion validates the version resolution configuration
+func validateVersion(v *Version) error {
+	switch v.GetSource() {
+	case GithubReleases, GithubTags:
+	case HTTPJSON:
+		if v.GetURL() == "" {
+			return fmt.Errorf("version.url is required for the %s source", HTTPJSON)
+		}
+	default:
+		return fmt.Errorf("unsupported version.source: %s", v.GetSource())
+	}
+	if err := ValidateShellSafe(v.GetURL(), "version.url"); err != nil {
+		return err
+	}
+	if err := ValidateShellSafe(v.GetJSONPath(), "version.json_path"); err != nil {
+		return err
+	}
+	if _, err := jsonpath.Parse(v.GetJSONPath()); err != nil {
+		return fmt.Errorf("invalid version.json_path: %w", err)
+	}
+	if pattern := v.GetTagPattern(); pattern != "" {
+		if v.GetSource() == HTTPJSON {
+			return fmt.Errorf("version.tag_pattern does not apply to the %s source", HTTPJSON)
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid version.tag_pattern: %w", err)
+		}
+	}
 	return nil
 }
@@ -185,6 +185,60 @@ func TestValidate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "asset.template",
 		},
+		{
+			name: "valid runtime_env",
+			spec: &InstallSpec{
+				Repo:       StringPtr("owner/repo"),
+				RuntimeEnv: map[string]string{"TOOL_HOME": "${INSTALL_DIR}/share/tool", "TOOL_PLUGINS": "${BINDIR}/plugins"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "runtime_env referencing another variable",
+			spec: &InstallSpec{
+				Repo:       StringPtr("owner/repo"),
+				RuntimeEnv: map[string]string{"TOOL_HOME": "${HOME}/.tool"},
+			},
+			wantErr: true,
+			errMsg:  "runtime_env.TOOL_HOME may only reference",
+		},
+		{
+			name: "runtime_env with an invalid name",
+			spec: &InstallSpec{
+				Repo:       StringPtr("owner/repo"),
+				RuntimeEnv: map[string]string{"TOOL-HOME": "/opt/tool"},
+			},
+			wantErr: true,
+			errMsg:  "invalid variable name",
+		},
+		{
+			name: "wrapper replacing a binary",
+			spec: &InstallSpec{
+				Name:     StringPtr("tool"),
+				Repo:     StringPtr("owner/repo"),
+				Wrappers: []WrapperElement{{Name: StringPtr("tool")}},
+			},
+			wantErr: true,
+			errMsg:  "must differ from the names of the binaries",
+		},
+		{
+			name: "wrapper name outside the installation directory",
+			spec: &InstallSpec{
+				Repo:     StringPtr("owner/repo"),
+				Wrappers: []WrapperElement{{Name: StringPtr("../tool-local")}},
+			},
+			wantErr: true,
+			errMsg:  "wrappers[0].name must be a file name",
+		},
+		{
+			name: "wrapper args referencing another variable",
+			spec: &InstallSpec{
+				Repo:     StringPtr("owner/repo"),
+				Wrappers: []WrapperElement{{Name: StringPtr("tool-local"), Args: []string{"--home=${HOME}"}}},
+			},
+			wantErr: true,
+			errMsg:  "wrappers[0].args[0] may only reference",
+		},
 		{
 			name: "invalid checksum template",
 			spec: &InstallSpec{
@@ -197,6 +251,284 @@ func TestValidate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "checksums.template",
 		},
+		{
+			name: "required checksums without a source",
+			spec: NewInstallSpec("owner/repo").
+				WithChecksums(NewChecksums("").WithRequired(true)),
+			wantErr: true,
+			errMsg:  "checksums.required",
+		},
+		{
+			name: "required checksums with embedded checksums",
+			spec: NewInstallSpec("owner/repo").
+				WithChecksums(NewChecksums("").WithRequired(true).WithEmbeddedChecksum("v1.0.0", "repo.tar.gz", "abc")),
+			wantErr: false,
+		},
+		{
+			name: "cosign without identity",
+			spec: NewInstallSpec("owner/repo").
+				WithChecksums(NewChecksums("checksums.txt").WithCosign(&Cosign{})),
+			wantErr: true,
+			errMsg:  "checksums.cosign",
+		},
+		{
+			name: "cosign with rotated identities",
+			spec: NewInstallSpec("owner/repo").
+				WithChecksums(NewChecksums("checksums.txt").WithCosign((&Cosign{}).
+					WithIdentity("^old$", "https://token.actions.githubusercontent.com", "", "v2.0.0").
+					WithIdentity("^new$", "https://token.actions.githubusercontent.com", "v2.0.0", ""))),
+			wantErr: false,
+		},
+		{
+			name: "cosign identity with invalid window",
+			spec: NewInstallSpec("owner/repo").
+				WithChecksums(NewChecksums("checksums.txt").WithCosign((&Cosign{}).
+					WithIdentity("^old$", "https://token.actions.githubusercontent.com", "", "next"))),
+			wantErr: true,
+			errMsg:  "checksums.cosign.identities[0].valid_until",
+		},
+		{
+			name: "minisign signature",
+			spec: NewInstallSpec("owner/repo").
+				WithChecksums(NewChecksums("checksums.txt").WithSignature(NewSignature(Minisign, "RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3"))),
+			wantErr: false,
+		},
+		{
+			name: "signature with invalid minisign key",
+			spec: NewInstallSpec("owner/repo").
+				WithChecksums(NewChecksums("checksums.txt").WithSignature(NewSignature(Minisign, "untrusted comment: minisign public key"))),
+			wantErr: true,
+			errMsg:  "not a minisign public key",
+		},
+		{
+			name: "gpg signature without armored key",
+			spec: NewInstallSpec("owner/repo").
+				WithChecksums(NewChecksums("checksums.txt").WithSignature(NewSignature(Gpg, "0123456789ABCDEF"))),
+			wantErr: true,
+			errMsg:  "armored PGP public key block",
+		},
+		{
+			name: "signature with key and keyring",
+			spec: NewInstallSpec("owner/repo").
+				WithChecksums(NewChecksums("checksums.txt").WithSignature(&Signature{
+					Key:     StringPtr("RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3"),
+					Keyring: StringPtr("https://example.com/key.asc"),
+				})),
+			wantErr: true,
+			errMsg:  "exactly one of key and keyring",
+		},
+		{
+			name: "signature keyring over http",
+			spec: NewInstallSpec("owner/repo").
+				WithChecksums(NewChecksums("checksums.txt").WithSignature(&Signature{Keyring: StringPtr("http://example.com/key.asc")})),
+			wantErr: true,
+			errMsg:  "https URL",
+		},
+		{
+			name: "attestation with signer workflow",
+			spec: NewInstallSpec("owner/repo").
+				WithAttestation(NewAttestation("owner/repo/.github/workflows/release.yml")),
+			wantErr: false,
+		},
+		{
+			name: "attestation signer workflow without repository",
+			spec: NewInstallSpec("owner/repo").
+				WithAttestation(NewAttestation("release.yml")),
+			wantErr: true,
+			errMsg:  "attestation.signer_workflow",
+		},
+		{
+			name: "attestation signer workflow with command substitution",
+			spec: NewInstallSpec("owner/repo").
+				WithAttestation(NewAttestation("owner/repo/$(id).yml")),
+			wantErr: true,
+			errMsg:  "attestation.signer_workflow",
+		},
+		{
+			name: "gitlab source",
+			spec: NewInstallSpec("group/subgroup/tool").
+				WithSource(Gitlab, "gitlab.example.com:8443"),
+			wantErr: false,
+		},
+		{
+			name: "github enterprise server host",
+			spec: NewInstallSpec("owner/repo").
+				WithSource(Github, "github.example.com"),
+			wantErr: false,
+		},
+		{
+			name: "github host with scheme",
+			spec: NewInstallSpec("owner/repo").
+				WithSource(Github, "https://github.example.com"),
+			wantErr: true,
+			errMsg:  "host must be a host name",
+		},
+		{
+			name: "gitlab host with path",
+			spec: NewInstallSpec("group/tool").
+				WithSource(Gitlab, "gitlab.example.com/gitlab"),
+			wantErr: true,
+			errMsg:  "host must be a host name",
+		},
+		{
+			name: "unsupported source",
+			spec: NewInstallSpec("owner/repo").
+				WithSource("bitbucket", ""),
+			wantErr: true,
+			errMsg:  "unsupported source: bitbucket",
+		},
+		{
+			name: "attestation of gitlab releases",
+			spec: NewInstallSpec("group/tool").
+				WithSource(Gitlab, "").
+				WithAttestation(NewAttestation("")),
+			wantErr: true,
+			errMsg:  "attestation is only supported for GitHub releases",
+		},
+		{
+			name: "attestation on GitHub Enterprise Server",
+			spec: NewInstallSpec("owner/repo").
+				WithSource(Github, "github.example.com").
+				WithAttestation(NewAttestation("")),
+			wantErr: true,
+			errMsg:  "attestation is only supported for releases on github.com",
+		},
+		{
+			name: "usage ping",
+			spec: NewInstallSpec("owner/repo").
+				WithUsagePing(NewUsagePing("https://counter.example.com/tool?project=tool")),
+			wantErr: false,
+		},
+		{
+			name: "usage ping url without opt-in",
+			spec: NewInstallSpec("owner/repo").
+				WithUsagePing(&UsagePing{URL: StringPtr("https://counter.example.com/tool")}),
+			wantErr: true,
+			errMsg:  "usage_ping.enabled is not",
+		},
+		{
+			name: "usage ping turned off",
+			spec: NewInstallSpec("owner/repo").
+				WithUsagePing(NewUsagePing("https://counter.example.com/tool").WithEnabled(false)),
+			wantErr: false,
+		},
+		{
+			name: "usage ping opt-in without url",
+			spec: NewInstallSpec("owner/repo").
+				WithUsagePing(NewUsagePing("")),
+			wantErr: true,
+			errMsg:  "usage_ping.url is not set",
+		},
+		{
+			name: "usage ping over http",
+			spec: NewInstallSpec("owner/repo").
+				WithUsagePing(NewUsagePing("http://counter.example.com/tool")),
+			wantErr: true,
+			errMsg:  "https",
+		},
+		{
+			name: "usage ping url with variables",
+			spec: NewInstallSpec("owner/repo").
+				WithUsagePing(NewUsagePing("https://counter.example.com/tool?user=${USER}")),
+			wantErr: true,
+			errMsg:  "without variables",
+		},
+		{
+			name: "usage ping url with credentials",
+			spec: NewInstallSpec("owner/repo").
+				WithUsagePing(NewUsagePing("https://token@counter.example.com/tool")),
+			wantErr: true,
+			errMsg:  "credentials",
+		},
+		{
+			name:    "notify webhook from BINSTALLER_WEBHOOK_URL",
+			spec:    &InstallSpec{Repo: StringPtr("owner/repo"), Notify: &Notify{WebhookURL: StringPtr("${BINSTALLER_WEBHOOK_URL}")}},
+			wantErr: false,
+		},
+		{
+			name:    "notify webhook with other variables",
+			spec:    &InstallSpec{Repo: StringPtr("owner/repo"), Notify: &Notify{WebhookURL: StringPtr("https://hooks.example.com/?token=${GITHUB_TOKEN}")}},
+			wantErr: true,
+			errMsg:  "may only reference ${BINSTALLER_WEBHOOK_URL}",
+		},
+		{
+			name:    "notify webhook over http",
+			spec:    &InstallSpec{Repo: StringPtr("owner/repo"), Notify: &Notify{WebhookURL: StringPtr("http://hooks.example.com/services/T000")}},
+			wantErr: true,
+			errMsg:  "https",
+		},
+		{
+			name: "download retries and timeout",
+			spec: NewInstallSpec("owner/repo").
+				WithDownload((&Download{}).WithRetries(5).WithRetryDelay(3).WithTimeout(120)),
+			wantErr: false,
+		},
+		{
+			name: "download retries disabled",
+			spec: NewInstallSpec("owner/repo").
+				WithDownload((&Download{}).WithRetries(0)),
+			wantErr: false,
+		},
+		{
+			name: "negative download retries",
+			spec: NewInstallSpec("owner/repo").
+				WithDownload((&Download{}).WithRetries(-1)),
+			wantErr: true,
+			errMsg:  "download.retries",
+		},
+		{
+			name: "download retry delay without retries",
+			spec: NewInstallSpec("owner/repo").
+				WithDownload((&Download{}).WithRetryDelay(3)),
+			wantErr: true,
+			errMsg:  "requires download.retries",
+		},
+		{
+			name: "zero download timeout",
+			spec: NewInstallSpec("owner/repo").
+				WithDownload((&Download{}).WithTimeout(0)),
+			wantErr: true,
+			errMsg:  "download.timeout",
+		},
+		{
+			name: "breaking changes",
+			spec: NewInstallSpec("owner/repo").
+				WithBreakingChange("v2.0.0", "Config keys were renamed; see https://example.com/v2 & the FAQ"),
+			wantErr: false,
+		},
+		{
+			name: "breaking change without a semantic version",
+			spec: NewInstallSpec("owner/repo").
+				WithBreakingChange("next", ""),
+			wantErr: true,
+			errMsg:  "breaking_changes[0].version: invalid version",
+		},
+		{
+			name: "breaking change notes on several lines",
+			spec: NewInstallSpec("owner/repo").
+				WithBreakingChange("v2.0.0", "line one\nline two"),
+			wantErr: true,
+			errMsg:  "breaking_changes[0].notes contains control character",
+		},
+		{
+			name: "breaking changes url over http",
+			spec: &InstallSpec{
+				Repo:               StringPtr("owner/repo"),
+				BreakingChangesURL: StringPtr("http://example.com/breaking.yml"),
+			},
+			wantErr: true,
+			errMsg:  "breaking_changes_url must be an https URL",
+		},
+		{
+			name: "nested tools",
+			spec: &InstallSpec{
+				Tools: []InstallSpec{
+					{Repo: StringPtr("cli/cli"), Tools: []InstallSpec{{Repo: StringPtr("junegunn/fzf")}}},
+				},
+			},
+			wantErr: true,
+			errMsg:  "tools[0] cannot declare tools of its own",
+		},
 		{
 			name: "invalid rule template",
 			spec: &InstallSpec{
@@ -251,6 +583,95 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "invalid fallback template with command substitution",
+			spec: NewInstallSpec("owner/repo").WithAsset(NewAsset("${NAME}${EXT}").
+				WithRules(NewRule("linux", "").WithFallbackTemplates("${NAME}-$(id)${EXT}"))),
+			wantErr: true,
+			errMsg:  "asset.rules[0].fallback_templates[0]",
+		},
+		{
+			name: "invalid fallback template with whitespace",
+			spec: NewInstallSpec("owner/repo").WithAsset(NewAsset("${NAME}${EXT}").
+				WithRules(NewRule("linux", "").WithFallbackTemplates("${NAME} gnu${EXT}"))),
+			wantErr: true,
+			errMsg:  "must not contain whitespace",
+		},
+		{
+			name:    "valid extra file",
+			spec:    NewInstallSpec("owner/repo").WithAsset(NewAsset("${NAME}${EXT}").WithExtraFile("${NAME}-data.tar.gz", "../share/tool")),
+			wantErr: false,
+		},
+		{
+			name:    "invalid extra file template with command substitution",
+			spec:    NewInstallSpec("owner/repo").WithAsset(NewAsset("${NAME}${EXT}").WithExtraFile("$(id).tar.gz", "")),
+			wantErr: true,
+			errMsg:  "asset.extra_files[0].template",
+		},
+		{
+			name:    "invalid absolute extra file dest",
+			spec:    NewInstallSpec("owner/repo").WithAsset(NewAsset("${NAME}${EXT}").WithExtraFile("${NAME}-data.tar.gz", "/usr/share/tool")),
+			wantErr: true,
+			errMsg:  "asset.extra_files[0].dest must be relative",
+		},
+		{
+			name:    "valid completions",
+			spec:    NewInstallSpec("owner/repo").WithAsset(NewAsset("${NAME}${EXT}")).WithCompletion("bash", "completions/tool.bash").WithCompletion("zsh", "completions/_tool"),
+			wantErr: false,
+		},
+		{
+			name:    "invalid completion path outside the archive",
+			spec:    NewInstallSpec("owner/repo").WithAsset(NewAsset("${NAME}${EXT}")).WithCompletion("fish", "../tool.fish"),
+			wantErr: true,
+			errMsg:  "completions.fish must be a path within the archive",
+		},
+		{
+			name:    "invalid completion path with command substitution",
+			spec:    NewInstallSpec("owner/repo").WithAsset(NewAsset("${NAME}${EXT}")).WithCompletion("bash", "$(id)"),
+			wantErr: true,
+			errMsg:  "completions.bash",
+		},
+		{
+			name:    "valid release variables in asset template",
+			spec:    NewInstallSpec("owner/repo").WithAsset(NewAsset("${NAME}-${PUBLISHED_DATE}${EXT}")),
+			wantErr: false,
+		},
+		{
+			name:    "invalid release variables in checksum template",
+			spec:    NewInstallSpec("owner/repo").WithAsset(NewAsset("${NAME}${EXT}")).WithChecksums(NewChecksums("${NAME}-${RELEASE_NAME}.sha256")),
+			wantErr: true,
+			errMsg:  "checksums.template cannot reference release metadata variables",
+		},
+		{
+			name:    "valid unpack filters",
+			spec:    NewInstallSpec("owner/repo").WithAsset(NewAsset("${NAME}${EXT}")).WithUnpack(NewUnpack(1).WithInclude("bin/*", "lib/[!.]*").WithExclude("share/doc")),
+			wantErr: false,
+		},
+		{
+			name:    "invalid unpack include with quote",
+			spec:    NewInstallSpec("owner/repo").WithAsset(NewAsset("${NAME}${EXT}")).WithUnpack(NewUnpack(0).WithInclude("bin/*'; id")),
+			wantErr: true,
+			errMsg:  "unpack.include[0]",
+		},
+		{
+			name:    "invalid absolute unpack exclude",
+			spec:    NewInstallSpec("owner/repo").WithAsset(NewAsset("${NAME}${EXT}")).WithUnpack(NewUnpack(0).WithExclude("/etc")),
+			wantErr: true,
+			errMsg:  "relative to the archive root",
+		},
+		{
+			name: "invalid os_version pattern",
+			spec: NewInstallSpec("owner/repo").WithAsset(NewAsset("${NAME}${EXT}").
+				WithRules(NewRule("linux", "").WithOSVersion("alpine'; id"))),
+			wantErr: true,
+			errMsg:  "asset.rules[0].when.os_version",
+		},
+		{
+			name: "valid os_version pattern",
+			spec: NewInstallSpec("owner/repo").WithAsset(NewAsset("${NAME}${EXT}").
+				WithRules(NewRule("darwin", "").WithOSVersion("macos-1[34]"))),
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -268,3 +689,30 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestValidate_Version(t *testing.T) {
+	tests := []struct {
+		name    string
+		version *Version
+		wantErr bool
+	}{
+		{"default source", &Version{}, false},
+		{"github tags", NewVersion(GithubTags), false},
+		{"http json", NewVersion(HTTPJSON).WithURL("https://example.com/${NAME}.json").WithJSONPath("$.stable.version"), false},
+		{"http json without url", NewVersion(HTTPJSON), true},
+		{"unknown source", NewVersion("gitlab"), true},
+		{"dangerous url", NewVersion(HTTPJSON).WithURL("https://example.com/$(id)"), true},
+		{"invalid json path", NewVersion(HTTPJSON).WithURL("https://example.com").WithJSONPath("version"), true},
+		{"tag pattern", NewVersion(GithubReleases).WithTagPattern(`^v\d+\.\d+\.\d+$`), false},
+		{"invalid tag pattern", NewVersion(GithubTags).WithTagPattern("v(1"), true},
+		{"tag pattern of http json", NewVersion(HTTPJSON).WithURL("https://example.com").WithTagPattern("^v"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewInstallSpec("owner/repo").WithVersion(tt.version)
+			if err := Validate(s); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package spec
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// dateVersion matches versions starting with a date, which are not semantic
+// versions even when they parse as one: 2024-05-01 would be 2024.0.0 with the
+// pre-release 05-01
+var dateVersion = regexp.MustCompile(`^[vV]?\d{4}-\d{1,2}-\d{1,2}`)
+
+// semanticVersion parses version as a semantic version, or returns nil when
+// it follows another scheme
+func semanticVersion(version string) *semver.Version {
+	if dateVersion.MatchString(version) {
+		return nil
+	}
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+// CheckVersion returns an error when version cannot be ordered against other
+// versions. Besides semantic versions, any version with a number can, such as
+// dates (2024.05.01) or build numbers (b5123); names like nightly cannot.
+func CheckVersion(version string) error {
+	if semanticVersion(version) != nil || strings.ContainsAny(version, "0123456789") {
+		return nil
+	}
+	return fmt.Errorf("%q has no version number", version)
+}
+
+// CompareVersions returns -1, 0 or +1 when version a is older than, the same
+// as or newer than b. Two semantic versions are compared by semver precedence.
+// Other versions are compared piece by piece, ignoring punctuation and a v
+// prefix: numbers numerically and words as text, so 2024.10.1 is newer than
+// 2024.9.30 and build-1000 newer than build-999. A version continued by a
+// word, as in 2024.05.01-rc1, is older than the version without it.
+func CompareVersions(a, b string) int {
+	if va, vb := semanticVersion(a), semanticVersion(b); va != nil && vb != nil {
+		return va.Compare(vb)
+	}
+	x, y := versionPieces(a), versionPieces(b)
+	for i := 0; i < len(x) || i < len(y); i++ {
+		switch {
+		case i == len(x):
+			if isNumber(y[i]) {
+				return -1
+			}
+			return 1
+		case i == len(y):
+			if isNumber(x[i]) {
+				return 1
+			}
+			return -1
+		}
+		if c := comparePieces(x[i], y[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// IsSemanticVersion reports whether version is a semantic version, with or
+// without a v prefix
+func IsSemanticVersion(version string) bool {
+	return semanticVersion(version) != nil
+}
+
+// IsPrerelease reports whether version is a semantic version with a
+// pre-release part. Versions of other schemes are never pre-releases.
+func IsPrerelease(version string) bool {
+	v := semanticVersion(version)
+	return v != nil && v.Prerelease() != ""
+}
+
+// versionPieces splits a version into its runs of digits and of letters
+func versionPieces(version string) []string {
+	if len(version) > 1 && (version[0] == 'v' || version[0] == 'V') && isDigit(version[1]) {
+		version = version[1:]
+	}
+	var pieces []string
+	start := -1
+	for i := 0; i <= len(version); i++ {
+		if start >= 0 && (i == len(version) || !isAlnum(version[i]) || isDigit(version[i]) != isDigit(version[start])) {
+			pieces = append(pieces, version[start:i])
+			start = -1
+		}
+		if start < 0 && i < len(version) && isAlnum(version[i]) {
+			start = i
+		}
+	}
+	return pieces
+}
+
+// comparePieces compares two pieces of versions. Numbers are newer than words.
+func comparePieces(a, b string) int {
+	switch {
+	case isNumber(a) && isNumber(b):
+		a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+		if len(a) != len(b) {
+			if len(a) < len(b) {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a, b)
+	case isNumber(a):
+		return 1
+	case isNumber(b):
+		return -1
+	}
+	return strings.Compare(a, b)
+}
+
+func isNumber(piece string) bool {
+	return piece != "" && isDigit(piece[0])
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isAlnum(c byte) bool {
+	return isDigit(c) || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
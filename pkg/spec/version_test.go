@@ -0,0 +1,59 @@
+package spec
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.2.3", "1.2.3", 0},
+		{"v1.10.0", "v1.9.0", 1},
+		{"v2.0.0-rc.1", "v2.0.0", -1},
+		{"2024.10.01", "2024.9.30", 1},
+		{"2024.05.01", "2024.05.01.1", -1},
+		{"2024.05.01-rc1", "2024.05.01", -1},
+		{"2024-05-01", "2024.05.01", 0},
+		{"2024-10-01", "2024-09-30", 1},
+		{"build-1000", "build-999", 1},
+		{"b5123", "b5123", 0},
+		{"r99", "r100", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			if got := CompareVersions(tt.a, tt.b); got != tt.want {
+				t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+			if got := CompareVersions(tt.b, tt.a); got != -tt.want {
+				t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	for version, valid := range map[string]bool{
+		"v1.2.3":     true,
+		"2024.05.01": true,
+		"b5123":      true,
+		"nightly":    false,
+		"":           false,
+	} {
+		if err := CheckVersion(version); (err == nil) != valid {
+			t.Errorf("CheckVersion(%q) error = %v, want valid %v", version, err, valid)
+		}
+	}
+}
+
+func TestIsPrerelease(t *testing.T) {
+	for version, want := range map[string]bool{
+		"v2.0.0-rc.1": true,
+		"v2.0.0":      false,
+		"2024-05-01":  false,
+		"b5123":       false,
+	} {
+		if got := IsPrerelease(version); got != want {
+			t.Errorf("IsPrerelease(%q) = %v, want %v", version, got, want)
+		}
+	}
+}
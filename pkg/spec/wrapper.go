@@ -0,0 +1,77 @@
+package spec
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// wrapperNamePattern matches the file names allowed for wrappers and the
+// binaries they run
+var wrapperNamePattern = regexp.MustCompile(`^[A-Za-z0-9_+-][A-Za-z0-9._+-]*$`)
+
+// GetName returns the name of the wrapper script
+func (w *WrapperElement) GetName() string {
+	if w == nil {
+		return ""
+	}
+	return StringValue(w.Name)
+}
+
+// WrapperBinary returns the binary a wrapper runs: its binary, or else the
+// first binary of asset.binaries
+func (s *InstallSpec) WrapperBinary(w *WrapperElement) string {
+	if binary := StringValue(w.Binary); binary != "" {
+		return binary
+	}
+	if s.Asset != nil && len(s.Asset.Binaries) > 0 {
+		if name := s.Asset.Binaries[0].GetName(); name != "" {
+			return name
+		}
+	}
+	return s.GetName()
+}
+
+// validateWrappers checks that wrappers have unique file names that do not
+// replace a binary, and env and args that only reference ${INSTALL_DIR} and
+// ${BINDIR}
+func validateWrappers(s *InstallSpec) error {
+	binaries := make(map[string]bool)
+	if s.Asset != nil {
+		for _, binary := range s.Asset.Binaries {
+			binaries[binary.GetName()] = true
+		}
+		for _, rule := range s.Asset.Rules {
+			for _, binary := range rule.Binaries {
+				binaries[binary.GetName()] = true
+			}
+		}
+	}
+	names := make(map[string]bool)
+	for i, w := range s.Wrappers {
+		field := fmt.Sprintf("wrappers[%d]", i)
+		name := w.GetName()
+		switch {
+		case name == "":
+			return fmt.Errorf("%s.name is required", field)
+		case !wrapperNamePattern.MatchString(name):
+			return fmt.Errorf("%s.name must be a file name of letters, digits, '.', '_', '+' and '-': %s", field, name)
+		case binaries[name] || name == s.GetName():
+			return fmt.Errorf("%s.name %s must differ from the names of the binaries", field, name)
+		case names[name]:
+			return fmt.Errorf("%s.name %s is used by another wrapper", field, name)
+		}
+		names[name] = true
+		if binary := StringValue(w.Binary); binary != "" && !wrapperNamePattern.MatchString(binary) {
+			return fmt.Errorf("%s.binary must be a file name of letters, digits, '.', '_', '+' and '-': %s", field, binary)
+		}
+		if err := validateRuntimeEnv(w.Env, field+".env"); err != nil {
+			return err
+		}
+		for j, arg := range w.Args {
+			if err := validateDirRefs(arg, fmt.Sprintf("%s.args[%d]", field, j)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -6,6 +6,10 @@ QUICKTYPE_REPO="https://github.com/haya14busa/quicktype.git"
 QUICKTYPE_BRANCH="fix-unevaluated-properties-support"
 QUICKTYPE_DIR="/tmp/quicktype-fork"
 SCRIPT_DIR="$(cd "$(dirname "$0")" && pwd)"
+# Set REQUIRED_AS_VALUES=1 to generate non-pointer fields for required properties.
+# By default every property is optional (pointer) and pkg/spec/accessors.go
+# provides value accessors on top of the generated types.
+REQUIRED_AS_VALUES="${REQUIRED_AS_VALUES:-0}"
 
 echo "🔧 Setting up forked quicktype..."
 
@@ -51,6 +55,11 @@ deno run --allow-read --allow-write --allow-env add-quicktype-property-order.ts
     --output "$TMP_SCHEMA"
 
 # Generate Go structs using the temporary file
+QUICKTYPE_OPTS=()
+if [ "$REQUIRED_AS_VALUES" != "1" ]; then
+    QUICKTYPE_OPTS+=(--all-properties-optional)
+fi
+
 echo "🚀 Generating Go structs..."
 node "$QUICKTYPE_DIR/dist/index.js" \
     --src "$TMP_SCHEMA" \
@@ -58,7 +67,7 @@ node "$QUICKTYPE_DIR/dist/index.js" \
     --lang go \
     --package spec \
     -o "../pkg/spec/generated.go" \
-    --all-properties-optional \
+    ${QUICKTYPE_OPTS[@]+"${QUICKTYPE_OPTS[@]}"} \
     --top-level InstallSpec
 
 # Clean up temporary file
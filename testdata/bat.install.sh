@@ -7,19 +7,28 @@ usage() {
   cat <<EOF
 $this: download ${NAME} from ${REPO}
 
-Usage: $this [-b bindir] [-d] [-q] [-n] [tag]
+Usage: $this [-b bindir] [-d] [-q] [-n] [-s] [-o os] [-a arch] [tag]
   -b sets bindir or installation directory, Defaults to ${BINSTALLER_BIN:-${HOME}/.local/bin}
   -d turns on debug logging
   -q turns on quiet mode (errors only)
   -n turns on dry run mode
+  -s fails instead of installing into ~/.local/bin when bindir is read-only
+  -o sets the OS instead of detecting it (e.g. linux)
+  -a sets the architecture instead of detecting it (e.g. arm64)
+  -x traces the script and logs the resolved platform, tag, asset URL and
+     checksum source in one line
+  --log-json logs one JSON object per line, e.g. for CI log scraping
+  The deprecated --bindir, --debug, --quiet, --dry-run, and --help
+  options still work.
    [tag] is a tag from
    https://github.com/sharkdp/bat/releases
    If tag is missing, then latest will be used.
 
 Environment variables:
   BINSTALLER_NO_PROGRESS=1   Disable progress indicators
-  BINSTALLER_OS=...          Override OS detection
-  BINSTALLER_ARCH=...        Override architecture detection
+  BINSTALLER_OS=...          Override OS detection (-o takes precedence)
+  BINSTALLER_ARCH=...        Override architecture detection (-a takes precedence)
+  BINSTALLER_NO_FALLBACK=1   Fail instead of falling back to ~/.local/bin (like -s)
 
  Generated by binstaller
   https://github.com/binary-install/binstaller
@@ -65,21 +74,45 @@ log_tag() {
     *) echo "$1" ;;
   esac
 }
+_logjson=0
+_logstep="init"
+log_set_json() {
+  _logjson="$1"
+}
+log_set_step() {
+  _logstep="$1"
+}
+log_json_escape() {
+  printf '%s' "$1" | tr '\n\t' '  ' | tr -d '\000-\037' | sed -e 's/\\/\\\\/g' -e 's/"/\\"/g'
+}
+log_line() {
+  _loglevel="$(log_tag "$1")"
+  shift
+  if [ "$_logjson" = 1 ]; then
+    printf '{"level":"%s","repo":"%s","step":"%s","msg":"%s"}\n' "$_loglevel" "$(log_json_escape "$(log_prefix)")" "$_logstep" "$(log_json_escape "$*")" 1>&2
+  else
+    echoerr "$(log_prefix)" "$_loglevel" "$@"
+  fi
+}
 log_debug() {
   log_priority 7 || return 0
-  echoerr "$(log_prefix)" "$(log_tag 7)" "$@"
+  log_line 7 "$@"
 }
 log_info() {
   log_priority 6 || return 0
-  echoerr "$(log_prefix)" "$(log_tag 6)" "$@"
+  log_line 6 "$@"
+}
+log_warn() {
+  log_priority 4 || return 0
+  log_line 4 "$@"
 }
 log_err() {
   log_priority 3 || return 0
-  echoerr "$(log_prefix)" "$(log_tag 3)" "$@"
+  log_line 3 "$@"
 }
 log_crit() {
   log_priority 2 || return 0
-  echoerr "$(log_prefix)" "$(log_tag 2)" "$@"
+  log_line 2 "$@"
 }
 uname_os() {
   os=$(uname -s | tr '[:upper:]' '[:lower:]')
@@ -106,9 +139,12 @@ uname_arch() {
     i686) arch="386" ;;
     i386) arch="386" ;;
     aarch64) arch="arm64" ;;
+    aarch64_be) arch="arm64be" ;;
+    armv*b) arch="armbe" ;;
     armv5*) arch="armv5" ;;
     armv6*) arch="armv6" ;;
     armv7*) arch="armv7" ;;
+    loongarch64) arch="loong64" ;;
   esac
   echo "${arch}"
 }
@@ -148,7 +184,13 @@ uname_arch_check() {
     mips64) return 0 ;;
     mips64le) return 0 ;;
     s390x) return 0 ;;
+    riscv64) return 0 ;;
+    loong64) return 0 ;;
     amd64p32) return 0 ;;
+    armbe | arm64be)
+      log_crit "big-endian ARM ('$(uname -m)') has no release binaries: releases target little-endian ARM"
+      return 1
+      ;;
   esac
   log_crit "uname_arch_check '$(uname -m)' got converted to '$arch' which is not a GOARCH value.  Please file bug report at https://github.com/client9/shlib"
   return 1
@@ -160,31 +202,6 @@ End of functions from https://github.com/client9/shlib
 EOF
 
 
-hash_sha256() {
-  TARGET=${1:-/dev/stdin}
-  if is_command gsha256sum; then
-    hash=$(gsha256sum "$TARGET") || return 1
-    echo "$hash" | cut -d ' ' -f 1
-  elif is_command sha256sum; then
-    hash=$(sha256sum "$TARGET") || return 1
-    echo "$hash" | cut -d ' ' -f 1
-  elif is_command shasum; then
-    hash=$(shasum -a 256 "$TARGET" 2>/dev/null) || return 1
-    echo "$hash" | cut -d ' ' -f 1
-  elif is_command openssl; then
-    hash=$(openssl dgst -sha256 "$TARGET") || return 1
-    echo "$hash" | cut -d ' ' -f 2
-  else
-    log_crit "hash_sha256 unable to find command to compute sha-256 hash"
-    return 1
-  fi
-}
-
-hash_compute() {
-  hash_sha256 "$1"
-}
-
-
 # shellcheck shell=sh
 # Terminal progress reporting functions
 progress_init() {
@@ -235,9 +252,10 @@ untar() {
   case "${tarball}" in
   *.tar.gz | *.tgz) tar --no-same-owner -xzf "${tarball}" --strip-components "${strip_components}" ;;
   *.tar.xz) tar --no-same-owner -xJf "${tarball}" --strip-components "${strip_components}" ;;
-  *.tar.bz2) tar --no-same-owner -xjf "${tarball}" --strip-components "${strip_components}" ;;
+  *.tar.bz2 | *.tbz2 | *.tbz) tar --no-same-owner -xjf "${tarball}" --strip-components "${strip_components}" ;;
   *.tar) tar --no-same-owner -xf "${tarball}" --strip-components "${strip_components}" ;;
   *.gz) gunzip "${tarball}" ;;
+  *.bz2) bunzip2 "${tarball}" ;;
   *.zip)
     # unzip doesn't have a standard --strip-components
     # Workaround: extract to a subdir and move contents up if stripping
@@ -268,113 +286,90 @@ untar() {
   esac
 }
 
-
-
-hash_verify() {
-  TARGET_PATH=$1
-  SUMFILE=$2
-  if [ -z "${SUMFILE}" ]; then
-    log_err "hash_verify checksum file not specified in arg2"
-    return 1
-  fi
-  got=$(hash_compute "$TARGET_PATH")
-  if [ -z "${got}" ]; then
-    log_err "failed to calculate hash: ${TARGET_PATH}"
-    return 1
-  fi
-
-  BASENAME=${TARGET_PATH##*/}
-
-  # Check for line matches in checksum file
-  # Format: "<hash>  <filename>" or "<hash> *<filename>"
-  # Filename may include path prefix (e.g., "deployment/m2/file.tar.gz")
-  while IFS= read -r line || [ -n "$line" ]; do
-    # Normalize tabs to spaces
-    line=$(echo "$line" | tr '\t' ' ')
-
-    # Remove trailing spaces for hash-only line check
-    line_trimmed=$(echo "$line" | sed 's/[[:space:]]*$//')
-
-    # Check for hash-only line (no filename) - early return
-    if [ "$line_trimmed" = "$got" ]; then
-      return 0
-    fi
-
-    # Extract hash and filename parts
-    # First field is the hash, rest is filename (which may contain spaces)
-    line_hash=$(echo "$line" | cut -d' ' -f1)
-
-    # Skip if hash doesn't match
-    if [ "$line_hash" != "$got" ]; then
-      continue
-    fi
-
-    # Hash matches, now check filename
-    # Remove the hash part from the beginning of the line
-    line_rest="${line#"$got"}"
-    # Remove leading spaces
-    while [ "${line_rest#[ ]}" != "$line_rest" ]; do
-      line_rest="${line_rest#[ ]}"
-    done
-
-    # Remove leading asterisk if present (binary mode indicator)
-    if [ "${line_rest#\*}" != "$line_rest" ]; then
-      line_rest="${line_rest#\*}"
-    fi
-
-    # Extract just the filename without any path
-    line_filename="${line_rest##*/}"
-
-    # Check if the filename matches
-    if [ "$line_filename" = "$BASENAME" ]; then
-      return 0
-    fi
-  done < "$SUMFILE"
-
-  log_err "hash_verify checksum for '$TARGET_PATH' did not verify"
-  log_err "  Expected hash: ${got}"
-  log_err "  Checksum file content:"
-  cat "$SUMFILE" >&2
-  return 1
-}
-
 # GitHub HTTP download functions with GITHUB_TOKEN support
+#
+# With a token, redirects are followed by hand so that the Authorization header
+# is only sent to the host of the URL. GitHub redirects release assets to signed
+# objects.githubusercontent.com URLs, which reject requests carrying another
+# Authorization header, and wget forwards --header to every redirect host.
+url_host() {
+  echo "$1" | sed -e 's#^[A-Za-z][A-Za-z0-9+.-]*://##' -e 's#[/?#].*##' -e 's#.*@##'
+}
+github_redirect_limit=10
 github_http_download_curl() {
   local_file=$1
   source_url=$2
   header=$3
   if [ -n "$GITHUB_TOKEN" ]; then
     log_debug "Using GITHUB_TOKEN for authentication"
-    if [ -z "$header" ]; then
-      curl -fsSL -H "Authorization: Bearer $GITHUB_TOKEN" -o "$local_file" "$source_url"
-    else
-      curl -fsSL -H "Authorization: Bearer $GITHUB_TOKEN" -H "$header" -o "$local_file" "$source_url"
-    fi
+    auth_host=$(url_host "$source_url")
+    redirects=0
+    while [ "$(url_host "$source_url")" = "$auth_host" ]; do
+      if [ -z "$header" ]; then
+        redirect_url=$(curl -fsS -H "Authorization: Bearer $GITHUB_TOKEN" -o "$local_file" -w '%{redirect_url}' "$source_url") || return 1
+      else
+        redirect_url=$(curl -fsS -H "Authorization: Bearer $GITHUB_TOKEN" -H "$header" -o "$local_file" -w '%{redirect_url}' "$source_url") || return 1
+      fi
+      [ -z "$redirect_url" ] && return 0
+      redirects=$((redirects + 1))
+      if [ "$redirects" -gt "$github_redirect_limit" ]; then
+        log_err "github_http_download stopped after ${github_redirect_limit} redirects"
+        return 1
+      fi
+      source_url=$redirect_url
+    done
+    log_debug "Following redirect to $(url_host "$source_url") without GITHUB_TOKEN"
+  fi
+  if [ -z "$header" ]; then
+    curl -fsSL -o "$local_file" "$source_url"
   else
-    if [ -z "$header" ]; then
-      curl -fsSL -o "$local_file" "$source_url"
-    else
-      curl -fsSL -H "$header" -o "$local_file" "$source_url"
-    fi
+    curl -fsSL -H "$header" -o "$local_file" "$source_url"
   fi
 }
 github_http_download_wget() {
   local_file=$1
   source_url=$2
   header=$3
-  if [ -n "$GITHUB_TOKEN" ]; then
+  if [ -n "$GITHUB_TOKEN" ] && ! wget --help 2>&1 | grep -q -- --max-redirect; then
+    # BusyBox wget follows every redirect with the same headers, which would
+    # send GITHUB_TOKEN to the host a release asset redirects to
+    log_warn "wget cannot stop at redirects; downloading without GITHUB_TOKEN (install curl to authenticate)"
+  elif [ -n "$GITHUB_TOKEN" ]; then
     log_debug "Using GITHUB_TOKEN for authentication"
-    if [ -z "$header" ]; then
-      wget -q --header "Authorization: Bearer $GITHUB_TOKEN" -O "$local_file" "$source_url"
-    else
-      wget -q --header "Authorization: Bearer $GITHUB_TOKEN" --header "$header" -O "$local_file" "$source_url"
-    fi
+    auth_host=$(url_host "$source_url")
+    redirects=0
+    headers_file=$(mktemp)
+    while [ "$(url_host "$source_url")" = "$auth_host" ]; do
+      # wget fails on a redirect it may not follow; the Location header tells
+      # a redirect apart from an error
+      if [ -z "$header" ]; then
+        wget -q -S --max-redirect=0 --header "Authorization: Bearer $GITHUB_TOKEN" -O "$local_file" "$source_url" 2>"$headers_file" && status=0 || status=$?
+      else
+        wget -q -S --max-redirect=0 --header "Authorization: Bearer $GITHUB_TOKEN" --header "$header" -O "$local_file" "$source_url" 2>"$headers_file" && status=0 || status=$?
+      fi
+      redirect_url=$(tr -d '\r' <"$headers_file" | sed -n 's/^ *[Ll][Oo][Cc][Aa][Tt][Ii][Oo][Nn]: *//p' | tail -n 1)
+      if [ -z "$redirect_url" ]; then
+        rm -f "$headers_file"
+        return "$status"
+      fi
+      case "$redirect_url" in
+        /*) redirect_url="${source_url%%://*}://$(url_host "$source_url")${redirect_url}" ;;
+      esac
+      redirects=$((redirects + 1))
+      if [ "$redirects" -gt "$github_redirect_limit" ]; then
+        rm -f "$headers_file"
+        log_err "github_http_download stopped after ${github_redirect_limit} redirects"
+        return 1
+      fi
+      source_url=$redirect_url
+    done
+    rm -f "$headers_file"
+    log_debug "Following redirect to $(url_host "$source_url") without GITHUB_TOKEN"
+  fi
+  if [ -z "$header" ]; then
+    wget -q -O "$local_file" "$source_url"
   else
-    if [ -z "$header" ]; then
-      wget -q -O "$local_file" "$source_url"
-    else
-      wget -q --header "$header" -O "$local_file" "$source_url"
-    fi
+    wget -q --header "$header" -O "$local_file" "$source_url"
   fi
 }
 github_http_download() {
@@ -399,8 +394,9 @@ github_http_copy() {
 github_release() {
   owner_repo=$1
   version=$2
+  server_url=${3:-https://github.com}
   test -z "$version" && version="latest"
-  giturl="https://github.com/${owner_repo}/releases/${version}"
+  giturl="${server_url}/${owner_repo}/releases/${version}"
   json=$(github_http_copy "$giturl" "Accept:application/json")
   test -z "$json" && return 1
   version=$(echo "$json" | tr -s '\n' ' ' | sed 's/.*"tag_name":"//' | sed 's/".*//')
@@ -408,26 +404,118 @@ github_release() {
   echo "$version"
 }
 
-# --- Embedded Checksums (Format: VERSION:FILENAME:HASH) ---
-EMBEDDED_CHECKSUMS=""
 
-# Find embedded checksum for a given version and filename
-find_embedded_checksum() {
-  version="$1"
-  filename="$2"
-  echo "$EMBEDDED_CHECKSUMS" | grep -E "^${version}:${filename}:" | cut -d':' -f3
+# Print why a directory cannot be installed into: it is a system directory of an
+# immutable distribution or on a read-only file system. Prints nothing otherwise.
+# /usr/local stays writable on ostree-based systems, so only the probe checks it.
+bindir_read_only() {
+  for dir in /usr /bin /sbin /lib /nix/store; do
+    case "$1" in
+    /usr/local | /usr/local/*) ;;
+    "${dir}" | "${dir}"/*)
+      if [ -e /etc/NIXOS ]; then
+        echo "${dir} is read-only on NixOS"
+        return 0
+      fi
+      if [ -e /run/ostree-booted ]; then
+        echo "${dir} is read-only on an ostree-based immutable system"
+        return 0
+      fi
+      ;;
+    esac
+  done
+  if [ "$DRY_RUN" = "1" ]; then
+    return 0
+  fi
+  # Probe the nearest existing directory, where BINDIR would be created
+  dir="$1"
+  while [ ! -d "${dir}" ]; do
+    dir=$(dirname "${dir}")
+  done
+  probe="${dir}/.binstaller-probe.$$"
+  if err=$(LC_ALL=C touch "${probe}" 2>&1); then
+    rm -f "${probe}"
+  else
+    case "${err}" in
+    *"Read-only file system"*) echo "${dir} is on a read-only file system" ;;
+    esac
+  fi
+}
+
+# Redirect a read-only BINDIR to ~/.local/bin, or fail with -s
+resolve_bindir() {
+  reason=$(bindir_read_only "${BINDIR}")
+  if [ -z "${reason}" ]; then
+    return 0
+  fi
+  fallback="${HOME}/.local/bin"
+  if [ "${NO_FALLBACK}" = "1" ] || [ "${NO_FALLBACK}" = "true" ] || [ -z "${HOME}" ] || [ "${BINDIR}" = "${fallback}" ]; then
+    log_crit "Cannot install into ${BINDIR}: ${reason}"
+    log_crit "Choose another directory with -b"
+    exit 1
+  fi
+  log_warn "Cannot install into ${BINDIR}: ${reason}"
+  log_warn "Installing into ${fallback} instead; make sure it is on PATH, or pass -s to fail instead"
+  BINDIR="${fallback}"
 }
 parse_args() {
   BINDIR="${BINSTALLER_BIN:-${HOME}/.local/bin}"
   DRY_RUN=0
-  while getopts "b:dqh?xn" arg; do
+  NO_FALLBACK="${BINSTALLER_NO_FALLBACK:-0}"
+  TRACE=0
+  while getopts "b:dqh?xnso:a:-:" arg; do
+    if [ "$arg" = "-" ]; then
+      # v0 long options, e.g. --bindir=DIR or --bindir DIR
+      arg_value=""
+      case "$OPTARG" in
+      *=*)
+        arg_value="${OPTARG#*=}"
+        OPTARG="${OPTARG%%=*}"
+        ;;
+      esac
+      case "$OPTARG" in
+      bindir)
+        if [ -z "$arg_value" ]; then
+          eval "arg_value=\${$OPTIND}"
+          OPTIND=$((OPTIND + 1))
+        fi
+        if [ -z "$arg_value" ]; then
+          log_err "--$OPTARG requires a value"
+          usage "$0"
+        fi
+        ;;
+      esac
+      case "$OPTARG" in
+      log-json)
+        log_set_json 1
+        continue
+        ;;
+      bindir) arg="b" ;;
+      debug) arg="d" ;;
+      quiet) arg="q" ;;
+      dry-run) arg="n" ;;
+      help) arg="h" ;;
+      *)
+        log_err "unknown option --$OPTARG"
+        usage "$0"
+        ;;
+      esac
+      log_warn "--$OPTARG is deprecated, use -$arg instead"
+      OPTARG="$arg_value"
+    fi
     case "$arg" in
     b) BINDIR="$OPTARG" ;;
     d) log_set_priority 10 ;;
+    o) BINSTALLER_OS="$OPTARG" ;;
+    a) BINSTALLER_ARCH="$OPTARG" ;;
     q) log_set_priority 3 ;;
     h | \?) usage "$0" ;;
-    x) set -x ;;
+    x)
+      TRACE=1
+      set -x
+      ;;
     n) DRY_RUN=1 ;;
+    s) NO_FALLBACK=1 ;;
     esac
   done
   shift $((OPTIND - 1))
@@ -491,51 +579,40 @@ cleanup() {
   fi
 }
 
+# log_resolved logs the resolved variables and the checksum source $1
+# (embedded, the checksum file URL or none) in one line when tracing, and as
+# one JSON object with --log-json
+log_resolved() {
+  if [ "$_logjson" = 1 ]; then
+    printf '{"level":"info","repo":"%s","step":"resolve","msg":"resolved","os":"%s","arch":"%s","tag":"%s","version":"%s","asset_url":"%s","checksum":"%s"}\n' \
+      "$(log_json_escape "${REPO}")" "$(log_json_escape "${OS}")" "$(log_json_escape "${ARCH}")" "$(log_json_escape "${TAG}")" \
+      "$(log_json_escape "${VERSION}")" "$(log_json_escape "${ASSET_URL}")" "$(log_json_escape "$1")" 1>&2
+  elif [ "${TRACE}" = 1 ]; then
+    echoerr "$(log_prefix)" resolved "os=${OS}" "arch=${ARCH}" "tag=${TAG}" "version=${VERSION}" "asset_url=${ASSET_URL}" "checksum=$1"
+  fi
+}
+
 execute() {
   STRIP_COMPONENTS=1
-  CHECKSUM_FILENAME=""
 
   # --- Construct URLs ---
   GITHUB_DOWNLOAD="https://github.com/${REPO}/releases/download"
   ASSET_URL="${GITHUB_DOWNLOAD}/${TAG}/${ASSET_FILENAME}"
-  CHECKSUM_URL=""
-  if [ -n "$CHECKSUM_FILENAME" ]; then
-    CHECKSUM_URL="${GITHUB_DOWNLOAD}/${TAG}/${CHECKSUM_FILENAME}"
-  fi
 
   # --- Download and Verify ---
+  log_set_step download
   TMPDIR=$(mktemp -d)
   # Set up cleanup trap that includes progress clearing
   trap cleanup EXIT HUP INT TERM
   log_debug "Downloading files into ${TMPDIR}"
   log_info "Downloading ${ASSET_URL}"
   github_http_download "${TMPDIR}/${ASSET_FILENAME}" "${ASSET_URL}"
+  log_set_step verify
+  log_resolved none
 
-  # Try to find embedded checksum first
-  EMBEDDED_HASH=$(find_embedded_checksum "$VERSION" "$ASSET_FILENAME")
-
-  if [ -n "$EMBEDDED_HASH" ]; then
-    log_info "Using embedded checksum for verification"
-
-    # Verify using embedded hash
-    got=$(hash_compute "${TMPDIR}/${ASSET_FILENAME}")
-    if [ "$got" != "$EMBEDDED_HASH" ]; then
-      log_crit "Checksum verification failed for ${ASSET_FILENAME}"
-      log_crit "Expected: ${EMBEDDED_HASH}"
-      log_crit "Got: ${got}"
-      return 1
-    fi
-    log_info "Checksum verification successful"
-  elif [ -n "$CHECKSUM_URL" ]; then
-    # Fall back to downloading checksum file
-    log_info "Downloading checksums from ${CHECKSUM_URL}"
-    github_http_download "${TMPDIR}/${CHECKSUM_FILENAME}" "${CHECKSUM_URL}"
-    log_info "Verifying checksum ..."
-    hash_verify "${TMPDIR}/${ASSET_FILENAME}" "${TMPDIR}/${CHECKSUM_FILENAME}"
-  else
-    log_info "No checksum found, skipping verification."
-  fi
+  log_warn "No checksum found, skipping verification."
 
+  log_set_step extract
   if [ -z "${EXT}" ] || [ "${EXT}" = ".exe" ]; then
     log_debug "Target is raw binary"
   else
@@ -567,6 +644,7 @@ execute() {
 
   progress_clear
   # Install the binary
+  log_set_step install
   INSTALL_PATH="${BINDIR}/${BINARY_NAME}"
 
   if [ "$DRY_RUN" = "1" ]; then
@@ -595,6 +673,9 @@ progress_init
 progress_pulse_start
 
 # --- Determine target platform ---
+log_set_step platform
+# Options (-o/-a, or --os/--arch of runners) set BINSTALLER_OS/BINSTALLER_ARCH,
+# which take precedence over detection
 OS="${BINSTALLER_OS:-$(uname_os)}"
 UNAME_OS="${OS}"
 
@@ -605,7 +686,9 @@ log_info "Detected Platform: ${OS}/${ARCH}"
 # --- Validate platform ---
 uname_os_check "$OS"
 uname_arch_check "$ARCH"
+resolve_bindir
 
+log_set_step version
 tag_to_version
 
 resolve_asset_filename